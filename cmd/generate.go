@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/logger"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+)
+
+const generateCommand = "generate"
+
+// runGenerate writes to out the client code for the saved queries of the
+// namespace given as argument, and returns the process exit code.
+func runGenerate(args []string, out io.Writer, errOut io.Writer) int {
+	fs := flag.NewFlagSet(generateCommand, flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	language := fs.String("lang", web.TypeScriptClient, "language of the generated client, typescript, go or openapi")
+	pkg := fs.String("package", "", "package name of the generated go client, defaults to the namespace")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(errOut, "usage: restql %s [-lang typescript|go|openapi] [-package name] <namespace>\n", generateCommand)
+		return 2
+	}
+
+	// As the test command, it requires the ports to be defined.
+	for _, key := range []string{"RESTQL_PORT", "RESTQL_HEALTH_PORT"} {
+		if os.Getenv(key) == "" {
+			os.Setenv(key, "0")
+		}
+	}
+
+	cfg, err := conf.Load(build)
+	if err != nil {
+		fmt.Fprintf(errOut, "[ERROR] failed to load configuration : %v\n", err)
+		return 1
+	}
+
+	log := logger.New(errOut, logger.LogOptions{
+		Enable:               cfg.Logging.Enable,
+		TimestampFieldName:   cfg.Logging.TimestampFieldName,
+		TimestampFieldFormat: cfg.Logging.TimestampFieldFormat,
+		Level:                cfg.Logging.Level,
+		Format:               cfg.Logging.Format,
+		Sampling:             cfg.Logging.Sampling,
+	})
+
+	code, err := web.GenerateClient(log, cfg, fs.Arg(0), *language, *pkg)
+	if err != nil {
+		fmt.Fprintf(errOut, "[ERROR] failed to generate client : %v\n", err)
+		return 1
+	}
+
+	fmt.Fprint(out, code)
+	return 0
+}
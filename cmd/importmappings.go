@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/openapi"
+	"gopkg.in/yaml.v2"
+)
+
+const importMappingsCommand = "import-mappings"
+
+type importedMappingsConf struct {
+	Mappings map[string]string            `yaml:"mappings,omitempty"`
+	Tenants  map[string]map[string]string `yaml:"tenants,omitempty"`
+	Defaults *importedDefaultsConf        `yaml:"defaults,omitempty"`
+}
+
+type importedDefaultsConf struct {
+	Mappings map[string]importedHeadersConf `yaml:"mappings"`
+}
+
+type importedHeadersConf struct {
+	Headers map[string]string `yaml:"headers"`
+}
+
+// runImportMappings writes to out the configuration of the mappings
+// generated from the OpenAPI document given as argument, either a file
+// or a URL, and returns the process exit code.
+func runImportMappings(args []string, out io.Writer, errOut io.Writer) int {
+	fs := flag.NewFlagSet(importMappingsCommand, flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	prefix := fs.String("prefix", "", "prefix of the generated resource names")
+	baseURL := fs.String("base-url", "", "upstream address replacing the one defined on the document")
+	tenant := fs.String("tenant", "", "tenant the mappings are generated for, defaults to every tenant")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(errOut, "usage: restql %s [-prefix name] [-base-url url] [-tenant name] <document file or url>\n", importMappingsCommand)
+		return 2
+	}
+
+	location := fs.Arg(0)
+	content, err := openapi.Load(context.Background(), location)
+	if err != nil {
+		fmt.Fprintf(errOut, "[ERROR] failed to load document : %v\n", err)
+		return 1
+	}
+
+	mappings, err := openapi.Import(content, openapi.Options{Prefix: *prefix, BaseURL: *baseURL, Location: location})
+	if err != nil {
+		fmt.Fprintf(errOut, "[ERROR] failed to import mappings : %v\n", err)
+		return 1
+	}
+
+	urls := make(map[string]string, len(mappings))
+	headers := make(map[string]importedHeadersConf)
+	for _, m := range mappings {
+		urls[m.Resource] = m.URL
+		if len(m.Headers) > 0 {
+			headers[m.Resource] = importedHeadersConf{Headers: m.Headers}
+		}
+	}
+
+	var c importedMappingsConf
+	if *tenant != "" {
+		c.Tenants = map[string]map[string]string{*tenant: urls}
+	} else {
+		c.Mappings = urls
+	}
+	if len(headers) > 0 {
+		c.Defaults = &importedDefaultsConf{Mappings: headers}
+	}
+
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		fmt.Fprintf(errOut, "[ERROR] failed to write configuration : %v\n", err)
+		return 1
+	}
+
+	fmt.Fprint(out, string(data))
+	return 0
+}
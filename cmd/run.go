@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql/engine"
+	"gopkg.in/yaml.v2"
+)
+
+const runCommand = "run"
+
+const waterfallWidth = 40
+
+// runQuery executes the query file given as argument against the resources
+// of a mappings file, writes the result to out and returns the process exit code.
+func runQuery(args []string, out io.Writer, errOut io.Writer) int {
+	fs := flag.NewFlagSet(runCommand, flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	mappingsPath := fs.String("mappings", "", "YAML file with the resource mappings, either as a map of names to URLs or as a restQL configuration file")
+	debug := fs.Bool("debug", false, "include the debugging information in the result and write the execution waterfall")
+	params := queryParams{}
+	fs.Var(params, "param", "query parameter as name=value, repeat it to pass a list")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || *mappingsPath == "" {
+		fmt.Fprintf(errOut, "usage: restql %s -mappings <file> [-param name=value]... [-debug] <query file>\n", runCommand)
+		return 2
+	}
+
+	queryText, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(errOut, "[ERROR] failed to read query : %v\n", err)
+		return 1
+	}
+
+	mappings, err := readMappingsFile(*mappingsPath)
+	if err != nil {
+		fmt.Fprintf(errOut, "[ERROR] failed to read mappings : %v\n", err)
+		return 1
+	}
+
+	e, err := engine.New(engine.Config{Mappings: mappings})
+	if err != nil {
+		fmt.Fprintf(errOut, "[ERROR] failed to initialize engine : %v\n", err)
+		return 1
+	}
+
+	if *debug {
+		params["_debug"] = "true"
+	}
+
+	result, err := e.Execute(context.Background(), string(queryText), params)
+	if err != nil {
+		fmt.Fprintf(errOut, "[ERROR] failed to run query : %v\n", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result.Body); err != nil {
+		fmt.Fprintf(errOut, "[ERROR] failed to write result : %v\n", err)
+		return 1
+	}
+
+	for _, w := range result.Warnings {
+		fmt.Fprintf(errOut, "[WARN] %s %s: %s\n", w.Code, w.Statement, w.Message)
+	}
+
+	if *debug {
+		fmt.Fprintf(errOut, "status %d\n", result.StatusCode)
+		writeWaterfall(errOut, result.Body)
+	}
+
+	return 0
+}
+
+// queryParams collects the repeated -param flags,
+// grouping the values of the same name in a list.
+type queryParams map[string]interface{}
+
+func (qp queryParams) String() string {
+	return fmt.Sprintf("%v", map[string]interface{}(qp))
+}
+
+func (qp queryParams) Set(s string) error {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("parameter must be in the name=value form : %s", s)
+	}
+
+	name, value := parts[0], parts[1]
+	switch current := qp[name].(type) {
+	case nil:
+		qp[name] = value
+	case []interface{}:
+		qp[name] = append(current, value)
+	default:
+		qp[name] = []interface{}{current, value}
+	}
+
+	return nil
+}
+
+func readMappingsFile(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg struct {
+		Mappings map[string]string `yaml:"mappings"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err == nil && len(cfg.Mappings) > 0 {
+		return cfg.Mappings, nil
+	}
+
+	var mappings map[string]string
+	if err := yaml.Unmarshal(data, &mappings); err != nil {
+		return nil, err
+	}
+
+	return mappings, nil
+}
+
+type waterfallRow struct {
+	statement string
+	start     float64
+	duration  float64
+}
+
+// writeWaterfall draws the statements execution timeline,
+// ordered by the moment each one started.
+func writeWaterfall(out io.Writer, body map[string]interface{}) {
+	var rows []waterfallRow
+	for statement, result := range body {
+		r, ok := result.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		switch details := r["details"].(type) {
+		case map[string]interface{}:
+			rows = appendWaterfallRow(rows, statement, details)
+		case []interface{}:
+			for i, d := range details {
+				if d, ok := d.(map[string]interface{}); ok {
+					rows = appendWaterfallRow(rows, fmt.Sprintf("%s[%d]", statement, i), d)
+				}
+			}
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].start != rows[j].start {
+			return rows[i].start < rows[j].start
+		}
+		return rows[i].statement < rows[j].statement
+	})
+
+	var total float64
+	nameWidth := 0
+	for _, r := range rows {
+		if end := r.start + r.duration; end > total {
+			total = end
+		}
+		if len(r.statement) > nameWidth {
+			nameWidth = len(r.statement)
+		}
+	}
+
+	for _, r := range rows {
+		offset, length := 0, 1
+		if total > 0 {
+			offset = int(r.start / total * waterfallWidth)
+			length = int(r.duration/total*waterfallWidth + 0.5)
+		}
+		if length < 1 {
+			length = 1
+		}
+		if offset+length > waterfallWidth {
+			offset = waterfallWidth - length
+		}
+
+		bar := strings.Repeat(" ", offset) + strings.Repeat("#", length) + strings.Repeat(" ", waterfallWidth-offset-length)
+		fmt.Fprintf(out, "%-*s |%s| %8.1fms +%.1fms\n", nameWidth, r.statement, bar, r.start, r.duration)
+	}
+}
+
+func appendWaterfallRow(rows []waterfallRow, statement string, details map[string]interface{}) []waterfallRow {
+	debug, ok := details["debug"].(map[string]interface{})
+	if !ok {
+		return rows
+	}
+
+	row := waterfallRow{statement: statement}
+	if duration, ok := debug["response-time"].(float64); ok {
+		row.duration = duration
+	}
+	if timeline, ok := debug["timeline"].(map[string]interface{}); ok {
+		if start, ok := timeline["start-offset"].(float64); ok {
+			row.start = start
+		}
+	}
+
+	return append(rows, row)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/logger"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+)
+
+const testCommand = "test"
+
+// runTests executes the saved query test cases defined in configuration,
+// optionally filtered by a `namespace` or `namespace/query` argument,
+// and returns the process exit code.
+func runTests(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet(testCommand, flag.ContinueOnError)
+	fs.SetOutput(out)
+	record := fs.Bool("record", false, "record the cassettes of the test cases calling the mapped resources")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	args = fs.Args()
+
+	// The test command does not start any server, but the
+	// configuration requires the ports to be defined.
+	for _, key := range []string{"RESTQL_PORT", "RESTQL_HEALTH_PORT"} {
+		if os.Getenv(key) == "" {
+			os.Setenv(key, "0")
+		}
+	}
+
+	cfg, err := conf.Load(build)
+	if err != nil {
+		fmt.Fprintf(out, "[ERROR] failed to load configuration : %v\n", err)
+		return 1
+	}
+
+	log := logger.New(os.Stderr, logger.LogOptions{
+		Enable:               cfg.Logging.Enable,
+		TimestampFieldName:   cfg.Logging.TimestampFieldName,
+		TimestampFieldFormat: cfg.Logging.TimestampFieldFormat,
+		Level:                cfg.Logging.Level,
+		Format:               cfg.Logging.Format,
+		Sampling:             cfg.Logging.Sampling,
+	})
+
+	var namespace, queryID string
+	if len(args) > 0 {
+		parts := strings.SplitN(args[0], "/", 2)
+		namespace = parts[0]
+		if len(parts) > 1 {
+			queryID = parts[1]
+		}
+	}
+
+	results, err := web.RunQueryTests(log, cfg, namespace, queryID, *record)
+	if err != nil {
+		fmt.Fprintf(out, "[ERROR] failed to run query tests : %v\n", err)
+		return 1
+	}
+
+	failures := 0
+	for _, r := range results {
+		id := fmt.Sprintf("%s/%s/%d %s", r.Namespace, r.Query, r.Revision, r.Name)
+		if r.Passed {
+			fmt.Fprintf(out, "PASS %s\n", id)
+			continue
+		}
+
+		failures++
+		fmt.Fprintf(out, "FAIL %s\n", id)
+		if r.Error != "" {
+			fmt.Fprintf(out, "    error: %s\n", r.Error)
+		}
+		for _, d := range r.Differences {
+			fmt.Fprintf(out, "    %s %s: expected %v, got %v\n", d.Kind, d.Path, d.Base, d.Target)
+		}
+	}
+
+	fmt.Fprintf(out, "%d passed, %d failed\n", len(results)-failures, failures)
+	if failures > 0 {
+		return 1
+	}
+
+	return 0
+}
@@ -3,6 +3,7 @@ module github.com/b2wdigital/restQL-golang/v4
 go 1.15
 
 require (
+	github.com/andybalholm/brotli v1.0.0
 	github.com/bluele/gcache v0.0.0-20190518031135-bc40bd653833
 	github.com/caarlos0/env/v6 v6.3.0
 	github.com/davecgh/go-spew v1.1.1 // indirect
@@ -12,7 +13,6 @@ require (
 	github.com/imdario/mergo v0.3.11
 	github.com/klauspost/compress v1.11.0 // indirect
 	github.com/pkg/errors v0.9.1
-	github.com/rs/dnscache v0.0.0-20190621150935-06bb5526f76b
 	github.com/rs/zerolog v1.20.0
 	github.com/valyala/fasthttp v1.16.0
 	go.uber.org/automaxprocs v1.3.0
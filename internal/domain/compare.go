@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Operators of the Compare function.
+const (
+	CompareEquals      = "equals"
+	CompareGreaterThan = "greaterThan"
+	CompareLessThan    = "lessThan"
+	CompareAfter       = "after"
+	CompareBefore      = "before"
+)
+
+// dateLayouts are the formats accepted by the date
+// comparisons, where dates without a zone are in UTC.
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"}
+
+// ParseDate returns the time represented by the
+// value, if it is a string in an accepted format.
+func ParseDate(value interface{}) (time.Time, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,26 @@
+package domain
+
+// Aggregators available to computed fields.
+const (
+	SumAggregator    = "sum"
+	CountAggregator  = "count"
+	AvgAggregator    = "avg"
+	MinAggregator    = "min"
+	MaxAggregator    = "max"
+	ConcatAggregator = "concat"
+)
+
+// ComputedField represents an entry of the `compute` clause,
+// setting on the statement result the value found at Path,
+// reduced by the Aggregator when one is given. Separator is
+// placed between the values joined by the concat aggregator.
+// Expression, when not nil, transforms the resulting value, with
+// Variables holding the values of the query variables it references.
+type ComputedField struct {
+	Name       string
+	Path       []string
+	Aggregator string
+	Separator  string
+	Expression ExpressionProgram
+	Variables  map[string]interface{}
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"context"
+	"net/http"
+)
+
+type cookieJarKey struct{}
+
+// WithCookieJar returns a context carrying the jar that keeps the
+// session cookies of the resource of the statement being executed.
+func WithCookieJar(ctx context.Context, jar http.CookieJar) context.Context {
+	return context.WithValue(ctx, cookieJarKey{}, jar)
+}
+
+// GetCookieJar returns the jar of the session cookies of
+// the statement being executed, if it keeps them.
+func GetCookieJar(ctx context.Context) (http.CookieJar, bool) {
+	jar, ok := ctx.Value(cookieJarKey{}).(http.CookieJar)
+	return jar, ok && jar != nil
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Faults represents the failures injected in the requests to the
+// upstream of a mapping to verify how queries degrade, each given
+// by the probability, between 0 and 1, of affecting a request.
+//
+// Latency is the maximum delay added to a request, chosen at random,
+// which happens with LatencyRate. ErrorRate answers the request with
+// ErrorStatus, ResetRate fails it as a dropped connection, and
+// TruncateRate cuts the upstream response body in half.
+type Faults struct {
+	Latency      time.Duration
+	LatencyRate  float64
+	ErrorRate    float64
+	ErrorStatus  int
+	ResetRate    float64
+	TruncateRate float64
+}
+
+type faultInjectionKey struct{}
+
+// WithFaultInjection returns a context in which the faults
+// declared for the mappings are injected in their requests.
+func WithFaultInjection(ctx context.Context) context.Context {
+	return context.WithValue(ctx, faultInjectionKey{}, true)
+}
+
+// FaultInjectionEnabled reports if the faults declared for
+// the mappings are injected in the requests of the context.
+func FaultInjectionEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(faultInjectionKey{}).(bool)
+	return enabled
+}
@@ -62,10 +62,12 @@ func (b Base64) Map(fn func(target interface{}) interface{}) Function {
 }
 
 // Match is a Function that select values from the
-// statement result based on the given Arg.
+// statement result based on the given Arg, a regex
+// compiled with the given Flags.
 type Match struct {
 	Value interface{}
 	Arg   interface{}
+	Flags string
 }
 
 // Target return the value upon which Match will be applied.
@@ -76,7 +78,81 @@ func (m Match) Target() interface{} {
 // Map apply the given function to the Target value
 // preserving the Match as wrapper.
 func (m Match) Map(fn func(target interface{}) interface{}) Function {
-	return Match{Value: fn(m.Value), Arg: m.Arg}
+	return Match{Value: fn(m.Value), Arg: m.Arg, Flags: m.Flags}
+}
+
+// Compare is a Function that select values from the statement
+// result by comparing them with the given Arg, using one of the
+// comparison operators, like CompareGreaterThan.
+type Compare struct {
+	Value    interface{}
+	Operator string
+	Arg      interface{}
+}
+
+// Target return the value upon which Compare will be applied.
+func (c Compare) Target() interface{} {
+	return c.Value
+}
+
+// Map apply the given function to the Target value
+// preserving the Compare as wrapper.
+func (c Compare) Map(fn func(target interface{}) interface{}) Function {
+	return Compare{Value: fn(c.Value), Operator: c.Operator, Arg: c.Arg}
+}
+
+// FilterByKeys is a Function that keeps only the given
+// Keys of the objects selected by a filter, where Keys
+// is a list of names or a variable resolving to it.
+type FilterByKeys struct {
+	Value interface{}
+	Keys  interface{}
+}
+
+// Target return the value upon which FilterByKeys will be applied.
+func (fk FilterByKeys) Target() interface{} {
+	return fk.Value
+}
+
+// Map apply the given function to the Target value
+// preserving the FilterByKeys as wrapper.
+func (fk FilterByKeys) Map(fn func(target interface{}) interface{}) Function {
+	return FilterByKeys{Value: fn(fk.Value), Keys: fk.Keys}
+}
+
+// RenameAs is a Function that returns the field
+// selected by a filter under the given Name.
+type RenameAs struct {
+	Value interface{}
+	Name  string
+}
+
+// Target return the value upon which RenameAs will be applied.
+func (ra RenameAs) Target() interface{} {
+	return ra.Value
+}
+
+// Map apply the given function to the Target value
+// preserving the RenameAs as wrapper.
+func (ra RenameAs) Map(fn func(target interface{}) interface{}) Function {
+	return RenameAs{Value: fn(ra.Value), Name: ra.Name}
+}
+
+// First is a Function that collapses the list
+// selected by a filter to its first element.
+type First struct {
+	Value interface{}
+}
+
+// Target return the value upon which First will be applied.
+func (f First) Target() interface{} {
+	return f.Value
+}
+
+// Map apply the given function to the Target value
+// preserving the First as wrapper.
+func (f First) Map(fn func(target interface{}) interface{}) Function {
+	return First{Value: fn(f.Value)}
 }
 
 // AsBody is a Function that define a `with`
@@ -113,3 +189,110 @@ func (f Flatten) Target() interface{} {
 func (f Flatten) Map(fn func(target interface{}) interface{}) Function {
 	return Flatten{Value: fn(f.Value)}
 }
+
+// Separators used by the Delimited encoder.
+const (
+	CSVSeparator  = ","
+	PipeSeparator = "|"
+)
+
+// DeepObject is a Function that encode the target object
+// as one query parameter for each field, with the nested
+// keys in brackets, like `filter[color]=red`.
+type DeepObject struct {
+	Value interface{}
+}
+
+// Target return the value upon which DeepObject will be applied.
+func (d DeepObject) Target() interface{} {
+	return d.Value
+}
+
+// Map apply the given function to the Target value
+// preserving the DeepObject as wrapper.
+func (d DeepObject) Map(fn func(target interface{}) interface{}) Function {
+	return DeepObject{Value: fn(d.Value)}
+}
+
+// Delimited is a Function that encode the target list
+// as a single value, joining its elements with Separator.
+type Delimited struct {
+	Value     interface{}
+	Separator string
+}
+
+// Target return the value upon which Delimited will be applied.
+func (d Delimited) Target() interface{} {
+	return d.Value
+}
+
+// Map apply the given function to the Target value
+// preserving the Delimited as wrapper.
+func (d Delimited) Map(fn func(target interface{}) interface{}) Function {
+	return Delimited{Value: fn(d.Value), Separator: d.Separator}
+}
+
+// Repeated is a Function that encode the target list
+// as the same query parameter repeated for each element,
+// in a single request instead of multiplexing it.
+type Repeated struct {
+	Value interface{}
+}
+
+// Target return the value upon which Repeated will be applied.
+func (r Repeated) Target() interface{} {
+	return r.Value
+}
+
+// Map apply the given function to the Target value
+// preserving the Repeated as wrapper.
+func (r Repeated) Map(fn func(target interface{}) interface{}) Function {
+	return Repeated{Value: fn(r.Value)}
+}
+
+// DefaultValue is a Function that replaces the target value
+// by Default when it cannot be resolved, like a variable the
+// client did not send or a chain targeting a missing field.
+type DefaultValue struct {
+	Value   interface{}
+	Default interface{}
+}
+
+// Target return the value upon which DefaultValue will be applied.
+func (dv DefaultValue) Target() interface{} {
+	return dv.Value
+}
+
+// Map apply the given function to the Target value
+// preserving the DefaultValue as wrapper.
+func (dv DefaultValue) Map(fn func(target interface{}) interface{}) Function {
+	return DefaultValue{Value: fn(dv.Value), Default: dv.Default}
+}
+
+// ExpressionProgram is a compiled expression, evaluated over
+// a value with the values of the query variables it references.
+// String returns the expression source.
+type ExpressionProgram interface {
+	Eval(value interface{}, variables map[string]interface{}) (interface{}, error)
+	String() string
+}
+
+// Expression is a Function that transforms the target value
+// with the compiled Program, where Variables holds the values
+// of the query variables it references, by name.
+type Expression struct {
+	Value     interface{}
+	Program   ExpressionProgram
+	Variables map[string]interface{}
+}
+
+// Target return the value upon which Expression will be applied.
+func (e Expression) Target() interface{} {
+	return e.Value
+}
+
+// Map apply the given function to the Target value
+// preserving the Expression as wrapper.
+func (e Expression) Map(fn func(target interface{}) interface{}) Function {
+	return Expression{Value: fn(e.Value), Program: e.Program, Variables: e.Variables}
+}
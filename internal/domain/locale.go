@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Headers carrying the locale preferences of the client.
+const (
+	AcceptLanguageHeader = "Accept-Language"
+	LocaleHeader         = "X-Locale"
+	CurrencyHeader       = "X-Currency"
+)
+
+// Implicit query variables filled from the locale headers
+// when the query input has no value with the same name.
+const (
+	LocaleVariable   = "locale"
+	CurrencyVariable = "currency"
+)
+
+// ForwardingProfiles are the built-in groups of client headers
+// that a forwarding policy can allow by name.
+var ForwardingProfiles = map[string][]string{
+	"locale": {AcceptLanguageHeader, LocaleHeader, CurrencyHeader},
+}
+
+// LocaleVariableValue returns the value of the implicit locale
+// variables from the client headers. The locale is given by the
+// LocaleHeader or, without it, by the preferred language of the
+// AcceptLanguageHeader, and the currency by the CurrencyHeader.
+func LocaleVariableValue(name string, headers map[string]string) (string, bool) {
+	switch name {
+	case LocaleVariable:
+		if locale, found := headerValue(headers, LocaleHeader); found {
+			return locale, true
+		}
+
+		acceptLanguage, found := headerValue(headers, AcceptLanguageHeader)
+		if !found {
+			return "", false
+		}
+		return preferredLanguage(acceptLanguage)
+	case CurrencyVariable:
+		return headerValue(headers, CurrencyHeader)
+	default:
+		return "", false
+	}
+}
+
+// preferredLanguage returns the language tag with the highest
+// quality in an Accept-Language value, the first one on ties.
+func preferredLanguage(acceptLanguage string) (string, bool) {
+	var preferred string
+	best := -1.0
+
+	for _, item := range strings.Split(acceptLanguage, ",") {
+		parts := strings.Split(item, ";")
+		tag := strings.TrimSpace(parts[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, p := range parts[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				q, err := strconv.ParseFloat(p[2:], 64)
+				if err != nil {
+					q = 0
+				}
+				quality = q
+			}
+		}
+
+		if quality > best {
+			preferred, best = tag, quality
+		}
+	}
+
+	return preferred, preferred != "" && best > 0
+}
+
+func headerValue(headers map[string]string, name string) (string, bool) {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value), true
+		}
+	}
+
+	return "", false
+}
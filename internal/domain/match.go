@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Flags accepted by the `matches` function, where the unicode
+// flag makes the \w, \d and \s classes, and their negations,
+// match any letter, digit or space instead of only ASCII ones.
+const (
+	MatchCaseInsensitive = 'i'
+	MatchMultiline       = 'm'
+	MatchDotAll          = 's'
+	MatchUnicode         = 'u'
+)
+
+// Replacements of the Perl classes by the unicode flag,
+// outside and inside a bracketed class. Negated classes
+// cannot be expressed inside a bracketed class and are
+// kept as ASCII classes there.
+var unicodeClasses = map[byte][2]string{
+	'w': {`[\p{L}\p{M}\p{N}_]`, `\p{L}\p{M}\p{N}_`},
+	'W': {`[^\p{L}\p{M}\p{N}_]`, ""},
+	'd': {`\p{Nd}`, `\p{Nd}`},
+	'D': {`\P{Nd}`, `\P{Nd}`},
+	's': {`[\s\p{Z}]`, `\s\p{Z}`},
+	'S': {`[^\s\p{Z}]`, ""},
+}
+
+// CompileMatch compiles the regex of a `matches` function
+// with the given flags, failing on unknown flags.
+func CompileMatch(pattern string, flags string) (*regexp.Regexp, error) {
+	var inline strings.Builder
+	unicode := false
+	for _, f := range flags {
+		switch f {
+		case MatchCaseInsensitive, MatchMultiline, MatchDotAll:
+			if !strings.ContainsRune(inline.String(), f) {
+				inline.WriteRune(f)
+			}
+		case MatchUnicode:
+			unicode = true
+		default:
+			return nil, fmt.Errorf("unknown flag %q, must be one of i, m, s or u", f)
+		}
+	}
+
+	if unicode {
+		pattern = expandUnicodeClasses(pattern)
+	}
+
+	if inline.Len() > 0 {
+		pattern = "(?" + inline.String() + ")" + pattern
+	}
+
+	return regexp.Compile(pattern)
+}
+
+func expandUnicodeClasses(pattern string) string {
+	var b strings.Builder
+	inClass := false
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			next := pattern[i+1]
+			i++
+
+			if r, ok := unicodeClasses[next]; ok {
+				replacement := r[0]
+				if inClass {
+					replacement = r[1]
+				}
+				if replacement != "" {
+					b.WriteString(replacement)
+					continue
+				}
+			}
+
+			b.WriteByte(c)
+			b.WriteByte(next)
+		case c == '[' && !inClass:
+			inClass = true
+			b.WriteByte(c)
+
+			// a closing bracket right after the opening one,
+			// or its negation, is a literal member of the class
+			if i+1 < len(pattern) && pattern[i+1] == '^' {
+				i++
+				b.WriteByte('^')
+			}
+			if i+1 < len(pattern) && pattern[i+1] == ']' {
+				i++
+				b.WriteByte(']')
+			}
+		case c == '[' && inClass && strings.HasPrefix(pattern[i:], "[:"):
+			end := strings.Index(pattern[i:], ":]")
+			if end < 0 {
+				b.WriteByte(c)
+				continue
+			}
+			b.WriteString(pattern[i : i+end+2])
+			i += end + 1
+		case c == ']' && inClass:
+			inClass = false
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,17 @@
+package domain
+
+// ResourcePolicy represents the requests allowed against a mapping,
+// which queries must conform to before they are executed. Empty
+// values allow everything.
+type ResourcePolicy struct {
+	// Methods are the allowed HTTP methods, in upper case.
+	Methods []string `json:"methods,omitempty"`
+
+	// PathParams are the path parameters statements can set,
+	// when not nil.
+	PathParams []string `json:"pathParams,omitempty"`
+
+	// MaxBodySize is the maximum size, in bytes,
+	// of the JSON encoded request body.
+	MaxBodySize int `json:"maxBodySize,omitempty"`
+}
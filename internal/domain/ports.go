@@ -2,6 +2,8 @@ package domain
 
 import (
 	"context"
+	"time"
+
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
 	"github.com/pkg/errors"
 )
@@ -11,6 +13,20 @@ import (
 // the timeout defined in HTTPRequest.
 var ErrRequestTimeout = errors.New("request timed out")
 
+// ErrResponseTooLarge is the error returned by HTTPClient
+// when the response body exceeds the maximum size
+// defined in HTTPRequest.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// ErrResponseTooComplex is the error returned by HTTPClient
+// when the response body exceeds the maximum JSON nesting
+// depth or number of object keys.
+var ErrResponseTooComplex = errors.New("response body too complex")
+
+// ErrUpstreamThrottled is the error reported when a request
+// is done again because the upstream throttled it.
+var ErrUpstreamThrottled = errors.New("request throttled by upstream")
+
 // EnvSource expose access to environment variables.
 type EnvSource interface {
 	GetString(key string) string
@@ -24,3 +40,51 @@ type EnvSource interface {
 type HTTPClient interface {
 	Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error)
 }
+
+// ResponseCache is the interface that wrap the methods Get, Set and SetWithTTL
+//
+// It stores upstream responses by request so they can be used
+// when the upstream answers a revalidation with 304 Not Modified,
+// or for the statements with the `cache` modifier, along with the
+// resource they belong to, so they can be purged.
+type ResponseCache interface {
+	Get(key string) (restql.HTTPResponse, bool)
+	Set(key string, resource string, response restql.HTTPResponse)
+	SetWithTTL(key string, resource string, response restql.HTTPResponse, ttl time.Duration)
+}
+
+// Bulkhead is the interface that wrap the method AcquireResource
+//
+// AcquireResource takes one of the slots for requests in flight to the
+// upstream of a mapped resource, waiting in its queue while they are all
+// taken. It returns the function releasing the slot, or false when the
+// request was rejected because the queue was full or the wait expired.
+type Bulkhead interface {
+	AcquireResource(ctx context.Context, tenant string, resource string) (func(), bool)
+}
+
+// RateLimiter is the interface that wrap the method AllowResource
+//
+// AllowResource reports if a request can be made to the upstream
+// of a mapped resource, or else how long until it is allowed.
+type RateLimiter interface {
+	AllowResource(ctx context.Context, tenant string, resource string) (bool, time.Duration)
+}
+
+// UpstreamEvent represents an event received from the
+// server-sent events stream of an upstream.
+type UpstreamEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// EventSource is the interface that wrap the method Subscribe
+//
+// Subscribe opens the server-sent events stream of the request,
+// returning the upstream response once its headers are received
+// and, when it is an event stream, the channel of its events,
+// which is closed when the stream ends or the Context is done.
+type EventSource interface {
+	Subscribe(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, <-chan UpstreamEvent, error)
+}
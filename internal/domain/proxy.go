@@ -0,0 +1,51 @@
+package domain
+
+import "context"
+
+// Schemes of the outbound proxies.
+const (
+	HTTPProxyScheme   = "http"
+	SOCKS5ProxyScheme = "socks5"
+)
+
+// OutboundProxy represents the egress proxy the requests to the
+// upstream of a mapping are routed through, either an HTTP proxy
+// tunneling the connections with CONNECT or a SOCKS5 one.
+// A proxy without address means the requests go direct.
+type OutboundProxy struct {
+	Scheme   string
+	Addr     string
+	Username string
+	Password string
+}
+
+// Direct returns true when the proxy disables an inherited one.
+func (p OutboundProxy) Direct() bool {
+	return p.Addr == ""
+}
+
+// String returns the proxy URL, omitting the password.
+func (p OutboundProxy) String() string {
+	if p.Direct() {
+		return "direct"
+	}
+	if p.Username != "" {
+		return p.Scheme + "://" + p.Username + "@" + p.Addr
+	}
+	return p.Scheme + "://" + p.Addr
+}
+
+type outboundProxyKey struct{}
+
+// WithOutboundProxy returns a context carrying the proxy the
+// requests of the statement being executed are routed through.
+func WithOutboundProxy(ctx context.Context, proxy *OutboundProxy) context.Context {
+	return context.WithValue(ctx, outboundProxyKey{}, proxy)
+}
+
+// GetOutboundProxy returns the proxy the requests of the
+// statement being executed are routed through, if any.
+func GetOutboundProxy(ctx context.Context) (*OutboundProxy, bool) {
+	proxy, ok := ctx.Value(outboundProxyKey{}).(*OutboundProxy)
+	return proxy, ok && proxy != nil && !proxy.Direct()
+}
@@ -1,5 +1,11 @@
 package domain
 
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
 // Methods available to be used in query statements.
 const (
 	FromMethod   string = "from"
@@ -9,28 +15,131 @@ const (
 	DeleteMethod        = "delete"
 )
 
+// SubqueryPrefix identifies the statements targeting
+// a saved query instead of a mapped resource.
+const SubqueryPrefix = "query:"
+
+// Functions available to be applied to statement results.
+const (
+	FlattenResult  = "flatten"
+	DistinctResult = "distinct"
+)
+
 // Query is the internal representation of the restQL language.
+//
+// Only holds the filters of the `use only` clause, applied to the
+// aggregated result, whose paths start with the statement identifier.
 type Query struct {
 	Use        Modifiers
+	Only       []interface{}
 	Statements []Statement
+	Warnings   []Warning
 }
 
 // Modifiers is the internal representation of the `use` clause.
 type Modifiers map[string]interface{}
 
 // Statement is the internal representation of a query statement.
+//
+// Default is the JSON encoded value of the `default` clause,
+// which replaces the result when the statement fails.
+//
+// ResultFunctions are applied, in order, to the statement result
+// before it is filtered or aggregated.
+//
+// Unordered is set by `-> ordered(false)`, allowing each result of a
+// multiplexed statement to be streamed as soon as it completes. The
+// statement result always keeps the order of the multiplexed values.
+//
+// HTTPMethod is the upstream request method declared by the
+// `method` clause, overriding the one implied by Method.
+//
+// DefaultParams are the query parameters set by the defaults
+// cascade, sent with every request of the statement.
 type Statement struct {
-	Method       string
-	Resource     string
-	Alias        string
-	In           []string
-	Headers      map[string]interface{}
-	Timeout      interface{}
-	With         Params
-	Only         []interface{}
-	Hidden       bool
-	CacheControl CacheControl
-	IgnoreErrors bool
+	Method                    string
+	HTTPMethod                string
+	Resource                  string
+	Alias                     string
+	In                        []string
+	Join                      *Join
+	ResultFunctions           []string
+	Unordered                 bool
+	Headers                   map[string]interface{}
+	DefaultParams             map[string]string
+	Timeout                   interface{}
+	Retries                   int
+	ForwardConditionalHeaders bool
+	SessionCookies            bool
+	ForwardHeaders            *HeaderForwarding
+	FailoverURLs              []string
+	FailoverStatusCodes       []int
+	MaxResponseSize           int
+	MaxMultiplexedRequests    int
+	RejectedRequests          uint64
+	Normalize                 *Normalization
+	Mock                      *Mock
+	Mocked                    bool
+	Subscribed                bool
+	ResponseSchema            *ResponseSchema
+	Signing                   *RequestSigning
+	Policy                    *ResourcePolicy
+	Proxy                     *OutboundProxy
+	TLS                       *UpstreamTLS
+	Faults                    *Faults
+	With                      Params
+	Only                      []interface{}
+	Compute                   []ComputedField
+	Hidden                    bool
+	CacheControl              CacheControl
+	Cache                     int
+	NoCache                   bool
+	SLO                       int
+	ReturnHeaders             []string
+	Default                   []byte
+	IgnoreErrors              bool
+	FilterErrors              bool
+}
+
+// Join represents the `on` clause of `in` and `join`, matching each
+// element of the target with the statement result whose OriginKey
+// value equals the element TargetKey value, instead of matching them
+// by position. A `join` is an `in` targeting the statement itself,
+// whose matched results are merged into the target elements.
+type Join struct {
+	TargetKey []string
+	OriginKey []string
+}
+
+// Normalization represents the rules applied to every successful
+// response of a resource before it is used by the query, in the
+// order: lift, drop and rename. When the lifted value is a list,
+// drop and rename apply to each of its objects.
+type Normalization struct {
+	Lift   string            `json:"lift,omitempty"`
+	Drop   []string          `json:"drop,omitempty"`
+	Rename map[string]string `json:"rename,omitempty"`
+}
+
+// HeaderForwarding represents the rules selecting which client
+// headers are forwarded to the upstream of a resource. When Allow
+// is defined only the listed headers are forwarded, and Deny takes
+// precedence over it. Names ending with * match any header with the
+// prefix. Rename maps a client header to the name sent upstream.
+type HeaderForwarding struct {
+	Allow  []string          `json:"allow,omitempty"`
+	Deny   []string          `json:"deny,omitempty"`
+	Rename map[string]string `json:"rename,omitempty"`
+}
+
+// Mock represents the canned response declared for a resource,
+// served instead of calling the upstream when the statement is
+// Mocked or the resource has no mapping. Body is JSON encoded.
+type Mock struct {
+	Status  int
+	Headers map[string]string
+	Body    []byte
+	Latency time.Duration
 }
 
 // Params is the internal representation of the `with` clause.
@@ -45,6 +154,64 @@ type CacheControl struct {
 	SMaxAge interface{}
 }
 
+// MaxDynamicTimeout caps, in milliseconds, the `timeout` of statements
+// set from variables or chained values, while MaxDynamicMaxAge caps,
+// in seconds, the `max-age` and `s-max-age` set the same way.
+const (
+	MaxDynamicTimeout = 60000
+	MaxDynamicMaxAge  = 31536000
+)
+
+// DynamicTimeout returns the `timeout` resolved from a variable or
+// chained value, which must be a positive integer, as milliseconds
+// limited to MaxDynamicTimeout.
+func DynamicTimeout(value interface{}) (int, bool) {
+	timeout, ok := dynamicInt(value)
+	if !ok || timeout <= 0 {
+		return 0, false
+	}
+
+	if timeout > MaxDynamicTimeout {
+		return MaxDynamicTimeout, true
+	}
+	return timeout, true
+}
+
+// DynamicMaxAge returns the `max-age` or `s-max-age` resolved from a
+// variable or chained value, which must be a non negative integer,
+// as seconds limited to MaxDynamicMaxAge.
+func DynamicMaxAge(value interface{}) (int, bool) {
+	maxAge, ok := dynamicInt(value)
+	if !ok || maxAge < 0 {
+		return 0, false
+	}
+
+	if maxAge > MaxDynamicMaxAge {
+		return MaxDynamicMaxAge, true
+	}
+	return maxAge, true
+}
+
+func dynamicInt(value interface{}) (int, bool) {
+	switch value := value.(type) {
+	case int:
+		return value, true
+	case float64:
+		if value != float64(int(value)) {
+			return 0, false
+		}
+		return int(value), true
+	case string:
+		result, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, false
+		}
+		return result, true
+	default:
+		return 0, false
+	}
+}
+
 // Variable is the internal representation of a variable parameter value.
 type Variable struct {
 	Target string
@@ -52,3 +219,47 @@ type Variable struct {
 
 // Chain is the internal representation of a chain parameter value.
 type Chain []interface{}
+
+// Range is the internal representation of a `range` parameter value,
+// generating a list of integers from Start to End, inclusive, by Step.
+//
+// Bounds and step can be integers, variables or chain values,
+// and the list is only generated once all of them are resolved.
+type Range struct {
+	Start interface{}
+	End   interface{}
+	Step  interface{}
+}
+
+// Subquery is the internal representation of a saved query
+// targeted by a statement, in the form `query:namespace/id/revision`.
+// A zero Revision means the latest revision of the query.
+type Subquery struct {
+	Namespace string
+	ID        string
+	Revision  int
+}
+
+// ParseSubquery returns the saved query targeted by the
+// statement resource, if it is a subquery.
+func ParseSubquery(resource string) (Subquery, bool) {
+	if !strings.HasPrefix(resource, SubqueryPrefix) {
+		return Subquery{}, false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(resource, SubqueryPrefix), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return Subquery{}, false
+	}
+
+	subquery := Subquery{Namespace: parts[0], ID: parts[1]}
+	if len(parts) == 3 {
+		revision, err := strconv.Atoi(parts[2])
+		if err != nil || revision <= 0 {
+			return Subquery{}, false
+		}
+		subquery.Revision = revision
+	}
+
+	return subquery, true
+}
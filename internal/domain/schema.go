@@ -0,0 +1,22 @@
+package domain
+
+// Modes of the response schema validation, where the failing
+// one fails the statement on a violation while the annotating
+// one only reports it.
+const (
+	SchemaModeFail     = "fail"
+	SchemaModeAnnotate = "annotate"
+)
+
+// SchemaValidator checks a value, in its generic JSON form,
+// returning the violations found or nil for valid values.
+type SchemaValidator interface {
+	Validate(value interface{}) []string
+}
+
+// ResponseSchema represents the JSON Schema every successful
+// response of a resource is validated against.
+type ResponseSchema struct {
+	Schema SchemaValidator
+	Mode   string
+}
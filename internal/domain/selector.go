@@ -0,0 +1,250 @@
+package domain
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// ListSelector represents a segment of an `only` path selecting
+// elements of a list, either by index, like `[0]`, or by a slice,
+// like `[0:10]`. Negative bounds count from the end of the list.
+type ListSelector struct {
+	Start *int
+	End   *int
+	Index bool
+}
+
+// ParseListSelector returns the selector represented
+// by the path segment, if it is a valid one.
+func ParseListSelector(segment string) (ListSelector, bool) {
+	if !strings.HasPrefix(segment, "[") || !strings.HasSuffix(segment, "]") {
+		return ListSelector{}, false
+	}
+
+	bounds := strings.Split(segment[1:len(segment)-1], ":")
+	switch len(bounds) {
+	case 1:
+		index, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return ListSelector{}, false
+		}
+		return ListSelector{Start: &index, Index: true}, true
+	case 2:
+		var selector ListSelector
+		for i, b := range bounds {
+			if b == "" {
+				continue
+			}
+
+			n, err := strconv.Atoi(b)
+			if err != nil {
+				return ListSelector{}, false
+			}
+
+			if i == 0 {
+				selector.Start = &n
+			} else {
+				selector.End = &n
+			}
+		}
+		return selector, true
+	default:
+		return ListSelector{}, false
+	}
+}
+
+// IsListSelector returns true if the path segment
+// is meant to select elements of a list.
+func IsListSelector(segment string) bool {
+	return strings.HasPrefix(segment, "[")
+}
+
+// Indexes returns the positions selected in a list of
+// the given length, in ascending order.
+func (s ListSelector) Indexes(length int) []int {
+	if s.Index {
+		i := *s.Start
+		if i < 0 {
+			i += length
+		}
+		if i < 0 || i >= length {
+			return nil
+		}
+		return []int{i}
+	}
+
+	start, end := 0, length
+	if s.Start != nil {
+		start = clampBound(*s.Start, length)
+	}
+	if s.End != nil {
+		end = clampBound(*s.End, length)
+	}
+
+	var result []int
+	for i := start; i < end; i++ {
+		result = append(result, i)
+	}
+	return result
+}
+
+func clampBound(bound int, length int) int {
+	if bound < 0 {
+		bound += length
+	}
+
+	switch {
+	case bound < 0:
+		return 0
+	case bound > length:
+		return length
+	default:
+		return bound
+	}
+}
+
+// FormatPath joins the fields of an `only`
+// path as it is written in the query.
+func FormatPath(fields []string) string {
+	var b strings.Builder
+	for i, f := range fields {
+		if i > 0 && !IsListSelector(f) {
+			b.WriteByte('.')
+		}
+		b.WriteString(f)
+	}
+	return b.String()
+}
+
+// ElementPredicate represents a chain path segment selecting the
+// elements of a list that satisfy a condition, like
+// `[?(@.active==true)]`. Without an operator, it selects the
+// elements where the field is present and is not false or null.
+type ElementPredicate struct {
+	Field    []string
+	Operator string
+	Value    interface{}
+}
+
+// ParseElementPredicate returns the predicate represented
+// by the path segment, if it is a valid one.
+func ParseElementPredicate(segment string) (ElementPredicate, bool) {
+	if !strings.HasPrefix(segment, "[?(") || !strings.HasSuffix(segment, ")]") {
+		return ElementPredicate{}, false
+	}
+
+	expr := strings.TrimSpace(segment[3 : len(segment)-2])
+	if !strings.HasPrefix(expr, "@.") {
+		return ElementPredicate{}, false
+	}
+	expr = expr[2:]
+
+	i := strings.IndexAny(expr, "=!<>")
+	if i < 0 {
+		return ElementPredicate{Field: strings.Split(expr, ".")}, true
+	}
+
+	op := expr[i : i+1]
+	if i+1 < len(expr) && expr[i+1] == '=' {
+		op = expr[i : i+2]
+	}
+	if op == "=" || op == "!" {
+		return ElementPredicate{}, false
+	}
+
+	value, ok := parsePredicateValue(strings.TrimSpace(expr[i+len(op):]))
+	if !ok {
+		return ElementPredicate{}, false
+	}
+
+	field := strings.Split(strings.TrimSpace(expr[:i]), ".")
+	return ElementPredicate{Field: field, Operator: op, Value: value}, true
+}
+
+func parsePredicateValue(s string) (interface{}, bool) {
+	switch {
+	case s == "true":
+		return true, true
+	case s == "false":
+		return false, true
+	case s == "null":
+		return nil, true
+	case len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0]:
+		return s[1 : len(s)-1], true
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, false
+	}
+	return n, true
+}
+
+// Matches returns true if the list element satisfies the predicate.
+func (p ElementPredicate) Matches(element interface{}) bool {
+	value, found := element, true
+	for _, f := range p.Field {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		value, found = object[f]
+		if !found {
+			return false
+		}
+	}
+
+	switch p.Operator {
+	case "":
+		return value != nil && value != false
+	case "==":
+		return predicateEquals(value, p.Value)
+	case "!=":
+		return !predicateEquals(value, p.Value)
+	}
+
+	v, vok := predicateNumber(value)
+	a, aok := predicateNumber(p.Value)
+	if !vok || !aok {
+		return false
+	}
+
+	switch p.Operator {
+	case ">":
+		return v > a
+	case "<":
+		return v < a
+	case ">=":
+		return v >= a
+	default:
+		return v <= a
+	}
+}
+
+func predicateEquals(value interface{}, arg interface{}) bool {
+	switch arg := arg.(type) {
+	case nil:
+		return value == nil
+	case float64:
+		v, ok := predicateNumber(value)
+		return ok && v == arg
+	default:
+		return value == arg
+	}
+}
+
+func predicateNumber(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case int:
+		return float64(value), true
+	case json.Number:
+		f, err := value.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "context"
+
+// Schemes of the outbound request signatures.
+const (
+	HMACSigningScheme  = "hmac"
+	SigV4SigningScheme = "sigv4"
+)
+
+// RequestSigning represents how the requests to the upstream of a
+// mapping are authenticated, either by an HMAC of the canonical
+// request made with a shared secret or by the AWS Signature Version 4
+// of the credentials found in the environment.
+type RequestSigning struct {
+	Scheme string
+
+	// KeyID, Secret and Header are used by the hmac scheme,
+	// where Header is the one carrying the signature.
+	KeyID  string
+	Secret string
+	Header string
+
+	// Region and Service are used by the sigv4 scheme.
+	Region  string
+	Service string
+}
+
+type requestSigningKey struct{}
+
+// WithRequestSigning returns a context carrying how the
+// requests of the statement being executed are signed.
+func WithRequestSigning(ctx context.Context, signing *RequestSigning) context.Context {
+	return context.WithValue(ctx, requestSigningKey{}, signing)
+}
+
+// GetRequestSigning returns how the requests of the
+// statement being executed are signed, if they are.
+func GetRequestSigning(ctx context.Context) (*RequestSigning, bool) {
+	signing, ok := ctx.Value(requestSigningKey{}).(*RequestSigning)
+	return signing, ok && signing != nil
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+)
+
+// Revocation checks of the upstream certificates.
+const (
+	OCSPRevocationCheck       = "ocsp"
+	StrictOCSPRevocationCheck = "ocsp-strict"
+	CRLRevocationCheck        = "crl"
+)
+
+// UpstreamTLS represents the settings of the TLS connections to the
+// upstream of a mapping, where zero values keep the client defaults.
+// Session resumption is enabled unless DisableSessionTickets is set,
+// with SessionCacheSize sessions kept, and RevocationCheck verifies
+// the stapled OCSP response, requiring one when strict, or the
+// certificate revocation lists of the upstream certificate.
+type UpstreamTLS struct {
+	MinVersion            uint16
+	CipherSuites          []uint16
+	DisableSessionTickets bool
+	SessionCacheSize      int
+	RevocationCheck       string
+}
+
+// String returns a representation of the settings
+// which is the same for every equivalent one.
+func (t UpstreamTLS) String() string {
+	return fmt.Sprintf("min=%x ciphers=%x tickets=%t cache=%d revocation=%s",
+		t.MinVersion, t.CipherSuites, !t.DisableSessionTickets, t.SessionCacheSize, t.RevocationCheck)
+}
+
+type upstreamTLSKey struct{}
+
+// WithUpstreamTLS returns a context carrying the TLS settings
+// of the connections of the statement being executed.
+func WithUpstreamTLS(ctx context.Context, t *UpstreamTLS) context.Context {
+	return context.WithValue(ctx, upstreamTLSKey{}, t)
+}
+
+// GetUpstreamTLS returns the TLS settings of the connections
+// of the statement being executed, if there are any.
+func GetUpstreamTLS(ctx context.Context) (*UpstreamTLS, bool) {
+	t, ok := ctx.Value(upstreamTLSKey{}).(*UpstreamTLS)
+	return t, ok && t != nil
+}
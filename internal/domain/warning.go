@@ -0,0 +1,17 @@
+package domain
+
+// Codes of the warnings reported during query processing.
+const (
+	InvalidModifierWarning = "invalid-modifier"
+	FilterMissWarning      = "filter-miss"
+	UnusedHiddenWarning    = "unused-hidden"
+)
+
+// Warning represents a non-fatal issue found while
+// processing a query, which does not prevent it from
+// running but probably is a misconfiguration.
+type Warning struct {
+	Code      string `json:"code"`
+	Statement string `json:"statement,omitempty"`
+	Message   string `json:"message"`
+}
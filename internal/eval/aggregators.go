@@ -8,8 +8,8 @@ import (
 	"github.com/pkg/errors"
 )
 
-// ApplyAggregators resolves the `in` keyword in the query,
-// taking values from one statement result than setting it
+// ApplyAggregators resolves the `in` and `join` keywords in the
+// query, taking values from one statement result than setting it
 // on target statement result.
 func ApplyAggregators(log restql.Logger, query domain.Query, resources domain.Resources) domain.Resources {
 	for _, stmt := range query.Statements {
@@ -26,7 +26,7 @@ func ApplyAggregators(log restql.Logger, query domain.Query, resources domain.Re
 		targetResourceID := domain.ResourceID(target)
 		targetResource := resources[targetResourceID]
 
-		err := aggregateOriginOnTarget(path, originResource, targetResource)
+		err := aggregateOriginOnTarget(path, stmt.Join, originResource, targetResource)
 		if err != nil {
 			log.Error("an error occurred when aggregating the resources", err)
 			continue
@@ -37,16 +37,28 @@ func ApplyAggregators(log restql.Logger, query domain.Query, resources domain.Re
 	return resources
 }
 
-func aggregateOriginOnTarget(path []string, origin interface{}, target interface{}) error {
+func aggregateOriginOnTarget(path []string, join *domain.Join, origin interface{}, target interface{}) error {
 	switch target := target.(type) {
 	case restql.DoneResource:
 		body := target.ResponseBody.Unmarshal()
-		return aggregateOriginOnTarget(path, origin, body)
+		return aggregateOriginOnTarget(path, join, origin, body)
 	case restql.DoneResources:
-		return aggregateOriginOnListTarget(path, origin, target)
+		return aggregateOriginOnListTarget(path, join, origin, target)
 	case []interface{}:
-		return aggregateOriginOnListTarget(path, origin, target)
+		return aggregateOriginOnListTarget(path, join, origin, target)
 	case map[string]interface{}:
+		if len(path) <= 1 && join != nil {
+			joined, found := joinOrigin(join, origin, target)
+			if !found {
+				return nil
+			}
+			origin = joined
+		}
+
+		if len(path) == 0 {
+			return joinInObject(target, parseOrigin(origin))
+		}
+
 		field := path[0]
 
 		nextTarget, targetFieldExist := target[field]
@@ -56,7 +68,7 @@ func aggregateOriginOnTarget(path []string, origin interface{}, target interface
 		}
 
 		if len(path) > 1 {
-			return aggregateOriginOnTarget(path[1:], origin, nextTarget)
+			return aggregateOriginOnTarget(path[1:], join, origin, nextTarget)
 		}
 
 		originValue := parseOrigin(origin)
@@ -99,6 +111,17 @@ func mergeInObject(target map[string]interface{}, origin interface{}) (interface
 	}
 }
 
+// joinInObject adds the origin fields to the target, keeping
+// the target values of the fields present in both.
+func joinInObject(target map[string]interface{}, origin interface{}) error {
+	o, ok := origin.(map[string]interface{})
+	if !ok {
+		return errors.Errorf("invalid origin type for join into object: %T", origin)
+	}
+
+	return mergo.Merge(&target, o)
+}
+
 func mergeInList(target []interface{}, origin interface{}) (interface{}, error) {
 	switch origin := origin.(type) {
 	case []interface{}:
@@ -138,15 +161,33 @@ func max(a int, b int) int {
 	return b
 }
 
-func aggregateOriginOnListTarget(path []string, origin interface{}, target []interface{}) error {
+// aggregateOriginOnListTarget zips a multiplexed origin into the
+// target elements by position, unless the statement joins them by
+// key, when every element is matched against the whole origin.
+func aggregateOriginOnListTarget(path []string, join *domain.Join, origin interface{}, target []interface{}) error {
+	if join != nil {
+		var err error
+		for _, t := range target {
+			aggregateErr := aggregateOriginOnTarget(path, join, origin, t)
+			if aggregateErr != nil {
+				err = fmt.Errorf("failed to aggregate joined resource into target: %v\n%w", aggregateErr, err)
+			}
+		}
+		return err
+	}
+
 	switch origin := origin.(type) {
 	case restql.DoneResource:
 		body := origin.ResponseBody.Unmarshal()
-		return aggregateOriginOnTarget(path, body, target)
+		return aggregateOriginOnTarget(path, join, body, target)
 	case restql.DoneResources:
 		var err error
 		for i, t := range target {
-			aggregateErr := aggregateOriginOnTarget(path, origin[i], t)
+			if i >= len(origin) {
+				break
+			}
+
+			aggregateErr := aggregateOriginOnTarget(path, join, origin[i], t)
 			if aggregateErr != nil {
 				err = fmt.Errorf("failed to aggregate multiplexed resource into target: %v\n%w", aggregateErr, err)
 			}
@@ -155,7 +196,11 @@ func aggregateOriginOnListTarget(path []string, origin interface{}, target []int
 	case []interface{}:
 		var err error
 		for i, t := range target {
-			aggregateErr := aggregateOriginOnTarget(path, origin[i], t)
+			if i >= len(origin) {
+				break
+			}
+
+			aggregateErr := aggregateOriginOnTarget(path, join, origin[i], t)
 			if aggregateErr != nil {
 				err = fmt.Errorf("failed to aggregate multiplexed resource into target: %v\n%w", aggregateErr, err)
 			}
@@ -164,7 +209,7 @@ func aggregateOriginOnListTarget(path []string, origin interface{}, target []int
 	default:
 		var err error
 		for _, t := range target {
-			aggregateErr := aggregateOriginOnTarget(path, origin, t)
+			aggregateErr := aggregateOriginOnTarget(path, join, origin, t)
 			if aggregateErr != nil {
 				err = fmt.Errorf("failed to aggregate multiplexed resource into target: %v\n%w", aggregateErr, err)
 			}
@@ -173,6 +218,54 @@ func aggregateOriginOnListTarget(path []string, origin interface{}, target []int
 	}
 }
 
+// joinOrigin returns the origin value whose join key equals the
+// key of the target element, looking into every response of a
+// multiplexed origin and every element of a list body.
+func joinOrigin(join *domain.Join, origin interface{}, target map[string]interface{}) (interface{}, bool) {
+	key, found := valueAtPath(target, join.TargetKey)
+	if !found {
+		return nil, false
+	}
+
+	for _, candidate := range joinCandidates(parseOrigin(origin)) {
+		candidateKey, found := valueAtPath(candidate, join.OriginKey)
+		if found && equals(candidateKey, key) {
+			return candidate, true
+		}
+	}
+
+	return nil, false
+}
+
+func joinCandidates(origin interface{}) []interface{} {
+	list, ok := origin.([]interface{})
+	if !ok {
+		return []interface{}{origin}
+	}
+
+	var result []interface{}
+	for _, o := range list {
+		result = append(result, joinCandidates(o)...)
+	}
+	return result
+}
+
+func valueAtPath(value interface{}, path []string) (interface{}, bool) {
+	for _, p := range path {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		value, ok = object[p]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return value, value != nil
+}
+
 func parseOrigin(origin interface{}) interface{} {
 	switch origin := origin.(type) {
 	case restql.DoneResource:
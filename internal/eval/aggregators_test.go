@@ -316,6 +316,108 @@ func TestApplyAggregators(t *testing.T) {
 				"sidekick": restql.DoneResource{ResponseBody: &restql.ResponseBody{}},
 			},
 		},
+		{
+			"should zip multiplexed resource into nested list elements by position",
+			domain.Query{Statements: []domain.Statement{
+				{Resource: "cart"},
+				{Resource: "product", In: []string{"cart", "items", "product"}},
+			}},
+			domain.Resources{
+				"cart": restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(
+					test.NoOpLogger,
+					test.Unmarshal(`{ "id": 1, "items": [{ "sku": "a" }, { "sku": "b" }, { "sku": "c" }] }`),
+				)},
+				"product": restql.DoneResources{
+					restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{ "name": "rope" }`))},
+					restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{ "name": "belt" }`))},
+				},
+			},
+			domain.Resources{
+				"cart": restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(
+					test.NoOpLogger,
+					test.Unmarshal(`{ "id": 1, "items": [{ "sku": "a", "product": { "name": "rope" } }, { "sku": "b", "product": { "name": "belt" } }, { "sku": "c" }] }`),
+				)},
+				"product": restql.DoneResources{
+					restql.DoneResource{ResponseBody: &restql.ResponseBody{}},
+					restql.DoneResource{ResponseBody: &restql.ResponseBody{}},
+				},
+			},
+		},
+		{
+			"should join multiplexed resource into nested list elements by key",
+			domain.Query{Statements: []domain.Statement{
+				{Resource: "cart"},
+				{Resource: "product", In: []string{"cart", "items", "product"}, Join: &domain.Join{TargetKey: []string{"sku"}, OriginKey: []string{"id"}}},
+			}},
+			domain.Resources{
+				"cart": restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(
+					test.NoOpLogger,
+					test.Unmarshal(`{ "id": 1, "items": [{ "sku": 3 }, { "sku": 1 }, { "sku": 7 }] }`),
+				)},
+				"product": restql.DoneResources{
+					restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{ "id": "1", "name": "rope" }`))},
+					restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`[{ "id": 2, "name": "car" }, { "id": 3, "name": "belt" }]`))},
+				},
+			},
+			domain.Resources{
+				"cart": restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(
+					test.NoOpLogger,
+					test.Unmarshal(`{ "id": 1, "items": [{ "sku": 3, "product": { "id": 3, "name": "belt" } }, { "sku": 1, "product": { "id": "1", "name": "rope" } }, { "sku": 7 }] }`),
+				)},
+				"product": restql.DoneResources{
+					restql.DoneResource{ResponseBody: &restql.ResponseBody{}},
+					restql.DoneResource{ResponseBody: &restql.ResponseBody{}},
+				},
+			},
+		},
+		{
+			"should join resource into object target by key",
+			domain.Query{Statements: []domain.Statement{
+				{Resource: "hero"},
+				{Resource: "sidekick", In: []string{"hero", "sidekick"}, Join: &domain.Join{TargetKey: []string{"partner", "id"}, OriginKey: []string{"id"}}},
+			}},
+			domain.Resources{
+				"hero": restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(
+					test.NoOpLogger,
+					test.Unmarshal(`{ "name": "batman", "partner": { "id": 11 } }`),
+				)},
+				"sidekick": restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(
+					test.NoOpLogger,
+					test.Unmarshal(`[{ "id": 10, "name": "robin" }, { "id": 11, "name": "batgirl" }]`),
+				)},
+			},
+			domain.Resources{
+				"hero": restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(
+					test.NoOpLogger,
+					test.Unmarshal(`{ "name": "batman", "partner": { "id": 11 }, "sidekick": { "id": 11, "name": "batgirl" } }`),
+				)},
+				"sidekick": restql.DoneResource{ResponseBody: &restql.ResponseBody{}},
+			},
+		},
+		{
+			"should join resource into target list elements by key",
+			domain.Query{Statements: []domain.Statement{
+				{Resource: "products"},
+				{Resource: "stock", In: []string{"products"}, Join: &domain.Join{TargetKey: []string{"id"}, OriginKey: []string{"productId"}}},
+			}},
+			domain.Resources{
+				"products": restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(
+					test.NoOpLogger,
+					test.Unmarshal(`[{ "id": 1, "name": "rope" }, { "id": 2, "name": "belt" }]`),
+				)},
+				"stock": restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(
+					test.NoOpLogger,
+					test.Unmarshal(`[{ "productId": 2, "name": "warehouse", "quantity": 5 }, { "productId": 3, "quantity": 1 }]`),
+				)},
+			},
+			domain.Resources{
+				"products": restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(
+					test.NoOpLogger,
+					test.Unmarshal(`[{ "id": 1, "name": "rope" }, { "id": 2, "name": "belt", "productId": 2, "quantity": 5 }]`),
+				)},
+				"stock": restql.DoneResource{ResponseBody: &restql.ResponseBody{}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,148 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+)
+
+// resolveFilterChains replaces the chained arguments of the
+// comparisons in the filters by their values in the done
+// resources. Chains that cannot be resolved are kept, so
+// the comparison does not select any value.
+func resolveFilterChains(only []interface{}, resources domain.Resources) []interface{} {
+	if only == nil {
+		return nil
+	}
+
+	result := make([]interface{}, len(only))
+	for i, filter := range only {
+		if fn, ok := filter.(domain.Function); ok {
+			result[i] = resolveCompareChains(fn, resources)
+		} else {
+			result[i] = filter
+		}
+	}
+
+	return result
+}
+
+func resolveCompareChains(fn domain.Function, resources domain.Resources) domain.Function {
+	if inner, ok := fn.Target().(domain.Function); ok {
+		resolved := resolveCompareChains(inner, resources)
+		fn = fn.Map(func(target interface{}) interface{} { return resolved })
+	}
+
+	c, ok := fn.(domain.Compare)
+	if !ok {
+		return fn
+	}
+
+	chain, ok := c.Arg.(domain.Chain)
+	if !ok {
+		return fn
+	}
+
+	if value, found := runner.ChainedValue(chain, resources); found {
+		c.Arg = value
+	}
+	return c
+}
+
+// applyCompareFilter keeps the field in the node if its value
+// satisfies the comparison or, for lists, only the elements
+// that satisfy it, removing the field when none does.
+func applyCompareFilter(c domain.Compare, key string, value interface{}, node map[string]interface{}) {
+	list, isList := value.([]interface{})
+	if !isList {
+		if compare(c.Operator, value, c.Arg) {
+			node[key] = value
+		} else {
+			delete(node, key)
+		}
+		return
+	}
+
+	var result []interface{}
+	for _, v := range list {
+		if compare(c.Operator, v, c.Arg) {
+			result = append(result, v)
+		}
+	}
+
+	if len(result) > 0 {
+		node[key] = result
+	} else {
+		delete(node, key)
+	}
+}
+
+func compare(operator string, value interface{}, arg interface{}) bool {
+	if _, unresolved := arg.(domain.Chain); unresolved {
+		return false
+	}
+
+	switch operator {
+	case domain.CompareEquals:
+		return equals(value, arg)
+	case domain.CompareGreaterThan, domain.CompareLessThan:
+		v, vok := toNumber(value)
+		a, aok := toNumber(arg)
+		if !vok || !aok {
+			return false
+		}
+		if operator == domain.CompareGreaterThan {
+			return v > a
+		}
+		return v < a
+	case domain.CompareAfter, domain.CompareBefore:
+		v, vok := domain.ParseDate(value)
+		a, aok := domain.ParseDate(arg)
+		if !vok || !aok {
+			return false
+		}
+		if operator == domain.CompareAfter {
+			return v.After(a)
+		}
+		return v.Before(a)
+	default:
+		return false
+	}
+}
+
+// equals compares numbers by their value, even when one of
+// them is given as a string, like a query parameter, and any
+// other value by its textual representation.
+func equals(value interface{}, arg interface{}) bool {
+	_, valueIsString := value.(string)
+	_, argIsString := arg.(string)
+	if !valueIsString || !argIsString {
+		v, vok := toNumber(value)
+		a, aok := toNumber(arg)
+		if vok && aok {
+			return v == a
+		}
+	}
+
+	return fmt.Sprintf("%v", value) == fmt.Sprintf("%v", arg)
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case int:
+		return float64(value), true
+	case json.Number:
+		f, err := value.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(value, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,189 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// ApplyComputedFields resolves the `compute` clause in the query,
+// setting the computed fields on the statement result, which must
+// be a successful response with an object body. Paths starting with
+// a statement of the query take its values, otherwise the values
+// come from the statement own result.
+func ApplyComputedFields(query domain.Query, resources domain.Resources) domain.Resources {
+	statements := make(map[string]bool, len(query.Statements))
+	for _, stmt := range query.Statements {
+		statements[string(domain.NewResourceID(stmt))] = true
+	}
+
+	for _, stmt := range query.Statements {
+		if len(stmt.Compute) == 0 {
+			continue
+		}
+
+		resourceID := domain.NewResourceID(stmt)
+		resources[resourceID] = computeFields(stmt.Compute, statements, resources[resourceID], resources)
+	}
+
+	return resources
+}
+
+func computeFields(fields []domain.ComputedField, statements map[string]bool, result interface{}, resources domain.Resources) interface{} {
+	switch result := result.(type) {
+	case restql.DoneResource:
+		if result.Status < 200 || result.Status >= 400 || result.ResponseBody == nil {
+			return result
+		}
+
+		body, ok := result.ResponseBody.Unmarshal().(map[string]interface{})
+		if !ok {
+			return result
+		}
+
+		for _, field := range fields {
+			values := computedFieldValues(field.Path, statements, result, resources)
+			value, found := aggregate(field, values)
+			if field.Expression != nil {
+				value, found = computeExpression(field, value, found)
+			}
+			if found {
+				body[field.Name] = value
+			}
+		}
+		result.ResponseBody.SetValue(body)
+
+		return result
+	case restql.DoneResources:
+		list := make(restql.DoneResources, len(result))
+		for i, r := range result {
+			list[i] = computeFields(fields, statements, r, resources)
+		}
+		return list
+	default:
+		return result
+	}
+}
+
+// ownResult is the identifier under which the statement
+// own result is looked up, which cannot clash with the
+// statements since it is not a valid resource name.
+const ownResult = "@self"
+
+func computedFieldValues(path []string, statements map[string]bool, result restql.DoneResource, resources domain.Resources) interface{} {
+	var chain domain.Chain
+	source := resources
+	if !statements[path[0]] {
+		chain = append(chain, ownResult)
+		source = domain.Resources{ownResult: result}
+	}
+
+	for _, p := range path {
+		chain = append(chain, p)
+	}
+
+	value, found := runner.ChainedValue(chain, source)
+	if !found {
+		return nil
+	}
+
+	return value
+}
+
+// aggregate reduces the values found for the computed field
+// by its aggregator, ignoring the values that cannot be used
+// by it. Fields without aggregator keep the values as found.
+func aggregate(field domain.ComputedField, value interface{}) (interface{}, bool) {
+	values := flattenValues(value)
+
+	switch field.Aggregator {
+	case "":
+		return value, value != nil
+	case domain.CountAggregator:
+		return float64(len(values)), true
+	case domain.ConcatAggregator:
+		texts := make([]string, len(values))
+		for i, v := range values {
+			texts[i] = fmt.Sprintf("%v", v)
+		}
+		return strings.Join(texts, field.Separator), true
+	}
+
+	var numbers []float64
+	for _, v := range values {
+		if n, ok := toNumber(v); ok {
+			numbers = append(numbers, n)
+		}
+	}
+
+	if field.Aggregator == domain.SumAggregator {
+		var sum float64
+		for _, n := range numbers {
+			sum += n
+		}
+		return sum, true
+	}
+
+	if len(numbers) == 0 {
+		return nil, false
+	}
+
+	result := numbers[0]
+	for _, n := range numbers[1:] {
+		switch field.Aggregator {
+		case domain.AvgAggregator:
+			result += n
+		case domain.MinAggregator:
+			if n < result {
+				result = n
+			}
+		case domain.MaxAggregator:
+			if n > result {
+				result = n
+			}
+		}
+	}
+
+	if field.Aggregator == domain.AvgAggregator {
+		result = result / float64(len(numbers))
+	}
+
+	return result, true
+}
+
+// computeExpression transforms the computed value by the field
+// expression, where a value not found is null. The field is left
+// out when the expression fails or results in null.
+func computeExpression(field domain.ComputedField, value interface{}, found bool) (interface{}, bool) {
+	if !found {
+		value = nil
+	}
+
+	result, err := field.Expression.Eval(value, field.Variables)
+	if err != nil {
+		return nil, false
+	}
+
+	return result, result != nil
+}
+
+func flattenValues(value interface{}) []interface{} {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var result []interface{}
+		for _, v := range value {
+			result = append(result, flattenValues(v)...)
+		}
+		return result
+	default:
+		if value == runner.EmptyChained {
+			return nil
+		}
+		return []interface{}{value}
+	}
+}
@@ -0,0 +1,101 @@
+package eval_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestApplyComputedFields(t *testing.T) {
+	hero := `{ "name": "batman", "items": [{ "name": "rope", "price": 10 }, { "name": "belt", "price": "30.5" }, { "name": "car" }] }`
+	sidekicks := `[{ "name": "robin", "age": 20 }, { "name": "batgirl", "age": 18 }]`
+
+	tests := []struct {
+		name     string
+		compute  []domain.ComputedField
+		expected interface{}
+	}{
+		{
+			"should do nothing if there is no computed field",
+			nil,
+			test.Unmarshal(hero),
+		},
+		{
+			"should copy value when there is no aggregator",
+			[]domain.ComputedField{{Name: "itemNames", Path: []string{"items", "name"}}, {Name: "unknown", Path: []string{"address", "city"}}},
+			test.Unmarshal(`{ "name": "batman", "items": [{ "name": "rope", "price": 10 }, { "name": "belt", "price": "30.5" }, { "name": "car" }], "itemNames": ["rope", "belt", "car"] }`),
+		},
+		{
+			"should aggregate numbers of own result",
+			[]domain.ComputedField{
+				{Name: "total", Path: []string{"items", "price"}, Aggregator: domain.SumAggregator},
+				{Name: "average", Path: []string{"items", "price"}, Aggregator: domain.AvgAggregator},
+				{Name: "cheapest", Path: []string{"items", "price"}, Aggregator: domain.MinAggregator},
+				{Name: "priciest", Path: []string{"items", "price"}, Aggregator: domain.MaxAggregator},
+				{Name: "items", Path: []string{"items"}, Aggregator: domain.CountAggregator},
+			},
+			test.Unmarshal(`{ "name": "batman", "items": 3, "total": 40.5, "average": 20.25, "cheapest": 10, "priciest": 30.5 }`),
+		},
+		{
+			"should aggregate values of other statement",
+			[]domain.ComputedField{
+				{Name: "sidekicks", Path: []string{"sidekick", "name"}, Aggregator: domain.ConcatAggregator, Separator: ", "},
+				{Name: "oldest", Path: []string{"sidekick", "age"}, Aggregator: domain.MaxAggregator},
+				{Name: "count", Path: []string{"sidekick"}, Aggregator: domain.CountAggregator},
+			},
+			test.Unmarshal(`{ "name": "batman", "items": [{ "name": "rope", "price": 10 }, { "name": "belt", "price": "30.5" }, { "name": "car" }], "sidekicks": "robin, batgirl", "oldest": 20, "count": 2 }`),
+		},
+		{
+			"should not set field when there is no value to aggregate",
+			[]domain.ComputedField{
+				{Name: "average", Path: []string{"items", "name"}, Aggregator: domain.AvgAggregator},
+				{Name: "total", Path: []string{"weapons", "price"}, Aggregator: domain.SumAggregator},
+			},
+			test.Unmarshal(`{ "name": "batman", "items": [{ "name": "rope", "price": 10 }, { "name": "belt", "price": "30.5" }, { "name": "car" }], "total": 0 }`),
+		},
+		{
+			"should transform computed value by expression",
+			[]domain.ComputedField{
+				{Name: "label", Path: []string{"name"}, Expression: test.Program("upper(value) + ' ' + $edition"), Variables: map[string]interface{}{"edition": "begins"}},
+				{Name: "total", Path: []string{"items", "price"}, Aggregator: domain.SumAggregator, Expression: test.Program("value > 40 ? 'high' : 'low'")},
+				{Name: "city", Path: []string{"address", "city"}, Expression: test.Program("default(value, 'gotham')")},
+				{Name: "invalid", Path: []string{"name"}, Expression: test.Program("value * 2")},
+			},
+			test.Unmarshal(`{ "name": "batman", "items": [{ "name": "rope", "price": 10 }, { "name": "belt", "price": "30.5" }, { "name": "car" }], "label": "BATMAN begins", "total": "high", "city": "gotham" }`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := domain.Query{Statements: []domain.Statement{{Resource: "hero", Compute: tt.compute}, {Resource: "sidekick"}}}
+			resources := domain.Resources{
+				"hero":     restql.DoneResource{Status: http.StatusOK, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(hero))},
+				"sidekick": restql.DoneResource{Status: http.StatusOK, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(sidekicks))},
+			}
+
+			got := eval.ApplyComputedFields(query, resources)
+
+			test.Equal(t, got["hero"].(restql.DoneResource).ResponseBody.Unmarshal(), tt.expected)
+		})
+	}
+}
+
+func TestApplyComputedFieldsOnMultiplexedAndFailedResults(t *testing.T) {
+	query := domain.Query{Statements: []domain.Statement{{Resource: "hero", Compute: []domain.ComputedField{{Name: "total", Path: []string{"weapons"}, Aggregator: domain.CountAggregator}}}}}
+	resources := domain.Resources{
+		"hero": restql.DoneResources{
+			restql.DoneResource{Status: http.StatusOK, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{ "weapons": ["rope", "belt"] }`))},
+			restql.DoneResource{Status: http.StatusNotFound, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{ "message": "not found" }`))},
+		},
+	}
+
+	got := eval.ApplyComputedFields(query, resources)
+
+	list := got["hero"].(restql.DoneResources)
+	test.Equal(t, list[0].(restql.DoneResource).ResponseBody.Unmarshal(), test.Unmarshal(`{ "weapons": ["rope", "belt"], "total": 2 }`))
+	test.Equal(t, list[1].(restql.DoneResource).ResponseBody.Unmarshal(), test.Unmarshal(`{ "message": "not found" }`))
+}
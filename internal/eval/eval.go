@@ -13,9 +13,11 @@ type MappingsReader interface {
 }
 
 // QueryReader is an interface implemented by types that
-// can fetch a query for the given identification (namespace, id, revision).
+// can fetch a query for the given identification (namespace, id, revision)
+// or list all the revisions of a query.
 type QueryReader interface {
 	Get(ctx context.Context, namespace, id string, revision int) (restql.SavedQuery, error)
+	ListQueryRevisions(ctx context.Context, namespace, id string) ([]restql.SavedQuery, error)
 }
 
 // ErrValidation is returned by Evaluator when
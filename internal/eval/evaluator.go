@@ -3,6 +3,7 @@ package eval
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
 	"github.com/b2wdigital/restQL-golang/v4/internal/parser"
@@ -29,17 +30,23 @@ type Evaluator struct {
 	queryReader    QueryReader
 	runner         runner.Runner
 	lifecycle      plugins.Lifecycle
+	recorder       *ExecutionRecorder
+
+	failOnHiddenErrors bool
 }
 
 // NewEvaluator constructs an instance of the restQL interpreter.
-func NewEvaluator(log restql.Logger, mr MappingsReader, qr QueryReader, r runner.Runner, p parser.Parser, l plugins.Lifecycle) Evaluator {
+// Saved query executions are only recorded when a recorder is given.
+func NewEvaluator(log restql.Logger, mr MappingsReader, qr QueryReader, r runner.Runner, p parser.Parser, l plugins.Lifecycle, failOnHiddenErrors bool, recorder *ExecutionRecorder) Evaluator {
 	return Evaluator{
-		log:            log,
-		mappingsReader: mr,
-		queryReader:    qr,
-		runner:         r,
-		parser:         p,
-		lifecycle:      l,
+		log:                log,
+		mappingsReader:     mr,
+		queryReader:        qr,
+		runner:             r,
+		parser:             p,
+		lifecycle:          l,
+		recorder:           recorder,
+		failOnHiddenErrors: failOnHiddenErrors,
 	}
 }
 
@@ -50,7 +57,28 @@ func (e Evaluator) AdHocQuery(ctx context.Context, queryTxt string, queryOpts re
 		return nil, fmt.Errorf("%w: %s", ErrValidation, errInvalidTenant)
 	}
 
-	return e.evaluateQuery(ctx, queryTxt, queryOpts, queryInput)
+	return e.evaluateQuery(ctx, queryTxt, queryOpts, queryInput, nil, nil)
+}
+
+// StatementObserver receives the result of each visible statement,
+// with its filters applied, as soon as the statement is done.
+type StatementObserver func(resourceID domain.ResourceID, resource interface{})
+
+// ItemObserver receives each result of the visible multiplexed
+// statements set as unordered, with its filters applied and its
+// index in the statement result, as soon as its request is done.
+type ItemObserver func(resourceID domain.ResourceID, index int, resource interface{})
+
+// StreamAdHocQuery executes an ad-hoc query like AdHocQuery,
+// notifying the observer as each statement result is available
+// and the item observer as each item of the unordered multiplexed
+// statements is available, in place of the whole statement.
+func (e Evaluator) StreamAdHocQuery(ctx context.Context, queryTxt string, queryOpts restql.QueryOptions, queryInput restql.QueryInput, observer StatementObserver, itemObserver ItemObserver) (domain.Resources, error) {
+	if queryOpts.Tenant == "" {
+		return nil, fmt.Errorf("%w: %s", ErrValidation, errInvalidTenant)
+	}
+
+	return e.evaluateQuery(ctx, queryTxt, queryOpts, queryInput, observer, itemObserver)
 }
 
 // SavedQuery executes a saved query identified by namespace,
@@ -62,7 +90,7 @@ func (e Evaluator) SavedQuery(ctx context.Context, queryOpts restql.QueryOptions
 		return nil, err
 	}
 
-	savedQuery, err := e.queryReader.Get(ctx, queryOpts.Namespace, queryOpts.Id, queryOpts.Revision)
+	savedQuery, err := e.savedQuery(ctx, queryOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -70,10 +98,27 @@ func (e Evaluator) SavedQuery(ctx context.Context, queryOpts restql.QueryOptions
 	log := restql.GetLogger(ctx)
 	log.Debug("Saved query retrieved", "query", savedQuery)
 
-	return e.evaluateQuery(ctx, savedQuery.Text, queryOpts, queryInput)
+	return e.evaluateQuery(ctx, savedQuery.Text, queryOpts, queryInput, nil, nil)
+}
+
+// savedQuery fetches the saved query identified by the options,
+// which is not found when its namespace is not available to the
+// tenant.
+func (e Evaluator) savedQuery(ctx context.Context, queryOpts restql.QueryOptions) (restql.SavedQuery, error) {
+	if !e.runner.AllowsNamespace(queryOpts.Tenant, queryOpts.Namespace) {
+		return restql.SavedQuery{}, fmt.Errorf("%w: %s is not available for tenant %s", restql.ErrNamespaceNotFound, queryOpts.Namespace, queryOpts.Tenant)
+	}
+
+	return e.queryReader.Get(ctx, queryOpts.Namespace, queryOpts.Id, queryOpts.Revision)
 }
 
-func (e Evaluator) evaluateQuery(ctx context.Context, queryTxt string, queryOpts restql.QueryOptions, queryInput restql.QueryInput) (domain.Resources, error) {
+// ExplainQuery resolves the execution plan of an ad-hoc query,
+// with the defaults applied to each statement, without running it.
+func (e Evaluator) ExplainQuery(ctx context.Context, queryTxt string, queryOpts restql.QueryOptions, queryInput restql.QueryInput) ([]runner.StatementPlan, error) {
+	if queryOpts.Tenant == "" {
+		return nil, fmt.Errorf("%w: %s", ErrValidation, errInvalidTenant)
+	}
+
 	log := restql.GetLogger(ctx)
 
 	query, err := e.parser.Parse(queryTxt)
@@ -88,51 +133,303 @@ func (e Evaluator) evaluateQuery(ctx context.Context, queryTxt string, queryOpts
 		return nil, err
 	}
 
-	err = validateQueryResources(query, mappings)
+	err = validateQueryResources(query, mappings, e.mockedResources(queryOpts.Tenant))
+	if err != nil {
+		log.Error("query reference invalid resource", err, "mappings", fmt.Sprintf("%#v", mappings))
+		return nil, err
+	}
+
+	queryContext := restql.QueryContext{
+		Mappings: mappings,
+		Options:  queryOpts,
+		Input:    queryInput,
+	}
+
+	query = ResolveVariables(query, queryContext.Input)
+
+	return e.runner.PlanQuery(query, queryContext), nil
+}
+
+// DryRunQuery resolves the requests each statement of an ad-hoc query
+// would make, with the values known before execution, without calling
+// any upstream.
+func (e Evaluator) DryRunQuery(ctx context.Context, queryTxt string, queryOpts restql.QueryOptions, queryInput restql.QueryInput) ([]runner.StatementDryRun, error) {
+	if queryOpts.Tenant == "" {
+		return nil, fmt.Errorf("%w: %s", ErrValidation, errInvalidTenant)
+	}
+
+	return e.dryRun(ctx, queryTxt, queryOpts, queryInput)
+}
+
+// DryRunSavedQuery resolves the requests each statement of a saved
+// query would make, as DryRunQuery.
+func (e Evaluator) DryRunSavedQuery(ctx context.Context, queryOpts restql.QueryOptions, queryInput restql.QueryInput) ([]runner.StatementDryRun, error) {
+	err := validateQueryOptions(queryOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	savedQuery, err := e.savedQuery(ctx, queryOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.dryRun(ctx, savedQuery.Text, queryOpts, queryInput)
+}
+
+// QueryGraph returns the execution graph of a saved query, showing
+// which statements run in parallel and which chain on others. It does
+// not depend on the tenant mappings nor on the query input.
+func (e Evaluator) QueryGraph(ctx context.Context, queryOpts restql.QueryOptions) (runner.QueryGraph, error) {
+	log := restql.GetLogger(ctx)
+
+	err := validateQueryRevision(queryOpts)
+	if err != nil {
+		return runner.QueryGraph{}, err
+	}
+
+	savedQuery, err := e.queryReader.Get(ctx, queryOpts.Namespace, queryOpts.Id, queryOpts.Revision)
+	if err != nil {
+		return runner.QueryGraph{}, err
+	}
+
+	query, err := e.parser.Parse(savedQuery.Text)
+	if err != nil {
+		log.Debug("failed to parse query", "error", err)
+		return runner.QueryGraph{}, fmt.Errorf("%w: invalid query syntax %s", ErrParser, err)
+	}
+
+	graph, err := runner.BuildQueryGraph(query)
+	switch {
+	case errors.Is(err, runner.ErrInvalidChainedParameter):
+		return runner.QueryGraph{}, fmt.Errorf("%w: %s", ErrParser, err)
+	case errors.Is(err, runner.ErrChainCycle):
+		return runner.QueryGraph{}, fmt.Errorf("%w: %s", ErrValidation, err)
+	case err != nil:
+		return runner.QueryGraph{}, err
+	}
+
+	return graph, nil
+}
+
+func (e Evaluator) dryRun(ctx context.Context, queryTxt string, queryOpts restql.QueryOptions, queryInput restql.QueryInput) ([]runner.StatementDryRun, error) {
+	log := restql.GetLogger(ctx)
+
+	query, err := e.parser.Parse(queryTxt)
+	if err != nil {
+		log.Debug("failed to parse query", "error", err)
+		return nil, fmt.Errorf("%w: invalid query syntax %s", ErrParser, err)
+	}
+
+	mappings, err := e.mappingsReader.FromTenant(ctx, queryOpts.Tenant)
+	if err != nil {
+		log.Error("failed to fetch mappings", err)
+		return nil, err
+	}
+
+	err = validateQueryResources(query, mappings, e.mockedResources(queryOpts.Tenant))
+	if err != nil {
+		log.Error("query reference invalid resource", err, "mappings", fmt.Sprintf("%#v", mappings))
+		return nil, err
+	}
+
+	queryContext := restql.QueryContext{
+		Mappings: mappings,
+		Options:  queryOpts,
+		Input:    queryInput,
+	}
+
+	query = ResolveVariables(query, queryContext.Input)
+
+	statements, err := e.runner.DryRunQuery(query, queryContext)
+	switch {
+	case errors.Is(err, runner.ErrInvalidChainedParameter):
+		return nil, fmt.Errorf("%w: %s", ErrParser, err)
+	case errors.Is(err, runner.ErrChainCycle), errors.Is(err, runner.ErrChainTooDeep), errors.Is(err, runner.ErrPolicyViolation):
+		return nil, fmt.Errorf("%w: %s", ErrValidation, err)
+	case err != nil:
+		return nil, err
+	}
+
+	return statements, nil
+}
+
+func (e Evaluator) evaluateQuery(ctx context.Context, queryTxt string, queryOpts restql.QueryOptions, queryInput restql.QueryInput, observer StatementObserver, itemObserver ItemObserver) (domain.Resources, error) {
+	_, nested := ctx.Value(subqueryPathKey{}).([]string)
+	ctx, log := withQueryLogger(ctx, queryOpts)
+
+	ctx, err := withSubqueryPath(ctx, queryOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := e.parser.Parse(queryTxt)
+	if err != nil {
+		log.Debug("failed to parse query", "error", err)
+		return nil, fmt.Errorf("%w: invalid query syntax %s", ErrParser, err)
+	}
+
+	mappings, err := e.mappingsReader.FromTenant(ctx, queryOpts.Tenant)
+	if err != nil {
+		log.Error("failed to fetch mappings", err)
+		return nil, err
+	}
+
+	err = validateQueryResources(query, mappings, e.mockedResources(queryOpts.Tenant))
 	if err != nil {
 		log.Error("query reference invalid resource", err, "mappings", fmt.Sprintf("%#v", mappings))
 		return nil, err
 	}
 
+	err = validateSubqueries(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.runner.Strict(queryOpts.Tenant, query.Use) {
+		err = validateStrictInput(query, queryInput)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	addWarnings(ctx, query.Warnings...)
+	addWarnings(ctx, unusedHiddenWarnings(query)...)
+	markPassThrough(ctx, query)
+
 	queryContext := restql.QueryContext{
 		Mappings: mappings,
 		Options:  queryOpts,
 		Input:    queryInput,
 	}
 
+	ctx = runner.WithSubqueryRunner(ctx, e.runSubquery)
 	queryCtx := e.lifecycle.BeforeQuery(ctx, queryTxt, queryContext)
 
 	query = ResolveVariables(query, queryContext.Input)
 
+	if observer != nil {
+		so := newStreamObserver(log, query)
+		queryCtx = runner.WithDoneObserver(queryCtx, so.observeStatements(observer))
+		if itemObserver != nil {
+			queryCtx = runner.WithItemObserver(queryCtx, so.observeItems(itemObserver))
+		}
+	}
+
+	start := time.Now()
 	resources, err := e.runner.ExecuteQuery(queryCtx, query, queryContext)
+	if !nested {
+		publishQueryFinished(queryCtx, queryOpts, queryContext.Input, resources, err, start)
+	}
+
 	switch {
 	case err == runner.ErrQueryTimedOut:
 		return nil, fmt.Errorf("%w: %s", ErrTimeout, err)
 	case errors.Is(err, runner.ErrInvalidChainedParameter):
 		return nil, fmt.Errorf("%w: %s", ErrParser, err)
+	case errors.Is(err, runner.ErrChainCycle), errors.Is(err, runner.ErrChainTooDeep), errors.Is(err, runner.ErrMissingChainedValue), errors.Is(err, runner.ErrPolicyViolation):
+		return nil, fmt.Errorf("%w: %s", ErrValidation, err)
 	case err != nil:
 		return nil, err
 	}
 
+	e.recorder.record(log, queryOpts, queryTxt, queryInput, resources)
+
+	resources = ApplyResultFunctions(log, query, resources)
 	resources, err = ApplyFilters(log, query, resources)
 	if err != nil {
 		log.Error("failed to apply filters", err, "input", fmt.Sprintf("%+#v", queryContext.Input))
 		return nil, err
 	}
+	addWarnings(ctx, FilterMisses(query, resources)...)
 
-	resources = ApplyAggregators(nil, query, resources)
+	resources = ApplyComputedFields(query, resources)
+	resources = ApplyAggregators(log, query, resources)
+	resources, err = ApplyProjection(log, query, resources, e.failOnHiddenErrors)
+	if err != nil {
+		log.Error("failed to apply query filters", err, "input", fmt.Sprintf("%+#v", queryContext.Input))
+		return nil, err
+	}
 
 	e.lifecycle.AfterQuery(queryCtx, queryTxt, resources)
 
-	resources = ApplyHidden(query, resources)
+	resources = ApplyHidden(query, resources, e.failOnHiddenErrors)
 
 	return resources, nil
 }
 
-func validateQueryResources(query domain.Query, mappings map[string]restql.Mapping) error {
+// withQueryLogger stores in the context a logger carrying the
+// identification of the query, which is inherited by the logs of
+// every statement and plugin hook. Subqueries keep the logger of
+// the query that referenced them.
+func withQueryLogger(ctx context.Context, queryOpts restql.QueryOptions) (context.Context, restql.Logger) {
+	log := restql.GetLogger(ctx)
+	if _, nested := ctx.Value(subqueryPathKey{}).([]string); nested {
+		return ctx, log
+	}
+
+	fields := []interface{}{"tenant", queryOpts.Tenant}
+	if queryOpts.Id != "" {
+		fields = append(fields, "namespace", queryOpts.Namespace, "query", queryOpts.Id, "revision", queryOpts.Revision)
+	}
+
+	log = log.WithFields(fields...)
+	return restql.WithLogger(ctx, log), log
+}
+
+// publishQueryFinished notifies the subscribers of the execution of
+// a query, with its parameters and the status of each statement result.
+func publishQueryFinished(ctx context.Context, queryOpts restql.QueryOptions, queryInput restql.QueryInput, resources domain.Resources, err error, start time.Time) {
+	statuses := make(map[string]int, len(resources))
+	for resourceID, result := range resources {
+		statuses[string(resourceID)] = highestStatus(result)
+	}
+
+	restql.PublishEvent(ctx, restql.QueryFinishedEvent{
+		Tenant:    queryOpts.Tenant,
+		Namespace: queryOpts.Namespace,
+		Query:     queryOpts.Id,
+		Revision:  queryOpts.Revision,
+		Params:    queryInput.Params,
+		Statuses:  statuses,
+		Duration:  time.Since(start),
+		Err:       err,
+	})
+}
+
+func highestStatus(result interface{}) int {
+	switch result := result.(type) {
+	case restql.DoneResource:
+		return result.Status
+	case restql.DoneResources:
+		highest := 0
+		for _, r := range result {
+			if s := highestStatus(r); s > highest {
+				highest = s
+			}
+		}
+		return highest
+	default:
+		return 0
+	}
+}
+
+// mockedResources returns a function reporting if a
+// resource of the tenant has a mock declared.
+func (e Evaluator) mockedResources(tenant string) func(resource string) bool {
+	return func(resource string) bool {
+		return e.runner.HasMock(tenant, resource)
+	}
+}
+
+func validateQueryResources(query domain.Query, mappings map[string]restql.Mapping, hasMock func(resource string) bool) error {
 	for _, s := range query.Statements {
+		if _, isSubquery := domain.ParseSubquery(s.Resource); isSubquery {
+			continue
+		}
+
 		_, found := mappings[s.Resource]
-		if !found {
+		if !found && !hasMock(s.Resource) {
 			return fmt.Errorf("%w: statement should reference a valid mapped resource. Error was in %s", ErrMapping, s.Resource)
 		}
 	}
@@ -141,6 +438,19 @@ func validateQueryResources(query domain.Query, mappings map[string]restql.Mappi
 }
 
 func validateQueryOptions(queryOpts restql.QueryOptions) error {
+	err := validateQueryRevision(queryOpts)
+	if err != nil {
+		return err
+	}
+
+	if queryOpts.Tenant == "" {
+		return fmt.Errorf("%w: %s", ErrValidation, errInvalidTenant)
+	}
+
+	return nil
+}
+
+func validateQueryRevision(queryOpts restql.QueryOptions) error {
 	if queryOpts.Revision <= 0 {
 		return fmt.Errorf("%w: %s", ErrValidation, errInvalidRevision)
 	}
@@ -153,9 +463,5 @@ func validateQueryOptions(queryOpts restql.QueryOptions) error {
 		return fmt.Errorf("%w: %s", ErrValidation, errInvalidNamespace)
 	}
 
-	if queryOpts.Tenant == "" {
-		return fmt.Errorf("%w: %s", ErrValidation, errInvalidTenant)
-	}
-
 	return nil
 }
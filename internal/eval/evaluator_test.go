@@ -0,0 +1,49 @@
+package eval_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/internal/parser"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestQueryFinishedEvent(t *testing.T) {
+	hero, err := restql.NewMapping("hero", "http://hero.io/api")
+	test.VerifyError(t, err)
+
+	p, err := parser.New()
+	test.VerifyError(t, err)
+
+	executor := runner.NewExecutor(test.NoOpLogger, &heroClient{}, nil, nil, nil, time.Second, "", nil)
+	r := runner.NewRunner(test.NoOpLogger, executor, time.Second, runner.DefaultsCascade{}, nil, 0)
+	e := eval.NewEvaluator(test.NoOpLogger, staticMappings{"hero": hero}, staticQueries{"heroes/get-hero": "from hero as batman\nfrom hero as robin"}, r, p, plugins.NoOpLifecycle, false, nil)
+
+	var events []restql.QueryFinishedEvent
+	unsubscribe := restql.SubscribeEvents(func(ctx context.Context, event restql.Event) {
+		if finished, ok := event.(restql.QueryFinishedEvent); ok {
+			events = append(events, finished)
+		}
+	})
+	defer unsubscribe()
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	opts := restql.QueryOptions{Namespace: "heroes", Id: "get-hero", Revision: 1, Tenant: "DC"}
+	_, err = e.SavedQuery(ctx, opts, restql.QueryInput{})
+	test.VerifyError(t, err)
+
+	test.Equal(t, len(events), 1)
+	event := events[0]
+	test.Equal(t, event.Tenant, "DC")
+	test.Equal(t, event.Namespace, "heroes")
+	test.Equal(t, event.Query, "get-hero")
+	test.Equal(t, event.Revision, 1)
+	test.Equal(t, event.Statuses, map[string]int{"batman": http.StatusOK, "robin": http.StatusOK})
+	test.Equal(t, event.Err, nil)
+}
@@ -1,16 +1,21 @@
 package eval
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"regexp"
 
 	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/bluele/gcache"
 	"github.com/pkg/errors"
 )
 
 // ApplyFilters returns a version of the already resolved Resources
-// only with the fields defined by the `only` clause.
+// only with the fields defined by the `only` clause. Error bodies are
+// kept as returned by the upstream unless the statement is flagged
+// with `filter-errors`.
 func ApplyFilters(log restql.Logger, query domain.Query, resources domain.Resources) (domain.Resources, error) {
 	result := make(domain.Resources)
 
@@ -18,7 +23,8 @@ func ApplyFilters(log restql.Logger, query domain.Query, resources domain.Resour
 		resourceID := domain.NewResourceID(stmt)
 		dr := resources[resourceID]
 
-		filtered, err := applyOnlyFilters(stmt.Only, dr)
+		only := resolveFilterChains(stmt.Only, resources)
+		filtered, err := applyOnlyFilters(only, stmt.FilterErrors, dr)
 		if err != nil {
 			log.Error("failed to apply filter on statement", err, "statement", fmt.Sprintf("%+#v", stmt), "done-resource", fmt.Sprintf("%+#v", dr))
 			return nil, err
@@ -30,15 +36,18 @@ func ApplyFilters(log restql.Logger, query domain.Query, resources domain.Resour
 	return result, nil
 }
 
-func applyOnlyFilters(filters []interface{}, resourceResult interface{}) (interface{}, error) {
+func applyOnlyFilters(filters []interface{}, filterErrors bool, resourceResult interface{}) (interface{}, error) {
 	if len(filters) == 0 {
 		return resourceResult, nil
 	}
 
 	switch resourceResult := resourceResult.(type) {
 	case restql.DoneResource:
-		body := resourceResult.ResponseBody.Unmarshal()
-		result, err := extractWithFilters(buildFilterTree(filters), body)
+		if resourceResult.Status >= 400 && !filterErrors && !resourceResult.Defaulted {
+			return resourceResult, nil
+		}
+
+		result, err := filterResponseBody(buildFilterTree(filters), resourceResult.ResponseBody)
 		if err != nil {
 			return nil, err
 		}
@@ -48,7 +57,7 @@ func applyOnlyFilters(filters []interface{}, resourceResult interface{}) (interf
 	case restql.DoneResources:
 		list := make(restql.DoneResources, len(resourceResult))
 		for i, r := range resourceResult {
-			list[i], _ = applyOnlyFilters(filters, r)
+			list[i], _ = applyOnlyFilters(filters, filterErrors, r)
 		}
 		return list, nil
 	default:
@@ -56,6 +65,176 @@ func applyOnlyFilters(filters []interface{}, resourceResult interface{}) (interf
 	}
 }
 
+// filterResponseBody applies the filters while tokenizing the body
+// bytes when it was not decoded yet, so that only the selected fields
+// are materialized instead of the whole upstream response, and the
+// elements of large lists are filtered one at a time.
+func filterResponseBody(filters map[string]interface{}, body *restql.ResponseBody) (interface{}, error) {
+	if body.Value() != nil || !body.Valid() {
+		return extractWithFilters(filters, body.Unmarshal())
+	}
+
+	return streamWithFilters(filters, json.NewDecoder(bytes.NewReader(body.Bytes())))
+}
+
+// streamWithFilters decodes the next value of the decoder applying
+// the filters to it, skipping the fields they do not select.
+func streamWithFilters(filters map[string]interface{}, dec *json.Decoder) (interface{}, error) {
+	if _, hasSelectAll := filters["*"]; hasSelectAll {
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		return extractWithFilters(filters, value)
+	}
+
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		node, err := streamObjectWithFilters(filters, dec)
+		if err != nil {
+			return nil, err
+		}
+		return node, closeDelim(dec)
+	case json.Delim('['):
+		node, err := streamListWithFilters(filters, dec)
+		if err != nil {
+			return nil, err
+		}
+		return node, closeDelim(dec)
+	default:
+		return token, nil
+	}
+}
+
+func streamObjectWithFilters(filters map[string]interface{}, dec *json.Decoder) (map[string]interface{}, error) {
+	node := make(map[string]interface{})
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+
+		subFilter, found := filters[key]
+		if !found {
+			if err := skipValue(dec); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if subFilter, ok := subFilter.(map[string]interface{}); ok {
+			f, err := streamWithFilters(subFilter, dec)
+			if err != nil {
+				return nil, err
+			}
+			node[key] = f
+			continue
+		}
+
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		if fn, ok := subFilter.(domain.Function); ok {
+			err := applyFilterFunction(fn, key, value, node)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			node[key] = value
+		}
+	}
+
+	return node, nil
+}
+
+// streamListWithFilters filters each element as soon as it is
+// decoded, unless the filters have list selectors, which depend
+// on the list length and so are applied once the list is read.
+func streamListWithFilters(filters map[string]interface{}, dec *json.Decoder) ([]interface{}, error) {
+	if !hasListSelector(filters) {
+		node := []interface{}{}
+		for dec.More() {
+			f, err := streamWithFilters(filters, dec)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, f)
+		}
+		return node, nil
+	}
+
+	var list []json.RawMessage
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		list = append(list, raw)
+	}
+
+	elements, _ := selectListElements(filters, len(list))
+	node := make([]interface{}, len(elements))
+	for i, e := range elements {
+		var err error
+		raw := json.NewDecoder(bytes.NewReader(list[e.index]))
+		if e.whole {
+			err = raw.Decode(&node[i])
+		} else {
+			node[i], err = streamWithFilters(e.filters, raw)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return node, nil
+}
+
+func hasListSelector(filters map[string]interface{}) bool {
+	for key := range filters {
+		if domain.IsListSelector(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipValue consumes the next value of the decoder without
+// materializing its objects and lists.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func closeDelim(dec *json.Decoder) error {
+	_, err := dec.Token()
+	return err
+}
+
 func extractWithFilters(filters map[string]interface{}, resourceResult interface{}) (interface{}, error) {
 	filters, hasSelectAll := extractSelectAllFilter(filters)
 
@@ -74,8 +253,8 @@ func extractWithFilters(filters map[string]interface{}, resourceResult interface
 				continue
 			}
 
-			if matchFilter, ok := subFilter.(domain.Match); ok {
-				err := applyMatchFilter(matchFilter, key, value, node)
+			if fn, ok := subFilter.(domain.Function); ok {
+				err := applyFilterFunction(fn, key, value, node)
 				if err != nil {
 					return nil, err
 				}
@@ -94,6 +273,24 @@ func extractWithFilters(filters map[string]interface{}, resourceResult interface
 
 		return node, nil
 	case []interface{}:
+		if elements, ok := selectListElements(filters, len(resourceResult)); ok && !hasSelectAll {
+			node := make([]interface{}, len(elements))
+			for i, e := range elements {
+				if e.whole {
+					node[i] = resourceResult[e.index]
+					continue
+				}
+
+				f, err := extractWithFilters(e.filters, resourceResult[e.index])
+				if err != nil {
+					return nil, err
+				}
+				node[i] = f
+			}
+
+			return node, nil
+		}
+
 		var node []interface{}
 		if hasSelectAll {
 			node = resourceResult
@@ -130,8 +327,177 @@ func extractSelectAllFilter(filters map[string]interface{}) (map[string]interfac
 	return m, has
 }
 
+// listElementFilter is the filter applied to an element
+// kept from a list, or whether it is kept as a whole.
+type listElementFilter struct {
+	index   int
+	filters map[string]interface{}
+	whole   bool
+}
+
+// selectListElements returns the elements kept from a list of
+// the given length when the filters have list selectors, like
+// `[0]` or `[0:10]`, in their original order. The filters of
+// fields without a selector apply to every element, which keeps
+// the whole list, merged with the filters of the selectors
+// picking the element.
+func selectListElements(filters map[string]interface{}, length int) ([]listElementFilter, bool) {
+	common := make(map[string]interface{})
+	var selectors []string
+	for key, subFilter := range filters {
+		if domain.IsListSelector(key) {
+			selectors = append(selectors, key)
+		} else {
+			common[key] = subFilter
+		}
+	}
+
+	if len(selectors) == 0 {
+		return nil, false
+	}
+
+	elements := make([]listElementFilter, length)
+	kept := make([]bool, length)
+	for i := range elements {
+		elements[i] = listElementFilter{index: i, filters: make(map[string]interface{})}
+		mergeFilterTree(elements[i].filters, common)
+		kept[i] = len(common) > 0
+	}
+
+	for _, key := range selectors {
+		selector, ok := domain.ParseListSelector(key)
+		if !ok {
+			continue
+		}
+
+		subFilter, _ := filters[key].(map[string]interface{})
+		for _, i := range selector.Indexes(length) {
+			kept[i] = true
+			if subFilter == nil {
+				elements[i].whole = true
+				continue
+			}
+			mergeFilterTree(elements[i].filters, subFilter)
+		}
+	}
+
+	var result []listElementFilter
+	for i, e := range elements {
+		if kept[i] {
+			result = append(result, e)
+		}
+	}
+
+	return result, true
+}
+
+// mergeFilterTree adds the fields selected by src to dst,
+// where selecting a whole field prevails over its subfields.
+func mergeFilterTree(dst map[string]interface{}, src map[string]interface{}) {
+	for key, srcFilter := range src {
+		dstFilter, found := dst[key]
+		srcNode, srcIsNode := srcFilter.(map[string]interface{})
+		dstNode, dstIsNode := dstFilter.(map[string]interface{})
+
+		switch {
+		case !found && srcIsNode:
+			node := make(map[string]interface{})
+			mergeFilterTree(node, srcNode)
+			dst[key] = node
+		case !found, srcFilter == nil:
+			dst[key] = srcFilter
+		case srcIsNode && dstIsNode:
+			mergeFilterTree(dstNode, srcNode)
+		}
+	}
+}
+
+// applyFilterFunction sets the field in the node with its value
+// transformed by the function, after the functions it wraps.
+func applyFilterFunction(fn domain.Function, key string, value interface{}, node map[string]interface{}) error {
+	if inner, ok := fn.Target().(domain.Function); ok {
+		err := applyFilterFunction(inner, key, value, node)
+		if err != nil {
+			return err
+		}
+
+		v, found := node[key]
+		if !found {
+			return nil
+		}
+		value = v
+	}
+
+	switch fn := fn.(type) {
+	case domain.Match:
+		return applyMatchFilter(fn, key, value, node)
+	case domain.Compare:
+		applyCompareFilter(fn, key, value, node)
+	case domain.FilterByKeys:
+		node[key] = filterByKeys(value, fn.Keys)
+	case domain.First:
+		list, ok := value.([]interface{})
+		switch {
+		case !ok:
+			node[key] = value
+		case len(list) > 0:
+			node[key] = list[0]
+		default:
+			delete(node, key)
+		}
+	case domain.RenameAs:
+		delete(node, key)
+		node[fn.Name] = value
+	default:
+		node[key] = value
+	}
+
+	return nil
+}
+
+// filterByKeys keeps only the given keys of an object,
+// or of each object in a list, where keys is a list of
+// names or a single one.
+func filterByKeys(value interface{}, keys interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{})
+		for _, k := range toKeys(keys) {
+			if v, found := value[k]; found {
+				result[k] = v
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, v := range value {
+			result[i] = filterByKeys(v, keys)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func toKeys(keys interface{}) []string {
+	switch keys := keys.(type) {
+	case []string:
+		return keys
+	case []interface{}:
+		result := make([]string, 0, len(keys))
+		for _, k := range keys {
+			result = append(result, fmt.Sprintf("%v", k))
+		}
+		return result
+	case nil:
+		return nil
+	default:
+		return []string{fmt.Sprintf("%v", keys)}
+	}
+}
+
 func applyMatchFilter(filter domain.Match, key string, value interface{}, node map[string]interface{}) error {
-	matchRegex, err := parseMatchArg(filter.Arg)
+	matchRegex, err := parseMatchArg(filter.Arg, filter.Flags)
 	if err != nil {
 		return err
 	}
@@ -167,17 +533,41 @@ func applyMatchFilter(filter domain.Match, key string, value interface{}, node m
 	}
 }
 
-func parseMatchArg(arg interface{}) (*regexp.Regexp, error) {
+// matchRegexCacheSize bounds the amount of compiled
+// regexes kept for `matches` arguments resolved at runtime.
+const matchRegexCacheSize = 512
+
+// matchRegexCache keeps the compiled regexes of `matches` arguments
+// given as variables, which are not known at parse time, so they are
+// not compiled again for each element of a list response.
+var matchRegexCache = gcache.New(matchRegexCacheSize).LRU().Build()
+
+func parseMatchArg(arg interface{}, flags string) (*regexp.Regexp, error) {
 	switch arg := arg.(type) {
 	case *regexp.Regexp:
 		return arg, nil
 	case string:
-		return regexp.Compile(arg)
+		return compileMatchArg(arg, flags)
 	default:
 		return nil, errors.New("failed to parse match argument : unknown match argument type")
 	}
 }
 
+func compileMatchArg(arg string, flags string) (*regexp.Regexp, error) {
+	key := flags + "/" + arg
+	if cached, err := matchRegexCache.Get(key); err == nil {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	regex, err := domain.CompileMatch(arg, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = matchRegexCache.Set(key, regex)
+	return regex, nil
+}
+
 func buildFilterTree(filters []interface{}) map[string]interface{} {
 	tree := make(map[string]interface{})
 
@@ -201,8 +591,8 @@ func buildPathInTree(path []interface{}, tree map[string]interface{}) {
 	case string:
 		field = f
 		leaf = nil
-	case domain.Match:
-		fields, ok := f.Target().([]string)
+	case domain.Function:
+		fields, ok := filterPath(f)
 		if !ok {
 			return
 		}
@@ -242,8 +632,8 @@ func parsePath(s interface{}) []interface{} {
 			result[i] = item
 		}
 		return result
-	case domain.Match:
-		items, ok := s.Target().([]string)
+	case domain.Function:
+		items, ok := filterPath(s)
 		if !ok {
 			return nil
 		}
@@ -251,7 +641,7 @@ func parsePath(s interface{}) []interface{} {
 		result := make([]interface{}, len(items))
 		for i, item := range items {
 			if i == len(items)-1 {
-				result[i] = domain.Match{Value: []string{item}, Arg: s.Arg}
+				result[i] = withFilterPath(s, []string{item})
 			} else {
 				result[i] = item
 			}
@@ -262,20 +652,266 @@ func parsePath(s interface{}) []interface{} {
 	}
 }
 
+// filterPath returns the path of the filter
+// wrapped by the function and the ones it wraps.
+func filterPath(fn domain.Function) ([]string, bool) {
+	switch target := fn.Target().(type) {
+	case []string:
+		return target, true
+	case domain.Function:
+		return filterPath(target)
+	default:
+		return nil, false
+	}
+}
+
+// withFilterPath replaces the path of the filter wrapped by
+// the function and the ones it wraps, preserving them.
+func withFilterPath(fn domain.Function, path []string) domain.Function {
+	return fn.Map(func(target interface{}) interface{} {
+		if inner, ok := target.(domain.Function); ok {
+			return withFilterPath(inner, path)
+		}
+		return path
+	})
+}
+
+// FilterMisses returns a warning for each `only` field not found
+// in any successful result of its statement, which usually means a
+// typo in the query or a change in the upstream response.
+func FilterMisses(query domain.Query, resources domain.Resources) []domain.Warning {
+	var result []domain.Warning
+
+	for _, stmt := range query.Statements {
+		resourceID := domain.NewResourceID(stmt)
+
+		for _, f := range stmt.Only {
+			path, ok := f.([]string)
+			if !ok || containsSelectAll(path) {
+				continue
+			}
+
+			found, applicable := resultHasPath(resources[resourceID], path)
+			if found || !applicable {
+				continue
+			}
+
+			result = append(result, domain.Warning{
+				Code:      domain.FilterMissWarning,
+				Statement: string(resourceID),
+				Message:   fmt.Sprintf("only field %s was not found in the response", domain.FormatPath(path)),
+			})
+		}
+	}
+
+	return result
+}
+
+func containsSelectAll(path []string) bool {
+	for _, p := range path {
+		if p == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// resultHasPath reports if the path is present in the result
+// and if the result has a successful response to look into.
+func resultHasPath(resourceResult interface{}, path []string) (found bool, applicable bool) {
+	switch resourceResult := resourceResult.(type) {
+	case restql.DoneResource:
+		if resourceResult.Status < 200 || resourceResult.Status >= 300 || resourceResult.ResponseBody == nil {
+			return false, false
+		}
+
+		return bodyHasPath(resourceResult.ResponseBody.Unmarshal(), path), true
+	case restql.DoneResources:
+		for _, r := range resourceResult {
+			f, a := resultHasPath(r, path)
+			if f {
+				return true, true
+			}
+			applicable = applicable || a
+		}
+		return false, applicable
+	default:
+		return false, false
+	}
+}
+
+func bodyHasPath(body interface{}, path []string) bool {
+	if len(path) == 0 {
+		return true
+	}
+
+	switch body := body.(type) {
+	case map[string]interface{}:
+		v, found := body[path[0]]
+		if !found {
+			return false
+		}
+		return bodyHasPath(v, path[1:])
+	case []interface{}:
+		if domain.IsListSelector(path[0]) {
+			selector, ok := domain.ParseListSelector(path[0])
+			if !ok {
+				return false
+			}
+
+			for _, i := range selector.Indexes(len(body)) {
+				if bodyHasPath(body[i], path[1:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		for _, item := range body {
+			if bodyHasPath(item, path) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
 // ApplyHidden returns a version of the already resolved Resources
-// removing the statement results with the `hidden` clause.
-func ApplyHidden(query domain.Query, resources domain.Resources) domain.Resources {
+// removing the statement results with the `hidden` clause. When
+// keepFailed is set, hidden statements that failed without the
+// `ignore-errors` clause are kept, so the failure reaches the client.
+func ApplyHidden(query domain.Query, resources domain.Resources, keepFailed bool) domain.Resources {
 	result := make(domain.Resources)
 
 	for _, stmt := range query.Statements {
-		if stmt.Hidden {
+		resourceID := domain.NewResourceID(stmt)
+		dr, found := resources[resourceID]
+		if !found {
+			continue
+		}
+
+		if stmt.Hidden && !(keepFailed && hasFailed(dr)) {
 			continue
 		}
-		resourceID := domain.NewResourceID(stmt)
-		dr := resources[resourceID]
+
+		if stmt.Hidden {
+			dr = markHidden(dr)
+		}
 
 		result[resourceID] = dr
 	}
 
 	return result
 }
+
+func markHidden(result interface{}) interface{} {
+	switch result := result.(type) {
+	case restql.DoneResource:
+		result.Hidden = true
+		return result
+	case restql.DoneResources:
+		marked := make(restql.DoneResources, len(result))
+		for i, r := range result {
+			marked[i] = markHidden(r)
+		}
+		return marked
+	default:
+		return result
+	}
+}
+
+// ApplyProjection returns a version of the already resolved Resources
+// only with the fields defined by the query `use only` clause, whose
+// paths start with the statement identifier, or `*` for every one, and
+// are applied to the results after the aggregations. A path with only
+// the identifier keeps the whole statement result. Statements not
+// referenced by any path are removed, unless keepFailed is set and they
+// failed without the `ignore-errors` clause.
+func ApplyProjection(log restql.Logger, query domain.Query, resources domain.Resources, keepFailed bool) (domain.Resources, error) {
+	if len(query.Only) == 0 {
+		return resources, nil
+	}
+
+	filters := make(map[string][]interface{})
+	whole := make(map[string]bool)
+	for _, f := range resolveFilterChains(query.Only, resources) {
+		target, filter, ok := splitProjectionFilter(f)
+		if !ok {
+			continue
+		}
+
+		if filter == nil {
+			whole[target] = true
+			continue
+		}
+		filters[target] = append(filters[target], filter)
+	}
+
+	result := make(domain.Resources)
+	for _, stmt := range query.Statements {
+		resourceID := domain.NewResourceID(stmt)
+		dr, found := resources[resourceID]
+		if !found {
+			continue
+		}
+
+		only := append(append([]interface{}{}, filters["*"]...), filters[string(resourceID)]...)
+		switch {
+		case whole["*"] || whole[string(resourceID)]:
+			result[resourceID] = dr
+		case len(only) > 0:
+			filtered, err := applyOnlyFilters(only, stmt.FilterErrors, dr)
+			if err != nil {
+				log.Error("failed to apply query filter on statement", err, "statement", fmt.Sprintf("%+#v", stmt), "done-resource", fmt.Sprintf("%+#v", dr))
+				return nil, err
+			}
+			result[resourceID] = filtered
+		case keepFailed && hasFailed(dr):
+			result[resourceID] = dr
+		}
+	}
+
+	return result, nil
+}
+
+// splitProjectionFilter separates the statement identifier from a
+// `use only` filter, returning a nil filter when the path has
+// only the identifier.
+func splitProjectionFilter(filter interface{}) (string, interface{}, bool) {
+	switch filter := filter.(type) {
+	case []string:
+		if len(filter) == 0 {
+			return "", nil, false
+		}
+		if len(filter) == 1 {
+			return filter[0], nil, true
+		}
+		return filter[0], filter[1:], true
+	case domain.Function:
+		path, ok := filterPath(filter)
+		if !ok || len(path) < 2 {
+			return "", nil, false
+		}
+		return path[0], withFilterPath(filter, path[1:]), true
+	default:
+		return "", nil, false
+	}
+}
+
+func hasFailed(resourceResult interface{}) bool {
+	switch resourceResult := resourceResult.(type) {
+	case restql.DoneResource:
+		return !resourceResult.Success && !resourceResult.IgnoreErrors
+	case restql.DoneResources:
+		for _, r := range resourceResult {
+			if hasFailed(r) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
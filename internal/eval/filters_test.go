@@ -1,6 +1,8 @@
 package eval_test
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
 	"regexp"
 	"testing"
@@ -25,7 +27,32 @@ func TestHiddenFilter(t *testing.T) {
 		"sidekick": restql.DoneResource{ResponseBody: nil},
 	}
 
-	got := eval.ApplyHidden(query, resources)
+	got := eval.ApplyHidden(query, resources, false)
+
+	test.Equal(t, got, expectedResources)
+}
+
+func TestHiddenFilterKeepingFailures(t *testing.T) {
+	query := domain.Query{Statements: []domain.Statement{
+		{Resource: "hero", Hidden: true},
+		{Resource: "villain", Hidden: true},
+		{Resource: "weapon", Hidden: true, IgnoreErrors: true},
+		{Resource: "sidekick", Hidden: true},
+	}}
+
+	resources := domain.Resources{
+		"hero":     restql.DoneResource{Status: 200, Success: true},
+		"villain":  restql.DoneResource{Status: 503, Success: false},
+		"weapon":   restql.DoneResource{Status: 404, Success: false, IgnoreErrors: true},
+		"sidekick": restql.DoneResources{restql.DoneResource{Status: 200, Success: true}, restql.DoneResource{Status: 500, Success: false}},
+	}
+
+	expectedResources := domain.Resources{
+		"villain":  restql.DoneResource{Status: 503, Success: false, Hidden: true},
+		"sidekick": restql.DoneResources{restql.DoneResource{Status: 200, Success: true, Hidden: true}, restql.DoneResource{Status: 500, Success: false, Hidden: true}},
+	}
+
+	got := eval.ApplyHidden(query, resources, true)
 
 	test.Equal(t, got, expectedResources)
 }
@@ -335,6 +362,130 @@ func TestOnlyFilters(t *testing.T) {
 				},
 			},
 		},
+		{
+			"should bring only the fields of each list item that matches string arg",
+			domain.Query{Statements: []domain.Statement{{
+				Resource: "hero",
+				Only:     []interface{}{domain.Match{Value: []string{"name"}, Arg: "^b"}},
+			}}},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`[{ "name": "batman" }, { "name": "robin" }, { "name": "batgirl" }]`),
+					),
+				},
+			},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`[{ "name": "batman" }, {}, { "name": "batgirl" }]`),
+					),
+				},
+			},
+		},
+		{
+			"should keep error body unfiltered",
+			domain.Query{Statements: []domain.Statement{{
+				Resource: "hero",
+				Only:     []interface{}{[]string{"name"}},
+			}}},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					Status: 404,
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`{ "code": "NOT_FOUND", "message": "hero not found" }`),
+					),
+				},
+			},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					Status: 404,
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`{ "code": "NOT_FOUND", "message": "hero not found" }`),
+					),
+				},
+			},
+		},
+		{
+			"should bring only the given fields of error body when filtering errors",
+			domain.Query{Statements: []domain.Statement{{
+				Resource:     "hero",
+				Only:         []interface{}{[]string{"message"}},
+				FilterErrors: true,
+			}}},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					Status: 404,
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`{ "code": "NOT_FOUND", "message": "hero not found" }`),
+					),
+				},
+			},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					Status: 404,
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`{ "message": "hero not found" }`),
+					),
+				},
+			},
+		},
+		{
+			"should bring only the given fields of default value",
+			domain.Query{Statements: []domain.Statement{{
+				Resource: "hero",
+				Only:     []interface{}{[]string{"name"}},
+			}}},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					Status:    503,
+					Defaulted: true,
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`{ "name": "unknown", "age": 0 }`),
+					),
+				},
+			},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					Status:    503,
+					Defaulted: true,
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`{ "name": "unknown" }`),
+					),
+				},
+			},
+		},
+		{
+			"should bring only the fields of each list item that matches string arg with flags",
+			domain.Query{Statements: []domain.Statement{{
+				Resource: "hero",
+				Only:     []interface{}{domain.Match{Value: []string{"name"}, Arg: `^\w+ão$`, Flags: "iu"}},
+			}}},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`[{ "name": "Gavião" }, { "name": "robin" }, { "name": "FALCÃO" }]`),
+					),
+				},
+			},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`[{ "name": "Gavião" }, {}, { "name": "FALCÃO" }]`),
+					),
+				},
+			},
+		},
 		{
 			"should bring only the list elements that matches arg",
 			domain.Query{Statements: []domain.Statement{{
@@ -404,14 +555,378 @@ func TestOnlyFilters(t *testing.T) {
 				},
 			},
 		},
+		{
+			"should bring only the indexed list elements",
+			domain.Query{Statements: []domain.Statement{{
+				Resource: "hero",
+				Only:     []interface{}{[]string{"name"}, []string{"weapons", "[0]", "id"}, []string{"weapons", "[-1]"}},
+			}}},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`{ "name": "batman", "weapons": [{"id": 1, "name": "batarang"}, {"id": 2, "name": "rope"}, {"id": 3, "name": "gun"}] }`),
+					),
+				},
+			},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`{ "name": "batman", "weapons": [{"id": 1}, {"id": 3, "name": "gun"}] }`),
+					),
+				},
+			},
+		},
+		{
+			"should bring only the sliced list elements",
+			domain.Query{Statements: []domain.Statement{{
+				Resource: "hero",
+				Only:     []interface{}{[]string{"weapons", "[1:10]", "name"}, []string{"[0:1]", "tags", "[:2]"}},
+			}}},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`[{ "weapons": [{"id": 1, "name": "batarang"}, {"id": 2, "name": "rope"}, {"id": 3, "name": "gun"}], "tags": ["a", "b", "c"] }, { "tags": ["d"] }]`),
+					),
+				},
+			},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`[{ "weapons": [{"name": "rope"}, {"name": "gun"}], "tags": ["a", "b"] }, {}]`),
+					),
+				},
+			},
+		},
+		{
+			"should apply list selector with fields selected from every element",
+			domain.Query{Statements: []domain.Statement{{
+				Resource: "hero",
+				Only:     []interface{}{[]string{"weapons", "id"}, []string{"weapons", "[1]", "name"}, []string{"weapons", "[5]"}},
+			}}},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`{ "weapons": [{"id": 1, "name": "batarang"}, {"id": 2, "name": "rope"}] }`),
+					),
+				},
+			},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`{ "weapons": [{"id": 1}, {"id": 2, "name": "rope"}] }`),
+					),
+				},
+			},
+		},
+		{
+			"should apply functions to the filtered fields",
+			domain.Query{Statements: []domain.Statement{{
+				Resource: "hero",
+				Only: []interface{}{
+					domain.FilterByKeys{Value: []string{"city"}, Keys: []string{"name", "country"}},
+					domain.FilterByKeys{Value: []string{"skills"}, Keys: []interface{}{"id"}},
+					domain.RenameAs{Value: domain.First{Value: domain.Match{Value: []string{"weapons"}, Arg: regexp.MustCompile("^bat")}}, Name: "weapon"},
+					domain.First{Value: []string{"sidekicks"}},
+					domain.RenameAs{Value: []string{"id"}, Name: "heroId"},
+				},
+			}}},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`{ "id": "12345", "city": {"name": "Gotham", "population": 10}, "skills": [{"id": 1, "name": "fly"}, {"id": 2}], "weapons": ["rope", "batarang", "batmobile"], "sidekicks": [] }`),
+					),
+				},
+			},
+			domain.Resources{
+				"hero": restql.DoneResource{
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`{ "heroId": "12345", "city": {"name": "Gotham"}, "skills": [{"id": 1}, {"id": 2}], "weapon": "batarang" }`),
+					),
+				},
+			},
+		},
+		{
+			"should apply comparisons to the filtered fields",
+			domain.Query{Statements: []domain.Statement{{
+				Resource: "product",
+				Only: []interface{}{
+					[]string{"id"},
+					domain.Compare{Value: []string{"price"}, Operator: domain.CompareGreaterThan, Arg: 100},
+					domain.Compare{Value: []string{"status"}, Operator: domain.CompareEquals, Arg: "active"},
+					domain.Compare{Value: []string{"stock"}, Operator: domain.CompareEquals, Arg: "10"},
+					domain.Compare{Value: []string{"offers", "date"}, Operator: domain.CompareAfter, Arg: "2020-01-01"},
+					domain.Compare{Value: []string{"sizes"}, Operator: domain.CompareLessThan, Arg: "40"},
+				},
+			}}},
+			domain.Resources{
+				"product": restql.DoneResource{
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`[{ "id": 1, "price": 150, "status": "active", "stock": 10, "offers": [{"date": "2019-12-31T10:00:00Z"}, {"date": "2020-06-01"}], "sizes": [38, 40, 42] }, { "id": 2, "price": 100, "status": "inactive", "stock": 5, "sizes": [44] }]`),
+					),
+				},
+			},
+			domain.Resources{
+				"product": restql.DoneResource{
+					ResponseBody: restql.NewResponseBodyFromValue(
+						test.NoOpLogger,
+						test.Unmarshal(`[{ "id": 1, "price": 150, "status": "active", "stock": 10, "offers": [{}, {"date": "2020-06-01"}], "sizes": [38] }, { "id": 2 }]`),
+					),
+				},
+			},
+		},
+		{
+			"should compare with chained values",
+			domain.Query{Statements: []domain.Statement{
+				{Resource: "limits"},
+				{
+					Resource: "product",
+					Only: []interface{}{
+						domain.Compare{Value: []string{"price"}, Operator: domain.CompareLessThan, Arg: domain.Chain{"limits", "maxPrice"}},
+						domain.Compare{Value: []string{"stock"}, Operator: domain.CompareGreaterThan, Arg: domain.Chain{"limits", "unknown"}},
+					},
+				},
+			}},
+			domain.Resources{
+				"limits": restql.DoneResource{
+					Status:       200,
+					ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{ "maxPrice": 120 }`)),
+				},
+				"product": restql.DoneResource{
+					Status:       200,
+					ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`[{ "price": 150, "stock": 1 }, { "price": 100, "stock": 2 }]`)),
+				},
+			},
+			domain.Resources{
+				"limits": restql.DoneResource{
+					Status:       200,
+					ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{ "maxPrice": 120 }`)),
+				},
+				"product": restql.DoneResource{
+					Status:       200,
+					ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`[{}, { "price": 100 }]`)),
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
+		raw := withRawBodies(t, tt.resources)
+
 		t.Run(tt.name, func(t *testing.T) {
 			got, err := eval.ApplyFilters(test.NoOpLogger, tt.query, tt.resources)
 
 			test.VerifyError(t, err)
 			test.Equal(t, got, tt.expected)
 		})
+
+		t.Run(tt.name+" over undecoded body", func(t *testing.T) {
+			got, err := eval.ApplyFilters(test.NoOpLogger, tt.query, raw)
+
+			test.VerifyError(t, err)
+			test.Equal(t, got, tt.expected)
+		})
+	}
+}
+
+func TestFilterMisses(t *testing.T) {
+	hero := func(status int, body string) restql.DoneResource {
+		return restql.DoneResource{Status: status, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(body))}
+	}
+
+	tests := []struct {
+		name      string
+		only      []interface{}
+		resources domain.Resources
+		expected  []domain.Warning
+	}{
+		{
+			"should not warn when every field is found",
+			[]interface{}{[]string{"name"}, []string{"weapons", "name"}},
+			domain.Resources{"hero": hero(200, `{"name": "batman", "weapons": [{"id": 1}, {"name": "batarang"}]}`)},
+			nil,
+		},
+		{
+			"should warn for field not found in the selected list elements",
+			[]interface{}{[]string{"weapons", "[0]", "name"}, []string{"weapons", "[1:]", "name"}},
+			domain.Resources{"hero": hero(200, `{"weapons": [{"id": 1}, {"name": "batarang"}]}`)},
+			[]domain.Warning{{Code: domain.FilterMissWarning, Statement: "hero", Message: "only field weapons[0].name was not found in the response"}},
+		},
+		{
+			"should warn for field not found",
+			[]interface{}{[]string{"name"}, []string{"city", "name"}},
+			domain.Resources{"hero": hero(200, `{"name": "batman"}`)},
+			[]domain.Warning{{Code: domain.FilterMissWarning, Statement: "hero", Message: "only field city.name was not found in the response"}},
+		},
+		{
+			"should not warn for failed response",
+			[]interface{}{[]string{"name"}},
+			domain.Resources{"hero": hero(500, `{}`)},
+			nil,
+		},
+		{
+			"should not warn for field found in some multiplexed result",
+			[]interface{}{[]string{"name"}, []string{"age"}},
+			domain.Resources{"hero": restql.DoneResources{hero(200, `{"name": "batman"}`), hero(200, `{"name": "robin", "age": 18}`)}},
+			nil,
+		},
+		{
+			"should not warn for select all and match filters",
+			[]interface{}{[]string{"*"}, domain.Match{Value: []string{"age"}, Arg: regexp.MustCompile("18")}},
+			domain.Resources{"hero": hero(200, `{"name": "batman"}`)},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := domain.Query{Statements: []domain.Statement{{Resource: "hero", Only: tt.only}}}
+
+			got := eval.FilterMisses(query, tt.resources)
+			test.Equal(t, got, tt.expected)
+		})
 	}
 }
+
+func withRawBodies(t *testing.T, resources domain.Resources) domain.Resources {
+	result := make(domain.Resources, len(resources))
+	for resourceID, r := range resources {
+		result[resourceID] = withRawBody(t, r)
+	}
+	return result
+}
+
+func withRawBody(t *testing.T, resource interface{}) interface{} {
+	switch resource := resource.(type) {
+	case restql.DoneResource:
+		if resource.ResponseBody == nil {
+			return resource
+		}
+
+		b, err := json.Marshal(resource.ResponseBody.Value())
+		test.VerifyError(t, err)
+
+		resource.ResponseBody = restql.NewResponseBodyFromBytes(test.NoOpLogger, b)
+		return resource
+	case restql.DoneResources:
+		list := make(restql.DoneResources, len(resource))
+		for i, r := range resource {
+			list[i] = withRawBody(t, r)
+		}
+		return list
+	default:
+		return resource
+	}
+}
+
+func BenchmarkOnlyFilters(b *testing.B) {
+	items := make([]interface{}, 1000)
+	for i := range items {
+		items[i] = map[string]interface{}{
+			"id":      i,
+			"name":    fmt.Sprintf("hero %d", i),
+			"details": map[string]interface{}{"city": "Gotham", "weapons": []interface{}{"batarang", "batbelt", "grapple"}},
+		}
+	}
+	body, err := json.Marshal(map[string]interface{}{"total": len(items), "items": items})
+	if err != nil {
+		b.Fatalf("failed to marshal body : %v", err)
+	}
+
+	query := domain.Query{Statements: []domain.Statement{{Resource: "hero", Only: []interface{}{[]string{"items", "id"}}}}}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		resources := domain.Resources{"hero": restql.DoneResource{ResponseBody: restql.NewResponseBodyFromBytes(test.NoOpLogger, body)}}
+
+		_, err := eval.ApplyFilters(test.NoOpLogger, query, resources)
+		if err != nil {
+			b.Fatalf("failed to apply filters : %v", err)
+		}
+	}
+}
+
+func TestOnlyFiltersStreamingRawBody(t *testing.T) {
+	body := `{
+		"skipped": {"nested": [{"text": "]}"}, [1, [2]]], "other": "{["},
+		"items": [
+			{"id": 1, "tags": ["a", "b"], "details": {"city": "Gotham", "weapons": ["batarang"]}},
+			{"id": 2, "details": null},
+			3
+		],
+		"total": 3
+	}`
+	query := domain.Query{Statements: []domain.Statement{{Resource: "hero", Only: []interface{}{
+		[]string{"items", "id"},
+		[]string{"items", "details", "city"},
+		[]string{"total"},
+	}}}}
+	resources := domain.Resources{"hero": restql.DoneResource{ResponseBody: restql.NewResponseBodyFromBytes(test.NoOpLogger, []byte(body))}}
+
+	got, err := eval.ApplyFilters(test.NoOpLogger, query, resources)
+
+	test.VerifyError(t, err)
+	test.Equal(t, got["hero"].(restql.DoneResource).ResponseBody.Unmarshal(), test.Unmarshal(`{
+		"items": [{"id": 1, "details": {"city": "Gotham"}}, {"id": 2, "details": null}, 3],
+		"total": 3
+	}`))
+}
+
+func TestProjection(t *testing.T) {
+	query := domain.Query{
+		Only: []interface{}{
+			[]string{"cart", "items", "product", "name"},
+			domain.Match{Value: []string{"cart", "id"}, Arg: regexp.MustCompile("^c")},
+			[]string{"customer"},
+		},
+		Statements: []domain.Statement{
+			{Resource: "cart"},
+			{Resource: "customer"},
+			{Resource: "shipping"},
+			{Resource: "coupon"},
+		},
+	}
+	resources := domain.Resources{
+		"cart": restql.DoneResource{Status: 200, Success: true, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{
+			"id": "c1",
+			"total": 10,
+			"items": [{"quantity": 1, "product": {"name": "cape", "price": 10}}]
+		}`))},
+		"customer": restql.DoneResource{Status: 200, Success: true, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"name": "bruce"}`))},
+		"shipping": restql.DoneResource{Status: 200, Success: true},
+		"coupon":   restql.DoneResource{Status: 500, Success: false},
+	}
+
+	got, err := eval.ApplyProjection(test.NoOpLogger, query, resources, true)
+
+	test.VerifyError(t, err)
+	test.Equal(t, len(got), 3)
+	test.Equal(t, got["cart"].(restql.DoneResource).ResponseBody.Unmarshal(), test.Unmarshal(`{"id": "c1", "items": [{"product": {"name": "cape"}}]}`))
+	test.Equal(t, got["customer"].(restql.DoneResource).ResponseBody.Unmarshal(), test.Unmarshal(`{"name": "bruce"}`))
+	test.Equal(t, got["coupon"], restql.DoneResource{Status: 500, Success: false})
+}
+
+func TestProjectionOnEveryStatement(t *testing.T) {
+	query := domain.Query{
+		Only:       []interface{}{[]string{"*", "id"}, []string{"hero", "name"}},
+		Statements: []domain.Statement{{Resource: "hero"}, {Resource: "villain", Alias: "joker"}},
+	}
+	resources := domain.Resources{
+		"hero":  restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"id": 1, "name": "batman", "city": "gotham"}`))},
+		"joker": restql.DoneResource{ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"id": 2, "name": "joker"}`))},
+	}
+
+	got, err := eval.ApplyProjection(test.NoOpLogger, query, resources, false)
+
+	test.VerifyError(t, err)
+	test.Equal(t, got["hero"].(restql.DoneResource).ResponseBody.Unmarshal(), test.Unmarshal(`{"id": 1, "name": "batman"}`))
+	test.Equal(t, got["joker"].(restql.DoneResource).ResponseBody.Unmarshal(), test.Unmarshal(`{"id": 2}`))
+}
@@ -0,0 +1,69 @@
+package eval
+
+import (
+	"context"
+	"sync"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+)
+
+type passThroughKey struct{}
+
+type passThrough struct {
+	mu         sync.Mutex
+	evaluated  bool
+	eligible   bool
+	resourceID domain.ResourceID
+}
+
+// WithPassThrough returns a context that records if the query
+// evaluated with it can have its response replaced by the
+// upstream body of its single statement.
+func WithPassThrough(ctx context.Context) context.Context {
+	return context.WithValue(ctx, passThroughKey{}, &passThrough{})
+}
+
+// PassThroughResource returns the resource whose upstream body is the
+// whole result of the query evaluated with the given context. It is only
+// the case for queries with one statement, that is neither hidden, nor
+// filtered with `only`, nor aggregated into another one.
+func PassThroughResource(ctx context.Context) (domain.ResourceID, bool) {
+	p, ok := ctx.Value(passThroughKey{}).(*passThrough)
+	if !ok {
+		return "", false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.resourceID, p.eligible
+}
+
+// markPassThrough records the eligibility of the first query evaluated
+// with the context, so subqueries it executes are not considered.
+func markPassThrough(ctx context.Context, query domain.Query) {
+	p, ok := ctx.Value(passThroughKey{}).(*passThrough)
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.evaluated {
+		return
+	}
+	p.evaluated = true
+
+	if len(query.Statements) != 1 || len(query.Only) > 0 {
+		return
+	}
+
+	stmt := query.Statements[0]
+	if len(stmt.Only) > 0 || len(stmt.Compute) > 0 || len(stmt.ResultFunctions) > 0 || stmt.Hidden || len(stmt.In) > 0 {
+		return
+	}
+
+	p.eligible = true
+	p.resourceID = domain.NewResourceID(stmt)
+}
@@ -0,0 +1,204 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+// ErrExecutionNotFound is returned by Evaluator when the asked
+// recorded execution does not exist or was already discarded.
+var ErrExecutionNotFound = errors.New("recorded execution not found")
+
+// RecordedExecution represents a saved query execution kept by the
+// ExecutionRecorder, with the statement results as returned by the
+// upstreams, before any filter or aggregation is applied.
+type RecordedExecution struct {
+	ID       string                 `json:"id"`
+	At       time.Time              `json:"at"`
+	Tenant   string                 `json:"tenant"`
+	Revision int                    `json:"revision"`
+	Params   map[string]interface{} `json:"params,omitempty"`
+
+	query     string
+	input     restql.QueryInput
+	resources domain.Resources
+}
+
+// ExecutionRecorder keeps the most recent executions of each
+// saved query, so their responses can be rendered again.
+type ExecutionRecorder struct {
+	mu          sync.Mutex
+	maxPerQuery int
+	sequence    uint64
+	executions  map[string][]RecordedExecution
+}
+
+// NewExecutionRecorder constructs an ExecutionRecorder keeping
+// up to maxPerQuery executions of each saved query.
+func NewExecutionRecorder(maxPerQuery int) *ExecutionRecorder {
+	return &ExecutionRecorder{maxPerQuery: maxPerQuery, executions: make(map[string][]RecordedExecution)}
+}
+
+func (er *ExecutionRecorder) record(log restql.Logger, queryOpts restql.QueryOptions, queryTxt string, queryInput restql.QueryInput, resources domain.Resources) {
+	if er == nil || er.maxPerQuery <= 0 || queryOpts.Namespace == "" || queryOpts.Id == "" {
+		return
+	}
+
+	snapshot := make(domain.Resources, len(resources))
+	for id, r := range resources {
+		snapshot[id] = cloneResult(log, r)
+	}
+
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	er.sequence++
+	execution := RecordedExecution{
+		ID:        strconv.FormatUint(er.sequence, 10),
+		At:        time.Now(),
+		Tenant:    queryOpts.Tenant,
+		Revision:  queryOpts.Revision,
+		Params:    queryInput.Params,
+		query:     queryTxt,
+		input:     queryInput,
+		resources: snapshot,
+	}
+
+	key := executionsKey(queryOpts.Namespace, queryOpts.Id)
+	executions := append(er.executions[key], execution)
+	if len(executions) > er.maxPerQuery {
+		executions = executions[len(executions)-er.maxPerQuery:]
+	}
+	er.executions[key] = executions
+}
+
+// List returns the recorded executions of the saved query,
+// from the most recent to the oldest.
+func (er *ExecutionRecorder) List(namespace string, queryName string) []RecordedExecution {
+	if er == nil {
+		return nil
+	}
+
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	executions := er.executions[executionsKey(namespace, queryName)]
+	result := make([]RecordedExecution, len(executions))
+	for i, e := range executions {
+		result[len(executions)-1-i] = e
+	}
+
+	return result
+}
+
+func (er *ExecutionRecorder) get(namespace string, queryName string, id string) (RecordedExecution, bool) {
+	if er == nil {
+		return RecordedExecution{}, false
+	}
+
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	for _, e := range er.executions[executionsKey(namespace, queryName)] {
+		if e.ID == id {
+			return e, true
+		}
+	}
+
+	return RecordedExecution{}, false
+}
+
+func executionsKey(namespace string, queryName string) string {
+	return namespace + "/" + queryName
+}
+
+// RecordedExecutions returns the recorded executions of the saved
+// query, from the most recent to the oldest, or nil when the
+// recording is disabled.
+func (e Evaluator) RecordedExecutions(namespace string, queryName string) []RecordedExecution {
+	return e.recorder.List(namespace, queryName)
+}
+
+// RenderExecution applies the filters, computed fields, aggregations and hidden clauses
+// of the query to the results of a recorded execution of the saved
+// query, showing what a client would have received from it. The query
+// text defaults to the executed one and can only reference the
+// statements of the recorded execution.
+func (e Evaluator) RenderExecution(ctx context.Context, namespace string, queryName string, id string, queryTxt string) (domain.Resources, error) {
+	log := restql.GetLogger(ctx)
+
+	execution, found := e.recorder.get(namespace, queryName, id)
+	if !found {
+		return nil, ErrExecutionNotFound
+	}
+
+	if queryTxt == "" {
+		queryTxt = execution.query
+	}
+
+	query, err := e.parser.Parse(queryTxt)
+	if err != nil {
+		log.Debug("failed to parse query", "error", err)
+		return nil, fmt.Errorf("%w: invalid query syntax %s", ErrParser, err)
+	}
+
+	resources := make(domain.Resources, len(query.Statements))
+	for _, stmt := range query.Statements {
+		resourceID := domain.NewResourceID(stmt)
+		r, recorded := execution.resources[resourceID]
+		if !recorded {
+			return nil, fmt.Errorf("%w: statement %s is not in the recorded execution", ErrValidation, resourceID)
+		}
+		resources[resourceID] = cloneResult(log, r)
+	}
+
+	query = ResolveVariables(query, execution.input)
+
+	resources = ApplyResultFunctions(log, query, resources)
+	resources, err = ApplyFilters(log, query, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	resources = ApplyComputedFields(query, resources)
+	resources = ApplyAggregators(log, query, resources)
+	resources = ApplyHidden(query, resources, e.failOnHiddenErrors)
+
+	return resources, nil
+}
+
+// cloneResult copies the statement result with its own response
+// body, since filters replace the body value in place.
+func cloneResult(log restql.Logger, result interface{}) interface{} {
+	switch result := result.(type) {
+	case restql.DoneResource:
+		if result.ResponseBody == nil {
+			return result
+		}
+
+		body, err := json.Marshal(result.ResponseBody.Unmarshal())
+		if err != nil {
+			log.Debug("failed to copy recorded response body", "error", err)
+			return result
+		}
+		result.ResponseBody = restql.NewResponseBodyFromBytes(log, body)
+
+		return result
+	case restql.DoneResources:
+		list := make(restql.DoneResources, len(result))
+		for i, r := range result {
+			list[i] = cloneResult(log, r)
+		}
+		return list
+	default:
+		return result
+	}
+}
@@ -0,0 +1,92 @@
+package eval_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/internal/parser"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+type heroClient struct {
+	calls int64
+}
+
+func (c *heroClient) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	atomic.AddInt64(&c.calls, 1)
+	body := []byte(`{"id":"1","name":"Batman","city":"Gotham"}`)
+	return restql.HTTPResponse{URL: "http://hero.io/api", StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromBytes(test.NoOpLogger, body)}, nil
+}
+
+type staticQueries map[string]string
+
+func (s staticQueries) Get(ctx context.Context, namespace, id string, revision int) (restql.SavedQuery, error) {
+	return restql.SavedQuery{Name: id, Text: s[namespace+"/"+id], Revision: revision}, nil
+}
+
+func (s staticQueries) ListQueryRevisions(ctx context.Context, namespace, id string) ([]restql.SavedQuery, error) {
+	return nil, nil
+}
+
+func TestRenderExecution(t *testing.T) {
+	hero, err := restql.NewMapping("hero", "http://hero.io/api")
+	test.VerifyError(t, err)
+
+	p, err := parser.New()
+	test.VerifyError(t, err)
+
+	client := &heroClient{}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+	r := runner.NewRunner(test.NoOpLogger, executor, time.Second, runner.DefaultsCascade{}, nil, 0)
+	queries := staticQueries{"dc/heroes": "from hero only name"}
+	e := eval.NewEvaluator(test.NoOpLogger, staticMappings{"hero": hero}, queries, r, p, plugins.NoOpLifecycle, false, eval.NewExecutionRecorder(2))
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	options := restql.QueryOptions{Namespace: "dc", Id: "heroes", Revision: 1, Tenant: "DC"}
+	for i := 0; i < 3; i++ {
+		result, err := e.SavedQuery(ctx, options, restql.QueryInput{Params: map[string]interface{}{"run": i}})
+		test.VerifyError(t, err)
+		test.Equal(t, result["hero"].(restql.DoneResource).ResponseBody.Unmarshal(), map[string]interface{}{"name": "Batman"})
+	}
+
+	executions := e.RecordedExecutions("dc", "heroes")
+	test.Equal(t, len(executions), 2)
+	test.Equal(t, executions[0].ID, "3")
+	test.Equal(t, executions[1].ID, "2")
+	test.Equal(t, executions[0].Params, map[string]interface{}{"run": 2})
+
+	tests := []struct {
+		name     string
+		query    string
+		expected interface{}
+	}{
+		{"executed query", "", map[string]interface{}{"name": "Batman"}},
+		{"modified projection", "from hero only name, city", map[string]interface{}{"name": "Batman", "city": "Gotham"}},
+		{"without projection", "from hero", map[string]interface{}{"id": "1", "name": "Batman", "city": "Gotham"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := e.RenderExecution(ctx, "dc", "heroes", "3", tt.query)
+			test.VerifyError(t, err)
+			test.Equal(t, result["hero"].(restql.DoneResource).ResponseBody.Unmarshal(), tt.expected)
+		})
+	}
+	test.Equal(t, atomic.LoadInt64(&client.calls), int64(3))
+
+	_, err = e.RenderExecution(ctx, "dc", "heroes", "1", "")
+	test.Equal(t, errors.Is(err, eval.ErrExecutionNotFound), true)
+
+	_, err = e.RenderExecution(ctx, "dc", "heroes", "3", "from villain")
+	test.Equal(t, errors.Is(err, eval.ErrValidation), true)
+
+	test.Equal(t, len(e.RecordedExecutions("dc", "villains")), 0)
+}
@@ -0,0 +1,156 @@
+package eval
+
+import (
+	"encoding/json"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// ApplyResultFunctions returns a version of the already resolved
+// Resources with the statement results transformed by the functions
+// following the resource name, like `-> flatten` and `-> distinct`.
+func ApplyResultFunctions(log restql.Logger, query domain.Query, resources domain.Resources) domain.Resources {
+	for _, stmt := range query.Statements {
+		if len(stmt.ResultFunctions) == 0 {
+			continue
+		}
+
+		resourceID := domain.NewResourceID(stmt)
+		resources[resourceID] = applyResultFunctions(log, stmt.ResultFunctions, resources[resourceID])
+	}
+
+	return resources
+}
+
+func applyResultFunctions(log restql.Logger, functions []string, result interface{}) interface{} {
+	for _, fn := range functions {
+		switch fn {
+		case domain.FlattenResult:
+			result = flattenResult(log, result)
+		case domain.DistinctResult:
+			result = distinctResult(result)
+		}
+	}
+
+	return result
+}
+
+// flattenResult collapses the responses of a multiplexed statement
+// into a single result, whose body is the list of every successful
+// response body, with the lists they contain flattened. The result
+// takes the details of the first response, or of the first failed
+// one, when there is any.
+func flattenResult(log restql.Logger, result interface{}) interface{} {
+	switch result := result.(type) {
+	case restql.DoneResource:
+		if failedResponse(result) {
+			return result
+		}
+
+		if list, ok := result.ResponseBody.Unmarshal().([]interface{}); ok {
+			result.ResponseBody.SetValue(flattenList(list))
+		}
+		return result
+	case restql.DoneResources:
+		responses := multiplexedResponses(result)
+		if len(responses) == 0 {
+			return result
+		}
+
+		combined := responses[0]
+		body := []interface{}{}
+		for _, r := range responses {
+			if r.ResponseTime > combined.ResponseTime {
+				combined.ResponseTime = r.ResponseTime
+			}
+
+			if failedResponse(r) {
+				if !failedResponse(combined) {
+					combined.Status = r.Status
+					combined.Success = false
+				}
+				continue
+			}
+
+			body = append(body, flattenList([]interface{}{r.ResponseBody.Unmarshal()})...)
+		}
+		combined.ResponseBody = restql.NewResponseBodyFromValue(log, body)
+
+		return combined
+	default:
+		return result
+	}
+}
+
+func multiplexedResponses(result restql.DoneResources) []restql.DoneResource {
+	var responses []restql.DoneResource
+	for _, r := range result {
+		switch r := r.(type) {
+		case restql.DoneResource:
+			responses = append(responses, r)
+		case restql.DoneResources:
+			responses = append(responses, multiplexedResponses(r)...)
+		}
+	}
+	return responses
+}
+
+func flattenList(list []interface{}) []interface{} {
+	result := []interface{}{}
+	for _, v := range list {
+		if l, ok := v.([]interface{}); ok {
+			result = append(result, flattenList(l)...)
+			continue
+		}
+
+		if v != nil {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// distinctResult removes the repeated elements of list bodies,
+// keeping the first occurrence of each one.
+func distinctResult(result interface{}) interface{} {
+	switch result := result.(type) {
+	case restql.DoneResource:
+		if failedResponse(result) {
+			return result
+		}
+
+		list, ok := result.ResponseBody.Unmarshal().([]interface{})
+		if !ok {
+			return result
+		}
+
+		seen := make(map[string]struct{}, len(list))
+		distinct := make([]interface{}, 0, len(list))
+		for _, v := range list {
+			key, err := json.Marshal(v)
+			if err == nil {
+				if _, found := seen[string(key)]; found {
+					continue
+				}
+				seen[string(key)] = struct{}{}
+			}
+			distinct = append(distinct, v)
+		}
+		result.ResponseBody.SetValue(distinct)
+
+		return result
+	case restql.DoneResources:
+		list := make(restql.DoneResources, len(result))
+		for i, r := range result {
+			list[i] = distinctResult(r)
+		}
+		return list
+	default:
+		return result
+	}
+}
+
+func failedResponse(result restql.DoneResource) bool {
+	return result.ResponseBody == nil || result.Status < 200 || result.Status >= 400
+}
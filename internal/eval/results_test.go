@@ -0,0 +1,90 @@
+package eval_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestApplyResultFunctions(t *testing.T) {
+	response := func(status int, body string) restql.DoneResource {
+		return restql.DoneResource{Status: status, Success: status < 400, ResponseTime: int64(status), ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(body))}
+	}
+
+	tests := []struct {
+		name           string
+		functions      []string
+		result         interface{}
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			"should flatten multiplexed responses into one list",
+			[]string{domain.FlattenResult},
+			restql.DoneResources{
+				response(http.StatusOK, `[{ "id": 1 }, { "id": 2 }]`),
+				restql.DoneResources{response(http.StatusOK, `{ "id": 3 }`), response(http.StatusOK, `[[{ "id": 1 }]]`)},
+			},
+			http.StatusOK,
+			test.Unmarshal(`[{ "id": 1 }, { "id": 2 }, { "id": 3 }, { "id": 1 }]`),
+		},
+		{
+			"should flatten and remove duplicated elements",
+			[]string{domain.FlattenResult, domain.DistinctResult},
+			restql.DoneResources{
+				response(http.StatusOK, `[{ "id": 1, "tags": ["a"] }, { "id": 2 }]`),
+				response(http.StatusOK, `[{ "tags": ["a"], "id": 1 }, "x", "x"]`),
+			},
+			http.StatusOK,
+			test.Unmarshal(`[{ "id": 1, "tags": ["a"] }, { "id": 2 }, "x"]`),
+		},
+		{
+			"should keep status of failed response when flattening",
+			[]string{domain.FlattenResult},
+			restql.DoneResources{
+				response(http.StatusOK, `[{ "id": 1 }]`),
+				response(http.StatusNotFound, `{ "message": "not found" }`),
+			},
+			http.StatusNotFound,
+			test.Unmarshal(`[{ "id": 1 }]`),
+		},
+		{
+			"should flatten lists of single response",
+			[]string{domain.FlattenResult},
+			response(http.StatusOK, `[[1, 2], [3, [4]]]`),
+			http.StatusOK,
+			test.Unmarshal(`[1, 2, 3, 4]`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := domain.Query{Statements: []domain.Statement{{Resource: "hero", ResultFunctions: tt.functions}}}
+
+			got := eval.ApplyResultFunctions(test.NoOpLogger, query, domain.Resources{"hero": tt.result})
+
+			dr := got["hero"].(restql.DoneResource)
+			test.Equal(t, dr.Status, tt.expectedStatus)
+			test.Equal(t, dr.ResponseBody.Unmarshal(), tt.expectedBody)
+		})
+	}
+}
+
+func TestApplyDistinctOnMultiplexedResult(t *testing.T) {
+	query := domain.Query{Statements: []domain.Statement{{Resource: "hero", ResultFunctions: []string{domain.DistinctResult}}}}
+	resources := domain.Resources{"hero": restql.DoneResources{
+		restql.DoneResource{Status: http.StatusOK, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`[1, 2, 2]`))},
+		restql.DoneResource{Status: http.StatusOK, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`[2, 2]`))},
+	}}
+
+	got := eval.ApplyResultFunctions(test.NoOpLogger, query, resources)
+
+	list := got["hero"].(restql.DoneResources)
+	test.Equal(t, len(list), 2)
+	test.Equal(t, list[0].(restql.DoneResource).ResponseBody.Unmarshal(), test.Unmarshal(`[1, 2]`))
+	test.Equal(t, list[1].(restql.DoneResource).ResponseBody.Unmarshal(), test.Unmarshal(`[2]`))
+}
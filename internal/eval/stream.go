@@ -0,0 +1,130 @@
+package eval
+
+import (
+	"sync"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// streamObserver adapts the StatementObserver and ItemObserver to the
+// runner, skipping hidden statements and applying the filters on a copy
+// of each result, so the response built once the query is done is not
+// affected. Chained comparison arguments and computed fields are
+// resolved against the statements already done.
+type streamObserver struct {
+	mu          sync.Mutex
+	log         restql.Logger
+	statements  map[domain.ResourceID]domain.Statement
+	resourceIDs map[string]bool
+	done        domain.Resources
+}
+
+func newStreamObserver(log restql.Logger, query domain.Query) *streamObserver {
+	statements := make(map[domain.ResourceID]domain.Statement, len(query.Statements))
+	resourceIDs := make(map[string]bool, len(query.Statements))
+	for _, stmt := range query.Statements {
+		statements[domain.NewResourceID(stmt)] = stmt
+		resourceIDs[string(domain.NewResourceID(stmt))] = true
+	}
+
+	return &streamObserver{
+		log:         log,
+		statements:  statements,
+		resourceIDs: resourceIDs,
+		done:        make(domain.Resources, len(query.Statements)),
+	}
+}
+
+// observeStatements notifies the observer of each statement result,
+// except for the unordered multiplexed statements, whose items are
+// notified on their own as soon as they are done.
+func (so *streamObserver) observeStatements(observer StatementObserver) runner.DoneObserver {
+	return func(resourceID domain.ResourceID, response interface{}) {
+		so.mu.Lock()
+		so.done[resourceID] = response
+		so.mu.Unlock()
+
+		stmt, found := so.statements[resourceID]
+		if !found || stmt.Hidden {
+			return
+		}
+
+		if _, multiplexed := response.(restql.DoneResources); multiplexed && stmt.Unordered {
+			return
+		}
+
+		result := applyResultFunctions(so.log, stmt.ResultFunctions, copyResult(so.log, response))
+		filtered, err := so.filter(stmt, result)
+		if err != nil {
+			so.log.Error("failed to apply filter on streamed statement", err, "resource", resourceID)
+			return
+		}
+
+		observer(resourceID, filtered)
+	}
+}
+
+// observeItems notifies the observer of each item of the unordered
+// multiplexed statements. Result functions, like flatten, apply to
+// the whole statement result and hence are not applied to the items.
+func (so *streamObserver) observeItems(observer ItemObserver) runner.ItemObserver {
+	return func(resourceID domain.ResourceID, index int, response interface{}) {
+		stmt, found := so.statements[resourceID]
+		if !found || stmt.Hidden {
+			return
+		}
+
+		filtered, err := so.filter(stmt, copyResult(so.log, response))
+		if err != nil {
+			so.log.Error("failed to apply filter on streamed item", err, "resource", resourceID, "index", index)
+			return
+		}
+
+		observer(resourceID, index, filtered)
+	}
+}
+
+func (so *streamObserver) filter(stmt domain.Statement, result interface{}) (interface{}, error) {
+	so.mu.Lock()
+	defer so.mu.Unlock()
+
+	only := resolveFilterChains(stmt.Only, so.done)
+	filtered, err := applyOnlyFilters(only, stmt.FilterErrors, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stmt.Compute) > 0 {
+		filtered = computeFields(stmt.Compute, so.resourceIDs, filtered, so.done)
+	}
+
+	return filtered, nil
+}
+
+func copyResult(log restql.Logger, response interface{}) interface{} {
+	switch response := response.(type) {
+	case restql.DoneResource:
+		if response.ResponseBody == nil {
+			return response
+		}
+
+		body := response.ResponseBody
+		if body.Value() != nil {
+			response.ResponseBody = restql.NewResponseBodyFromValue(log, body.Value())
+		} else {
+			response.ResponseBody = restql.NewResponseBodyFromBytes(log, body.Bytes())
+		}
+
+		return response
+	case restql.DoneResources:
+		list := make(restql.DoneResources, len(response))
+		for i, r := range response {
+			list[i] = copyResult(log, r)
+		}
+		return list
+	default:
+		return response
+	}
+}
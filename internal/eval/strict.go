@@ -0,0 +1,84 @@
+package eval
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// validateStrictInput returns an error if the query references
+// variables the client input does not provide and that have no
+// default value, or the client gives query parameters the query
+// never references. The tenant and the parameters prefixed by an
+// underscore, which control the execution, are not expected to be
+// referenced.
+func validateStrictInput(query domain.Query, input restql.QueryInput) error {
+	variables := QueryVariables(query)
+	defaulted := defaultedVariables(query)
+
+	var missing []string
+	referenced := make(map[string]struct{}, len(variables))
+	for _, name := range variables {
+		referenced[name] = struct{}{}
+		if _, found := getUniqueParamValue(name, input); !found {
+			if _, hasDefault := defaulted[name]; !hasDefault {
+				missing = append(missing, name)
+			}
+		}
+	}
+
+	var unused []string
+	for name := range input.Params {
+		if _, found := referenced[name]; found || name == "tenant" || strings.HasPrefix(name, "_") {
+			continue
+		}
+		unused = append(unused, name)
+	}
+	sort.Strings(unused)
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing params "+strings.Join(missing, ", "))
+	}
+	if len(unused) > 0 {
+		problems = append(problems, "unused params "+strings.Join(unused, ", "))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: strict query has %s", ErrValidation, strings.Join(problems, " and "))
+	}
+
+	return nil
+}
+
+// defaultedVariables returns the variables given to `with`
+// parameters that fall back to a default value.
+func defaultedVariables(query domain.Query) map[string]struct{} {
+	seen := make(map[string]struct{})
+	for _, stmt := range query.Statements {
+		for _, value := range stmt.With.Values {
+			collectDefaultedVariables(value, seen)
+		}
+	}
+	return seen
+}
+
+func collectDefaultedVariables(value interface{}, seen map[string]struct{}) {
+	switch value := value.(type) {
+	case domain.DefaultValue:
+		collectVariables(value.Value, seen)
+	case domain.Function:
+		collectDefaultedVariables(value.Target(), seen)
+	case []interface{}:
+		for _, v := range value {
+			collectDefaultedVariables(v, seen)
+		}
+	case map[string]interface{}:
+		for _, v := range value {
+			collectDefaultedVariables(v, seen)
+		}
+	}
+}
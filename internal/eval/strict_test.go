@@ -0,0 +1,85 @@
+package eval_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/internal/parser"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestStrictQuery(t *testing.T) {
+	hero, err := restql.NewMapping("hero", "http://hero.io/api")
+	test.VerifyError(t, err)
+
+	p, err := parser.New()
+	test.VerifyError(t, err)
+
+	executor := runner.NewExecutor(test.NoOpLogger, &heroClient{}, nil, nil, nil, time.Second, "", nil)
+	r := runner.NewRunner(test.NoOpLogger, executor, time.Second, runner.DefaultsCascade{}, nil, 0)
+	e := eval.NewEvaluator(test.NoOpLogger, staticMappings{"hero": hero}, nil, r, p, plugins.NoOpLifecycle, false, nil)
+
+	tests := []struct {
+		name        string
+		query       string
+		input       restql.QueryInput
+		expectedErr string
+	}{
+		{
+			"should execute strict query with every param used",
+			"use strict\nfrom hero with id = $id",
+			restql.QueryInput{Params: map[string]interface{}{"id": "1", "tenant": "DC", "_debug": "true"}},
+			"",
+		},
+		{
+			"should execute strict query with param given by header",
+			"use strict\nfrom hero with id = $id",
+			restql.QueryInput{Headers: map[string]string{"id": "1"}},
+			"",
+		},
+		{
+			"should reject strict query with missing param",
+			"use strict\nfrom hero with id = $id, name = $name",
+			restql.QueryInput{Params: map[string]interface{}{"id": "1"}},
+			"validation error: strict query has missing params name",
+		},
+		{
+			"should execute strict query with missing param that has default value",
+			"use strict\nfrom hero with id = $id, name = $name -> default(\"Batman\")",
+			restql.QueryInput{Params: map[string]interface{}{"id": "1"}},
+			"",
+		},
+		{
+			"should reject strict query with unused params",
+			"use strict\nfrom hero with id = $id",
+			restql.QueryInput{Params: map[string]interface{}{"id": "1", "nmae": "Batman", "city": "Gotham"}},
+			"validation error: strict query has unused params city, nmae",
+		},
+		{
+			"should execute query with unused params when not strict",
+			"from hero with id = $id",
+			restql.QueryInput{Params: map[string]interface{}{"nmae": "Batman"}},
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+			_, err := e.AdHocQuery(ctx, tt.query, restql.QueryOptions{Tenant: "DC"}, tt.input)
+
+			if tt.expectedErr == "" {
+				test.VerifyError(t, err)
+				return
+			}
+			test.Equal(t, errors.Is(err, eval.ErrValidation), true)
+			test.Equal(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
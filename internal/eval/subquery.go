@@ -0,0 +1,228 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+// MaxSubqueryDepth is the maximum number of nested saved
+// queries a query execution can go through.
+const MaxSubqueryDepth = 5
+
+var (
+	errSubqueryCycle        = errors.New("subquery cycle detected")
+	errSubqueryTooDeep      = errors.New("subquery nesting too deep")
+	errSubqueryInvalidQuery = errors.New("subquery statements must use the from method")
+)
+
+type subqueryPathKey struct{}
+
+// runSubquery executes the saved query targeted by a statement
+// with the statement parameters as input, sharing the parent
+// query tenant, headers, deadline and the evaluator caches.
+//
+// The result is returned as a single resource, whose body
+// holds the result of each of the saved query statements.
+func (e Evaluator) runSubquery(ctx context.Context, subquery domain.Subquery, statement domain.Statement, queryCtx restql.QueryContext) restql.DoneResource {
+	log := restql.GetLogger(ctx)
+	start := time.Now()
+
+	dr, err := e.doSubquery(ctx, subquery, statement, queryCtx)
+	if err != nil {
+		log.Debug("subquery execution failed", "error", err, "resource", statement.Resource)
+		dr = restql.DoneResource{
+			Status:       subqueryErrorStatus(err),
+			ResponseBody: restql.NewResponseBodyFromValue(log, err.Error()),
+		}
+	}
+
+	dr.Method = statement.Method
+	dr.URL = statement.Resource
+	dr.RequestParams = statement.With.Values
+	dr.ResponseTime = time.Since(start).Milliseconds()
+
+	return dr
+}
+
+func (e Evaluator) doSubquery(ctx context.Context, subquery domain.Subquery, statement domain.Statement, queryCtx restql.QueryContext) (restql.DoneResource, error) {
+	log := restql.GetLogger(ctx)
+
+	tenant := queryCtx.Options.Tenant
+	if !e.runner.AllowsNamespace(tenant, subquery.Namespace) {
+		return restql.DoneResource{}, fmt.Errorf("%w: %s is not available for tenant %s", restql.ErrNamespaceNotFound, subquery.Namespace, tenant)
+	}
+
+	savedQuery, err := e.findSubquery(ctx, subquery)
+	if err != nil {
+		return restql.DoneResource{}, err
+	}
+
+	options := restql.QueryOptions{
+		Namespace: subquery.Namespace,
+		Id:        subquery.ID,
+		Revision:  savedQuery.Revision,
+		Tenant:    queryCtx.Options.Tenant,
+	}
+
+	input := restql.QueryInput{
+		Params:  statement.With.Values,
+		Headers: queryCtx.Input.Headers,
+	}
+
+	resources, err := e.evaluateQuery(ctx, savedQuery.Text, options, input, nil, nil)
+	if err != nil {
+		return restql.DoneResource{}, err
+	}
+
+	body := make(map[string]interface{}, len(resources))
+	for resourceID, resource := range resources {
+		body[string(resourceID)] = subqueryResultBody(resource)
+	}
+
+	status := subqueryStatus(resources)
+
+	return restql.DoneResource{
+		Status:       status,
+		Success:      status < 400,
+		ResponseBody: restql.NewResponseBodyFromValue(log, body),
+	}, nil
+}
+
+func (e Evaluator) findSubquery(ctx context.Context, subquery domain.Subquery) (restql.SavedQuery, error) {
+	if subquery.Revision > 0 {
+		return e.queryReader.Get(ctx, subquery.Namespace, subquery.ID, subquery.Revision)
+	}
+
+	revisions, err := e.queryReader.ListQueryRevisions(ctx, subquery.Namespace, subquery.ID)
+	if err != nil {
+		return restql.SavedQuery{}, err
+	}
+
+	var latest restql.SavedQuery
+	for _, r := range revisions {
+		if r.Revision > latest.Revision {
+			latest = r
+		}
+	}
+
+	if latest.Revision == 0 {
+		return restql.SavedQuery{}, restql.ErrQueryNotFound
+	}
+
+	return latest, nil
+}
+
+// withSubqueryPath returns a context tracking the saved queries
+// being executed, failing if the query is already being executed
+// by a parent or the nesting limit is reached.
+func withSubqueryPath(ctx context.Context, queryOpts restql.QueryOptions) (context.Context, error) {
+	if queryOpts.Id == "" {
+		return ctx, nil
+	}
+
+	identifier := queryOpts.Namespace + "/" + queryOpts.Id + "/" + strconv.Itoa(queryOpts.Revision)
+
+	path, _ := ctx.Value(subqueryPathKey{}).([]string)
+	for _, p := range path {
+		if p == identifier {
+			return nil, fmt.Errorf("%w: %s", errSubqueryCycle, identifier)
+		}
+	}
+
+	if len(path) >= MaxSubqueryDepth {
+		return nil, fmt.Errorf("%w: %s", errSubqueryTooDeep, identifier)
+	}
+
+	newPath := make([]string, len(path), len(path)+1)
+	copy(newPath, path)
+
+	return context.WithValue(ctx, subqueryPathKey{}, append(newPath, identifier)), nil
+}
+
+func validateSubqueries(query domain.Query) error {
+	for _, s := range query.Statements {
+		if _, ok := domain.ParseSubquery(s.Resource); ok && s.Method != domain.FromMethod {
+			return fmt.Errorf("%w: %s", ErrValidation, errSubqueryInvalidQuery)
+		}
+	}
+
+	return nil
+}
+
+func subqueryResultBody(resource interface{}) interface{} {
+	switch resource := resource.(type) {
+	case restql.DoneResource:
+		if resource.ResponseBody == nil {
+			return nil
+		}
+		return resource.ResponseBody.Unmarshal()
+	case restql.DoneResources:
+		result := make([]interface{}, len(resource))
+		for i, r := range resource {
+			result[i] = subqueryResultBody(r)
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// subqueryStatus is the highest status code among the saved query
+// statements, disregarding the ones that ignore errors, like the
+// status code of the query response.
+func subqueryStatus(resources domain.Resources) int {
+	status := http.StatusOK
+	for _, r := range resources {
+		if s := resourceStatus(r); s > status {
+			status = s
+		}
+	}
+
+	return status
+}
+
+func resourceStatus(resource interface{}) int {
+	switch resource := resource.(type) {
+	case restql.DoneResource:
+		if resource.IgnoreErrors || resource.Status == http.StatusCreated || resource.Status == http.StatusNoContent {
+			return http.StatusOK
+		}
+		if resource.Status == 0 {
+			return http.StatusInternalServerError
+		}
+		return resource.Status
+	case restql.DoneResources:
+		status := http.StatusOK
+		for _, r := range resource {
+			if s := resourceStatus(r); s > status {
+				status = s
+			}
+		}
+		return status
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func subqueryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, restql.ErrQueryNotFound), errors.Is(err, restql.ErrQueryNotFoundInDatabase), errors.Is(err, restql.ErrNamespaceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errSubqueryCycle), errors.Is(err, errSubqueryTooDeep):
+		return http.StatusLoopDetected
+	case errors.Is(err, ErrTimeout), errors.Is(err, runner.ErrQueryTimedOut):
+		return http.StatusRequestTimeout
+	case errors.Is(err, ErrValidation), errors.Is(err, ErrParser), errors.Is(err, ErrMapping):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
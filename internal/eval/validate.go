@@ -0,0 +1,218 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/parser"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// Codes of the issues found by query validation.
+const (
+	SyntaxIssue          = "syntax"
+	UnknownResourceIssue = "unknown-resource"
+	InvalidChainIssue    = "invalid-chain"
+	ChainCycleIssue      = "chain-cycle"
+	UnusedStatementIssue = "unused-statement"
+)
+
+// ValidationIssue represents a problem found in a query,
+// where Line and Column are only defined for syntax errors.
+type ValidationIssue struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Statement string `json:"statement,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+}
+
+// ValidationReport represents the outcome of a query validation.
+// The query is valid when there are no errors, even with warnings.
+type ValidationReport struct {
+	Valid    bool              `json:"valid"`
+	Errors   []ValidationIssue `json:"errors,omitempty"`
+	Warnings []ValidationIssue `json:"warnings,omitempty"`
+}
+
+// ValidateQuery checks an ad-hoc query without executing it, reporting
+// syntax errors, statements referencing resources not mapped for the
+// tenant, chained parameters targeting unknown statements or forming
+// a cycle, and hidden statements whose results are never used.
+// The resources are not checked when the tenant is empty.
+func (e Evaluator) ValidateQuery(ctx context.Context, queryTxt string, tenant string) (ValidationReport, error) {
+	log := restql.GetLogger(ctx)
+	report := ValidationReport{}
+
+	query, err := e.parser.Parse(queryTxt)
+	if err != nil {
+		log.Debug("failed to parse query", "error", err)
+
+		issue := ValidationIssue{Code: SyntaxIssue, Message: err.Error()}
+		issue.Line, issue.Column, _ = parser.ErrorPosition(err)
+		report.Errors = append(report.Errors, issue)
+
+		return report, nil
+	}
+
+	if tenant != "" {
+		mappings, err := e.mappingsReader.FromTenant(ctx, tenant)
+		if err != nil {
+			log.Error("failed to fetch mappings", err)
+			return ValidationReport{}, err
+		}
+
+		report.Errors = append(report.Errors, unknownResources(query, mappings, e.mockedResources(tenant))...)
+	}
+
+	resources := domain.NewResources(query.Statements)
+	for _, stmt := range query.Statements {
+		for _, chain := range statementChains(stmt) {
+			target := chain[0]
+			if _, found := resources[domain.ResourceID(target)]; !found {
+				report.Errors = append(report.Errors, ValidationIssue{
+					Code:      InvalidChainIssue,
+					Message:   fmt.Sprintf("chained parameter %s targets an unknown statement", strings.Join(chain, ".")),
+					Statement: string(domain.NewResourceID(stmt)),
+				})
+			}
+		}
+	}
+
+	if err := runner.ValidateChainDependencies(resources, 0); err != nil {
+		report.Errors = append(report.Errors, ValidationIssue{Code: ChainCycleIssue, Message: err.Error()})
+	}
+
+	for _, resourceID := range unusedHiddenStatements(query) {
+		report.Warnings = append(report.Warnings, ValidationIssue{
+			Code:      UnusedStatementIssue,
+			Message:   unusedHiddenMessage,
+			Statement: resourceID,
+		})
+	}
+
+	report.Valid = len(report.Errors) == 0
+	return report, nil
+}
+
+const unusedHiddenMessage = "hidden statement is not referenced by any chained parameter"
+
+// unusedHiddenStatements returns the hidden statements that are neither
+// referenced by a chained parameter nor aggregated into another
+// statement, as their results are executed but never used.
+func unusedHiddenStatements(query domain.Query) []string {
+	referenced := make(map[string]bool)
+	for _, stmt := range query.Statements {
+		for _, chain := range statementChains(stmt) {
+			referenced[chain[0]] = true
+		}
+	}
+
+	var unused []string
+	for _, stmt := range query.Statements {
+		resourceID := string(domain.NewResourceID(stmt))
+		if stmt.Hidden && len(stmt.In) == 0 && !referenced[resourceID] {
+			unused = append(unused, resourceID)
+		}
+	}
+
+	return unused
+}
+
+// unusedHiddenWarnings reports the hidden statements
+// never used as warnings of the query execution.
+func unusedHiddenWarnings(query domain.Query) []domain.Warning {
+	var warnings []domain.Warning
+	for _, resourceID := range unusedHiddenStatements(query) {
+		warnings = append(warnings, domain.Warning{
+			Code:      domain.UnusedHiddenWarning,
+			Statement: resourceID,
+			Message:   unusedHiddenMessage,
+		})
+	}
+
+	return warnings
+}
+
+func unknownResources(query domain.Query, mappings map[string]restql.Mapping, hasMock func(resource string) bool) []ValidationIssue {
+	var issues []ValidationIssue
+	for _, s := range query.Statements {
+		if _, isSubquery := domain.ParseSubquery(s.Resource); isSubquery {
+			continue
+		}
+
+		if _, found := mappings[s.Resource]; !found && !hasMock(s.Resource) {
+			issues = append(issues, ValidationIssue{
+				Code:      UnknownResourceIssue,
+				Message:   fmt.Sprintf("resource %s is not mapped", s.Resource),
+				Statement: string(domain.NewResourceID(s)),
+			})
+		}
+	}
+
+	return issues
+}
+
+// statementChains returns the paths of the chained values in the
+// statement parameters, headers, timeout and cache control, sorted
+// for a stable report.
+func statementChains(stmt domain.Statement) [][]string {
+	var chains [][]string
+	for _, value := range stmt.With.Values {
+		chains = collectChains(value, chains)
+	}
+	for _, value := range stmt.Headers {
+		chains = collectChains(value, chains)
+	}
+	chains = collectChains(stmt.Timeout, chains)
+	chains = collectChains(stmt.CacheControl.MaxAge, chains)
+	chains = collectChains(stmt.CacheControl.SMaxAge, chains)
+
+	sort.Slice(chains, func(i, j int) bool {
+		return strings.Join(chains[i], ".") < strings.Join(chains[j], ".")
+	})
+
+	return chains
+}
+
+func collectChains(value interface{}, chains [][]string) [][]string {
+	switch value := value.(type) {
+	case domain.Chain:
+		if len(value) == 0 {
+			return chains
+		}
+
+		path := make([]string, 0, len(value))
+		for _, segment := range value {
+			switch segment := segment.(type) {
+			case string:
+				path = append(path, segment)
+			case domain.Variable:
+				path = append(path, "$"+segment.Target)
+			default:
+				path = append(path, fmt.Sprintf("%v", segment))
+			}
+		}
+		chains = append(chains, path)
+	case domain.Range:
+		chains = collectChains(value.Start, chains)
+		chains = collectChains(value.End, chains)
+		chains = collectChains(value.Step, chains)
+	case domain.Function:
+		chains = collectChains(value.Target(), chains)
+	case []interface{}:
+		for _, v := range value {
+			chains = collectChains(v, chains)
+		}
+	case map[string]interface{}:
+		for _, v := range value {
+			chains = collectChains(v, chains)
+		}
+	}
+
+	return chains
+}
@@ -0,0 +1,119 @@
+package eval_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/internal/parser"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+type staticMappings map[string]restql.Mapping
+
+func (s staticMappings) FromTenant(ctx context.Context, tenant string) (map[string]restql.Mapping, error) {
+	return s, nil
+}
+
+func TestValidateQuery(t *testing.T) {
+	hero, err := restql.NewMapping("hero", "http://hero.io/api")
+	test.VerifyError(t, err)
+	sidekick, err := restql.NewMapping("sidekick", "http://sidekick.io/api")
+	test.VerifyError(t, err)
+
+	p, err := parser.New()
+	test.VerifyError(t, err)
+
+	r := runner.NewRunner(test.NoOpLogger, runner.Executor{}, time.Second, runner.DefaultsCascade{}, nil, 0)
+	mappings := staticMappings{"hero": hero, "sidekick": sidekick}
+	e := eval.NewEvaluator(test.NoOpLogger, mappings, nil, r, p, plugins.NoOpLifecycle, false, nil)
+
+	tests := []struct {
+		name     string
+		query    string
+		tenant   string
+		expected eval.ValidationReport
+	}{
+		{
+			"valid query",
+			"from hero\nfrom sidekick with hero = hero.id",
+			"DC",
+			eval.ValidationReport{Valid: true},
+		},
+		{
+			"syntax error with position",
+			"from hero\nfrom sidekick wit id = 1",
+			"DC",
+			eval.ValidationReport{Errors: []eval.ValidationIssue{
+				{Code: eval.SyntaxIssue, Line: 2, Column: 15},
+			}},
+		},
+		{
+			"unknown resource",
+			"from hero\nfrom villain",
+			"DC",
+			eval.ValidationReport{Errors: []eval.ValidationIssue{
+				{Code: eval.UnknownResourceIssue, Message: "resource villain is not mapped", Statement: "villain"},
+			}},
+		},
+		{
+			"resources are not checked without tenant",
+			"from villain",
+			"",
+			eval.ValidationReport{Valid: true},
+		},
+		{
+			"chain targeting unknown statement",
+			"from hero with id = villain.hero.id",
+			"DC",
+			eval.ValidationReport{Errors: []eval.ValidationIssue{
+				{Code: eval.InvalidChainIssue, Message: "chained parameter villain.hero.id targets an unknown statement", Statement: "hero"},
+			}},
+		},
+		{
+			"chain cycle",
+			"from hero with id = sidekick.hero\nfrom sidekick with hero = hero.id",
+			"DC",
+			eval.ValidationReport{Errors: []eval.ValidationIssue{
+				{Code: eval.ChainCycleIssue, Message: "chained parameters form a cycle : hero -> sidekick -> hero"},
+			}},
+		},
+		{
+			"unused hidden statement",
+			"from hero hidden\nfrom sidekick",
+			"DC",
+			eval.ValidationReport{Valid: true, Warnings: []eval.ValidationIssue{
+				{Code: eval.UnusedStatementIssue, Message: "hidden statement is not referenced by any chained parameter", Statement: "hero"},
+			}},
+		},
+		{
+			"hidden statement aggregated into another",
+			"from hero\nfrom sidekick in hero.sidekick hidden",
+			"DC",
+			eval.ValidationReport{Valid: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+
+			got, err := e.ValidateQuery(ctx, tt.query, tt.tenant)
+			test.VerifyError(t, err)
+
+			// Syntax messages come from the generated parser,
+			// so only their position is verified.
+			for i := range got.Errors {
+				if got.Errors[i].Code == eval.SyntaxIssue {
+					got.Errors[i].Message = ""
+				}
+			}
+
+			test.Equal(t, got, tt.expected)
+		})
+	}
+}
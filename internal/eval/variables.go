@@ -3,6 +3,7 @@ package eval
 import (
 	"encoding/json"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"sort"
 	"strconv"
 
 	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
@@ -21,11 +22,12 @@ func ResolveVariables(query domain.Query, input restql.QueryInput) domain.Query
 		copyStmt.Headers = resolveHeaders(copyStmt.Headers, input)
 		copyStmt.CacheControl = resolveCacheControl(copyStmt.CacheControl, input)
 		copyStmt.Only = resolveOnly(copyStmt.Only, input)
+		copyStmt.Compute = resolveCompute(copyStmt.Compute, input)
 
 		result[i] = copyStmt
 	}
 
-	return domain.Query{Use: query.Use, Statements: result}
+	return domain.Query{Use: query.Use, Only: resolveOnly(query.Only, input), Statements: result}
 }
 
 func resolveWith(with domain.Params, input restql.QueryInput) domain.Params {
@@ -55,6 +57,13 @@ func resolveWithParamValue(value interface{}, input restql.QueryInput) (interfac
 		return getUniqueParamValue(value.Target, input)
 	case domain.Chain:
 		return resolveChain(value, input)
+	case domain.Range:
+		return resolveRange(value, input)
+	case domain.DefaultValue:
+		return resolveDefaultValue(value, input)
+	case domain.Expression:
+		v, ok := resolveWithParamValue(value.Value, input)
+		return domain.Expression{Value: v, Program: value.Program, Variables: resolveExpressionVariables(value.Variables, input)}, ok
 	case domain.Function:
 		v, ok := resolveWithParamValue(value.Target(), input)
 		fnValue := value.Map(func(target interface{}) interface{} { return v })
@@ -68,6 +77,93 @@ func resolveWithParamValue(value interface{}, input restql.QueryInput) (interfac
 	}
 }
 
+// resolveDefaultValue returns the default when the value cannot
+// be resolved from the input, keeping it for the chains, which
+// are only resolved during the execution.
+func resolveDefaultValue(dv domain.DefaultValue, input restql.QueryInput) (interface{}, bool) {
+	defaultValue, defaultOk := resolveWithParamValue(dv.Default, input)
+
+	v, ok := resolveWithParamValue(dv.Value, input)
+	if !ok {
+		return defaultValue, defaultOk
+	}
+
+	if _, isChain := v.(domain.Chain); isChain && defaultOk {
+		return domain.DefaultValue{Value: v, Default: defaultValue}, true
+	}
+
+	return v, true
+}
+
+// resolveExpressionVariables returns the values of the variables
+// referenced by an expression, leaving out the ones not found.
+func resolveExpressionVariables(variables map[string]interface{}, input restql.QueryInput) map[string]interface{} {
+	if len(variables) == 0 {
+		return variables
+	}
+
+	result := make(map[string]interface{}, len(variables))
+	for name := range variables {
+		if v, found := getUniqueParamValue(name, input); found {
+			result[name] = v
+		}
+	}
+
+	return result
+}
+
+func resolveCompute(fields []domain.ComputedField, input restql.QueryInput) []domain.ComputedField {
+	if fields == nil {
+		return nil
+	}
+
+	result := make([]domain.ComputedField, len(fields))
+	for i, f := range fields {
+		f.Variables = resolveExpressionVariables(f.Variables, input)
+		result[i] = f
+	}
+
+	return result
+}
+
+func resolveRange(r domain.Range, input restql.QueryInput) (domain.Range, bool) {
+	start, ok := resolveRangeArg(r.Start, input)
+	if !ok {
+		return r, false
+	}
+
+	end, ok := resolveRangeArg(r.End, input)
+	if !ok {
+		return r, false
+	}
+
+	step, ok := resolveRangeArg(r.Step, input)
+	if !ok {
+		return r, false
+	}
+
+	return domain.Range{Start: start, End: end, Step: step}, true
+}
+
+func resolveRangeArg(arg interface{}, input restql.QueryInput) (interface{}, bool) {
+	switch arg := arg.(type) {
+	case domain.Variable:
+		paramValue, found := getUniqueParamValue(arg.Target, input)
+		if !found {
+			return nil, false
+		}
+
+		if i, ok := castToInt(paramValue); ok {
+			return i, true
+		}
+		return paramValue, true
+	case domain.Chain:
+		return resolveChain(arg, input)
+	default:
+		return arg, true
+	}
+}
+
 func resolveWithBody(body interface{}, input restql.QueryInput) interface{} {
 	switch body := body.(type) {
 	case domain.Variable:
@@ -147,34 +243,38 @@ func resolveCacheControl(cacheControl domain.CacheControl, input restql.QueryInp
 
 	switch value := cacheControl.MaxAge.(type) {
 	case domain.Variable:
-		paramValue, found := getUniqueParamValue(value.Target, input)
-		if !found {
-			result.MaxAge = nil
-		}
+		paramValue, _ := getUniqueParamValue(value.Target, input)
 
-		maxAge, ok := castToInt(paramValue)
+		maxAge, ok := domain.DynamicMaxAge(paramValue)
 		if !ok {
 			return domain.CacheControl{}
 		}
 
 		result.MaxAge = maxAge
+	case domain.Chain:
+		chain, ok := resolveChain(value, input)
+		if ok {
+			result.MaxAge = chain
+		}
 	case int:
 		result.MaxAge = value
 	}
 
 	switch value := cacheControl.SMaxAge.(type) {
 	case domain.Variable:
-		paramValue, found := getUniqueParamValue(value.Target, input)
-		if !found {
-			result.SMaxAge = nil
-		}
+		paramValue, _ := getUniqueParamValue(value.Target, input)
 
-		smaxAge, ok := castToInt(paramValue)
+		smaxAge, ok := domain.DynamicMaxAge(paramValue)
 		if !ok {
 			return domain.CacheControl{}
 		}
 
 		result.SMaxAge = smaxAge
+	case domain.Chain:
+		chain, ok := resolveChain(value, input)
+		if ok {
+			result.SMaxAge = chain
+		}
 	case int:
 		result.SMaxAge = value
 	}
@@ -221,12 +321,19 @@ func resolveTimeout(timeout interface{}, input restql.QueryInput) interface{} {
 			return nil
 		}
 
-		result, ok := castToInt(paramValue)
+		result, ok := domain.DynamicTimeout(paramValue)
 		if !ok {
 			return nil
 		}
 
 		return result
+	case domain.Chain:
+		chain, ok := resolveChain(timeout, input)
+		if !ok {
+			return nil
+		}
+
+		return chain
 	case int:
 		return timeout
 	default:
@@ -261,12 +368,12 @@ func resolveOnly(only []interface{}, input restql.QueryInput) []interface{} {
 	result := make([]interface{}, len(only))
 	for i, filter := range only {
 		switch filter := filter.(type) {
-		case domain.Match:
-			match, ok := resolveMatch(filter, input)
+		case domain.Function:
+			fn, ok := resolveFilterFunction(filter, input)
 			if !ok {
 				continue
 			}
-			result[i] = match
+			result[i] = fn
 		default:
 			result[i] = filter
 		}
@@ -275,11 +382,49 @@ func resolveOnly(only []interface{}, input restql.QueryInput) []interface{} {
 	return result
 }
 
-func resolveMatch(match domain.Match, input restql.QueryInput) (interface{}, bool) {
+// resolveFilterFunction resolves the variables given as arguments
+// to the function and to the functions it wraps, failing if any
+// of them is not present in the input.
+func resolveFilterFunction(fn domain.Function, input restql.QueryInput) (domain.Function, bool) {
+	if inner, ok := fn.Target().(domain.Function); ok {
+		resolved, ok := resolveFilterFunction(inner, input)
+		if !ok {
+			return nil, false
+		}
+		fn = fn.Map(func(target interface{}) interface{} { return resolved })
+	}
+
+	switch fn := fn.(type) {
+	case domain.Match:
+		return resolveMatch(fn, input)
+	case domain.FilterByKeys:
+		keys, isVariable := fn.Keys.(domain.Variable)
+		if !isVariable {
+			return fn, true
+		}
+		value, ok := getUniqueParamValue(keys.Target, input)
+		return domain.FilterByKeys{Value: fn.Value, Keys: value}, ok
+	case domain.Compare:
+		switch arg := fn.Arg.(type) {
+		case domain.Variable:
+			value, ok := getUniqueParamValue(arg.Target, input)
+			return domain.Compare{Value: fn.Value, Operator: fn.Operator, Arg: value}, ok
+		case domain.Chain:
+			chain, ok := resolveChain(arg, input)
+			return domain.Compare{Value: fn.Value, Operator: fn.Operator, Arg: chain}, ok
+		default:
+			return fn, true
+		}
+	default:
+		return fn, true
+	}
+}
+
+func resolveMatch(match domain.Match, input restql.QueryInput) (domain.Function, bool) {
 	switch matchArg := match.Arg.(type) {
 	case domain.Variable:
 		arg, ok := getUniqueParamValue(matchArg.Target, input)
-		return domain.Match{Value: match.Value, Arg: arg}, ok
+		return domain.Match{Value: match.Value, Arg: arg, Flags: match.Flags}, ok
 	default:
 		return match, true
 	}
@@ -312,7 +457,11 @@ func getUniqueParamValue(name string, input restql.QueryInput) (interface{}, boo
 	}
 
 	headerValue, found := input.Headers[name]
-	return headerValue, found
+	if found {
+		return headerValue, true
+	}
+
+	return domain.LocaleVariableValue(name, input.Headers)
 }
 
 func getUniqueParamValueFromBody(name string, body interface{}) (interface{}, bool) {
@@ -324,3 +473,113 @@ func getUniqueParamValueFromBody(name string, body interface{}) (interface{}, bo
 	value, found := b[name]
 	return value, found
 }
+
+// QueryVariables returns the sorted names of the variables
+// referenced by the query, which the client input must provide.
+func QueryVariables(query domain.Query) []string {
+	seen := make(map[string]struct{})
+	for _, stmt := range query.Statements {
+		for _, value := range stmt.With.Values {
+			collectVariables(value, seen)
+		}
+		collectVariables(stmt.With.Body, seen)
+		collectVariables(stmt.Timeout, seen)
+		for _, value := range stmt.Headers {
+			collectVariables(value, seen)
+		}
+		collectVariables(stmt.CacheControl.MaxAge, seen)
+		collectVariables(stmt.CacheControl.SMaxAge, seen)
+		for _, filter := range stmt.Only {
+			collectFilterVariables(filter, seen)
+		}
+	}
+	for _, filter := range query.Only {
+		collectFilterVariables(filter, seen)
+	}
+
+	result := make([]string, 0, len(seen))
+	for name := range seen {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// RequiredVariables returns the sorted names of the variables
+// referenced by the query that have no default value.
+func RequiredVariables(query domain.Query) []string {
+	defaulted := defaultedVariables(query)
+
+	var result []string
+	for _, name := range QueryVariables(query) {
+		if _, found := defaulted[name]; !found {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+// VariableUsages returns, for each variable given to `with`
+// parameters, the sorted parameters using it, in the form
+// resource.param, where resource is the statement identifier.
+func VariableUsages(query domain.Query) map[string][]string {
+	result := make(map[string][]string)
+	for _, stmt := range query.Statements {
+		resourceID := string(domain.NewResourceID(stmt))
+		for param, value := range stmt.With.Values {
+			seen := make(map[string]struct{})
+			collectVariables(value, seen)
+			for name := range seen {
+				result[name] = append(result[name], resourceID+"."+param)
+			}
+		}
+	}
+
+	for _, usages := range result {
+		sort.Strings(usages)
+	}
+
+	return result
+}
+
+func collectFilterVariables(filter interface{}, seen map[string]struct{}) {
+	switch filter := filter.(type) {
+	case domain.Match:
+		collectVariables(filter.Arg, seen)
+	case domain.FilterByKeys:
+		collectVariables(filter.Keys, seen)
+	case domain.Compare:
+		collectVariables(filter.Arg, seen)
+	}
+
+	if fn, ok := filter.(domain.Function); ok {
+		collectFilterVariables(fn.Target(), seen)
+	}
+}
+
+func collectVariables(value interface{}, seen map[string]struct{}) {
+	switch value := value.(type) {
+	case domain.Variable:
+		seen[value.Target] = struct{}{}
+	case domain.Chain:
+		for _, v := range value {
+			collectVariables(v, seen)
+		}
+	case domain.Range:
+		collectVariables(value.Start, seen)
+		collectVariables(value.End, seen)
+		collectVariables(value.Step, seen)
+	case domain.Function:
+		collectVariables(value.Target(), seen)
+	case []interface{}:
+		for _, v := range value {
+			collectVariables(v, seen)
+		}
+	case map[string]interface{}:
+		for _, v := range value {
+			collectVariables(v, seen)
+		}
+	}
+}
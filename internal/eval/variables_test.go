@@ -28,12 +28,101 @@ func TestResolveVariables(t *testing.T) {
 			restql.QueryInput{Headers: map[string]string{"duration": "1000"}},
 			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Timeout: 1000}}},
 		},
+		{
+			"resolve implicit locale variables from headers",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{"lang": domain.Variable{"locale"}, "currency": domain.Variable{"currency"}}}}}},
+			restql.QueryInput{Headers: map[string]string{"Accept-Language": "en;q=0.8, pt-BR, *;q=0.1", "X-Currency": "BRL"}},
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{"lang": "pt-BR", "currency": "BRL"}}}}},
+		},
+		{
+			"resolve implicit locale variable from locale header",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{"lang": domain.Variable{"locale"}}}}}},
+			restql.QueryInput{Headers: map[string]string{"Accept-Language": "en", "X-Locale": "pt-BR"}},
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{"lang": "pt-BR"}}}}},
+		},
+		{
+			"resolve locale variable from params before headers",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{"lang": domain.Variable{"locale"}}}}}},
+			restql.QueryInput{Params: map[string]interface{}{"locale": "es-AR"}, Headers: map[string]string{"X-Locale": "pt-BR"}},
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{"lang": "es-AR"}}}}},
+		},
 		{
 			"resolve variable in timeout from body",
 			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Timeout: domain.Variable{"duration"}}}},
 			restql.QueryInput{Body: map[string]interface{}{"duration": 1000}},
 			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Timeout: 1000}}},
 		},
+		{
+			"cap variable in timeout",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Timeout: domain.Variable{"duration"}}}},
+			restql.QueryInput{Body: map[string]interface{}{"duration": float64(600000)}},
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Timeout: domain.MaxDynamicTimeout}}},
+		},
+		{
+			"drop invalid variable in timeout",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Timeout: domain.Variable{"duration"}}}},
+			restql.QueryInput{Params: map[string]interface{}{"duration": "-10"}},
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero"}}},
+		},
+		{
+			"resolve variables in chained timeout",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Timeout: domain.Chain{"config", domain.Variable{"field"}}}}},
+			restql.QueryInput{Params: map[string]interface{}{"field": "timeout"}},
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Timeout: domain.Chain{"config", "timeout"}}}},
+		},
+		{
+			"resolve variables in range arguments",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{
+				"page": domain.Range{Start: domain.Variable{Target: "first"}, End: domain.Variable{Target: "last"}, Step: 1},
+			}}}}},
+			restql.QueryInput{Params: map[string]interface{}{"first": "1"}, Body: map[string]interface{}{"last": float64(5)}},
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{
+				"page": domain.Range{Start: 1, End: float64(5), Step: 1},
+			}}}}},
+		},
+		{
+			"resolve variables of expressions",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"name": domain.Expression{Value: domain.Variable{Target: "name"}, Program: test.Program("value + $suffix + $missing"), Variables: map[string]interface{}{"suffix": domain.Variable{Target: "suffix"}, "missing": domain.Variable{Target: "missing"}}},
+				}},
+				Compute: []domain.ComputedField{{Name: "label", Path: []string{"name"}, Expression: test.Program("value + $suffix"), Variables: map[string]interface{}{"suffix": domain.Variable{Target: "suffix"}}}},
+			}}},
+			restql.QueryInput{Params: map[string]interface{}{"name": "batman", "suffix": "-dc"}},
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"name": domain.Expression{Value: "batman", Program: test.Program("value + $suffix + $missing"), Variables: map[string]interface{}{"suffix": "-dc"}},
+				}},
+				Compute: []domain.ComputedField{{Name: "label", Path: []string{"name"}, Expression: test.Program("value + $suffix"), Variables: map[string]interface{}{"suffix": "-dc"}}},
+			}}},
+		},
+		{
+			"drop range parameter with unknown variable",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{
+				"page": domain.Range{Start: 1, End: domain.Variable{Target: "last"}, Step: 1},
+			}}}}},
+			restql.QueryInput{},
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{}}}}},
+		},
+		{
+			"resolve default value of missing variables in with",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{
+				"page":   domain.DefaultValue{Value: domain.Variable{Target: "page"}, Default: 1},
+				"size":   domain.DefaultValue{Value: domain.Variable{Target: "size"}, Default: 10},
+				"ids":    domain.NoMultiplex{Value: domain.DefaultValue{Value: domain.Variable{Target: "ids"}, Default: []interface{}{"1"}}},
+				"sort":   domain.DefaultValue{Value: domain.Variable{Target: "sort"}, Default: domain.Variable{Target: "order"}},
+				"parent": domain.DefaultValue{Value: domain.Chain{"team", domain.Variable{Target: "field"}}, Default: "none"},
+				"city":   domain.DefaultValue{Value: domain.Chain{"team", "city"}, Default: "Gotham"},
+			}}}}},
+			restql.QueryInput{Params: map[string]interface{}{"size": "20"}},
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{
+				"page":   1,
+				"size":   "20",
+				"ids":    domain.NoMultiplex{Value: []interface{}{"1"}},
+				"parent": "none",
+				"city":   domain.DefaultValue{Value: domain.Chain{"team", "city"}, Default: "Gotham"},
+			}}}}},
+		},
 		{
 			"resolve variable in with from params",
 			domain.Query{
@@ -182,6 +271,16 @@ func TestResolveVariables(t *testing.T) {
 				Statements: []domain.Statement{{Method: "from", Resource: "hero", CacheControl: domain.CacheControl{MaxAge: 200, SMaxAge: 400}}},
 			},
 		},
+		{
+			"cap variable in max-age/s-max-age and keep chained value",
+			domain.Query{
+				Statements: []domain.Statement{{Method: "from", Resource: "hero", CacheControl: domain.CacheControl{MaxAge: domain.Variable{"cache-control"}, SMaxAge: domain.Chain{"config", "sMaxAge"}}}},
+			},
+			restql.QueryInput{Params: map[string]interface{}{"cache-control": "99999999"}},
+			domain.Query{
+				Statements: []domain.Statement{{Method: "from", Resource: "hero", CacheControl: domain.CacheControl{MaxAge: domain.MaxDynamicMaxAge, SMaxAge: domain.Chain{"config", "sMaxAge"}}}},
+			},
+		},
 		{
 			"resolve variable in headers from params",
 			domain.Query{
@@ -254,6 +353,22 @@ func TestResolveVariables(t *testing.T) {
 			restql.QueryInput{Body: map[string]interface{}{"heroName": "^Super"}},
 			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Only: []interface{}{domain.Match{Value: "name", Arg: "^Super"}}}}},
 		},
+		{
+			"resolve variables in only functions",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Only: []interface{}{
+				domain.FilterByKeys{Value: domain.Match{Value: []string{"skills"}, Arg: domain.Variable{Target: "skill"}}, Keys: domain.Variable{Target: "fields"}},
+				domain.RenameAs{Value: domain.FilterByKeys{Value: []string{"weapons"}, Keys: domain.Variable{Target: "missing"}}, Name: "weapon"},
+				domain.Compare{Value: []string{"birth"}, Operator: domain.CompareAfter, Arg: domain.Variable{Target: "since"}},
+				domain.Compare{Value: []string{"stock"}, Operator: domain.CompareLessThan, Arg: domain.Chain{"limits", domain.Variable{Target: "limit"}}},
+			}}}},
+			restql.QueryInput{Params: map[string]interface{}{"skill": "^fly", "fields": []interface{}{"id", "name"}, "since": "2020-01-01", "limit": "stock"}},
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Only: []interface{}{
+				domain.FilterByKeys{Value: domain.Match{Value: []string{"skills"}, Arg: "^fly"}, Keys: []interface{}{"id", "name"}},
+				nil,
+				domain.Compare{Value: []string{"birth"}, Operator: domain.CompareAfter, Arg: "2020-01-01"},
+				domain.Compare{Value: []string{"stock"}, Operator: domain.CompareLessThan, Arg: domain.Chain{"limits", "stock"}},
+			}}}},
+		},
 	}
 
 	for _, tt := range tests {
@@ -263,3 +378,31 @@ func TestResolveVariables(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryVariables(t *testing.T) {
+	query := domain.Query{Statements: []domain.Statement{
+		{
+			Method:   "from",
+			Resource: "hero",
+			Timeout:  domain.Variable{Target: "timeout"},
+			With: domain.Params{Values: map[string]interface{}{
+				"id":    domain.Variable{Target: "id"},
+				"page":  domain.Range{Start: domain.Variable{Target: "first"}, End: 10, Step: 1},
+				"names": []interface{}{domain.Variable{Target: "name"}, "batman"},
+			}},
+			Headers: map[string]interface{}{"X-Token": domain.Variable{Target: "token"}},
+		},
+		{
+			Method:   "from",
+			Resource: "sidekick",
+			With:     domain.Params{Values: map[string]interface{}{"hero": domain.Chain{"hero", domain.Variable{Target: "field"}}}},
+			Only: []interface{}{
+				domain.Match{Value: []string{"name"}, Arg: domain.Variable{Target: "name"}},
+				domain.RenameAs{Value: domain.FilterByKeys{Value: []string{"skills"}, Keys: domain.Variable{Target: "fields"}}, Name: "abilities"},
+				domain.Compare{Value: []string{"birth"}, Operator: domain.CompareAfter, Arg: domain.Variable{Target: "since"}},
+			},
+		},
+	}}
+
+	test.Equal(t, eval.QueryVariables(query), []string{"field", "fields", "first", "id", "name", "since", "timeout", "token"})
+}
@@ -0,0 +1,58 @@
+package eval
+
+import (
+	"context"
+	"sync"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+type warningsKey struct{}
+
+type warnings struct {
+	mu   sync.Mutex
+	list []domain.Warning
+}
+
+// WithWarnings returns a context that collects the
+// warnings found while evaluating queries with it.
+func WithWarnings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, warningsKey{}, &warnings{})
+}
+
+// Warnings returns the warnings collected with
+// the given context, in the order they were found.
+func Warnings(ctx context.Context) []domain.Warning {
+	w, ok := ctx.Value(warningsKey{}).(*warnings)
+	if !ok {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	result := make([]domain.Warning, len(w.list))
+	copy(result, w.list)
+	return result
+}
+
+func addWarnings(ctx context.Context, found ...domain.Warning) {
+	if len(found) == 0 {
+		return
+	}
+
+	log := restql.GetLogger(ctx)
+	for _, f := range found {
+		log.Warn("query warning", "code", f.Code, "statement", f.Statement, "message", f.Message)
+	}
+
+	w, ok := ctx.Value(warningsKey{}).(*warnings)
+	if !ok {
+		return
+	}
+
+	w.mu.Lock()
+	w.list = append(w.list, found...)
+	w.mu.Unlock()
+}
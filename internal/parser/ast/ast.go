@@ -1,30 +1,61 @@
 package ast
 
+import "errors"
+
 // restQL language keywords.
 const (
-	FromMethod          = "from"
-	IntoMethod          = "into"
-	UpdateMethod        = "update"
-	ToMethod            = "to"
-	DeleteMethod        = "delete"
-	WithKeyword         = "with"
-	OnlyKeyword         = "only"
-	HeadersKeyword      = "headers"
-	HiddenKeyword       = "hidden"
-	TimeoutKeyword      = "timeout"
-	MaxAgeKeyword       = "max-age"
-	SmaxAgeKeyword      = "s-max-age"
-	IgnoreErrorsKeyword = "ignore-errors"
-	NoMultiplex         = "no-multiplex"
-	Base64              = "base64"
-	JSON                = "json"
-	AsBody              = "as-body"
-	Flatten             = "flatten"
+	FromMethod           = "from"
+	IntoMethod           = "into"
+	UpdateMethod         = "update"
+	ToMethod             = "to"
+	DeleteMethod         = "delete"
+	WithKeyword          = "with"
+	OnlyKeyword          = "only"
+	ComputeKeyword       = "compute"
+	HeadersKeyword       = "headers"
+	HiddenKeyword        = "hidden"
+	TimeoutKeyword       = "timeout"
+	MaxAgeKeyword        = "max-age"
+	SmaxAgeKeyword       = "s-max-age"
+	IgnoreErrorsKeyword  = "ignore-errors"
+	FilterErrorsKeyword  = "filter-errors"
+	CacheKeyword         = "cache"
+	NoCacheKeyword       = "no-cache"
+	SLOKeyword           = "slo"
+	ReturnHeadersKeyword = "return-headers"
+	DefaultKeyword       = "default"
+	MethodKeyword        = "method"
+	NoMultiplex          = "no-multiplex"
+	Base64               = "base64"
+	JSON                 = "json"
+	AsBody               = "as-body"
+	Flatten              = "flatten"
+	DeepObject           = "deep-object"
+	CSV                  = "csv"
+	PipeDelimited        = "pipe-delimited"
+	Repeated             = "repeated"
+	Distinct             = "distinct"
+	FilterByKeys         = "filterByKeys"
+	RenameAs             = "renameAs"
+	First                = "first"
+	Equals               = "equals"
+	GreaterThan          = "greaterThan"
+	LessThan             = "lessThan"
+	After                = "after"
+	Before               = "before"
+	Sum                  = "sum"
+	Count                = "count"
+	Avg                  = "avg"
+	Min                  = "min"
+	Max                  = "max"
+	Concat               = "concat"
+	RangeKeyword         = "range"
 )
 
 // Query is the root of the restQL AST.
 type Query struct {
 	Use    []Use
+	Only   []Filter
 	Blocks []Block
 }
 
@@ -39,36 +70,84 @@ type Use struct {
 type UseValue struct {
 	Int    *int
 	String *string
+	Bool   *bool
 }
 
 // Block is the syntax node representing a statement.
+//
+// Ordered is set by the `-> ordered` result function, when present.
 type Block struct {
 	Method     string
 	Resource   string
 	Alias      string
 	In         []string
+	Join       *JoinKey
+	Functions  []string
+	Ordered    *bool
 	Qualifiers []Qualifier
 }
 
+// JoinKey is the syntax node representing the `on` clause
+// of `in` and `join`, where Target is the key of the target elements
+// and Origin the key of the statement results matched to them.
+type JoinKey struct {
+	Target []string
+	Origin []string
+}
+
 // Qualifier is the syntax node representing statement
-// clauses: `with`, `only`, `hidden`, `headers`, `timeout`
-// `max-age`, `s-max-age` and `ignore-errors`.
+// clauses: `with`, `only`, `hidden`, `compute`, `headers`, `timeout`
+// `max-age`, `s-max-age`, `cache`, `slo`, `return-headers`, `default`,
+// `method`, `ignore-errors`, `filter-errors` and `no-cache`.
 type Qualifier struct {
-	With         *Parameters
-	Only         []Filter
-	Headers      []HeaderItem
-	Hidden       bool
-	Timeout      *TimeoutValue
-	MaxAge       *MaxAgeValue
-	SMaxAge      *SMaxAgeValue
-	IgnoreErrors bool
+	With          *Parameters
+	Only          []Filter
+	Compute       []ComputedField
+	Headers       []HeaderItem
+	Hidden        bool
+	Timeout       *TimeoutValue
+	MaxAge        *MaxAgeValue
+	SMaxAge       *SMaxAgeValue
+	Cache         *int
+	SLO           *int
+	ReturnHeaders []string
+	Default       *Value
+	HTTPMethod    string
+	IgnoreErrors  bool
+	FilterErrors  bool
+	NoCache       bool
 }
 
 // Filter is the syntax node representing entries
 // in the `only` clause.
 type Filter struct {
-	Field []string
-	Match *Match
+	Field     []string
+	Match     *Match
+	Functions []FilterFunction
+}
+
+// FilterFunction is the syntax node representing the
+// functions applied to a filter after `matches`, where
+// Keys or Variable are the `filterByKeys` argument, Alias
+// is the `renameAs` one and Arg the comparisons one.
+type FilterFunction struct {
+	Name     string
+	Keys     []string
+	Variable *string
+	Alias    string
+	Arg      *Value
+}
+
+// ComputedField is the syntax node representing entries
+// in the `compute` clause, where Separator is the optional
+// argument of the `concat` aggregator and Expression the
+// argument of the `expr` function.
+type ComputedField struct {
+	Name       string
+	Path       []string
+	Aggregator string
+	Separator  string
+	Expression string
 }
 
 // Match is the syntax node representing the
@@ -76,6 +155,7 @@ type Filter struct {
 type Match struct {
 	String   *string
 	Variable *string
+	Flags    string
 }
 
 // Parameters is the syntax node representing
@@ -93,11 +173,15 @@ type ParameterBody struct {
 }
 
 // KeyValue is the syntax node representing
-// parameters in the `with` clause.
+// parameters in the `with` clause, where Default
+// is the argument of the `default` function and
+// Expression the argument of the `expr` function.
 type KeyValue struct {
-	Key       string
-	Value     Value
-	Functions []string
+	Key        string
+	Value      Value
+	Functions  []string
+	Default    *Value
+	Expression *string
 }
 
 // Value is the syntax node representing
@@ -108,6 +192,23 @@ type Value struct {
 	Object    []ObjectEntry
 	Variable  *string
 	Primitive *Primitive
+	Range     *Range
+}
+
+// Range is the syntax node representing
+// the `range` generator function.
+type Range struct {
+	Start RangeArg
+	End   RangeArg
+	Step  *RangeArg
+}
+
+// RangeArg is the syntax node representing
+// the possible types of a `range` argument.
+type RangeArg struct {
+	Int      *int
+	Variable *string
+	Chain    []Chained
 }
 
 // ObjectEntry is the syntax node representing
@@ -153,6 +254,7 @@ type HeaderValue struct {
 type variableOrInt struct {
 	Variable *string
 	Int      *int
+	Chain    []Chained
 }
 
 // TimeoutValue is the syntax node representing
@@ -188,3 +290,19 @@ func (g Generator) Parse(query string) (*Query, error) {
 	q := parse.(Query)
 	return &q, nil
 }
+
+// SyntaxPosition returns the line and column, both starting at 1,
+// where the parsing failed for an error returned by Parse.
+func SyntaxPosition(err error) (line int, column int, ok bool) {
+	var list errList
+	if errors.As(err, &list) && len(list) > 0 {
+		err = list[0]
+	}
+
+	var pe *parserError
+	if !errors.As(err, &pe) {
+		return 0, 0, false
+	}
+
+	return pe.pos.line, pe.pos.col, true
+}
@@ -59,6 +59,22 @@ func TestAstGenerator(t *testing.T) {
 				Blocks: []ast.Block{{Method: ast.FromMethod, Resource: "cart"}},
 			},
 		},
+		{
+			"Simple from resource query with strict modifier",
+			`
+							use strict
+							use strict false
+
+							from cart
+					`,
+			ast.Query{
+				Use: []ast.Use{
+					{Key: "strict", Value: ast.UseValue{}},
+					{Key: "strict", Value: ast.UseValue{Bool: Boolean(false)}},
+				},
+				Blocks: []ast.Block{{Method: ast.FromMethod, Resource: "cart"}},
+			},
+		},
 		{
 			"query with two from statements",
 			`
@@ -534,6 +550,26 @@ func TestAstGenerator(t *testing.T) {
 			`from hero only name.path:withcollon.id`,
 			ast.Query{Blocks: []ast.Block{{Method: "from", Resource: "hero", Qualifiers: []ast.Qualifier{{Only: []ast.Filter{{Field: []string{"name", "path:withcollon", "id"}}}}}}}},
 		},
+		{
+			"Get query with select filters containing list selectors",
+			`from hero only weapons[0].id, weapons[1:10].name, weapons[-1], matrix[0][:2]`,
+			ast.Query{Blocks: []ast.Block{{Method: "from", Resource: "hero", Qualifiers: []ast.Qualifier{{Only: []ast.Filter{
+				{Field: []string{"weapons", "[0]", "id"}},
+				{Field: []string{"weapons", "[1:10]", "name"}},
+				{Field: []string{"weapons", "[-1]"}},
+				{Field: []string{"matrix", "[0]", "[:2]"}},
+			}}}}}},
+		},
+		{
+			"Get query with select filters applying functions",
+			`from hero only skills -> filterByKeys(["id", "name"]), items -> filterByKeys($fields), weapons -> matches("^bat") -> first -> renameAs("weapon"), tags -> filterByKeys([])`,
+			ast.Query{Blocks: []ast.Block{{Method: "from", Resource: "hero", Qualifiers: []ast.Qualifier{{Only: []ast.Filter{
+				{Field: []string{"skills"}, Functions: []ast.FilterFunction{{Name: ast.FilterByKeys, Keys: []string{"id", "name"}}}},
+				{Field: []string{"items"}, Functions: []ast.FilterFunction{{Name: ast.FilterByKeys, Variable: String("fields")}}},
+				{Field: []string{"weapons"}, Match: &ast.Match{String: String("^bat")}, Functions: []ast.FilterFunction{{Name: ast.First}, {Name: ast.RenameAs, Alias: "weapon"}}},
+				{Field: []string{"tags"}, Functions: []ast.FilterFunction{{Name: ast.FilterByKeys, Keys: []string{}}}},
+			}}}}}},
+		},
 		{
 			"Get query with select filter delimited by new line",
 			fmt.Sprintf("from hero only name\nweapons"),
@@ -586,6 +622,39 @@ func TestAstGenerator(t *testing.T) {
 				},
 			}}},
 		},
+		{
+			"Get query with computed fields",
+			`from hero
+				only
+					name
+					items.price
+				compute
+					total = items.price -> sum
+					sidekicks = sidekick.name -> concat(", ")
+					city = address.city
+				ignore-errors`,
+			ast.Query{Blocks: []ast.Block{{
+				Method:   ast.FromMethod,
+				Resource: "hero",
+				Qualifiers: []ast.Qualifier{
+					{Only: []ast.Filter{{Field: []string{"name"}}, {Field: []string{"items", "price"}}}},
+					{Compute: []ast.ComputedField{
+						{Name: "total", Path: []string{"items", "price"}, Aggregator: ast.Sum},
+						{Name: "sidekicks", Path: []string{"sidekick", "name"}, Aggregator: ast.Concat, Separator: ", "},
+						{Name: "city", Path: []string{"address", "city"}},
+					}},
+					{IgnoreErrors: true},
+				},
+			}}},
+		},
+		{
+			"Get query with computed field without only",
+			"from hero compute weapons = weapons -> count, cheapest = items.price -> min",
+			ast.Query{Blocks: []ast.Block{{Method: ast.FromMethod, Resource: "hero", Qualifiers: []ast.Qualifier{{Compute: []ast.ComputedField{
+				{Name: "weapons", Path: []string{"weapons"}, Aggregator: ast.Count},
+				{Name: "cheapest", Path: []string{"items", "price"}, Aggregator: ast.Min},
+			}}}}}},
+		},
 		{
 			"Get query with hidden",
 			"from hero hidden",
@@ -596,6 +665,11 @@ func TestAstGenerator(t *testing.T) {
 			"from hero ignore-errors",
 			ast.Query{Blocks: []ast.Block{{Method: ast.FromMethod, Resource: "hero", Qualifiers: []ast.Qualifier{{IgnoreErrors: true}}}}},
 		},
+		{
+			"Get query with filter errors and ignore errors",
+			"from hero only name filter-errors, ignore-errors",
+			ast.Query{Blocks: []ast.Block{{Method: ast.FromMethod, Resource: "hero", Qualifiers: []ast.Qualifier{{Only: []ast.Filter{{Field: []string{"name"}}}}, {FilterErrors: true}, {IgnoreErrors: true}}}}},
+		},
 		{
 			"Get query with integer timeout",
 			`from hero timeout 200`,
@@ -625,6 +699,17 @@ func TestAstGenerator(t *testing.T) {
 			`from hero s-max-age 2000`,
 			ast.Query{Blocks: []ast.Block{{Method: ast.FromMethod, Resource: "hero", Qualifiers: []ast.Qualifier{{SMaxAge: &ast.SMaxAgeValue{Int: Int(2000)}}}}}},
 		},
+		{
+			"Get query with default value",
+			`from hero default { name: "unknown", powers: [] } ignore-errors`,
+			ast.Query{Blocks: []ast.Block{{Method: ast.FromMethod, Resource: "hero", Qualifiers: []ast.Qualifier{
+				{Default: &ast.Value{Object: []ast.ObjectEntry{
+					{Key: "name", Value: ast.Value{Primitive: &ast.Primitive{String: String("unknown")}}},
+					{Key: "powers", Value: ast.Value{List: []ast.Value{}}},
+				}}},
+				{IgnoreErrors: true},
+			}}}},
+		},
 		{
 			"Simple from resource query with aggregation",
 			`
@@ -636,6 +721,45 @@ func TestAstGenerator(t *testing.T) {
 				{Method: ast.FromMethod, Resource: "sidekick", In: []string{"hero", "sidekick"}},
 			}},
 		},
+		{
+			"From resource query with result functions",
+			"from products -> flatten -> distinct as p with id = [1, 2] only name",
+			ast.Query{Blocks: []ast.Block{{
+				Method:     ast.FromMethod,
+				Resource:   "products",
+				Alias:      "p",
+				Functions:  []string{ast.Flatten, ast.Distinct},
+				Qualifiers: []ast.Qualifier{{With: &ast.Parameters{KeyValues: []ast.KeyValue{{Key: "id", Value: ast.Value{List: []ast.Value{{Primitive: &ast.Primitive{Int: Int(1)}}, {Primitive: &ast.Primitive{Int: Int(2)}}}}}}}}, {Only: []ast.Filter{{Field: []string{"name"}}}}},
+			}}},
+		},
+		{
+			"From resource query with join",
+			`
+							from products
+							from stock join products on id = productId
+						`,
+			ast.Query{Blocks: []ast.Block{
+				{Method: ast.FromMethod, Resource: "products"},
+				{Method: ast.FromMethod, Resource: "stock", In: []string{"products"}, Join: &ast.JoinKey{Target: []string{"id"}, Origin: []string{"productId"}}},
+			}},
+		},
+		{
+			"From resource query with aggregation joined by key",
+			`
+							from cart
+							from product in cart.items.product on product.id = sku only name
+						`,
+			ast.Query{Blocks: []ast.Block{
+				{Method: ast.FromMethod, Resource: "cart"},
+				{
+					Method:     ast.FromMethod,
+					Resource:   "product",
+					In:         []string{"cart", "items", "product"},
+					Join:       &ast.JoinKey{Target: []string{"product", "id"}, Origin: []string{"sku"}},
+					Qualifiers: []ast.Qualifier{{Only: []ast.Filter{{Field: []string{"name"}}}}},
+				},
+			}},
+		},
 		{
 			"Full query",
 			`from hero as h
@@ -10,14 +10,13 @@ import (
 func newQuery(uses, firstBlock, otherBlocks interface{}) (Query, error) {
 	var q Query
 
-	useList := uses.([]interface{})
-	if len(useList) > 0 {
-		us := make([]Use, len(useList))
-		for i, u := range useList {
-			us[i] = u.(Use)
+	for _, u := range uses.([]interface{}) {
+		switch u := u.(type) {
+		case Use:
+			q.Use = append(q.Use, u)
+		case useOnly:
+			q.Only = append(q.Only, u...)
 		}
-
-		q.Use = us
 	}
 
 	fb := firstBlock.(Block)
@@ -55,11 +54,24 @@ func newBlockList(blocks []interface{}) []Block {
 
 func newUse(rule, value interface{}) (Use, error) {
 	r := rule.(string)
-	v := value.(UseValue)
+	v, _ := value.(UseValue)
 
 	return Use{Key: r, Value: v}, nil
 }
 
+type useOnly []Filter
+
+func newUseOnly(first, others interface{}) (useOnly, error) {
+	filters := useOnly{first.(Filter)}
+
+	for _, o := range others.([]interface{}) {
+		seq := o.([]interface{})
+		filters = append(filters, seq[len(seq)-1].(Filter))
+	}
+
+	return filters, nil
+}
+
 func newUseValue(value interface{}) (UseValue, error) {
 	vInt, ok := value.(int)
 	if ok {
@@ -71,16 +83,24 @@ func newUseValue(value interface{}) (UseValue, error) {
 		return UseValue{String: &sInt}, nil
 	}
 
+	vBool, ok := value.(bool)
+	if ok {
+		return UseValue{Bool: &vBool}, nil
+	}
+
 	return UseValue{}, errors.Errorf("unknown use value type : %T", value)
 }
 
-func newBlock(action, modifiers, with, filter, ignore interface{}) (Block, error) {
+func newBlock(action, modifiers, with, filter, compute, flags interface{}) (Block, error) {
 	ac := action.(actionRule)
 	block := Block{
-		Method:   ac.Method,
-		Resource: ac.Resource,
-		Alias:    ac.Alias,
-		In:       ac.In,
+		Method:    ac.Method,
+		Resource:  ac.Resource,
+		Alias:     ac.Alias,
+		In:        ac.In,
+		Join:      ac.Join,
+		Functions: ac.Functions,
+		Ordered:   ac.Ordered,
 	}
 
 	if modifiers != nil {
@@ -98,6 +118,18 @@ func newBlock(action, modifiers, with, filter, ignore interface{}) (Block, error
 				q = Qualifier{MaxAge: m}
 			case *SMaxAgeValue:
 				q = Qualifier{SMaxAge: m}
+			case *Value:
+				q = Qualifier{Default: m}
+			case httpMethod:
+				q = Qualifier{HTTPMethod: string(m)}
+			case cacheTTL:
+				ttl := int(m)
+				q = Qualifier{Cache: &ttl}
+			case sloTarget:
+				target := int(m)
+				q = Qualifier{SLO: &target}
+			case returnHeaders:
+				q = Qualifier{ReturnHeaders: m}
 			default:
 				continue
 			}
@@ -128,46 +160,103 @@ func newBlock(action, modifiers, with, filter, ignore interface{}) (Block, error
 		block.Qualifiers = append(block.Qualifiers, q)
 	}
 
-	if ignore != nil {
-		ig := ignore.(ignoreErrors)
-		q := Qualifier{IgnoreErrors: bool(ig)}
+	if compute != nil {
+		q := Qualifier{Compute: compute.([]ComputedField)}
 
 		block.Qualifiers = append(block.Qualifiers, q)
 	}
 
+	if flags != nil {
+		for _, f := range flags.([]statementFlag) {
+			var q Qualifier
+
+			switch f {
+			case IgnoreErrorsKeyword:
+				q = Qualifier{IgnoreErrors: true}
+			case FilterErrorsKeyword:
+				q = Qualifier{FilterErrors: true}
+			case NoCacheKeyword:
+				q = Qualifier{NoCache: true}
+			default:
+				return Block{}, fmt.Errorf("got an unknown flag : %s", f)
+			}
+
+			block.Qualifiers = append(block.Qualifiers, q)
+		}
+	}
+
 	return block, nil
 }
 
 type actionRule struct {
-	Method   string
-	Resource string
-	Alias    string
-	In       []string
+	Method    string
+	Resource  string
+	Alias     string
+	In        []string
+	Join      *JoinKey
+	Functions []string
+	Ordered   *bool
 }
 
-func newActionRule(method, resource, alias, in interface{}) (actionRule, error) {
+func newActionRule(method, resource, functions, alias, in interface{}) (actionRule, error) {
 	m := method.(string)
 	r := resource.(string)
 
 	ar := actionRule{Method: m, Resource: r}
 
+	if fns, ok := functions.([]interface{}); ok {
+		for _, fn := range fns {
+			switch fn := fn.(type) {
+			case ordered:
+				o := bool(fn)
+				ar.Ordered = &o
+			case string:
+				ar.Functions = append(ar.Functions, fn)
+			}
+		}
+	}
+
 	if alias != nil {
 		a := alias.(string)
 		ar.Alias = a
 	}
 
 	if in != nil {
-		i := in.([]string)
-		ar.In = i
+		i := in.(inRule)
+		ar.In = i.Path
+		ar.Join = i.Join
 	}
 
 	return ar, nil
 }
 
-func newIn(target interface{}) ([]string, error) {
+type ordered bool
+
+func newOrdered(b interface{}) (ordered, error) {
+	return ordered(b.(bool)), nil
+}
+
+type inRule struct {
+	Path []string
+	Join *JoinKey
+}
+
+func newIn(target, join interface{}) (inRule, error) {
+	t := target.(string)
+	in := inRule{Path: strings.Split(t, ".")}
+
+	if j, ok := join.(JoinKey); ok {
+		in.Join = &j
+	}
+
+	return in, nil
+}
+
+func newJoinKey(target, origin interface{}) (JoinKey, error) {
 	t := target.(string)
-	path := strings.Split(t, ".")
-	return path, nil
+	o := origin.(string)
+
+	return JoinKey{Target: strings.Split(t, "."), Origin: strings.Split(o, ".")}, nil
 }
 
 func newWith(parameterBody, keyValues interface{}) (*Parameters, error) {
@@ -244,11 +333,36 @@ func newKeyValue(key, value, functions interface{}) (KeyValue, error) {
 
 	if functions != nil {
 		kv.Functions = newFunctionList(functions)
+
+		for _, fn := range functions.([]interface{}) {
+			switch fn := fn.(type) {
+			case defaultFunction:
+				value := fn.Value
+				kv.Default = &value
+			case expression:
+				source := string(fn)
+				kv.Expression = &source
+			}
+		}
 	}
 
 	return kv, nil
 }
 
+type defaultFunction struct {
+	Value Value
+}
+
+func newDefaultFunction(value interface{}) (defaultFunction, error) {
+	return defaultFunction{Value: value.(Value)}, nil
+}
+
+type expression string
+
+func newExpression(source interface{}) (expression, error) {
+	return expression(source.(string)), nil
+}
+
 func newFunctionList(functions interface{}) []string {
 	fns := functions.([]interface{})
 	var result []string
@@ -273,11 +387,41 @@ func newValue(value interface{}) (Value, error) {
 		return Value{List: value}, nil
 	case []ObjectEntry:
 		return Value{Object: value}, nil
+	case Range:
+		return Value{Range: &value}, nil
 	default:
 		return Value{}, fmt.Errorf("got an unknown value of type %T", value)
 	}
 }
 
+func newRange(start, end, step interface{}) (Range, error) {
+	r := Range{Start: start.(RangeArg), End: end.(RangeArg)}
+
+	if step != nil {
+		for _, s := range flatten(step.([]interface{})) {
+			if s, ok := s.(RangeArg); ok {
+				r.Step = &s
+			}
+		}
+	}
+
+	return r, nil
+}
+
+func newRangeArg(arg interface{}) (RangeArg, error) {
+	switch arg := arg.(type) {
+	case int:
+		return RangeArg{Int: &arg}, nil
+	case variable:
+		v := string(arg)
+		return RangeArg{Variable: &v}, nil
+	case []Chained:
+		return RangeArg{Chain: arg}, nil
+	default:
+		return RangeArg{}, fmt.Errorf("got an unknown range argument of type %T", arg)
+	}
+}
+
 func newEmptyList() ([]Value, error) {
 	return []Value{}, nil
 }
@@ -417,24 +561,114 @@ func newOnly(first, others interface{}) ([]Filter, error) {
 	return filters, nil
 }
 
-func newFilter(identifier, matchArg interface{}) (Filter, error) {
+func newFilter(identifier, match, functions interface{}) (Filter, error) {
 	ident := identifier.(string)
-	fields := strings.Split(ident, ".")
-	filter := Filter{Field: fields}
-
-	if matchArg != nil {
-		switch m := matchArg.(type) {
-		case string:
-			filter.Match = &Match{String: &m}
-		case variable:
-			matchVar := string(m)
-			filter.Match = &Match{Variable: &matchVar}
+	filter := Filter{Field: splitFilterPath(ident)}
+
+	if m, ok := match.(Match); ok {
+		filter.Match = &m
+	}
+
+	if fns, ok := functions.([]interface{}); ok {
+		for _, fn := range fns {
+			if fn, ok := fn.(FilterFunction); ok {
+				filter.Functions = append(filter.Functions, fn)
+			}
 		}
 	}
 
 	return filter, nil
 }
 
+func newFilterByKeys(keys interface{}) (FilterFunction, error) {
+	switch keys := keys.(type) {
+	case variable:
+		v := string(keys)
+		return FilterFunction{Name: FilterByKeys, Variable: &v}, nil
+	case []string:
+		return FilterFunction{Name: FilterByKeys, Keys: keys}, nil
+	default:
+		return FilterFunction{}, errors.Errorf("unknown filterByKeys argument type : %T", keys)
+	}
+}
+
+func newKeysList(keys interface{}) ([]string, error) {
+	result := []string{}
+	if ks, ok := keys.([]interface{}); ok {
+		for _, k := range flatten(ks) {
+			if k, ok := k.(string); ok {
+				result = append(result, k)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func newRenameAs(alias interface{}) (FilterFunction, error) {
+	return FilterFunction{Name: RenameAs, Alias: alias.(string)}, nil
+}
+
+func newFirst() (FilterFunction, error) {
+	return FilterFunction{Name: First}, nil
+}
+
+func newCompare(operator, arg interface{}) (FilterFunction, error) {
+	v, err := newValue(arg)
+	if err != nil {
+		return FilterFunction{}, err
+	}
+
+	return FilterFunction{Name: operator.(string), Arg: &v}, nil
+}
+
+// splitFilterPath breaks the filter path into its fields, keeping
+// each list selector, like `[0]` or `[0:10]`, as a field of its own.
+func splitFilterPath(path string) []string {
+	var fields []string
+	for _, part := range strings.Split(path, ".") {
+		selected := false
+		for {
+			open := strings.Index(part, "[")
+			if open < 0 {
+				break
+			}
+			selected = true
+
+			if open > 0 {
+				fields = append(fields, part[:open])
+			}
+
+			end := strings.Index(part, "]") + 1
+			fields = append(fields, part[open:end])
+			part = part[end:]
+		}
+
+		if part != "" || !selected {
+			fields = append(fields, part)
+		}
+	}
+
+	return fields
+}
+
+func newMatch(arg, flags interface{}) (Match, error) {
+	var m Match
+	switch arg := arg.(type) {
+	case string:
+		m.String = &arg
+	case variable:
+		matchVar := string(arg)
+		m.Variable = &matchVar
+	}
+
+	if f, ok := flags.(string); ok {
+		m.Flags = f
+	}
+
+	return m, nil
+}
+
 func newFilterValue(value interface{}) (string, error) {
 	switch value := value.(type) {
 	case string:
@@ -448,6 +682,54 @@ func newFilterValue(value interface{}) (string, error) {
 
 type hidden bool
 
+func newCompute(first, others interface{}) ([]ComputedField, error) {
+	fields := []ComputedField{first.(ComputedField)}
+
+	if others != nil {
+		cs := others.([]interface{})
+		if len(cs) > 0 {
+			cs = flatten(cs)
+
+			for _, c := range cs {
+				if c, ok := c.(ComputedField); ok {
+					fields = append(fields, c)
+				}
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+type aggregator struct {
+	name      string
+	separator string
+}
+
+func newComputedField(name, path, agg, expr interface{}) (ComputedField, error) {
+	field := ComputedField{Name: name.(string), Path: strings.Split(path.(string), ".")}
+
+	if a, ok := agg.(aggregator); ok {
+		field.Aggregator = a.name
+		field.Separator = a.separator
+	}
+
+	if e, ok := expr.(expression); ok {
+		field.Expression = string(e)
+	}
+
+	return field, nil
+}
+
+func newAggregator(name string, separator interface{}) (aggregator, error) {
+	a := aggregator{name: name}
+	if s, ok := separator.(string); ok {
+		a.separator = s
+	}
+
+	return a, nil
+}
+
 func newHidden() (hidden, error) {
 	return true, nil
 }
@@ -497,6 +779,17 @@ func newHeaderValue(value interface{}) (HeaderValue, error) {
 	}
 }
 
+type httpMethod string
+
+func newHTTPMethod(name interface{}) (httpMethod, error) {
+	n, ok := name.(string)
+	if !ok {
+		return "", fmt.Errorf("got an unknown type : %T", name)
+	}
+
+	return httpMethod(strings.ToUpper(n)), nil
+}
+
 func newTimeout(value interface{}) (*TimeoutValue, error) {
 	switch value := value.(type) {
 	case variable:
@@ -504,6 +797,8 @@ func newTimeout(value interface{}) (*TimeoutValue, error) {
 		return &TimeoutValue{Variable: &v}, nil
 	case int:
 		return &TimeoutValue{Int: &value}, nil
+	case []Chained:
+		return &TimeoutValue{Chain: value}, nil
 	default:
 		return &TimeoutValue{}, fmt.Errorf("got an unknown type : %T", value)
 	}
@@ -516,6 +811,8 @@ func newMaxAge(value interface{}) (*MaxAgeValue, error) {
 		return &MaxAgeValue{Variable: &v}, nil
 	case int:
 		return &MaxAgeValue{Int: &value}, nil
+	case []Chained:
+		return &MaxAgeValue{Chain: value}, nil
 	default:
 		return &MaxAgeValue{}, fmt.Errorf("got an unknown type : %T", value)
 	}
@@ -528,20 +825,76 @@ func newSmaxAge(value interface{}) (*SMaxAgeValue, error) {
 		return &SMaxAgeValue{Variable: &v}, nil
 	case int:
 		return &SMaxAgeValue{Int: &value}, nil
+	case []Chained:
+		return &SMaxAgeValue{Chain: value}, nil
 	default:
 		return &SMaxAgeValue{}, fmt.Errorf("got an unknown type : %T", value)
 	}
 }
 
-type ignoreErrors bool
+type cacheTTL int
 
-func newFlags(ignoreFlag, others interface{}) (ignoreErrors, error) {
-	i := ignoreFlag.(ignoreErrors)
-	return i, nil
+func newCache(value interface{}) (cacheTTL, error) {
+	ttl, ok := value.(int)
+	if !ok {
+		return 0, fmt.Errorf("got an unknown type : %T", value)
+	}
+
+	return cacheTTL(ttl), nil
 }
 
-func newIgnoreErrors() (ignoreErrors, error) {
-	return true, nil
+type sloTarget int
+
+func newSLO(value interface{}) (sloTarget, error) {
+	target, ok := value.(int)
+	if !ok {
+		return 0, fmt.Errorf("got an unknown type : %T", value)
+	}
+
+	return sloTarget(target), nil
+}
+
+type returnHeaders []string
+
+func newReturnHeaders(first, others interface{}) (returnHeaders, error) {
+	headers := returnHeaders{first.(string)}
+
+	for _, o := range others.([]interface{}) {
+		seq := o.([]interface{})
+		headers = append(headers, seq[len(seq)-1].(string))
+	}
+
+	return headers, nil
+}
+
+func newDefault(value interface{}) (*Value, error) {
+	v := value.(Value)
+	return &v, nil
+}
+
+type statementFlag string
+
+func newFlags(flag, others interface{}) ([]statementFlag, error) {
+	result := []statementFlag{flag.(statementFlag)}
+
+	for _, o := range others.([]interface{}) {
+		seq := o.([]interface{})
+		result = append(result, seq[len(seq)-1].(statementFlag))
+	}
+
+	return result, nil
+}
+
+func newIgnoreErrors() (statementFlag, error) {
+	return IgnoreErrorsKeyword, nil
+}
+
+func newFilterErrors() (statementFlag, error) {
+	return FilterErrorsKeyword, nil
+}
+
+func newNoCache() (statementFlag, error) {
+	return NoCacheKeyword, nil
 }
 
 func newBoolean(boolean []byte) (bool, error) {
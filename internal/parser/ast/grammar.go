@@ -49,58 +49,67 @@ var g = &grammar {
 	label: "us",
 	expr: &zeroOrMoreExpr{
 	pos: position{line: 17, col: 37, offset: 154},
-	expr: &ruleRefExpr{
+	expr: &choiceExpr{
+	pos: position{line: 17, col: 38, offset: 155},
+	alternatives: []interface{}{
+&ruleRefExpr{
 	pos: position{line: 17, col: 38, offset: 155},
+	name: "USE_ONLY",
+},
+&ruleRefExpr{
+	pos: position{line: 17, col: 49, offset: 166},
 	name: "USE",
+},
+	},
 },
 },
 },
 &ruleRefExpr{
-	pos: position{line: 17, col: 44, offset: 161},
+	pos: position{line: 17, col: 55, offset: 172},
 	name: "WS",
 },
 &zeroOrMoreExpr{
-	pos: position{line: 17, col: 47, offset: 164},
+	pos: position{line: 17, col: 58, offset: 175},
 	expr: &choiceExpr{
-	pos: position{line: 17, col: 48, offset: 165},
+	pos: position{line: 17, col: 59, offset: 176},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 17, col: 48, offset: 165},
+	pos: position{line: 17, col: 59, offset: 176},
 	name: "NL",
 },
 &ruleRefExpr{
-	pos: position{line: 17, col: 53, offset: 170},
+	pos: position{line: 17, col: 64, offset: 181},
 	name: "COMMENT",
 },
 	},
 },
 },
 &ruleRefExpr{
-	pos: position{line: 17, col: 63, offset: 180},
+	pos: position{line: 17, col: 74, offset: 191},
 	name: "WS",
 },
 &labeledExpr{
-	pos: position{line: 17, col: 66, offset: 183},
+	pos: position{line: 17, col: 77, offset: 194},
 	label: "firstBlock",
 	expr: &ruleRefExpr{
-	pos: position{line: 17, col: 77, offset: 194},
+	pos: position{line: 17, col: 88, offset: 205},
 	name: "BLOCK",
 },
 },
 &labeledExpr{
-	pos: position{line: 17, col: 83, offset: 200},
+	pos: position{line: 17, col: 94, offset: 211},
 	label: "otherBlocks",
 	expr: &zeroOrMoreExpr{
-	pos: position{line: 17, col: 95, offset: 212},
+	pos: position{line: 17, col: 106, offset: 223},
 	expr: &seqExpr{
-	pos: position{line: 17, col: 96, offset: 213},
+	pos: position{line: 17, col: 107, offset: 224},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 17, col: 96, offset: 213},
+	pos: position{line: 17, col: 107, offset: 224},
 	name: "BS",
 },
 &ruleRefExpr{
-	pos: position{line: 17, col: 99, offset: 216},
+	pos: position{line: 17, col: 110, offset: 227},
 	name: "BLOCK",
 },
 	},
@@ -108,27 +117,27 @@ var g = &grammar {
 },
 },
 &zeroOrMoreExpr{
-	pos: position{line: 17, col: 107, offset: 224},
+	pos: position{line: 17, col: 118, offset: 235},
 	expr: &choiceExpr{
-	pos: position{line: 17, col: 108, offset: 225},
+	pos: position{line: 17, col: 119, offset: 236},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 17, col: 108, offset: 225},
+	pos: position{line: 17, col: 119, offset: 236},
 	name: "NL",
 },
 &ruleRefExpr{
-	pos: position{line: 17, col: 113, offset: 230},
+	pos: position{line: 17, col: 124, offset: 241},
 	name: "SPACE",
 },
 &ruleRefExpr{
-	pos: position{line: 17, col: 121, offset: 238},
+	pos: position{line: 17, col: 132, offset: 249},
 	name: "COMMENT",
 },
 	},
 },
 },
 &ruleRefExpr{
-	pos: position{line: 17, col: 131, offset: 248},
+	pos: position{line: 17, col: 142, offset: 259},
 	name: "EOF",
 },
 	},
@@ -137,55 +146,152 @@ var g = &grammar {
 },
 {
 	name: "USE",
-	pos: position{line: 21, col: 1, offset: 303},
+	pos: position{line: 21, col: 1, offset: 314},
 	expr: &actionExpr{
-	pos: position{line: 21, col: 8, offset: 310},
+	pos: position{line: 21, col: 8, offset: 321},
 	run: (*parser).callonUSE1,
 	expr: &seqExpr{
-	pos: position{line: 21, col: 8, offset: 310},
+	pos: position{line: 21, col: 8, offset: 321},
 	exprs: []interface{}{
 &litMatcher{
-	pos: position{line: 21, col: 8, offset: 310},
+	pos: position{line: 21, col: 8, offset: 321},
 	val: "use",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 21, col: 14, offset: 316},
+	pos: position{line: 21, col: 14, offset: 327},
 	name: "WS_MAND",
 },
 &labeledExpr{
-	pos: position{line: 21, col: 22, offset: 324},
+	pos: position{line: 21, col: 22, offset: 335},
 	label: "r",
 	expr: &ruleRefExpr{
-	pos: position{line: 21, col: 25, offset: 327},
+	pos: position{line: 21, col: 25, offset: 338},
 	name: "USE_ACTION",
 },
 },
 &ruleRefExpr{
-	pos: position{line: 21, col: 37, offset: 339},
+	pos: position{line: 21, col: 37, offset: 350},
 	name: "WS",
 },
 &labeledExpr{
-	pos: position{line: 21, col: 40, offset: 342},
+	pos: position{line: 21, col: 40, offset: 353},
 	label: "v",
+	expr: &zeroOrOneExpr{
+	pos: position{line: 21, col: 42, offset: 355},
 	expr: &ruleRefExpr{
-	pos: position{line: 21, col: 43, offset: 345},
+	pos: position{line: 21, col: 43, offset: 356},
 	name: "USE_VALUE",
 },
 },
+},
+&ruleRefExpr{
+	pos: position{line: 21, col: 55, offset: 368},
+	name: "WS",
+},
+&zeroOrMoreExpr{
+	pos: position{line: 21, col: 58, offset: 371},
+	expr: &ruleRefExpr{
+	pos: position{line: 21, col: 58, offset: 371},
+	name: "LS",
+},
+},
+&ruleRefExpr{
+	pos: position{line: 21, col: 62, offset: 375},
+	name: "WS",
+},
+	},
+},
+},
+},
+{
+	name: "USE_ONLY",
+	pos: position{line: 25, col: 1, offset: 404},
+	expr: &actionExpr{
+	pos: position{line: 25, col: 13, offset: 416},
+	run: (*parser).callonUSE_ONLY1,
+	expr: &seqExpr{
+	pos: position{line: 25, col: 13, offset: 416},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 25, col: 13, offset: 416},
+	val: "use",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 25, col: 19, offset: 422},
+	name: "WS_MAND",
+},
+&litMatcher{
+	pos: position{line: 25, col: 27, offset: 430},
+	val: "only",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 25, col: 34, offset: 437},
+	name: "WS_MAND",
+},
+&labeledExpr{
+	pos: position{line: 25, col: 42, offset: 445},
+	label: "f",
+	expr: &ruleRefExpr{
+	pos: position{line: 25, col: 45, offset: 448},
+	name: "FILTER",
+},
+},
+&labeledExpr{
+	pos: position{line: 25, col: 53, offset: 456},
+	label: "fs",
+	expr: &zeroOrMoreExpr{
+	pos: position{line: 25, col: 56, offset: 459},
+	expr: &seqExpr{
+	pos: position{line: 25, col: 57, offset: 460},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 25, col: 57, offset: 460},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 25, col: 60, offset: 463},
+	val: ",",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 25, col: 64, offset: 467},
+	name: "WS",
+},
+&zeroOrMoreExpr{
+	pos: position{line: 25, col: 67, offset: 470},
+	expr: &ruleRefExpr{
+	pos: position{line: 25, col: 67, offset: 470},
+	name: "NL",
+},
+},
+&ruleRefExpr{
+	pos: position{line: 25, col: 71, offset: 474},
+	name: "WS",
+},
+&ruleRefExpr{
+	pos: position{line: 25, col: 74, offset: 477},
+	name: "FILTER",
+},
+	},
+},
+},
+},
 &ruleRefExpr{
-	pos: position{line: 21, col: 54, offset: 356},
+	pos: position{line: 25, col: 83, offset: 486},
 	name: "WS",
 },
 &zeroOrMoreExpr{
-	pos: position{line: 21, col: 57, offset: 359},
+	pos: position{line: 25, col: 86, offset: 489},
 	expr: &ruleRefExpr{
-	pos: position{line: 21, col: 57, offset: 359},
+	pos: position{line: 25, col: 86, offset: 489},
 	name: "LS",
 },
 },
 &ruleRefExpr{
-	pos: position{line: 21, col: 61, offset: 363},
+	pos: position{line: 25, col: 90, offset: 493},
 	name: "WS",
 },
 	},
@@ -194,27 +300,57 @@ var g = &grammar {
 },
 {
 	name: "USE_ACTION",
-	pos: position{line: 25, col: 1, offset: 392},
+	pos: position{line: 29, col: 1, offset: 527},
 	expr: &actionExpr{
-	pos: position{line: 25, col: 15, offset: 406},
+	pos: position{line: 29, col: 15, offset: 541},
 	run: (*parser).callonUSE_ACTION1,
 	expr: &choiceExpr{
-	pos: position{line: 25, col: 16, offset: 407},
+	pos: position{line: 29, col: 16, offset: 542},
 	alternatives: []interface{}{
 &litMatcher{
-	pos: position{line: 25, col: 16, offset: 407},
+	pos: position{line: 29, col: 16, offset: 542},
 	val: "timeout",
 	ignoreCase: false,
 },
 &litMatcher{
-	pos: position{line: 25, col: 28, offset: 419},
+	pos: position{line: 29, col: 28, offset: 554},
+	val: "retries",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 29, col: 40, offset: 566},
 	val: "max-age",
 	ignoreCase: false,
 },
 &litMatcher{
-	pos: position{line: 25, col: 40, offset: 431},
+	pos: position{line: 29, col: 52, offset: 578},
 	val: "s-max-age",
 	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 29, col: 66, offset: 592},
+	val: "mock",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 29, col: 75, offset: 601},
+	val: "subscribe",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 29, col: 89, offset: 615},
+	val: "strict",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 29, col: 100, offset: 626},
+	val: "cache",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 29, col: 110, offset: 636},
+	val: "slo",
+	ignoreCase: false,
 },
 	},
 },
@@ -222,23 +358,27 @@ var g = &grammar {
 },
 {
 	name: "USE_VALUE",
-	pos: position{line: 29, col: 1, offset: 475},
+	pos: position{line: 33, col: 1, offset: 674},
 	expr: &actionExpr{
-	pos: position{line: 29, col: 14, offset: 488},
+	pos: position{line: 33, col: 14, offset: 687},
 	run: (*parser).callonUSE_VALUE1,
 	expr: &labeledExpr{
-	pos: position{line: 29, col: 14, offset: 488},
+	pos: position{line: 33, col: 14, offset: 687},
 	label: "v",
 	expr: &choiceExpr{
-	pos: position{line: 29, col: 17, offset: 491},
+	pos: position{line: 33, col: 17, offset: 690},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 29, col: 17, offset: 491},
+	pos: position{line: 33, col: 17, offset: 690},
 	name: "String",
 },
 &ruleRefExpr{
-	pos: position{line: 29, col: 26, offset: 500},
+	pos: position{line: 33, col: 26, offset: 699},
 	name: "Integer",
+},
+&ruleRefExpr{
+	pos: position{line: 33, col: 36, offset: 709},
+	name: "Boolean",
 },
 	},
 },
@@ -247,57 +387,57 @@ var g = &grammar {
 },
 {
 	name: "BLOCK",
-	pos: position{line: 33, col: 1, offset: 537},
+	pos: position{line: 37, col: 1, offset: 746},
 	expr: &actionExpr{
-	pos: position{line: 33, col: 10, offset: 546},
+	pos: position{line: 37, col: 10, offset: 755},
 	run: (*parser).callonBLOCK1,
 	expr: &seqExpr{
-	pos: position{line: 33, col: 10, offset: 546},
+	pos: position{line: 37, col: 10, offset: 755},
 	exprs: []interface{}{
 &labeledExpr{
-	pos: position{line: 33, col: 10, offset: 546},
+	pos: position{line: 37, col: 10, offset: 755},
 	label: "action",
 	expr: &ruleRefExpr{
-	pos: position{line: 33, col: 18, offset: 554},
+	pos: position{line: 37, col: 18, offset: 763},
 	name: "ACTION_RULE",
 },
 },
 &labeledExpr{
-	pos: position{line: 33, col: 31, offset: 567},
+	pos: position{line: 37, col: 31, offset: 776},
 	label: "m",
 	expr: &zeroOrOneExpr{
-	pos: position{line: 33, col: 34, offset: 570},
+	pos: position{line: 37, col: 34, offset: 779},
 	expr: &ruleRefExpr{
-	pos: position{line: 33, col: 34, offset: 570},
+	pos: position{line: 37, col: 34, offset: 779},
 	name: "MODIFIER_RULE",
 },
 },
 },
 &labeledExpr{
-	pos: position{line: 33, col: 50, offset: 586},
+	pos: position{line: 37, col: 50, offset: 795},
 	label: "w",
 	expr: &zeroOrOneExpr{
-	pos: position{line: 33, col: 53, offset: 589},
+	pos: position{line: 37, col: 53, offset: 798},
 	expr: &ruleRefExpr{
-	pos: position{line: 33, col: 53, offset: 589},
+	pos: position{line: 37, col: 53, offset: 798},
 	name: "WITH_RULE",
 },
 },
 },
 &labeledExpr{
-	pos: position{line: 33, col: 65, offset: 601},
+	pos: position{line: 37, col: 65, offset: 810},
 	label: "f",
 	expr: &zeroOrOneExpr{
-	pos: position{line: 33, col: 67, offset: 603},
+	pos: position{line: 37, col: 67, offset: 812},
 	expr: &choiceExpr{
-	pos: position{line: 33, col: 68, offset: 604},
+	pos: position{line: 37, col: 68, offset: 813},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 33, col: 68, offset: 604},
+	pos: position{line: 37, col: 68, offset: 813},
 	name: "HIDDEN_RULE",
 },
 &ruleRefExpr{
-	pos: position{line: 33, col: 82, offset: 618},
+	pos: position{line: 37, col: 82, offset: 827},
 	name: "ONLY_RULE",
 },
 	},
@@ -305,18 +445,29 @@ var g = &grammar {
 },
 },
 &labeledExpr{
-	pos: position{line: 33, col: 94, offset: 630},
+	pos: position{line: 37, col: 94, offset: 839},
+	label: "cp",
+	expr: &zeroOrOneExpr{
+	pos: position{line: 37, col: 98, offset: 843},
+	expr: &ruleRefExpr{
+	pos: position{line: 37, col: 98, offset: 843},
+	name: "COMPUTE_RULE",
+},
+},
+},
+&labeledExpr{
+	pos: position{line: 37, col: 113, offset: 858},
 	label: "fl",
 	expr: &zeroOrOneExpr{
-	pos: position{line: 33, col: 98, offset: 634},
+	pos: position{line: 37, col: 117, offset: 862},
 	expr: &ruleRefExpr{
-	pos: position{line: 33, col: 98, offset: 634},
+	pos: position{line: 37, col: 117, offset: 862},
 	name: "FLAGS_RULE",
 },
 },
 },
 &ruleRefExpr{
-	pos: position{line: 33, col: 111, offset: 647},
+	pos: position{line: 37, col: 130, offset: 875},
 	name: "WS",
 },
 	},
@@ -325,54 +476,193 @@ var g = &grammar {
 },
 {
 	name: "ACTION_RULE",
-	pos: position{line: 37, col: 1, offset: 693},
+	pos: position{line: 41, col: 1, offset: 925},
 	expr: &actionExpr{
-	pos: position{line: 37, col: 16, offset: 708},
+	pos: position{line: 41, col: 16, offset: 940},
 	run: (*parser).callonACTION_RULE1,
 	expr: &seqExpr{
-	pos: position{line: 37, col: 16, offset: 708},
+	pos: position{line: 41, col: 16, offset: 940},
 	exprs: []interface{}{
 &labeledExpr{
-	pos: position{line: 37, col: 16, offset: 708},
+	pos: position{line: 41, col: 16, offset: 940},
 	label: "m",
 	expr: &ruleRefExpr{
-	pos: position{line: 37, col: 19, offset: 711},
+	pos: position{line: 41, col: 19, offset: 943},
 	name: "METHOD",
 },
 },
 &ruleRefExpr{
-	pos: position{line: 37, col: 27, offset: 719},
+	pos: position{line: 41, col: 27, offset: 951},
 	name: "WS_MAND",
 },
 &labeledExpr{
-	pos: position{line: 37, col: 35, offset: 727},
+	pos: position{line: 41, col: 35, offset: 959},
 	label: "r",
-	expr: &ruleRefExpr{
-	pos: position{line: 37, col: 38, offset: 730},
+	expr: &choiceExpr{
+	pos: position{line: 41, col: 38, offset: 962},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 41, col: 38, offset: 962},
+	name: "SUBQUERY",
+},
+&ruleRefExpr{
+	pos: position{line: 41, col: 49, offset: 973},
 	name: "IDENT",
+},
+	},
 },
 },
 &labeledExpr{
-	pos: position{line: 37, col: 45, offset: 737},
+	pos: position{line: 41, col: 56, offset: 980},
+	label: "fns",
+	expr: &zeroOrMoreExpr{
+	pos: position{line: 41, col: 60, offset: 984},
+	expr: &ruleRefExpr{
+	pos: position{line: 41, col: 61, offset: 985},
+	name: "RESULT_FN",
+},
+},
+},
+&labeledExpr{
+	pos: position{line: 41, col: 73, offset: 997},
 	label: "a",
 	expr: &zeroOrOneExpr{
-	pos: position{line: 37, col: 48, offset: 740},
+	pos: position{line: 41, col: 76, offset: 1000},
 	expr: &ruleRefExpr{
-	pos: position{line: 37, col: 48, offset: 740},
+	pos: position{line: 41, col: 76, offset: 1000},
 	name: "ALIAS",
 },
 },
 },
 &labeledExpr{
-	pos: position{line: 37, col: 56, offset: 748},
+	pos: position{line: 41, col: 84, offset: 1008},
 	label: "i",
 	expr: &zeroOrOneExpr{
-	pos: position{line: 37, col: 59, offset: 751},
-	expr: &ruleRefExpr{
-	pos: position{line: 37, col: 59, offset: 751},
+	pos: position{line: 41, col: 86, offset: 1010},
+	expr: &choiceExpr{
+	pos: position{line: 41, col: 87, offset: 1011},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 41, col: 87, offset: 1011},
 	name: "IN",
 },
+&ruleRefExpr{
+	pos: position{line: 41, col: 92, offset: 1016},
+	name: "JOIN",
+},
+	},
+},
+},
+},
+	},
+},
+},
+},
+{
+	name: "RESULT_FN",
+	pos: position{line: 45, col: 1, offset: 1067},
+	expr: &actionExpr{
+	pos: position{line: 45, col: 14, offset: 1080},
+	run: (*parser).callonRESULT_FN1,
+	expr: &seqExpr{
+	pos: position{line: 45, col: 14, offset: 1080},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 45, col: 14, offset: 1080},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 45, col: 17, offset: 1083},
+	val: "->",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 45, col: 22, offset: 1088},
+	name: "WS",
+},
+&labeledExpr{
+	pos: position{line: 45, col: 25, offset: 1091},
+	label: "fn",
+	expr: &choiceExpr{
+	pos: position{line: 45, col: 29, offset: 1095},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 45, col: 29, offset: 1095},
+	name: "ORDERED_FN",
+},
+&ruleRefExpr{
+	pos: position{line: 45, col: 42, offset: 1108},
+	name: "RESULT_FN_NAME",
+},
+	},
+},
+},
+	},
+},
+},
+},
+{
+	name: "ORDERED_FN",
+	pos: position{line: 49, col: 1, offset: 1145},
+	expr: &actionExpr{
+	pos: position{line: 49, col: 15, offset: 1159},
+	run: (*parser).callonORDERED_FN1,
+	expr: &seqExpr{
+	pos: position{line: 49, col: 15, offset: 1159},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 49, col: 15, offset: 1159},
+	val: "ordered",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 49, col: 25, offset: 1169},
+	val: "(",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 49, col: 29, offset: 1173},
+	name: "WS",
+},
+&labeledExpr{
+	pos: position{line: 49, col: 32, offset: 1176},
+	label: "b",
+	expr: &ruleRefExpr{
+	pos: position{line: 49, col: 35, offset: 1179},
+	name: "Boolean",
+},
+},
+&ruleRefExpr{
+	pos: position{line: 49, col: 44, offset: 1188},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 49, col: 47, offset: 1191},
+	val: ")",
+	ignoreCase: false,
+},
+	},
+},
+},
+},
+{
+	name: "RESULT_FN_NAME",
+	pos: position{line: 53, col: 1, offset: 1222},
+	expr: &actionExpr{
+	pos: position{line: 53, col: 19, offset: 1240},
+	run: (*parser).callonRESULT_FN_NAME1,
+	expr: &choiceExpr{
+	pos: position{line: 53, col: 20, offset: 1241},
+	alternatives: []interface{}{
+&litMatcher{
+	pos: position{line: 53, col: 20, offset: 1241},
+	val: "flatten",
+	ignoreCase: false,
 },
+&litMatcher{
+	pos: position{line: 53, col: 32, offset: 1253},
+	val: "distinct",
+	ignoreCase: false,
 },
 	},
 },
@@ -380,35 +670,35 @@ var g = &grammar {
 },
 {
 	name: "METHOD",
-	pos: position{line: 41, col: 1, offset: 795},
+	pos: position{line: 57, col: 1, offset: 1296},
 	expr: &actionExpr{
-	pos: position{line: 41, col: 11, offset: 805},
+	pos: position{line: 57, col: 11, offset: 1306},
 	run: (*parser).callonMETHOD1,
 	expr: &choiceExpr{
-	pos: position{line: 41, col: 12, offset: 806},
+	pos: position{line: 57, col: 12, offset: 1307},
 	alternatives: []interface{}{
 &litMatcher{
-	pos: position{line: 41, col: 12, offset: 806},
+	pos: position{line: 57, col: 12, offset: 1307},
 	val: "from",
 	ignoreCase: false,
 },
 &litMatcher{
-	pos: position{line: 41, col: 21, offset: 815},
+	pos: position{line: 57, col: 21, offset: 1316},
 	val: "to",
 	ignoreCase: false,
 },
 &litMatcher{
-	pos: position{line: 41, col: 28, offset: 822},
+	pos: position{line: 57, col: 28, offset: 1323},
 	val: "into",
 	ignoreCase: false,
 },
 &litMatcher{
-	pos: position{line: 41, col: 36, offset: 830},
+	pos: position{line: 57, col: 36, offset: 1331},
 	val: "update",
 	ignoreCase: false,
 },
 &litMatcher{
-	pos: position{line: 41, col: 47, offset: 841},
+	pos: position{line: 57, col: 47, offset: 1342},
 	val: "delete",
 	ignoreCase: false,
 },
@@ -416,33 +706,81 @@ var g = &grammar {
 },
 },
 },
+{
+	name: "SUBQUERY",
+	pos: position{line: 61, col: 1, offset: 1383},
+	expr: &actionExpr{
+	pos: position{line: 61, col: 13, offset: 1395},
+	run: (*parser).callonSUBQUERY1,
+	expr: &seqExpr{
+	pos: position{line: 61, col: 13, offset: 1395},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 61, col: 13, offset: 1395},
+	val: "query:",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 61, col: 22, offset: 1404},
+	name: "IDENT_WITHOUT_COLLON",
+},
+&litMatcher{
+	pos: position{line: 61, col: 43, offset: 1425},
+	val: "/",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 61, col: 47, offset: 1429},
+	name: "IDENT_WITHOUT_COLLON",
+},
+&zeroOrOneExpr{
+	pos: position{line: 61, col: 68, offset: 1450},
+	expr: &seqExpr{
+	pos: position{line: 61, col: 69, offset: 1451},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 61, col: 69, offset: 1451},
+	val: "/",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 61, col: 73, offset: 1455},
+	name: "Natural",
+},
+	},
+},
+},
+	},
+},
+},
+},
 {
 	name: "ALIAS",
-	pos: position{line: 45, col: 1, offset: 882},
+	pos: position{line: 65, col: 1, offset: 1496},
 	expr: &actionExpr{
-	pos: position{line: 45, col: 10, offset: 891},
+	pos: position{line: 65, col: 10, offset: 1505},
 	run: (*parser).callonALIAS1,
 	expr: &seqExpr{
-	pos: position{line: 45, col: 10, offset: 891},
+	pos: position{line: 65, col: 10, offset: 1505},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 45, col: 10, offset: 891},
+	pos: position{line: 65, col: 10, offset: 1505},
 	name: "WS_MAND",
 },
 &litMatcher{
-	pos: position{line: 45, col: 18, offset: 899},
+	pos: position{line: 65, col: 18, offset: 1513},
 	val: "as",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 45, col: 23, offset: 904},
+	pos: position{line: 65, col: 23, offset: 1518},
 	name: "WS_MAND",
 },
 &labeledExpr{
-	pos: position{line: 45, col: 31, offset: 912},
+	pos: position{line: 65, col: 31, offset: 1526},
 	label: "a",
 	expr: &ruleRefExpr{
-	pos: position{line: 45, col: 34, offset: 915},
+	pos: position{line: 65, col: 34, offset: 1529},
 	name: "IDENT",
 },
 },
@@ -452,67 +790,195 @@ var g = &grammar {
 },
 {
 	name: "IN",
-	pos: position{line: 49, col: 1, offset: 942},
+	pos: position{line: 69, col: 1, offset: 1556},
 	expr: &actionExpr{
-	pos: position{line: 49, col: 7, offset: 948},
+	pos: position{line: 69, col: 7, offset: 1562},
 	run: (*parser).callonIN1,
 	expr: &seqExpr{
-	pos: position{line: 49, col: 7, offset: 948},
+	pos: position{line: 69, col: 7, offset: 1562},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 49, col: 7, offset: 948},
+	pos: position{line: 69, col: 7, offset: 1562},
 	name: "WS_MAND",
 },
 &litMatcher{
-	pos: position{line: 49, col: 15, offset: 956},
+	pos: position{line: 69, col: 15, offset: 1570},
 	val: "in",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 49, col: 20, offset: 961},
+	pos: position{line: 69, col: 20, offset: 1575},
 	name: "WS_MAND",
 },
 &labeledExpr{
-	pos: position{line: 49, col: 28, offset: 969},
+	pos: position{line: 69, col: 28, offset: 1583},
 	label: "t",
 	expr: &ruleRefExpr{
-	pos: position{line: 49, col: 31, offset: 972},
+	pos: position{line: 69, col: 31, offset: 1586},
 	name: "IDENT_WITH_DOT",
 },
+},
+&labeledExpr{
+	pos: position{line: 69, col: 47, offset: 1602},
+	label: "j",
+	expr: &zeroOrOneExpr{
+	pos: position{line: 69, col: 50, offset: 1605},
+	expr: &ruleRefExpr{
+	pos: position{line: 69, col: 50, offset: 1605},
+	name: "JOIN_KEY",
+},
+},
 },
 	},
 },
 },
 },
 {
-	name: "MODIFIER_RULE",
-	pos: position{line: 53, col: 1, offset: 1010},
+	name: "JOIN",
+	pos: position{line: 73, col: 1, offset: 1641},
 	expr: &actionExpr{
-	pos: position{line: 53, col: 18, offset: 1027},
-	run: (*parser).callonMODIFIER_RULE1,
-	expr: &labeledExpr{
-	pos: position{line: 53, col: 18, offset: 1027},
-	label: "m",
-	expr: &oneOrMoreExpr{
-	pos: position{line: 53, col: 20, offset: 1029},
-	expr: &choiceExpr{
-	pos: position{line: 53, col: 21, offset: 1030},
-	alternatives: []interface{}{
+	pos: position{line: 73, col: 9, offset: 1649},
+	run: (*parser).callonJOIN1,
+	expr: &seqExpr{
+	pos: position{line: 73, col: 9, offset: 1649},
+	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 53, col: 21, offset: 1030},
-	name: "HEADERS",
+	pos: position{line: 73, col: 9, offset: 1649},
+	name: "WS_MAND",
+},
+&litMatcher{
+	pos: position{line: 73, col: 17, offset: 1657},
+	val: "join",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 73, col: 24, offset: 1664},
+	name: "WS_MAND",
+},
+&labeledExpr{
+	pos: position{line: 73, col: 32, offset: 1672},
+	label: "t",
+	expr: &ruleRefExpr{
+	pos: position{line: 73, col: 35, offset: 1675},
+	name: "IDENT",
+},
+},
+&labeledExpr{
+	pos: position{line: 73, col: 42, offset: 1682},
+	label: "j",
+	expr: &ruleRefExpr{
+	pos: position{line: 73, col: 45, offset: 1685},
+	name: "JOIN_KEY",
+},
+},
+	},
+},
+},
+},
+{
+	name: "JOIN_KEY",
+	pos: position{line: 77, col: 1, offset: 1720},
+	expr: &actionExpr{
+	pos: position{line: 77, col: 13, offset: 1732},
+	run: (*parser).callonJOIN_KEY1,
+	expr: &seqExpr{
+	pos: position{line: 77, col: 13, offset: 1732},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 77, col: 13, offset: 1732},
+	name: "WS_MAND",
+},
+&litMatcher{
+	pos: position{line: 77, col: 21, offset: 1740},
+	val: "on",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 77, col: 26, offset: 1745},
+	name: "WS_MAND",
+},
+&labeledExpr{
+	pos: position{line: 77, col: 34, offset: 1753},
+	label: "t",
+	expr: &ruleRefExpr{
+	pos: position{line: 77, col: 37, offset: 1756},
+	name: "IDENT_WITH_DOT",
+},
+},
+&ruleRefExpr{
+	pos: position{line: 77, col: 53, offset: 1772},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 77, col: 56, offset: 1775},
+	val: "=",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 77, col: 60, offset: 1779},
+	name: "WS",
+},
+&labeledExpr{
+	pos: position{line: 77, col: 63, offset: 1782},
+	label: "o",
+	expr: &ruleRefExpr{
+	pos: position{line: 77, col: 66, offset: 1785},
+	name: "IDENT_WITH_DOT",
+},
+},
+	},
+},
+},
+},
+{
+	name: "MODIFIER_RULE",
+	pos: position{line: 81, col: 1, offset: 1831},
+	expr: &actionExpr{
+	pos: position{line: 81, col: 18, offset: 1848},
+	run: (*parser).callonMODIFIER_RULE1,
+	expr: &labeledExpr{
+	pos: position{line: 81, col: 18, offset: 1848},
+	label: "m",
+	expr: &oneOrMoreExpr{
+	pos: position{line: 81, col: 20, offset: 1850},
+	expr: &choiceExpr{
+	pos: position{line: 81, col: 21, offset: 1851},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 81, col: 21, offset: 1851},
+	name: "HEADERS",
 },
 &ruleRefExpr{
-	pos: position{line: 53, col: 31, offset: 1040},
+	pos: position{line: 81, col: 31, offset: 1861},
 	name: "TIMEOUT",
 },
 &ruleRefExpr{
-	pos: position{line: 53, col: 41, offset: 1050},
+	pos: position{line: 81, col: 41, offset: 1871},
 	name: "MAX_AGE",
 },
 &ruleRefExpr{
-	pos: position{line: 53, col: 51, offset: 1060},
+	pos: position{line: 81, col: 51, offset: 1881},
 	name: "S_MAX_AGE",
+},
+&ruleRefExpr{
+	pos: position{line: 81, col: 63, offset: 1893},
+	name: "CACHE",
+},
+&ruleRefExpr{
+	pos: position{line: 81, col: 71, offset: 1901},
+	name: "SLO",
+},
+&ruleRefExpr{
+	pos: position{line: 81, col: 77, offset: 1907},
+	name: "RETURN_HEADERS",
+},
+&ruleRefExpr{
+	pos: position{line: 81, col: 94, offset: 1924},
+	name: "DEFAULT",
+},
+&ruleRefExpr{
+	pos: position{line: 81, col: 104, offset: 1934},
+	name: "HTTP_METHOD",
 },
 	},
 },
@@ -522,44 +988,44 @@ var g = &grammar {
 },
 {
 	name: "WITH_RULE",
-	pos: position{line: 57, col: 1, offset: 1092},
+	pos: position{line: 85, col: 1, offset: 1968},
 	expr: &actionExpr{
-	pos: position{line: 57, col: 14, offset: 1105},
+	pos: position{line: 85, col: 14, offset: 1981},
 	run: (*parser).callonWITH_RULE1,
 	expr: &seqExpr{
-	pos: position{line: 57, col: 14, offset: 1105},
+	pos: position{line: 85, col: 14, offset: 1981},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 57, col: 14, offset: 1105},
+	pos: position{line: 85, col: 14, offset: 1981},
 	name: "WS_MAND",
 },
 &litMatcher{
-	pos: position{line: 57, col: 22, offset: 1113},
+	pos: position{line: 85, col: 22, offset: 1989},
 	val: "with",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 57, col: 29, offset: 1120},
+	pos: position{line: 85, col: 29, offset: 1996},
 	name: "WS_MAND",
 },
 &labeledExpr{
-	pos: position{line: 57, col: 37, offset: 1128},
+	pos: position{line: 85, col: 37, offset: 2004},
 	label: "pb",
 	expr: &zeroOrOneExpr{
-	pos: position{line: 57, col: 40, offset: 1131},
+	pos: position{line: 85, col: 40, offset: 2007},
 	expr: &ruleRefExpr{
-	pos: position{line: 57, col: 40, offset: 1131},
+	pos: position{line: 85, col: 40, offset: 2007},
 	name: "PARAMETER_BODY",
 },
 },
 },
 &labeledExpr{
-	pos: position{line: 57, col: 56, offset: 1147},
+	pos: position{line: 85, col: 56, offset: 2023},
 	label: "kvs",
 	expr: &zeroOrOneExpr{
-	pos: position{line: 57, col: 60, offset: 1151},
+	pos: position{line: 85, col: 60, offset: 2027},
 	expr: &ruleRefExpr{
-	pos: position{line: 57, col: 60, offset: 1151},
+	pos: position{line: 85, col: 60, offset: 2027},
 	name: "KEY_VALUE_LIST",
 },
 },
@@ -570,50 +1036,50 @@ var g = &grammar {
 },
 {
 	name: "PARAMETER_BODY",
-	pos: position{line: 61, col: 1, offset: 1197},
+	pos: position{line: 89, col: 1, offset: 2073},
 	expr: &actionExpr{
-	pos: position{line: 61, col: 19, offset: 1215},
+	pos: position{line: 89, col: 19, offset: 2091},
 	run: (*parser).callonPARAMETER_BODY1,
 	expr: &seqExpr{
-	pos: position{line: 61, col: 19, offset: 1215},
+	pos: position{line: 89, col: 19, offset: 2091},
 	exprs: []interface{}{
 &litMatcher{
-	pos: position{line: 61, col: 19, offset: 1215},
+	pos: position{line: 89, col: 19, offset: 2091},
 	val: "$",
 	ignoreCase: false,
 },
 &labeledExpr{
-	pos: position{line: 61, col: 23, offset: 1219},
+	pos: position{line: 89, col: 23, offset: 2095},
 	label: "t",
 	expr: &ruleRefExpr{
-	pos: position{line: 61, col: 26, offset: 1222},
+	pos: position{line: 89, col: 26, offset: 2098},
 	name: "IDENT",
 },
 },
 &labeledExpr{
-	pos: position{line: 61, col: 33, offset: 1229},
+	pos: position{line: 89, col: 33, offset: 2105},
 	label: "fn",
 	expr: &zeroOrMoreExpr{
-	pos: position{line: 61, col: 36, offset: 1232},
+	pos: position{line: 89, col: 36, offset: 2108},
 	expr: &ruleRefExpr{
-	pos: position{line: 61, col: 37, offset: 1233},
+	pos: position{line: 89, col: 37, offset: 2109},
 	name: "APPLY_FN",
 },
 },
 },
 &ruleRefExpr{
-	pos: position{line: 61, col: 48, offset: 1244},
+	pos: position{line: 89, col: 48, offset: 2120},
 	name: "WS",
 },
 &zeroOrOneExpr{
-	pos: position{line: 61, col: 51, offset: 1247},
+	pos: position{line: 89, col: 51, offset: 2123},
 	expr: &ruleRefExpr{
-	pos: position{line: 61, col: 51, offset: 1247},
+	pos: position{line: 89, col: 51, offset: 2123},
 	name: "LS",
 },
 },
 &ruleRefExpr{
-	pos: position{line: 61, col: 55, offset: 1251},
+	pos: position{line: 89, col: 55, offset: 2127},
 	name: "WS",
 },
 	},
@@ -622,58 +1088,58 @@ var g = &grammar {
 },
 {
 	name: "KEY_VALUE_LIST",
-	pos: position{line: 65, col: 1, offset: 1291},
+	pos: position{line: 93, col: 1, offset: 2167},
 	expr: &actionExpr{
-	pos: position{line: 65, col: 19, offset: 1309},
+	pos: position{line: 93, col: 19, offset: 2185},
 	run: (*parser).callonKEY_VALUE_LIST1,
 	expr: &seqExpr{
-	pos: position{line: 65, col: 19, offset: 1309},
+	pos: position{line: 93, col: 19, offset: 2185},
 	exprs: []interface{}{
 &labeledExpr{
-	pos: position{line: 65, col: 19, offset: 1309},
+	pos: position{line: 93, col: 19, offset: 2185},
 	label: "first",
 	expr: &ruleRefExpr{
-	pos: position{line: 65, col: 25, offset: 1315},
+	pos: position{line: 93, col: 25, offset: 2191},
 	name: "KEY_VALUE",
 },
 },
 &labeledExpr{
-	pos: position{line: 65, col: 35, offset: 1325},
+	pos: position{line: 93, col: 35, offset: 2201},
 	label: "others",
 	expr: &zeroOrMoreExpr{
-	pos: position{line: 65, col: 42, offset: 1332},
+	pos: position{line: 93, col: 42, offset: 2208},
 	expr: &seqExpr{
-	pos: position{line: 65, col: 43, offset: 1333},
+	pos: position{line: 93, col: 43, offset: 2209},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 65, col: 43, offset: 1333},
+	pos: position{line: 93, col: 43, offset: 2209},
 	name: "WS",
 },
 &choiceExpr{
-	pos: position{line: 65, col: 47, offset: 1337},
+	pos: position{line: 93, col: 47, offset: 2213},
 	alternatives: []interface{}{
 &seqExpr{
-	pos: position{line: 65, col: 47, offset: 1337},
+	pos: position{line: 93, col: 47, offset: 2213},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 65, col: 47, offset: 1337},
+	pos: position{line: 93, col: 47, offset: 2213},
 	name: "LS",
 },
 &zeroOrMoreExpr{
-	pos: position{line: 65, col: 50, offset: 1340},
+	pos: position{line: 93, col: 50, offset: 2216},
 	expr: &seqExpr{
-	pos: position{line: 65, col: 51, offset: 1341},
+	pos: position{line: 93, col: 51, offset: 2217},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 65, col: 51, offset: 1341},
+	pos: position{line: 93, col: 51, offset: 2217},
 	name: "WS",
 },
 &ruleRefExpr{
-	pos: position{line: 65, col: 54, offset: 1344},
+	pos: position{line: 93, col: 54, offset: 2220},
 	name: "NL",
 },
 &ruleRefExpr{
-	pos: position{line: 65, col: 57, offset: 1347},
+	pos: position{line: 93, col: 57, offset: 2223},
 	name: "WS",
 },
 	},
@@ -682,17 +1148,17 @@ var g = &grammar {
 	},
 },
 &ruleRefExpr{
-	pos: position{line: 65, col: 64, offset: 1354},
+	pos: position{line: 93, col: 64, offset: 2230},
 	name: "LS",
 },
 	},
 },
 &ruleRefExpr{
-	pos: position{line: 65, col: 68, offset: 1358},
+	pos: position{line: 93, col: 68, offset: 2234},
 	name: "WS",
 },
 &ruleRefExpr{
-	pos: position{line: 65, col: 71, offset: 1361},
+	pos: position{line: 93, col: 71, offset: 2237},
 	name: "KEY_VALUE",
 },
 	},
@@ -705,51 +1171,64 @@ var g = &grammar {
 },
 {
 	name: "KEY_VALUE",
-	pos: position{line: 69, col: 1, offset: 1417},
+	pos: position{line: 97, col: 1, offset: 2293},
 	expr: &actionExpr{
-	pos: position{line: 69, col: 14, offset: 1430},
+	pos: position{line: 97, col: 14, offset: 2306},
 	run: (*parser).callonKEY_VALUE1,
 	expr: &seqExpr{
-	pos: position{line: 69, col: 14, offset: 1430},
+	pos: position{line: 97, col: 14, offset: 2306},
 	exprs: []interface{}{
 &labeledExpr{
-	pos: position{line: 69, col: 14, offset: 1430},
+	pos: position{line: 97, col: 14, offset: 2306},
 	label: "k",
 	expr: &ruleRefExpr{
-	pos: position{line: 69, col: 17, offset: 1433},
+	pos: position{line: 97, col: 17, offset: 2309},
 	name: "IDENT_WITH_DOT",
 },
 },
 &ruleRefExpr{
-	pos: position{line: 69, col: 33, offset: 1449},
+	pos: position{line: 97, col: 33, offset: 2325},
 	name: "WS",
 },
 &litMatcher{
-	pos: position{line: 69, col: 36, offset: 1452},
+	pos: position{line: 97, col: 36, offset: 2328},
 	val: "=",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 69, col: 40, offset: 1456},
+	pos: position{line: 97, col: 40, offset: 2332},
 	name: "WS",
 },
 &labeledExpr{
-	pos: position{line: 69, col: 43, offset: 1459},
+	pos: position{line: 97, col: 43, offset: 2335},
 	label: "v",
 	expr: &ruleRefExpr{
-	pos: position{line: 69, col: 46, offset: 1462},
+	pos: position{line: 97, col: 46, offset: 2338},
 	name: "VALUE",
 },
 },
 &labeledExpr{
-	pos: position{line: 69, col: 53, offset: 1469},
+	pos: position{line: 97, col: 53, offset: 2345},
 	label: "fn",
 	expr: &zeroOrMoreExpr{
-	pos: position{line: 69, col: 56, offset: 1472},
-	expr: &ruleRefExpr{
-	pos: position{line: 69, col: 57, offset: 1473},
+	pos: position{line: 97, col: 56, offset: 2348},
+	expr: &choiceExpr{
+	pos: position{line: 97, col: 57, offset: 2349},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 97, col: 57, offset: 2349},
 	name: "APPLY_FN",
 },
+&ruleRefExpr{
+	pos: position{line: 97, col: 68, offset: 2360},
+	name: "DEFAULT_FN",
+},
+&ruleRefExpr{
+	pos: position{line: 97, col: 81, offset: 2373},
+	name: "EXPR_FN",
+},
+	},
+},
 },
 },
 	},
@@ -757,74 +1236,59 @@ var g = &grammar {
 },
 },
 {
-	name: "APPLY_FN",
-	pos: position{line: 73, col: 1, offset: 1519},
+	name: "DEFAULT_FN",
+	pos: position{line: 101, col: 1, offset: 2418},
 	expr: &actionExpr{
-	pos: position{line: 73, col: 13, offset: 1531},
-	run: (*parser).callonAPPLY_FN1,
+	pos: position{line: 101, col: 15, offset: 2432},
+	run: (*parser).callonDEFAULT_FN1,
 	expr: &seqExpr{
-	pos: position{line: 73, col: 13, offset: 1531},
+	pos: position{line: 101, col: 15, offset: 2432},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 73, col: 13, offset: 1531},
+	pos: position{line: 101, col: 15, offset: 2432},
 	name: "WS",
 },
 &litMatcher{
-	pos: position{line: 73, col: 16, offset: 1534},
+	pos: position{line: 101, col: 18, offset: 2435},
 	val: "->",
 	ignoreCase: false,
 },
 &zeroOrOneExpr{
-	pos: position{line: 73, col: 21, offset: 1539},
+	pos: position{line: 101, col: 23, offset: 2440},
 	expr: &ruleRefExpr{
-	pos: position{line: 73, col: 21, offset: 1539},
+	pos: position{line: 101, col: 23, offset: 2440},
 	name: "WS",
 },
 },
-&labeledExpr{
-	pos: position{line: 73, col: 25, offset: 1543},
-	label: "fn",
-	expr: &ruleRefExpr{
-	pos: position{line: 73, col: 29, offset: 1547},
-	name: "FUNCTION",
-},
-},
-	},
-},
-},
-},
-{
-	name: "FUNCTION",
-	pos: position{line: 77, col: 1, offset: 1578},
-	expr: &actionExpr{
-	pos: position{line: 77, col: 13, offset: 1590},
-	run: (*parser).callonFUNCTION1,
-	expr: &choiceExpr{
-	pos: position{line: 77, col: 14, offset: 1591},
-	alternatives: []interface{}{
 &litMatcher{
-	pos: position{line: 77, col: 14, offset: 1591},
-	val: "no-multiplex",
+	pos: position{line: 101, col: 27, offset: 2444},
+	val: "default",
 	ignoreCase: false,
 },
 &litMatcher{
-	pos: position{line: 77, col: 31, offset: 1608},
-	val: "base64",
+	pos: position{line: 101, col: 37, offset: 2454},
+	val: "(",
 	ignoreCase: false,
 },
-&litMatcher{
-	pos: position{line: 77, col: 42, offset: 1619},
-	val: "json",
-	ignoreCase: false,
+&ruleRefExpr{
+	pos: position{line: 101, col: 41, offset: 2458},
+	name: "WS",
 },
-&litMatcher{
-	pos: position{line: 77, col: 50, offset: 1627},
-	val: "as-body",
-	ignoreCase: false,
+&labeledExpr{
+	pos: position{line: 101, col: 44, offset: 2461},
+	label: "v",
+	expr: &ruleRefExpr{
+	pos: position{line: 101, col: 47, offset: 2464},
+	name: "DEFAULT_VALUE",
+},
+},
+&ruleRefExpr{
+	pos: position{line: 101, col: 62, offset: 2479},
+	name: "WS",
 },
 &litMatcher{
-	pos: position{line: 77, col: 62, offset: 1639},
-	val: "flatten",
+	pos: position{line: 101, col: 65, offset: 2482},
+	val: ")",
 	ignoreCase: false,
 },
 	},
@@ -832,32 +1296,32 @@ var g = &grammar {
 },
 },
 {
-	name: "VALUE",
-	pos: position{line: 81, col: 1, offset: 1681},
+	name: "DEFAULT_VALUE",
+	pos: position{line: 105, col: 1, offset: 2521},
 	expr: &actionExpr{
-	pos: position{line: 81, col: 10, offset: 1690},
-	run: (*parser).callonVALUE1,
+	pos: position{line: 105, col: 18, offset: 2538},
+	run: (*parser).callonDEFAULT_VALUE1,
 	expr: &labeledExpr{
-	pos: position{line: 81, col: 10, offset: 1690},
+	pos: position{line: 105, col: 18, offset: 2538},
 	label: "v",
 	expr: &choiceExpr{
-	pos: position{line: 81, col: 13, offset: 1693},
+	pos: position{line: 105, col: 21, offset: 2541},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 81, col: 13, offset: 1693},
+	pos: position{line: 105, col: 21, offset: 2541},
 	name: "LIST",
 },
 &ruleRefExpr{
-	pos: position{line: 81, col: 20, offset: 1700},
+	pos: position{line: 105, col: 28, offset: 2548},
 	name: "OBJECT",
 },
 &ruleRefExpr{
-	pos: position{line: 81, col: 29, offset: 1709},
+	pos: position{line: 105, col: 37, offset: 2557},
 	name: "VARIABLE",
 },
 &ruleRefExpr{
-	pos: position{line: 81, col: 40, offset: 1720},
-	name: "PRIMITIVE",
+	pos: position{line: 105, col: 48, offset: 2568},
+	name: "DEFAULT_PRIMITIVE",
 },
 	},
 },
@@ -865,24 +1329,32 @@ var g = &grammar {
 },
 },
 {
-	name: "LIST",
-	pos: position{line: 85, col: 1, offset: 1756},
+	name: "DEFAULT_PRIMITIVE",
+	pos: position{line: 109, col: 1, offset: 2612},
 	expr: &actionExpr{
-	pos: position{line: 85, col: 9, offset: 1764},
-	run: (*parser).callonLIST1,
+	pos: position{line: 109, col: 22, offset: 2633},
+	run: (*parser).callonDEFAULT_PRIMITIVE1,
 	expr: &labeledExpr{
-	pos: position{line: 85, col: 9, offset: 1764},
-	label: "l",
+	pos: position{line: 109, col: 22, offset: 2633},
+	label: "p",
 	expr: &choiceExpr{
-	pos: position{line: 85, col: 12, offset: 1767},
+	pos: position{line: 109, col: 25, offset: 2636},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 85, col: 12, offset: 1767},
-	name: "EMPTY_LIST",
+	pos: position{line: 109, col: 25, offset: 2636},
+	name: "Boolean",
 },
 &ruleRefExpr{
-	pos: position{line: 85, col: 25, offset: 1780},
-	name: "POPULATED_LIST",
+	pos: position{line: 109, col: 35, offset: 2646},
+	name: "String",
+},
+&ruleRefExpr{
+	pos: position{line: 109, col: 44, offset: 2655},
+	name: "Float",
+},
+&ruleRefExpr{
+	pos: position{line: 109, col: 52, offset: 2663},
+	name: "Integer",
 },
 	},
 },
@@ -890,260 +1362,289 @@ var g = &grammar {
 },
 },
 {
-	name: "EMPTY_LIST",
-	pos: position{line: 89, col: 1, offset: 1816},
+	name: "EXPR_FN",
+	pos: position{line: 113, col: 1, offset: 2701},
 	expr: &actionExpr{
-	pos: position{line: 89, col: 15, offset: 1830},
-	run: (*parser).callonEMPTY_LIST1,
+	pos: position{line: 113, col: 12, offset: 2712},
+	run: (*parser).callonEXPR_FN1,
 	expr: &seqExpr{
-	pos: position{line: 89, col: 15, offset: 1830},
+	pos: position{line: 113, col: 12, offset: 2712},
 	exprs: []interface{}{
-&litMatcher{
-	pos: position{line: 89, col: 15, offset: 1830},
-	val: "[",
-	ignoreCase: false,
-},
 &ruleRefExpr{
-	pos: position{line: 89, col: 19, offset: 1834},
+	pos: position{line: 113, col: 12, offset: 2712},
 	name: "WS",
 },
 &litMatcher{
-	pos: position{line: 89, col: 22, offset: 1837},
-	val: "]",
+	pos: position{line: 113, col: 15, offset: 2715},
+	val: "->",
 	ignoreCase: false,
 },
-	},
+&zeroOrOneExpr{
+	pos: position{line: 113, col: 20, offset: 2720},
+	expr: &ruleRefExpr{
+	pos: position{line: 113, col: 20, offset: 2720},
+	name: "WS",
 },
 },
+&litMatcher{
+	pos: position{line: 113, col: 24, offset: 2724},
+	val: "expr",
+	ignoreCase: false,
 },
-{
-	name: "POPULATED_LIST",
-	pos: position{line: 93, col: 1, offset: 1869},
-	expr: &actionExpr{
-	pos: position{line: 93, col: 19, offset: 1887},
-	run: (*parser).callonPOPULATED_LIST1,
-	expr: &seqExpr{
-	pos: position{line: 93, col: 19, offset: 1887},
-	exprs: []interface{}{
 &litMatcher{
-	pos: position{line: 93, col: 19, offset: 1887},
-	val: "[",
+	pos: position{line: 113, col: 31, offset: 2731},
+	val: "(",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 93, col: 23, offset: 1891},
+	pos: position{line: 113, col: 35, offset: 2735},
 	name: "WS",
 },
 &labeledExpr{
-	pos: position{line: 93, col: 26, offset: 1894},
-	label: "i",
-	expr: &ruleRefExpr{
-	pos: position{line: 93, col: 28, offset: 1896},
-	name: "VALUE",
-},
-},
-&labeledExpr{
-	pos: position{line: 93, col: 34, offset: 1902},
-	label: "ii",
-	expr: &zeroOrMoreExpr{
-	pos: position{line: 93, col: 37, offset: 1905},
-	expr: &seqExpr{
-	pos: position{line: 93, col: 38, offset: 1906},
-	exprs: []interface{}{
-&ruleRefExpr{
-	pos: position{line: 93, col: 38, offset: 1906},
-	name: "WS",
-},
-&zeroOrMoreExpr{
-	pos: position{line: 93, col: 41, offset: 1909},
+	pos: position{line: 113, col: 38, offset: 2738},
+	label: "s",
 	expr: &ruleRefExpr{
-	pos: position{line: 93, col: 41, offset: 1909},
-	name: "LS",
+	pos: position{line: 113, col: 40, offset: 2740},
+	name: "String",
 },
 },
 &ruleRefExpr{
-	pos: position{line: 93, col: 45, offset: 1913},
+	pos: position{line: 113, col: 47, offset: 2747},
 	name: "WS",
 },
-&ruleRefExpr{
-	pos: position{line: 93, col: 48, offset: 1916},
-	name: "VALUE",
+&litMatcher{
+	pos: position{line: 113, col: 50, offset: 2750},
+	val: ")",
+	ignoreCase: false,
 },
 	},
 },
 },
 },
+{
+	name: "APPLY_FN",
+	pos: position{line: 117, col: 1, offset: 2784},
+	expr: &actionExpr{
+	pos: position{line: 117, col: 13, offset: 2796},
+	run: (*parser).callonAPPLY_FN1,
+	expr: &seqExpr{
+	pos: position{line: 117, col: 13, offset: 2796},
+	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 93, col: 56, offset: 1924},
+	pos: position{line: 117, col: 13, offset: 2796},
 	name: "WS",
 },
 &litMatcher{
-	pos: position{line: 93, col: 59, offset: 1927},
-	val: "]",
+	pos: position{line: 117, col: 16, offset: 2799},
+	val: "->",
 	ignoreCase: false,
 },
-	},
-},
+&zeroOrOneExpr{
+	pos: position{line: 117, col: 21, offset: 2804},
+	expr: &ruleRefExpr{
+	pos: position{line: 117, col: 21, offset: 2804},
+	name: "WS",
 },
 },
-{
-	name: "OBJECT",
-	pos: position{line: 97, col: 1, offset: 1959},
-	expr: &actionExpr{
-	pos: position{line: 97, col: 11, offset: 1969},
-	run: (*parser).callonOBJECT1,
-	expr: &labeledExpr{
-	pos: position{line: 97, col: 11, offset: 1969},
-	label: "o",
-	expr: &choiceExpr{
-	pos: position{line: 97, col: 14, offset: 1972},
-	alternatives: []interface{}{
-&ruleRefExpr{
-	pos: position{line: 97, col: 14, offset: 1972},
-	name: "EMPTY_OBJ",
+&labeledExpr{
+	pos: position{line: 117, col: 25, offset: 2808},
+	label: "fn",
+	expr: &ruleRefExpr{
+	pos: position{line: 117, col: 29, offset: 2812},
+	name: "FUNCTION",
 },
-&ruleRefExpr{
-	pos: position{line: 97, col: 26, offset: 1984},
-	name: "POPULATED_OBJ",
 },
 	},
 },
 },
 },
-},
 {
-	name: "EMPTY_OBJ",
-	pos: position{line: 101, col: 1, offset: 2019},
+	name: "FUNCTION",
+	pos: position{line: 121, col: 1, offset: 2843},
 	expr: &actionExpr{
-	pos: position{line: 101, col: 14, offset: 2032},
-	run: (*parser).callonEMPTY_OBJ1,
-	expr: &seqExpr{
-	pos: position{line: 101, col: 14, offset: 2032},
-	exprs: []interface{}{
+	pos: position{line: 121, col: 13, offset: 2855},
+	run: (*parser).callonFUNCTION1,
+	expr: &choiceExpr{
+	pos: position{line: 121, col: 14, offset: 2856},
+	alternatives: []interface{}{
 &litMatcher{
-	pos: position{line: 101, col: 14, offset: 2032},
-	val: "{",
+	pos: position{line: 121, col: 14, offset: 2856},
+	val: "no-multiplex",
 	ignoreCase: false,
 },
-&ruleRefExpr{
-	pos: position{line: 101, col: 18, offset: 2036},
-	name: "WS",
-},
-&zeroOrMoreExpr{
-	pos: position{line: 101, col: 21, offset: 2039},
-	expr: &ruleRefExpr{
-	pos: position{line: 101, col: 21, offset: 2039},
-	name: "NL",
+&litMatcher{
+	pos: position{line: 121, col: 31, offset: 2873},
+	val: "base64",
+	ignoreCase: false,
 },
+&litMatcher{
+	pos: position{line: 121, col: 42, offset: 2884},
+	val: "json",
+	ignoreCase: false,
 },
-&ruleRefExpr{
-	pos: position{line: 101, col: 25, offset: 2043},
-	name: "WS",
+&litMatcher{
+	pos: position{line: 121, col: 50, offset: 2892},
+	val: "as-body",
+	ignoreCase: false,
 },
 &litMatcher{
-	pos: position{line: 101, col: 28, offset: 2046},
-	val: "}",
+	pos: position{line: 121, col: 62, offset: 2904},
+	val: "flatten",
 	ignoreCase: false,
 },
-	},
+&litMatcher{
+	pos: position{line: 121, col: 74, offset: 2916},
+	val: "deep-object",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 121, col: 90, offset: 2932},
+	val: "csv",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 121, col: 98, offset: 2940},
+	val: "pipe-delimited",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 121, col: 117, offset: 2959},
+	val: "repeated",
+	ignoreCase: false,
+},
+	},
 },
 },
 },
 {
-	name: "POPULATED_OBJ",
-	pos: position{line: 105, col: 1, offset: 2080},
+	name: "VALUE",
+	pos: position{line: 125, col: 1, offset: 3002},
 	expr: &actionExpr{
-	pos: position{line: 105, col: 18, offset: 2097},
-	run: (*parser).callonPOPULATED_OBJ1,
+	pos: position{line: 125, col: 10, offset: 3011},
+	run: (*parser).callonVALUE1,
+	expr: &labeledExpr{
+	pos: position{line: 125, col: 10, offset: 3011},
+	label: "v",
+	expr: &choiceExpr{
+	pos: position{line: 125, col: 13, offset: 3014},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 125, col: 13, offset: 3014},
+	name: "RANGE",
+},
+&ruleRefExpr{
+	pos: position{line: 125, col: 21, offset: 3022},
+	name: "LIST",
+},
+&ruleRefExpr{
+	pos: position{line: 125, col: 28, offset: 3029},
+	name: "OBJECT",
+},
+&ruleRefExpr{
+	pos: position{line: 125, col: 37, offset: 3038},
+	name: "VARIABLE",
+},
+&ruleRefExpr{
+	pos: position{line: 125, col: 48, offset: 3049},
+	name: "PRIMITIVE",
+},
+	},
+},
+},
+},
+},
+{
+	name: "RANGE",
+	pos: position{line: 129, col: 1, offset: 3085},
+	expr: &actionExpr{
+	pos: position{line: 129, col: 10, offset: 3094},
+	run: (*parser).callonRANGE1,
 	expr: &seqExpr{
-	pos: position{line: 105, col: 18, offset: 2097},
+	pos: position{line: 129, col: 10, offset: 3094},
 	exprs: []interface{}{
 &litMatcher{
-	pos: position{line: 105, col: 18, offset: 2097},
-	val: "{",
+	pos: position{line: 129, col: 10, offset: 3094},
+	val: "range",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 105, col: 22, offset: 2101},
+	pos: position{line: 129, col: 18, offset: 3102},
 	name: "WS",
 },
-&zeroOrMoreExpr{
-	pos: position{line: 105, col: 25, offset: 2104},
-	expr: &ruleRefExpr{
-	pos: position{line: 105, col: 25, offset: 2104},
-	name: "NL",
-},
+&litMatcher{
+	pos: position{line: 129, col: 21, offset: 3105},
+	val: "(",
+	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 105, col: 29, offset: 2108},
+	pos: position{line: 129, col: 25, offset: 3109},
 	name: "WS",
 },
 &labeledExpr{
-	pos: position{line: 105, col: 32, offset: 2111},
-	label: "oe",
+	pos: position{line: 129, col: 28, offset: 3112},
+	label: "s",
 	expr: &ruleRefExpr{
-	pos: position{line: 105, col: 36, offset: 2115},
-	name: "OBJ_ENTRY",
+	pos: position{line: 129, col: 31, offset: 3115},
+	name: "RANGE_ARG",
 },
 },
-&labeledExpr{
-	pos: position{line: 105, col: 47, offset: 2126},
-	label: "oes",
-	expr: &zeroOrMoreExpr{
-	pos: position{line: 105, col: 51, offset: 2130},
-	expr: &seqExpr{
-	pos: position{line: 105, col: 52, offset: 2131},
-	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 105, col: 52, offset: 2131},
+	pos: position{line: 129, col: 42, offset: 3126},
 	name: "WS",
 },
 &litMatcher{
-	pos: position{line: 105, col: 55, offset: 2134},
+	pos: position{line: 129, col: 45, offset: 3129},
 	val: ",",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 105, col: 59, offset: 2138},
+	pos: position{line: 129, col: 49, offset: 3133},
 	name: "WS",
 },
-&zeroOrMoreExpr{
-	pos: position{line: 105, col: 62, offset: 2141},
+&labeledExpr{
+	pos: position{line: 129, col: 52, offset: 3136},
+	label: "e",
 	expr: &ruleRefExpr{
-	pos: position{line: 105, col: 62, offset: 2141},
-	name: "NL",
+	pos: position{line: 129, col: 55, offset: 3139},
+	name: "RANGE_ARG",
 },
 },
+&labeledExpr{
+	pos: position{line: 129, col: 66, offset: 3150},
+	label: "st",
+	expr: &zeroOrOneExpr{
+	pos: position{line: 129, col: 69, offset: 3153},
+	expr: &seqExpr{
+	pos: position{line: 129, col: 70, offset: 3154},
+	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 105, col: 66, offset: 2145},
+	pos: position{line: 129, col: 70, offset: 3154},
 	name: "WS",
 },
-&ruleRefExpr{
-	pos: position{line: 105, col: 69, offset: 2148},
-	name: "OBJ_ENTRY",
-},
-	},
-},
-},
+&litMatcher{
+	pos: position{line: 129, col: 73, offset: 3157},
+	val: ",",
+	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 105, col: 81, offset: 2160},
+	pos: position{line: 129, col: 77, offset: 3161},
 	name: "WS",
 },
-&zeroOrMoreExpr{
-	pos: position{line: 105, col: 84, offset: 2163},
-	expr: &ruleRefExpr{
-	pos: position{line: 105, col: 84, offset: 2163},
-	name: "NL",
+&ruleRefExpr{
+	pos: position{line: 129, col: 80, offset: 3164},
+	name: "RANGE_ARG",
+},
+	},
+},
 },
 },
 &ruleRefExpr{
-	pos: position{line: 105, col: 88, offset: 2167},
+	pos: position{line: 129, col: 92, offset: 3176},
 	name: "WS",
 },
 &litMatcher{
-	pos: position{line: 105, col: 91, offset: 2170},
-	val: "}",
+	pos: position{line: 129, col: 95, offset: 3179},
+	val: ")",
 	ignoreCase: false,
 },
 	},
@@ -1151,648 +1652,573 @@ var g = &grammar {
 },
 },
 {
-	name: "OBJ_ENTRY",
-	pos: position{line: 109, col: 1, offset: 2215},
+	name: "RANGE_ARG",
+	pos: position{line: 133, col: 1, offset: 3215},
 	expr: &actionExpr{
-	pos: position{line: 109, col: 14, offset: 2228},
-	run: (*parser).callonOBJ_ENTRY1,
-	expr: &seqExpr{
-	pos: position{line: 109, col: 14, offset: 2228},
-	exprs: []interface{}{
-&labeledExpr{
-	pos: position{line: 109, col: 14, offset: 2228},
-	label: "k",
+	pos: position{line: 133, col: 14, offset: 3228},
+	run: (*parser).callonRANGE_ARG1,
+	expr: &labeledExpr{
+	pos: position{line: 133, col: 14, offset: 3228},
+	label: "a",
 	expr: &choiceExpr{
-	pos: position{line: 109, col: 17, offset: 2231},
+	pos: position{line: 133, col: 17, offset: 3231},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 109, col: 17, offset: 2231},
-	name: "String",
-},
-&ruleRefExpr{
-	pos: position{line: 109, col: 26, offset: 2240},
-	name: "IDENT_WITHOUT_COLLON",
-},
-	},
-},
+	pos: position{line: 133, col: 17, offset: 3231},
+	name: "VARIABLE",
 },
 &ruleRefExpr{
-	pos: position{line: 109, col: 48, offset: 2262},
-	name: "WS",
-},
-&litMatcher{
-	pos: position{line: 109, col: 51, offset: 2265},
-	val: ":",
-	ignoreCase: false,
+	pos: position{line: 133, col: 28, offset: 3242},
+	name: "Integer",
 },
 &ruleRefExpr{
-	pos: position{line: 109, col: 55, offset: 2269},
-	name: "WS",
-},
-&labeledExpr{
-	pos: position{line: 109, col: 58, offset: 2272},
-	label: "v",
-	expr: &ruleRefExpr{
-	pos: position{line: 109, col: 61, offset: 2275},
-	name: "VALUE",
-},
+	pos: position{line: 133, col: 38, offset: 3252},
+	name: "CHAIN",
 },
 	},
 },
 },
 },
+},
 {
-	name: "PRIMITIVE",
-	pos: position{line: 113, col: 1, offset: 2316},
+	name: "LIST",
+	pos: position{line: 137, col: 1, offset: 3287},
 	expr: &actionExpr{
-	pos: position{line: 113, col: 14, offset: 2329},
-	run: (*parser).callonPRIMITIVE1,
+	pos: position{line: 137, col: 9, offset: 3295},
+	run: (*parser).callonLIST1,
 	expr: &labeledExpr{
-	pos: position{line: 113, col: 14, offset: 2329},
-	label: "p",
+	pos: position{line: 137, col: 9, offset: 3295},
+	label: "l",
 	expr: &choiceExpr{
-	pos: position{line: 113, col: 17, offset: 2332},
+	pos: position{line: 137, col: 12, offset: 3298},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 113, col: 17, offset: 2332},
-	name: "Null",
+	pos: position{line: 137, col: 12, offset: 3298},
+	name: "EMPTY_LIST",
 },
 &ruleRefExpr{
-	pos: position{line: 113, col: 24, offset: 2339},
-	name: "Boolean",
+	pos: position{line: 137, col: 25, offset: 3311},
+	name: "POPULATED_LIST",
 },
-&ruleRefExpr{
-	pos: position{line: 113, col: 34, offset: 2349},
-	name: "String",
+	},
 },
-&ruleRefExpr{
-	pos: position{line: 113, col: 43, offset: 2358},
-	name: "Float",
 },
-&ruleRefExpr{
-	pos: position{line: 113, col: 51, offset: 2366},
-	name: "Integer",
+},
+},
+{
+	name: "EMPTY_LIST",
+	pos: position{line: 141, col: 1, offset: 3347},
+	expr: &actionExpr{
+	pos: position{line: 141, col: 15, offset: 3361},
+	run: (*parser).callonEMPTY_LIST1,
+	expr: &seqExpr{
+	pos: position{line: 141, col: 15, offset: 3361},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 141, col: 15, offset: 3361},
+	val: "[",
+	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 113, col: 61, offset: 2376},
-	name: "CHAIN",
+	pos: position{line: 141, col: 19, offset: 3365},
+	name: "WS",
 },
-	},
+&litMatcher{
+	pos: position{line: 141, col: 22, offset: 3368},
+	val: "]",
+	ignoreCase: false,
 },
+	},
 },
 },
 },
 {
-	name: "ONLY_RULE",
-	pos: position{line: 119, col: 1, offset: 2414},
+	name: "POPULATED_LIST",
+	pos: position{line: 145, col: 1, offset: 3400},
 	expr: &actionExpr{
-	pos: position{line: 119, col: 14, offset: 2427},
-	run: (*parser).callonONLY_RULE1,
+	pos: position{line: 145, col: 19, offset: 3418},
+	run: (*parser).callonPOPULATED_LIST1,
 	expr: &seqExpr{
-	pos: position{line: 119, col: 14, offset: 2427},
+	pos: position{line: 145, col: 19, offset: 3418},
 	exprs: []interface{}{
-&ruleRefExpr{
-	pos: position{line: 119, col: 14, offset: 2427},
-	name: "WS_MAND",
-},
 &litMatcher{
-	pos: position{line: 119, col: 22, offset: 2435},
-	val: "only",
+	pos: position{line: 145, col: 19, offset: 3418},
+	val: "[",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 119, col: 29, offset: 2442},
-	name: "WS_MAND",
+	pos: position{line: 145, col: 23, offset: 3422},
+	name: "WS",
 },
 &labeledExpr{
-	pos: position{line: 119, col: 37, offset: 2450},
-	label: "f",
+	pos: position{line: 145, col: 26, offset: 3425},
+	label: "i",
 	expr: &ruleRefExpr{
-	pos: position{line: 119, col: 40, offset: 2453},
-	name: "FILTER",
+	pos: position{line: 145, col: 28, offset: 3427},
+	name: "VALUE",
 },
 },
 &labeledExpr{
-	pos: position{line: 119, col: 48, offset: 2461},
-	label: "fs",
+	pos: position{line: 145, col: 34, offset: 3433},
+	label: "ii",
 	expr: &zeroOrMoreExpr{
-	pos: position{line: 119, col: 51, offset: 2464},
+	pos: position{line: 145, col: 37, offset: 3436},
 	expr: &seqExpr{
-	pos: position{line: 119, col: 52, offset: 2465},
+	pos: position{line: 145, col: 38, offset: 3437},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 119, col: 52, offset: 2465},
+	pos: position{line: 145, col: 38, offset: 3437},
 	name: "WS",
 },
-&notExpr{
-	pos: position{line: 119, col: 55, offset: 2468},
-	expr: &choiceExpr{
-	pos: position{line: 119, col: 57, offset: 2470},
-	alternatives: []interface{}{
-&ruleRefExpr{
-	pos: position{line: 119, col: 57, offset: 2470},
-	name: "FLAGS_RULE",
+&zeroOrMoreExpr{
+	pos: position{line: 145, col: 41, offset: 3440},
+	expr: &ruleRefExpr{
+	pos: position{line: 145, col: 41, offset: 3440},
+	name: "LS",
 },
-&seqExpr{
-	pos: position{line: 119, col: 70, offset: 2483},
-	exprs: []interface{}{
-&ruleRefExpr{
-	pos: position{line: 119, col: 70, offset: 2483},
-	name: "BS",
 },
 &ruleRefExpr{
-	pos: position{line: 119, col: 73, offset: 2486},
-	name: "BLOCK",
+	pos: position{line: 145, col: 45, offset: 3444},
+	name: "WS",
 },
-	},
+&ruleRefExpr{
+	pos: position{line: 145, col: 48, offset: 3447},
+	name: "VALUE",
 },
 	},
 },
 },
-&choiceExpr{
-	pos: position{line: 119, col: 81, offset: 2494},
-	alternatives: []interface{}{
-&seqExpr{
-	pos: position{line: 119, col: 81, offset: 2494},
-	exprs: []interface{}{
-&ruleRefExpr{
-	pos: position{line: 119, col: 81, offset: 2494},
-	name: "LS",
-},
-&zeroOrMoreExpr{
-	pos: position{line: 119, col: 84, offset: 2497},
-	expr: &seqExpr{
-	pos: position{line: 119, col: 85, offset: 2498},
-	exprs: []interface{}{
-&ruleRefExpr{
-	pos: position{line: 119, col: 85, offset: 2498},
-	name: "WS",
 },
 &ruleRefExpr{
-	pos: position{line: 119, col: 88, offset: 2501},
-	name: "NL",
-},
-&ruleRefExpr{
-	pos: position{line: 119, col: 91, offset: 2504},
+	pos: position{line: 145, col: 56, offset: 3455},
 	name: "WS",
 },
-	},
-},
+&litMatcher{
+	pos: position{line: 145, col: 59, offset: 3458},
+	val: "]",
+	ignoreCase: false,
 },
 	},
 },
-&ruleRefExpr{
-	pos: position{line: 119, col: 98, offset: 2511},
-	name: "LS",
 },
-	},
 },
+{
+	name: "OBJECT",
+	pos: position{line: 149, col: 1, offset: 3490},
+	expr: &actionExpr{
+	pos: position{line: 149, col: 11, offset: 3500},
+	run: (*parser).callonOBJECT1,
+	expr: &labeledExpr{
+	pos: position{line: 149, col: 11, offset: 3500},
+	label: "o",
+	expr: &choiceExpr{
+	pos: position{line: 149, col: 14, offset: 3503},
+	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 119, col: 102, offset: 2515},
-	name: "WS",
+	pos: position{line: 149, col: 14, offset: 3503},
+	name: "EMPTY_OBJ",
 },
 &ruleRefExpr{
-	pos: position{line: 119, col: 105, offset: 2518},
-	name: "FILTER",
+	pos: position{line: 149, col: 26, offset: 3515},
+	name: "POPULATED_OBJ",
 },
 	},
 },
 },
-},
-	},
-},
 },
 },
 {
-	name: "FILTER",
-	pos: position{line: 123, col: 1, offset: 2555},
+	name: "EMPTY_OBJ",
+	pos: position{line: 153, col: 1, offset: 3550},
 	expr: &actionExpr{
-	pos: position{line: 123, col: 11, offset: 2565},
-	run: (*parser).callonFILTER1,
+	pos: position{line: 153, col: 14, offset: 3563},
+	run: (*parser).callonEMPTY_OBJ1,
 	expr: &seqExpr{
-	pos: position{line: 123, col: 11, offset: 2565},
+	pos: position{line: 153, col: 14, offset: 3563},
 	exprs: []interface{}{
-&labeledExpr{
-	pos: position{line: 123, col: 11, offset: 2565},
-	label: "f",
-	expr: &ruleRefExpr{
-	pos: position{line: 123, col: 14, offset: 2568},
-	name: "FILTER_VALUE",
+&litMatcher{
+	pos: position{line: 153, col: 14, offset: 3563},
+	val: "{",
+	ignoreCase: false,
 },
+&ruleRefExpr{
+	pos: position{line: 153, col: 18, offset: 3567},
+	name: "WS",
 },
-&labeledExpr{
-	pos: position{line: 123, col: 28, offset: 2582},
-	label: "fn",
-	expr: &zeroOrOneExpr{
-	pos: position{line: 123, col: 32, offset: 2586},
+&zeroOrMoreExpr{
+	pos: position{line: 153, col: 21, offset: 3570},
 	expr: &ruleRefExpr{
-	pos: position{line: 123, col: 32, offset: 2586},
-	name: "MATCHES_FN",
-},
-},
-},
-	},
-},
+	pos: position{line: 153, col: 21, offset: 3570},
+	name: "NL",
 },
 },
-{
-	name: "FILTER_VALUE",
-	pos: position{line: 127, col: 1, offset: 2629},
-	expr: &actionExpr{
-	pos: position{line: 127, col: 17, offset: 2645},
-	run: (*parser).callonFILTER_VALUE1,
-	expr: &labeledExpr{
-	pos: position{line: 127, col: 17, offset: 2645},
-	label: "fv",
-	expr: &choiceExpr{
-	pos: position{line: 127, col: 21, offset: 2649},
-	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 127, col: 21, offset: 2649},
-	name: "IDENT_WITH_DOT",
+	pos: position{line: 153, col: 25, offset: 3574},
+	name: "WS",
 },
 &litMatcher{
-	pos: position{line: 127, col: 38, offset: 2666},
-	val: "*",
+	pos: position{line: 153, col: 28, offset: 3577},
+	val: "}",
 	ignoreCase: false,
 },
 	},
 },
 },
 },
-},
 {
-	name: "MATCHES_FN",
-	pos: position{line: 131, col: 1, offset: 2703},
+	name: "POPULATED_OBJ",
+	pos: position{line: 157, col: 1, offset: 3611},
 	expr: &actionExpr{
-	pos: position{line: 131, col: 15, offset: 2717},
-	run: (*parser).callonMATCHES_FN1,
+	pos: position{line: 157, col: 18, offset: 3628},
+	run: (*parser).callonPOPULATED_OBJ1,
 	expr: &seqExpr{
-	pos: position{line: 131, col: 15, offset: 2717},
+	pos: position{line: 157, col: 18, offset: 3628},
 	exprs: []interface{}{
-&ruleRefExpr{
-	pos: position{line: 131, col: 15, offset: 2717},
-	name: "WS",
-},
 &litMatcher{
-	pos: position{line: 131, col: 18, offset: 2720},
-	val: "->",
+	pos: position{line: 157, col: 18, offset: 3628},
+	val: "{",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 131, col: 23, offset: 2725},
+	pos: position{line: 157, col: 22, offset: 3632},
 	name: "WS",
 },
-&litMatcher{
-	pos: position{line: 131, col: 26, offset: 2728},
-	val: "matches",
-	ignoreCase: false,
-},
-&litMatcher{
-	pos: position{line: 131, col: 36, offset: 2738},
-	val: "(",
-	ignoreCase: false,
+&zeroOrMoreExpr{
+	pos: position{line: 157, col: 25, offset: 3635},
+	expr: &ruleRefExpr{
+	pos: position{line: 157, col: 25, offset: 3635},
+	name: "NL",
 },
-&labeledExpr{
-	pos: position{line: 131, col: 40, offset: 2742},
-	label: "arg",
-	expr: &choiceExpr{
-	pos: position{line: 131, col: 45, offset: 2747},
-	alternatives: []interface{}{
-&ruleRefExpr{
-	pos: position{line: 131, col: 45, offset: 2747},
-	name: "VARIABLE",
 },
 &ruleRefExpr{
-	pos: position{line: 131, col: 56, offset: 2758},
-	name: "String",
-},
-	},
-},
-},
-&litMatcher{
-	pos: position{line: 131, col: 64, offset: 2766},
-	val: ")",
-	ignoreCase: false,
-},
-	},
+	pos: position{line: 157, col: 29, offset: 3639},
+	name: "WS",
 },
+&labeledExpr{
+	pos: position{line: 157, col: 32, offset: 3642},
+	label: "oe",
+	expr: &ruleRefExpr{
+	pos: position{line: 157, col: 36, offset: 3646},
+	name: "OBJ_ENTRY",
 },
 },
-{
-	name: "HEADERS",
-	pos: position{line: 135, col: 1, offset: 2792},
-	expr: &actionExpr{
-	pos: position{line: 135, col: 12, offset: 2803},
-	run: (*parser).callonHEADERS1,
+&labeledExpr{
+	pos: position{line: 157, col: 47, offset: 3657},
+	label: "oes",
+	expr: &zeroOrMoreExpr{
+	pos: position{line: 157, col: 51, offset: 3661},
 	expr: &seqExpr{
-	pos: position{line: 135, col: 12, offset: 2803},
+	pos: position{line: 157, col: 52, offset: 3662},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 135, col: 12, offset: 2803},
-	name: "WS_MAND",
+	pos: position{line: 157, col: 52, offset: 3662},
+	name: "WS",
 },
 &litMatcher{
-	pos: position{line: 135, col: 20, offset: 2811},
-	val: "headers",
+	pos: position{line: 157, col: 55, offset: 3665},
+	val: ",",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 135, col: 30, offset: 2821},
-	name: "WS_MAND",
+	pos: position{line: 157, col: 59, offset: 3669},
+	name: "WS",
 },
-&labeledExpr{
-	pos: position{line: 135, col: 38, offset: 2829},
-	label: "h",
+&zeroOrMoreExpr{
+	pos: position{line: 157, col: 62, offset: 3672},
 	expr: &ruleRefExpr{
-	pos: position{line: 135, col: 41, offset: 2832},
-	name: "HEADER",
+	pos: position{line: 157, col: 62, offset: 3672},
+	name: "NL",
 },
 },
-&labeledExpr{
-	pos: position{line: 135, col: 49, offset: 2840},
-	label: "hs",
-	expr: &zeroOrMoreExpr{
-	pos: position{line: 135, col: 52, offset: 2843},
-	expr: &seqExpr{
-	pos: position{line: 135, col: 53, offset: 2844},
-	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 135, col: 53, offset: 2844},
+	pos: position{line: 157, col: 66, offset: 3676},
 	name: "WS",
 },
 &ruleRefExpr{
-	pos: position{line: 135, col: 56, offset: 2847},
-	name: "LS",
+	pos: position{line: 157, col: 69, offset: 3679},
+	name: "OBJ_ENTRY",
+},
+	},
+},
+},
 },
 &ruleRefExpr{
-	pos: position{line: 135, col: 59, offset: 2850},
+	pos: position{line: 157, col: 81, offset: 3691},
 	name: "WS",
 },
-&ruleRefExpr{
-	pos: position{line: 135, col: 62, offset: 2853},
-	name: "HEADER",
+&zeroOrMoreExpr{
+	pos: position{line: 157, col: 84, offset: 3694},
+	expr: &ruleRefExpr{
+	pos: position{line: 157, col: 84, offset: 3694},
+	name: "NL",
 },
-	},
 },
+&ruleRefExpr{
+	pos: position{line: 157, col: 88, offset: 3698},
+	name: "WS",
 },
+&litMatcher{
+	pos: position{line: 157, col: 91, offset: 3701},
+	val: "}",
+	ignoreCase: false,
 },
 	},
 },
 },
 },
 {
-	name: "HEADER",
-	pos: position{line: 139, col: 1, offset: 2893},
+	name: "OBJ_ENTRY",
+	pos: position{line: 161, col: 1, offset: 3746},
 	expr: &actionExpr{
-	pos: position{line: 139, col: 11, offset: 2903},
-	run: (*parser).callonHEADER1,
+	pos: position{line: 161, col: 14, offset: 3759},
+	run: (*parser).callonOBJ_ENTRY1,
 	expr: &seqExpr{
-	pos: position{line: 139, col: 11, offset: 2903},
+	pos: position{line: 161, col: 14, offset: 3759},
 	exprs: []interface{}{
 &labeledExpr{
-	pos: position{line: 139, col: 11, offset: 2903},
-	label: "n",
-	expr: &ruleRefExpr{
-	pos: position{line: 139, col: 14, offset: 2906},
-	name: "IDENT",
+	pos: position{line: 161, col: 14, offset: 3759},
+	label: "k",
+	expr: &choiceExpr{
+	pos: position{line: 161, col: 17, offset: 3762},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 161, col: 17, offset: 3762},
+	name: "String",
+},
+&ruleRefExpr{
+	pos: position{line: 161, col: 26, offset: 3771},
+	name: "IDENT_WITHOUT_COLLON",
+},
+	},
 },
 },
 &ruleRefExpr{
-	pos: position{line: 139, col: 21, offset: 2913},
+	pos: position{line: 161, col: 48, offset: 3793},
 	name: "WS",
 },
 &litMatcher{
-	pos: position{line: 139, col: 24, offset: 2916},
-	val: "=",
+	pos: position{line: 161, col: 51, offset: 3796},
+	val: ":",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 139, col: 28, offset: 2920},
+	pos: position{line: 161, col: 55, offset: 3800},
 	name: "WS",
 },
 &labeledExpr{
-	pos: position{line: 139, col: 31, offset: 2923},
+	pos: position{line: 161, col: 58, offset: 3803},
 	label: "v",
+	expr: &ruleRefExpr{
+	pos: position{line: 161, col: 61, offset: 3806},
+	name: "VALUE",
+},
+},
+	},
+},
+},
+},
+{
+	name: "PRIMITIVE",
+	pos: position{line: 165, col: 1, offset: 3847},
+	expr: &actionExpr{
+	pos: position{line: 165, col: 14, offset: 3860},
+	run: (*parser).callonPRIMITIVE1,
+	expr: &labeledExpr{
+	pos: position{line: 165, col: 14, offset: 3860},
+	label: "p",
 	expr: &choiceExpr{
-	pos: position{line: 139, col: 34, offset: 2926},
+	pos: position{line: 165, col: 17, offset: 3863},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 139, col: 34, offset: 2926},
-	name: "VARIABLE",
+	pos: position{line: 165, col: 17, offset: 3863},
+	name: "Null",
 },
 &ruleRefExpr{
-	pos: position{line: 139, col: 45, offset: 2937},
-	name: "CHAIN",
+	pos: position{line: 165, col: 24, offset: 3870},
+	name: "Boolean",
 },
 &ruleRefExpr{
-	pos: position{line: 139, col: 53, offset: 2945},
+	pos: position{line: 165, col: 34, offset: 3880},
 	name: "String",
 },
-	},
+&ruleRefExpr{
+	pos: position{line: 165, col: 43, offset: 3889},
+	name: "Float",
+},
+&ruleRefExpr{
+	pos: position{line: 165, col: 51, offset: 3897},
+	name: "Integer",
 },
+&ruleRefExpr{
+	pos: position{line: 165, col: 61, offset: 3907},
+	name: "CHAIN",
 },
 	},
 },
 },
 },
+},
 {
-	name: "HIDDEN_RULE",
-	pos: position{line: 143, col: 1, offset: 2982},
+	name: "ONLY_RULE",
+	pos: position{line: 171, col: 1, offset: 3945},
 	expr: &actionExpr{
-	pos: position{line: 143, col: 16, offset: 2997},
-	run: (*parser).callonHIDDEN_RULE1,
+	pos: position{line: 171, col: 14, offset: 3958},
+	run: (*parser).callonONLY_RULE1,
 	expr: &seqExpr{
-	pos: position{line: 143, col: 16, offset: 2997},
+	pos: position{line: 171, col: 14, offset: 3958},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 143, col: 16, offset: 2997},
+	pos: position{line: 171, col: 14, offset: 3958},
 	name: "WS_MAND",
 },
 &litMatcher{
-	pos: position{line: 143, col: 24, offset: 3005},
-	val: "hidden",
+	pos: position{line: 171, col: 22, offset: 3966},
+	val: "only",
 	ignoreCase: false,
 },
-	},
+&ruleRefExpr{
+	pos: position{line: 171, col: 29, offset: 3973},
+	name: "WS_MAND",
 },
+&labeledExpr{
+	pos: position{line: 171, col: 37, offset: 3981},
+	label: "f",
+	expr: &ruleRefExpr{
+	pos: position{line: 171, col: 40, offset: 3984},
+	name: "FILTER",
 },
 },
-{
-	name: "TIMEOUT",
-	pos: position{line: 147, col: 1, offset: 3039},
-	expr: &actionExpr{
-	pos: position{line: 147, col: 12, offset: 3050},
-	run: (*parser).callonTIMEOUT1,
+&labeledExpr{
+	pos: position{line: 171, col: 48, offset: 3992},
+	label: "fs",
+	expr: &zeroOrMoreExpr{
+	pos: position{line: 171, col: 51, offset: 3995},
 	expr: &seqExpr{
-	pos: position{line: 147, col: 12, offset: 3050},
+	pos: position{line: 171, col: 52, offset: 3996},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 147, col: 12, offset: 3050},
-	name: "WS_MAND",
-},
-&litMatcher{
-	pos: position{line: 147, col: 20, offset: 3058},
-	val: "timeout",
-	ignoreCase: false,
-},
-&ruleRefExpr{
-	pos: position{line: 147, col: 30, offset: 3068},
-	name: "WS_MAND",
+	pos: position{line: 171, col: 52, offset: 3996},
+	name: "WS",
 },
-&labeledExpr{
-	pos: position{line: 147, col: 38, offset: 3076},
-	label: "t",
+&notExpr{
+	pos: position{line: 171, col: 55, offset: 3999},
 	expr: &choiceExpr{
-	pos: position{line: 147, col: 41, offset: 3079},
+	pos: position{line: 171, col: 57, offset: 4001},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 147, col: 41, offset: 3079},
-	name: "VARIABLE",
+	pos: position{line: 171, col: 57, offset: 4001},
+	name: "FLAGS_RULE",
 },
 &ruleRefExpr{
-	pos: position{line: 147, col: 52, offset: 3090},
-	name: "Integer",
+	pos: position{line: 171, col: 70, offset: 4014},
+	name: "COMPUTE_RULE",
 },
-	},
+&seqExpr{
+	pos: position{line: 171, col: 85, offset: 4029},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 171, col: 85, offset: 4029},
+	name: "BS",
 },
+&ruleRefExpr{
+	pos: position{line: 171, col: 88, offset: 4032},
+	name: "BLOCK",
 },
 	},
 },
+	},
 },
 },
-{
-	name: "MAX_AGE",
-	pos: position{line: 151, col: 1, offset: 3126},
-	expr: &actionExpr{
-	pos: position{line: 151, col: 12, offset: 3137},
-	run: (*parser).callonMAX_AGE1,
-	expr: &seqExpr{
-	pos: position{line: 151, col: 12, offset: 3137},
+&choiceExpr{
+	pos: position{line: 171, col: 96, offset: 4040},
+	alternatives: []interface{}{
+&seqExpr{
+	pos: position{line: 171, col: 96, offset: 4040},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 151, col: 12, offset: 3137},
-	name: "WS_MAND",
-},
-&litMatcher{
-	pos: position{line: 151, col: 20, offset: 3145},
-	val: "max-age",
-	ignoreCase: false,
+	pos: position{line: 171, col: 96, offset: 4040},
+	name: "LS",
 },
+&zeroOrMoreExpr{
+	pos: position{line: 171, col: 99, offset: 4043},
+	expr: &seqExpr{
+	pos: position{line: 171, col: 100, offset: 4044},
+	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 151, col: 30, offset: 3155},
-	name: "WS_MAND",
+	pos: position{line: 171, col: 100, offset: 4044},
+	name: "WS",
 },
-&labeledExpr{
-	pos: position{line: 151, col: 38, offset: 3163},
-	label: "t",
-	expr: &choiceExpr{
-	pos: position{line: 151, col: 41, offset: 3166},
-	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 151, col: 41, offset: 3166},
-	name: "VARIABLE",
+	pos: position{line: 171, col: 103, offset: 4047},
+	name: "NL",
 },
 &ruleRefExpr{
-	pos: position{line: 151, col: 52, offset: 3177},
-	name: "Integer",
+	pos: position{line: 171, col: 106, offset: 4050},
+	name: "WS",
 },
 	},
 },
 },
 	},
 },
-},
-},
-{
-	name: "S_MAX_AGE",
-	pos: position{line: 155, col: 1, offset: 3212},
-	expr: &actionExpr{
-	pos: position{line: 155, col: 14, offset: 3225},
-	run: (*parser).callonS_MAX_AGE1,
-	expr: &seqExpr{
-	pos: position{line: 155, col: 14, offset: 3225},
-	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 155, col: 14, offset: 3225},
-	name: "WS_MAND",
-},
-&litMatcher{
-	pos: position{line: 155, col: 22, offset: 3233},
-	val: "s-max-age",
-	ignoreCase: false,
+	pos: position{line: 171, col: 113, offset: 4057},
+	name: "LS",
 },
-&ruleRefExpr{
-	pos: position{line: 155, col: 34, offset: 3245},
-	name: "WS_MAND",
+	},
 },
-&labeledExpr{
-	pos: position{line: 155, col: 42, offset: 3253},
-	label: "t",
-	expr: &choiceExpr{
-	pos: position{line: 155, col: 45, offset: 3256},
-	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 155, col: 45, offset: 3256},
-	name: "VARIABLE",
+	pos: position{line: 171, col: 117, offset: 4061},
+	name: "WS",
 },
 &ruleRefExpr{
-	pos: position{line: 155, col: 56, offset: 3267},
-	name: "Integer",
+	pos: position{line: 171, col: 120, offset: 4064},
+	name: "FILTER",
 },
 	},
 },
+},
 },
 	},
 },
 },
 },
 {
-	name: "FLAGS_RULE",
-	pos: position{line: 159, col: 1, offset: 3303},
+	name: "FILTER",
+	pos: position{line: 175, col: 1, offset: 4101},
 	expr: &actionExpr{
-	pos: position{line: 159, col: 15, offset: 3317},
-	run: (*parser).callonFLAGS_RULE1,
+	pos: position{line: 175, col: 11, offset: 4111},
+	run: (*parser).callonFILTER1,
 	expr: &seqExpr{
-	pos: position{line: 159, col: 15, offset: 3317},
+	pos: position{line: 175, col: 11, offset: 4111},
 	exprs: []interface{}{
-&ruleRefExpr{
-	pos: position{line: 159, col: 15, offset: 3317},
-	name: "WS_MAND",
-},
 &labeledExpr{
-	pos: position{line: 159, col: 23, offset: 3325},
-	label: "i",
+	pos: position{line: 175, col: 11, offset: 4111},
+	label: "f",
 	expr: &ruleRefExpr{
-	pos: position{line: 159, col: 25, offset: 3327},
-	name: "IGNORE_FLAG",
+	pos: position{line: 175, col: 14, offset: 4114},
+	name: "FILTER_VALUE",
 },
 },
 &labeledExpr{
-	pos: position{line: 159, col: 37, offset: 3339},
-	label: "is",
-	expr: &zeroOrMoreExpr{
-	pos: position{line: 159, col: 40, offset: 3342},
-	expr: &seqExpr{
-	pos: position{line: 159, col: 41, offset: 3343},
-	exprs: []interface{}{
-&ruleRefExpr{
-	pos: position{line: 159, col: 41, offset: 3343},
-	name: "WS",
-},
-&ruleRefExpr{
-	pos: position{line: 159, col: 44, offset: 3346},
-	name: "LS",
+	pos: position{line: 175, col: 28, offset: 4128},
+	label: "fn",
+	expr: &zeroOrOneExpr{
+	pos: position{line: 175, col: 32, offset: 4132},
+	expr: &ruleRefExpr{
+	pos: position{line: 175, col: 32, offset: 4132},
+	name: "MATCHES_FN",
 },
-&ruleRefExpr{
-	pos: position{line: 159, col: 47, offset: 3349},
-	name: "WS",
 },
-&ruleRefExpr{
-	pos: position{line: 159, col: 50, offset: 3352},
-	name: "IGNORE_FLAG",
 },
-	},
+&labeledExpr{
+	pos: position{line: 175, col: 45, offset: 4145},
+	label: "fns",
+	expr: &zeroOrMoreExpr{
+	pos: position{line: 175, col: 49, offset: 4149},
+	expr: &ruleRefExpr{
+	pos: position{line: 175, col: 50, offset: 4150},
+	name: "FILTER_FN",
 },
 },
 },
@@ -1801,53 +2227,1502 @@ var g = &grammar {
 },
 },
 {
-	name: "IGNORE_FLAG",
-	pos: position{line: 163, col: 1, offset: 3395},
+	name: "FILTER_VALUE",
+	pos: position{line: 179, col: 1, offset: 4197},
 	expr: &actionExpr{
-	pos: position{line: 163, col: 16, offset: 3410},
-	run: (*parser).callonIGNORE_FLAG1,
-	expr: &litMatcher{
-	pos: position{line: 163, col: 16, offset: 3410},
-	val: "ignore-errors",
-	ignoreCase: false,
-},
-},
+	pos: position{line: 179, col: 17, offset: 4213},
+	run: (*parser).callonFILTER_VALUE1,
+	expr: &labeledExpr{
+	pos: position{line: 179, col: 17, offset: 4213},
+	label: "fv",
+	expr: &choiceExpr{
+	pos: position{line: 179, col: 21, offset: 4217},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 179, col: 21, offset: 4217},
+	name: "FILTER_PATH",
+},
+&litMatcher{
+	pos: position{line: 179, col: 35, offset: 4231},
+	val: "*",
+	ignoreCase: false,
+},
+	},
+},
+},
+},
+},
+{
+	name: "FILTER_PATH",
+	pos: position{line: 183, col: 1, offset: 4268},
+	expr: &actionExpr{
+	pos: position{line: 183, col: 16, offset: 4283},
+	run: (*parser).callonFILTER_PATH1,
+	expr: &oneOrMoreExpr{
+	pos: position{line: 183, col: 16, offset: 4283},
+	expr: &choiceExpr{
+	pos: position{line: 183, col: 17, offset: 4284},
+	alternatives: []interface{}{
+&charClassMatcher{
+	pos: position{line: 183, col: 17, offset: 4284},
+	val: "[a-zA-Z0-9-:_.]",
+	chars: []rune{'-',':','_','.',},
+	ranges: []rune{'a','z','A','Z','0','9',},
+	ignoreCase: false,
+	inverted: false,
+},
+&seqExpr{
+	pos: position{line: 183, col: 35, offset: 4302},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 183, col: 35, offset: 4302},
+	val: "[",
+	ignoreCase: false,
+},
+&zeroOrMoreExpr{
+	pos: position{line: 183, col: 39, offset: 4306},
+	expr: &charClassMatcher{
+	pos: position{line: 183, col: 39, offset: 4306},
+	val: "[0-9:-]",
+	chars: []rune{':','-',},
+	ranges: []rune{'0','9',},
+	ignoreCase: false,
+	inverted: false,
+},
+},
+&litMatcher{
+	pos: position{line: 183, col: 48, offset: 4315},
+	val: "]",
+	ignoreCase: false,
+},
+	},
+},
+	},
+},
+},
+},
+},
+{
+	name: "MATCHES_FN",
+	pos: position{line: 187, col: 1, offset: 4352},
+	expr: &actionExpr{
+	pos: position{line: 187, col: 15, offset: 4366},
+	run: (*parser).callonMATCHES_FN1,
+	expr: &seqExpr{
+	pos: position{line: 187, col: 15, offset: 4366},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 187, col: 15, offset: 4366},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 187, col: 18, offset: 4369},
+	val: "->",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 187, col: 23, offset: 4374},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 187, col: 26, offset: 4377},
+	val: "matches",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 187, col: 36, offset: 4387},
+	val: "(",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 187, col: 40, offset: 4391},
+	name: "WS",
+},
+&labeledExpr{
+	pos: position{line: 187, col: 43, offset: 4394},
+	label: "arg",
+	expr: &choiceExpr{
+	pos: position{line: 187, col: 48, offset: 4399},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 187, col: 48, offset: 4399},
+	name: "VARIABLE",
+},
+&ruleRefExpr{
+	pos: position{line: 187, col: 59, offset: 4410},
+	name: "String",
+},
+	},
+},
+},
+&labeledExpr{
+	pos: position{line: 187, col: 67, offset: 4418},
+	label: "flags",
+	expr: &zeroOrOneExpr{
+	pos: position{line: 187, col: 74, offset: 4425},
+	expr: &ruleRefExpr{
+	pos: position{line: 187, col: 74, offset: 4425},
+	name: "MATCH_FLAGS",
+},
+},
+},
+&ruleRefExpr{
+	pos: position{line: 187, col: 88, offset: 4439},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 187, col: 91, offset: 4442},
+	val: ")",
+	ignoreCase: false,
+},
+	},
+},
+},
+},
+{
+	name: "MATCH_FLAGS",
+	pos: position{line: 191, col: 1, offset: 4480},
+	expr: &actionExpr{
+	pos: position{line: 191, col: 16, offset: 4495},
+	run: (*parser).callonMATCH_FLAGS1,
+	expr: &seqExpr{
+	pos: position{line: 191, col: 16, offset: 4495},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 191, col: 16, offset: 4495},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 191, col: 19, offset: 4498},
+	val: ",",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 191, col: 23, offset: 4502},
+	name: "WS",
+},
+&labeledExpr{
+	pos: position{line: 191, col: 26, offset: 4505},
+	label: "f",
+	expr: &ruleRefExpr{
+	pos: position{line: 191, col: 28, offset: 4507},
+	name: "String",
+},
+},
+	},
+},
+},
+},
+{
+	name: "FILTER_FN",
+	pos: position{line: 195, col: 1, offset: 4534},
+	expr: &actionExpr{
+	pos: position{line: 195, col: 14, offset: 4547},
+	run: (*parser).callonFILTER_FN1,
+	expr: &seqExpr{
+	pos: position{line: 195, col: 14, offset: 4547},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 195, col: 14, offset: 4547},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 195, col: 17, offset: 4550},
+	val: "->",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 195, col: 22, offset: 4555},
+	name: "WS",
+},
+&labeledExpr{
+	pos: position{line: 195, col: 25, offset: 4558},
+	label: "fn",
+	expr: &choiceExpr{
+	pos: position{line: 195, col: 29, offset: 4562},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 195, col: 29, offset: 4562},
+	name: "FILTER_BY_KEYS_FN",
+},
+&ruleRefExpr{
+	pos: position{line: 195, col: 49, offset: 4582},
+	name: "RENAME_AS_FN",
+},
+&ruleRefExpr{
+	pos: position{line: 195, col: 64, offset: 4597},
+	name: "FIRST_FN",
+},
+&ruleRefExpr{
+	pos: position{line: 195, col: 75, offset: 4608},
+	name: "COMPARE_FN",
+},
+	},
+},
+},
+	},
+},
+},
+},
+{
+	name: "FILTER_BY_KEYS_FN",
+	pos: position{line: 199, col: 1, offset: 4641},
+	expr: &actionExpr{
+	pos: position{line: 199, col: 22, offset: 4662},
+	run: (*parser).callonFILTER_BY_KEYS_FN1,
+	expr: &seqExpr{
+	pos: position{line: 199, col: 22, offset: 4662},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 199, col: 22, offset: 4662},
+	val: "filterByKeys",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 199, col: 37, offset: 4677},
+	val: "(",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 199, col: 41, offset: 4681},
+	name: "WS",
+},
+&labeledExpr{
+	pos: position{line: 199, col: 44, offset: 4684},
+	label: "k",
+	expr: &choiceExpr{
+	pos: position{line: 199, col: 47, offset: 4687},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 199, col: 47, offset: 4687},
+	name: "VARIABLE",
+},
+&ruleRefExpr{
+	pos: position{line: 199, col: 58, offset: 4698},
+	name: "KEYS_LIST",
+},
+	},
+},
+},
+&ruleRefExpr{
+	pos: position{line: 199, col: 69, offset: 4709},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 199, col: 72, offset: 4712},
+	val: ")",
+	ignoreCase: false,
+},
+	},
+},
+},
+},
+{
+	name: "KEYS_LIST",
+	pos: position{line: 203, col: 1, offset: 4748},
+	expr: &actionExpr{
+	pos: position{line: 203, col: 14, offset: 4761},
+	run: (*parser).callonKEYS_LIST1,
+	expr: &seqExpr{
+	pos: position{line: 203, col: 14, offset: 4761},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 203, col: 14, offset: 4761},
+	val: "[",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 203, col: 18, offset: 4765},
+	name: "WS",
+},
+&labeledExpr{
+	pos: position{line: 203, col: 21, offset: 4768},
+	label: "ks",
+	expr: &zeroOrOneExpr{
+	pos: position{line: 203, col: 24, offset: 4771},
+	expr: &seqExpr{
+	pos: position{line: 203, col: 25, offset: 4772},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 203, col: 25, offset: 4772},
+	name: "String",
+},
+&zeroOrMoreExpr{
+	pos: position{line: 203, col: 32, offset: 4779},
+	expr: &seqExpr{
+	pos: position{line: 203, col: 33, offset: 4780},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 203, col: 33, offset: 4780},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 203, col: 36, offset: 4783},
+	val: ",",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 203, col: 40, offset: 4787},
+	name: "WS",
+},
+&ruleRefExpr{
+	pos: position{line: 203, col: 43, offset: 4790},
+	name: "String",
+},
+	},
+},
+},
+	},
+},
+},
+},
+&ruleRefExpr{
+	pos: position{line: 203, col: 54, offset: 4801},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 203, col: 57, offset: 4804},
+	val: "]",
+	ignoreCase: false,
+},
+	},
+},
+},
+},
+{
+	name: "RENAME_AS_FN",
+	pos: position{line: 207, col: 1, offset: 4837},
+	expr: &actionExpr{
+	pos: position{line: 207, col: 17, offset: 4853},
+	run: (*parser).callonRENAME_AS_FN1,
+	expr: &seqExpr{
+	pos: position{line: 207, col: 17, offset: 4853},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 207, col: 17, offset: 4853},
+	val: "renameAs",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 207, col: 28, offset: 4864},
+	val: "(",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 207, col: 32, offset: 4868},
+	name: "WS",
+},
+&labeledExpr{
+	pos: position{line: 207, col: 35, offset: 4871},
+	label: "n",
+	expr: &ruleRefExpr{
+	pos: position{line: 207, col: 37, offset: 4873},
+	name: "String",
+},
+},
+&ruleRefExpr{
+	pos: position{line: 207, col: 44, offset: 4880},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 207, col: 47, offset: 4883},
+	val: ")",
+	ignoreCase: false,
+},
+	},
+},
+},
+},
+{
+	name: "FIRST_FN",
+	pos: position{line: 211, col: 1, offset: 4915},
+	expr: &actionExpr{
+	pos: position{line: 211, col: 13, offset: 4927},
+	run: (*parser).callonFIRST_FN1,
+	expr: &litMatcher{
+	pos: position{line: 211, col: 13, offset: 4927},
+	val: "first",
+	ignoreCase: false,
+},
+},
+},
+{
+	name: "COMPARE_FN",
+	pos: position{line: 215, col: 1, offset: 4959},
+	expr: &actionExpr{
+	pos: position{line: 215, col: 15, offset: 4973},
+	run: (*parser).callonCOMPARE_FN1,
+	expr: &seqExpr{
+	pos: position{line: 215, col: 15, offset: 4973},
+	exprs: []interface{}{
+&labeledExpr{
+	pos: position{line: 215, col: 15, offset: 4973},
+	label: "op",
+	expr: &ruleRefExpr{
+	pos: position{line: 215, col: 19, offset: 4977},
+	name: "COMPARE_OPERATOR",
+},
+},
+&litMatcher{
+	pos: position{line: 215, col: 37, offset: 4995},
+	val: "(",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 215, col: 41, offset: 4999},
+	name: "WS",
+},
+&labeledExpr{
+	pos: position{line: 215, col: 44, offset: 5002},
+	label: "arg",
+	expr: &choiceExpr{
+	pos: position{line: 215, col: 49, offset: 5007},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 215, col: 49, offset: 5007},
+	name: "VARIABLE",
+},
+&ruleRefExpr{
+	pos: position{line: 215, col: 60, offset: 5018},
+	name: "PRIMITIVE",
+},
+	},
+},
+},
+&ruleRefExpr{
+	pos: position{line: 215, col: 71, offset: 5029},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 215, col: 74, offset: 5032},
+	val: ")",
+	ignoreCase: false,
+},
+	},
+},
+},
+},
+{
+	name: "COMPARE_OPERATOR",
+	pos: position{line: 219, col: 1, offset: 5069},
+	expr: &actionExpr{
+	pos: position{line: 219, col: 21, offset: 5089},
+	run: (*parser).callonCOMPARE_OPERATOR1,
+	expr: &choiceExpr{
+	pos: position{line: 219, col: 22, offset: 5090},
+	alternatives: []interface{}{
+&litMatcher{
+	pos: position{line: 219, col: 22, offset: 5090},
+	val: "equals",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 219, col: 33, offset: 5101},
+	val: "greaterThan",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 219, col: 49, offset: 5117},
+	val: "lessThan",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 219, col: 62, offset: 5130},
+	val: "after",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 219, col: 72, offset: 5140},
+	val: "before",
+	ignoreCase: false,
+},
+	},
+},
+},
+},
+{
+	name: "COMPUTE_RULE",
+	pos: position{line: 223, col: 1, offset: 5181},
+	expr: &actionExpr{
+	pos: position{line: 223, col: 17, offset: 5197},
+	run: (*parser).callonCOMPUTE_RULE1,
+	expr: &seqExpr{
+	pos: position{line: 223, col: 17, offset: 5197},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 223, col: 17, offset: 5197},
+	name: "WS_MAND",
+},
+&litMatcher{
+	pos: position{line: 223, col: 25, offset: 5205},
+	val: "compute",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 223, col: 35, offset: 5215},
+	name: "WS_MAND",
+},
+&labeledExpr{
+	pos: position{line: 223, col: 43, offset: 5223},
+	label: "f",
+	expr: &ruleRefExpr{
+	pos: position{line: 223, col: 46, offset: 5226},
+	name: "COMPUTED_FIELD",
+},
+},
+&labeledExpr{
+	pos: position{line: 223, col: 62, offset: 5242},
+	label: "fs",
+	expr: &zeroOrMoreExpr{
+	pos: position{line: 223, col: 65, offset: 5245},
+	expr: &seqExpr{
+	pos: position{line: 223, col: 66, offset: 5246},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 223, col: 66, offset: 5246},
+	name: "WS",
+},
+&notExpr{
+	pos: position{line: 223, col: 69, offset: 5249},
+	expr: &choiceExpr{
+	pos: position{line: 223, col: 71, offset: 5251},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 223, col: 71, offset: 5251},
+	name: "FLAGS_RULE",
+},
+&seqExpr{
+	pos: position{line: 223, col: 84, offset: 5264},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 223, col: 84, offset: 5264},
+	name: "BS",
+},
+&ruleRefExpr{
+	pos: position{line: 223, col: 87, offset: 5267},
+	name: "BLOCK",
+},
+	},
+},
+	},
+},
+},
+&choiceExpr{
+	pos: position{line: 223, col: 95, offset: 5275},
+	alternatives: []interface{}{
+&seqExpr{
+	pos: position{line: 223, col: 95, offset: 5275},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 223, col: 95, offset: 5275},
+	name: "LS",
+},
+&zeroOrMoreExpr{
+	pos: position{line: 223, col: 98, offset: 5278},
+	expr: &seqExpr{
+	pos: position{line: 223, col: 99, offset: 5279},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 223, col: 99, offset: 5279},
+	name: "WS",
+},
+&ruleRefExpr{
+	pos: position{line: 223, col: 102, offset: 5282},
+	name: "NL",
+},
+&ruleRefExpr{
+	pos: position{line: 223, col: 105, offset: 5285},
+	name: "WS",
+},
+	},
+},
+},
+	},
+},
+&ruleRefExpr{
+	pos: position{line: 223, col: 112, offset: 5292},
+	name: "LS",
+},
+	},
+},
+&ruleRefExpr{
+	pos: position{line: 223, col: 116, offset: 5296},
+	name: "WS",
+},
+&ruleRefExpr{
+	pos: position{line: 223, col: 119, offset: 5299},
+	name: "COMPUTED_FIELD",
+},
+	},
+},
+},
+},
+	},
+},
+},
+},
+{
+	name: "COMPUTED_FIELD",
+	pos: position{line: 227, col: 1, offset: 5347},
+	expr: &actionExpr{
+	pos: position{line: 227, col: 19, offset: 5365},
+	run: (*parser).callonCOMPUTED_FIELD1,
+	expr: &seqExpr{
+	pos: position{line: 227, col: 19, offset: 5365},
+	exprs: []interface{}{
+&labeledExpr{
+	pos: position{line: 227, col: 19, offset: 5365},
+	label: "n",
+	expr: &ruleRefExpr{
+	pos: position{line: 227, col: 22, offset: 5368},
+	name: "IDENT",
+},
+},
+&ruleRefExpr{
+	pos: position{line: 227, col: 29, offset: 5375},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 227, col: 32, offset: 5378},
+	val: "=",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 227, col: 36, offset: 5382},
+	name: "WS",
+},
+&labeledExpr{
+	pos: position{line: 227, col: 39, offset: 5385},
+	label: "p",
+	expr: &ruleRefExpr{
+	pos: position{line: 227, col: 42, offset: 5388},
+	name: "IDENT_WITH_DOT",
+},
+},
+&labeledExpr{
+	pos: position{line: 227, col: 58, offset: 5404},
+	label: "a",
+	expr: &zeroOrOneExpr{
+	pos: position{line: 227, col: 61, offset: 5407},
+	expr: &ruleRefExpr{
+	pos: position{line: 227, col: 61, offset: 5407},
+	name: "AGGREGATOR_FN",
+},
+},
+},
+&labeledExpr{
+	pos: position{line: 227, col: 77, offset: 5423},
+	label: "e",
+	expr: &zeroOrOneExpr{
+	pos: position{line: 227, col: 80, offset: 5426},
+	expr: &ruleRefExpr{
+	pos: position{line: 227, col: 80, offset: 5426},
+	name: "EXPR_FN",
+},
+},
+},
+	},
+},
+},
+},
+{
+	name: "AGGREGATOR_FN",
+	pos: position{line: 231, col: 1, offset: 5478},
+	expr: &actionExpr{
+	pos: position{line: 231, col: 18, offset: 5495},
+	run: (*parser).callonAGGREGATOR_FN1,
+	expr: &seqExpr{
+	pos: position{line: 231, col: 18, offset: 5495},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 231, col: 18, offset: 5495},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 231, col: 21, offset: 5498},
+	val: "->",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 231, col: 26, offset: 5503},
+	name: "WS",
+},
+&labeledExpr{
+	pos: position{line: 231, col: 29, offset: 5506},
+	label: "a",
+	expr: &choiceExpr{
+	pos: position{line: 231, col: 32, offset: 5509},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 231, col: 32, offset: 5509},
+	name: "CONCAT_FN",
+},
+&ruleRefExpr{
+	pos: position{line: 231, col: 44, offset: 5521},
+	name: "AGGREGATOR",
+},
+	},
+},
+},
+	},
+},
+},
+},
+{
+	name: "CONCAT_FN",
+	pos: position{line: 235, col: 1, offset: 5553},
+	expr: &actionExpr{
+	pos: position{line: 235, col: 14, offset: 5566},
+	run: (*parser).callonCONCAT_FN1,
+	expr: &seqExpr{
+	pos: position{line: 235, col: 14, offset: 5566},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 235, col: 14, offset: 5566},
+	val: "concat",
+	ignoreCase: false,
+},
+&labeledExpr{
+	pos: position{line: 235, col: 23, offset: 5575},
+	label: "s",
+	expr: &zeroOrOneExpr{
+	pos: position{line: 235, col: 26, offset: 5578},
+	expr: &ruleRefExpr{
+	pos: position{line: 235, col: 26, offset: 5578},
+	name: "CONCAT_SEPARATOR",
+},
+},
+},
+	},
+},
+},
+},
+{
+	name: "CONCAT_SEPARATOR",
+	pos: position{line: 239, col: 1, offset: 5637},
+	expr: &actionExpr{
+	pos: position{line: 239, col: 21, offset: 5657},
+	run: (*parser).callonCONCAT_SEPARATOR1,
+	expr: &seqExpr{
+	pos: position{line: 239, col: 21, offset: 5657},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 239, col: 21, offset: 5657},
+	val: "(",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 239, col: 25, offset: 5661},
+	name: "WS",
+},
+&labeledExpr{
+	pos: position{line: 239, col: 28, offset: 5664},
+	label: "s",
+	expr: &ruleRefExpr{
+	pos: position{line: 239, col: 30, offset: 5666},
+	name: "String",
+},
+},
+&ruleRefExpr{
+	pos: position{line: 239, col: 37, offset: 5673},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 239, col: 40, offset: 5676},
+	val: ")",
+	ignoreCase: false,
+},
+	},
+},
+},
+},
+{
+	name: "AGGREGATOR",
+	pos: position{line: 243, col: 1, offset: 5700},
+	expr: &actionExpr{
+	pos: position{line: 243, col: 15, offset: 5714},
+	run: (*parser).callonAGGREGATOR1,
+	expr: &labeledExpr{
+	pos: position{line: 243, col: 15, offset: 5714},
+	label: "a",
+	expr: &choiceExpr{
+	pos: position{line: 243, col: 18, offset: 5717},
+	alternatives: []interface{}{
+&litMatcher{
+	pos: position{line: 243, col: 18, offset: 5717},
+	val: "sum",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 243, col: 26, offset: 5725},
+	val: "count",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 243, col: 36, offset: 5735},
+	val: "avg",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 243, col: 44, offset: 5743},
+	val: "min",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 243, col: 52, offset: 5751},
+	val: "max",
+	ignoreCase: false,
+},
+	},
+},
+},
+},
+},
+{
+	name: "HEADERS",
+	pos: position{line: 247, col: 1, offset: 5806},
+	expr: &actionExpr{
+	pos: position{line: 247, col: 12, offset: 5817},
+	run: (*parser).callonHEADERS1,
+	expr: &seqExpr{
+	pos: position{line: 247, col: 12, offset: 5817},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 247, col: 12, offset: 5817},
+	name: "WS_MAND",
+},
+&litMatcher{
+	pos: position{line: 247, col: 20, offset: 5825},
+	val: "headers",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 247, col: 30, offset: 5835},
+	name: "WS_MAND",
+},
+&labeledExpr{
+	pos: position{line: 247, col: 38, offset: 5843},
+	label: "h",
+	expr: &ruleRefExpr{
+	pos: position{line: 247, col: 41, offset: 5846},
+	name: "HEADER",
+},
+},
+&labeledExpr{
+	pos: position{line: 247, col: 49, offset: 5854},
+	label: "hs",
+	expr: &zeroOrMoreExpr{
+	pos: position{line: 247, col: 52, offset: 5857},
+	expr: &seqExpr{
+	pos: position{line: 247, col: 53, offset: 5858},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 247, col: 53, offset: 5858},
+	name: "WS",
+},
+&ruleRefExpr{
+	pos: position{line: 247, col: 56, offset: 5861},
+	name: "LS",
+},
+&ruleRefExpr{
+	pos: position{line: 247, col: 59, offset: 5864},
+	name: "WS",
+},
+&ruleRefExpr{
+	pos: position{line: 247, col: 62, offset: 5867},
+	name: "HEADER",
+},
+	},
+},
+},
+},
+	},
+},
+},
+},
+{
+	name: "HEADER",
+	pos: position{line: 251, col: 1, offset: 5907},
+	expr: &actionExpr{
+	pos: position{line: 251, col: 11, offset: 5917},
+	run: (*parser).callonHEADER1,
+	expr: &seqExpr{
+	pos: position{line: 251, col: 11, offset: 5917},
+	exprs: []interface{}{
+&labeledExpr{
+	pos: position{line: 251, col: 11, offset: 5917},
+	label: "n",
+	expr: &ruleRefExpr{
+	pos: position{line: 251, col: 14, offset: 5920},
+	name: "IDENT",
+},
+},
+&ruleRefExpr{
+	pos: position{line: 251, col: 21, offset: 5927},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 251, col: 24, offset: 5930},
+	val: "=",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 251, col: 28, offset: 5934},
+	name: "WS",
+},
+&labeledExpr{
+	pos: position{line: 251, col: 31, offset: 5937},
+	label: "v",
+	expr: &choiceExpr{
+	pos: position{line: 251, col: 34, offset: 5940},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 251, col: 34, offset: 5940},
+	name: "VARIABLE",
+},
+&ruleRefExpr{
+	pos: position{line: 251, col: 45, offset: 5951},
+	name: "CHAIN",
+},
+&ruleRefExpr{
+	pos: position{line: 251, col: 53, offset: 5959},
+	name: "String",
+},
+	},
+},
+},
+	},
+},
+},
+},
+{
+	name: "HIDDEN_RULE",
+	pos: position{line: 255, col: 1, offset: 5996},
+	expr: &actionExpr{
+	pos: position{line: 255, col: 16, offset: 6011},
+	run: (*parser).callonHIDDEN_RULE1,
+	expr: &seqExpr{
+	pos: position{line: 255, col: 16, offset: 6011},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 255, col: 16, offset: 6011},
+	name: "WS_MAND",
+},
+&litMatcher{
+	pos: position{line: 255, col: 24, offset: 6019},
+	val: "hidden",
+	ignoreCase: false,
+},
+	},
+},
+},
+},
+{
+	name: "TIMEOUT",
+	pos: position{line: 259, col: 1, offset: 6053},
+	expr: &actionExpr{
+	pos: position{line: 259, col: 12, offset: 6064},
+	run: (*parser).callonTIMEOUT1,
+	expr: &seqExpr{
+	pos: position{line: 259, col: 12, offset: 6064},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 259, col: 12, offset: 6064},
+	name: "WS_MAND",
+},
+&litMatcher{
+	pos: position{line: 259, col: 20, offset: 6072},
+	val: "timeout",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 259, col: 30, offset: 6082},
+	name: "WS_MAND",
+},
+&labeledExpr{
+	pos: position{line: 259, col: 38, offset: 6090},
+	label: "t",
+	expr: &choiceExpr{
+	pos: position{line: 259, col: 41, offset: 6093},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 259, col: 41, offset: 6093},
+	name: "VARIABLE",
+},
+&ruleRefExpr{
+	pos: position{line: 259, col: 52, offset: 6104},
+	name: "Integer",
+},
+&ruleRefExpr{
+	pos: position{line: 259, col: 62, offset: 6114},
+	name: "CHAIN",
+},
+	},
+},
+},
+	},
+},
+},
+},
+{
+	name: "MAX_AGE",
+	pos: position{line: 263, col: 1, offset: 6148},
+	expr: &actionExpr{
+	pos: position{line: 263, col: 12, offset: 6159},
+	run: (*parser).callonMAX_AGE1,
+	expr: &seqExpr{
+	pos: position{line: 263, col: 12, offset: 6159},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 263, col: 12, offset: 6159},
+	name: "WS_MAND",
+},
+&litMatcher{
+	pos: position{line: 263, col: 20, offset: 6167},
+	val: "max-age",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 263, col: 30, offset: 6177},
+	name: "WS_MAND",
+},
+&labeledExpr{
+	pos: position{line: 263, col: 38, offset: 6185},
+	label: "t",
+	expr: &choiceExpr{
+	pos: position{line: 263, col: 41, offset: 6188},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 263, col: 41, offset: 6188},
+	name: "VARIABLE",
+},
+&ruleRefExpr{
+	pos: position{line: 263, col: 52, offset: 6199},
+	name: "Integer",
+},
+&ruleRefExpr{
+	pos: position{line: 263, col: 62, offset: 6209},
+	name: "CHAIN",
+},
+	},
+},
+},
+	},
+},
+},
+},
+{
+	name: "S_MAX_AGE",
+	pos: position{line: 267, col: 1, offset: 6242},
+	expr: &actionExpr{
+	pos: position{line: 267, col: 14, offset: 6255},
+	run: (*parser).callonS_MAX_AGE1,
+	expr: &seqExpr{
+	pos: position{line: 267, col: 14, offset: 6255},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 267, col: 14, offset: 6255},
+	name: "WS_MAND",
+},
+&litMatcher{
+	pos: position{line: 267, col: 22, offset: 6263},
+	val: "s-max-age",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 267, col: 34, offset: 6275},
+	name: "WS_MAND",
+},
+&labeledExpr{
+	pos: position{line: 267, col: 42, offset: 6283},
+	label: "t",
+	expr: &choiceExpr{
+	pos: position{line: 267, col: 45, offset: 6286},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 267, col: 45, offset: 6286},
+	name: "VARIABLE",
+},
+&ruleRefExpr{
+	pos: position{line: 267, col: 56, offset: 6297},
+	name: "Integer",
+},
+&ruleRefExpr{
+	pos: position{line: 267, col: 66, offset: 6307},
+	name: "CHAIN",
+},
+	},
+},
+},
+	},
+},
+},
+},
+{
+	name: "CACHE",
+	pos: position{line: 271, col: 1, offset: 6341},
+	expr: &actionExpr{
+	pos: position{line: 271, col: 10, offset: 6350},
+	run: (*parser).callonCACHE1,
+	expr: &seqExpr{
+	pos: position{line: 271, col: 10, offset: 6350},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 271, col: 10, offset: 6350},
+	name: "WS_MAND",
+},
+&litMatcher{
+	pos: position{line: 271, col: 18, offset: 6358},
+	val: "cache",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 271, col: 26, offset: 6366},
+	name: "WS_MAND",
+},
+&labeledExpr{
+	pos: position{line: 271, col: 34, offset: 6374},
+	label: "t",
+	expr: &ruleRefExpr{
+	pos: position{line: 271, col: 36, offset: 6376},
+	name: "Integer",
+},
+},
+	},
+},
+},
+},
+{
+	name: "SLO",
+	pos: position{line: 275, col: 1, offset: 6409},
+	expr: &actionExpr{
+	pos: position{line: 275, col: 8, offset: 6416},
+	run: (*parser).callonSLO1,
+	expr: &seqExpr{
+	pos: position{line: 275, col: 8, offset: 6416},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 275, col: 8, offset: 6416},
+	name: "WS_MAND",
+},
+&litMatcher{
+	pos: position{line: 275, col: 16, offset: 6424},
+	val: "slo",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 275, col: 22, offset: 6430},
+	name: "WS_MAND",
+},
+&labeledExpr{
+	pos: position{line: 275, col: 30, offset: 6438},
+	label: "t",
+	expr: &ruleRefExpr{
+	pos: position{line: 275, col: 32, offset: 6440},
+	name: "Integer",
+},
+},
+	},
+},
+},
+},
+{
+	name: "RETURN_HEADERS",
+	pos: position{line: 279, col: 1, offset: 6471},
+	expr: &actionExpr{
+	pos: position{line: 279, col: 19, offset: 6489},
+	run: (*parser).callonRETURN_HEADERS1,
+	expr: &seqExpr{
+	pos: position{line: 279, col: 19, offset: 6489},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 279, col: 19, offset: 6489},
+	name: "WS_MAND",
+},
+&litMatcher{
+	pos: position{line: 279, col: 27, offset: 6497},
+	val: "return-headers",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 279, col: 44, offset: 6514},
+	name: "WS_MAND",
+},
+&labeledExpr{
+	pos: position{line: 279, col: 52, offset: 6522},
+	label: "h",
+	expr: &ruleRefExpr{
+	pos: position{line: 279, col: 55, offset: 6525},
+	name: "IDENT",
+},
+},
+&labeledExpr{
+	pos: position{line: 279, col: 62, offset: 6532},
+	label: "hs",
+	expr: &zeroOrMoreExpr{
+	pos: position{line: 279, col: 65, offset: 6535},
+	expr: &seqExpr{
+	pos: position{line: 279, col: 66, offset: 6536},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 279, col: 66, offset: 6536},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 279, col: 69, offset: 6539},
+	val: ",",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 279, col: 73, offset: 6543},
+	name: "WS",
+},
+&ruleRefExpr{
+	pos: position{line: 279, col: 76, offset: 6546},
+	name: "IDENT",
+},
+	},
+},
+},
+},
+	},
+},
+},
+},
+{
+	name: "DEFAULT",
+	pos: position{line: 283, col: 1, offset: 6591},
+	expr: &actionExpr{
+	pos: position{line: 283, col: 12, offset: 6602},
+	run: (*parser).callonDEFAULT1,
+	expr: &seqExpr{
+	pos: position{line: 283, col: 12, offset: 6602},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 283, col: 12, offset: 6602},
+	name: "WS_MAND",
+},
+&litMatcher{
+	pos: position{line: 283, col: 20, offset: 6610},
+	val: "default",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 283, col: 30, offset: 6620},
+	name: "WS_MAND",
+},
+&labeledExpr{
+	pos: position{line: 283, col: 38, offset: 6628},
+	label: "v",
+	expr: &ruleRefExpr{
+	pos: position{line: 283, col: 41, offset: 6631},
+	name: "VALUE",
+},
+},
+	},
+},
+},
+},
+{
+	name: "HTTP_METHOD",
+	pos: position{line: 287, col: 1, offset: 6665},
+	expr: &actionExpr{
+	pos: position{line: 287, col: 16, offset: 6680},
+	run: (*parser).callonHTTP_METHOD1,
+	expr: &seqExpr{
+	pos: position{line: 287, col: 16, offset: 6680},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 287, col: 16, offset: 6680},
+	name: "WS_MAND",
+},
+&litMatcher{
+	pos: position{line: 287, col: 24, offset: 6688},
+	val: "method",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 287, col: 33, offset: 6697},
+	name: "WS_MAND",
+},
+&labeledExpr{
+	pos: position{line: 287, col: 41, offset: 6705},
+	label: "m",
+	expr: &ruleRefExpr{
+	pos: position{line: 287, col: 44, offset: 6708},
+	name: "HTTP_METHOD_NAME",
+},
+},
+	},
+},
+},
+},
+{
+	name: "HTTP_METHOD_NAME",
+	pos: position{line: 291, col: 1, offset: 6756},
+	expr: &actionExpr{
+	pos: position{line: 291, col: 21, offset: 6776},
+	run: (*parser).callonHTTP_METHOD_NAME1,
+	expr: &oneOrMoreExpr{
+	pos: position{line: 291, col: 21, offset: 6776},
+	expr: &charClassMatcher{
+	pos: position{line: 291, col: 21, offset: 6776},
+	val: "[A-Za-z]",
+	ranges: []rune{'A','Z','a','z',},
+	ignoreCase: false,
+	inverted: false,
+},
+},
+},
+},
+{
+	name: "FLAGS_RULE",
+	pos: position{line: 295, col: 1, offset: 6817},
+	expr: &actionExpr{
+	pos: position{line: 295, col: 15, offset: 6831},
+	run: (*parser).callonFLAGS_RULE1,
+	expr: &seqExpr{
+	pos: position{line: 295, col: 15, offset: 6831},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 295, col: 15, offset: 6831},
+	name: "WS_MAND",
+},
+&labeledExpr{
+	pos: position{line: 295, col: 23, offset: 6839},
+	label: "f",
+	expr: &ruleRefExpr{
+	pos: position{line: 295, col: 25, offset: 6841},
+	name: "FLAG",
+},
+},
+&labeledExpr{
+	pos: position{line: 295, col: 30, offset: 6846},
+	label: "fs",
+	expr: &zeroOrMoreExpr{
+	pos: position{line: 295, col: 33, offset: 6849},
+	expr: &seqExpr{
+	pos: position{line: 295, col: 34, offset: 6850},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 295, col: 34, offset: 6850},
+	name: "WS",
+},
+&ruleRefExpr{
+	pos: position{line: 295, col: 37, offset: 6853},
+	name: "LS",
+},
+&ruleRefExpr{
+	pos: position{line: 295, col: 40, offset: 6856},
+	name: "WS",
+},
+&ruleRefExpr{
+	pos: position{line: 295, col: 43, offset: 6859},
+	name: "FLAG",
+},
+	},
+},
+},
+},
+	},
+},
+},
+},
+{
+	name: "FLAG",
+	pos: position{line: 299, col: 1, offset: 6895},
+	expr: &choiceExpr{
+	pos: position{line: 299, col: 9, offset: 6903},
+	alternatives: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 299, col: 9, offset: 6903},
+	name: "IGNORE_FLAG",
+},
+&ruleRefExpr{
+	pos: position{line: 299, col: 23, offset: 6917},
+	name: "FILTER_ERRORS_FLAG",
+},
+&ruleRefExpr{
+	pos: position{line: 299, col: 44, offset: 6938},
+	name: "NO_CACHE_FLAG",
+},
+	},
+},
+},
+{
+	name: "IGNORE_FLAG",
+	pos: position{line: 301, col: 1, offset: 6953},
+	expr: &actionExpr{
+	pos: position{line: 301, col: 16, offset: 6968},
+	run: (*parser).callonIGNORE_FLAG1,
+	expr: &litMatcher{
+	pos: position{line: 301, col: 16, offset: 6968},
+	val: "ignore-errors",
+	ignoreCase: false,
+},
+},
+},
+{
+	name: "FILTER_ERRORS_FLAG",
+	pos: position{line: 305, col: 1, offset: 7015},
+	expr: &actionExpr{
+	pos: position{line: 305, col: 23, offset: 7037},
+	run: (*parser).callonFILTER_ERRORS_FLAG1,
+	expr: &litMatcher{
+	pos: position{line: 305, col: 23, offset: 7037},
+	val: "filter-errors",
+	ignoreCase: false,
+},
+},
+},
+{
+	name: "NO_CACHE_FLAG",
+	pos: position{line: 309, col: 1, offset: 7084},
+	expr: &actionExpr{
+	pos: position{line: 309, col: 18, offset: 7101},
+	run: (*parser).callonNO_CACHE_FLAG1,
+	expr: &litMatcher{
+	pos: position{line: 309, col: 18, offset: 7101},
+	val: "no-cache",
+	ignoreCase: false,
+},
+},
 },
 {
 	name: "CHAIN",
-	pos: position{line: 167, col: 1, offset: 3457},
+	pos: position{line: 313, col: 1, offset: 7138},
 	expr: &actionExpr{
-	pos: position{line: 167, col: 10, offset: 3466},
+	pos: position{line: 313, col: 10, offset: 7147},
 	run: (*parser).callonCHAIN1,
 	expr: &seqExpr{
-	pos: position{line: 167, col: 10, offset: 3466},
+	pos: position{line: 313, col: 10, offset: 7147},
 	exprs: []interface{}{
 &labeledExpr{
-	pos: position{line: 167, col: 10, offset: 3466},
+	pos: position{line: 313, col: 10, offset: 7147},
 	label: "i",
 	expr: &ruleRefExpr{
-	pos: position{line: 167, col: 13, offset: 3469},
+	pos: position{line: 313, col: 13, offset: 7150},
 	name: "CHAINED_ITEM",
 },
 },
 &labeledExpr{
-	pos: position{line: 167, col: 27, offset: 3483},
+	pos: position{line: 313, col: 27, offset: 7164},
 	label: "ii",
 	expr: &zeroOrMoreExpr{
-	pos: position{line: 167, col: 30, offset: 3486},
+	pos: position{line: 313, col: 30, offset: 7167},
 	expr: &seqExpr{
-	pos: position{line: 167, col: 31, offset: 3487},
+	pos: position{line: 313, col: 31, offset: 7168},
 	exprs: []interface{}{
 &zeroOrOneExpr{
-	pos: position{line: 167, col: 31, offset: 3487},
+	pos: position{line: 313, col: 31, offset: 7168},
 	expr: &litMatcher{
-	pos: position{line: 167, col: 31, offset: 3487},
+	pos: position{line: 313, col: 31, offset: 7168},
 	val: ".",
 	ignoreCase: false,
 },
 },
 &ruleRefExpr{
-	pos: position{line: 167, col: 36, offset: 3492},
+	pos: position{line: 313, col: 36, offset: 7173},
 	name: "CHAINED_ITEM",
 },
 	},
@@ -1860,63 +3735,352 @@ var g = &grammar {
 },
 {
 	name: "CHAINED_ITEM",
-	pos: position{line: 171, col: 1, offset: 3536},
+	pos: position{line: 317, col: 1, offset: 7217},
 	expr: &actionExpr{
-	pos: position{line: 171, col: 17, offset: 3552},
+	pos: position{line: 317, col: 17, offset: 7233},
 	run: (*parser).callonCHAINED_ITEM1,
 	expr: &labeledExpr{
-	pos: position{line: 171, col: 17, offset: 3552},
+	pos: position{line: 317, col: 17, offset: 7233},
 	label: "ci",
 	expr: &choiceExpr{
-	pos: position{line: 171, col: 21, offset: 3556},
+	pos: position{line: 317, col: 21, offset: 7237},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 171, col: 21, offset: 3556},
+	pos: position{line: 317, col: 21, offset: 7237},
 	name: "PATH_VARIABLE",
 },
-&ruleRefExpr{
-	pos: position{line: 171, col: 37, offset: 3572},
-	name: "IDENT",
+&ruleRefExpr{
+	pos: position{line: 317, col: 37, offset: 7253},
+	name: "CHAIN_SELECTOR",
+},
+&ruleRefExpr{
+	pos: position{line: 317, col: 54, offset: 7270},
+	name: "IDENT",
+},
+	},
+},
+},
+},
+},
+{
+	name: "CHAIN_SELECTOR",
+	pos: position{line: 321, col: 1, offset: 7305},
+	expr: &actionExpr{
+	pos: position{line: 321, col: 19, offset: 7323},
+	run: (*parser).callonCHAIN_SELECTOR1,
+	expr: &choiceExpr{
+	pos: position{line: 321, col: 20, offset: 7324},
+	alternatives: []interface{}{
+&seqExpr{
+	pos: position{line: 321, col: 20, offset: 7324},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 321, col: 20, offset: 7324},
+	val: "[?(",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 321, col: 26, offset: 7330},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 321, col: 29, offset: 7333},
+	val: "@",
+	ignoreCase: false,
+},
+&oneOrMoreExpr{
+	pos: position{line: 321, col: 33, offset: 7337},
+	expr: &seqExpr{
+	pos: position{line: 321, col: 34, offset: 7338},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 321, col: 34, offset: 7338},
+	val: ".",
+	ignoreCase: false,
+},
+&ruleRefExpr{
+	pos: position{line: 321, col: 38, offset: 7342},
+	name: "IDENT",
+},
+	},
+},
+},
+&zeroOrOneExpr{
+	pos: position{line: 321, col: 46, offset: 7350},
+	expr: &seqExpr{
+	pos: position{line: 321, col: 47, offset: 7351},
+	exprs: []interface{}{
+&ruleRefExpr{
+	pos: position{line: 321, col: 47, offset: 7351},
+	name: "WS",
+},
+&ruleRefExpr{
+	pos: position{line: 321, col: 50, offset: 7354},
+	name: "PREDICATE_OPERATOR",
+},
+&ruleRefExpr{
+	pos: position{line: 321, col: 69, offset: 7373},
+	name: "WS",
+},
+&ruleRefExpr{
+	pos: position{line: 321, col: 72, offset: 7376},
+	name: "PREDICATE_VALUE",
+},
+	},
+},
+},
+&ruleRefExpr{
+	pos: position{line: 321, col: 90, offset: 7394},
+	name: "WS",
+},
+&litMatcher{
+	pos: position{line: 321, col: 93, offset: 7397},
+	val: ")]",
+	ignoreCase: false,
+},
+	},
+},
+&seqExpr{
+	pos: position{line: 321, col: 100, offset: 7404},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 321, col: 100, offset: 7404},
+	val: "[",
+	ignoreCase: false,
+},
+&oneOrMoreExpr{
+	pos: position{line: 321, col: 104, offset: 7408},
+	expr: &charClassMatcher{
+	pos: position{line: 321, col: 104, offset: 7408},
+	val: "[0-9:-]",
+	chars: []rune{':','-',},
+	ranges: []rune{'0','9',},
+	ignoreCase: false,
+	inverted: false,
+},
+},
+&litMatcher{
+	pos: position{line: 321, col: 113, offset: 7417},
+	val: "]",
+	ignoreCase: false,
+},
+	},
+},
+	},
+},
+},
+},
+{
+	name: "PREDICATE_OPERATOR",
+	pos: position{line: 325, col: 1, offset: 7453},
+	expr: &choiceExpr{
+	pos: position{line: 325, col: 23, offset: 7475},
+	alternatives: []interface{}{
+&litMatcher{
+	pos: position{line: 325, col: 23, offset: 7475},
+	val: "==",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 325, col: 30, offset: 7482},
+	val: "!=",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 325, col: 37, offset: 7489},
+	val: ">=",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 325, col: 44, offset: 7496},
+	val: "<=",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 325, col: 51, offset: 7503},
+	val: ">",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 325, col: 57, offset: 7509},
+	val: "<",
+	ignoreCase: false,
+},
+	},
+},
+},
+{
+	name: "PREDICATE_VALUE",
+	pos: position{line: 327, col: 1, offset: 7514},
+	expr: &choiceExpr{
+	pos: position{line: 327, col: 20, offset: 7533},
+	alternatives: []interface{}{
+&litMatcher{
+	pos: position{line: 327, col: 20, offset: 7533},
+	val: "true",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 327, col: 29, offset: 7542},
+	val: "false",
+	ignoreCase: false,
+},
+&litMatcher{
+	pos: position{line: 327, col: 39, offset: 7552},
+	val: "null",
+	ignoreCase: false,
+},
+&seqExpr{
+	pos: position{line: 327, col: 48, offset: 7561},
+	exprs: []interface{}{
+&zeroOrOneExpr{
+	pos: position{line: 327, col: 48, offset: 7561},
+	expr: &litMatcher{
+	pos: position{line: 327, col: 48, offset: 7561},
+	val: "-",
+	ignoreCase: false,
+},
+},
+&oneOrMoreExpr{
+	pos: position{line: 327, col: 53, offset: 7566},
+	expr: &charClassMatcher{
+	pos: position{line: 327, col: 53, offset: 7566},
+	val: "[0-9]",
+	ranges: []rune{'0','9',},
+	ignoreCase: false,
+	inverted: false,
+},
+},
+&zeroOrOneExpr{
+	pos: position{line: 327, col: 60, offset: 7573},
+	expr: &seqExpr{
+	pos: position{line: 327, col: 61, offset: 7574},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 327, col: 61, offset: 7574},
+	val: ".",
+	ignoreCase: false,
+},
+&oneOrMoreExpr{
+	pos: position{line: 327, col: 65, offset: 7578},
+	expr: &charClassMatcher{
+	pos: position{line: 327, col: 65, offset: 7578},
+	val: "[0-9]",
+	ranges: []rune{'0','9',},
+	ignoreCase: false,
+	inverted: false,
+},
+},
+	},
+},
+},
+	},
+},
+&seqExpr{
+	pos: position{line: 327, col: 76, offset: 7589},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 327, col: 76, offset: 7589},
+	val: "\"",
+	ignoreCase: false,
+},
+&zeroOrMoreExpr{
+	pos: position{line: 327, col: 80, offset: 7593},
+	expr: &seqExpr{
+	pos: position{line: 327, col: 81, offset: 7594},
+	exprs: []interface{}{
+&notExpr{
+	pos: position{line: 327, col: 81, offset: 7594},
+	expr: &litMatcher{
+	pos: position{line: 327, col: 82, offset: 7595},
+	val: "\"",
+	ignoreCase: false,
+},
+},
+&anyMatcher{
+	line: 327, col: 86, offset: 7599,
+},
+	},
+},
+},
+&litMatcher{
+	pos: position{line: 327, col: 90, offset: 7603},
+	val: "\"",
+	ignoreCase: false,
+},
+	},
+},
+&seqExpr{
+	pos: position{line: 327, col: 96, offset: 7609},
+	exprs: []interface{}{
+&litMatcher{
+	pos: position{line: 327, col: 96, offset: 7609},
+	val: "'",
+	ignoreCase: false,
+},
+&zeroOrMoreExpr{
+	pos: position{line: 327, col: 101, offset: 7614},
+	expr: &seqExpr{
+	pos: position{line: 327, col: 102, offset: 7615},
+	exprs: []interface{}{
+&notExpr{
+	pos: position{line: 327, col: 102, offset: 7615},
+	expr: &litMatcher{
+	pos: position{line: 327, col: 103, offset: 7616},
+	val: "'",
+	ignoreCase: false,
+},
+},
+&anyMatcher{
+	line: 327, col: 108, offset: 7621,
 },
 	},
 },
 },
+&litMatcher{
+	pos: position{line: 327, col: 112, offset: 7625},
+	val: "'",
+	ignoreCase: false,
+},
+	},
+},
+	},
 },
 },
 {
 	name: "PATH_VARIABLE",
-	pos: position{line: 175, col: 1, offset: 3607},
+	pos: position{line: 329, col: 1, offset: 7631},
 	expr: &actionExpr{
-	pos: position{line: 175, col: 18, offset: 3624},
+	pos: position{line: 329, col: 18, offset: 7648},
 	run: (*parser).callonPATH_VARIABLE1,
 	expr: &seqExpr{
-	pos: position{line: 175, col: 18, offset: 3624},
+	pos: position{line: 329, col: 18, offset: 7648},
 	exprs: []interface{}{
 &zeroOrOneExpr{
-	pos: position{line: 175, col: 18, offset: 3624},
+	pos: position{line: 329, col: 18, offset: 7648},
 	expr: &litMatcher{
-	pos: position{line: 175, col: 18, offset: 3624},
+	pos: position{line: 329, col: 18, offset: 7648},
 	val: "[",
 	ignoreCase: false,
 },
 },
 &litMatcher{
-	pos: position{line: 175, col: 23, offset: 3629},
+	pos: position{line: 329, col: 23, offset: 7653},
 	val: "$",
 	ignoreCase: false,
 },
 &labeledExpr{
-	pos: position{line: 175, col: 27, offset: 3633},
+	pos: position{line: 329, col: 27, offset: 7657},
 	label: "i",
 	expr: &ruleRefExpr{
-	pos: position{line: 175, col: 30, offset: 3636},
+	pos: position{line: 329, col: 30, offset: 7660},
 	name: "IDENT",
 },
 },
 &zeroOrOneExpr{
-	pos: position{line: 175, col: 37, offset: 3643},
+	pos: position{line: 329, col: 37, offset: 7667},
 	expr: &litMatcher{
-	pos: position{line: 175, col: 37, offset: 3643},
+	pos: position{line: 329, col: 37, offset: 7667},
 	val: "]",
 	ignoreCase: false,
 },
@@ -1927,23 +4091,23 @@ var g = &grammar {
 },
 {
 	name: "VARIABLE",
-	pos: position{line: 179, col: 1, offset: 3685},
+	pos: position{line: 333, col: 1, offset: 7709},
 	expr: &actionExpr{
-	pos: position{line: 179, col: 13, offset: 3697},
+	pos: position{line: 333, col: 13, offset: 7721},
 	run: (*parser).callonVARIABLE1,
 	expr: &seqExpr{
-	pos: position{line: 179, col: 13, offset: 3697},
+	pos: position{line: 333, col: 13, offset: 7721},
 	exprs: []interface{}{
 &litMatcher{
-	pos: position{line: 179, col: 13, offset: 3697},
+	pos: position{line: 333, col: 13, offset: 7721},
 	val: "$",
 	ignoreCase: false,
 },
 &labeledExpr{
-	pos: position{line: 179, col: 17, offset: 3701},
+	pos: position{line: 333, col: 17, offset: 7725},
 	label: "v",
 	expr: &ruleRefExpr{
-	pos: position{line: 179, col: 20, offset: 3704},
+	pos: position{line: 333, col: 20, offset: 7728},
 	name: "IDENT_WITH_DOT",
 },
 },
@@ -1953,14 +4117,14 @@ var g = &grammar {
 },
 {
 	name: "IDENT",
-	pos: position{line: 183, col: 1, offset: 3748},
+	pos: position{line: 337, col: 1, offset: 7772},
 	expr: &actionExpr{
-	pos: position{line: 183, col: 10, offset: 3757},
+	pos: position{line: 337, col: 10, offset: 7781},
 	run: (*parser).callonIDENT1,
 	expr: &oneOrMoreExpr{
-	pos: position{line: 183, col: 10, offset: 3757},
+	pos: position{line: 337, col: 10, offset: 7781},
 	expr: &charClassMatcher{
-	pos: position{line: 183, col: 10, offset: 3757},
+	pos: position{line: 337, col: 10, offset: 7781},
 	val: "[A-Za-z0-9:_-]",
 	chars: []rune{':','_','-',},
 	ranges: []rune{'A','Z','a','z','0','9',},
@@ -1972,14 +4136,14 @@ var g = &grammar {
 },
 {
 	name: "IDENT_WITHOUT_COLLON",
-	pos: position{line: 187, col: 1, offset: 3804},
+	pos: position{line: 341, col: 1, offset: 7828},
 	expr: &actionExpr{
-	pos: position{line: 187, col: 25, offset: 3828},
+	pos: position{line: 341, col: 25, offset: 7852},
 	run: (*parser).callonIDENT_WITHOUT_COLLON1,
 	expr: &oneOrMoreExpr{
-	pos: position{line: 187, col: 25, offset: 3828},
+	pos: position{line: 341, col: 25, offset: 7852},
 	expr: &charClassMatcher{
-	pos: position{line: 187, col: 25, offset: 3828},
+	pos: position{line: 341, col: 25, offset: 7852},
 	val: "[A-Za-z0-9_-]",
 	chars: []rune{'_','-',},
 	ranges: []rune{'A','Z','a','z','0','9',},
@@ -1991,14 +4155,14 @@ var g = &grammar {
 },
 {
 	name: "IDENT_WITH_DOT",
-	pos: position{line: 191, col: 1, offset: 3874},
+	pos: position{line: 345, col: 1, offset: 7898},
 	expr: &actionExpr{
-	pos: position{line: 191, col: 19, offset: 3892},
+	pos: position{line: 345, col: 19, offset: 7916},
 	run: (*parser).callonIDENT_WITH_DOT1,
 	expr: &oneOrMoreExpr{
-	pos: position{line: 191, col: 19, offset: 3892},
+	pos: position{line: 345, col: 19, offset: 7916},
 	expr: &charClassMatcher{
-	pos: position{line: 191, col: 19, offset: 3892},
+	pos: position{line: 345, col: 19, offset: 7916},
 	val: "[a-zA-Z0-9-:_.]",
 	chars: []rune{'-',':','_','.',},
 	ranges: []rune{'a','z','A','Z','0','9',},
@@ -2010,12 +4174,12 @@ var g = &grammar {
 },
 {
 	name: "Null",
-	pos: position{line: 195, col: 1, offset: 3940},
+	pos: position{line: 349, col: 1, offset: 7964},
 	expr: &actionExpr{
-	pos: position{line: 195, col: 9, offset: 3948},
+	pos: position{line: 349, col: 9, offset: 7972},
 	run: (*parser).callonNull1,
 	expr: &litMatcher{
-	pos: position{line: 195, col: 9, offset: 3948},
+	pos: position{line: 349, col: 9, offset: 7972},
 	val: "null",
 	ignoreCase: false,
 },
@@ -2023,20 +4187,20 @@ var g = &grammar {
 },
 {
 	name: "Boolean",
-	pos: position{line: 199, col: 1, offset: 3978},
+	pos: position{line: 353, col: 1, offset: 8002},
 	expr: &actionExpr{
-	pos: position{line: 199, col: 12, offset: 3989},
+	pos: position{line: 353, col: 12, offset: 8013},
 	run: (*parser).callonBoolean1,
 	expr: &choiceExpr{
-	pos: position{line: 199, col: 13, offset: 3990},
+	pos: position{line: 353, col: 13, offset: 8014},
 	alternatives: []interface{}{
 &litMatcher{
-	pos: position{line: 199, col: 13, offset: 3990},
+	pos: position{line: 353, col: 13, offset: 8014},
 	val: "true",
 	ignoreCase: false,
 },
 &litMatcher{
-	pos: position{line: 199, col: 22, offset: 3999},
+	pos: position{line: 353, col: 22, offset: 8023},
 	val: "false",
 	ignoreCase: false,
 },
@@ -2046,39 +4210,39 @@ var g = &grammar {
 },
 {
 	name: "String",
-	pos: position{line: 203, col: 1, offset: 4040},
+	pos: position{line: 357, col: 1, offset: 8064},
 	expr: &actionExpr{
-	pos: position{line: 203, col: 11, offset: 4050},
+	pos: position{line: 357, col: 11, offset: 8074},
 	run: (*parser).callonString1,
 	expr: &seqExpr{
-	pos: position{line: 203, col: 11, offset: 4050},
+	pos: position{line: 357, col: 11, offset: 8074},
 	exprs: []interface{}{
 &litMatcher{
-	pos: position{line: 203, col: 11, offset: 4050},
+	pos: position{line: 357, col: 11, offset: 8074},
 	val: "\"",
 	ignoreCase: false,
 },
 &zeroOrMoreExpr{
-	pos: position{line: 203, col: 15, offset: 4054},
+	pos: position{line: 357, col: 15, offset: 8078},
 	expr: &seqExpr{
-	pos: position{line: 203, col: 17, offset: 4056},
+	pos: position{line: 357, col: 17, offset: 8080},
 	exprs: []interface{}{
 &notExpr{
-	pos: position{line: 203, col: 17, offset: 4056},
+	pos: position{line: 357, col: 17, offset: 8080},
 	expr: &litMatcher{
-	pos: position{line: 203, col: 18, offset: 4057},
+	pos: position{line: 357, col: 18, offset: 8081},
 	val: "\"",
 	ignoreCase: false,
 },
 },
 &anyMatcher{
-	line: 203, col: 22, offset: 4061,
+	line: 357, col: 22, offset: 8085,
 },
 	},
 },
 },
 &litMatcher{
-	pos: position{line: 203, col: 27, offset: 4066},
+	pos: position{line: 357, col: 27, offset: 8090},
 	val: "\"",
 	ignoreCase: false,
 },
@@ -2088,25 +4252,25 @@ var g = &grammar {
 },
 {
 	name: "Float",
-	pos: position{line: 207, col: 1, offset: 4101},
+	pos: position{line: 361, col: 1, offset: 8125},
 	expr: &actionExpr{
-	pos: position{line: 207, col: 10, offset: 4110},
+	pos: position{line: 361, col: 10, offset: 8134},
 	run: (*parser).callonFloat1,
 	expr: &seqExpr{
-	pos: position{line: 207, col: 10, offset: 4110},
+	pos: position{line: 361, col: 10, offset: 8134},
 	exprs: []interface{}{
 &zeroOrOneExpr{
-	pos: position{line: 207, col: 10, offset: 4110},
+	pos: position{line: 361, col: 10, offset: 8134},
 	expr: &choiceExpr{
-	pos: position{line: 207, col: 11, offset: 4111},
+	pos: position{line: 361, col: 11, offset: 8135},
 	alternatives: []interface{}{
 &litMatcher{
-	pos: position{line: 207, col: 11, offset: 4111},
+	pos: position{line: 361, col: 11, offset: 8135},
 	val: "+",
 	ignoreCase: false,
 },
 &litMatcher{
-	pos: position{line: 207, col: 17, offset: 4117},
+	pos: position{line: 361, col: 17, offset: 8141},
 	val: "-",
 	ignoreCase: false,
 },
@@ -2114,16 +4278,16 @@ var g = &grammar {
 },
 },
 &ruleRefExpr{
-	pos: position{line: 207, col: 23, offset: 4123},
+	pos: position{line: 361, col: 23, offset: 8147},
 	name: "Natural",
 },
 &litMatcher{
-	pos: position{line: 207, col: 31, offset: 4131},
+	pos: position{line: 361, col: 31, offset: 8155},
 	val: ".",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 207, col: 35, offset: 4135},
+	pos: position{line: 361, col: 35, offset: 8159},
 	name: "Natural",
 },
 	},
@@ -2132,25 +4296,25 @@ var g = &grammar {
 },
 {
 	name: "Integer",
-	pos: position{line: 211, col: 1, offset: 4173},
+	pos: position{line: 365, col: 1, offset: 8197},
 	expr: &actionExpr{
-	pos: position{line: 211, col: 12, offset: 4184},
+	pos: position{line: 365, col: 12, offset: 8208},
 	run: (*parser).callonInteger1,
 	expr: &seqExpr{
-	pos: position{line: 211, col: 12, offset: 4184},
+	pos: position{line: 365, col: 12, offset: 8208},
 	exprs: []interface{}{
 &zeroOrOneExpr{
-	pos: position{line: 211, col: 12, offset: 4184},
+	pos: position{line: 365, col: 12, offset: 8208},
 	expr: &choiceExpr{
-	pos: position{line: 211, col: 13, offset: 4185},
+	pos: position{line: 365, col: 13, offset: 8209},
 	alternatives: []interface{}{
 &litMatcher{
-	pos: position{line: 211, col: 13, offset: 4185},
+	pos: position{line: 365, col: 13, offset: 8209},
 	val: "+",
 	ignoreCase: false,
 },
 &litMatcher{
-	pos: position{line: 211, col: 19, offset: 4191},
+	pos: position{line: 365, col: 19, offset: 8215},
 	val: "-",
 	ignoreCase: false,
 },
@@ -2158,7 +4322,7 @@ var g = &grammar {
 },
 },
 &ruleRefExpr{
-	pos: position{line: 211, col: 25, offset: 4197},
+	pos: position{line: 365, col: 25, offset: 8221},
 	name: "Natural",
 },
 	},
@@ -2167,26 +4331,26 @@ var g = &grammar {
 },
 {
 	name: "Natural",
-	pos: position{line: 215, col: 1, offset: 4237},
+	pos: position{line: 369, col: 1, offset: 8261},
 	expr: &choiceExpr{
-	pos: position{line: 215, col: 11, offset: 4249},
+	pos: position{line: 369, col: 11, offset: 8273},
 	alternatives: []interface{}{
 &litMatcher{
-	pos: position{line: 215, col: 11, offset: 4249},
+	pos: position{line: 369, col: 11, offset: 8273},
 	val: "0",
 	ignoreCase: false,
 },
 &seqExpr{
-	pos: position{line: 215, col: 17, offset: 4255},
+	pos: position{line: 369, col: 17, offset: 8279},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 215, col: 17, offset: 4255},
+	pos: position{line: 369, col: 17, offset: 8279},
 	name: "NonZeroDecimalDigit",
 },
 &zeroOrMoreExpr{
-	pos: position{line: 215, col: 37, offset: 4275},
+	pos: position{line: 369, col: 37, offset: 8299},
 	expr: &ruleRefExpr{
-	pos: position{line: 215, col: 37, offset: 4275},
+	pos: position{line: 369, col: 37, offset: 8299},
 	name: "DecimalDigit",
 },
 },
@@ -2197,9 +4361,9 @@ var g = &grammar {
 },
 {
 	name: "DecimalDigit",
-	pos: position{line: 217, col: 1, offset: 4290},
+	pos: position{line: 371, col: 1, offset: 8314},
 	expr: &charClassMatcher{
-	pos: position{line: 217, col: 16, offset: 4307},
+	pos: position{line: 371, col: 16, offset: 8331},
 	val: "[0-9]",
 	ranges: []rune{'0','9',},
 	ignoreCase: false,
@@ -2208,9 +4372,9 @@ var g = &grammar {
 },
 {
 	name: "NonZeroDecimalDigit",
-	pos: position{line: 218, col: 1, offset: 4313},
+	pos: position{line: 372, col: 1, offset: 8337},
 	expr: &charClassMatcher{
-	pos: position{line: 218, col: 23, offset: 4337},
+	pos: position{line: 372, col: 23, offset: 8361},
 	val: "[1-9]",
 	ranges: []rune{'1','9',},
 	ignoreCase: false,
@@ -2219,9 +4383,9 @@ var g = &grammar {
 },
 {
 	name: "SPACE",
-	pos: position{line: 220, col: 1, offset: 4344},
+	pos: position{line: 374, col: 1, offset: 8368},
 	expr: &charClassMatcher{
-	pos: position{line: 220, col: 10, offset: 4353},
+	pos: position{line: 374, col: 10, offset: 8377},
 	val: "[ \\t]",
 	chars: []rune{' ','\t',},
 	ignoreCase: false,
@@ -2231,22 +4395,22 @@ var g = &grammar {
 {
 	name: "WS_MAND",
 	displayName: "\"mandatory-whitespace\"",
-	pos: position{line: 221, col: 1, offset: 4359},
+	pos: position{line: 375, col: 1, offset: 8383},
 	expr: &oneOrMoreExpr{
-	pos: position{line: 221, col: 35, offset: 4393},
+	pos: position{line: 375, col: 35, offset: 8417},
 	expr: &choiceExpr{
-	pos: position{line: 221, col: 36, offset: 4394},
+	pos: position{line: 375, col: 36, offset: 8418},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 221, col: 36, offset: 4394},
+	pos: position{line: 375, col: 36, offset: 8418},
 	name: "SPACE",
 },
 &ruleRefExpr{
-	pos: position{line: 221, col: 44, offset: 4402},
+	pos: position{line: 375, col: 44, offset: 8426},
 	name: "COMMENT",
 },
 &ruleRefExpr{
-	pos: position{line: 221, col: 54, offset: 4412},
+	pos: position{line: 375, col: 54, offset: 8436},
 	name: "NL",
 },
 	},
@@ -2256,18 +4420,18 @@ var g = &grammar {
 {
 	name: "WS",
 	displayName: "\"whitespace\"",
-	pos: position{line: 222, col: 1, offset: 4417},
+	pos: position{line: 376, col: 1, offset: 8441},
 	expr: &zeroOrMoreExpr{
-	pos: position{line: 222, col: 20, offset: 4436},
+	pos: position{line: 376, col: 20, offset: 8460},
 	expr: &choiceExpr{
-	pos: position{line: 222, col: 21, offset: 4437},
+	pos: position{line: 376, col: 21, offset: 8461},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 222, col: 21, offset: 4437},
+	pos: position{line: 376, col: 21, offset: 8461},
 	name: "SPACE",
 },
 &ruleRefExpr{
-	pos: position{line: 222, col: 29, offset: 4445},
+	pos: position{line: 376, col: 29, offset: 8469},
 	name: "COMMENT",
 },
 	},
@@ -2277,21 +4441,21 @@ var g = &grammar {
 {
 	name: "LS",
 	displayName: "\"line-separator\"",
-	pos: position{line: 223, col: 1, offset: 4455},
+	pos: position{line: 377, col: 1, offset: 8479},
 	expr: &choiceExpr{
-	pos: position{line: 223, col: 25, offset: 4479},
+	pos: position{line: 377, col: 25, offset: 8503},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 223, col: 25, offset: 4479},
+	pos: position{line: 377, col: 25, offset: 8503},
 	name: "NL",
 },
 &litMatcher{
-	pos: position{line: 223, col: 30, offset: 4484},
+	pos: position{line: 377, col: 30, offset: 8508},
 	val: ",",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 223, col: 36, offset: 4490},
+	pos: position{line: 377, col: 36, offset: 8514},
 	name: "COMMENT",
 },
 	},
@@ -2300,31 +4464,31 @@ var g = &grammar {
 {
 	name: "BS",
 	displayName: "\"block-separator\"",
-	pos: position{line: 224, col: 1, offset: 4499},
+	pos: position{line: 378, col: 1, offset: 8523},
 	expr: &oneOrMoreExpr{
-	pos: position{line: 224, col: 25, offset: 4523},
+	pos: position{line: 378, col: 25, offset: 8547},
 	expr: &seqExpr{
-	pos: position{line: 224, col: 26, offset: 4524},
+	pos: position{line: 378, col: 26, offset: 8548},
 	exprs: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 224, col: 26, offset: 4524},
+	pos: position{line: 378, col: 26, offset: 8548},
 	name: "WS",
 },
 &choiceExpr{
-	pos: position{line: 224, col: 30, offset: 4528},
+	pos: position{line: 378, col: 30, offset: 8552},
 	alternatives: []interface{}{
 &ruleRefExpr{
-	pos: position{line: 224, col: 30, offset: 4528},
+	pos: position{line: 378, col: 30, offset: 8552},
 	name: "NL",
 },
 &ruleRefExpr{
-	pos: position{line: 224, col: 35, offset: 4533},
+	pos: position{line: 378, col: 35, offset: 8557},
 	name: "COMMENT",
 },
 	},
 },
 &ruleRefExpr{
-	pos: position{line: 224, col: 44, offset: 4542},
+	pos: position{line: 378, col: 44, offset: 8566},
 	name: "WS",
 },
 	},
@@ -2334,53 +4498,53 @@ var g = &grammar {
 {
 	name: "NL",
 	displayName: "\"new-line\"",
-	pos: position{line: 225, col: 1, offset: 4547},
+	pos: position{line: 379, col: 1, offset: 8571},
 	expr: &litMatcher{
-	pos: position{line: 225, col: 18, offset: 4564},
+	pos: position{line: 379, col: 18, offset: 8588},
 	val: "\n",
 	ignoreCase: false,
 },
 },
 {
 	name: "COMMENT",
-	pos: position{line: 227, col: 1, offset: 4570},
+	pos: position{line: 381, col: 1, offset: 8594},
 	expr: &seqExpr{
-	pos: position{line: 227, col: 12, offset: 4581},
+	pos: position{line: 381, col: 12, offset: 8605},
 	exprs: []interface{}{
 &litMatcher{
-	pos: position{line: 227, col: 12, offset: 4581},
+	pos: position{line: 381, col: 12, offset: 8605},
 	val: "//",
 	ignoreCase: false,
 },
 &zeroOrMoreExpr{
-	pos: position{line: 227, col: 17, offset: 4586},
+	pos: position{line: 381, col: 17, offset: 8610},
 	expr: &seqExpr{
-	pos: position{line: 227, col: 19, offset: 4588},
+	pos: position{line: 381, col: 19, offset: 8612},
 	exprs: []interface{}{
 &notExpr{
-	pos: position{line: 227, col: 19, offset: 4588},
+	pos: position{line: 381, col: 19, offset: 8612},
 	expr: &litMatcher{
-	pos: position{line: 227, col: 20, offset: 4589},
+	pos: position{line: 381, col: 20, offset: 8613},
 	val: "\n",
 	ignoreCase: false,
 },
 },
 &anyMatcher{
-	line: 227, col: 25, offset: 4594,
+	line: 381, col: 25, offset: 8618,
 },
 	},
 },
 },
 &choiceExpr{
-	pos: position{line: 227, col: 31, offset: 4600},
+	pos: position{line: 381, col: 31, offset: 8624},
 	alternatives: []interface{}{
 &litMatcher{
-	pos: position{line: 227, col: 31, offset: 4600},
+	pos: position{line: 381, col: 31, offset: 8624},
 	val: "\n",
 	ignoreCase: false,
 },
 &ruleRefExpr{
-	pos: position{line: 227, col: 38, offset: 4607},
+	pos: position{line: 381, col: 38, offset: 8631},
 	name: "EOF",
 },
 	},
@@ -2390,11 +4554,11 @@ var g = &grammar {
 },
 {
 	name: "EOF",
-	pos: position{line: 229, col: 1, offset: 4613},
+	pos: position{line: 383, col: 1, offset: 8637},
 	expr: &notExpr{
-	pos: position{line: 229, col: 8, offset: 4620},
+	pos: position{line: 383, col: 8, offset: 8644},
 	expr: &anyMatcher{
-	line: 229, col: 9, offset: 4621,
+	line: 383, col: 9, offset: 8645,
 },
 },
 },
@@ -2420,6 +4584,16 @@ func (p *parser) callonUSE1() (interface{}, error) {
 	return p.cur.onUSE1(stack["r"], stack["v"])
 }
 
+func (c *current) onUSE_ONLY1(f, fs interface{}) (interface{}, error) {
+	return newUseOnly(f, fs)
+}
+
+func (p *parser) callonUSE_ONLY1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onUSE_ONLY1(stack["f"], stack["fs"])
+}
+
 func (c *current) onUSE_ACTION1() (interface{}, error) {
 	return stringify(c.text)
 }
@@ -2440,24 +4614,54 @@ func (p *parser) callonUSE_VALUE1() (interface{}, error) {
 	return p.cur.onUSE_VALUE1(stack["v"])
 }
 
-func (c *current) onBLOCK1(action, m, w, f, fl interface{}) (interface{}, error) {
-	return newBlock(action, m, w, f, fl)
+func (c *current) onBLOCK1(action, m, w, f, cp, fl interface{}) (interface{}, error) {
+	return newBlock(action, m, w, f, cp, fl)
 }
 
 func (p *parser) callonBLOCK1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onBLOCK1(stack["action"], stack["m"], stack["w"], stack["f"], stack["fl"])
+	return p.cur.onBLOCK1(stack["action"], stack["m"], stack["w"], stack["f"], stack["cp"], stack["fl"])
 }
 
-func (c *current) onACTION_RULE1(m, r, a, i interface{}) (interface{}, error) {
-	return newActionRule(m, r, a, i)
+func (c *current) onACTION_RULE1(m, r, fns, a, i interface{}) (interface{}, error) {
+	return newActionRule(m, r, fns, a, i)
 }
 
 func (p *parser) callonACTION_RULE1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onACTION_RULE1(stack["m"], stack["r"], stack["a"], stack["i"])
+	return p.cur.onACTION_RULE1(stack["m"], stack["r"], stack["fns"], stack["a"], stack["i"])
+}
+
+func (c *current) onRESULT_FN1(fn interface{}) (interface{}, error) {
+	return fn, nil
+}
+
+func (p *parser) callonRESULT_FN1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRESULT_FN1(stack["fn"])
+}
+
+func (c *current) onORDERED_FN1(b interface{}) (interface{}, error) {
+	return newOrdered(b)
+}
+
+func (p *parser) callonORDERED_FN1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onORDERED_FN1(stack["b"])
+}
+
+func (c *current) onRESULT_FN_NAME1() (interface{}, error) {
+	return stringify(c.text)
+}
+
+func (p *parser) callonRESULT_FN_NAME1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRESULT_FN_NAME1()
 }
 
 func (c *current) onMETHOD1() (interface{}, error) {
@@ -2470,6 +4674,16 @@ func (p *parser) callonMETHOD1() (interface{}, error) {
 	return p.cur.onMETHOD1()
 }
 
+func (c *current) onSUBQUERY1() (interface{}, error) {
+	return stringify(c.text)
+}
+
+func (p *parser) callonSUBQUERY1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSUBQUERY1()
+}
+
 func (c *current) onALIAS1(a interface{}) (interface{}, error) {
 	return a, nil
 }
@@ -2480,14 +4694,34 @@ func (p *parser) callonALIAS1() (interface{}, error) {
 	return p.cur.onALIAS1(stack["a"])
 }
 
-func (c *current) onIN1(t interface{}) (interface{}, error) {
-	return newIn(t)
+func (c *current) onIN1(t, j interface{}) (interface{}, error) {
+	return newIn(t, j)
 }
 
 func (p *parser) callonIN1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onIN1(stack["t"])
+	return p.cur.onIN1(stack["t"], stack["j"])
+}
+
+func (c *current) onJOIN1(t, j interface{}) (interface{}, error) {
+	return newIn(t, j)
+}
+
+func (p *parser) callonJOIN1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onJOIN1(stack["t"], stack["j"])
+}
+
+func (c *current) onJOIN_KEY1(t, o interface{}) (interface{}, error) {
+	return newJoinKey(t, o)
+}
+
+func (p *parser) callonJOIN_KEY1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onJOIN_KEY1(stack["t"], stack["o"])
 }
 
 func (c *current) onMODIFIER_RULE1(m interface{}) (interface{}, error) {
@@ -2540,6 +4774,46 @@ func (p *parser) callonKEY_VALUE1() (interface{}, error) {
 	return p.cur.onKEY_VALUE1(stack["k"], stack["v"], stack["fn"])
 }
 
+func (c *current) onDEFAULT_FN1(v interface{}) (interface{}, error) {
+	return newDefaultFunction(v)
+}
+
+func (p *parser) callonDEFAULT_FN1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDEFAULT_FN1(stack["v"])
+}
+
+func (c *current) onDEFAULT_VALUE1(v interface{}) (interface{}, error) {
+	return newValue(v)
+}
+
+func (p *parser) callonDEFAULT_VALUE1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDEFAULT_VALUE1(stack["v"])
+}
+
+func (c *current) onDEFAULT_PRIMITIVE1(p interface{}) (interface{}, error) {
+	return newPrimitive(p)
+}
+
+func (p *parser) callonDEFAULT_PRIMITIVE1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDEFAULT_PRIMITIVE1(stack["p"])
+}
+
+func (c *current) onEXPR_FN1(s interface{}) (interface{}, error) {
+	return newExpression(s)
+}
+
+func (p *parser) callonEXPR_FN1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onEXPR_FN1(stack["s"])
+}
+
 func (c *current) onAPPLY_FN1(fn interface{}) (interface{}, error) {
 	return fn, nil
 }
@@ -2570,6 +4844,26 @@ func (p *parser) callonVALUE1() (interface{}, error) {
 	return p.cur.onVALUE1(stack["v"])
 }
 
+func (c *current) onRANGE1(s, e, st interface{}) (interface{}, error) {
+	return newRange(s, e, st)
+}
+
+func (p *parser) callonRANGE1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRANGE1(stack["s"], stack["e"], stack["st"])
+}
+
+func (c *current) onRANGE_ARG1(a interface{}) (interface{}, error) {
+	return newRangeArg(a)
+}
+
+func (p *parser) callonRANGE_ARG1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRANGE_ARG1(stack["a"])
+}
+
 func (c *current) onLIST1(l interface{}) (interface{}, error) {
 	return l, nil
 }
@@ -2660,14 +4954,14 @@ func (p *parser) callonONLY_RULE1() (interface{}, error) {
 	return p.cur.onONLY_RULE1(stack["f"], stack["fs"])
 }
 
-func (c *current) onFILTER1(f, fn interface{}) (interface{}, error) {
-	return newFilter(f, fn)
+func (c *current) onFILTER1(f, fn, fns interface{}) (interface{}, error) {
+	return newFilter(f, fn, fns)
 }
 
 func (p *parser) callonFILTER1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onFILTER1(stack["f"], stack["fn"])
+	return p.cur.onFILTER1(stack["f"], stack["fn"], stack["fns"])
 }
 
 func (c *current) onFILTER_VALUE1(fv interface{}) (interface{}, error) {
@@ -2680,14 +4974,164 @@ func (p *parser) callonFILTER_VALUE1() (interface{}, error) {
 	return p.cur.onFILTER_VALUE1(stack["fv"])
 }
 
-func (c *current) onMATCHES_FN1(arg interface{}) (interface{}, error) {
-	return arg, nil
+func (c *current) onFILTER_PATH1() (interface{}, error) {
+	return stringify(c.text)
+}
+
+func (p *parser) callonFILTER_PATH1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onFILTER_PATH1()
+}
+
+func (c *current) onMATCHES_FN1(arg, flags interface{}) (interface{}, error) {
+	return newMatch(arg, flags)
 }
 
 func (p *parser) callonMATCHES_FN1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onMATCHES_FN1(stack["arg"])
+	return p.cur.onMATCHES_FN1(stack["arg"], stack["flags"])
+}
+
+func (c *current) onMATCH_FLAGS1(f interface{}) (interface{}, error) {
+	return f, nil
+}
+
+func (p *parser) callonMATCH_FLAGS1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMATCH_FLAGS1(stack["f"])
+}
+
+func (c *current) onFILTER_FN1(fn interface{}) (interface{}, error) {
+	return fn, nil
+}
+
+func (p *parser) callonFILTER_FN1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onFILTER_FN1(stack["fn"])
+}
+
+func (c *current) onFILTER_BY_KEYS_FN1(k interface{}) (interface{}, error) {
+	return newFilterByKeys(k)
+}
+
+func (p *parser) callonFILTER_BY_KEYS_FN1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onFILTER_BY_KEYS_FN1(stack["k"])
+}
+
+func (c *current) onKEYS_LIST1(ks interface{}) (interface{}, error) {
+	return newKeysList(ks)
+}
+
+func (p *parser) callonKEYS_LIST1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onKEYS_LIST1(stack["ks"])
+}
+
+func (c *current) onRENAME_AS_FN1(n interface{}) (interface{}, error) {
+	return newRenameAs(n)
+}
+
+func (p *parser) callonRENAME_AS_FN1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRENAME_AS_FN1(stack["n"])
+}
+
+func (c *current) onFIRST_FN1() (interface{}, error) {
+	return newFirst()
+}
+
+func (p *parser) callonFIRST_FN1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onFIRST_FN1()
+}
+
+func (c *current) onCOMPARE_FN1(op, arg interface{}) (interface{}, error) {
+	return newCompare(op, arg)
+}
+
+func (p *parser) callonCOMPARE_FN1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCOMPARE_FN1(stack["op"], stack["arg"])
+}
+
+func (c *current) onCOMPARE_OPERATOR1() (interface{}, error) {
+	return stringify(c.text)
+}
+
+func (p *parser) callonCOMPARE_OPERATOR1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCOMPARE_OPERATOR1()
+}
+
+func (c *current) onCOMPUTE_RULE1(f, fs interface{}) (interface{}, error) {
+	return newCompute(f, fs)
+}
+
+func (p *parser) callonCOMPUTE_RULE1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCOMPUTE_RULE1(stack["f"], stack["fs"])
+}
+
+func (c *current) onCOMPUTED_FIELD1(n, p, a, e interface{}) (interface{}, error) {
+	return newComputedField(n, p, a, e)
+}
+
+func (p *parser) callonCOMPUTED_FIELD1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCOMPUTED_FIELD1(stack["n"], stack["p"], stack["a"], stack["e"])
+}
+
+func (c *current) onAGGREGATOR_FN1(a interface{}) (interface{}, error) {
+	return a, nil
+}
+
+func (p *parser) callonAGGREGATOR_FN1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAGGREGATOR_FN1(stack["a"])
+}
+
+func (c *current) onCONCAT_FN1(s interface{}) (interface{}, error) {
+	return newAggregator("concat", s)
+}
+
+func (p *parser) callonCONCAT_FN1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCONCAT_FN1(stack["s"])
+}
+
+func (c *current) onCONCAT_SEPARATOR1(s interface{}) (interface{}, error) {
+	return s, nil
+}
+
+func (p *parser) callonCONCAT_SEPARATOR1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCONCAT_SEPARATOR1(stack["s"])
+}
+
+func (c *current) onAGGREGATOR1(a interface{}) (interface{}, error) {
+	return newAggregator(string(c.text), nil)
+}
+
+func (p *parser) callonAGGREGATOR1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAGGREGATOR1(stack["a"])
 }
 
 func (c *current) onHEADERS1(h, hs interface{}) (interface{}, error) {
@@ -2750,14 +5194,74 @@ func (p *parser) callonS_MAX_AGE1() (interface{}, error) {
 	return p.cur.onS_MAX_AGE1(stack["t"])
 }
 
-func (c *current) onFLAGS_RULE1(i, is interface{}) (interface{}, error) {
-	return newFlags(i, is)
+func (c *current) onCACHE1(t interface{}) (interface{}, error) {
+	return newCache(t)
+}
+
+func (p *parser) callonCACHE1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCACHE1(stack["t"])
+}
+
+func (c *current) onSLO1(t interface{}) (interface{}, error) {
+	return newSLO(t)
+}
+
+func (p *parser) callonSLO1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSLO1(stack["t"])
+}
+
+func (c *current) onRETURN_HEADERS1(h, hs interface{}) (interface{}, error) {
+	return newReturnHeaders(h, hs)
+}
+
+func (p *parser) callonRETURN_HEADERS1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRETURN_HEADERS1(stack["h"], stack["hs"])
+}
+
+func (c *current) onDEFAULT1(v interface{}) (interface{}, error) {
+	return newDefault(v)
+}
+
+func (p *parser) callonDEFAULT1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDEFAULT1(stack["v"])
+}
+
+func (c *current) onHTTP_METHOD1(m interface{}) (interface{}, error) {
+	return newHTTPMethod(m)
+}
+
+func (p *parser) callonHTTP_METHOD1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onHTTP_METHOD1(stack["m"])
+}
+
+func (c *current) onHTTP_METHOD_NAME1() (interface{}, error) {
+	return stringify(c.text)
+}
+
+func (p *parser) callonHTTP_METHOD_NAME1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onHTTP_METHOD_NAME1()
+}
+
+func (c *current) onFLAGS_RULE1(f, fs interface{}) (interface{}, error) {
+	return newFlags(f, fs)
 }
 
 func (p *parser) callonFLAGS_RULE1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onFLAGS_RULE1(stack["i"], stack["is"])
+	return p.cur.onFLAGS_RULE1(stack["f"], stack["fs"])
 }
 
 func (c *current) onIGNORE_FLAG1() (interface{}, error) {
@@ -2770,6 +5274,26 @@ func (p *parser) callonIGNORE_FLAG1() (interface{}, error) {
 	return p.cur.onIGNORE_FLAG1()
 }
 
+func (c *current) onFILTER_ERRORS_FLAG1() (interface{}, error) {
+	return newFilterErrors()
+}
+
+func (p *parser) callonFILTER_ERRORS_FLAG1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onFILTER_ERRORS_FLAG1()
+}
+
+func (c *current) onNO_CACHE_FLAG1() (interface{}, error) {
+	return newNoCache()
+}
+
+func (p *parser) callonNO_CACHE_FLAG1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onNO_CACHE_FLAG1()
+}
+
 func (c *current) onCHAIN1(i, ii interface{}) (interface{}, error) {
 	return newChain(i, ii)
 }
@@ -2790,6 +5314,16 @@ func (p *parser) callonCHAINED_ITEM1() (interface{}, error) {
 	return p.cur.onCHAINED_ITEM1(stack["ci"])
 }
 
+func (c *current) onCHAIN_SELECTOR1() (interface{}, error) {
+	return stringify(c.text)
+}
+
+func (p *parser) callonCHAIN_SELECTOR1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onCHAIN_SELECTOR1()
+}
+
 func (c *current) onPATH_VARIABLE1(i interface{}) (interface{}, error) {
 	return newChainPathVariable(i)
 }
@@ -3187,6 +5721,10 @@ type parser struct {
 
 	// stats
 	exprCnt int
+
+	// farthest position where a matcher failed, reported
+	// as the position of the error when there is no match
+	maxFailPos position
 }
 
 // push a variable set on the vstack.
@@ -3370,7 +5908,7 @@ func (p *parser) parse(g *grammar) (val interface{}, err error) {
 	if !ok {
 		if len(*p.errs) == 0 {
 			// make sure this doesn't go out silently
-			p.addErr(errNoMatch)
+			p.addErrAt(errNoMatch, p.maxFailPos)
 		}
 		return nil, p.errs.err()
 	}
@@ -3455,12 +5993,26 @@ func (p *parser) parseExpr(expr interface{}) (interface{}, bool) {
 	default:
 		panic(fmt.Sprintf("unknown expression type %T", expr))
 	}
+	if !ok {
+		switch expr.(type) {
+		case *anyMatcher, *charClassMatcher, *litMatcher:
+			p.failAt(p.pt.position)
+		}
+	}
 	if p.memoize {
 		p.setMemoized(pt, expr, resultTuple{val, ok, p.pt})
 	}
 	return val, ok
 }
 
+// failAt records the position of a failed matcher
+// if it is the farthest one reached.
+func (p *parser) failAt(pos position) {
+	if p.maxFailPos.line == 0 || pos.offset > p.maxFailPos.offset {
+		p.maxFailPos = pos
+	}
+}
+
 func (p *parser) parseActionExpr(act *actionExpr) (interface{}, bool) {
 	if p.debug {
 		defer p.out(p.in("parseActionExpr"))
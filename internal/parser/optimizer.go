@@ -1,11 +1,14 @@
 package parser
 
 import (
-	"regexp"
+	"encoding/json"
+	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
 	"github.com/b2wdigital/restQL-golang/v4/internal/parser/ast"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/expr"
 	"github.com/pkg/errors"
 )
 
@@ -20,19 +23,122 @@ func Optimize(queryAst *ast.Query) (domain.Query, error) {
 
 	if queryAst.Use != nil {
 		query.Use = makeUse(queryAst)
+		query.Warnings = validateUse(query.Use)
+	}
+
+	if queryAst.Only != nil {
+		err := validateQueryOnly(queryAst.Only, statements)
+		if err != nil {
+			return domain.Query{}, err
+		}
+
+		only, err := makeOnlyFilter(ast.Qualifier{Only: queryAst.Only})
+		if err != nil {
+			return domain.Query{}, err
+		}
+
+		query.Only = only
 	}
 
 	return query, nil
 }
 
+// integerModifiers are the `use` modifiers ignored
+// at runtime when not given an integer value.
+var integerModifiers = []string{"timeout", "retries", "cache", "slo"}
+
+// stringModifiers are the `use` modifiers ignored
+// at runtime when not given a string value.
+var stringModifiers = []string{"mock", "subscribe"}
+
+// booleanModifiers are the `use` modifiers ignored
+// at runtime when not given a boolean value.
+var booleanModifiers = []string{"strict"}
+
+func validateUse(use domain.Modifiers) []domain.Warning {
+	var warnings []domain.Warning
+	for _, key := range integerModifiers {
+		value, found := use[key]
+		if !found {
+			continue
+		}
+
+		if _, ok := value.(int); !ok {
+			warnings = append(warnings, domain.Warning{
+				Code:    domain.InvalidModifierWarning,
+				Message: fmt.Sprintf("use %s expects an integer value and was ignored", key),
+			})
+		}
+	}
+
+	for _, key := range stringModifiers {
+		value, found := use[key]
+		if !found {
+			continue
+		}
+
+		if _, ok := value.(string); !ok {
+			warnings = append(warnings, domain.Warning{
+				Code:    domain.InvalidModifierWarning,
+				Message: fmt.Sprintf("use %s expects a string value and was ignored", key),
+			})
+		}
+	}
+
+	for _, key := range booleanModifiers {
+		value, found := use[key]
+		if !found {
+			continue
+		}
+
+		if _, ok := value.(bool); !ok {
+			warnings = append(warnings, domain.Warning{
+				Code:    domain.InvalidModifierWarning,
+				Message: fmt.Sprintf("use %s expects a boolean value and was ignored", key),
+			})
+		}
+	}
+
+	return warnings
+}
+
+// validateQueryOnly checks that the fields of the `use only` clause
+// start with the identifier of a statement present in the result, or
+// with `*` for every statement, and that functions are applied to a
+// field of the statement result instead of the whole result.
+func validateQueryOnly(filters []ast.Filter, statements []domain.Statement) error {
+	visible := make(map[string]bool, len(statements))
+	for _, s := range statements {
+		visible[string(domain.NewResourceID(s))] = !s.Hidden
+	}
+
+	for _, f := range filters {
+		target := f.Field[0]
+		if target != "*" && !visible[target] {
+			return errors.Errorf("use only filter %s must start with a returned statement", domain.FormatPath(f.Field))
+		}
+
+		if len(f.Field) == 1 && (f.Match != nil || len(f.Functions) > 0) {
+			return errors.Errorf("filter functions must be applied to a field on filter %s", domain.FormatPath(f.Field))
+		}
+	}
+
+	return nil
+}
+
 func makeUse(queryAst *ast.Query) map[string]interface{} {
 	result := map[string]interface{}{}
 	for _, use := range queryAst.Use {
 		key := strings.Trim(use.Key, " ")
-		if use.Value.String != nil {
+		switch {
+		case use.Value.String != nil:
 			result[key] = *use.Value.String
-		} else {
+		case use.Value.Int != nil:
 			result[key] = *use.Value.Int
+		case use.Value.Bool != nil:
+			result[key] = *use.Value.Bool
+		default:
+			result[key] = true
 		}
 	}
 	return result
@@ -44,7 +150,7 @@ func mapToStatements(fromBlocks []ast.Block) ([]domain.Statement, error) {
 	for i, block := range fromBlocks {
 		statement, err := makeStatement(block)
 		if err != nil {
-			return nil, nil
+			return nil, err
 		}
 
 		result[i] = statement
@@ -60,9 +166,31 @@ func makeStatement(block ast.Block) (domain.Statement, error) {
 		Alias:    block.Alias,
 		In:       block.In,
 	}
+
+	if len(block.Functions) > 0 {
+		s.ResultFunctions = block.Functions
+	}
+
+	if block.Ordered != nil {
+		s.Unordered = !*block.Ordered
+	}
+
+	if block.Join != nil {
+		s.Join = &domain.Join{TargetKey: block.Join.Target, OriginKey: block.Join.Origin}
+	}
+
+	if subquery, ok := domain.ParseSubquery(s.Resource); ok && s.Alias == "" {
+		s.Alias = subquery.ID
+	}
+
 	for _, qualifier := range block.Qualifiers {
 		if qualifier.With != nil {
-			s.With = makeParams(qualifier)
+			with, err := makeParams(qualifier)
+			if err != nil {
+				return domain.Statement{}, err
+			}
+
+			s.With = with
 		}
 
 		if qualifier.Only != nil {
@@ -74,6 +202,15 @@ func makeStatement(block ast.Block) (domain.Statement, error) {
 			s.Only = filter
 		}
 
+		if qualifier.Compute != nil {
+			compute, err := makeComputedFields(qualifier)
+			if err != nil {
+				return domain.Statement{}, err
+			}
+
+			s.Compute = compute
+		}
+
 		if qualifier.Timeout != nil {
 			s.Timeout = makeTimeout(qualifier)
 		}
@@ -92,18 +229,77 @@ func makeStatement(block ast.Block) (domain.Statement, error) {
 			s.CacheControl.SMaxAge = value
 		}
 
+		if qualifier.Cache != nil {
+			s.Cache = *qualifier.Cache
+		}
+
+		if qualifier.SLO != nil {
+			s.SLO = *qualifier.SLO
+		}
+
+		if qualifier.ReturnHeaders != nil {
+			s.ReturnHeaders = qualifier.ReturnHeaders
+		}
+
+		if qualifier.Default != nil {
+			value, err := makeDefault(qualifier)
+			if err != nil {
+				return domain.Statement{}, err
+			}
+
+			s.Default = value
+		}
+
+		if qualifier.HTTPMethod != "" {
+			s.HTTPMethod = qualifier.HTTPMethod
+		}
+
 		s.Hidden = qualifier.Hidden || s.Hidden
 		s.IgnoreErrors = qualifier.IgnoreErrors || s.IgnoreErrors
+		s.FilterErrors = qualifier.FilterErrors || s.FilterErrors
+		s.NoCache = qualifier.NoCache || s.NoCache
 	}
 
 	return s, nil
 }
 
-func makeParams(wq ast.Qualifier) domain.Params {
+func makeComputedFields(cq ast.Qualifier) ([]domain.ComputedField, error) {
+	fields := make([]domain.ComputedField, len(cq.Compute))
+	for i, c := range cq.Compute {
+		fields[i] = domain.ComputedField{Name: c.Name, Path: c.Path, Aggregator: c.Aggregator, Separator: c.Separator}
+
+		if c.Expression != "" {
+			program, variables, err := compileExpression(c.Expression)
+			if err != nil {
+				return nil, errors.Wrapf(err, "expr function argument is invalid on computed field %s", c.Name)
+			}
+
+			fields[i].Expression = program
+			fields[i].Variables = variables
+		}
+	}
+
+	return fields, nil
+}
+
+func makeParams(wq ast.Qualifier) (domain.Params, error) {
 	values := make(map[string]interface{})
 	for _, item := range wq.With.KeyValues {
 		v := getValue(item.Value)
 
+		if item.Default != nil {
+			v = domain.DefaultValue{Value: v, Default: getValue(*item.Default)}
+		}
+
+		if item.Expression != nil {
+			program, variables, err := compileExpression(*item.Expression)
+			if err != nil {
+				return domain.Params{}, errors.Wrapf(err, "expr function argument is invalid on parameter %s", item.Key)
+			}
+
+			v = domain.Expression{Value: v, Program: program, Variables: variables}
+		}
+
 		v = applyFunctions(v, item.Functions)
 
 		values[item.Key] = v
@@ -115,7 +311,7 @@ func makeParams(wq ast.Qualifier) domain.Params {
 
 	parameterBody := wq.With.Body
 	if parameterBody == nil {
-		return p
+		return p, nil
 	}
 
 	var body interface{}
@@ -125,7 +321,23 @@ func makeParams(wq ast.Qualifier) domain.Params {
 
 	p.Body = body
 
-	return p
+	return p, nil
+}
+
+// compileExpression compiles the expression, returning it along
+// with the query variables it references, to be resolved by name.
+func compileExpression(source string) (*expr.Program, map[string]interface{}, error) {
+	program, err := expr.Compile(source)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	variables := make(map[string]interface{})
+	for _, name := range program.Variables() {
+		variables[name] = domain.Variable{Target: name}
+	}
+
+	return program, variables, nil
 }
 
 func applyFunctions(v interface{}, functions []string) interface{} {
@@ -141,6 +353,14 @@ func applyFunctions(v interface{}, functions []string) interface{} {
 			v = domain.JSON{Value: v}
 		case ast.Flatten:
 			v = domain.Flatten{Value: v}
+		case ast.DeepObject:
+			v = domain.DeepObject{Value: v}
+		case ast.CSV:
+			v = domain.Delimited{Value: v, Separator: domain.CSVSeparator}
+		case ast.PipeDelimited:
+			v = domain.Delimited{Value: v, Separator: domain.PipeSeparator}
+		case ast.Repeated:
+			v = domain.Repeated{Value: v}
 		}
 	}
 
@@ -152,32 +372,117 @@ func makeOnlyFilter(onlyQualifier ast.Qualifier) ([]interface{}, error) {
 
 	result := make([]interface{}, len(filters))
 	for i, f := range filters {
+		err := validateListSelectors(f)
+		if err != nil {
+			return nil, err
+		}
+
+		var filter interface{} = f.Field
 		if f.Match != nil {
 			match, err := makeMatchFunction(f)
 			if err != nil {
 				return nil, err
 			}
-			result[i] = match
-		} else {
-			result[i] = f.Field
+			filter = match
 		}
+
+		filter, err = applyFilterFunctions(filter, f)
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = filter
 	}
 
 	return result, nil
 }
 
+func applyFilterFunctions(filter interface{}, f ast.Filter) (interface{}, error) {
+	for _, fn := range f.Functions {
+		switch fn.Name {
+		case ast.FilterByKeys:
+			var keys interface{} = fn.Keys
+			if fn.Variable != nil {
+				keys = domain.Variable{Target: *fn.Variable}
+			}
+			filter = domain.FilterByKeys{Value: filter, Keys: keys}
+		case ast.RenameAs:
+			filter = domain.RenameAs{Value: filter, Name: fn.Alias}
+		case ast.First:
+			filter = domain.First{Value: filter}
+		case ast.Equals, ast.GreaterThan, ast.LessThan, ast.After, ast.Before:
+			arg := getValue(*fn.Arg)
+			if !isValidCompareArg(fn.Name, arg) {
+				return nil, errors.Errorf("%s function argument %v is invalid on filter %s", fn.Name, arg, domain.FormatPath(f.Field))
+			}
+			filter = domain.Compare{Value: filter, Operator: fn.Name, Arg: arg}
+		}
+	}
+
+	return filter, nil
+}
+
+// isValidCompareArg returns false for arguments given directly in
+// the query that the comparison can never match, like a date
+// comparison against a number.
+func isValidCompareArg(operator string, arg interface{}) bool {
+	switch arg.(type) {
+	case domain.Variable, domain.Chain:
+		return true
+	}
+
+	switch operator {
+	case ast.GreaterThan, ast.LessThan:
+		switch arg := arg.(type) {
+		case int, float64:
+			return true
+		case string:
+			_, err := strconv.ParseFloat(arg, 64)
+			return err == nil
+		default:
+			return false
+		}
+	case ast.After, ast.Before:
+		_, ok := domain.ParseDate(arg)
+		return ok
+	default:
+		return true
+	}
+}
+
+func validateListSelectors(f ast.Filter) error {
+	for _, field := range f.Field {
+		if !domain.IsListSelector(field) {
+			continue
+		}
+
+		if _, ok := domain.ParseListSelector(field); !ok {
+			return errors.Errorf("invalid list selector %s on filter %s", field, domain.FormatPath(f.Field))
+		}
+	}
+
+	if (f.Match != nil || len(f.Functions) > 0) && domain.IsListSelector(f.Field[len(f.Field)-1]) {
+		return errors.Errorf("filter functions must be applied to a field on filter %s", domain.FormatPath(f.Field))
+	}
+
+	return nil
+}
+
 func makeMatchFunction(f ast.Filter) (domain.Match, error) {
 	if f.Match.String != nil {
 		arg := *f.Match.String
-		regex, err := regexp.Compile(arg)
+		regex, err := domain.CompileMatch(arg, f.Match.Flags)
 		if err != nil {
-			return domain.Match{}, errors.Wrap(err, "matches function regex argument is invalid")
+			return domain.Match{}, errors.Wrapf(err, "matches function regex argument is invalid on filter %s", domain.FormatPath(f.Field))
 		}
-		return domain.Match{Value: f.Field, Arg: regex}, nil
+		return domain.Match{Value: f.Field, Arg: regex, Flags: f.Match.Flags}, nil
 	}
 
 	if f.Match.Variable != nil {
-		return domain.Match{Value: f.Field, Arg: domain.Variable{Target: *f.Match.Variable}}, nil
+		if _, err := domain.CompileMatch("", f.Match.Flags); err != nil {
+			return domain.Match{}, errors.Wrapf(err, "matches function flags are invalid on filter %s", domain.FormatPath(f.Field))
+		}
+		return domain.Match{Value: f.Field, Arg: domain.Variable{Target: *f.Match.Variable}, Flags: f.Match.Flags}, nil
 	}
 
 	return domain.Match{}, errors.New("no argument provided to matches functions")
@@ -216,6 +521,10 @@ func makeTimeout(qualifier ast.Qualifier) interface{} {
 		return domain.Variable{Target: *v.Variable}
 	}
 
+	if v.Chain != nil {
+		return makeChain(v.Chain)
+	}
+
 	return nil
 }
 
@@ -229,6 +538,10 @@ func makeSMaxAge(qualifier ast.Qualifier) interface{} {
 		return domain.Variable{Target: *v.Variable}
 	}
 
+	if v.Chain != nil {
+		return makeChain(v.Chain)
+	}
+
 	return nil
 }
 
@@ -242,9 +555,43 @@ func makeMaxAge(qualifier ast.Qualifier) interface{} {
 		return domain.Variable{Target: *v.Variable}
 	}
 
+	if v.Chain != nil {
+		return makeChain(v.Chain)
+	}
+
 	return nil
 }
 
+func makeDefault(qualifier ast.Qualifier) ([]byte, error) {
+	value := getValue(*qualifier.Default)
+	if !isStaticValue(value) {
+		return nil, errors.New("default value must not use variables, chained values or ranges")
+	}
+
+	return json.Marshal(value)
+}
+
+func isStaticValue(value interface{}) bool {
+	switch value := value.(type) {
+	case domain.Variable, domain.Chain, domain.Range:
+		return false
+	case []interface{}:
+		for _, v := range value {
+			if !isStaticValue(v) {
+				return false
+			}
+		}
+	case map[string]interface{}:
+		for _, v := range value {
+			if !isStaticValue(v) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
 func getValue(value ast.Value) interface{} {
 	if value.Variable != nil {
 		return domain.Variable{Target: *value.Variable}
@@ -254,6 +601,10 @@ func getValue(value ast.Value) interface{} {
 		return getPrimitive(value.Primitive)
 	}
 
+	if value.Range != nil {
+		return makeRange(value.Range)
+	}
+
 	if value.List != nil {
 		result := make([]interface{}, len(value.List))
 		for i, v := range value.List {
@@ -270,6 +621,31 @@ func getValue(value ast.Value) interface{} {
 	return nil
 }
 
+func makeRange(r *ast.Range) domain.Range {
+	result := domain.Range{Start: getRangeArg(r.Start), End: getRangeArg(r.End), Step: 1}
+	if r.Step != nil {
+		result.Step = getRangeArg(*r.Step)
+	}
+
+	return result
+}
+
+func getRangeArg(arg ast.RangeArg) interface{} {
+	if arg.Int != nil {
+		return *arg.Int
+	}
+
+	if arg.Variable != nil {
+		return domain.Variable{Target: *arg.Variable}
+	}
+
+	if arg.Chain != nil {
+		return makeChain(arg.Chain)
+	}
+
+	return nil
+}
+
 func getMap(entries []ast.ObjectEntry) map[string]interface{} {
 	result := map[string]interface{}{}
 
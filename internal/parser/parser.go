@@ -37,3 +37,9 @@ func (p parser) Parse(queryStr string) (domain.Query, error) {
 
 	return Optimize(query)
 }
+
+// ErrorPosition returns the line and column of the query
+// where the syntax error returned by Parse was found.
+func ErrorPosition(err error) (line int, column int, ok bool) {
+	return ast.SyntaxPosition(err)
+}
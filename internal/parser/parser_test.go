@@ -66,11 +66,53 @@ func TestQueryParser(t *testing.T) {
 			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{"id": domain.Chain{"done-resource", domain.Variable{"field"}, "id"}}}}}},
 			"from hero with id = done-resource.$field.id",
 		},
+		{
+			"Unique from statement and chained with list selectors",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{
+				"ids":   domain.Chain{"products", "items", "[?(@.active==true)]", "id"},
+				"names": domain.Chain{"products", "items", `[?( @.tag != "old" )]`, "name"},
+				"first": domain.Chain{"products", "items", "[0]", "id"},
+				"last":  domain.Chain{"products", "items", "[-2:]", "id"},
+			}}}}},
+			`from hero with ids = products.items[?(@.active==true)].id, names = products.items[?( @.tag != "old" )].name, first = products.items[0].id, last = products.items[-2:].id`,
+		},
+		{
+			"Unique from statement and only filters with list selectors",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Only: []interface{}{[]string{"weapons", "[0]", "id"}, []string{"[-1]"}}}}},
+			"from hero only weapons[0].id, [-1]",
+		},
+		{
+			"Unique from statement and only filters with functions",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Only: []interface{}{
+				domain.FilterByKeys{Value: []string{"skills"}, Keys: domain.Variable{Target: "fields"}},
+				domain.RenameAs{Value: domain.First{Value: domain.Match{Value: []string{"weapons"}, Arg: regexp.MustCompile("^bat")}}, Name: "weapon"},
+			}}}},
+			`from hero only skills -> filterByKeys($fields), weapons -> matches("^bat") -> first -> renameAs("weapon")`,
+		},
+		{
+			"Unique from statement and only filters with comparisons",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "product", Only: []interface{}{
+				domain.Compare{Value: []string{"price"}, Operator: domain.CompareGreaterThan, Arg: 100},
+				domain.Compare{Value: []string{"status"}, Operator: domain.CompareEquals, Arg: "active"},
+				domain.First{Value: domain.Compare{Value: []string{"offers", "date"}, Operator: domain.CompareAfter, Arg: domain.Variable{Target: "since"}}},
+				domain.Compare{Value: []string{"stock"}, Operator: domain.CompareLessThan, Arg: domain.Chain{"limits", "stock"}},
+				domain.Compare{Value: []string{"releasedAt"}, Operator: domain.CompareBefore, Arg: "2020-01-02"},
+			}}}},
+			`from product only price -> greaterThan(100), status -> equals("active"), offers.date -> after($since) -> first, stock -> lessThan(limits.stock), releasedAt -> before("2020-01-02")`,
+		},
 		{
 			"Unique from statement and only filters",
 			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Only: []interface{}{[]string{"name"}, []string{"weapons"}}}}},
 			"from hero only name, weapons",
 		},
+		{
+			"Unique from statement and computed fields",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Only: []interface{}{[]string{"name"}, []string{"items", "price"}}, Compute: []domain.ComputedField{
+				{Name: "total", Path: []string{"items", "price"}, Aggregator: domain.SumAggregator},
+				{Name: "names", Path: []string{"sidekick", "name"}, Aggregator: domain.ConcatAggregator, Separator: "/"},
+			}}}},
+			`from hero only name, items.price compute total = items.price -> sum, names = sidekick.name -> concat("/")`,
+		},
 		{
 			"Unique from statement and hidden filter",
 			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Hidden: true}}},
@@ -81,6 +123,36 @@ func TestQueryParser(t *testing.T) {
 			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", IgnoreErrors: true}}},
 			"from hero ignore-errors",
 		},
+		{
+			"Unique from statement and filter errors flag",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Only: []interface{}{[]string{"name"}}, FilterErrors: true}}},
+			"from hero only name filter-errors",
+		},
+		{
+			"Unique from statement and cache",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Cache: 60, IgnoreErrors: true}}},
+			"from hero cache 60 ignore-errors",
+		},
+		{
+			"Unique from statement and slo",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Timeout: 500, SLO: 300}}},
+			"from hero timeout 500 slo 300",
+		},
+		{
+			"Unique from statement and return headers",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", ReturnHeaders: []string{"X-Total-Count", "Link"}, IgnoreErrors: true}}},
+			"from hero return-headers X-Total-Count, Link ignore-errors",
+		},
+		{
+			"Unique from statement and no cache flag",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Only: []interface{}{[]string{"name"}}, NoCache: true}}},
+			"from hero only name no-cache",
+		},
+		{
+			"Unique from statement and default value",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Default: []byte(`{"name":"unknown","powers":[],"rank":1}`), IgnoreErrors: true}}},
+			`from hero default { name: "unknown", rank: 1, powers: [] } ignore-errors`,
+		},
 		{
 			"Unique from statement and fixed timeout",
 			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Timeout: 2000}}},
@@ -116,6 +188,11 @@ func TestQueryParser(t *testing.T) {
 			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", CacheControl: domain.CacheControl{MaxAge: domain.Variable{"maxAge"}, SMaxAge: domain.Variable{"sMaxAge"}}}}},
 			"from hero max-age $maxAge s-max-age $sMaxAge",
 		},
+		{
+			"Unique from statement and chained timeout and max age",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Timeout: domain.Chain{"config", "timeout"}, CacheControl: domain.CacheControl{MaxAge: domain.Chain{"config", "cache", domain.Variable{"kind"}}, SMaxAge: 600}}}},
+			"from hero timeout config.timeout max-age config.cache.$kind s-max-age 600",
+		},
 		{
 			"Unique from statement and flattened list parameters",
 			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{"id": domain.NoMultiplex{[]interface{}{1, 2}}}}}}},
@@ -146,6 +223,24 @@ func TestQueryParser(t *testing.T) {
 			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{"id": domain.Flatten{[]interface{}{[]interface{}{1}, []interface{}{2}, []interface{}{3}}}}}}}},
 			`from hero with id = [[1], [2], [3]] -> flatten`,
 		},
+		{
+			"Statements with custom http method",
+			domain.Query{Statements: []domain.Statement{
+				{Method: "to", HTTPMethod: "PURGE", Resource: "cache", With: domain.Params{Values: map[string]interface{}{"id": 1}}},
+				{Method: "from", HTTPMethod: "REPORT", Resource: "hero", Timeout: 100},
+			}},
+			"to cache method purge with id = 1\nfrom hero timeout 100 method REPORT",
+		},
+		{
+			"Unique from statement and parameters with query string encoders",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{
+				"filter": domain.DeepObject{Value: map[string]interface{}{"color": "red"}},
+				"ids":    domain.Delimited{Value: []interface{}{1, 2}, Separator: domain.CSVSeparator},
+				"tags":   domain.Delimited{Value: []interface{}{"a", "b"}, Separator: domain.PipeSeparator},
+				"names":  domain.Repeated{Value: []interface{}{"batman", "robin"}},
+			}}}}},
+			`from hero with filter = {color: "red"} -> deep-object, ids = [1, 2] -> csv, tags = ["a", "b"] -> pipe-delimited, names = ["batman", "robin"] -> repeated`,
+		},
 		{
 			"Unique to statement with default body value and custom parameter",
 			domain.Query{Statements: []domain.Statement{{Method: "to", Resource: "hero", With: domain.Params{Body: domain.Variable{"hero"}, Values: map[string]interface{}{"name": "batman"}}}}},
@@ -166,6 +261,58 @@ func TestQueryParser(t *testing.T) {
 			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Only: []interface{}{domain.Match{Value: []string{"name"}, Arg: domain.Variable{Target: "heroName"}}, []string{"weapons"}}}}},
 			`from hero only name -> matches($heroName), weapons`,
 		},
+		{
+			"Unique from statement and only filters with match function flags",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", Only: []interface{}{domain.Match{Value: []string{"name"}, Arg: regexp.MustCompile("(?i)^super"), Flags: "i"}, domain.Match{Value: []string{"city"}, Arg: domain.Variable{Target: "city"}, Flags: "iu"}}}}},
+			`from hero only name -> matches( "^super", "i" ), city -> matches($city, "iu")`,
+		},
+		{
+			"Unique from statement with default values in with",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{
+				"page": domain.DefaultValue{Value: domain.Variable{Target: "page"}, Default: 1},
+				"ids":  domain.NoMultiplex{Value: domain.DefaultValue{Value: domain.Chain{"team", "heroes"}, Default: []interface{}{"1", "2"}}},
+				"sort": domain.DefaultValue{Value: domain.Variable{Target: "sort"}, Default: domain.Variable{Target: "order"}},
+			}}}}},
+			`from hero with page = $page -> default(1), ids = team.heroes -> default(["1", "2"]) -> no-multiplex, sort = $sort -> default($order)`,
+		},
+		{
+			"Unique from statement with expressions in with and compute",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"name": domain.Base64{Value: domain.Expression{Value: domain.Chain{"product", "title"}, Program: test.Program("lower(value) + '-' + $suffix"), Variables: map[string]interface{}{"suffix": domain.Variable{Target: "suffix"}}}},
+					"page": domain.Expression{Value: domain.DefaultValue{Value: domain.Variable{Target: "page"}, Default: 1}, Program: test.Program("value - 1"), Variables: map[string]interface{}{}},
+				}},
+				Compute: []domain.ComputedField{
+					{Name: "total", Path: []string{"items", "price"}, Aggregator: domain.SumAggregator, Expression: test.Program("round(value, 2)"), Variables: map[string]interface{}{}},
+				},
+			}}},
+			`from hero with name = product.title -> expr("lower(value) + '-' + $suffix") -> base64, page = $page -> default(1) -> expr("value - 1") compute total = items.price -> sum -> expr("round(value, 2)")`,
+		},
+		{
+			"Unique from statement with result functions",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "products", ResultFunctions: []string{domain.FlattenResult, domain.DistinctResult}}}},
+			"from products -> flatten -> distinct",
+		},
+		{
+			"Unique from statement with unordered multiplexed results",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", ResultFunctions: []string{domain.FlattenResult}, Unordered: true, With: domain.Params{Values: map[string]interface{}{"id": []interface{}{1, 2}}}}}},
+			"from hero -> ordered(false) -> flatten with id = [1, 2]",
+		},
+		{
+			"Unique from statement with ordered multiplexed results",
+			domain.Query{Statements: []domain.Statement{{Method: "from", Resource: "hero", With: domain.Params{Values: map[string]interface{}{"id": []interface{}{1, 2}}}}}},
+			"from hero -> ordered(true) with id = [1, 2]",
+		},
+		{
+			"Unique from statement with aggregation joined by key",
+			domain.Query{Statements: []domain.Statement{
+				{Method: "from", Resource: "cart"},
+				{Method: "from", Resource: "product", In: []string{"cart", "items", "product"}, Join: &domain.Join{TargetKey: []string{"productId"}, OriginKey: []string{"id"}}},
+			}},
+			`
+					from cart
+					from product in cart.items.product on productId = id`,
+		},
 		{
 			"Unique from statement with aggregation",
 			domain.Query{Statements: []domain.Statement{
@@ -177,6 +324,128 @@ func TestQueryParser(t *testing.T) {
 					from sidekick in hero.sidekick
 			`,
 		},
+		{
+			"Query with retries modifier",
+			domain.Query{
+				Use:        map[string]interface{}{"retries": 2},
+				Statements: []domain.Statement{{Method: "from", Resource: "hero"}},
+			},
+			`use retries 2
+				from hero`,
+		},
+		{
+			"Query with slo modifier",
+			domain.Query{
+				Use:        map[string]interface{}{"slo": 300},
+				Statements: []domain.Statement{{Method: "from", Resource: "hero", SLO: 100}},
+			},
+			`use slo 300
+				from hero slo 100`,
+		},
+		{
+			"Query with only modifier",
+			domain.Query{
+				Use:  map[string]interface{}{"timeout": 500},
+				Only: []interface{}{[]string{"cart", "items", "product", "name"}, []string{"cart", "id"}},
+				Statements: []domain.Statement{
+					{Method: "from", Resource: "cart"},
+					{Method: "from", Resource: "product", In: []string{"cart", "items", "product"}, Hidden: true},
+				},
+			},
+			`use only cart.items.product.name,
+					cart.id
+				use timeout 500
+				from cart
+				from product in cart.items.product hidden`,
+		},
+		{
+			"Query with mock modifier",
+			domain.Query{
+				Use:        map[string]interface{}{"mock": "hero, sidekick"},
+				Statements: []domain.Statement{{Method: "from", Resource: "hero"}},
+			},
+			`use mock "hero, sidekick"
+				from hero`,
+		},
+		{
+			"Query with subscribe modifier",
+			domain.Query{
+				Use:        map[string]interface{}{"subscribe": "quotes"},
+				Statements: []domain.Statement{{Method: "from", Resource: "quotes"}},
+			},
+			`use subscribe "quotes"
+				from quotes`,
+		},
+		{
+			"Query with strict modifier",
+			domain.Query{
+				Use:        map[string]interface{}{"strict": true},
+				Statements: []domain.Statement{{Method: "from", Resource: "hero"}},
+			},
+			`use strict
+				from hero`,
+		},
+		{
+			"Query with strict modifier disabled",
+			domain.Query{
+				Use:        map[string]interface{}{"strict": false, "retries": 2},
+				Statements: []domain.Statement{{Method: "from", Resource: "hero"}},
+			},
+			`use strict false
+				use retries 2
+				from hero`,
+		},
+		{
+			"Query with warning for strict modifier without boolean value",
+			domain.Query{
+				Use:        map[string]interface{}{"strict": "yes"},
+				Statements: []domain.Statement{{Method: "from", Resource: "hero"}},
+				Warnings:   []domain.Warning{{Code: domain.InvalidModifierWarning, Message: "use strict expects a boolean value and was ignored"}},
+			},
+			`use strict "yes"
+				from hero`,
+		},
+		{
+			"Query with warning for modifier ignored at runtime",
+			domain.Query{
+				Use:        map[string]interface{}{"timeout": "1s"},
+				Statements: []domain.Statement{{Method: "from", Resource: "hero"}},
+				Warnings:   []domain.Warning{{Code: domain.InvalidModifierWarning, Message: "use timeout expects an integer value and was ignored"}},
+			},
+			`use timeout "1s"
+				from hero`,
+		},
+		{
+			"Query with range parameter",
+			domain.Query{Statements: []domain.Statement{{
+				Method:   "from",
+				Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"page":  domain.Range{Start: 1, End: domain.Chain{"first", "totalPages"}, Step: 1},
+					"index": domain.Range{Start: domain.Variable{Target: "from"}, End: 10, Step: 2},
+				}},
+			}}},
+			`from hero with page = range(1, first.totalPages), index = range($from, 10, 2)`,
+		},
+		{
+			"Query with subquery statement",
+			domain.Query{Statements: []domain.Statement{{
+				Method:   "from",
+				Resource: "query:catalog/product-detail",
+				Alias:    "product-detail",
+				With:     domain.Params{Values: map[string]interface{}{"id": domain.Variable{Target: "id"}}},
+			}}},
+			`from query:catalog/product-detail with id = $id`,
+		},
+		{
+			"Query with subquery statement pinned to revision",
+			domain.Query{Statements: []domain.Statement{{
+				Method:   "from",
+				Resource: "query:catalog/product-detail/2",
+				Alias:    "detail",
+			}}},
+			`from query:catalog/product-detail/2 as detail`,
+		},
 		{
 			"Full query",
 			domain.Query{
@@ -262,6 +531,137 @@ func TestQueryParser(t *testing.T) {
 	}
 }
 
+func TestQueryParserInvalidMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			"invalid regex",
+			`from hero only name -> matches("^(super")`,
+			"matches function regex argument is invalid on filter name: error parsing regexp: missing closing ): `^(super`",
+		},
+		{
+			"unknown flag on regex",
+			`from hero only name -> matches("^super", "ix")`,
+			"matches function regex argument is invalid on filter name: unknown flag 'x', must be one of i, m, s or u",
+		},
+		{
+			"unknown flag on variable",
+			`from hero only address.city -> matches($city, "g")`,
+			"matches function flags are invalid on filter address.city: unknown flag 'g', must be one of i, m, s or u",
+		},
+	}
+
+	queryParser, err := parser.New()
+	test.VerifyError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := queryParser.Parse(tt.query)
+			if err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			test.Equal(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestQueryParserInvalidExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			"unknown function in with",
+			`from hero with name = $name -> expr("capitalize(value)")`,
+			"expr function argument is invalid on parameter name: invalid expression: unknown identifier capitalize at position 0",
+		},
+		{
+			"wrong number of arguments in compute",
+			`from hero compute total = items.price -> sum -> expr("round()")`,
+			"expr function argument is invalid on computed field total: invalid expression: wrong number of arguments for round at position 0",
+		},
+		{
+			"unterminated expression",
+			`from hero with name = $name -> expr("lower(value")`,
+			"expr function argument is invalid on parameter name: invalid expression: expected \",\" or \")\" but found end of expression at position 11",
+		},
+	}
+
+	queryParser, err := parser.New()
+	test.VerifyError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := queryParser.Parse(tt.query)
+			if err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			test.Equal(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestQueryParserInvalidDefault(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"variable default", `from hero default $fallback`},
+		{"chained value in default", `from hero default { name: sidekick.name }`},
+		{"range in default", `from hero default [range(1, 3)]`},
+	}
+
+	queryParser, err := parser.New()
+	test.VerifyError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := queryParser.Parse(tt.query)
+			if err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			test.Equal(t, err.Error(), "default value must not use variables, chained values or ranges")
+		})
+	}
+}
+
+func TestQueryParserInvalidListSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"empty selector", `from hero only weapons[].id`, "invalid list selector [] on filter weapons[].id"},
+		{"selector with three bounds", `from hero only weapons[1:2:3]`, "invalid list selector [1:2:3] on filter weapons[1:2:3]"},
+		{"selector with bare sign", `from hero only weapons[-]`, "invalid list selector [-] on filter weapons[-]"},
+		{"matches on selector", `from hero only weapons[0] -> matches("^bat")`, "filter functions must be applied to a field on filter weapons[0]"},
+		{"date comparison with number", `from hero only birth -> after(2020)`, "after function argument 2020 is invalid on filter birth"},
+		{"date comparison with invalid date", `from hero only birth -> before("yesterday")`, "before function argument yesterday is invalid on filter birth"},
+		{"numeric comparison with text", `from hero only age -> greaterThan("old")`, "greaterThan function argument old is invalid on filter age"},
+		{"numeric comparison with boolean", `from hero only age -> lessThan(true)`, "lessThan function argument true is invalid on filter age"},
+		{"query filter on unknown statement", "use only villain.name\nfrom hero", "use only filter villain.name must start with a returned statement"},
+		{"query filter on hidden statement", "use only hero.name\nfrom hero hidden", "use only filter hero.name must start with a returned statement"},
+		{"query filter function on statement", "use only hero -> matches(\"^bat\")\nfrom hero", "filter functions must be applied to a field on filter hero"},
+	}
+
+	queryParser, err := parser.New()
+	test.VerifyError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := queryParser.Parse(tt.query)
+			if err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			test.Equal(t, err.Error(), tt.expected)
+		})
+	}
+}
+
 func BenchmarkParse(b *testing.B) {
 	query := `
 from hero as h
@@ -0,0 +1,236 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+// Actions applied to the query parameters captured by the audit log.
+const (
+	CaptureParam = "capture"
+	RedactParam  = "redact"
+	HashParam    = "hash"
+	DropParam    = "drop"
+)
+
+const (
+	redactedValue         = "[REDACTED]"
+	defaultAuditTimeout   = 5 * time.Second
+	defaultAuditQueueSize = 1000
+)
+
+// Record is the audit entry of a query execution.
+type Record struct {
+	At         time.Time                  `json:"at"`
+	RequestID  string                     `json:"requestId,omitempty"`
+	Principal  string                     `json:"principal,omitempty"`
+	Tenant     string                     `json:"tenant"`
+	Namespace  string                     `json:"namespace,omitempty"`
+	Query      string                     `json:"query,omitempty"`
+	Revision   int                        `json:"revision,omitempty"`
+	Params     map[string]interface{}     `json:"params,omitempty"`
+	Resources  map[string]ResourceOutcome `json:"resources"`
+	DurationMs float64                    `json:"durationMs"`
+	Error      string                     `json:"error,omitempty"`
+}
+
+// ResourceOutcome is the result of a statement of the audited query,
+// which succeeded when its upstream answered with a status below 400.
+type ResourceOutcome struct {
+	Status  int  `json:"status"`
+	Success bool `json:"success"`
+}
+
+// Sink writes the audit records.
+type Sink interface {
+	Write(record Record) error
+}
+
+// Redactor applies the capture rules to the query parameters.
+type Redactor struct {
+	defaultAction string
+	rules         map[string]string
+}
+
+// NewRedactor constructs a Redactor from the parameters configuration,
+// where rules match the parameter names regardless of case.
+func NewRedactor(cfg conf.AuditParamsConf) (Redactor, error) {
+	r := Redactor{defaultAction: CaptureParam, rules: make(map[string]string, len(cfg.Rules))}
+	if cfg.Default != "" {
+		if !isAction(cfg.Default) {
+			return Redactor{}, errors.Errorf("unknown default params action %q", cfg.Default)
+		}
+		r.defaultAction = cfg.Default
+	}
+
+	for name, action := range cfg.Rules {
+		if !isAction(action) {
+			return Redactor{}, errors.Errorf("unknown action %q of param %s", action, name)
+		}
+		r.rules[strings.ToLower(name)] = action
+	}
+
+	return r, nil
+}
+
+// Apply returns the parameters to be recorded.
+func (r Redactor) Apply(params map[string]interface{}) map[string]interface{} {
+	if len(params) == 0 {
+		return nil
+	}
+
+	result := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		action, found := r.rules[strings.ToLower(name)]
+		if !found {
+			action = r.defaultAction
+		}
+
+		switch action {
+		case CaptureParam:
+			result[name] = value
+		case RedactParam:
+			result[name] = redactedValue
+		case HashParam:
+			result[name] = hashValue(value)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func isAction(action string) bool {
+	switch action {
+	case CaptureParam, RedactParam, HashParam, DropParam:
+		return true
+	default:
+		return false
+	}
+}
+
+// hashValue returns the hex encoded SHA-256 of the string, or the JSON
+// encoded value, so the same values can be correlated without being
+// disclosed.
+func hashValue(value interface{}) string {
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	default:
+		var err error
+		if data, err = json.Marshal(value); err != nil {
+			data = []byte(fmt.Sprint(value))
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Auditor records every query execution on its sink. Records are
+// queued and written in the background, hence query executions are
+// never delayed by the sink, and the ones not fitting the queue are
+// dropped.
+type Auditor struct {
+	log       restql.Logger
+	sink      Sink
+	redactor  Redactor
+	savedOnly bool
+	queue     chan Record
+}
+
+// New constructs an Auditor writing to the configured sink
+// and starts writing its records.
+func New(log restql.Logger, cfg conf.AuditConf, kafka conf.KafkaConf) (*Auditor, error) {
+	redactor, err := NewRedactor(cfg.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	sink, err := newSink(cfg, kafka)
+	if err != nil {
+		return nil, err
+	}
+
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultAuditQueueSize
+	}
+
+	a := &Auditor{
+		log:       log,
+		sink:      sink,
+		redactor:  redactor,
+		savedOnly: cfg.SavedOnly,
+		queue:     make(chan Record, queueSize),
+	}
+	go a.run()
+
+	return a, nil
+}
+
+// Handler returns the event handler queueing
+// the record of every query execution.
+func (a *Auditor) Handler() restql.EventHandler {
+	return func(ctx context.Context, event restql.Event) {
+		finished, ok := event.(restql.QueryFinishedEvent)
+		if !ok || (a.savedOnly && finished.Query == "") {
+			return
+		}
+
+		select {
+		case a.queue <- a.NewRecord(ctx, finished):
+		default:
+			a.log.Warn("audit queue is full, dropping record", "tenant", finished.Tenant, "namespace", finished.Namespace, "query", finished.Query)
+		}
+	}
+}
+
+// NewRecord builds the audit record of the query execution.
+func (a *Auditor) NewRecord(ctx context.Context, event restql.QueryFinishedEvent) Record {
+	record := Record{
+		At:         time.Now(),
+		Tenant:     event.Tenant,
+		Namespace:  event.Namespace,
+		Query:      event.Query,
+		Revision:   event.Revision,
+		Params:     a.redactor.Apply(event.Params),
+		Resources:  make(map[string]ResourceOutcome, len(event.Statuses)),
+		DurationMs: float64(event.Duration.Microseconds()) / 1000,
+	}
+
+	for resource, status := range event.Statuses {
+		record.Resources[resource] = ResourceOutcome{Status: status, Success: status > 0 && status < 400}
+	}
+	if event.Err != nil {
+		record.Error = event.Err.Error()
+	}
+	if requestID, ok := restql.RequestID(ctx); ok {
+		record.RequestID = requestID
+	}
+	if principal, ok := restql.Principal(ctx); ok {
+		record.Principal = principal
+	}
+
+	return record
+}
+
+func (a *Auditor) run() {
+	for record := range a.queue {
+		if err := a.sink.Write(record); err != nil {
+			a.log.Warn("failed to write audit record", "error", err, "tenant", record.Tenant, "namespace", record.Namespace, "query", record.Query)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package audit_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/audit"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestRedactor(t *testing.T) {
+	redactor, err := audit.NewRedactor(conf.AuditParamsConf{
+		Rules: map[string]string{"password": "drop", "Email": "redact", "document": "hash"},
+	})
+	test.VerifyError(t, err)
+
+	got := redactor.Apply(map[string]interface{}{
+		"id":       1,
+		"password": "s3cr3t",
+		"email":    "bruce@wayne.com",
+		"document": "123",
+	})
+
+	test.Equal(t, got, map[string]interface{}{
+		"id":       1,
+		"email":    "[REDACTED]",
+		"document": "a665a45920422f9d417e4867efdc4fb8a04a1f3fff1fa07e998e86f7f7a27ae3",
+	})
+
+	redactor, err = audit.NewRedactor(conf.AuditParamsConf{Default: "drop", Rules: map[string]string{"id": "capture"}})
+	test.VerifyError(t, err)
+
+	test.Equal(t, redactor.Apply(map[string]interface{}{"id": 1, "name": "batman"}), map[string]interface{}{"id": 1})
+
+	_, err = audit.NewRedactor(conf.AuditParamsConf{Rules: map[string]string{"id": "mask"}})
+	test.Equal(t, err != nil, true)
+}
+
+func TestAuditorFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "restql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	auditor, err := audit.New(test.NoOpLogger, conf.AuditConf{
+		Sink:      audit.FileSink,
+		Path:      path,
+		SavedOnly: true,
+		Params:    conf.AuditParamsConf{Rules: map[string]string{"token": "redact"}},
+	}, conf.KafkaConf{})
+	test.VerifyError(t, err)
+	handler := auditor.Handler()
+
+	ctx := restql.WithPrincipal(restql.WithRequestID(context.Background(), "abc-123"), "catalog")
+	handler(ctx, restql.QueryFinishedEvent{Tenant: "DC", Statuses: map[string]int{"hero": 200}})
+	handler(ctx, restql.QueryFinishedEvent{
+		Tenant:    "DC",
+		Namespace: "heroes",
+		Query:     "get-hero",
+		Revision:  2,
+		Params:    map[string]interface{}{"id": "1", "token": "s3cr3t"},
+		Statuses:  map[string]int{"hero": 200, "sidekick": 0},
+		Duration:  1500 * time.Microsecond,
+		Err:       errors.New("query timed out"),
+	})
+
+	var records []audit.Record
+	for i := 0; i < 20 && len(records) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		records = readRecords(t, path)
+	}
+
+	test.Equal(t, len(records), 1)
+	record := records[0]
+	record.At = time.Time{}
+	test.Equal(t, record, audit.Record{
+		RequestID:  "abc-123",
+		Principal:  "catalog",
+		Tenant:     "DC",
+		Namespace:  "heroes",
+		Query:      "get-hero",
+		Revision:   2,
+		Params:     map[string]interface{}{"id": "1", "token": "[REDACTED]"},
+		Resources:  map[string]audit.ResourceOutcome{"hero": {Status: 200, Success: true}, "sidekick": {Status: 0, Success: false}},
+		DurationMs: 1.5,
+		Error:      "query timed out",
+	})
+}
+
+func TestAuditorKafkaSink(t *testing.T) {
+	received := make(chan map[string][]map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		test.Equal(t, r.URL.Path, "/topics/audit")
+		test.Equal(t, r.Header.Get("Content-Type"), "application/vnd.kafka.json.v2+json")
+
+		var body map[string][]map[string]interface{}
+		test.VerifyError(t, json.NewDecoder(r.Body).Decode(&body))
+		received <- body
+	}))
+	defer server.Close()
+
+	auditor, err := audit.New(test.NoOpLogger, conf.AuditConf{Sink: audit.KafkaSink, Topic: "audit"}, conf.KafkaConf{RestProxy: server.URL})
+	test.VerifyError(t, err)
+
+	auditor.Handler()(context.Background(), restql.QueryFinishedEvent{Tenant: "DC", Namespace: "heroes", Query: "get-hero", Revision: 1})
+
+	select {
+	case body := <-received:
+		test.Equal(t, len(body["records"]), 1)
+		test.Equal(t, body["records"][0]["key"], "DC")
+	case <-time.After(time.Second):
+		t.Fatal("audit record not received")
+	}
+}
+
+func readRecords(t *testing.T, path string) []audit.Record {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var records []audit.Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r audit.Record
+		test.VerifyError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+
+	return records
+}
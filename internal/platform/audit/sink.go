@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/pkg/errors"
+)
+
+// Types of the audit sinks.
+const (
+	FileSink  = "file"
+	HTTPSink  = "http"
+	KafkaSink = "kafka"
+)
+
+const (
+	kafkaContentType = "application/vnd.kafka.json.v2+json"
+	kafkaAccept      = "application/vnd.kafka.v2+json"
+)
+
+func newSink(cfg conf.AuditConf, kafka conf.KafkaConf) (Sink, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultAuditTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+
+	switch cfg.Sink {
+	case FileSink:
+		if cfg.Path == "" {
+			return nil, errors.New("audit file sink requires a path")
+		}
+
+		f, err := os.OpenFile(cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open audit file")
+		}
+
+		return fileSink{file: f}, nil
+	case HTTPSink:
+		if cfg.URL == "" {
+			return nil, errors.New("audit http sink requires an url")
+		}
+
+		return httpSink{url: cfg.URL, headers: cfg.Headers, client: client}, nil
+	case KafkaSink:
+		if cfg.Topic == "" {
+			return nil, errors.New("audit kafka sink requires a topic")
+		}
+		if kafka.RestProxy == "" {
+			return nil, errors.New("audit kafka sink requires the kafka rest proxy")
+		}
+
+		target := strings.TrimRight(kafka.RestProxy, "/") + "/topics/" + url.PathEscape(cfg.Topic)
+		return httpSink{url: target, headers: cfg.Headers, client: client, kafka: true}, nil
+	default:
+		return nil, errors.Errorf("unknown audit sink %q", cfg.Sink)
+	}
+}
+
+// fileSink appends the records to a file as JSON lines.
+type fileSink struct {
+	file *os.File
+}
+
+func (s fileSink) Write(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// httpSink posts each record to an endpoint, or produces it
+// to a Kafka topic through the REST proxy, keyed by tenant.
+type httpSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+	kafka   bool
+}
+
+func (s httpSink) Write(record Record) error {
+	var payload interface{} = record
+	contentType := "application/json"
+	if s.kafka {
+		payload = map[string]interface{}{
+			"records": []map[string]interface{}{{"key": record.Tenant, "value": record}},
+		}
+		contentType = kafkaContentType
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if s.kafka {
+		req.Header.Set("Accept", kafkaAccept)
+	}
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("audit sink responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package bulkhead
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+)
+
+// bulkheadMetrics holds the saturation of every compartment,
+// exposed by the expvar handler.
+var bulkheadMetrics = expvar.NewMap("bulkhead")
+
+// Rule represents how many requests can be in flight, and how
+// many can wait for a slot during up to MaxWait, when not zero.
+type Rule struct {
+	MaxConcurrent int
+	MaxQueue      int
+	MaxWait       time.Duration
+}
+
+func newRule(c conf.BulkheadRuleConf) (Rule, bool) {
+	if c.MaxConcurrent <= 0 {
+		return Rule{}, false
+	}
+
+	queue := c.MaxQueue
+	if queue < 0 {
+		queue = 0
+	}
+
+	return Rule{MaxConcurrent: c.MaxConcurrent, MaxQueue: queue, MaxWait: c.MaxWait}, true
+}
+
+// Stats represents the saturation of a compartment.
+type Stats struct {
+	MaxConcurrent int   `json:"maxConcurrent"`
+	InFlight      int64 `json:"inFlight"`
+	Queued        int64 `json:"queued"`
+	Rejected      int64 `json:"rejected"`
+}
+
+type compartment struct {
+	rule     Rule
+	slots    chan struct{}
+	queued   int64
+	rejected int64
+}
+
+func newCompartment(rule Rule) *compartment {
+	return &compartment{rule: rule, slots: make(chan struct{}, rule.MaxConcurrent)}
+}
+
+func (c *compartment) acquire(ctx context.Context) bool {
+	select {
+	case c.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if atomic.AddInt64(&c.queued, 1) > int64(c.rule.MaxQueue) {
+		atomic.AddInt64(&c.queued, -1)
+		atomic.AddInt64(&c.rejected, 1)
+		return false
+	}
+	defer atomic.AddInt64(&c.queued, -1)
+
+	var timeout <-chan time.Time
+	if c.rule.MaxWait > 0 {
+		timer := time.NewTimer(c.rule.MaxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+		return true
+	case <-timeout:
+	case <-ctx.Done():
+	}
+
+	atomic.AddInt64(&c.rejected, 1)
+	return false
+}
+
+func (c *compartment) release() {
+	<-c.slots
+}
+
+func (c *compartment) stats() Stats {
+	return Stats{
+		MaxConcurrent: c.rule.MaxConcurrent,
+		InFlight:      int64(len(c.slots)),
+		Queued:        atomic.LoadInt64(&c.queued),
+		Rejected:      atomic.LoadInt64(&c.rejected),
+	}
+}
+
+// Bulkhead isolates the requests made to each mapped resource,
+// so a slow upstream cannot take every request in flight. Each
+// tenant and resource has its own compartment.
+type Bulkhead struct {
+	fallback *Rule
+	rules    map[string]Rule
+
+	mu           sync.Mutex
+	compartments map[string]*compartment
+}
+
+// New constructs a Bulkhead from the configuration.
+func New(cfg conf.BulkheadConf) *Bulkhead {
+	b := &Bulkhead{rules: make(map[string]Rule, len(cfg.Resources)), compartments: make(map[string]*compartment)}
+	if cfg.Resource != nil {
+		if r, ok := newRule(*cfg.Resource); ok {
+			b.fallback = &r
+		}
+	}
+
+	for name, c := range cfg.Resources {
+		if r, ok := newRule(c); ok {
+			b.rules[name] = r
+		}
+	}
+
+	return b
+}
+
+// AcquireResource takes a slot of the compartment of the resource,
+// waiting in its queue while every slot is taken. A nil Bulkhead,
+// or one without a rule for the resource, allows every request.
+func (b *Bulkhead) AcquireResource(ctx context.Context, tenant string, resource string) (func(), bool) {
+	if b == nil {
+		return func() {}, true
+	}
+
+	c, found := b.compartment(tenant, resource)
+	if !found {
+		return func() {}, true
+	}
+
+	if !c.acquire(ctx) {
+		return nil, false
+	}
+
+	return c.release, true
+}
+
+// Stats returns the saturation of every compartment in use,
+// by its tenant and resource. A nil Bulkhead has no compartments.
+func (b *Bulkhead) Stats() map[string]Stats {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := make(map[string]Stats, len(b.compartments))
+	for key, c := range b.compartments {
+		result[key] = c.stats()
+	}
+	return result
+}
+
+func (b *Bulkhead) compartment(tenant string, resource string) (*compartment, bool) {
+	rule, found := b.rules[resource]
+	if !found {
+		if b.fallback == nil {
+			return nil, false
+		}
+		rule = *b.fallback
+	}
+
+	key := tenant + ":" + resource
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, found := b.compartments[key]
+	if !found {
+		c = newCompartment(rule)
+		b.compartments[key] = c
+		bulkheadMetrics.Set(key, expvar.Func(func() interface{} {
+			return c.stats()
+		}))
+	}
+
+	return c, true
+}
@@ -0,0 +1,83 @@
+package bulkhead_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/bulkhead"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestBulkheadCompartments(t *testing.T) {
+	b := bulkhead.New(conf.BulkheadConf{
+		Resources: map[string]conf.BulkheadRuleConf{"hero": {MaxConcurrent: 1}},
+	})
+	ctx := context.Background()
+
+	_, acquired := b.AcquireResource(ctx, "DEFAULT", "hero")
+	test.Equal(t, acquired, true)
+
+	_, acquired = b.AcquireResource(ctx, "DEFAULT", "hero")
+	test.Equal(t, acquired, false)
+
+	_, acquired = b.AcquireResource(ctx, "OTHER", "hero")
+	test.Equal(t, acquired, true)
+
+	_, acquired = b.AcquireResource(ctx, "DEFAULT", "sidekick")
+	test.Equal(t, acquired, true)
+
+	test.Equal(t, b.Stats(), map[string]bulkhead.Stats{
+		"DEFAULT:hero": {MaxConcurrent: 1, InFlight: 1, Rejected: 1},
+		"OTHER:hero":   {MaxConcurrent: 1, InFlight: 1},
+	})
+}
+
+func TestBulkheadQueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should wait for a slot to be released", func(t *testing.T) {
+		b := bulkhead.New(conf.BulkheadConf{Resource: &conf.BulkheadRuleConf{MaxConcurrent: 1, MaxQueue: 1, MaxWait: time.Second}})
+
+		release, _ := b.AcquireResource(ctx, "DEFAULT", "hero")
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			release()
+		}()
+
+		_, acquired := b.AcquireResource(ctx, "DEFAULT", "hero")
+		test.Equal(t, acquired, true)
+	})
+
+	t.Run("should reject when wait expires", func(t *testing.T) {
+		b := bulkhead.New(conf.BulkheadConf{Resource: &conf.BulkheadRuleConf{MaxConcurrent: 1, MaxQueue: 1, MaxWait: 10 * time.Millisecond}})
+
+		b.AcquireResource(ctx, "DEFAULT", "hero")
+		_, acquired := b.AcquireResource(ctx, "DEFAULT", "hero")
+
+		test.Equal(t, acquired, false)
+		test.Equal(t, b.Stats()["DEFAULT:hero"].Rejected, int64(1))
+	})
+
+	t.Run("should reject when queue is full", func(t *testing.T) {
+		b := bulkhead.New(conf.BulkheadConf{Resource: &conf.BulkheadRuleConf{MaxConcurrent: 1, MaxQueue: 1, MaxWait: time.Second}})
+
+		b.AcquireResource(ctx, "DEFAULT", "hero")
+		go b.AcquireResource(ctx, "DEFAULT", "hero")
+		time.Sleep(10 * time.Millisecond)
+
+		_, acquired := b.AcquireResource(ctx, "DEFAULT", "hero")
+		test.Equal(t, acquired, false)
+		test.Equal(t, b.Stats()["DEFAULT:hero"].Queued, int64(1))
+	})
+}
+
+func TestNilBulkheadAllowsEveryRequest(t *testing.T) {
+	var b *bulkhead.Bulkhead
+
+	_, acquired := b.AcquireResource(context.Background(), "DEFAULT", "hero")
+
+	test.Equal(t, acquired, true)
+	test.Equal(t, len(b.Stats()), 0)
+}
@@ -2,17 +2,51 @@ package cache
 
 import (
 	"context"
-	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"expvar"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+
 	"github.com/bluele/gcache"
 	"github.com/pkg/errors"
 )
 
+// ErrStaleEntry is returned when the entry expired and could
+// not be refreshed while the cache is in fail fast mode.
+var ErrStaleEntry = errors.New("cache entry is stale and could not be refreshed")
+
+// FailureMode defines how the cache behaves when an
+// expired entry fails to be refreshed from its source.
+type FailureMode string
+
+// Failure modes available
+const (
+	// ServeStale keeps serving the last known good value.
+	ServeStale FailureMode = "serve-stale"
+	// FailFast returns ErrStaleEntry until the entry is refreshed.
+	FailFast FailureMode = "fail-fast"
+)
+
+// ParseFailureMode returns the failure mode named by the value,
+// defaulting to ServeStale when it is empty.
+func ParseFailureMode(value string) (FailureMode, error) {
+	switch mode := FailureMode(value); mode {
+	case "":
+		return ServeStale, nil
+	case ServeStale, FailFast:
+		return mode, nil
+	default:
+		return "", errors.Errorf("invalid cache failure mode %q, expected %s or %s", value, ServeStale, FailFast)
+	}
+}
+
 type cacheItem struct {
-	key        interface{}
-	value      interface{}
-	expiration time.Time
+	key           interface{}
+	value         interface{}
+	expiration    time.Time
+	refreshFailed bool
 }
 
 func (i cacheItem) Expired() bool {
@@ -49,28 +83,106 @@ func WithExpiration(expiration time.Duration) Option {
 	}
 }
 
+// WithTTL sets the time to live after which cache entries
+// are evicted, making the next access load them again.
+// Unlike WithExpiration, no stale value is served.
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithFailureMode sets the behaviour when an
+// expired entry fails to be refreshed.
+func WithFailureMode(mode FailureMode) Option {
+	return func(c *Cache) {
+		c.failureMode = mode
+	}
+}
+
+// WithName sets the name under which the cache
+// metrics are published.
+func WithName(name string) Option {
+	return func(c *Cache) {
+		c.name = name
+	}
+}
+
+// cacheMetrics holds the metrics of every named cache,
+// exposed by the expvar handler.
+var cacheMetrics = expvar.NewMap("cache")
+
+type statsProvider interface {
+	Stats() Stats
+}
+
+var registry = struct {
+	mu     sync.Mutex
+	caches map[string]statsProvider
+}{caches: make(map[string]statsProvider)}
+
+func register(name string, c statsProvider) {
+	registry.mu.Lock()
+	registry.caches[name] = c
+	registry.mu.Unlock()
+
+	cacheMetrics.Set(name, expvar.Func(func() interface{} {
+		return c.Stats()
+	}))
+}
+
+// AllStats returns the usage counters of every named cache.
+func AllStats() map[string]Stats {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	result := make(map[string]Stats, len(registry.caches))
+	for name, c := range registry.caches {
+		result[name] = c.Stats()
+	}
+
+	return result
+}
+
+// Stats represents the cache usage counters.
+type Stats struct {
+	Size            int   `json:"size"`
+	Hits            int64 `json:"hits"`
+	Misses          int64 `json:"misses"`
+	StaleHits       int64 `json:"staleHits"`
+	LoadFailures    int64 `json:"loadFailures"`
+	Refreshes       int64 `json:"refreshes"`
+	RefreshFailures int64 `json:"refreshFailures"`
+	Rejections      int64 `json:"rejections"`
+
+	// HitRate is the fraction of accesses served
+	// from cache, including stale entries.
+	HitRate float64 `json:"hitRate"`
+}
+
 // Cache is an in-memory container that uses a LRU
 // eviction strategy. It also supports stale cache,
 // i.e. cache entries have an expiration and when
 // its due the entry is refresh with a background
 // routine, never deleting the old value, only replacing it.
 type Cache struct {
+	stats              Stats
 	log                restql.Logger
+	name               string
+	failureMode        FailureMode
 	gcache             gcache.Cache
 	loader             Loader
 	refreshWorkCh      chan interface{}
 	expiration         time.Duration
+	ttl                time.Duration
 	refreshInterval    time.Duration
 	refreshQueueLength int
 }
 
 // New constructs an Cache instance.
 func New(log restql.Logger, size int, loader Loader, options ...Option) *Cache {
-	c := gcache.New(size).LRU().Build()
-
 	cache := Cache{
 		log:    log,
-		gcache: c,
 		loader: loader,
 	}
 
@@ -78,22 +190,67 @@ func New(log restql.Logger, size int, loader Loader, options ...Option) *Cache {
 		option(&cache)
 	}
 
+	builder := gcache.New(size).LRU()
+	if cache.ttl > 0 {
+		builder = builder.Expiration(cache.ttl)
+	}
+	cache.gcache = builder.Build()
+
 	if cache.refreshInterval > 0 && cache.refreshQueueLength > 0 {
 		rw := cache.setupRefreshWorker()
 		go rw.Run()
 	}
 
+	if cache.name != "" {
+		register(cache.name, &cache)
+	}
+
 	return &cache
 }
 
+// Stats returns a snapshot of the cache usage counters.
+func (c *Cache) Stats() Stats {
+	s := Stats{
+		Size:            c.gcache.Len(false),
+		Hits:            atomic.LoadInt64(&c.stats.Hits),
+		Misses:          atomic.LoadInt64(&c.stats.Misses),
+		StaleHits:       atomic.LoadInt64(&c.stats.StaleHits),
+		LoadFailures:    atomic.LoadInt64(&c.stats.LoadFailures),
+		Refreshes:       atomic.LoadInt64(&c.stats.Refreshes),
+		RefreshFailures: atomic.LoadInt64(&c.stats.RefreshFailures),
+		Rejections:      atomic.LoadInt64(&c.stats.Rejections),
+	}
+
+	hits := s.Hits + s.StaleHits
+	if accesses := hits + s.Misses; accesses > 0 {
+		s.HitRate = float64(hits) / float64(accesses)
+	}
+
+	return s
+}
+
+// Warm populates the cache with the given keys, so
+// the first access to them does not block on the loader.
+func (c *Cache) Warm(ctx context.Context, keys ...interface{}) {
+	for _, key := range keys {
+		_, err := c.populate(ctx, key)
+		if err != nil {
+			atomic.AddInt64(&c.stats.LoadFailures, 1)
+			c.log.Warn("failed to warm cache entry", "key", key, "error", err)
+		}
+	}
+}
+
 // Get retrieves and entry for the given key.
 func (c *Cache) Get(ctx context.Context, key interface{}) (interface{}, error) {
 	obj, err := c.gcache.Get(key)
 
 	switch {
 	case err == gcache.KeyNotFoundError:
+		atomic.AddInt64(&c.stats.Misses, 1)
 		item, err := c.populate(ctx, key)
 		if err != nil {
+			atomic.AddInt64(&c.stats.LoadFailures, 1)
 			return nil, err
 		}
 
@@ -110,10 +267,27 @@ func (c *Cache) Get(ctx context.Context, key interface{}) (interface{}, error) {
 		return nil, err
 	}
 
-	if item.Expired() {
-		go func() {
-			c.refreshWorkCh <- item.key
-		}()
+	if !item.Expired() {
+		atomic.AddInt64(&c.stats.Hits, 1)
+		return item.value, nil
+	}
+
+	atomic.AddInt64(&c.stats.StaleHits, 1)
+	if c.refreshWorkCh != nil {
+		select {
+		case c.refreshWorkCh <- item.key:
+		default:
+			c.log.Debug("refresh queue is full", "key", item.key)
+		}
+	}
+
+	if item.refreshFailed {
+		if c.failureMode == FailFast {
+			atomic.AddInt64(&c.stats.Rejections, 1)
+			return nil, ErrStaleEntry
+		}
+
+		recordStaleness(ctx, time.Since(item.expiration))
 	}
 
 	return item.value, nil
@@ -148,6 +322,24 @@ func (c *Cache) populate(ctx context.Context, key interface{}) (cacheItem, error
 	return item, nil
 }
 
+func (c *Cache) markRefreshFailed(key interface{}) {
+	obj, err := c.gcache.Get(key)
+	if err != nil {
+		return
+	}
+
+	item, ok := obj.(cacheItem)
+	if !ok || item.refreshFailed {
+		return
+	}
+
+	item.refreshFailed = true
+	err = c.gcache.Set(key, item)
+	if err != nil {
+		c.log.Error("failed to set value on cache", err)
+	}
+}
+
 func (c *Cache) setupRefreshWorker() *refreshWorker {
 	ticker := time.NewTicker(c.refreshInterval)
 	refreshWorkCh := make(chan interface{}, c.refreshQueueLength)
@@ -173,18 +365,41 @@ type refreshWorker struct {
 }
 
 func (rw *refreshWorker) Run() {
+	for range rw.ticker.C {
+		for _, key := range rw.pendingKeys() {
+			key := key
+			go func() {
+				_, err := rw.cache.populate(context.Background(), key)
+				if err != nil {
+					rw.cache.markRefreshFailed(key)
+					atomic.AddInt64(&rw.cache.stats.RefreshFailures, 1)
+					rw.log.Error("failed to refresh cache item in background", err, "key", key)
+					return
+				}
+
+				atomic.AddInt64(&rw.cache.stats.Refreshes, 1)
+			}()
+		}
+	}
+}
+
+// pendingKeys drains the refresh queue, discarding
+// duplicated requests for the same key.
+func (rw *refreshWorker) pendingKeys() []interface{} {
+	var keys []interface{}
+	seen := make(map[interface{}]struct{})
+
 	for {
 		select {
-		case <-rw.ticker.C:
-			for key := range rw.refreshWorkCh {
-				key := key
-				go func() {
-					_, err := rw.cache.populate(context.Background(), key)
-					if err != nil {
-						rw.log.Error("failed to refresh cache item in background", err)
-					}
-				}()
+		case key := <-rw.refreshWorkCh:
+			if _, found := seen[key]; found {
+				continue
 			}
+
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		default:
+			return keys
 		}
 	}
 }
@@ -0,0 +1,196 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/parser"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/cache"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestCacheWarm(t *testing.T) {
+	var calls int64
+	loader := func(ctx context.Context, key interface{}) (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		return key, nil
+	}
+
+	c := cache.New(test.NoOpLogger, 10, loader)
+	c.Warm(context.Background(), "acme", "globex")
+
+	got, err := c.Get(context.Background(), "acme")
+
+	test.VerifyError(t, err)
+	test.Equal(t, got, "acme")
+	test.Equal(t, atomic.LoadInt64(&calls), int64(2))
+	test.Equal(t, c.Stats(), cache.Stats{Size: 2, Hits: 1, HitRate: 1})
+}
+
+func TestCacheBackgroundRefresh(t *testing.T) {
+	var fail int64
+	loader := func(ctx context.Context, key interface{}) (interface{}, error) {
+		if atomic.LoadInt64(&fail) == 1 {
+			return nil, errors.New("database unavailable")
+		}
+		return key, nil
+	}
+
+	c := cache.New(test.NoOpLogger, 10, loader,
+		cache.WithExpiration(time.Millisecond),
+		cache.WithRefreshInterval(5*time.Millisecond),
+		cache.WithRefreshQueueLength(10),
+	)
+	c.Warm(context.Background(), "acme")
+	atomic.StoreInt64(&fail, 1)
+
+	time.Sleep(2 * time.Millisecond)
+	got, err := c.Get(context.Background(), "acme")
+	test.VerifyError(t, err)
+	test.Equal(t, got, "acme")
+
+	waitFor(t, func() bool { return c.Stats().RefreshFailures == 1 })
+	atomic.StoreInt64(&fail, 0)
+
+	got, err = c.Get(context.Background(), "acme")
+	test.VerifyError(t, err)
+	test.Equal(t, got, "acme")
+
+	waitFor(t, func() bool { return c.Stats().Refreshes == 1 })
+	test.Equal(t, c.Stats().StaleHits, int64(2))
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met before deadline")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCacheFailureMode(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          cache.FailureMode
+		expectedValue interface{}
+		expectedErr   error
+		expectStale   bool
+	}{
+		{"should serve last known good value when serving stale", cache.ServeStale, "acme", nil, true},
+		{"should return error when failing fast", cache.FailFast, nil, cache.ErrStaleEntry, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fail int64
+			loader := func(ctx context.Context, key interface{}) (interface{}, error) {
+				if atomic.LoadInt64(&fail) == 1 {
+					return nil, errors.New("database unavailable")
+				}
+				return key, nil
+			}
+
+			c := cache.New(test.NoOpLogger, 10, loader,
+				cache.WithExpiration(time.Millisecond),
+				cache.WithRefreshInterval(5*time.Millisecond),
+				cache.WithRefreshQueueLength(10),
+				cache.WithFailureMode(tt.mode),
+			)
+			c.Warm(context.Background(), "acme")
+			atomic.StoreInt64(&fail, 1)
+
+			time.Sleep(2 * time.Millisecond)
+			_, err := c.Get(context.Background(), "acme")
+			test.VerifyError(t, err)
+
+			waitFor(t, func() bool { return c.Stats().RefreshFailures >= 1 })
+
+			ctx := cache.WithStalenessTracking(context.Background())
+			got, err := c.Get(ctx, "acme")
+			_, stale := cache.StaleAge(ctx)
+
+			if err != tt.expectedErr {
+				t.Errorf("Get returned error %v, expected %v", err, tt.expectedErr)
+			}
+			test.Equal(t, got, tt.expectedValue)
+			test.Equal(t, stale, tt.expectStale)
+		})
+	}
+}
+
+func TestParseFailureMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected cache.FailureMode
+	}{
+		{"should default to serve stale", "", cache.ServeStale},
+		{"should parse serve stale", "serve-stale", cache.ServeStale},
+		{"should parse fail fast", "fail-fast", cache.FailFast},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cache.ParseFailureMode(tt.value)
+			test.VerifyError(t, err)
+			test.Equal(t, got, tt.expected)
+		})
+	}
+
+	_, err := cache.ParseFailureMode("failfast")
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	test.Equal(t, err.Error(), `invalid cache failure mode "failfast", expected serve-stale or fail-fast`)
+}
+
+func TestCacheTTL(t *testing.T) {
+	var calls int64
+	loader := func(ctx context.Context, key interface{}) (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		return key, nil
+	}
+
+	c := cache.New(test.NoOpLogger, 10, loader, cache.WithTTL(time.Millisecond))
+
+	_, err := c.Get(context.Background(), "acme")
+	test.VerifyError(t, err)
+
+	time.Sleep(2 * time.Millisecond)
+	got, err := c.Get(context.Background(), "acme")
+	test.VerifyError(t, err)
+
+	test.Equal(t, got, "acme")
+	test.Equal(t, atomic.LoadInt64(&calls), int64(2))
+	test.Equal(t, c.Stats().StaleHits, int64(0))
+}
+
+func TestParserCache(t *testing.T) {
+	p, err := parser.New()
+	test.VerifyError(t, err)
+
+	c := cache.New(test.NoOpLogger, 10, cache.ParserCacheLoader(p))
+	parserCache := cache.NewParserCache(test.NoOpLogger, c)
+
+	for _, q := range []string{"from hero", "from hero", "from sidekick"} {
+		query, err := parserCache.Parse(q)
+		test.VerifyError(t, err)
+		test.Equal(t, query.Statements[0].Resource, q[len("from "):])
+	}
+
+	_, err = parserCache.Parse("from")
+	if err == nil {
+		t.Errorf("Parse returned no error for invalid query")
+	}
+
+	stats := c.Stats()
+	test.Equal(t, stats.Size, 2)
+	test.Equal(t, stats.Hits, int64(1))
+	test.Equal(t, stats.Misses, int64(3))
+	test.Equal(t, stats.HitRate, 0.25)
+}
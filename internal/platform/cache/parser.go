@@ -2,6 +2,8 @@ package cache
 
 import (
 	"context"
+	"crypto/sha256"
+
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
 
 	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
@@ -9,6 +11,12 @@ import (
 	"github.com/pkg/errors"
 )
 
+// parserKey identifies a query text by its hash, so that
+// large queries are not kept as keys nor compared on lookup.
+type parserKey [sha256.Size]byte
+
+type queryTextKey struct{}
+
 // ParserCache is a caching wrapper that implements the Parser interface.
 type ParserCache struct {
 	log   restql.Logger
@@ -23,7 +31,9 @@ func NewParserCache(log restql.Logger, c *Cache) ParserCache {
 // Parse returns a cached QueryRevisions internal representation if
 // present, transforming the query text into one otherwise.
 func (p ParserCache) Parse(queryStr string) (domain.Query, error) {
-	result, err := p.cache.Get(context.Background(), queryStr)
+	ctx := context.WithValue(context.Background(), queryTextKey{}, queryStr)
+
+	result, err := p.cache.Get(ctx, parserKey(sha256.Sum256([]byte(queryStr))))
 	if err != nil {
 		return domain.Query{}, err
 	}
@@ -40,11 +50,15 @@ func (p ParserCache) Parse(queryStr string) (domain.Query, error) {
 // values for the cached parser.
 func ParserCacheLoader(p parser.Parser) Loader {
 	return func(ctx context.Context, key interface{}) (interface{}, error) {
-		queryStr, ok := key.(string)
-		if !ok {
+		if _, ok := key.(parserKey); !ok {
 			return nil, errors.Errorf("invalid key type : got %T", key)
 		}
 
+		queryStr, ok := ctx.Value(queryTextKey{}).(string)
+		if !ok {
+			return nil, errors.New("query text not found in context")
+		}
+
 		query, err := p.Parse(queryStr)
 		if err != nil {
 			return nil, err
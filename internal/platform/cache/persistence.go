@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/persistence"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
@@ -23,6 +24,9 @@ func NewMappingsReaderCache(log restql.Logger, c *Cache) *MappingsReaderCache {
 // FromTenant returns a cached mapping index if present, fetching it otherwise.
 func (c *MappingsReaderCache) FromTenant(ctx context.Context, tenant string) (map[string]restql.Mapping, error) {
 	result, err := c.cache.Get(ctx, tenant)
+	if err == ErrStaleEntry {
+		return nil, fmt.Errorf("%w: mappings for tenant %s could not be refreshed", restql.ErrDatabaseCommunicationFailed, tenant)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -68,11 +72,18 @@ type cacheQueryKey struct {
 type QueryReaderCache struct {
 	log   restql.Logger
 	cache *Cache
+	qr    persistence.QueryReader
 }
 
 // NewQueryReaderCache constructs a QueryReaderCache instance.
-func NewQueryReaderCache(log restql.Logger, c *Cache) *QueryReaderCache {
-	return &QueryReaderCache{log: log, cache: c}
+func NewQueryReaderCache(log restql.Logger, c *Cache, qr persistence.QueryReader) *QueryReaderCache {
+	return &QueryReaderCache{log: log, cache: c, qr: qr}
+}
+
+// ListQueryRevisions returns all revisions of the saved query.
+// Since new revisions can be created at any moment, it is not cached.
+func (c *QueryReaderCache) ListQueryRevisions(ctx context.Context, namespace, id string) ([]restql.SavedQuery, error) {
+	return c.qr.ListQueryRevisions(ctx, namespace, id)
 }
 
 // Get returns a cached saved query if present, fetching it otherwise.
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/bluele/gcache"
+)
+
+// ProxyEntry is a query response kept by the ProxyCache,
+// along with the moment it was stored.
+type ProxyEntry struct {
+	Response restql.QueryResponse
+	StoredAt time.Time
+}
+
+// Age returns how long ago the entry was stored.
+func (e ProxyEntry) Age() time.Duration {
+	return time.Since(e.StoredAt)
+}
+
+// ProxyCache is an in-memory LRU container of query
+// responses, each one evicted once its freshness lifetime ends.
+type ProxyCache struct {
+	stats  Stats
+	log    restql.Logger
+	gcache gcache.Cache
+}
+
+// NewProxyCache constructs a ProxyCache instance.
+func NewProxyCache(log restql.Logger, size int) *ProxyCache {
+	c := &ProxyCache{log: log, gcache: gcache.New(size).LRU().Build()}
+	register("proxy", c)
+
+	return c
+}
+
+// Stats returns the cache usage counters.
+func (c *ProxyCache) Stats() Stats {
+	s := Stats{
+		Size:   c.gcache.Len(true),
+		Hits:   atomic.LoadInt64(&c.stats.Hits),
+		Misses: atomic.LoadInt64(&c.stats.Misses),
+	}
+
+	if accesses := s.Hits + s.Misses; accesses > 0 {
+		s.HitRate = float64(s.Hits) / float64(accesses)
+	}
+
+	return s
+}
+
+// Get returns the response stored for the key, if it is still fresh.
+func (c *ProxyCache) Get(key string) (ProxyEntry, bool) {
+	obj, err := c.gcache.Get(key)
+	if err != nil {
+		atomic.AddInt64(&c.stats.Misses, 1)
+		return ProxyEntry{}, false
+	}
+
+	entry, ok := obj.(ProxyEntry)
+	if !ok {
+		atomic.AddInt64(&c.stats.Misses, 1)
+		return ProxyEntry{}, false
+	}
+
+	atomic.AddInt64(&c.stats.Hits, 1)
+	return entry, true
+}
+
+// Set stores the response for the key during the given lifetime.
+func (c *ProxyCache) Set(key string, response restql.QueryResponse, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	entry := ProxyEntry{Response: response, StoredAt: time.Now()}
+	err := c.gcache.SetWithExpire(key, entry, ttl)
+	if err != nil {
+		c.log.Error("failed to set response on proxy cache", err, "key", key)
+	}
+}
@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"strings"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/bluele/gcache"
+)
+
+// SurrogateKeyHeader is the upstream response header listing,
+// separated by spaces, the tags used to purge the cached response.
+const SurrogateKeyHeader = "Surrogate-Key"
+
+type responseEntry struct {
+	resource string
+	tags     []string
+	response restql.HTTPResponse
+}
+
+// ResponseInvalidation selects the cached responses to be purged,
+// which must match every criteria given. The URL pattern accepts
+// `*` as a wildcard for any sequence of characters.
+type ResponseInvalidation struct {
+	Resource   string
+	URLPattern string
+	Tag        string
+}
+
+func (ri ResponseInvalidation) matches(entry responseEntry) bool {
+	if ri.Resource != "" && ri.Resource != entry.resource {
+		return false
+	}
+
+	if ri.URLPattern != "" && !matchWildcard(ri.URLPattern, entry.response.URL) {
+		return false
+	}
+
+	if ri.Tag != "" && !containsTag(entry.tags, ri.Tag) {
+		return false
+	}
+
+	return true
+}
+
+// ResponseCache is an in-memory LRU container of upstream
+// responses used to answer revalidated requests and the
+// statements cached by the query.
+type ResponseCache struct {
+	log    restql.Logger
+	gcache gcache.Cache
+}
+
+// NewResponseCache constructs a ResponseCache instance.
+func NewResponseCache(log restql.Logger, size int) *ResponseCache {
+	c := &ResponseCache{log: log, gcache: gcache.New(size).LRU().Build()}
+	register("responses", c)
+
+	return c
+}
+
+// Stats returns the cache usage counters.
+func (c *ResponseCache) Stats() Stats {
+	return Stats{Size: c.gcache.Len(false)}
+}
+
+// Get returns a copy of the cached response for the key, if present.
+func (c *ResponseCache) Get(key string) (restql.HTTPResponse, bool) {
+	obj, err := c.gcache.Get(key)
+	if err != nil {
+		return restql.HTTPResponse{}, false
+	}
+
+	entry, ok := obj.(responseEntry)
+	if !ok {
+		return restql.HTTPResponse{}, false
+	}
+
+	response := entry.response
+	response.Body = restql.NewResponseBodyFromBytes(c.log, response.Body.Bytes())
+	return response, true
+}
+
+// Set stores the response of the resource for the key, tagged by its
+// Surrogate-Key header. Only the raw body is kept, so later
+// manipulations of the response do not affect the cache.
+func (c *ResponseCache) Set(key string, resource string, response restql.HTTPResponse) {
+	c.set(key, resource, response, 0)
+}
+
+// SetWithTTL stores the response like Set, expiring it after the ttl.
+func (c *ResponseCache) SetWithTTL(key string, resource string, response restql.HTTPResponse, ttl time.Duration) {
+	c.set(key, resource, response, ttl)
+}
+
+func (c *ResponseCache) set(key string, resource string, response restql.HTTPResponse, ttl time.Duration) {
+	if response.Body == nil {
+		return
+	}
+
+	response.Body = restql.NewResponseBodyFromBytes(c.log, response.Body.Bytes())
+	entry := responseEntry{resource: resource, tags: surrogateKeys(response.Headers), response: response}
+
+	var err error
+	if ttl > 0 {
+		err = c.gcache.SetWithExpire(key, entry, ttl)
+	} else {
+		err = c.gcache.Set(key, entry)
+	}
+
+	if err != nil {
+		c.log.Error("failed to set response on cache", err, "key", key)
+	}
+}
+
+// Invalidate removes the cached responses selected by the
+// invalidation and returns how many of them were removed.
+func (c *ResponseCache) Invalidate(ri ResponseInvalidation) int {
+	removed := 0
+	for key, obj := range c.gcache.GetALL(false) {
+		entry, ok := obj.(responseEntry)
+		if !ok || !ri.matches(entry) {
+			continue
+		}
+
+		if c.gcache.Remove(key) {
+			removed++
+		}
+	}
+
+	return removed
+}
+
+func surrogateKeys(headers restql.Headers) []string {
+	for key, value := range headers {
+		if strings.EqualFold(key, SurrogateKeyHeader) {
+			return strings.Fields(value)
+		}
+	}
+	return nil
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWildcard reports if the value matches the pattern,
+// where each `*` matches any sequence of characters.
+func matchWildcard(pattern string, value string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(value, part)
+		if i < 0 {
+			return false
+		}
+		value = value[i+len(part):]
+	}
+
+	return len(value) >= len(last) && strings.HasSuffix(value, last)
+}
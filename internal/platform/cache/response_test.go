@@ -0,0 +1,64 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/cache"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestResponseCacheInvalidate(t *testing.T) {
+	response := func(url string, surrogateKey string) restql.HTTPResponse {
+		return restql.HTTPResponse{
+			URL:     url,
+			Headers: restql.Headers{"Surrogate-Key": surrogateKey},
+			Body:    restql.NewResponseBodyFromBytes(test.NoOpLogger, []byte(`{}`)),
+		}
+	}
+
+	tests := []struct {
+		name         string
+		invalidation cache.ResponseInvalidation
+		expected     int
+		remaining    []string
+	}{
+		{"should invalidate by resource", cache.ResponseInvalidation{Resource: "hero"}, 2, []string{"sidekick"}},
+		{"should invalidate by url pattern", cache.ResponseInvalidation{URLPattern: "http://hero.io/*/batman"}, 1, []string{"superman", "sidekick"}},
+		{"should invalidate by tag", cache.ResponseInvalidation{Tag: "dc"}, 3, nil},
+		{"should invalidate matching every criteria", cache.ResponseInvalidation{Resource: "hero", Tag: "bats"}, 1, []string{"superman", "sidekick"}},
+		{"should invalidate nothing when no response matches", cache.ResponseInvalidation{Tag: "marvel"}, 0, []string{"batman", "superman", "sidekick"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cache.NewResponseCache(test.NoOpLogger, 10)
+			c.Set("batman", "hero", response("http://hero.io/api/batman", "dc bats"))
+			c.Set("superman", "hero", response("http://hero.io/api/superman", "dc"))
+			c.Set("sidekick", "sidekick", response("http://sidekick.io/api/robin", "dc bats"))
+
+			got := c.Invalidate(tt.invalidation)
+
+			test.Equal(t, got, tt.expected)
+			test.Equal(t, c.Stats().Size, len(tt.remaining))
+			for _, key := range tt.remaining {
+				_, found := c.Get(key)
+				test.Equal(t, found, true)
+			}
+		})
+	}
+}
+
+func TestResponseCacheSetWithTTL(t *testing.T) {
+	c := cache.NewResponseCache(test.NoOpLogger, 10)
+	c.SetWithTTL("batman", "hero", restql.HTTPResponse{Body: restql.NewResponseBodyFromBytes(test.NoOpLogger, []byte(`{}`))}, 10*time.Millisecond)
+
+	_, found := c.Get("batman")
+	test.Equal(t, found, true)
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, found = c.Get("batman")
+	test.Equal(t, found, false)
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type stalenessKey struct{}
+
+type staleness struct {
+	mu    sync.Mutex
+	age   time.Duration
+	found bool
+}
+
+// WithStalenessTracking returns a context that records
+// the age of stale entries served by caches using it.
+func WithStalenessTracking(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stalenessKey{}, &staleness{})
+}
+
+// StaleAge returns the age of the oldest stale entry
+// served with the given context, if any.
+func StaleAge(ctx context.Context) (time.Duration, bool) {
+	s, ok := ctx.Value(stalenessKey{}).(*staleness)
+	if !ok {
+		return 0, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.age, s.found
+}
+
+func recordStaleness(ctx context.Context, age time.Duration) {
+	s, ok := ctx.Value(stalenessKey{}).(*staleness)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.found = true
+	if age > s.age {
+		s.age = age
+	}
+}
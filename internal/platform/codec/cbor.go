@@ -0,0 +1,100 @@
+package codec
+
+import (
+	"bytes"
+	"math"
+	"sort"
+)
+
+const (
+	cborUnsigned = 0 << 5
+	cborNegative = 1 << 5
+	cborText     = 3 << 5
+	cborArray    = 4 << 5
+	cborMap      = 5 << 5
+)
+
+// MarshalCBOR encodes the value in the CBOR format (RFC 8949),
+// following its core deterministic encoding requirements.
+func MarshalCBOR(v interface{}) ([]byte, error) {
+	return appendCBOR(nil, v)
+}
+
+func appendCBOR(b []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xf6), nil
+	case bool:
+		if v {
+			return append(b, 0xf5), nil
+		}
+		return append(b, 0xf4), nil
+	case string:
+		return append(appendCBORHeader(b, cborText, uint64(len(v))), v...), nil
+	case []interface{}:
+		b = appendCBORHeader(b, cborArray, uint64(len(v)))
+		for _, item := range v {
+			var err error
+			if b, err = appendCBOR(b, item); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]interface{}:
+		return appendCBORMap(b, v)
+	}
+
+	n, ok := toNumber(v)
+	if !ok {
+		return nil, unsupported(v)
+	}
+
+	switch {
+	case !n.isInt:
+		return appendUint(append(b, 0xfb), math.Float64bits(n.f), 8), nil
+	case n.i >= 0:
+		return appendCBORHeader(b, cborUnsigned, uint64(n.i)), nil
+	default:
+		return appendCBORHeader(b, cborNegative, uint64(-1-n.i)), nil
+	}
+}
+
+// appendCBORMap writes the map entries ordered by the
+// bytewise lexicographic order of their encoded keys.
+func appendCBORMap(b []byte, m map[string]interface{}) ([]byte, error) {
+	keys := make([][]byte, 0, len(m))
+	values := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		encoded := append(appendCBORHeader(nil, cborText, uint64(len(k))), k...)
+		keys = append(keys, encoded)
+		values[string(encoded)] = v
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+
+	b = appendCBORHeader(b, cborMap, uint64(len(m)))
+	for _, key := range keys {
+		b = append(b, key...)
+
+		var err error
+		if b, err = appendCBOR(b, values[string(key)]); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+func appendCBORHeader(b []byte, major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return append(b, major|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(b, major|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return appendUint(append(b, major|25), arg, 2)
+	case arg <= math.MaxUint32:
+		return appendUint(append(b, major|26), arg, 4)
+	default:
+		return appendUint(append(b, major|27), arg, 8)
+	}
+}
@@ -0,0 +1,98 @@
+// Package codec implements the binary formats restQL is able to
+// encode query responses to, and decode upstream responses from,
+// along with the JSON encoders query responses can be written with.
+//
+// Values are expected to be in the generic form produced by
+// decoding JSON: nil, bool, numbers, string, []interface{} and
+// map[string]interface{}. Maps are encoded with their keys sorted,
+// hence the same value always produces the same bytes.
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnsupportedType represents the event of encoding a value
+// that has no representation in the target format.
+var ErrUnsupportedType = errors.New("unsupported type")
+
+// ErrMalformedData represents the event of decoding
+// a truncated or otherwise invalid payload.
+var ErrMalformedData = errors.New("malformed data")
+
+// ErrTooComplex represents the event of decoding a payload
+// nested too deeply or with too many map keys.
+var ErrTooComplex = errors.New("too complex")
+
+// DecodeLimits bounds the nesting depth and the number of map
+// keys of decoded payloads, where zero means no limit.
+type DecodeLimits struct {
+	MaxDepth int
+	MaxKeys  int
+}
+
+// FromJSON decodes a JSON document into the generic form
+// accepted by the encoders, keeping integers precision.
+func FromJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+type number struct {
+	isInt bool
+	i     int64
+	f     float64
+}
+
+func toNumber(v interface{}) (number, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return number{isInt: true, i: i}, true
+		}
+
+		f, err := n.Float64()
+		if err != nil {
+			return number{}, false
+		}
+		return toNumber(f)
+	case int:
+		return number{isInt: true, i: int64(n)}, true
+	case int64:
+		return number{isInt: true, i: n}, true
+	case float64:
+		if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+			return number{isInt: true, i: int64(n)}, true
+		}
+		return number{f: n}, true
+	default:
+		return number{}, false
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func unsupported(v interface{}) error {
+	return fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+}
@@ -0,0 +1,143 @@
+package codec_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/codec"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestMarshalMsgpack(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected string
+	}{
+		{"should encode null", `null`, "c0"},
+		{"should encode booleans", `[true, false]`, "92c3c2"},
+		{"should encode positive fixint", `127`, "7f"},
+		{"should encode negative fixint", `-32`, "e0"},
+		{"should encode uint8", `200`, "ccc8"},
+		{"should encode int16", `-1000`, "d1fc18"},
+		{"should encode uint32", `70000`, "ce00011170"},
+		{"should encode float", `1.5`, "cb3ff8000000000000"},
+		{"should encode fixstr", `"hero"`, "a46865726f"},
+		{"should encode map with sorted keys", `{"b": 1, "a": "x"}`, "82a161a178a16201"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := codec.FromJSON([]byte(tt.json))
+			test.VerifyError(t, err)
+
+			got, err := codec.MarshalMsgpack(v)
+
+			test.VerifyError(t, err)
+			test.Equal(t, hex.EncodeToString(got), tt.expected)
+		})
+	}
+}
+
+func TestUnmarshalMsgpack(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		limits      codec.DecodeLimits
+		expected    interface{}
+		expectedErr error
+	}{
+		{"should decode null", "c0", codec.DecodeLimits{}, nil, nil},
+		{"should decode integers as float", "93 7f e0 d1fc18", codec.DecodeLimits{}, []interface{}{float64(127), float64(-32), float64(-1000)}, nil},
+		{"should decode float32", "ca3fc00000", codec.DecodeLimits{}, float64(1.5), nil},
+		{"should decode str8", "d90468657266", codec.DecodeLimits{}, "herf", nil},
+		{"should decode bin as string", "c4026869", codec.DecodeLimits{}, "hi", nil},
+		{"should decode map", "82a161a178a16201", codec.DecodeLimits{}, map[string]interface{}{"a": "x", "b": float64(1)}, nil},
+		{"should stringify non string keys", "8101c3", codec.DecodeLimits{}, map[string]interface{}{"1": true}, nil},
+		{"should fail on truncated data", "a568", codec.DecodeLimits{}, nil, codec.ErrMalformedData},
+		{"should fail on trailing data", "c0c0", codec.DecodeLimits{}, nil, codec.ErrMalformedData},
+		{"should fail on oversized length", "ddffffffff", codec.DecodeLimits{}, nil, codec.ErrMalformedData},
+		{"should fail on extension types", "d4010a", codec.DecodeLimits{}, nil, codec.ErrUnsupportedType},
+		{"should decode within the limits", "91 81a161 91c0", codec.DecodeLimits{MaxDepth: 3, MaxKeys: 1}, []interface{}{map[string]interface{}{"a": []interface{}{nil}}}, nil},
+		{"should fail when nested too deeply", "91 91 91c0", codec.DecodeLimits{MaxDepth: 2}, nil, codec.ErrTooComplex},
+		{"should fail with too many map keys", "92 81a161c0 81a162c0", codec.DecodeLimits{MaxKeys: 1}, nil, codec.ErrTooComplex},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(stripSpaces(tt.data))
+			test.VerifyError(t, err)
+
+			got, err := codec.UnmarshalMsgpack(data, tt.limits)
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("UnmarshalMsgpack error = %v, want %v", err, tt.expectedErr)
+			}
+			test.Equal(t, got, tt.expected)
+		})
+	}
+}
+
+func TestUnmarshalMsgpackNestingBound(t *testing.T) {
+	data := append(bytes.Repeat([]byte{0x91}, 1<<20), 0xc0)
+
+	_, err := codec.UnmarshalMsgpack(data, codec.DecodeLimits{})
+
+	if !errors.Is(err, codec.ErrTooComplex) {
+		t.Fatalf("UnmarshalMsgpack error = %v, want %v", err, codec.ErrTooComplex)
+	}
+}
+
+func TestMarshalCBOR(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected string
+	}{
+		{"should encode null", `null`, "f6"},
+		{"should encode booleans", `[true, false]`, "82f5f4"},
+		{"should encode small unsigned", `23`, "17"},
+		{"should encode one byte unsigned", `24`, "1818"},
+		{"should encode negative", `-500`, "3901f3"},
+		{"should encode large unsigned", `1000000000000`, "1b000000e8d4a51000"},
+		{"should encode float", `1.1`, "fb3ff199999999999a"},
+		{"should encode text", `"IETF"`, "6449455446"},
+		{"should encode map with deterministic key order", `{"bb": 2, "a": 1, "c": [1]}`, "a3 6161 01 6163 8101 626262 02"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := codec.FromJSON([]byte(tt.json))
+			test.VerifyError(t, err)
+
+			got, err := codec.MarshalCBOR(v)
+
+			test.VerifyError(t, err)
+			test.Equal(t, hex.EncodeToString(got), stripSpaces(tt.expected))
+		})
+	}
+}
+
+func TestMarshalUnsupportedType(t *testing.T) {
+	_, err := codec.MarshalMsgpack(struct{}{})
+	if !errors.Is(err, codec.ErrUnsupportedType) {
+		t.Fatalf("MarshalMsgpack error = %v, want %v", err, codec.ErrUnsupportedType)
+	}
+
+	_, err = codec.MarshalCBOR(struct{}{})
+	if !errors.Is(err, codec.ErrUnsupportedType) {
+		t.Fatalf("MarshalCBOR error = %v, want %v", err, codec.ErrUnsupportedType)
+	}
+}
+
+func stripSpaces(s string) string {
+	result := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != ' ' {
+			result = append(result, s[i])
+		}
+	}
+	return string(result)
+}
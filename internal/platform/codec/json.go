@@ -0,0 +1,274 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// JSON encoders available to be selected by name.
+const (
+	StdJSONEncoder  = "std"
+	FastJSONEncoder = "fast"
+)
+
+// ErrUnknownEncoder represents the event of selecting
+// an encoder that is not available.
+var ErrUnknownEncoder = errors.New("unknown json encoder")
+
+// JSONOptions represents the behaviour shared by the JSON encoders.
+//
+// EscapeHTML replaces the <, > and & characters in strings by their
+// unicode escape sequences. SortKeys writes map keys sorted, which
+// is required for the same value to always produce the same bytes.
+// The std encoder always sorts map keys.
+type JSONOptions struct {
+	EscapeHTML bool
+	SortKeys   bool
+}
+
+// JSONEncoder encodes values into JSON documents.
+type JSONEncoder interface {
+	Marshal(v interface{}) ([]byte, error)
+}
+
+// NewJSONEncoder returns the JSON encoder registered with the name.
+// An empty name selects the std encoder.
+func NewJSONEncoder(name string, options JSONOptions) (JSONEncoder, error) {
+	switch name {
+	case "", StdJSONEncoder:
+		return stdEncoder{options: options}, nil
+	case FastJSONEncoder:
+		return fastEncoder{options: options}, nil
+	default:
+		return nil, fmt.Errorf("%w : %s", ErrUnknownEncoder, name)
+	}
+}
+
+// stdEncoder relies on encoding/json, which uses reflection for
+// every value and validates and compacts raw messages.
+type stdEncoder struct {
+	options JSONOptions
+}
+
+func (e stdEncoder) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(e.options.EscapeHTML)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// fastEncoder writes the generic values and raw messages restQL
+// handles without reflection, copying raw upstream bodies as they
+// are. Other types are handled by the std encoder.
+type fastEncoder struct {
+	options JSONOptions
+}
+
+func (e fastEncoder) Marshal(v interface{}) ([]byte, error) {
+	return e.appendValue(make([]byte, 0, 1024), v)
+}
+
+func (e fastEncoder) appendValue(dst []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(dst, "null"...), nil
+	case bool:
+		return strconv.AppendBool(dst, v), nil
+	case string:
+		return appendJSONString(dst, v, e.options.EscapeHTML), nil
+	case json.Number:
+		if v == "" {
+			return append(dst, '0'), nil
+		}
+		return append(dst, v...), nil
+	case json.RawMessage:
+		return e.appendRaw(dst, v), nil
+	case float64:
+		return appendJSONFloat(dst, v)
+	case int:
+		return strconv.AppendInt(dst, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(dst, v, 10), nil
+	case []interface{}:
+		return e.appendArray(dst, v)
+	case map[string]interface{}:
+		return e.appendObject(dst, v)
+	case map[string]string:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = value
+		}
+		return e.appendObject(dst, m)
+	default:
+		data, err := stdEncoder{options: e.options}.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return append(dst, data...), nil
+	}
+}
+
+func (e fastEncoder) appendRaw(dst []byte, raw json.RawMessage) []byte {
+	if len(raw) == 0 {
+		return append(dst, "null"...)
+	}
+
+	if !e.options.EscapeHTML {
+		return append(dst, raw...)
+	}
+
+	buf := bytes.NewBuffer(dst)
+	json.HTMLEscape(buf, raw)
+	return buf.Bytes()
+}
+
+func (e fastEncoder) appendArray(dst []byte, values []interface{}) ([]byte, error) {
+	dst = append(dst, '[')
+
+	var err error
+	for i, value := range values {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+
+		dst, err = e.appendValue(dst, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return append(dst, ']'), nil
+}
+
+func (e fastEncoder) appendObject(dst []byte, m map[string]interface{}) ([]byte, error) {
+	dst = append(dst, '{')
+
+	var err error
+	first := true
+	appendEntry := func(key string, value interface{}) {
+		if !first {
+			dst = append(dst, ',')
+		}
+		first = false
+
+		dst = appendJSONString(dst, key, e.options.EscapeHTML)
+		dst = append(dst, ':')
+		dst, err = e.appendValue(dst, value)
+	}
+
+	if e.options.SortKeys {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if appendEntry(key, m[key]); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		for key, value := range m {
+			if appendEntry(key, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return append(dst, '}'), nil
+}
+
+// appendJSONFloat formats floats as encoding/json does, using the
+// exponent notation only for very small or very large values.
+func appendJSONFloat(dst []byte, f float64) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, errors.Wrapf(ErrUnsupportedType, "json: unsupported value %v", f)
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(dst)
+		if n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+
+	return dst, nil
+}
+
+const hex = "0123456789abcdef"
+
+// appendJSONString quotes the string with the same escaping rules
+// of encoding/json, replacing invalid UTF-8 by the replacement rune.
+func appendJSONString(dst []byte, s string, escapeHTML bool) []byte {
+	dst = append(dst, '"')
+
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && (!escapeHTML || (b != '<' && b != '>' && b != '&')) {
+				i++
+				continue
+			}
+
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '\\', '"':
+				dst = append(dst, '\\', b)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+
+		if c == '\u2028' || c == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+
+		i += size
+	}
+
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
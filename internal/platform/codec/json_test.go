@@ -0,0 +1,81 @@
+package codec_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/codec"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+type statement struct {
+	Status int    `json:"status"`
+	Name   string `json:"name,omitempty"`
+}
+
+func TestJSONEncoders(t *testing.T) {
+	values := []struct {
+		name  string
+		value interface{}
+	}{
+		{"nil", nil},
+		{"scalars", []interface{}{true, false, "batman", 10, int64(-3), 1.5, 1e21, 0.0000001, json.Number("42")}},
+		{"escaped string", "\"quoted\"\n\t\\ <b>&</b> \x01 \u2028 \xff"},
+		{"nested objects", map[string]interface{}{"z": map[string]interface{}{"b": 1.0, "a": []interface{}{"x"}}, "a": nil}},
+		{"raw message", map[string]interface{}{"result": json.RawMessage(`{"name":"<batman>"}`)}},
+		{"string maps", map[string]string{"b": "2", "a": "1"}},
+		{"structs", []interface{}{statement{Status: 200}, statement{Status: 404, Name: "hero"}}},
+	}
+
+	for _, escapeHTML := range []bool{true, false} {
+		options := codec.JSONOptions{EscapeHTML: escapeHTML, SortKeys: true}
+
+		std, err := codec.NewJSONEncoder(codec.StdJSONEncoder, options)
+		test.VerifyError(t, err)
+		fast, err := codec.NewJSONEncoder(codec.FastJSONEncoder, options)
+		test.VerifyError(t, err)
+
+		for _, tt := range values {
+			t.Run(tt.name, func(t *testing.T) {
+				expected, err := std.Marshal(tt.value)
+				test.VerifyError(t, err)
+
+				got, err := fast.Marshal(tt.value)
+				test.VerifyError(t, err)
+
+				test.Equal(t, string(got), string(expected))
+			})
+		}
+	}
+}
+
+func TestFastJSONEncoderUnsortedKeys(t *testing.T) {
+	fast, err := codec.NewJSONEncoder(codec.FastJSONEncoder, codec.JSONOptions{})
+	test.VerifyError(t, err)
+
+	value := map[string]interface{}{"hero": "batman", "sidekick": "robin", "villain": "joker"}
+	got, err := fast.Marshal(value)
+	test.VerifyError(t, err)
+
+	var decoded map[string]interface{}
+	err = json.Unmarshal(got, &decoded)
+	test.VerifyError(t, err)
+	test.Equal(t, decoded, value)
+}
+
+func TestJSONEncoderErrors(t *testing.T) {
+	_, err := codec.NewJSONEncoder("segmentio", codec.JSONOptions{})
+	if !errors.Is(err, codec.ErrUnknownEncoder) {
+		t.Fatalf("NewJSONEncoder error = %v, want %v", err, codec.ErrUnknownEncoder)
+	}
+
+	fast, err := codec.NewJSONEncoder(codec.FastJSONEncoder, codec.JSONOptions{})
+	test.VerifyError(t, err)
+
+	_, err = fast.Marshal([]interface{}{math.NaN()})
+	if !errors.Is(err, codec.ErrUnsupportedType) {
+		t.Fatalf("Marshal error = %v, want %v", err, codec.ErrUnsupportedType)
+	}
+}
@@ -0,0 +1,343 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// MarshalMsgpack encodes the value in the MessagePack format.
+func MarshalMsgpack(v interface{}) ([]byte, error) {
+	return appendMsgpack(nil, v)
+}
+
+func appendMsgpack(b []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xc0), nil
+	case bool:
+		if v {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+	case string:
+		return appendMsgpackString(b, v), nil
+	case []interface{}:
+		b = appendMsgpackHeader(b, len(v), 0x90, 16, 0xdc)
+		for _, item := range v {
+			var err error
+			if b, err = appendMsgpack(b, item); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]interface{}:
+		b = appendMsgpackHeader(b, len(v), 0x80, 16, 0xde)
+		for _, key := range sortedKeys(v) {
+			b = appendMsgpackString(b, key)
+
+			var err error
+			if b, err = appendMsgpack(b, v[key]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+
+	n, ok := toNumber(v)
+	if !ok {
+		return nil, unsupported(v)
+	}
+
+	if !n.isInt {
+		b = append(b, 0xcb)
+		return appendUint(b, math.Float64bits(n.f), 8), nil
+	}
+
+	return appendMsgpackInt(b, n.i), nil
+}
+
+func appendMsgpackInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i <= math.MaxInt8:
+		return append(b, byte(i))
+	case i < 0 && i >= -32:
+		return append(b, byte(i))
+	case i >= 0 && i <= math.MaxUint8:
+		return append(b, 0xcc, byte(i))
+	case i >= 0 && i <= math.MaxUint16:
+		return appendUint(append(b, 0xcd), uint64(i), 2)
+	case i >= 0 && i <= math.MaxUint32:
+		return appendUint(append(b, 0xce), uint64(i), 4)
+	case i >= 0:
+		return appendUint(append(b, 0xcf), uint64(i), 8)
+	case i >= math.MinInt8:
+		return append(b, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		return appendUint(append(b, 0xd1), uint64(i), 2)
+	case i >= math.MinInt32:
+		return appendUint(append(b, 0xd2), uint64(i), 4)
+	default:
+		return appendUint(append(b, 0xd3), uint64(i), 8)
+	}
+}
+
+func appendMsgpackString(b []byte, s string) []byte {
+	length := len(s)
+	switch {
+	case length < 32:
+		b = append(b, 0xa0|byte(length))
+	case length <= math.MaxUint8:
+		b = append(b, 0xd9, byte(length))
+	case length <= math.MaxUint16:
+		b = appendUint(append(b, 0xda), uint64(length), 2)
+	default:
+		b = appendUint(append(b, 0xdb), uint64(length), 4)
+	}
+
+	return append(b, s...)
+}
+
+// appendMsgpackHeader writes the header of arrays and maps, which
+// have a fixed form for small lengths followed by 16 and 32 bits forms.
+func appendMsgpackHeader(b []byte, length int, fixed byte, fixedLimit int, first byte) []byte {
+	switch {
+	case length < fixedLimit:
+		return append(b, fixed|byte(length))
+	case length <= math.MaxUint16:
+		return appendUint(append(b, first), uint64(length), 2)
+	default:
+		return appendUint(append(b, first+1), uint64(length), 4)
+	}
+}
+
+func appendUint(b []byte, u uint64, size int) []byte {
+	for i := size - 1; i >= 0; i-- {
+		b = append(b, byte(u>>(8*uint(i))))
+	}
+
+	return b
+}
+
+// maxMsgpackDepth bounds the nesting of every decoded payload,
+// as the decoder recurses for each level, like encoding/json.
+const maxMsgpackDepth = 10000
+
+// UnmarshalMsgpack decodes a MessagePack payload into the same
+// generic form produced by decoding JSON, with numbers as float64
+// and binary data as string. Extension types are not supported.
+// Payloads exceeding the limits are refused before being decoded
+// any deeper.
+func UnmarshalMsgpack(data []byte, limits DecodeLimits) (interface{}, error) {
+	if limits.MaxDepth <= 0 || limits.MaxDepth > maxMsgpackDepth {
+		limits.MaxDepth = maxMsgpackDepth
+	}
+	d := msgpackDecoder{data: data, limits: limits}
+
+	v, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrMalformedData, len(d.data)-d.pos)
+	}
+
+	return v, nil
+}
+
+type msgpackDecoder struct {
+	data   []byte
+	pos    int
+	limits DecodeLimits
+	depth  int
+	keys   int
+}
+
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrMalformedData)
+	}
+
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *msgpackDecoder) readUint(size int) (uint64, error) {
+	b, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+func (d *msgpackDecoder) decode() (interface{}, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	t := b[0]
+
+	switch {
+	case t <= 0x7f:
+		return float64(t), nil
+	case t >= 0xe0:
+		return float64(int8(t)), nil
+	case t&0xe0 == 0xa0:
+		return d.decodeString(int(t & 0x1f))
+	case t&0xf0 == 0x90:
+		return d.decodeArray(int(t & 0x0f))
+	case t&0xf0 == 0x80:
+		return d.decodeMap(int(t & 0x0f))
+	}
+
+	switch t {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.readUint(1 << (t - 0xcc))
+		return float64(u), err
+	case 0xd0:
+		u, err := d.readUint(1)
+		return float64(int8(u)), err
+	case 0xd1:
+		u, err := d.readUint(2)
+		return float64(int16(u)), err
+	case 0xd2:
+		u, err := d.readUint(4)
+		return float64(int32(u)), err
+	case 0xd3:
+		u, err := d.readUint(8)
+		return float64(int64(u)), err
+	case 0xca:
+		u, err := d.readUint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := d.readUint(8)
+		return math.Float64frombits(u), err
+	case 0xd9, 0xc4:
+		return d.decodeSized(1, d.decodeString)
+	case 0xda, 0xc5:
+		return d.decodeSized(2, d.decodeString)
+	case 0xdb, 0xc6:
+		return d.decodeSized(4, d.decodeString)
+	case 0xdc:
+		return d.decodeSized(2, d.decodeArray)
+	case 0xdd:
+		return d.decodeSized(4, d.decodeArray)
+	case 0xde:
+		return d.decodeSized(2, d.decodeMap)
+	case 0xdf:
+		return d.decodeSized(4, d.decodeMap)
+	default:
+		return nil, fmt.Errorf("%w: type 0x%x", ErrUnsupportedType, t)
+	}
+}
+
+func (d *msgpackDecoder) decodeSized(size int, decode func(length int) (interface{}, error)) (interface{}, error) {
+	length, err := d.readUint(size)
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(len(d.data)) {
+		return nil, fmt.Errorf("%w: length %d exceeds data", ErrMalformedData, length)
+	}
+
+	return decode(int(length))
+}
+
+func (d *msgpackDecoder) decodeString(length int) (interface{}, error) {
+	b, err := d.read(length)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) decodeArray(length int) (interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	list := make([]interface{}, 0, minLength(length, len(d.data)-d.pos))
+	for i := 0; i < length; i++ {
+		item, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+
+	return list, nil
+}
+
+func (d *msgpackDecoder) decodeMap(length int) (interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	d.keys += length
+	if d.limits.MaxKeys > 0 && d.keys > d.limits.MaxKeys {
+		return nil, fmt.Errorf("%w: map keys exceed %d", ErrTooComplex, d.limits.MaxKeys)
+	}
+
+	m := make(map[string]interface{}, minLength(length, len(d.data)-d.pos))
+	for i := 0; i < length; i++ {
+		key, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+
+		if s, ok := key.(string); ok {
+			m[s] = value
+		} else {
+			m[fmt.Sprint(key)] = value
+		}
+	}
+
+	return m, nil
+}
+
+func (d *msgpackDecoder) enter() error {
+	d.depth++
+	if d.depth > d.limits.MaxDepth {
+		return fmt.Errorf("%w: nesting depth exceeds %d", ErrTooComplex, d.limits.MaxDepth)
+	}
+	return nil
+}
+
+func (d *msgpackDecoder) leave() {
+	d.depth--
+}
+
+// minLength bounds preallocations by the remaining data,
+// since each item takes at least one byte.
+func minLength(length int, remaining int) int {
+	if length < remaining {
+		return length
+	}
+	return remaining
+}
@@ -14,8 +14,9 @@ import (
 const configFileName = "restql.yml"
 
 type requestIDConf struct {
-	Header   string `yaml:"header"`
-	Strategy string `yaml:"strategy"`
+	Header    string   `yaml:"header"`
+	Strategy  string   `yaml:"strategy"`
+	Propagate []string `yaml:"propagate"`
 }
 
 type timeoutConf struct {
@@ -29,6 +30,157 @@ type corsConf struct {
 	ExposeHeaders    string `yaml:"exposeHeaders" env:"RESTQL_CORS_EXPOSE_HEADERS"`
 	MaxAge           int    `yaml:"maxAge" env:"RESTQL_CORS_MAX_AGE"`
 	AllowCredentials bool   `yaml:"allowCredentials" env:"RESTQL_CORS_ALLOW_CREDENTIALS"`
+
+	Tenants map[string]CorsRuleConf `yaml:"tenants"`
+	Paths   map[string]CorsRuleConf `yaml:"paths"`
+}
+
+// CorsRuleConf represents the CORS policy of a tenant or path,
+// where the fields not defined fall back to the global policy.
+type CorsRuleConf struct {
+	AllowOrigin      string `yaml:"allowOrigin"`
+	AllowMethods     string `yaml:"allowMethods"`
+	AllowHeaders     string `yaml:"allowHeaders"`
+	ExposeHeaders    string `yaml:"exposeHeaders"`
+	MaxAge           int    `yaml:"maxAge"`
+	AllowCredentials *bool  `yaml:"allowCredentials"`
+}
+
+type compressionConf struct {
+	MinSize     int `yaml:"minSize"`
+	GzipLevel   int `yaml:"gzipLevel"`
+	BrotliLevel int `yaml:"brotliLevel"`
+}
+
+// AuthenticationConf represents the API keys and JWT
+// validation rules protecting the query endpoints.
+type AuthenticationConf struct {
+	APIKeyHeader string       `yaml:"apiKeyHeader"`
+	APIKeys      []APIKeyConf `yaml:"apiKeys"`
+	JWT          *JWTConf     `yaml:"jwt"`
+}
+
+// APIKeyConf represents a static API key and the
+// namespaces whose saved queries it is allowed to run.
+type APIKeyConf struct {
+	Name       string   `yaml:"name"`
+	Key        string   `yaml:"key"`
+	Namespaces []string `yaml:"namespaces"`
+}
+
+// JWTConf represents the rules to validate bearer tokens,
+// signed with a shared secret or a key of the JWKS endpoint.
+type JWTConf struct {
+	Issuer              string        `yaml:"issuer"`
+	Audience            string        `yaml:"audience"`
+	Secret              string        `yaml:"secret" env:"RESTQL_AUTHENTICATION_JWT_SECRET"`
+	JWKSURL             string        `yaml:"jwksUrl"`
+	JWKSRefreshInterval time.Duration `yaml:"jwksRefreshInterval"`
+	NamespacesClaim     string        `yaml:"namespacesClaim"`
+	Leeway              time.Duration `yaml:"leeway"`
+}
+
+// RateLimitConf represents the token buckets limiting the
+// queries of each tenant and client, and the requests made
+// to each mapped resource.
+type RateLimitConf struct {
+	ClientIDHeader string                       `yaml:"clientIdHeader"`
+	Tenant         *RateLimitRuleConf           `yaml:"tenant"`
+	Tenants        map[string]RateLimitRuleConf `yaml:"tenants"`
+	Client         *RateLimitRuleConf           `yaml:"client"`
+	Clients        map[string]RateLimitRuleConf `yaml:"clients"`
+	Resources      map[string]RateLimitRuleConf `yaml:"resources"`
+	Redis          *RedisConf                   `yaml:"redis"`
+}
+
+// RateLimitRuleConf represents a token bucket refilled
+// with rate tokens per second, holding up to burst tokens.
+type RateLimitRuleConf struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// BulkheadConf represents the limits of concurrent requests
+// to the mapped resources, applied by tenant and resource.
+type BulkheadConf struct {
+	Resource  *BulkheadRuleConf           `yaml:"resource"`
+	Resources map[string]BulkheadRuleConf `yaml:"resources"`
+}
+
+// BulkheadRuleConf represents how many requests to a resource can be
+// in flight and how many can wait, during up to maxWait, for a slot.
+type BulkheadRuleConf struct {
+	MaxConcurrent int           `yaml:"maxConcurrent"`
+	MaxQueue      int           `yaml:"maxQueue"`
+	MaxWait       time.Duration `yaml:"maxWait"`
+}
+
+// WebhookConf represents the endpoint receiving a summary of
+// every query execution, enabled when its URL is defined.
+type WebhookConf struct {
+	URL       string            `yaml:"url" env:"RESTQL_NOTIFICATIONS_WEBHOOK_URL"`
+	Headers   map[string]string `yaml:"headers"`
+	Timeout   time.Duration     `yaml:"timeout"`
+	QueueSize int               `yaml:"queueSize"`
+}
+
+// AuditConf represents the audit log of the query executions, written
+// to a sink of the given type: a JSON lines file at path, an HTTP
+// endpoint at url or a Kafka topic through the brokers REST proxy.
+type AuditConf struct {
+	Sink      string            `yaml:"sink"`
+	Path      string            `yaml:"path"`
+	URL       string            `yaml:"url"`
+	Headers   map[string]string `yaml:"headers"`
+	Topic     string            `yaml:"topic"`
+	Timeout   time.Duration     `yaml:"timeout"`
+	QueueSize int               `yaml:"queueSize"`
+	SavedOnly bool              `yaml:"savedOnly"`
+	Params    AuditParamsConf   `yaml:"params"`
+}
+
+// AuditParamsConf represents how the query parameters are captured
+// by the audit log: kept, redacted, hashed or dropped, either by
+// name, on rules, or by default.
+type AuditParamsConf struct {
+	Default string            `yaml:"default"`
+	Rules   map[string]string `yaml:"rules"`
+}
+
+// MappingImportConf represents an OpenAPI or Swagger document the
+// mappings of a tenant are imported from at startup and, with an
+// interval, periodically re-synced.
+type MappingImportConf struct {
+	Tenant   string        `yaml:"tenant"`
+	Spec     string        `yaml:"spec"`
+	Prefix   string        `yaml:"prefix"`
+	BaseURL  string        `yaml:"baseUrl"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+// KafkaConf represents the Kafka REST Proxy used by the
+// mappings publishing records to a topic.
+type KafkaConf struct {
+	RestProxy string `yaml:"restProxy" env:"RESTQL_BROKERS_KAFKA_REST_PROXY"`
+}
+
+// AMQPConf represents the RabbitMQ management API used by
+// the mappings publishing messages to an exchange.
+type AMQPConf struct {
+	ManagementAPI string `yaml:"managementApi" env:"RESTQL_BROKERS_AMQP_MANAGEMENT_API"`
+	VHost         string `yaml:"vhost" env:"RESTQL_BROKERS_AMQP_VHOST"`
+	Username      string `yaml:"username" env:"RESTQL_BROKERS_AMQP_USERNAME"`
+	Password      string `yaml:"password" env:"RESTQL_BROKERS_AMQP_PASSWORD"`
+}
+
+// RedisConf represents the Redis server keeping the rate
+// limit buckets shared by every restQL instance.
+type RedisConf struct {
+	Addr     string        `yaml:"addr"`
+	Password string        `yaml:"password" env:"RESTQL_RATE_LIMIT_REDIS_PASSWORD"`
+	DB       int           `yaml:"db"`
+	Timeout  time.Duration `yaml:"timeout"`
+	PoolSize int           `yaml:"poolSize"`
 }
 
 type requestCancellationConf struct {
@@ -36,24 +188,261 @@ type requestCancellationConf struct {
 	WatchInterval time.Duration `yaml:"watchInterval"`
 }
 
+// DefaultsConf represents the fallback values for statement
+// execution settings at a level of the defaults cascade.
+type DefaultsConf struct {
+	Timeout time.Duration     `yaml:"timeout"`
+	Retries *int              `yaml:"retries"`
+	MaxAge  *int              `yaml:"maxAge"`
+	SMaxAge *int              `yaml:"sMaxAge"`
+	Headers map[string]string `yaml:"headers"`
+	Params  map[string]string `yaml:"params"`
+
+	HeadersEnv map[string]string `yaml:"headersEnv"`
+	ParamsEnv  map[string]string `yaml:"paramsEnv"`
+
+	ForwardConditionalHeaders *bool               `yaml:"forwardConditionalHeaders"`
+	ForwardHeaders            *ForwardHeadersConf `yaml:"forwardHeaders"`
+	SessionCookies            *bool               `yaml:"sessionCookies"`
+
+	MaxResponseSize        int `yaml:"maxResponseSize"`
+	MaxMultiplexedRequests int `yaml:"maxMultiplexedRequests"`
+
+	Failover struct {
+		URLs        []string `yaml:"urls"`
+		StatusCodes []int    `yaml:"statusCodes"`
+	} `yaml:"failover"`
+
+	Strict         *bool `yaml:"strict"`
+	MaxQueryMemory int   `yaml:"maxQueryMemory"`
+
+	IgnoreErrors *bool `yaml:"ignoreErrors"`
+
+	Proxy *ProxyConf `yaml:"proxy"`
+
+	Normalize      *NormalizeConf      `yaml:"normalize"`
+	Mock           *MockConf           `yaml:"mock"`
+	HealthCheck    *HealthCheckConf    `yaml:"healthCheck"`
+	ResponseSchema *ResponseSchemaConf `yaml:"responseSchema"`
+	Signing        *SigningConf        `yaml:"signing"`
+	Policy         *PolicyConf         `yaml:"policy"`
+	Faults         *FaultsConf         `yaml:"faults"`
+	TLS            *TLSConf            `yaml:"tls"`
+}
+
+// FaultsConf represents the failures injected in the requests to
+// a mapping when fault injection is enabled, each with a probability
+// between 0 and 1, only allowed at the mapping level.
+type FaultsConf struct {
+	Latency      time.Duration `yaml:"latency"`
+	LatencyRate  *float64      `yaml:"latencyRate"`
+	ErrorRate    float64       `yaml:"errorRate"`
+	ErrorStatus  int           `yaml:"errorStatus"`
+	ResetRate    float64       `yaml:"resetRate"`
+	TruncateRate float64       `yaml:"truncateRate"`
+}
+
+// FaultInjectionConf represents if the faults declared for the
+// mappings can be injected, which should only be allowed outside
+// production, and if they are injected in every query or only in
+// the ones sent with the header.
+type FaultInjectionConf struct {
+	Allowed bool   `yaml:"allowed"`
+	Enabled bool   `yaml:"enabled"`
+	Header  string `yaml:"header"`
+}
+
+// ProxyConf represents the egress proxy the requests to the upstreams
+// are routed through, given by an http or socks5 URL. Direct disables
+// a proxy inherited from a less specific level.
+type ProxyConf struct {
+	URL         string `yaml:"url"`
+	Username    string `yaml:"username"`
+	Password    string `yaml:"password"`
+	PasswordEnv string `yaml:"passwordEnv"`
+	Direct      bool   `yaml:"direct"`
+}
+
+// TLSConf represents the settings of the TLS connections to
+// the upstream of a mapping, only allowed at the mapping level.
+type TLSConf struct {
+	MinVersion       string   `yaml:"minVersion"`
+	CipherSuites     []string `yaml:"cipherSuites"`
+	SessionTickets   *bool    `yaml:"sessionTickets"`
+	SessionCacheSize int      `yaml:"sessionCacheSize"`
+	RevocationCheck  string   `yaml:"revocationCheck"`
+}
+
+// PolicyConf represents the methods, path parameters and body size
+// allowed against a mapping, only allowed at the mapping level.
+type PolicyConf struct {
+	Methods     []string `yaml:"methods"`
+	PathParams  []string `yaml:"pathParams"`
+	MaxBodySize int      `yaml:"maxBodySize"`
+}
+
+// SigningConf represents how the requests to the upstream of
+// a mapping are signed, either with an HMAC or with AWS SigV4,
+// only allowed at the mapping level.
+type SigningConf struct {
+	HMAC  *HMACSigningConf  `yaml:"hmac"`
+	SigV4 *SigV4SigningConf `yaml:"sigv4"`
+}
+
+// HMACSigningConf represents the shared secret, given directly or
+// by an environment variable, the requests are signed with.
+type HMACSigningConf struct {
+	KeyID     string `yaml:"keyId"`
+	Secret    string `yaml:"secret"`
+	SecretEnv string `yaml:"secretEnv"`
+	Header    string `yaml:"header"`
+}
+
+// SigV4SigningConf represents the AWS service and region the
+// requests are signed for, with the credentials of the environment.
+type SigV4SigningConf struct {
+	Region  string `yaml:"region"`
+	Service string `yaml:"service"`
+}
+
+// ResponseSchemaConf represents the JSON Schema, given inline or
+// by a file, the responses of a mapping are validated against,
+// only allowed at the mapping level.
+type ResponseSchemaConf struct {
+	Schema interface{} `yaml:"schema"`
+	File   string      `yaml:"file"`
+	Mode   string      `yaml:"mode"`
+}
+
+// HealthCheckConf represents how the upstream of a mapping
+// is probed, only allowed at the mapping level.
+type HealthCheckConf struct {
+	Path string `yaml:"path"`
+}
+
+// ForwardHeadersConf represents which client headers are
+// forwarded to the upstream, replacing the default policy
+// of forwarding every one of them. Profiles add built-in
+// groups of headers, like `locale`, to the allowed ones.
+type ForwardHeadersConf struct {
+	Allow    []string          `yaml:"allow"`
+	Deny     []string          `yaml:"deny"`
+	Rename   map[string]string `yaml:"rename"`
+	Profiles []string          `yaml:"profiles"`
+}
+
+// MockConf represents the canned response served for a
+// mapping, only allowed at the mapping level.
+type MockConf struct {
+	QueryFixtureConf `yaml:",inline"`
+	Latency          time.Duration `yaml:"latency"`
+}
+
+// NormalizeConf represents the rules applied to every
+// response of a mapping, only allowed at the mapping level.
+type NormalizeConf struct {
+	Lift   string            `yaml:"lift"`
+	Drop   []string          `yaml:"drop"`
+	Rename map[string]string `yaml:"rename"`
+}
+
+// TenantDefaultsConf represents the defaults of a tenant
+// and its mappings, along with the namespaces of the saved
+// queries it can run, which are all of them when empty.
+type TenantDefaultsConf struct {
+	DefaultsConf `yaml:",inline"`
+	Mappings     map[string]DefaultsConf `yaml:"mappings"`
+	Namespaces   []string                `yaml:"namespaces"`
+}
+
+// RevisionAliasConf represents a named revision of a saved query,
+// like `stable`, which can send a percentage of its executions to
+// a canary revision.
+type RevisionAliasConf struct {
+	Revision int `yaml:"revision"`
+	Canary   struct {
+		Revision   int `yaml:"revision"`
+		Percentage int `yaml:"percentage"`
+	} `yaml:"canary"`
+}
+
+// QueryTestConf represents a test case of a saved query, executed
+// with the given input against fixed upstream responses.
+type QueryTestConf struct {
+	Name     string                      `yaml:"name"`
+	Revision int                         `yaml:"revision"`
+	Tenant   string                      `yaml:"tenant"`
+	Params   map[string]interface{}      `yaml:"params"`
+	Headers  map[string]string           `yaml:"headers"`
+	Fixtures map[string]QueryFixtureConf `yaml:"fixtures"`
+	Cassette string                      `yaml:"cassette"`
+	Expected map[string]interface{}      `yaml:"expected"`
+}
+
+// QueryFixtureConf represents the response returned
+// for every request made to a resource during a test case.
+type QueryFixtureConf struct {
+	Status  int               `yaml:"status"`
+	Headers map[string]string `yaml:"headers"`
+	Body    interface{}       `yaml:"body"`
+}
+
+// SQLDatabaseConf represents a database queried by the mappings
+// with the sql scheme, along with its named read-only queries.
+type SQLDatabaseConf struct {
+	Driver       string            `yaml:"driver"`
+	DSN          string            `yaml:"dsn"`
+	DSNEnv       string            `yaml:"dsnEnv"`
+	Placeholder  string            `yaml:"placeholder"`
+	MaxOpenConns int               `yaml:"maxOpenConns"`
+	MaxRows      int               `yaml:"maxRows"`
+	Queries      map[string]string `yaml:"queries"`
+}
+
 // Config represents all parameters allowed in restQL runtime.
 type Config struct {
 	HTTP struct {
 		ForwardPrefix        string        `yaml:"forwardPrefix" env:"RESTQL_FORWARD_PREFIX"`
 		GlobalQueryTimeout   time.Duration `env:"RESTQL_QUERY_GLOBAL_TIMEOUT" envDefault:"30s"`
 		QueryResourceTimeout time.Duration `env:"RESTQL_QUERY_RESOURCE_TIMEOUT" envDefault:"5s"`
+		MaxChainDepth        int           `yaml:"maxChainDepth" env:"RESTQL_QUERY_MAX_CHAIN_DEPTH"`
+		FailOnHiddenErrors   bool          `yaml:"failOnHiddenErrors" env:"RESTQL_QUERY_FAIL_ON_HIDDEN_ERRORS"`
+		StatusPolicy         string        `yaml:"statusPolicy" env:"RESTQL_QUERY_STATUS_POLICY"`
+		SLOHeader            bool          `yaml:"sloHeader" env:"RESTQL_QUERY_SLO_HEADER"`
+		TenantHeader         string        `yaml:"tenantHeader" env:"RESTQL_TENANT_HEADER"`
 
 		Server struct {
-			APIAddr         string `env:"RESTQL_PORT,required"`
-			APIHealthAddr   string `env:"RESTQL_HEALTH_PORT,required"`
-			PropfAddr       string `env:"RESTQL_PPROF_PORT"`
-			EnablePprof     bool   `env:"RESTQL_ENABLE_PPROF"`
-			EnableFullPprof bool   `env:"RESTQL_ENABLE_FULL_PPROF"`
-			Admin           struct {
+			APIAddr           string `env:"RESTQL_PORT,required"`
+			APIHealthAddr     string `env:"RESTQL_HEALTH_PORT,required"`
+			PropfAddr         string `env:"RESTQL_PPROF_PORT"`
+			EnablePprof       bool   `env:"RESTQL_ENABLE_PPROF"`
+			EnableFullPprof   bool   `env:"RESTQL_ENABLE_FULL_PPROF"`
+			EnablePprofLabels bool   `env:"RESTQL_ENABLE_PPROF_LABELS"`
+			MemoryBallast     int    `yaml:"memoryBallast" env:"RESTQL_MEMORY_BALLAST"`
+			Admin             struct {
 				Enable            bool   `yaml:"enable" env:"RESTQL_ADMIN_ENABLE"`
 				AuthorizationCode string `yaml:"authorizationCode" env:"RESTQL_ADMIN_AUTHORIZATION_CODE"`
 			} `yaml:"admin"`
 
+			JSONEncoder struct {
+				Name       string `yaml:"name" env:"RESTQL_JSON_ENCODER"`
+				EscapeHTML bool   `yaml:"escapeHTML"`
+				SortKeys   bool   `yaml:"sortKeys"`
+			} `yaml:"jsonEncoder"`
+
+			ProxyCache struct {
+				Enable  bool `yaml:"enable" env:"RESTQL_PROXY_CACHE_ENABLE"`
+				MaxSize int  `yaml:"maxSize" env:"RESTQL_PROXY_CACHE_MAX_SIZE"`
+			} `yaml:"proxyCache"`
+
+			Stream struct {
+				MaxSubscriptionDuration time.Duration `yaml:"maxSubscriptionDuration" env:"RESTQL_STREAM_MAX_SUBSCRIPTION_DURATION"`
+			} `yaml:"stream"`
+
+			Batch struct {
+				MaxQueries int `yaml:"maxQueries" env:"RESTQL_BATCH_MAX_QUERIES"`
+			} `yaml:"batch"`
+
 			GracefulShutdownTimeout time.Duration `yaml:"gracefulShutdownTimeout"`
 			ReadTimeout             time.Duration `yaml:"readTimeout"`
 			IdleTimeout             time.Duration `yaml:"idleTimeout"`
@@ -63,6 +452,8 @@ type Config struct {
 				Timeout             *timeoutConf             `yaml:"timeout"`
 				Cors                *corsConf                `yaml:"cors"`
 				RequestCancellation *requestCancellationConf `yaml:"requestCancellation"`
+				Compression         *compressionConf         `yaml:"compression"`
+				Authentication      *AuthenticationConf      `yaml:"authentication"`
 			} `yaml:"middlewares"`
 		} `yaml:"server"`
 
@@ -74,44 +465,125 @@ type Config struct {
 			MaxIdleConns        int           `yaml:"maxIdleConnections"`
 			MaxIdleConnsPerHost int           `yaml:"maxIdleConnectionsPerHost"`
 			MaxIdleConnDuration time.Duration `yaml:"maxIdleConnectionDuration"`
+			MaxBodyDepth        int           `yaml:"maxBodyDepth" env:"RESTQL_CLIENT_MAX_BODY_DEPTH"`
+			MaxBodyKeys         int           `yaml:"maxBodyKeys" env:"RESTQL_CLIENT_MAX_BODY_KEYS"`
+
+			DNS struct {
+				MinTTL      time.Duration `yaml:"minTTL"`
+				MaxTTL      time.Duration `yaml:"maxTTL"`
+				NegativeTTL time.Duration `yaml:"negativeTTL"`
+			} `yaml:"dns"`
+
+			Cassette struct {
+				Mode string `yaml:"mode" env:"RESTQL_CASSETTE_MODE"`
+				Path string `yaml:"path" env:"RESTQL_CASSETTE_PATH"`
+
+				Executions int `yaml:"executions" env:"RESTQL_CASSETTE_EXECUTIONS"`
+			} `yaml:"cassette"`
 		} `yaml:"client"`
 	} `yaml:"http"`
 
 	Logging struct {
-		Enable               bool   `yaml:"enable" env:"RESTQL_LOGGING_ENABLE"`
-		TimestampFieldName   string `yaml:"timestampFieldName"`
-		TimestampFieldFormat string `yaml:"timestampFieldFormat"`
-		Level                string `yaml:"level" env:"RESTQL_LOGGING_LEVEL"`
-		Format               string `yaml:"format"`
+		Enable               bool              `yaml:"enable" env:"RESTQL_LOGGING_ENABLE"`
+		TimestampFieldName   string            `yaml:"timestampFieldName"`
+		TimestampFieldFormat string            `yaml:"timestampFieldFormat"`
+		Level                string            `yaml:"level" env:"RESTQL_LOGGING_LEVEL"`
+		Format               string            `yaml:"format"`
+		Sampling             map[string]uint64 `yaml:"sampling"`
+		AccessLog            bool              `yaml:"accessLog" env:"RESTQL_LOGGING_ACCESS_LOG"`
 	} `yaml:"logging"`
 
+	Notifications struct {
+		Webhook WebhookConf `yaml:"webhook"`
+	} `yaml:"notifications"`
+
+	Audit *AuditConf `yaml:"audit"`
+
+	SQL struct {
+		Databases map[string]SQLDatabaseConf `yaml:"databases"`
+	} `yaml:"sql"`
+
+	S3 struct {
+		Region    string `yaml:"region" env:"RESTQL_S3_REGION"`
+		Endpoint  string `yaml:"endpoint" env:"RESTQL_S3_ENDPOINT"`
+		PathStyle bool   `yaml:"pathStyle" env:"RESTQL_S3_PATH_STYLE"`
+	} `yaml:"s3"`
+
+	Brokers struct {
+		Kafka KafkaConf `yaml:"kafka"`
+		AMQP  AMQPConf  `yaml:"amqp"`
+	} `yaml:"brokers"`
+
+	Health struct {
+		ProbeTimeout time.Duration `yaml:"probeTimeout" env:"RESTQL_HEALTH_PROBE_TIMEOUT"`
+	} `yaml:"health"`
+
+	Snapshot struct {
+		Path     string        `yaml:"path" env:"RESTQL_SNAPSHOT_PATH"`
+		Interval time.Duration `yaml:"interval" env:"RESTQL_SNAPSHOT_INTERVAL"`
+	} `yaml:"snapshot"`
+
 	Cache struct {
 		Mappings struct {
 			MaxSize            int           `yaml:"maxSize" env:"RESTQL_CACHE_MAPPINGS_MAX_SIZE"`
 			Expiration         time.Duration `yaml:"expiration" env:"RESTQL_CACHE_MAPPINGS_EXPIRATION"`
 			RefreshInterval    time.Duration `yaml:"refreshInterval" env:"RESTQL_CACHE_MAPPINGS_REFRESH_INTERVAL"`
 			RefreshQueueLength int           `yaml:"refreshQueueLength" env:"RESTQL_CACHE_MAPPINGS_REFRESH_QUEUE_LENGTH"`
+			WarmUp             bool          `yaml:"warmUp" env:"RESTQL_CACHE_MAPPINGS_WARM_UP"`
+			FailureMode        string        `yaml:"failureMode" env:"RESTQL_CACHE_MAPPINGS_FAILURE_MODE"`
 		} `yaml:"mappings"`
 		Query struct {
 			MaxSize int `yaml:"maxSize" env:"RESTQL_CACHE_QUERY_MAX_SIZE"`
 		} `yaml:"query"`
 		Parser struct {
-			MaxSize int `yaml:"maxSize" env:"RESTQL_CACHE_PARSER_MAX_SIZE"`
+			MaxSize int           `yaml:"maxSize" env:"RESTQL_CACHE_PARSER_MAX_SIZE"`
+			TTL     time.Duration `yaml:"ttl" env:"RESTQL_CACHE_PARSER_TTL"`
 		} `yaml:"parser"`
+		Responses struct {
+			MaxSize int `yaml:"maxSize" env:"RESTQL_CACHE_RESPONSES_MAX_SIZE"`
+		} `yaml:"responses"`
 	} `yaml:"cache"`
 
+	Debug struct {
+		RedactHeaders        []string `yaml:"redactHeaders" env:"RESTQL_DEBUG_REDACT_HEADERS"`
+		RedactHeaderPatterns []string `yaml:"redactHeaderPatterns" env:"RESTQL_DEBUG_REDACT_HEADER_PATTERNS"`
+	} `yaml:"debug"`
+
+	RateLimit *RateLimitConf `yaml:"rateLimit"`
+
+	Bulkhead *BulkheadConf `yaml:"bulkhead"`
+
+	FaultInjection *FaultInjectionConf `yaml:"faultInjection"`
+
 	Plugins struct {
 		DisableDatabase bool `yaml:"disableDatabase" env:"RESTQL_PLUGINS_DATABASE_DISABLE"`
 	} `yaml:"plugins"`
 
+	Defaults struct {
+		DefaultsConf `yaml:",inline"`
+		Tenants      map[string]TenantDefaultsConf `yaml:"tenants"`
+		Mappings     map[string]DefaultsConf       `yaml:"mappings"`
+		Namespaces   map[string]DefaultsConf       `yaml:"namespaces"`
+	} `yaml:"defaults"`
+
+	Profile string `yaml:"profile" env:"RESTQL_PROFILE"`
+
 	Tenant string `env:"RESTQL_TENANT"`
 
 	Mappings map[string]string `yaml:"mappings"`
 
 	TenantMappings map[string]map[string]string `yaml:"tenants"`
 
+	MappingImports []MappingImportConf `yaml:"mappingImports"`
+
 	Queries map[string]map[string][]string `yaml:"queries"`
 
+	QueryTests map[string]map[string][]QueryTestConf `yaml:"queryTests"`
+
+	QueryHeaders map[string]map[string]map[string]string `yaml:"queryHeaders"`
+
+	RevisionAliases map[string]map[string]map[string]RevisionAliasConf `yaml:"revisionAliases"`
+
 	Env EnvSource
 
 	Build string
@@ -124,7 +596,12 @@ func Load(build string) (*Config, error) {
 	cfg := Config{}
 	readDefaults(&cfg)
 
-	err := yaml.Unmarshal(readConfigFile(), &cfg)
+	fileContent, err := applyProfile(readConfigFile())
+	if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(fileContent, &cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -140,6 +617,16 @@ func Load(build string) (*Config, error) {
 	return &cfg, nil
 }
 
+// Default returns a Config built only from the defaults,
+// for runtimes that are not configured by the YAML file
+// and environment variables, like the embedded engine.
+func Default() *Config {
+	cfg := Config{}
+	readDefaults(&cfg)
+
+	return &cfg
+}
+
 func readConfigFile() []byte {
 	path := getConfigFilepath()
 	if path == "" {
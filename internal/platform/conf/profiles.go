@@ -0,0 +1,89 @@
+package conf
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	profileEnvName  = "RESTQL_PROFILE"
+	profileField    = "profile"
+	profilesSection = "profiles"
+)
+
+// ErrUnknownProfile represents the event of activating a profile
+// that is not defined in the configuration file, which fails the
+// startup instead of running with the base configuration.
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// applyProfile takes the content of a configuration file and
+// deep merges the section of the active profile over it.
+//
+// The active profile is defined by the RESTQL_PROFILE environment
+// variable or, when it is absent, by the `profile` field in the file.
+// Maps are merged recursively while any other value present in the
+// profile replaces the one in the base configuration. Activating a
+// profile that is not defined fails with ErrUnknownProfile.
+func applyProfile(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	var base map[interface{}]interface{}
+	err := yaml.Unmarshal(data, &base)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := activeProfile(base)
+	if profile == "" {
+		return data, nil
+	}
+
+	profiles, ok := base[profilesSection].(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownProfile, "profile %s is active but no profiles are defined", profile)
+	}
+
+	profileCfg, ok := profiles[profile].(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownProfile, "profile %s not found in config file", profile)
+	}
+
+	delete(base, profilesSection)
+	merged := deepMerge(base, profileCfg)
+	merged[profileField] = profile
+
+	return yaml.Marshal(merged)
+}
+
+func activeProfile(base map[interface{}]interface{}) string {
+	if p := os.Getenv(profileEnvName); p != "" {
+		return p
+	}
+
+	p, _ := base[profileField].(string)
+	return p
+}
+
+func deepMerge(dst, src map[interface{}]interface{}) map[interface{}]interface{} {
+	result := make(map[interface{}]interface{}, len(dst))
+	for k, v := range dst {
+		result[k] = v
+	}
+
+	for k, srcValue := range src {
+		srcMap, srcIsMap := srcValue.(map[interface{}]interface{})
+		dstMap, dstIsMap := result[k].(map[interface{}]interface{})
+		if srcIsMap && dstIsMap {
+			result[k] = deepMerge(dstMap, srcMap)
+			continue
+		}
+
+		result[k] = srcValue
+	}
+
+	return result
+}
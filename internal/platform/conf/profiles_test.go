@@ -0,0 +1,108 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"gopkg.in/yaml.v2"
+)
+
+const profileTestConfig = `
+profile: dev
+
+http:
+  forwardPrefix: c_
+  server:
+    readTimeout: 3s
+    idleTimeout: 5s
+
+mappings:
+  hero: http://hero.dev/hero
+  sidekick: http://sidekick.dev/sidekick
+
+profiles:
+  dev:
+    logging:
+      level: debug
+  prod:
+    http:
+      server:
+        readTimeout: 1s
+    mappings:
+      hero: http://hero.prod/hero
+`
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         string
+		expected    string
+		expectedErr error
+	}{
+		{
+			"should apply profile defined in config file",
+			"",
+			`
+profile: dev
+http:
+  forwardPrefix: c_
+  server:
+    readTimeout: 3s
+    idleTimeout: 5s
+mappings:
+  hero: http://hero.dev/hero
+  sidekick: http://sidekick.dev/sidekick
+logging:
+  level: debug
+`,
+			nil,
+		},
+		{
+			"should deep merge profile defined in environment variable",
+			"prod",
+			`
+profile: prod
+http:
+  forwardPrefix: c_
+  server:
+    readTimeout: 1s
+    idleTimeout: 5s
+mappings:
+  hero: http://hero.prod/hero
+  sidekick: http://sidekick.dev/sidekick
+`,
+			nil,
+		},
+		{
+			"should fail when profile is unknown",
+			"staging",
+			"",
+			ErrUnknownProfile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv(profileEnvName, tt.env)
+			defer os.Unsetenv(profileEnvName)
+
+			got, err := applyProfile([]byte(profileTestConfig))
+			if tt.expectedErr != nil {
+				test.Equal(t, errors.Is(err, tt.expectedErr), true)
+				return
+			}
+			test.VerifyError(t, err)
+
+			test.Equal(t, unmarshalYAML(t, got), unmarshalYAML(t, []byte(tt.expected)))
+		})
+	}
+}
+
+func unmarshalYAML(t *testing.T, data []byte) map[interface{}]interface{} {
+	var m map[interface{}]interface{}
+	err := yaml.Unmarshal(data, &m)
+	test.VerifyError(t, err)
+	return m
+}
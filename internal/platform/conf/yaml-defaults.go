@@ -11,6 +11,16 @@ http:
     readTimeout: 3s
     idleTimeout: 5s
     gracefulShutdownTimeout: 1s
+    jsonEncoder:
+      name: std
+      escapeHTML: true
+      sortKeys: true
+    proxyCache:
+      maxSize: 1000
+    stream:
+      maxSubscriptionDuration: 60s
+    batch:
+      maxQueries: 20
     middlewares:
       requestCancellation:
         enabled: false
@@ -22,6 +32,13 @@ http:
     writeTimeout: 1s
     maxIdleConnectionsPerHost: 512
     maxIdleConnectionDuration: 10s
+    maxBodyDepth: 100
+    maxBodyKeys: 1000000
+    dns:
+      minTTL: 30s
+      negativeTTL: 5s
+    cassette:
+      executions: 10
 
 logging:
   enable: true
@@ -29,13 +46,38 @@ logging:
   level: info
   format: json
 
+health:
+  probeTimeout: 1s
+
+snapshot:
+  interval: 30s
+
 cache:
   mappings:
     maxSize: 100
+    expiration: 10m
+    refreshInterval: 10s
+    refreshQueueLength: 100
+    warmUp: true
+    failureMode: serve-stale
   query:
     maxSize: 100
   parser:
     maxSize: 100
+  responses:
+    maxSize: 1000
+
+debug:
+  redactHeaders:
+    - Authorization
+    - Proxy-Authorization
+    - Cookie
+    - Set-Cookie
+  redactHeaderPatterns:
+    - (?i)token
+    - (?i)secret
+    - (?i)api-?key
+    - (?i)password
 
 database:
   timeout: 1000
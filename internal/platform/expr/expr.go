@@ -0,0 +1,485 @@
+// Package expr implements the small expression language used to
+// transform values in queries, as in `lower(value) + '-' + $suffix`.
+//
+// Expressions operate on JSON values: null, booleans, numbers, strings,
+// lists and objects. The value being transformed is referred to as
+// `value` and the query variables by their name prefixed by `$`. They
+// support the arithmetic, comparison and logical operators, the ternary
+// conditional, field and index access and a set of built-in functions.
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidExpression is returned when
+// an expression cannot be compiled.
+var ErrInvalidExpression = errors.New("invalid expression")
+
+// ErrEvaluation is returned when the evaluation of an
+// expression fails, like on operands of the wrong type.
+var ErrEvaluation = errors.New("expression evaluation failed")
+
+// maxLength bounds the size of the expressions, which
+// are compiled from the text of client provided queries.
+const maxLength = 1024
+
+// Program represents a compiled expression.
+type Program struct {
+	source    string
+	root      node
+	variables []string
+}
+
+// Compile parses the expression, checking that
+// it only calls known functions with valid arity.
+func Compile(source string) (*Program, error) {
+	if len(source) > maxLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrInvalidExpression, maxLength)
+	}
+
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidExpression, err)
+	}
+
+	p := &parser{tokens: tokens, variables: make(map[string]bool)}
+	root, err := p.parseExpression()
+	if err == nil && p.peek().kind != eofToken {
+		err = errors.Errorf("unexpected %s at position %d", p.peek(), p.peek().pos)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidExpression, err)
+	}
+
+	return &Program{source: source, root: root, variables: p.order}, nil
+}
+
+// Variables returns the names of the query
+// variables used by the expression, without `$`.
+func (p *Program) Variables() []string {
+	return p.variables
+}
+
+// String returns the source of the expression.
+func (p *Program) String() string {
+	return p.source
+}
+
+// Eval returns the result of the expression for the value
+// and the query variables, where missing ones are null.
+func (p *Program) Eval(value interface{}, variables map[string]interface{}) (result interface{}, err error) {
+	env := environment{value: normalize(value), variables: variables}
+	result, err = p.root.eval(env)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrEvaluation, err)
+	}
+	return result, nil
+}
+
+type environment struct {
+	value     interface{}
+	variables map[string]interface{}
+}
+
+// normalize converts the numbers to float64,
+// the only numeric type used by expressions.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case json.Number:
+		n, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		return n
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalize(item)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			result[k] = normalize(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+type node interface {
+	eval(env environment) (interface{}, error)
+}
+
+type literal struct {
+	value interface{}
+}
+
+func (l literal) eval(env environment) (interface{}, error) {
+	return l.value, nil
+}
+
+type valueRef struct{}
+
+func (valueRef) eval(env environment) (interface{}, error) {
+	return env.value, nil
+}
+
+type variableRef struct {
+	name string
+}
+
+func (v variableRef) eval(env environment) (interface{}, error) {
+	return normalize(env.variables[v.name]), nil
+}
+
+type listNode struct {
+	items []node
+}
+
+func (l listNode) eval(env environment) (interface{}, error) {
+	result := make([]interface{}, len(l.items))
+	for i, item := range l.items {
+		v, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = v
+	}
+	return result, nil
+}
+
+type fieldAccess struct {
+	target node
+	field  string
+}
+
+func (f fieldAccess) eval(env environment) (interface{}, error) {
+	target, err := f.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch target := target.(type) {
+	case map[string]interface{}:
+		return target[f.field], nil
+	case nil:
+		return nil, nil
+	default:
+		return nil, errors.Errorf("cannot access field %s of %s", f.field, typeName(target))
+	}
+}
+
+type indexAccess struct {
+	target node
+	index  node
+}
+
+func (ia indexAccess) eval(env environment) (interface{}, error) {
+	target, err := ia.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	index, err := ia.index.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch target := target.(type) {
+	case []interface{}:
+		i, ok := index.(float64)
+		if !ok {
+			return nil, errors.Errorf("cannot index list with %s", typeName(index))
+		}
+		if i < 0 {
+			i += float64(len(target))
+		}
+		// checked before converting, as huge or NaN
+		// indexes do not fit an int
+		if !(i >= 0 && i < float64(len(target))) {
+			return nil, nil
+		}
+		return target[int(i)], nil
+	case map[string]interface{}:
+		key, ok := index.(string)
+		if !ok {
+			return nil, errors.Errorf("cannot index object with %s", typeName(index))
+		}
+		return target[key], nil
+	case nil:
+		return nil, nil
+	default:
+		return nil, errors.Errorf("cannot index %s", typeName(target))
+	}
+}
+
+type unaryOp struct {
+	op      string
+	operand node
+}
+
+func (u unaryOp) eval(env environment) (interface{}, error) {
+	v, err := u.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.op == "!" {
+		return !truthy(v), nil
+	}
+
+	n, ok := v.(float64)
+	if !ok {
+		return nil, errors.Errorf("cannot negate %s", typeName(v))
+	}
+	return -n, nil
+}
+
+type binaryOp struct {
+	op          string
+	left, right node
+}
+
+func (b binaryOp) eval(env environment) (interface{}, error) {
+	left, err := b.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch b.op {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+		right, err := b.right.eval(env)
+		return truthy(right), err
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := b.right.eval(env)
+		return truthy(right), err
+	}
+
+	right, err := b.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch b.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "+":
+		ls, lok := left.(string)
+		rs, rok := right.(string)
+		if lok || rok {
+			if !lok {
+				ls = stringify(left)
+			}
+			if !rok {
+				rs = stringify(right)
+			}
+			return ls + rs, nil
+		}
+		ll, lok := left.([]interface{})
+		rl, rok := right.([]interface{})
+		if lok && rok {
+			return append(append([]interface{}{}, ll...), rl...), nil
+		}
+	case "<", "<=", ">", ">=":
+		return compare(b.op, left, right)
+	}
+
+	ln, lok := left.(float64)
+	rn, rok := right.(float64)
+	if !lok || !rok {
+		return nil, errors.Errorf("invalid operands %s and %s for %s", typeName(left), typeName(right), b.op)
+	}
+
+	switch b.op {
+	case "+":
+		return ln + rn, nil
+	case "-":
+		return ln - rn, nil
+	case "*":
+		return ln * rn, nil
+	case "/":
+		if rn == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return ln / rn, nil
+	case "%":
+		if rn == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return math.Mod(ln, rn), nil
+	default:
+		return nil, errors.Errorf("unknown operator %s", b.op)
+	}
+}
+
+type conditional struct {
+	condition, then, otherwise node
+}
+
+func (c conditional) eval(env environment) (interface{}, error) {
+	v, err := c.condition.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(v) {
+		return c.then.eval(env)
+	}
+	return c.otherwise.eval(env)
+}
+
+type call struct {
+	fn   function
+	args []node
+}
+
+func (c call) eval(env environment) (interface{}, error) {
+	args := make([]interface{}, len(c.args))
+	for i, arg := range c.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	return c.fn.call(args)
+}
+
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
+
+func equal(left, right interface{}) bool {
+	switch l := left.(type) {
+	case []interface{}:
+		r, ok := right.([]interface{})
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for i := range l {
+			if !equal(l[i], r[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		r, ok := right.(map[string]interface{})
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for k, v := range l {
+			if !equal(v, r[k]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return left == right
+	}
+}
+
+func compare(op string, left, right interface{}) (interface{}, error) {
+	var c int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return nil, errors.Errorf("cannot compare number with %s", typeName(right))
+		}
+		switch {
+		case l < r:
+			c = -1
+		case l > r:
+			c = 1
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return nil, errors.Errorf("cannot compare string with %s", typeName(right))
+		}
+		c = strings.Compare(l, r)
+	default:
+		return nil, errors.Errorf("cannot compare %s", typeName(left))
+	}
+
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+// stringify returns the text of the value, with integral
+// numbers written without decimals and null as empty.
+func stringify(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
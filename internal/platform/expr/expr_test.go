@@ -0,0 +1,117 @@
+package expr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/expr"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestEval(t *testing.T) {
+	product := map[string]interface{}{"title": "Bat Rope", "price": 10.5, "tags": []interface{}{"gear", "rope"}}
+
+	tests := []struct {
+		name       string
+		expression string
+		value      interface{}
+		variables  map[string]interface{}
+		expected   interface{}
+	}{
+		{"should concatenate strings", `lower(value) + '-' + $suffix`, "Batman", map[string]interface{}{"suffix": "dc"}, "batman-dc"},
+		{"should concatenate numbers to strings", `'page-' + (value + 1)`, 1, nil, "page-2"},
+		{"should evaluate arithmetic by precedence", `value * 2 + 10 / 4 - 7 % 4`, 3, nil, 5.5},
+		{"should negate values", `string(-value) + string(!false)`, 2, nil, "-2true"},
+		{"should compare values", `value >= 10 && value < 20 || $force`, 15, nil, true},
+		{"should evaluate conditional", `value == null ? 'none' : value`, nil, nil, "none"},
+		{"should access fields and indexes", `value.tags[-1] + ':' + value['title']`, product, nil, "rope:Bat Rope"},
+		{"should access missing fields as null", `value.brand.name`, product, nil, nil},
+		{"should treat missing variables as null", `default($missing, value)`, "batman", nil, "batman"},
+		{"should build lists", `len([1, 'a', value] + split('x,y', ','))`, nil, nil, float64(5)},
+		{"should call string functions", `upper(substr(replace(trim(value), ' ', '_'), 0, 5))`, "  bat rope ", nil, "BAT_R"},
+		{"should check string affixes", `startsWith(value, 'bat') && endsWith(value, 'man') && contains(value, 'tm')`, "batman", nil, true},
+		{"should check list membership", `contains(value.tags, 'rope')`, product, nil, true},
+		{"should join lists", `join(value.tags, '|')`, product, nil, "gear|rope"},
+		{"should convert values", `number(value) + 1 + string(2)`, "41", nil, "422"},
+		{"should round numbers", `round(value, 1)`, 2.345, nil, 2.3},
+		{"should access huge indexes as null", `value.tags[10000000000000000000]`, product, nil, nil},
+		{"should access huge negative indexes as null", `value.tags[-10000000000000000000]`, product, nil, nil},
+		{"should clamp huge substring positions", `substr(value, -10000000000000000000, 10000000000000000000)`, "batman", nil, "batman"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := expr.Compile(tt.expression)
+			test.VerifyError(t, err)
+
+			got, err := program.Eval(tt.value, tt.variables)
+			test.VerifyError(t, err)
+			test.Equal(t, got, tt.expected)
+		})
+	}
+}
+
+func TestCompileVariables(t *testing.T) {
+	program, err := expr.Compile(`$first-name + value + $last + $first-name`)
+	test.VerifyError(t, err)
+
+	test.Equal(t, program.Variables(), []string{"first-name", "last"})
+}
+
+func TestCompileInvalidExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+	}{
+		{"unknown function", `capitalize(value)`, "invalid expression: unknown identifier capitalize at position 0"},
+		{"wrong number of arguments", `lower(value, 'x')`, "invalid expression: wrong number of arguments for lower at position 0"},
+		{"unterminated string", `value + 'abc`, "invalid expression: unterminated string at position 8"},
+		{"unexpected character", `value # 2`, "invalid expression: unexpected character '#' at position 6"},
+		{"trailing tokens", `value value`, "invalid expression: unexpected \"value\" at position 6"},
+		{"missing operand", `value +`, "invalid expression: unexpected end of expression at position 7"},
+		{"nested too deep", strings.Repeat("(", 40) + "value" + strings.Repeat(")", 40), "invalid expression: nested deeper than 32 levels"},
+		{"too long", strings.Repeat("1+", 600) + "1", "invalid expression: longer than 1024 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := expr.Compile(tt.expression)
+			if err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			test.Equal(t, errors.Is(err, expr.ErrInvalidExpression), true)
+			test.Equal(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestEvalError(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		value      interface{}
+		expected   string
+	}{
+		{"function argument of wrong type", `lower(value)`, 10, "expression evaluation failed: lower: argument 1 must be a string, not number"},
+		{"arithmetic on strings", `value * 2`, "batman", "expression evaluation failed: invalid operands string and number for *"},
+		{"division by zero", `1 / value`, 0, "expression evaluation failed: division by zero"},
+		{"field of string", `value.name`, "batman", "expression evaluation failed: cannot access field name of string"},
+		{"number conversion", `number(value)`, "abc", "expression evaluation failed: number: cannot convert \"abc\" to number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := expr.Compile(tt.expression)
+			test.VerifyError(t, err)
+
+			_, err = program.Eval(tt.value, nil)
+			if err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			test.Equal(t, errors.Is(err, expr.ErrEvaluation), true)
+			test.Equal(t, err.Error(), tt.expected)
+		})
+	}
+}
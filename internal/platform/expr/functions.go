@@ -0,0 +1,274 @@
+package expr
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+type function struct {
+	name    string
+	minArgs int
+	maxArgs int
+	impl    func(args []interface{}) (interface{}, error)
+}
+
+func (f function) call(args []interface{}) (interface{}, error) {
+	result, err := f.impl(args)
+	if err != nil {
+		return nil, errors.Wrap(err, f.name)
+	}
+	return result, nil
+}
+
+// functions holds the built-in functions, by name.
+var functions = map[string]function{}
+
+func init() {
+	for _, fn := range []function{
+		{"lower", 1, 1, stringFunction(strings.ToLower)},
+		{"upper", 1, 1, stringFunction(strings.ToUpper)},
+		{"trim", 1, 1, stringFunction(strings.TrimSpace)},
+		{"len", 1, 1, length},
+		{"substr", 2, 3, substr},
+		{"replace", 3, 3, replace},
+		{"split", 2, 2, split},
+		{"join", 2, 2, join},
+		{"contains", 2, 2, containsValue},
+		{"startsWith", 2, 2, stringPredicate(strings.HasPrefix)},
+		{"endsWith", 2, 2, stringPredicate(strings.HasSuffix)},
+		{"string", 1, 1, toString},
+		{"number", 1, 1, toNumber},
+		{"round", 1, 2, round},
+		{"default", 2, 2, defaultValue},
+	} {
+		functions[fn.name] = fn
+	}
+}
+
+func stringArg(args []interface{}, i int) (string, error) {
+	s, ok := args[i].(string)
+	if !ok {
+		return "", errors.Errorf("argument %d must be a string, not %s", i+1, typeName(args[i]))
+	}
+	return s, nil
+}
+
+func numberArg(args []interface{}, i int) (float64, error) {
+	n, ok := args[i].(float64)
+	if !ok {
+		return 0, errors.Errorf("argument %d must be a number, not %s", i+1, typeName(args[i]))
+	}
+	return n, nil
+}
+
+func stringFunction(fn func(string) string) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		s, err := stringArg(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		return fn(s), nil
+	}
+}
+
+func stringPredicate(fn func(string, string) bool) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		s, err := stringArg(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		affix, err := stringArg(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return fn(s, affix), nil
+	}
+}
+
+func length(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case string:
+		return float64(utf8.RuneCountInString(v)), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	case nil:
+		return float64(0), nil
+	default:
+		return nil, errors.Errorf("argument 1 must be a string, list or object, not %s", typeName(v))
+	}
+}
+
+// substr returns the characters from start up to end, exclusive,
+// or up to the end of the string, where negative positions count
+// from the end of the string.
+func substr(args []interface{}) (interface{}, error) {
+	s, err := stringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	runes := []rune(s)
+
+	start, err := numberArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	end := float64(len(runes))
+	if len(args) == 3 {
+		if end, err = numberArg(args, 2); err != nil {
+			return nil, err
+		}
+	}
+
+	from, to := clampIndex(start, len(runes)), clampIndex(end, len(runes))
+	if from >= to {
+		return "", nil
+	}
+	return string(runes[from:to]), nil
+}
+
+func clampIndex(i float64, length int) int {
+	if i < 0 {
+		i += float64(length)
+	}
+	if !(i > 0) {
+		return 0
+	}
+	if i > float64(length) {
+		return length
+	}
+	return int(i)
+}
+
+func replace(args []interface{}) (interface{}, error) {
+	s, err := stringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	old, err := stringArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	replacement, err := stringArg(args, 2)
+	if err != nil {
+		return nil, err
+	}
+	return strings.ReplaceAll(s, old, replacement), nil
+}
+
+func split(args []interface{}) (interface{}, error) {
+	s, err := stringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	sep, err := stringArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(s, sep)
+	result := make([]interface{}, len(parts))
+	for i, p := range parts {
+		result[i] = p
+	}
+	return result, nil
+}
+
+func join(args []interface{}) (interface{}, error) {
+	list, ok := args[0].([]interface{})
+	if !ok {
+		return nil, errors.Errorf("argument 1 must be a list, not %s", typeName(args[0]))
+	}
+	sep, err := stringArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, len(list))
+	for i, item := range list {
+		texts[i] = stringify(item)
+	}
+	return strings.Join(texts, sep), nil
+}
+
+func containsValue(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case string:
+		sub, err := stringArg(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return strings.Contains(v, sub), nil
+	case []interface{}:
+		for _, item := range v {
+			if equal(item, args[1]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]interface{}:
+		key, err := stringArg(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		_, found := v[key]
+		return found, nil
+	default:
+		return nil, errors.Errorf("argument 1 must be a string, list or object, not %s", typeName(v))
+	}
+}
+
+func toString(args []interface{}) (interface{}, error) {
+	return stringify(args[0]), nil
+}
+
+func toNumber(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return float64(1), nil
+		}
+		return float64(0), nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, errors.Errorf("cannot convert %q to number", v)
+		}
+		return n, nil
+	default:
+		return nil, errors.Errorf("cannot convert %s to number", typeName(v))
+	}
+}
+
+// round rounds the number half away from zero,
+// to the given number of decimal places.
+func round(args []interface{}) (interface{}, error) {
+	n, err := numberArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var places float64
+	if len(args) == 2 {
+		if places, err = numberArg(args, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	scale := math.Pow(10, math.Trunc(places))
+	return math.Round(n*scale) / scale, nil
+}
+
+func defaultValue(args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return args[1], nil
+	}
+	return args[0], nil
+}
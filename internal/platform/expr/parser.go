@@ -0,0 +1,378 @@
+package expr
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type tokenKind int
+
+const (
+	eofToken tokenKind = iota
+	numberToken
+	stringToken
+	identToken
+	variableToken
+	operatorToken
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == eofToken {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// operators holds the symbols of the language,
+// the ones with two characters first.
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "+", "-", "*", "/", "%", "<", ">", "!", "?", ":", "(", ")", "[", "]", ",", "."}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isDigit(c):
+			start := i
+			for i < len(source) && (isDigit(source[i]) || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: numberToken, text: source[start:i], pos: start})
+		case c == '\'' || c == '"':
+			text, end, err := readString(source, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: stringToken, text: text, pos: i})
+			i = end
+		case c == '$':
+			start := i
+			i++
+			for i < len(source) && (isIdentChar(source[i]) || source[i] == '-') {
+				i++
+			}
+			if i == start+1 {
+				return nil, errors.Errorf("missing variable name at position %d", start)
+			}
+			tokens = append(tokens, token{kind: variableToken, text: source[start+1 : i], pos: start})
+		case isIdentStart(c):
+			start := i
+			for i < len(source) && isIdentChar(source[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: identToken, text: source[start:i], pos: start})
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(source[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, errors.Errorf("unexpected character %q at position %d", c, i)
+			}
+			tokens = append(tokens, token{kind: operatorToken, text: op, pos: i})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: eofToken, pos: len(source)}), nil
+}
+
+// readString reads the quoted text starting at the position,
+// where a backslash escapes the quote or another backslash.
+func readString(source string, start int) (string, int, error) {
+	quote := source[start]
+	var sb strings.Builder
+	for i := start + 1; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			if i+1 < len(source) {
+				i++
+				sb.WriteByte(source[i])
+			}
+		case quote:
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteByte(source[i])
+		}
+	}
+	return "", 0, errors.Errorf("unterminated string at position %d", start)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+// maxDepth bounds the nesting of the expressions.
+const maxDepth = 32
+
+type parser struct {
+	tokens    []token
+	pos       int
+	depth     int
+	variables map[string]bool
+	order     []string
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != eofToken {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) accept(op string) bool {
+	t := p.peek()
+	if t.kind == operatorToken && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		return errors.Errorf("expected %q but found %s at position %d", op, p.peek(), p.peek().pos)
+	}
+	return nil
+}
+
+func (p *parser) parseExpression() (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, errors.Errorf("nested deeper than %d levels", maxDepth)
+	}
+
+	condition, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("?") {
+		return condition, nil
+	}
+
+	then, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	return conditional{condition: condition, then: then, otherwise: otherwise}, nil
+}
+
+// precedence lists the binary operators from the loosest binding.
+var precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level == len(precedence) {
+		return p.parseUnary()
+	}
+
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		if t.kind != operatorToken || !contains(precedence[level], t.text) {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryOp{op: t.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	for _, op := range []string{"!", "-"} {
+		if p.accept(op) {
+			p.depth++
+			defer func() { p.depth-- }()
+			if p.depth > maxDepth {
+				return nil, errors.Errorf("nested deeper than %d levels", maxDepth)
+			}
+
+			operand, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return unaryOp{op: op, operand: operand}, nil
+		}
+	}
+
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	target, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.accept("."):
+			t := p.next()
+			if t.kind != identToken {
+				return nil, errors.Errorf("expected field name but found %s at position %d", t, t.pos)
+			}
+			target = fieldAccess{target: target, field: t.text}
+		case p.accept("["):
+			index, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			target = indexAccess{target: target, index: index}
+		default:
+			return target, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case numberToken:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid number %s at position %d", t.text, t.pos)
+		}
+		return literal{value: n}, nil
+	case stringToken:
+		return literal{value: t.text}, nil
+	case variableToken:
+		if !p.variables[t.text] {
+			p.variables[t.text] = true
+			p.order = append(p.order, t.text)
+		}
+		return variableRef{name: t.text}, nil
+	case identToken:
+		return p.parseIdent(t)
+	case operatorToken:
+		switch t.text {
+		case "(":
+			inner, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return listNode{items: items}, nil
+		}
+	}
+
+	return nil, errors.Errorf("unexpected %s at position %d", t, t.pos)
+}
+
+func (p *parser) parseIdent(t token) (node, error) {
+	switch t.text {
+	case "value":
+		return valueRef{}, nil
+	case "true":
+		return literal{value: true}, nil
+	case "false":
+		return literal{value: false}, nil
+	case "null":
+		return literal{value: nil}, nil
+	}
+
+	fn, found := functions[t.text]
+	if !found {
+		return nil, errors.Errorf("unknown identifier %s at position %d", t.text, t.pos)
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	args, err := p.parseList(")")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < fn.minArgs || len(args) > fn.maxArgs {
+		return nil, errors.Errorf("wrong number of arguments for %s at position %d", t.text, t.pos)
+	}
+
+	return call{fn: fn, args: args}, nil
+}
+
+func (p *parser) parseList(end string) ([]node, error) {
+	var items []node
+	if p.accept(end) {
+		return items, nil
+	}
+
+	for {
+		item, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		if p.accept(end) {
+			return items, nil
+		}
+		if !p.accept(",") {
+			return nil, errors.Errorf("expected \",\" or %q but found %s at position %d", end, p.peek(), p.peek().pos)
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
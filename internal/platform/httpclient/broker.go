@@ -0,0 +1,226 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+// Schemes of the mappings that publish the statement body to a
+// message broker, in the forms kafka://<topic>/<key> and
+// amqp://<exchange>/<routing-key>, where the last part is optional.
+const (
+	KafkaScheme = "kafka"
+	AMQPScheme  = "amqp"
+)
+
+const (
+	kafkaContentType = "application/vnd.kafka.json.v2+json"
+	kafkaAccept      = "application/vnd.kafka.v2+json"
+	defaultAMQPVHost = "/"
+)
+
+// kafkaProduceResponse is the body of the Kafka REST Proxy
+// response to the records produced to a topic.
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// amqpPublishResponse is the body of the RabbitMQ management
+// API response to a message published to an exchange.
+type amqpPublishResponse struct {
+	Routed bool `json:"routed"`
+}
+
+// BrokerClient is an HTTPClient that translates the requests to
+// mappings with the kafka and amqp schemes into messages published
+// through the HTTP gateways of the brokers, the Kafka REST Proxy and
+// the RabbitMQ management API, executed by the wrapped client. The
+// response body is replaced by the broker acknowledgement. Every
+// other request is executed by the wrapped client unchanged.
+type BrokerClient struct {
+	log    restql.Logger
+	client domain.HTTPClient
+	kafka  *url.URL
+	amqp   *url.URL
+	vhost  string
+	auth   string
+}
+
+// WithBrokers wraps the client with a BrokerClient for the
+// configured gateways, where an empty URL disables the broker.
+func WithBrokers(log restql.Logger, client domain.HTTPClient, kafka conf.KafkaConf, amqp conf.AMQPConf) (domain.HTTPClient, error) {
+	bc := &BrokerClient{log: log, client: client, vhost: amqp.VHost}
+
+	var err error
+	if kafka.RestProxy != "" {
+		if bc.kafka, err = parseGatewayURL(kafka.RestProxy); err != nil {
+			return nil, errors.Wrap(err, "invalid kafka rest proxy")
+		}
+	}
+
+	if amqp.ManagementAPI != "" {
+		if bc.amqp, err = parseGatewayURL(amqp.ManagementAPI); err != nil {
+			return nil, errors.Wrap(err, "invalid amqp management api")
+		}
+	}
+
+	if bc.vhost == "" {
+		bc.vhost = defaultAMQPVHost
+	}
+	if amqp.Username != "" {
+		bc.auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(amqp.Username+":"+amqp.Password))
+	}
+
+	return bc, nil
+}
+
+func parseGatewayURL(gateway string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimRight(gateway, "/"))
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, errors.Errorf("invalid url %s", gateway)
+	}
+	return u, nil
+}
+
+// Do publishes the body of requests to mappings with the broker schemes.
+func (bc *BrokerClient) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	if request.Schema != KafkaScheme && request.Schema != AMQPScheme {
+		return bc.client.Do(ctx, request)
+	}
+
+	destination := request.Host
+	key := strings.Trim(request.Path, "/")
+	target := request.Schema + "://" + request.Host + request.Path
+
+	if request.Method != http.MethodPost {
+		return bc.failure(target, http.StatusMethodNotAllowed, request.Schema+" resources only support the to method"), nil
+	}
+
+	switch request.Schema {
+	case KafkaScheme:
+		if bc.kafka == nil {
+			return bc.failure(target, http.StatusNotImplemented, "no kafka rest proxy configured"), nil
+		}
+		return bc.produce(ctx, request, destination, key, target)
+	default:
+		if bc.amqp == nil {
+			return bc.failure(target, http.StatusNotImplemented, "no amqp management api configured"), nil
+		}
+		return bc.publish(ctx, request, destination, key, target)
+	}
+}
+
+func (bc *BrokerClient) produce(ctx context.Context, request restql.HTTPRequest, topic string, key string, target string) (restql.HTTPResponse, error) {
+	record := map[string]interface{}{"value": request.Body}
+	if key != "" {
+		record["key"] = key
+	}
+
+	gatewayRequest := bc.gatewayRequest(request, bc.kafka, "/topics/"+url.PathEscape(topic))
+	gatewayRequest.Body = map[string]interface{}{"records": []interface{}{record}}
+	gatewayRequest.Headers = restql.Headers{"Content-Type": kafkaContentType, "Accept": kafkaAccept}
+
+	response, err := bc.client.Do(ctx, gatewayRequest)
+	if err != nil || response.StatusCode >= 300 {
+		return response, err
+	}
+
+	var produced kafkaProduceResponse
+	if err := decodeGatewayBody(response, &produced); err != nil || len(produced.Offsets) == 0 {
+		return bc.failure(target, http.StatusBadGateway, "invalid kafka rest proxy response"), nil
+	}
+
+	offset := produced.Offsets[0]
+	if offset.ErrorCode != nil || offset.Error != "" {
+		return bc.failure(target, http.StatusBadGateway, fmt.Sprintf("kafka rejected the record : %s", offset.Error)), nil
+	}
+
+	return bc.ack(response, target, map[string]interface{}{
+		"topic":     topic,
+		"partition": offset.Partition,
+		"offset":    offset.Offset,
+	}), nil
+}
+
+func (bc *BrokerClient) publish(ctx context.Context, request restql.HTTPRequest, exchange string, routingKey string, target string) (restql.HTTPResponse, error) {
+	payload, err := json.Marshal(request.Body)
+	if err != nil {
+		return restql.HTTPResponse{}, errors.Wrap(err, "failed to marshal message payload")
+	}
+
+	path := "/api/exchanges/" + url.PathEscape(bc.vhost) + "/" + url.PathEscape(exchange) + "/publish"
+	gatewayRequest := bc.gatewayRequest(request, bc.amqp, path)
+	gatewayRequest.Body = map[string]interface{}{
+		"properties":       map[string]interface{}{"content_type": "application/json", "delivery_mode": 2},
+		"routing_key":      routingKey,
+		"payload":          string(payload),
+		"payload_encoding": "string",
+	}
+	gatewayRequest.Headers = restql.Headers{"Content-Type": "application/json"}
+	if bc.auth != "" {
+		gatewayRequest.Headers["Authorization"] = bc.auth
+	}
+
+	response, err := bc.client.Do(ctx, gatewayRequest)
+	if err != nil || response.StatusCode >= 300 {
+		return response, err
+	}
+
+	var published amqpPublishResponse
+	if err := decodeGatewayBody(response, &published); err != nil {
+		return bc.failure(target, http.StatusBadGateway, "invalid amqp management api response"), nil
+	}
+
+	return bc.ack(response, target, map[string]interface{}{
+		"exchange":   exchange,
+		"routingKey": routingKey,
+		"routed":     published.Routed,
+	}), nil
+}
+
+// gatewayRequest addresses the path of the gateway, keeping
+// the statement timeout and limits but not its query parameters.
+func (bc *BrokerClient) gatewayRequest(request restql.HTTPRequest, gateway *url.URL, path string) restql.HTTPRequest {
+	gatewayRequest := request
+	gatewayRequest.Schema = gateway.Scheme
+	gatewayRequest.Host = gateway.Host
+	gatewayRequest.Path = gateway.Path + path
+	gatewayRequest.Query = nil
+	return gatewayRequest
+}
+
+func decodeGatewayBody(response restql.HTTPResponse, v interface{}) error {
+	if response.Body == nil {
+		return errors.New("empty body")
+	}
+	return json.Unmarshal(response.Body.Bytes(), v)
+}
+
+func (bc *BrokerClient) ack(response restql.HTTPResponse, target string, ack map[string]interface{}) restql.HTTPResponse {
+	response.URL = target
+	response.Body = restql.NewResponseBodyFromValue(bc.log, ack)
+	return response
+}
+
+func (bc *BrokerClient) failure(target string, status int, msg string) restql.HTTPResponse {
+	return restql.HTTPResponse{
+		URL:        target,
+		StatusCode: status,
+		Body:       restql.NewResponseBodyFromValue(bc.log, msg),
+	}
+}
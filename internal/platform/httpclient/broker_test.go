@@ -0,0 +1,113 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+type gatewayClient struct {
+	requests []restql.HTTPRequest
+	body     string
+}
+
+func (g *gatewayClient) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	g.requests = append(g.requests, request)
+	return restql.HTTPResponse{StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromBytes(test.NoOpLogger, []byte(g.body))}, nil
+}
+
+func TestBrokerClientKafka(t *testing.T) {
+	gateway := &gatewayClient{body: `{"key_schema_id":null,"value_schema_id":null,"offsets":[{"partition":2,"offset":100,"error_code":null,"error":null}]}`}
+	c, err := WithBrokers(test.NoOpLogger, gateway, conf.KafkaConf{RestProxy: "http://kafka-rest:8082/"}, conf.AMQPConf{})
+	test.VerifyError(t, err)
+
+	request := restql.HTTPRequest{
+		Method:  http.MethodPost,
+		Schema:  KafkaScheme,
+		Host:    "orders",
+		Path:    "/42",
+		Query:   map[string]interface{}{"ignored": "true"},
+		Body:    map[string]interface{}{"id": 42},
+		Timeout: time.Second,
+	}
+	response, err := c.Do(context.Background(), request)
+	test.VerifyError(t, err)
+
+	test.Equal(t, response.StatusCode, http.StatusOK)
+	test.Equal(t, response.URL, "kafka://orders/42")
+	test.Equal(t, response.Body.Unmarshal(), map[string]interface{}{"topic": "orders", "partition": 2, "offset": int64(100)})
+
+	test.Equal(t, gateway.requests, []restql.HTTPRequest{{
+		Method:  http.MethodPost,
+		Schema:  "http",
+		Host:    "kafka-rest:8082",
+		Path:    "/topics/orders",
+		Body:    map[string]interface{}{"records": []interface{}{map[string]interface{}{"key": "42", "value": map[string]interface{}{"id": 42}}}},
+		Headers: restql.Headers{"Content-Type": kafkaContentType, "Accept": kafkaAccept},
+		Timeout: time.Second,
+	}})
+}
+
+func TestBrokerClientKafkaRejectedRecord(t *testing.T) {
+	gateway := &gatewayClient{body: `{"offsets":[{"partition":null,"offset":null,"error_code":50002,"error":"topic not found"}]}`}
+	c, err := WithBrokers(test.NoOpLogger, gateway, conf.KafkaConf{RestProxy: "http://kafka-rest:8082"}, conf.AMQPConf{})
+	test.VerifyError(t, err)
+
+	response, err := c.Do(context.Background(), restql.HTTPRequest{Method: http.MethodPost, Schema: KafkaScheme, Host: "orders"})
+	test.VerifyError(t, err)
+
+	test.Equal(t, response.StatusCode, http.StatusBadGateway)
+	test.Equal(t, response.Body.Unmarshal(), "kafka rejected the record : topic not found")
+}
+
+func TestBrokerClientAMQP(t *testing.T) {
+	gateway := &gatewayClient{body: `{"routed":true}`}
+	c, err := WithBrokers(test.NoOpLogger, gateway, conf.KafkaConf{}, conf.AMQPConf{ManagementAPI: "https://rabbit:15672", Username: "guest", Password: "guest"})
+	test.VerifyError(t, err)
+
+	request := restql.HTTPRequest{Method: http.MethodPost, Schema: AMQPScheme, Host: "orders", Path: "/order.created", Body: map[string]interface{}{"id": 42}}
+	response, err := c.Do(context.Background(), request)
+	test.VerifyError(t, err)
+
+	test.Equal(t, response.StatusCode, http.StatusOK)
+	test.Equal(t, response.Body.Unmarshal(), map[string]interface{}{"exchange": "orders", "routingKey": "order.created", "routed": true})
+
+	test.Equal(t, len(gateway.requests), 1)
+	published := gateway.requests[0]
+	test.Equal(t, published.Schema, "https")
+	test.Equal(t, published.Host, "rabbit:15672")
+	test.Equal(t, published.Path, "/api/exchanges/%2F/orders/publish")
+	test.Equal(t, published.Headers, restql.Headers{"Content-Type": "application/json", "Authorization": "Basic Z3Vlc3Q6Z3Vlc3Q="})
+	test.Equal(t, published.Body, map[string]interface{}{
+		"properties":       map[string]interface{}{"content_type": "application/json", "delivery_mode": 2},
+		"routing_key":      "order.created",
+		"payload":          `{"id":42}`,
+		"payload_encoding": "string",
+	})
+}
+
+func TestBrokerClientFailures(t *testing.T) {
+	gateway := &gatewayClient{}
+	c, err := WithBrokers(test.NoOpLogger, gateway, conf.KafkaConf{}, conf.AMQPConf{})
+	test.VerifyError(t, err)
+
+	response, err := c.Do(context.Background(), restql.HTTPRequest{Method: http.MethodGet, Schema: KafkaScheme, Host: "orders"})
+	test.VerifyError(t, err)
+	test.Equal(t, response.StatusCode, http.StatusMethodNotAllowed)
+
+	response, err = c.Do(context.Background(), restql.HTTPRequest{Method: http.MethodPost, Schema: AMQPScheme, Host: "orders"})
+	test.VerifyError(t, err)
+	test.Equal(t, response.StatusCode, http.StatusNotImplemented)
+
+	_, err = c.Do(context.Background(), restql.HTTPRequest{Method: http.MethodGet, Schema: "http", Host: "hero"})
+	test.VerifyError(t, err)
+	test.Equal(t, len(gateway.requests), 1)
+
+	_, err = WithBrokers(test.NoOpLogger, gateway, conf.KafkaConf{RestProxy: "kafka-rest"}, conf.AMQPConf{})
+	test.NotEqual(t, err, nil)
+}
@@ -0,0 +1,291 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// Cassette modes, which select if the upstream interactions
+// are recorded to or replayed from the cassette file.
+const (
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+// ErrCassetteMiss is the error returned when replaying a request
+// without a recorded interaction in the cassette.
+var ErrCassetteMiss = errors.New("no interaction recorded for request")
+
+// Cassette represents the upstream interactions recorded
+// during the execution of queries.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction represents an upstream request and
+// the response, or error, it produced.
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest identifies a recorded request by its method,
+// full URL, with the query arguments sorted, and body.
+type CassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// CassetteResponse represents a recorded response, where
+// RawBody holds the bodies that are not a valid JSON.
+type CassetteResponse struct {
+	Status  int               `json:"status,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+	RawBody string            `json:"rawBody,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// LoadCassette reads the cassette stored in the given file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read cassette %s", path)
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse cassette %s", path)
+	}
+
+	return &c, nil
+}
+
+// Save writes the cassette to the given file, replacing its content.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode cassette")
+	}
+
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write cassette %s", path)
+	}
+
+	return nil
+}
+
+// WithCassette wraps the client to record its interactions to,
+// or replace it by the replay of, the cassette in the given path.
+// The client is returned unchanged when no mode is defined.
+func WithCassette(log restql.Logger, client domain.HTTPClient, mode string, path string) (domain.HTTPClient, error) {
+	switch mode {
+	case "":
+		return client, nil
+	case CassetteRecord:
+		log.Info("recording upstream interactions", "cassette", path)
+		return NewRecorder(log, client, path), nil
+	case CassetteReplay:
+		c, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("replaying upstream interactions", "cassette", path, "interactions", len(c.Interactions))
+		return NewReplayer(log, c), nil
+	default:
+		return nil, errors.Errorf("invalid cassette mode %s", mode)
+	}
+}
+
+// Recorder is an HTTPClient that executes the requests with the
+// wrapped client, saving every interaction to the cassette file.
+type Recorder struct {
+	log    restql.Logger
+	client domain.HTTPClient
+	path   string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder constructs a Recorder that starts
+// a new cassette in the given path.
+func NewRecorder(log restql.Logger, client domain.HTTPClient, path string) *Recorder {
+	return &Recorder{log: log, client: client, path: path}
+}
+
+// Do executes the request and records the interaction.
+func (r *Recorder) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	response, err := r.client.Do(ctx, request)
+
+	cr, keyErr := cassetteRequest(request)
+	if keyErr != nil {
+		r.log.Error("failed to record upstream interaction", keyErr, "host", request.Host)
+		return response, err
+	}
+
+	interaction := Interaction{Request: cr, Response: cassetteResponse(response, err)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	if saveErr := r.cassette.Save(r.path); saveErr != nil {
+		r.log.Error("failed to save cassette", saveErr, "cassette", r.path)
+	}
+
+	return response, err
+}
+
+// Replayer is an HTTPClient that answers the requests with the
+// interactions recorded in a cassette, without calling the upstreams.
+// Repeated requests are answered in the recorded order, the last
+// interaction being reused once all of them were replayed.
+type Replayer struct {
+	log          restql.Logger
+	interactions map[CassetteRequest][]Interaction
+
+	mu     sync.Mutex
+	played map[CassetteRequest]int
+	misses map[string]struct{}
+}
+
+// NewReplayer constructs a Replayer for the given cassette.
+func NewReplayer(log restql.Logger, c *Cassette) *Replayer {
+	interactions := make(map[CassetteRequest][]Interaction)
+	for _, i := range c.Interactions {
+		interactions[i.Request] = append(interactions[i.Request], i)
+	}
+
+	return &Replayer{
+		log:          log,
+		interactions: interactions,
+		played:       make(map[CassetteRequest]int),
+		misses:       make(map[string]struct{}),
+	}
+}
+
+// Do answers the request with the next recorded interaction.
+func (r *Replayer) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	cr, err := cassetteRequest(request)
+	if err != nil {
+		return restql.HTTPResponse{}, err
+	}
+
+	r.mu.Lock()
+	recorded, found := r.interactions[cr]
+	if !found {
+		r.misses[cr.Method+" "+cr.URL] = struct{}{}
+		r.mu.Unlock()
+
+		r.log.Info("request not recorded in cassette", "method", cr.Method, "url", cr.URL)
+		return restql.HTTPResponse{URL: cr.URL}, fmt.Errorf("%w : %s %s", ErrCassetteMiss, cr.Method, cr.URL)
+	}
+
+	n := r.played[cr]
+	if n < len(recorded)-1 {
+		r.played[cr] = n + 1
+	}
+	r.mu.Unlock()
+
+	return replayResponse(r.log, cr.URL, recorded[n].Response)
+}
+
+// Misses returns the requests made without a recorded interaction.
+func (r *Replayer) Misses() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]string, 0, len(r.misses))
+	for m := range r.misses {
+		result = append(result, m)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func cassetteRequest(request restql.HTTPRequest) (CassetteRequest, error) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	if err := setupRequest(request, req); err != nil {
+		return CassetteRequest{}, err
+	}
+
+	uri := req.URI()
+	uri.QueryArgs().Sort(bytes.Compare)
+
+	return CassetteRequest{
+		Method: request.Method,
+		URL:    string(uri.FullURI()),
+		Body:   string(req.Body()),
+	}, nil
+}
+
+func cassetteResponse(response restql.HTTPResponse, err error) CassetteResponse {
+	cr := CassetteResponse{Status: response.StatusCode, Headers: response.Headers}
+	if err != nil {
+		cr.Error = err.Error()
+		if errors.Is(err, domain.ErrRequestTimeout) {
+			cr.Error = domain.ErrRequestTimeout.Error()
+		}
+		return cr
+	}
+
+	if response.Body == nil {
+		return cr
+	}
+
+	body, marshalErr := response.Body.Marshal()
+	switch body := body.(type) {
+	case json.RawMessage:
+		cr.Body = body
+	case string:
+		cr.RawBody = body
+	}
+	if marshalErr != nil {
+		cr.RawBody = string(response.Body.Bytes())
+	}
+
+	return cr
+}
+
+func replayResponse(log restql.Logger, url string, recorded CassetteResponse) (restql.HTTPResponse, error) {
+	response := restql.HTTPResponse{URL: url, StatusCode: recorded.Status, Headers: recorded.Headers}
+
+	if recorded.Error != "" {
+		if recorded.Error == domain.ErrRequestTimeout.Error() {
+			return response, domain.ErrRequestTimeout
+		}
+		return response, errors.New(recorded.Error)
+	}
+
+	if response.StatusCode == 0 {
+		response.StatusCode = http.StatusOK
+	}
+
+	var body []byte
+	switch {
+	case len(recorded.Body) > 0:
+		body = make([]byte, len(recorded.Body))
+		copy(body, recorded.Body)
+	case recorded.RawBody != "":
+		body = []byte(recorded.RawBody)
+	}
+	response.Body = restql.NewResponseBodyFromBytes(log, body)
+
+	return response, nil
+}
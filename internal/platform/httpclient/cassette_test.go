@@ -0,0 +1,78 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+type sequenceClient struct {
+	calls int
+}
+
+func (s *sequenceClient) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	s.calls++
+	if request.Host == "slow.io" {
+		return restql.HTTPResponse{StatusCode: http.StatusRequestTimeout}, domain.ErrRequestTimeout
+	}
+
+	body := map[string]interface{}{"call": s.calls}
+	return restql.HTTPResponse{
+		StatusCode: http.StatusOK,
+		Headers:    restql.Headers{"Content-Type": "application/json"},
+		Body:       restql.NewResponseBodyFromValue(test.NoOpLogger, body),
+	}, nil
+}
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	hero := restql.HTTPRequest{
+		Method: http.MethodGet,
+		Schema: "http",
+		Host:   "hero.io",
+		Path:   "/api",
+		Query:  map[string]interface{}{"name": "batman", "city": "gotham"},
+	}
+	slow := restql.HTTPRequest{Method: http.MethodGet, Schema: "http", Host: "slow.io", Path: "/api"}
+
+	recorder := NewRecorder(test.NoOpLogger, &sequenceClient{}, path)
+	for _, request := range []restql.HTTPRequest{hero, hero, slow} {
+		recorder.Do(ctx, request)
+	}
+
+	c, err := LoadCassette(path)
+	test.VerifyError(t, err)
+	test.Equal(t, len(c.Interactions), 3)
+	test.Equal(t, c.Interactions[0].Request.URL, "http://hero.io/api?city=gotham&name=batman")
+
+	replayer := NewReplayer(test.NoOpLogger, c)
+
+	for _, expected := range []string{`{"call":1}`, `{"call":2}`, `{"call":2}`} {
+		response, err := replayer.Do(ctx, hero)
+		test.VerifyError(t, err)
+		test.Equal(t, response.StatusCode, http.StatusOK)
+		test.Equal(t, response.Headers, restql.Headers{"Content-Type": "application/json"})
+		test.Equal(t, response.Body.Unmarshal(), test.Unmarshal(expected))
+	}
+
+	_, err = replayer.Do(ctx, slow)
+	test.Equal(t, errors.Is(err, domain.ErrRequestTimeout), true)
+
+	unknown := restql.HTTPRequest{Method: http.MethodPost, Schema: "http", Host: "hero.io", Path: "/api", Body: map[string]interface{}{"id": 1}}
+	_, err = replayer.Do(ctx, unknown)
+	test.Equal(t, errors.Is(err, ErrCassetteMiss), true)
+	test.Equal(t, replayer.Misses(), []string{"POST http://hero.io/api"})
+}
+
+func TestWithCassetteInvalidMode(t *testing.T) {
+	_, err := WithCassette(test.NoOpLogger, &sequenceClient{}, "rewind", "")
+	test.NotEqual(t, err, nil)
+}
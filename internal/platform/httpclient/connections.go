@@ -0,0 +1,31 @@
+package httpclient
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// activeConnections counts, for each host, the requests in
+// flight, as each one holds a connection until it is done.
+var activeConnections sync.Map
+
+// ActiveConnections returns the number of connections in use by
+// requests to the host, in the form it has in resource mappings.
+func ActiveConnections(host string) int64 {
+	v, ok := activeConnections.Load(host)
+	if !ok {
+		return 0
+	}
+
+	return atomic.LoadInt64(v.(*int64))
+}
+
+// trackConnection counts a request to the host as in flight
+// until the returned function is called.
+func trackConnection(host string) func() {
+	v, _ := activeConnections.LoadOrStore(host, new(int64))
+	counter := v.(*int64)
+
+	atomic.AddInt64(counter, 1)
+	return func() { atomic.AddInt64(counter, -1) }
+}
@@ -0,0 +1,23 @@
+package httpclient
+
+import (
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestActiveConnections(t *testing.T) {
+	test.Equal(t, ActiveConnections("hero.io"), int64(0))
+
+	doneFirst := trackConnection("hero.io")
+	doneSecond := trackConnection("hero.io")
+	trackConnection("villain.io")
+	test.Equal(t, ActiveConnections("hero.io"), int64(2))
+
+	doneFirst()
+	test.Equal(t, ActiveConnections("hero.io"), int64(1))
+
+	doneSecond()
+	test.Equal(t, ActiveConnections("hero.io"), int64(0))
+	test.Equal(t, ActiveConnections("villain.io"), int64(1))
+}
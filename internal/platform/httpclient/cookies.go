@@ -0,0 +1,38 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/valyala/fasthttp"
+)
+
+// setJarCookies adds to the request the cookies
+// kept by the jar for the request URL.
+func setJarCookies(jar http.CookieJar, request restql.HTTPRequest, req *fasthttp.Request) {
+	u := &url.URL{Scheme: request.Schema, Host: request.Host, Path: request.Path}
+	for _, c := range jar.Cookies(u) {
+		req.Header.SetCookie(c.Name, c.Value)
+	}
+}
+
+// storeJarCookies keeps in the jar the cookies set by the response.
+func storeJarCookies(jar http.CookieJar, target string, res *fasthttp.Response) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return
+	}
+
+	header := make(http.Header)
+	res.Header.VisitAll(func(key, value []byte) {
+		if string(key) == fasthttp.HeaderSetCookie {
+			header.Add(fasthttp.HeaderSetCookie, string(value))
+		}
+	})
+	if len(header) == 0 {
+		return
+	}
+
+	jar.SetCookies(u, (&http.Response{Header: header}).Cookies())
+}
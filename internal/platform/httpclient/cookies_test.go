@@ -0,0 +1,42 @@
+package httpclient
+
+import (
+	"net/http/cookiejar"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/valyala/fasthttp"
+)
+
+func TestJarCookies(t *testing.T) {
+	jar, err := cookiejar.New(nil)
+	test.VerifyError(t, err)
+
+	res := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(res)
+	res.Header.Add("Set-Cookie", "session=abc; Path=/")
+	res.Header.Add("Set-Cookie", "route=node-1; Path=/cart")
+
+	storeJarCookies(jar, "http://legacy.api/login", res)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"should send the cookies matching the path", "/cart/items", "route=node-1; session=abc"},
+		{"should not send the cookies of other paths", "/orders", "session=abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := fasthttp.AcquireRequest()
+			defer fasthttp.ReleaseRequest(req)
+
+			setJarCookies(jar, restql.HTTPRequest{Schema: "http", Host: "legacy.api", Path: tt.path}, req)
+
+			test.Equal(t, string(req.Header.Peek("Cookie")), tt.expected)
+		})
+	}
+}
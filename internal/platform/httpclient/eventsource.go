@@ -0,0 +1,196 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+	defaultEventType       = "message"
+
+	// maxEventLineSize bounds each line read from the stream.
+	maxEventLineSize = 1024 * 1024
+	// maxPlainBodySize bounds the body read from upstreams
+	// that answer the subscription without a stream.
+	maxPlainBodySize = 1024 * 1024
+)
+
+// EventSourceClient is an EventSource that subscribes to upstreams
+// answering with server-sent events. As the fasthttp client reads
+// the whole body before returning, it uses the standard library one.
+type EventSourceClient struct {
+	log    restql.Logger
+	client *http.Client
+}
+
+// NewEventSource constructs an EventSourceClient.
+func NewEventSource(log restql.Logger) *EventSourceClient {
+	return &EventSourceClient{log: log, client: &http.Client{}}
+}
+
+// Subscribe executes the request, waiting for the response headers
+// no longer than the request timeout, and reads the events of the
+// stream until it ends or the context is cancelled. Responses that
+// are not an event stream are returned with a nil channel.
+func (es *EventSourceClient) Subscribe(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, <-chan domain.UpstreamEvent, error) {
+	client := es.client
+	if request.Schema == UnixScheme {
+		socket, err := UnixSocketPath(request.Host)
+		if err != nil {
+			return makeErrorResponse(request.Host, 0, http.StatusBadRequest), nil, err
+		}
+		client = unixHTTPClient(socket)
+	}
+
+	schema, host := UpstreamAddr(request.Schema, request.Host)
+	target := (&url.URL{
+		Scheme:   schema,
+		Host:     host,
+		Path:     request.Path,
+		RawQuery: string(makeQueryArgs(nil, request)),
+	}).String()
+
+	streamCtx, cancel := context.WithCancel(ctx)
+
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, target, nil)
+	if err != nil {
+		cancel()
+		return makeErrorResponse(target, 0, http.StatusBadRequest), nil, errors.Wrap(err, "failed to setup subscription request")
+	}
+
+	for key, value := range request.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Accept", eventStreamContentType)
+	req.Header.Set("Cache-Control", "no-cache")
+
+	start := time.Now()
+	var timer *time.Timer
+	if request.Timeout > 0 {
+		timer = time.AfterFunc(request.Timeout, cancel)
+	}
+
+	res, err := client.Do(req)
+	if timer != nil && !timer.Stop() {
+		err = domain.ErrRequestTimeout
+	}
+	duration := time.Since(start)
+
+	if err != nil {
+		cancel()
+		if res != nil {
+			res.Body.Close()
+		}
+		if err == domain.ErrRequestTimeout {
+			es.log.Info("subscription timed out", "url", target, "duration-ms", duration.Milliseconds())
+			return makeErrorResponse(target, duration, http.StatusRequestTimeout), nil, err
+		}
+		return makeErrorResponse(target, duration, 0), nil, errors.Wrap(err, "subscription request failed")
+	}
+
+	headers := make(restql.Headers, len(res.Header))
+	for key := range res.Header {
+		headers[key] = res.Header.Get(key)
+	}
+
+	response := restql.HTTPResponse{
+		URL:        target,
+		StatusCode: res.StatusCode,
+		Headers:    headers,
+		Duration:   duration,
+	}
+
+	isStream := strings.HasPrefix(res.Header.Get("Content-Type"), eventStreamContentType)
+	if res.StatusCode < 200 || res.StatusCode > 299 || !isStream {
+		defer cancel()
+		defer res.Body.Close()
+
+		body, err := ioutil.ReadAll(io.LimitReader(res.Body, maxPlainBodySize))
+		if err != nil {
+			return makeErrorResponse(target, duration, res.StatusCode), nil, errors.Wrap(err, "failed to read subscription response")
+		}
+		response.Body = restql.NewResponseBodyFromBytes(es.log, body)
+
+		return response, nil, nil
+	}
+
+	response.Body = restql.NewResponseBodyFromBytes(es.log, nil)
+
+	events := make(chan domain.UpstreamEvent)
+	go func() {
+		defer close(events)
+		defer cancel()
+		defer res.Body.Close()
+
+		readEvents(streamCtx, res.Body, events)
+	}()
+
+	return response, events, nil
+}
+
+// readEvents parses the server-sent events from the stream,
+// as defined by the HTML Living Standard, sending each one
+// when dispatched by a blank line.
+func readEvents(ctx context.Context, stream io.Reader, events chan<- domain.UpstreamEvent) {
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 4096), maxEventLineSize)
+
+	var lastID string
+	var eventType string
+	var data []string
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			if len(data) == 0 {
+				eventType = ""
+				continue
+			}
+
+			if eventType == "" {
+				eventType = defaultEventType
+			}
+			event := domain.UpstreamEvent{ID: lastID, Event: eventType, Data: strings.Join(data, "\n")}
+			eventType, data = "", nil
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				lastID = value
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestReadEvents(t *testing.T) {
+	stream := ": keep alive\n" +
+		"data: first\n\n" +
+		"event: update\nid: 42\ndata: {\"a\":\ndata: 1}\n\n" +
+		"id\n\n" +
+		"data\n\n" +
+		"event: ignored\n\n" +
+		"data: without dispatch"
+
+	events := make(chan domain.UpstreamEvent, 10)
+	readEvents(context.Background(), strings.NewReader(stream), events)
+	close(events)
+
+	var got []domain.UpstreamEvent
+	for e := range events {
+		got = append(got, e)
+	}
+
+	expected := []domain.UpstreamEvent{
+		{Event: "message", Data: "first"},
+		{ID: "42", Event: "update", Data: "{\"a\":\n1}"},
+		{ID: "", Event: "message", Data: ""},
+	}
+	test.Equal(t, got, expected)
+}
+
+func TestEventSourceClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/events" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+
+		if r.Header.Get("Accept") != eventStreamContentType || r.URL.Query().Get("topic") != "heroes" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", eventStreamContentType)
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(w, "id: %d\ndata: {\"n\":%d}\n\n", i, i)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	es := NewEventSource(test.NoOpLogger)
+
+	request := restql.HTTPRequest{Method: http.MethodGet, Schema: "http", Host: u.Host, Path: "/events", Query: map[string]interface{}{"topic": "heroes"}, Timeout: time.Second}
+	response, events, err := es.Subscribe(context.Background(), request)
+	test.VerifyError(t, err)
+	test.Equal(t, response.StatusCode, http.StatusOK)
+
+	var got []string
+	for e := range events {
+		got = append(got, e.ID+" "+e.Data)
+	}
+	test.Equal(t, got, []string{`1 {"n":1}`, `2 {"n":2}`, `3 {"n":3}`})
+
+	request.Path = "/missing"
+	response, events, err = es.Subscribe(context.Background(), request)
+	test.VerifyError(t, err)
+	test.Equal(t, response.StatusCode, http.StatusNotFound)
+	test.Equal(t, events == nil, true)
+	test.Equal(t, response.Body.Unmarshal(), "not found\n")
+}
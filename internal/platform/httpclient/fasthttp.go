@@ -2,14 +2,13 @@ package httpclient
 
 import (
 	"context"
+	"expvar"
 	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
 	"github.com/pkg/errors"
-	"github.com/rs/dnscache"
 	"github.com/valyala/fasthttp"
-	"net"
 	"sync"
 	"time"
 )
@@ -21,72 +20,74 @@ type httpResult struct {
 	duration time.Duration
 	err      error
 	response *fasthttp.Response
+	timings  *restql.HTTPTimings
 }
 
 type fastHTTPClient struct {
 	client       *fasthttp.Client
+	resolver     *dnsResolver
 	log          restql.Logger
 	lifecycle    plugins.Lifecycle
 	responsePool *sync.Pool
+	bodyLimits   bodyLimits
+	dialClients  sync.Map
+	handshakes   *handshakeStats
+	crls         *crlCache
 }
 
 func newFastHTTPClient(log restql.Logger, pm plugins.Lifecycle, cfg *conf.Config) *fastHTTPClient {
 	clientCfg := cfg.HTTP.Client
 
-	r := &dnscache.Resolver{}
-	go func() {
-		t := time.NewTicker(clientCfg.DnsRefreshInterval)
-		defer t.Stop()
-		for range t.C {
-			r.Refresh(true)
-		}
-	}()
-	dialer := &fasthttp.TCPDialer{
-		Resolver: &net.Resolver{
-			PreferGo:     true,
-			StrictErrors: false,
-			Dial: func(ctx context.Context, network, address string) (conn net.Conn, err error) {
-				host, port, err := net.SplitHostPort(address)
-				if err != nil {
-					return nil, err
-				}
-				ips, err := r.LookupHost(ctx, host)
-				if err != nil {
-					return nil, err
-				}
-				for _, ip := range ips {
-					var dialer net.Dialer
-					conn, err = dialer.Dial(network, net.JoinHostPort(ip, port))
-					if err == nil {
-						break
-					}
-				}
-				return
-			},
-		},
+	maxTTL := clientCfg.DNS.MaxTTL
+	if maxTTL <= 0 {
+		maxTTL = clientCfg.DnsRefreshInterval
 	}
 
+	resolver := newDNSResolver(clientCfg.DNS.MinTTL, maxTTL, clientCfg.DNS.NegativeTTL)
+	dnsMetrics.Set("resolver", expvar.Func(func() interface{} {
+		return resolver.Stats()
+	}))
+
 	rp := &sync.Pool{
 		New: func() interface{} {
 			return make(chan httpResult)
 		},
 	}
 
+	handshakes := &handshakeStats{}
+	tlsMetrics.Set("handshakes", expvar.Func(func() interface{} {
+		return handshakes.Stats()
+	}))
+	crls := newCRLCache()
+
 	c := &fasthttp.Client{
 		Name:                          "restql",
 		NoDefaultUserAgentHeader:      false,
 		DisableHeaderNamesNormalizing: true,
-		Dial:                          dialer.Dial,
+		Dial:                          resolver.Dial,
 		MaxConnsPerHost:               clientCfg.MaxConnsPerHost,
 		MaxIdleConnDuration:           clientCfg.MaxIdleConnDuration,
 		MaxConnWaitTimeout:            clientCfg.ConnTimeout,
+		MaxResponseBodySize:           maxResponseBodySize(cfg),
+		TLSConfig:                     newTLSConfig(nil, handshakes, crls),
 	}
 
-	return &fastHTTPClient{client: c, log: log, lifecycle: pm, responsePool: rp}
+	limits := bodyLimits{maxDepth: clientCfg.MaxBodyDepth, maxKeys: clientCfg.MaxBodyKeys}
+
+	return &fastHTTPClient{client: c, resolver: resolver, log: log, lifecycle: pm, responsePool: rp, bodyLimits: limits, handshakes: handshakes, crls: crls}
 }
 
 func (hc *fastHTTPClient) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
 	requestCtx := hc.lifecycle.BeforeRequest(ctx, request)
+	jar, hasJar := domain.GetCookieJar(ctx)
+
+	client, traceable, err := hc.upstreamClient(ctx, request)
+	if err != nil {
+		hc.log.Error("failed to select upstream client", err)
+		response := makeErrorResponse(request.Schema+"://"+request.Host+request.Path, 0, fasthttp.StatusBadRequest)
+		hc.lifecycle.AfterRequest(requestCtx, request, response, err)
+		return response, err
+	}
 
 	c := hc.responsePool.Get().(chan httpResult)
 
@@ -101,15 +102,29 @@ func (hc *fastHTTPClient) Do(ctx context.Context, request restql.HTTPRequest) (r
 			return
 		}
 
+		if hasJar {
+			setJarCookies(jar, request, req)
+		}
+
 		res := fasthttp.AcquireResponse()
+		done := trackConnection(request.Host)
 		start := time.Now()
-		err = hc.client.DoTimeout(req, res, request.Timeout)
+		var timings *restql.HTTPTimings
+		if request.Trace && traceable {
+			timings, err = hc.doTraced(req, res, request)
+		} else {
+			err = client.DoTimeout(req, res, request.Timeout)
+		}
 		finish := time.Since(start)
+		done()
 
 		reqUri := req.URI().String()
+		if request.Schema == UnixScheme {
+			reqUri = UnixScheme + "://" + request.Host + string(req.URI().RequestURI())
+		}
 		fasthttp.ReleaseRequest(req)
 
-		c <- httpResult{target: reqUri, err: err, duration: finish, response: res}
+		c <- httpResult{target: reqUri, err: err, duration: finish, response: res, timings: timings}
 	}()
 
 	hr := <-c
@@ -125,8 +140,18 @@ func (hc *fastHTTPClient) Do(ctx context.Context, request restql.HTTPRequest) (r
 		hc.lifecycle.AfterRequest(requestCtx, request, response, hr.err)
 
 		return response, domain.ErrRequestTimeout
+	case hr.err == fasthttp.ErrBodyTooLarge:
+		hc.log.Info("response body too large", "url", hr.target, "method", request.Method)
+		response := makeErrorResponse(hr.target, hr.duration, fasthttp.StatusBadGateway)
+
+		fasthttp.ReleaseResponse(hr.response)
+
+		hc.lifecycle.AfterRequest(requestCtx, request, response, hr.err)
+
+		return response, domain.ErrResponseTooLarge
 	case hr.err != nil:
 		response := makeErrorResponse(hr.target, hr.duration, hr.response.StatusCode())
+		response.Timings = hr.timings
 
 		if hr.response != nil {
 			fasthttp.ReleaseResponse(hr.response)
@@ -137,17 +162,42 @@ func (hc *fastHTTPClient) Do(ctx context.Context, request restql.HTTPRequest) (r
 		return response, errors.Wrap(hr.err, "request execution failed")
 	}
 
-	body, err := unmarshalBody(hc.log, hr.response)
+	body, err := unmarshalBody(hc.log, hr.response, request.MaxResponseSize, hc.bodyLimits)
+	if errors.Is(err, domain.ErrResponseTooComplex) {
+		hc.log.Info("response body too complex", "url", hr.target, "method", request.Method, "error", err)
+		response := makeErrorResponse(hr.target, hr.duration, fasthttp.StatusBadGateway)
+
+		fasthttp.ReleaseResponse(hr.response)
+
+		hc.lifecycle.AfterRequest(requestCtx, request, response, err)
+
+		return response, err
+	}
+	if errors.Is(err, domain.ErrResponseTooLarge) {
+		hc.log.Info("response body too large", "url", hr.target, "method", request.Method, "max-size", request.MaxResponseSize)
+		response := makeErrorResponse(hr.target, hr.duration, fasthttp.StatusBadGateway)
+
+		fasthttp.ReleaseResponse(hr.response)
+
+		hc.lifecycle.AfterRequest(requestCtx, request, response, err)
+
+		return response, err
+	}
 	if err != nil {
 		hc.log.Error("invalid json as body", err, "url", hr.target, "body", body.Unmarshal(), "statusCode", hr.response.StatusCode())
 	}
 
+	if hasJar {
+		storeJarCookies(jar, hr.target, hr.response)
+	}
+
 	response := restql.HTTPResponse{
 		URL:        hr.target,
 		StatusCode: hr.response.StatusCode(),
 		Headers:    readHeaders(hr.response),
 		Duration:   hr.duration,
 		Body:       body,
+		Timings:    hr.timings,
 	}
 
 	fasthttp.ReleaseResponse(hr.response)
@@ -156,3 +206,33 @@ func (hc *fastHTTPClient) Do(ctx context.Context, request restql.HTTPRequest) (r
 
 	return response, nil
 }
+
+// maxResponseBodySize returns the limit applied while reading upstream
+// responses, which is the greatest of the configured ones, so each
+// request can then be checked against its own limit. There is no limit
+// when it is not defined globally, as some mappings can be unrestricted.
+func maxResponseBodySize(cfg *conf.Config) int {
+	result := cfg.Defaults.MaxResponseSize
+	if result <= 0 {
+		return 0
+	}
+
+	levels := []conf.DefaultsConf{}
+	for _, d := range cfg.Defaults.Mappings {
+		levels = append(levels, d)
+	}
+	for _, td := range cfg.Defaults.Tenants {
+		levels = append(levels, td.DefaultsConf)
+		for _, d := range td.Mappings {
+			levels = append(levels, d)
+		}
+	}
+
+	for _, d := range levels {
+		if d.MaxResponseSize > result {
+			result = d.MaxResponseSize
+		}
+	}
+
+	return result
+}
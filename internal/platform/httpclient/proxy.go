@@ -0,0 +1,199 @@
+package httpclient
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// proxyDial returns a dial function opening a tunnel to the address
+// through the proxy. TLS upstreams are handshaked by the client over
+// the tunnel, so the proxy never sees their traffic.
+func proxyDial(resolver *dnsResolver, proxy domain.OutboundProxy) fasthttp.DialFunc {
+	return func(addr string) (net.Conn, error) {
+		conn, err := resolver.Dial(proxy.Addr)
+		if err != nil {
+			return nil, err
+		}
+
+		conn.SetDeadline(time.Now().Add(fasthttp.DefaultDialTimeout))
+
+		switch proxy.Scheme {
+		case domain.SOCKS5ProxyScheme:
+			err = socks5Connect(conn, addr, proxy)
+		default:
+			conn, err = httpConnect(conn, addr, proxy)
+		}
+		if err != nil {
+			conn.Close()
+			return nil, errors.Wrapf(err, "failed to connect to %s through proxy %s", addr, proxy)
+		}
+
+		conn.SetDeadline(time.Time{})
+		return conn, nil
+	}
+}
+
+// httpConnect asks the HTTP proxy to tunnel the connection
+// to the address with the CONNECT method.
+func httpConnect(conn net.Conn, addr string, proxy domain.OutboundProxy) (net.Conn, error) {
+	req := "CONNECT " + addr + " HTTP/1.1\r\nHost: " + addr + "\r\n"
+	if proxy.Username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxy.Username + ":" + proxy.Password))
+		req += "Proxy-Authorization: Basic " + credentials + "\r\n"
+	}
+	req += "\r\n"
+
+	if _, err := io.WriteString(conn, req); err != nil {
+		return conn, err
+	}
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		return conn, err
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return conn, errors.Errorf("proxy responded %s", res.Status)
+	}
+
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn keeps the bytes read ahead of the CONNECT response.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+const (
+	socks5Version        = 0x05
+	socks5NoAuth         = 0x00
+	socks5UserPassAuth   = 0x02
+	socks5UserPassVer    = 0x01
+	socks5ConnectCommand = 0x01
+	socks5IPv4Address    = 0x01
+	socks5DomainAddress  = 0x03
+	socks5IPv6Address    = 0x04
+)
+
+// socks5Connect asks the SOCKS5 proxy to connect to the address, as in
+// RFC 1928, authenticating with username and password, as in RFC 1929,
+// when they are given. Host names are resolved by the proxy.
+func socks5Connect(conn net.Conn, addr string, proxy domain.OutboundProxy) error {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return errors.Errorf("invalid port %s", portStr)
+	}
+
+	method := byte(socks5NoAuth)
+	if proxy.Username != "" {
+		method = socks5UserPassAuth
+	}
+	if _, err := conn.Write([]byte{socks5Version, 1, method}); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != socks5Version {
+		return errors.Errorf("unexpected socks version %d", reply[0])
+	}
+	if reply[1] != method {
+		return errors.New("no acceptable authentication method")
+	}
+
+	if method == socks5UserPassAuth {
+		if len(proxy.Username) > 255 || len(proxy.Password) > 255 {
+			return errors.New("credentials too long")
+		}
+
+		auth := []byte{socks5UserPassVer, byte(len(proxy.Username))}
+		auth = append(auth, proxy.Username...)
+		auth = append(auth, byte(len(proxy.Password)))
+		auth = append(auth, proxy.Password...)
+		if _, err := conn.Write(auth); err != nil {
+			return err
+		}
+
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return err
+		}
+		if reply[1] != 0 {
+			return errors.New("authentication failed")
+		}
+	}
+
+	req := []byte{socks5Version, socks5ConnectCommand, 0}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			req = append(req, socks5IPv4Address)
+			req = append(req, ip4...)
+		} else {
+			req = append(req, socks5IPv6Address)
+			req = append(req, ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return errors.Errorf("host %s too long", host)
+		}
+		req = append(req, socks5DomainAddress, byte(len(host)))
+		req = append(req, host...)
+	}
+	req = append(req, 0, 0)
+	binary.BigEndian.PutUint16(req[len(req)-2:], uint16(port))
+
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if header[1] != 0 {
+		return errors.Errorf("proxy refused the connection with code %d", header[1])
+	}
+
+	var boundLen int
+	switch header[3] {
+	case socks5IPv4Address:
+		boundLen = net.IPv4len
+	case socks5IPv6Address:
+		boundLen = net.IPv6len
+	case socks5DomainAddress:
+		if _, err := io.ReadFull(conn, reply[:1]); err != nil {
+			return err
+		}
+		boundLen = int(reply[0])
+	default:
+		return errors.Errorf("unexpected address type %d", header[3])
+	}
+
+	_, err = io.ReadFull(conn, make([]byte, boundLen+2))
+	return err
+}
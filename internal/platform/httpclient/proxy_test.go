@@ -0,0 +1,193 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestProxiedRequest(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id": "1"}`)
+	}))
+	defer upstream.Close()
+
+	httpProxy, httpTunnels := newConnectProxy(t, "Basic dXNlcjpwYXNz")
+	defer httpProxy.Close()
+
+	socksProxy, socksTunnels := newSOCKS5Proxy(t, "user", "pass")
+	defer socksProxy.Close()
+
+	tests := []struct {
+		name    string
+		proxy   *domain.OutboundProxy
+		tunnels <-chan string
+	}{
+		{"should tunnel requests through http proxy", &domain.OutboundProxy{Scheme: domain.HTTPProxyScheme, Addr: strings.TrimPrefix(httpProxy.URL, "http://"), Username: "user", Password: "pass"}, httpTunnels},
+		{"should tunnel requests through socks5 proxy", &domain.OutboundProxy{Scheme: domain.SOCKS5ProxyScheme, Addr: socksProxy.Addr().String(), Username: "user", Password: "pass"}, socksTunnels},
+	}
+
+	client := newFastHTTPClient(test.NoOpLogger, plugins.NoOpLifecycle, &conf.Config{})
+	host := strings.TrimPrefix(upstream.URL, "http://")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := restql.HTTPRequest{Method: http.MethodGet, Schema: "http", Host: host, Timeout: time.Second}
+
+			response, err := client.Do(domain.WithOutboundProxy(context.Background(), tt.proxy), request)
+			test.VerifyError(t, err)
+
+			test.Equal(t, response.StatusCode, http.StatusOK)
+			test.Equal(t, response.Body.Unmarshal(), map[string]interface{}{"id": "1"})
+			test.Equal(t, <-tt.tunnels, host)
+		})
+	}
+}
+
+func TestProxiedRequestRejected(t *testing.T) {
+	httpProxy, _ := newConnectProxy(t, "Basic dXNlcjpwYXNz")
+	defer httpProxy.Close()
+
+	socksProxy, _ := newSOCKS5Proxy(t, "user", "pass")
+	defer socksProxy.Close()
+
+	tests := []struct {
+		name  string
+		proxy *domain.OutboundProxy
+	}{
+		{"should fail on http proxy authentication", &domain.OutboundProxy{Scheme: domain.HTTPProxyScheme, Addr: strings.TrimPrefix(httpProxy.URL, "http://"), Username: "user", Password: "wrong"}},
+		{"should fail on socks5 proxy authentication", &domain.OutboundProxy{Scheme: domain.SOCKS5ProxyScheme, Addr: socksProxy.Addr().String(), Username: "user", Password: "wrong"}},
+	}
+
+	client := newFastHTTPClient(test.NoOpLogger, plugins.NoOpLifecycle, &conf.Config{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := restql.HTTPRequest{Method: http.MethodGet, Schema: "http", Host: "hero.io", Timeout: time.Second}
+
+			_, err := client.Do(domain.WithOutboundProxy(context.Background(), tt.proxy), request)
+			test.Equal(t, err != nil, true)
+		})
+	}
+}
+
+func newConnectProxy(t *testing.T, authorization string) (*httptest.Server, <-chan string) {
+	tunnels := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect || r.Header.Get("Proxy-Authorization") != authorization {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		tunnels <- r.Host
+
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+
+		pipe(conn, upstream)
+	}))
+
+	return server, tunnels
+}
+
+func newSOCKS5Proxy(t *testing.T, username string, password string) (net.Listener, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tunnels := make(chan string, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSOCKS5(conn, username, password, tunnels)
+		}
+	}()
+
+	return listener, tunnels
+}
+
+func serveSOCKS5(conn net.Conn, username string, password string, tunnels chan<- string) {
+	buf := make([]byte, 512)
+
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		conn.Close()
+		return
+	}
+	io.ReadFull(conn, buf[:buf[1]])
+	conn.Write([]byte{0x05, 0x02})
+
+	io.ReadFull(conn, buf[:2])
+	user := make([]byte, buf[1])
+	io.ReadFull(conn, user)
+	io.ReadFull(conn, buf[:1])
+	pass := make([]byte, buf[0])
+	io.ReadFull(conn, pass)
+	if string(user) != username || string(pass) != password {
+		conn.Write([]byte{0x01, 0x01})
+		conn.Close()
+		return
+	}
+	conn.Write([]byte{0x01, 0x00})
+
+	io.ReadFull(conn, buf[:4])
+	var host string
+	switch buf[3] {
+	case 0x01:
+		io.ReadFull(conn, buf[:net.IPv4len])
+		host = net.IP(buf[:net.IPv4len]).String()
+	case 0x03:
+		io.ReadFull(conn, buf[:1])
+		name := make([]byte, buf[0])
+		io.ReadFull(conn, name)
+		host = string(name)
+	}
+	io.ReadFull(conn, buf[:2])
+	addr := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(buf[:2]))))
+
+	upstream, err := net.Dial("tcp", addr)
+	if err != nil {
+		conn.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+		conn.Close()
+		return
+	}
+	tunnels <- addr
+
+	conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 127, 0, 0, 1, 0, 0})
+	pipe(conn, upstream)
+}
+
+func pipe(a net.Conn, b net.Conn) {
+	go func() {
+		io.Copy(a, b)
+		a.Close()
+	}()
+	io.Copy(b, a)
+	b.Close()
+}
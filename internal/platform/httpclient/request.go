@@ -16,20 +16,26 @@ var (
 	equal     = []byte("=")
 )
 
+// acceptedEncodings are the content encodings restQL is able to
+// decode from upstream responses, used unless the statement
+// defines its own Accept-Encoding header.
+const acceptedEncodings = "gzip, deflate, br"
+
 func setupRequest(request restql.HTTPRequest, req *fasthttp.Request) error {
 	uri := fasthttp.AcquireURI()
 	defer func() {
 		fasthttp.ReleaseURI(uri)
 	}()
 	uri.DisablePathNormalizing = true
-	uri.SetScheme(request.Schema)
-	uri.SetHost(request.Host)
+	schema, host := UpstreamAddr(request.Schema, request.Host)
+	uri.SetScheme(schema)
+	uri.SetHost(host)
 	uri.SetPath(request.Path)
 	uri.SetQueryStringBytes(makeQueryArgs(uri.QueryString(), request))
 
 	req.SetRequestURIBytes(uri.FullURI())
 
-	if request.Method == http.MethodPost || request.Method == http.MethodPut || request.Method == http.MethodPatch {
+	if request.Method == http.MethodPost || request.Method == http.MethodPut || request.Method == http.MethodPatch || request.Body != nil {
 		var data []byte
 
 		body := request.Body
@@ -47,6 +53,7 @@ func setupRequest(request restql.HTTPRequest, req *fasthttp.Request) error {
 		req.SetBody(data)
 	}
 
+	req.Header.Set(fasthttp.HeaderAcceptEncoding, acceptedEncodings)
 	for key, value := range request.Headers {
 		req.Header.Set(key, value)
 	}
@@ -0,0 +1,253 @@
+package httpclient
+
+import (
+	"context"
+	"expvar"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+	"golang.org/x/sync/singleflight"
+)
+
+var errNoAddresses = errors.New("no addresses found for host")
+
+// dnsMetrics holds the metrics of the DNS resolver
+// used by the HTTP client, exposed by the expvar handler.
+var dnsMetrics = expvar.NewMap("dns")
+
+// ResolverStats represents the DNS resolver usage counters.
+type ResolverStats struct {
+	Entries        int     `json:"entries"`
+	Hits           int64   `json:"hits"`
+	NegativeHits   int64   `json:"negativeHits"`
+	Misses         int64   `json:"misses"`
+	Lookups        int64   `json:"lookups"`
+	LookupFailures int64   `json:"lookupFailures"`
+	HitRatio       float64 `json:"hitRatio"`
+	AvgLookupMs    float64 `json:"avgLookupMs"`
+	MaxLookupMs    int64   `json:"maxLookupMs"`
+}
+
+type dnsEntry struct {
+	addrs     []string
+	err       error
+	ttl       time.Duration
+	expiresAt time.Time
+}
+
+// dnsResolver caches host lookups so the connections opened by
+// multiplexed statements do not hit the system resolver each time.
+//
+// Since the system resolver does not expose the records TTL, a host
+// is first cached for minTTL, which is doubled on every lookup that
+// returns the same addresses up to maxTTL, and reset to minTTL when
+// they change. Failed lookups are cached for negativeTTL.
+type dnsResolver struct {
+	lookupHost  func(ctx context.Context, host string) ([]string, error)
+	minTTL      time.Duration
+	maxTTL      time.Duration
+	negativeTTL time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]*dnsEntry
+	group   singleflight.Group
+	next    uint32
+
+	hits           int64
+	negativeHits   int64
+	misses         int64
+	lookups        int64
+	lookupFailures int64
+	lookupTotalMs  int64
+	lookupMaxMs    int64
+}
+
+func newDNSResolver(minTTL, maxTTL, negativeTTL time.Duration) *dnsResolver {
+	if maxTTL < minTTL {
+		maxTTL = minTTL
+	}
+
+	return &dnsResolver{
+		lookupHost:  net.DefaultResolver.LookupHost,
+		minTTL:      minTTL,
+		maxTTL:      maxTTL,
+		negativeTTL: negativeTTL,
+		entries:     make(map[string]*dnsEntry),
+	}
+}
+
+// LookupHost returns the addresses of the host, from the
+// cache when they have not expired yet.
+func (r *dnsResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []string{host}, nil
+	}
+
+	r.mu.RLock()
+	entry, found := r.entries[host]
+	r.mu.RUnlock()
+
+	if found && time.Now().Before(entry.expiresAt) {
+		if entry.err != nil {
+			atomic.AddInt64(&r.negativeHits, 1)
+			return nil, entry.err
+		}
+
+		atomic.AddInt64(&r.hits, 1)
+		return entry.addrs, nil
+	}
+
+	atomic.AddInt64(&r.misses, 1)
+	result, err, _ := r.group.Do(host, func() (interface{}, error) {
+		return r.lookup(ctx, host, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]string), nil
+}
+
+func (r *dnsResolver) lookup(ctx context.Context, host string, previous *dnsEntry) ([]string, error) {
+	start := time.Now()
+	addrs, err := r.lookupHost(ctx, host)
+	r.observeLookup(time.Since(start))
+
+	if err == nil && len(addrs) == 0 {
+		err = errors.Wrap(errNoAddresses, host)
+	}
+
+	if err != nil {
+		atomic.AddInt64(&r.lookupFailures, 1)
+		if r.negativeTTL > 0 && ctx.Err() == nil {
+			r.store(host, &dnsEntry{err: err, ttl: r.negativeTTL})
+		}
+		return nil, err
+	}
+
+	ttl := r.minTTL
+	if previous != nil && previous.err == nil && sameAddresses(previous.addrs, addrs) {
+		ttl = previous.ttl * 2
+		if ttl > r.maxTTL {
+			ttl = r.maxTTL
+		}
+	}
+
+	r.store(host, &dnsEntry{addrs: addrs, ttl: ttl})
+
+	return addrs, nil
+}
+
+func (r *dnsResolver) store(host string, entry *dnsEntry) {
+	entry.expiresAt = time.Now().Add(entry.ttl)
+
+	r.mu.Lock()
+	r.entries[host] = entry
+	r.mu.Unlock()
+}
+
+func (r *dnsResolver) observeLookup(duration time.Duration) {
+	ms := duration.Milliseconds()
+	atomic.AddInt64(&r.lookups, 1)
+	atomic.AddInt64(&r.lookupTotalMs, ms)
+
+	for {
+		current := atomic.LoadInt64(&r.lookupMaxMs)
+		if ms <= current || atomic.CompareAndSwapInt64(&r.lookupMaxMs, current, ms) {
+			return
+		}
+	}
+}
+
+// Dial opens a TCP connection to the address, resolving its host
+// through the cache and trying each address in a round-robin manner.
+func (r *dnsResolver) Dial(addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fasthttp.DefaultDialTimeout)
+	defer cancel()
+
+	addrs, err := r.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.connect(addrs, port)
+}
+
+// connect opens a TCP connection to the port of the first address
+// that accepts it, rotating the address tried first on each call.
+func (r *dnsResolver) connect(addrs []string, port string) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: fasthttp.DefaultDialTimeout}
+	start := int(atomic.AddUint32(&r.next, 1))
+
+	var conn net.Conn
+	var err error
+	for i := range addrs {
+		ip := addrs[(start+i)%len(addrs)]
+		conn, err = dialer.Dial("tcp", net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+
+	return nil, err
+}
+
+// Stats returns a snapshot of the resolver usage counters.
+func (r *dnsResolver) Stats() ResolverStats {
+	r.mu.RLock()
+	entries := len(r.entries)
+	r.mu.RUnlock()
+
+	hits := atomic.LoadInt64(&r.hits)
+	negativeHits := atomic.LoadInt64(&r.negativeHits)
+	misses := atomic.LoadInt64(&r.misses)
+	lookups := atomic.LoadInt64(&r.lookups)
+
+	stats := ResolverStats{
+		Entries:        entries,
+		Hits:           hits,
+		NegativeHits:   negativeHits,
+		Misses:         misses,
+		Lookups:        lookups,
+		LookupFailures: atomic.LoadInt64(&r.lookupFailures),
+		MaxLookupMs:    atomic.LoadInt64(&r.lookupMaxMs),
+	}
+
+	if total := hits + negativeHits + misses; total > 0 {
+		stats.HitRatio = float64(hits+negativeHits) / float64(total)
+	}
+
+	if lookups > 0 {
+		stats.AvgLookupMs = float64(atomic.LoadInt64(&r.lookupTotalMs)) / float64(lookups)
+	}
+
+	return stats
+}
+
+func sameAddresses(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	seen := make(map[string]struct{}, len(a))
+	for _, addr := range a {
+		seen[addr] = struct{}{}
+	}
+
+	for _, addr := range b {
+		if _, found := seen[addr]; !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,95 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+type stubLookup struct {
+	addrs []string
+	err   error
+	calls int
+}
+
+func (s *stubLookup) LookupHost(ctx context.Context, host string) ([]string, error) {
+	s.calls++
+	return s.addrs, s.err
+}
+
+func TestDNSResolverCache(t *testing.T) {
+	lookup := &stubLookup{addrs: []string{"10.0.0.1", "10.0.0.2"}}
+	r := newDNSResolver(time.Minute, 4*time.Minute, time.Second)
+	r.lookupHost = lookup.LookupHost
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		addrs, err := r.LookupHost(ctx, "hero.io")
+		test.VerifyError(t, err)
+		test.Equal(t, addrs, []string{"10.0.0.1", "10.0.0.2"})
+	}
+
+	test.Equal(t, lookup.calls, 1)
+	test.Equal(t, r.entries["hero.io"].ttl, time.Minute)
+
+	stats := r.Stats()
+	test.Equal(t, stats.Hits, int64(2))
+	test.Equal(t, stats.Misses, int64(1))
+	test.Equal(t, stats.Lookups, int64(1))
+	test.Equal(t, stats.Entries, 1)
+
+	addrs, err := r.LookupHost(ctx, "10.0.0.3")
+	test.VerifyError(t, err)
+	test.Equal(t, addrs, []string{"10.0.0.3"})
+	test.Equal(t, lookup.calls, 1)
+}
+
+func TestDNSResolverTTL(t *testing.T) {
+	lookup := &stubLookup{addrs: []string{"10.0.0.1"}}
+	r := newDNSResolver(time.Minute, 3*time.Minute, time.Second)
+	r.lookupHost = lookup.LookupHost
+
+	expire := func() {
+		r.entries["hero.io"].expiresAt = time.Now().Add(-time.Second)
+	}
+
+	ctx := context.Background()
+	expectedTTLs := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute}
+	for _, expected := range expectedTTLs {
+		_, err := r.LookupHost(ctx, "hero.io")
+		test.VerifyError(t, err)
+		test.Equal(t, r.entries["hero.io"].ttl, expected)
+		expire()
+	}
+
+	lookup.addrs = []string{"10.0.0.2"}
+	addrs, err := r.LookupHost(ctx, "hero.io")
+	test.VerifyError(t, err)
+	test.Equal(t, addrs, []string{"10.0.0.2"})
+	test.Equal(t, r.entries["hero.io"].ttl, time.Minute)
+}
+
+func TestDNSResolverNegativeCache(t *testing.T) {
+	lookupErr := errors.New("no such host")
+	lookup := &stubLookup{err: lookupErr}
+	r := newDNSResolver(time.Minute, time.Minute, time.Minute)
+	r.lookupHost = lookup.LookupHost
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, err := r.LookupHost(ctx, "villain.io")
+		if err != lookupErr {
+			t.Fatalf("LookupHost error = %v, want %v", err, lookupErr)
+		}
+	}
+
+	test.Equal(t, lookup.calls, 1)
+
+	stats := r.Stats()
+	test.Equal(t, stats.NegativeHits, int64(1))
+	test.Equal(t, stats.LookupFailures, int64(1))
+	test.Equal(t, stats.HitRatio, 0.5)
+}
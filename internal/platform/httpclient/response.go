@@ -1,16 +1,62 @@
 package httpclient
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/codec"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
 	"github.com/valyala/fasthttp"
-	"time"
 )
 
-func unmarshalBody(log restql.Logger, response *fasthttp.Response) (*restql.ResponseBody, error) {
-	bodyByte := response.Body()
+var errInvalidEncoding = errors.New("invalid content encoding")
+
+// bodyLimits bounds the JSON nesting depth and the number of
+// object keys of upstream bodies, where zero means no limit.
+type bodyLimits struct {
+	maxDepth int
+	maxKeys  int
+}
+
+func unmarshalBody(log restql.Logger, response *fasthttp.Response, maxSize int, limits bodyLimits) (*restql.ResponseBody, error) {
+	if exceedsSize(response.Body(), maxSize) {
+		return restql.NewResponseBodyFromBytes(log, nil), domain.ErrResponseTooLarge
+	}
+
+	bodyByte, err := decodeBody(response, maxSize)
+	if err != nil {
+		return restql.NewResponseBodyFromBytes(log, nil), err
+	}
+
 	bb := make([]byte, len(bodyByte))
 	copy(bb, bodyByte)
 
+	if isMsgpack(response) {
+		data, err := msgpackToJSON(bb, limits)
+		if errors.Is(err, domain.ErrResponseTooComplex) {
+			return restql.NewResponseBodyFromBytes(log, nil), err
+		}
+		if err != nil {
+			return restql.NewResponseBodyFromBytes(log, bb), err
+		}
+		bb = data
+	}
+
+	if err := checkComplexity(bb, limits); err != nil {
+		return restql.NewResponseBodyFromBytes(log, nil), err
+	}
+
 	rb := restql.NewResponseBodyFromBytes(log, bb)
 	if !rb.Valid() {
 		return rb, errInvalidJSON
@@ -19,6 +65,131 @@ func unmarshalBody(log restql.Logger, response *fasthttp.Response) (*restql.Resp
 	return rb, nil
 }
 
+// checkComplexity scans the JSON body, without decoding it, to refuse
+// documents nested too deeply or with too many object keys, which
+// would exhaust the stack or memory when unmarshaled.
+func checkComplexity(body []byte, limits bodyLimits) error {
+	if limits.maxDepth <= 0 && limits.maxKeys <= 0 {
+		return nil
+	}
+
+	depth, keys := 0, 0
+	inString, escaped := false, false
+	for _, c := range body {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if limits.maxDepth > 0 && depth > limits.maxDepth {
+				return fmt.Errorf("%w: nesting depth exceeds %d", domain.ErrResponseTooComplex, limits.maxDepth)
+			}
+		case '}', ']':
+			depth--
+		case ':':
+			keys++
+			if limits.maxKeys > 0 && keys > limits.maxKeys {
+				return fmt.Errorf("%w: object keys exceed %d", domain.ErrResponseTooComplex, limits.maxKeys)
+			}
+		}
+	}
+
+	return nil
+}
+
+// exceedsSize reports if the body is greater than
+// the limit, where zero means there is no limit.
+func exceedsSize(body []byte, maxSize int) bool {
+	return maxSize > 0 && len(body) > maxSize
+}
+
+// decodeBody decompresses the upstream body according to its
+// Content-Encoding, which is removed from the response headers
+// as the body is then handled in its decoded form. Decompression
+// stops as soon as the body exceeds the maximum size, so small
+// compressed payloads cannot expand without bounds in memory.
+func decodeBody(response *fasthttp.Response, maxSize int) ([]byte, error) {
+	encoding := strings.ToLower(strings.TrimSpace(string(response.Header.Peek(fasthttp.HeaderContentEncoding))))
+
+	compressed := bytes.NewReader(response.Body())
+
+	var reader io.Reader
+	var err error
+	switch encoding {
+	case "", "identity":
+		return response.Body(), nil
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(compressed)
+	case "deflate":
+		reader, err = zlib.NewReader(compressed)
+	case "br":
+		reader = brotli.NewReader(compressed)
+	default:
+		return response.Body(), nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(errInvalidEncoding, "failed to decode %s body : %v", encoding, err)
+	}
+
+	if maxSize > 0 {
+		reader = io.LimitReader(reader, int64(maxSize)+1)
+	}
+
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(errInvalidEncoding, "failed to decode %s body : %v", encoding, err)
+	}
+
+	if exceedsSize(body, maxSize) {
+		return nil, domain.ErrResponseTooLarge
+	}
+
+	response.Header.Del(fasthttp.HeaderContentEncoding)
+	response.Header.SetContentLength(len(body))
+
+	return body, nil
+}
+
+func isMsgpack(response *fasthttp.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(string(response.Header.ContentType()))
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/msgpack" || mediaType == "application/x-msgpack" || mediaType == "application/vnd.msgpack"
+}
+
+// msgpackToJSON converts the upstream body, so it can be
+// handled as any other JSON response through the query,
+// applying the same limits as to JSON bodies while decoding.
+func msgpackToJSON(data []byte, limits bodyLimits) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	v, err := codec.UnmarshalMsgpack(data, codec.DecodeLimits{MaxDepth: limits.maxDepth, MaxKeys: limits.maxKeys})
+	if errors.Is(err, codec.ErrTooComplex) {
+		return nil, fmt.Errorf("%w: %v", domain.ErrResponseTooComplex, err)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid msgpack body")
+	}
+
+	return json.Marshal(v)
+}
+
 func readHeaders(res *fasthttp.Response) restql.Headers {
 	h := make(restql.Headers)
 	res.Header.VisitAll(func(key, value []byte) {
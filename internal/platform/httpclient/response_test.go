@@ -0,0 +1,113 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/valyala/fasthttp"
+)
+
+func TestCheckComplexity(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		limits   bodyLimits
+		expected bool
+	}{
+		{"no limits", strings.Repeat("[", 200) + strings.Repeat("]", 200), bodyLimits{}, false},
+		{"depth within limit", `{"a": [{"b": 1}]}`, bodyLimits{maxDepth: 3}, false},
+		{"depth exceeding limit", `{"a": [{"b": [1]}]}`, bodyLimits{maxDepth: 3}, true},
+		{"brackets inside strings", `{"a": "[[[{{{", "b": "\"[[["}`, bodyLimits{maxDepth: 1}, false},
+		{"keys within limit", `[{"a": 1}, {"a": 2}]`, bodyLimits{maxKeys: 2}, false},
+		{"keys exceeding limit", `[{"a": 1}, {"a": 2}, {"a": 3}]`, bodyLimits{maxKeys: 2}, true},
+		{"colons inside strings", `{"a": "b:c:d"}`, bodyLimits{maxKeys: 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkComplexity([]byte(tt.body), tt.limits)
+			test.Equal(t, errors.Is(err, domain.ErrResponseTooComplex), tt.expected)
+		})
+	}
+}
+
+func TestUnmarshalBodyTooComplex(t *testing.T) {
+	response := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(response)
+	response.SetBodyString(strings.Repeat(`{"a":`, 10) + "1" + strings.Repeat("}", 10))
+
+	body, err := unmarshalBody(test.NoOpLogger, response, 0, bodyLimits{maxDepth: 5})
+
+	test.Equal(t, err.Error(), "response body too complex: nesting depth exceeds 5")
+	test.Equal(t, body.Bytes(), []byte(nil))
+}
+
+func TestUnmarshalMsgpackBodyTooComplex(t *testing.T) {
+	response := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(response)
+	response.Header.SetContentType("application/msgpack")
+	response.SetBody(append(bytes.Repeat([]byte{0x91}, 10), 0x01))
+
+	body, err := unmarshalBody(test.NoOpLogger, response, 0, bodyLimits{maxDepth: 5})
+
+	test.Equal(t, errors.Is(err, domain.ErrResponseTooComplex), true)
+	test.Equal(t, body.Bytes(), []byte(nil))
+}
+
+func TestUnmarshalCompressedBody(t *testing.T) {
+	compress := func(encoding string, body []byte) []byte {
+		var buf bytes.Buffer
+		var w io.WriteCloser
+		switch encoding {
+		case "gzip":
+			w = gzip.NewWriter(&buf)
+		case "deflate":
+			w = zlib.NewWriter(&buf)
+		case "br":
+			w = brotli.NewWriter(&buf)
+		}
+		_, err := w.Write(body)
+		test.VerifyError(t, err)
+		test.VerifyError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	bomb := append(append([]byte(`{"a":"`), bytes.Repeat([]byte("0"), 10<<20)...), `"}`...)
+
+	tests := []struct {
+		name        string
+		encoding    string
+		body        []byte
+		expected    []byte
+		expectedErr error
+	}{
+		{"gzip body", "gzip", []byte(`{"id":1}`), []byte(`{"id":1}`), nil},
+		{"deflate body", "deflate", []byte(`{"id":1}`), []byte(`{"id":1}`), nil},
+		{"brotli body", "br", []byte(`{"id":1}`), []byte(`{"id":1}`), nil},
+		{"gzip body expanding past the limit", "gzip", bomb, nil, domain.ErrResponseTooLarge},
+		{"deflate body expanding past the limit", "deflate", bomb, nil, domain.ErrResponseTooLarge},
+		{"brotli body expanding past the limit", "br", bomb, nil, domain.ErrResponseTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := fasthttp.AcquireResponse()
+			defer fasthttp.ReleaseResponse(response)
+			response.Header.Set(fasthttp.HeaderContentEncoding, tt.encoding)
+			response.SetBody(compress(tt.encoding, tt.body))
+
+			body, err := unmarshalBody(test.NoOpLogger, response, 1024, bodyLimits{})
+
+			test.Equal(t, errors.Is(err, tt.expectedErr), true)
+			test.Equal(t, body.Bytes(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var errCertificateRevoked = errors.New("upstream certificate revoked")
+
+// checkRevocation verifies that the certificate of the upstream is not
+// revoked, according to its stapled OCSP response or to the revocation
+// lists of its issuer. Connections whose certificate was not verified
+// against a trusted issuer are not checked.
+func checkRevocation(check string, cs tls.ConnectionState, crls *crlCache) error {
+	if len(cs.VerifiedChains) == 0 || len(cs.VerifiedChains[0]) < 2 {
+		return nil
+	}
+	leaf, issuer := cs.VerifiedChains[0][0], cs.VerifiedChains[0][1]
+
+	switch check {
+	case domain.OCSPRevocationCheck, domain.StrictOCSPRevocationCheck:
+		if len(cs.OCSPResponse) == 0 {
+			if check == domain.StrictOCSPRevocationCheck {
+				return errors.New("upstream did not staple an ocsp response")
+			}
+			return nil
+		}
+		return verifyOCSP(cs.OCSPResponse, leaf, issuer, time.Now())
+	case domain.CRLRevocationCheck:
+		return crls.verify(leaf, issuer, time.Now())
+	default:
+		return nil
+	}
+}
+
+var (
+	oidOCSPBasicResponse = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 1}
+	oidOCSPSigning       = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 9}
+)
+
+// The structures of an OCSP response, as in RFC 6960.
+type ocspResponse struct {
+	Status   asn1.Enumerated
+	Response ocspResponseBytes `asn1:"explicit,tag:0,optional"`
+}
+
+type ocspResponseBytes struct {
+	ResponseType asn1.ObjectIdentifier
+	Response     []byte
+}
+
+type basicOCSPResponse struct {
+	TBSResponseData    ocspResponseData
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+	Certificates       []asn1.RawValue `asn1:"explicit,tag:0,optional"`
+}
+
+type ocspResponseData struct {
+	Raw            asn1.RawContent
+	Version        int `asn1:"optional,default:0,explicit,tag:0"`
+	RawResponderID asn1.RawValue
+	ProducedAt     time.Time `asn1:"generalized"`
+	Responses      []ocspSingleResponse
+}
+
+type ocspSingleResponse struct {
+	CertID           ocspCertID
+	Good             asn1.Flag        `asn1:"tag:0,optional"`
+	Revoked          ocspRevokedInfo  `asn1:"tag:1,optional"`
+	Unknown          asn1.Flag        `asn1:"tag:2,optional"`
+	ThisUpdate       time.Time        `asn1:"generalized"`
+	NextUpdate       time.Time        `asn1:"generalized,explicit,tag:0,optional"`
+	SingleExtensions []pkix.Extension `asn1:"explicit,tag:1,optional"`
+}
+
+type ocspCertID struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	NameHash      []byte
+	IssuerKeyHash []byte
+	SerialNumber  *big.Int
+}
+
+type ocspRevokedInfo struct {
+	RevocationTime time.Time       `asn1:"generalized"`
+	Reason         asn1.Enumerated `asn1:"explicit,tag:0,optional"`
+}
+
+// verifyOCSP checks the OCSP response signed by the issuer, or by
+// a responder it delegated to, for the status of the certificate.
+func verifyOCSP(raw []byte, leaf *x509.Certificate, issuer *x509.Certificate, now time.Time) error {
+	var resp ocspResponse
+	if rest, err := asn1.Unmarshal(raw, &resp); err != nil || len(rest) > 0 {
+		return errors.New("malformed ocsp response")
+	}
+	if resp.Status != 0 {
+		return errors.Errorf("ocsp response status %d", resp.Status)
+	}
+	if !resp.Response.ResponseType.Equal(oidOCSPBasicResponse) {
+		return errors.New("unsupported ocsp response type")
+	}
+
+	var basic basicOCSPResponse
+	if rest, err := asn1.Unmarshal(resp.Response.Response, &basic); err != nil || len(rest) > 0 {
+		return errors.New("malformed ocsp response")
+	}
+
+	signer, err := ocspSigner(basic, issuer)
+	if err != nil {
+		return err
+	}
+
+	algorithm, found := signatureAlgorithms[basic.SignatureAlgorithm.Algorithm.String()]
+	if !found {
+		return errors.New("unsupported ocsp signature algorithm")
+	}
+	if err := signer.CheckSignature(algorithm, basic.TBSResponseData.Raw, basic.Signature.RightAlign()); err != nil {
+		return errors.Wrap(err, "invalid ocsp signature")
+	}
+
+	for _, r := range basic.TBSResponseData.Responses {
+		if r.CertID.SerialNumber == nil || r.CertID.SerialNumber.Cmp(leaf.SerialNumber) != 0 {
+			continue
+		}
+
+		if now.Before(r.ThisUpdate) || (!r.NextUpdate.IsZero() && now.After(r.NextUpdate)) {
+			return errors.New("ocsp response out of its validity period")
+		}
+
+		switch {
+		case bool(r.Good):
+			return nil
+		case bool(r.Unknown):
+			return errors.New("upstream certificate unknown to the ocsp responder")
+		default:
+			return errCertificateRevoked
+		}
+	}
+
+	return errors.New("ocsp response does not cover the upstream certificate")
+}
+
+// ocspSigner returns the issuer, or the certificate of the responder
+// it authorized to sign OCSP responses on its behalf.
+func ocspSigner(basic basicOCSPResponse, issuer *x509.Certificate) (*x509.Certificate, error) {
+	if len(basic.Certificates) == 0 {
+		return issuer, nil
+	}
+
+	responder, err := x509.ParseCertificate(basic.Certificates[0].FullBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed ocsp responder certificate")
+	}
+	if responder.Equal(issuer) {
+		return issuer, nil
+	}
+
+	if err := responder.CheckSignatureFrom(issuer); err != nil {
+		return nil, errors.Wrap(err, "ocsp responder not authorized by the issuer")
+	}
+	for _, usage := range responder.ExtKeyUsage {
+		if usage == x509.ExtKeyUsageOCSPSigning {
+			return responder, nil
+		}
+	}
+	for _, usage := range responder.UnknownExtKeyUsage {
+		if usage.Equal(oidOCSPSigning) {
+			return responder, nil
+		}
+	}
+
+	return nil, errors.New("ocsp responder not authorized by the issuer")
+}
+
+// signatureAlgorithms maps the identifiers of the
+// algorithms OCSP responses are signed with.
+var signatureAlgorithms = map[string]x509.SignatureAlgorithm{
+	"1.2.840.113549.1.1.5":  x509.SHA1WithRSA,
+	"1.2.840.113549.1.1.11": x509.SHA256WithRSA,
+	"1.2.840.113549.1.1.12": x509.SHA384WithRSA,
+	"1.2.840.113549.1.1.13": x509.SHA512WithRSA,
+	"1.2.840.10045.4.1":     x509.ECDSAWithSHA1,
+	"1.2.840.10045.4.3.2":   x509.ECDSAWithSHA256,
+	"1.2.840.10045.4.3.3":   x509.ECDSAWithSHA384,
+	"1.2.840.10045.4.3.4":   x509.ECDSAWithSHA512,
+	"1.3.101.112":           x509.PureEd25519,
+}
+
+// crlFetchTimeout bounds the download of a revocation list,
+// which happens during the handshake with the upstream.
+const crlFetchTimeout = 5 * time.Second
+
+// crlCache keeps the revocation lists of the upstream certificates,
+// downloaded from their distribution points until their next update.
+type crlCache struct {
+	fetch func(url string) ([]byte, error)
+
+	mu    sync.Mutex
+	lists map[string]*pkix.CertificateList
+}
+
+func newCRLCache() *crlCache {
+	client := &http.Client{Timeout: crlFetchTimeout}
+	return &crlCache{
+		lists: make(map[string]*pkix.CertificateList),
+		fetch: func(url string) ([]byte, error) {
+			res, err := client.Get(url)
+			if err != nil {
+				return nil, err
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusOK {
+				return nil, errors.Errorf("crl distribution point responded %s", res.Status)
+			}
+			return ioutil.ReadAll(res.Body)
+		},
+	}
+}
+
+// verify checks the certificate against the revocation list of the
+// first of its distribution points available, failing when none is.
+func (c *crlCache) verify(leaf *x509.Certificate, issuer *x509.Certificate, now time.Time) error {
+	if len(leaf.CRLDistributionPoints) == 0 {
+		return errors.New("upstream certificate has no crl distribution point")
+	}
+
+	var lastErr error
+	for _, url := range leaf.CRLDistributionPoints {
+		list, err := c.get(url, issuer, now)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		for _, revoked := range list.TBSCertList.RevokedCertificates {
+			if revoked.SerialNumber.Cmp(leaf.SerialNumber) == 0 {
+				return errCertificateRevoked
+			}
+		}
+		return nil
+	}
+
+	return errors.Wrap(lastErr, "failed to check the upstream certificate revocation")
+}
+
+func (c *crlCache) get(url string, issuer *x509.Certificate, now time.Time) (*pkix.CertificateList, error) {
+	c.mu.Lock()
+	list, found := c.lists[url]
+	c.mu.Unlock()
+	if found && !list.HasExpired(now) {
+		return list, nil
+	}
+
+	raw, err := c.fetch(url)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err = x509.ParseCRL(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed crl")
+	}
+	if err := issuer.CheckCRLSignature(list); err != nil {
+		return nil, errors.Wrap(err, "invalid crl signature")
+	}
+
+	c.mu.Lock()
+	c.lists[url] = list
+	c.mu.Unlock()
+
+	return list, nil
+}
@@ -0,0 +1,139 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+// S3Scheme is the scheme of the mappings answered by an object
+// of a bucket in a S3 compatible storage, in the form
+// s3://<bucket>/<key>.
+const S3Scheme = "s3"
+
+const (
+	defaultS3Region = "us-east-1"
+	s3Service       = "s3"
+
+	// emptyPayloadHash is the SHA-256 of the empty body of GET requests.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// s3ForwardedHeaders are the statement headers kept in the object
+// requests, allowing the revalidation of cached responses.
+var s3ForwardedHeaders = []string{"If-None-Match", "If-Modified-Since"}
+
+// S3Client is an HTTPClient that translates the requests to mappings
+// with the s3 scheme into GET requests of the object, signed with the
+// AWS credentials of the environment, executed by the wrapped client.
+// Every other request is executed by the wrapped client unchanged.
+type S3Client struct {
+	log         restql.Logger
+	client      domain.HTTPClient
+	region      string
+	scheme      string
+	host        string
+	pathStyle   bool
+	credentials awsCredentials
+	now         func() time.Time
+}
+
+// WithS3 wraps the client with a S3Client for the storage at the
+// endpoint, or the AWS one of the region when empty. Buckets are
+// addressed in the path instead of the host when pathStyle is true.
+// Requests are anonymous when AWS_ACCESS_KEY_ID is not set.
+func WithS3(log restql.Logger, client domain.HTTPClient, region string, endpoint string, pathStyle bool) (domain.HTTPClient, error) {
+	if region == "" {
+		region = defaultS3Region
+	}
+
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, errors.Errorf("invalid s3 endpoint %s", endpoint)
+	}
+
+	return &S3Client{
+		log:         log,
+		client:      client,
+		region:      region,
+		scheme:      u.Scheme,
+		host:        u.Host,
+		pathStyle:   pathStyle,
+		credentials: awsCredentialsFromEnv(),
+		now:         time.Now,
+	}, nil
+}
+
+// Do fetches the object of requests to mappings with the s3 scheme.
+func (sc *S3Client) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	if request.Schema != S3Scheme {
+		return sc.client.Do(ctx, request)
+	}
+
+	if request.Method != http.MethodGet {
+		return restql.HTTPResponse{
+			URL:        S3Scheme + "://" + request.Host + request.Path,
+			StatusCode: http.StatusMethodNotAllowed,
+			Body:       restql.NewResponseBodyFromValue(sc.log, "s3 resources only support the from method"),
+		}, nil
+	}
+
+	return sc.client.Do(ctx, sc.objectRequest(request))
+}
+
+// objectRequest addresses the object in the storage endpoint,
+// replacing the statement headers by the signed ones. The
+// query parameters are not sent, as objects are named by key.
+func (sc *S3Client) objectRequest(request restql.HTTPRequest) restql.HTTPRequest {
+	key := strings.TrimPrefix(request.Path, "/")
+
+	host, path := request.Host+"."+sc.host, "/"+key
+	if sc.pathStyle {
+		host, path = sc.host, "/"+request.Host+"/"+key
+	}
+
+	headers := make(restql.Headers)
+	for _, name := range s3ForwardedHeaders {
+		if value, found := request.Headers[name]; found {
+			headers[name] = value
+		}
+	}
+
+	signed := request
+	signed.Schema = sc.scheme
+	signed.Host = host
+	signed.Path = path
+	signed.Query = nil
+	signed.Body = nil
+	signed.Headers = sc.sign(host, path, headers, sc.now())
+
+	return signed
+}
+
+// sign adds to the headers the AWS Signature Version 4 of the
+// GET request, signing the x-amz-* headers and the host along
+// with the given ones.
+func (sc *S3Client) sign(host string, path string, headers restql.Headers, now time.Time) restql.Headers {
+	if sc.credentials.accessKeyID == "" {
+		return headers
+	}
+
+	signer := sigV4Signer{credentials: sc.credentials, region: sc.region, service: s3Service}
+	return signer.sign(http.MethodGet, host, s3EscapePath(path), "", headers, emptyPayloadHash, now)
+}
+
+// s3EscapePath encodes every byte of the path
+// but the unreserved characters and the slashes.
+func s3EscapePath(path string) string {
+	return uriEncode(path, false)
+}
@@ -0,0 +1,292 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4DateFormat = "20060102T150405Z"
+	hmacAlgorithm   = "HMAC-SHA256"
+
+	defaultSigV4Region = "us-east-1"
+	defaultHMACHeader  = "Authorization"
+
+	// Headers set on the requests signed with the hmac scheme.
+	HMACDateHeader        = "X-Signature-Date"
+	HMACContentHashHeader = "X-Content-Sha256"
+)
+
+// SigningClient is an HTTPClient that signs the requests of the
+// statements whose mapping declares a signing scheme, as given by
+// domain.GetRequestSigning, computing the signature over the method,
+// the path, the canonical query string and the hash of the body.
+// Every other request is executed by the wrapped client unchanged.
+type SigningClient struct {
+	client      domain.HTTPClient
+	credentials awsCredentials
+	now         func() time.Time
+}
+
+// WithSigning wraps the client with a SigningClient, which
+// takes the sigv4 credentials from the AWS environment variables.
+func WithSigning(client domain.HTTPClient) domain.HTTPClient {
+	return &SigningClient{client: client, credentials: awsCredentialsFromEnv(), now: time.Now}
+}
+
+// Do signs the request when its statement requires it.
+func (sc *SigningClient) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	signing, ok := domain.GetRequestSigning(ctx)
+	if !ok {
+		return sc.client.Do(ctx, request)
+	}
+
+	signed, err := sc.sign(signing, request, sc.now())
+	if err != nil {
+		return restql.HTTPResponse{}, err
+	}
+
+	return sc.client.Do(ctx, signed)
+}
+
+func (sc *SigningClient) sign(signing *domain.RequestSigning, request restql.HTTPRequest, now time.Time) (restql.HTTPRequest, error) {
+	body, err := requestBody(request)
+	if err != nil {
+		return restql.HTTPRequest{}, err
+	}
+
+	query, err := canonicalQuery(request)
+	if err != nil {
+		return restql.HTTPRequest{}, err
+	}
+
+	// The body is sent as signed, since
+	// string bodies are written unchanged.
+	if body != nil {
+		request.Body = string(body)
+	}
+
+	headers := make(restql.Headers, len(request.Headers)+4)
+	for k, v := range request.Headers {
+		headers[k] = v
+	}
+	payloadHash := sha256Hex(string(body))
+
+	switch signing.Scheme {
+	case domain.HMACSigningScheme:
+		signHMAC(signing, request.Method, uriEncode(request.Path, false), query, headers, payloadHash, now)
+	case domain.SigV4SigningScheme:
+		if sc.credentials.accessKeyID == "" {
+			return restql.HTTPRequest{}, errors.New("no aws credentials to sign the request")
+		}
+
+		region := signing.Region
+		if region == "" {
+			region = defaultSigV4Region
+		}
+
+		signer := sigV4Signer{credentials: sc.credentials, region: region, service: signing.Service}
+		signed := signer.sign(request.Method, request.Host, sigV4Path(request.Path, signing.Service), query, make(restql.Headers), payloadHash, now)
+		for k, v := range signed {
+			headers[k] = v
+		}
+	default:
+		return restql.HTTPRequest{}, errors.Errorf("unknown signing scheme %s", signing.Scheme)
+	}
+
+	request.Headers = headers
+	return request, nil
+}
+
+// signHMAC adds to the headers the signature of the string
+// made of the method, path, query, date and body hash, each
+// in its own line, with the HMAC-SHA256 of the secret.
+func signHMAC(signing *domain.RequestSigning, method string, path string, query string, headers restql.Headers, payloadHash string, now time.Time) {
+	date := now.UTC().Format(sigV4DateFormat)
+	stringToSign := strings.Join([]string{method, path, query, date, payloadHash}, "\n")
+	signature := hex.EncodeToString(hmacSHA256([]byte(signing.Secret), stringToSign))
+
+	header := signing.Header
+	if header == "" {
+		header = defaultHMACHeader
+	}
+
+	headers[HMACDateHeader] = date
+	headers[HMACContentHashHeader] = payloadHash
+	headers[header] = hmacAlgorithm + " KeyId=" + signing.KeyID + ", Signature=" + signature
+}
+
+// requestBody returns the body as it is written by the client,
+// or nil when the request is sent without one.
+func requestBody(request restql.HTTPRequest) ([]byte, error) {
+	if request.Method != http.MethodPost && request.Method != http.MethodPut && request.Method != http.MethodPatch && request.Body == nil {
+		return nil, nil
+	}
+
+	if strBody, ok := request.Body.(string); ok {
+		return []byte(strBody), nil
+	}
+
+	data, err := json.Marshal(request.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal request body")
+	}
+
+	return data, nil
+}
+
+// canonicalQuery encodes the query parameters sent by the client
+// sorted by name and value, as required by the signature schemes.
+func canonicalQuery(request restql.HTTPRequest) (string, error) {
+	values, err := url.ParseQuery(string(makeQueryArgs(nil, request)))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse request query")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var params []string
+	for _, name := range names {
+		vs := values[name]
+		sort.Strings(vs)
+		for _, v := range vs {
+			params = append(params, uriEncode(name, true)+"="+uriEncode(v, true))
+		}
+	}
+
+	return strings.Join(params, "&"), nil
+}
+
+// sigV4Path returns the canonical path, which is encoded
+// twice for every service but S3.
+func sigV4Path(path string, service string) string {
+	if path == "" {
+		path = "/"
+	}
+
+	escaped := uriEncode(path, false)
+	if service == s3Service {
+		return escaped
+	}
+	return uriEncode(escaped, false)
+}
+
+type awsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+func awsCredentialsFromEnv() awsCredentials {
+	return awsCredentials{
+		accessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// sigV4Signer computes the AWS Signature Version 4
+// of requests to a service in a region.
+type sigV4Signer struct {
+	credentials awsCredentials
+	region      string
+	service     string
+}
+
+// sign adds to the headers the x-amz-* ones and the authorization,
+// signing the host along with every header given.
+func (s sigV4Signer) sign(method string, host string, path string, query string, headers restql.Headers, payloadHash string, now time.Time) restql.Headers {
+	amzDate := now.UTC().Format(sigV4DateFormat)
+	date := amzDate[:8]
+
+	headers["X-Amz-Date"] = amzDate
+	headers["X-Amz-Content-Sha256"] = payloadHash
+	if s.credentials.sessionToken != "" {
+		headers["X-Amz-Security-Token"] = s.credentials.sessionToken
+	}
+
+	canonical := map[string]string{"host": host}
+	for name, value := range headers {
+		canonical[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+
+	names := make([]string, 0, len(canonical))
+	for name := range canonical {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + canonical[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		path,
+		query,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/" + s.service + "/aws4_request"
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, sha256Hex(canonicalRequest)}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.credentials.secretAccessKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s.service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	headers["Authorization"] = sigV4Algorithm + " Credential=" + s.credentials.accessKeyID + "/" + scope +
+		", SignedHeaders=" + signedHeaders + ", Signature=" + signature
+
+	return headers
+}
+
+// uriEncode encodes every byte of the value but the
+// unreserved characters and, unless asked, the slashes.
+func uriEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+	}
+	return b.String()
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
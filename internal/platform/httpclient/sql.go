@@ -0,0 +1,279 @@
+package httpclient
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+// SQLScheme is the scheme of the mappings answered by a read-only
+// query of a configured database instead of an HTTP call, in the
+// form sql://<database>/<query>.
+const SQLScheme = "sql"
+
+// Placeholder styles of the SQL drivers.
+const (
+	SQLQuestionPlaceholder = "?"
+	SQLDollarPlaceholder   = "$"
+)
+
+const defaultSQLMaxRows = 100
+
+var (
+	sqlParamRegex    = regexp.MustCompile(`(^|[^:]):([A-Za-z_][A-Za-z0-9_]*)`)
+	sqlReadOnlyRegex = regexp.MustCompile(`(?i)^\s*(select|with)\s`)
+)
+
+// SQLClient is an HTTPClient that answers the requests to mappings
+// with the sql scheme by running the named query of the database
+// in a read-only transaction, with the request query parameters
+// bound to the query placeholders. The rows are returned as a
+// JSON array of objects. Every other request is executed by the
+// wrapped client.
+type SQLClient struct {
+	log       restql.Logger
+	client    domain.HTTPClient
+	lifecycle plugins.Lifecycle
+	databases map[string]sqlDatabase
+}
+
+type sqlDatabase struct {
+	db      *sql.DB
+	maxRows int
+	queries map[string]sqlQuery
+}
+
+type sqlQuery struct {
+	text   string
+	params []string
+}
+
+// WithSQL wraps the client with a SQLClient for the configured
+// databases, returning the client itself when there is none.
+func WithSQL(log restql.Logger, client domain.HTTPClient, lifecycle plugins.Lifecycle, databases map[string]conf.SQLDatabaseConf) (domain.HTTPClient, error) {
+	if len(databases) == 0 {
+		return client, nil
+	}
+
+	sc := &SQLClient{log: log, client: client, lifecycle: lifecycle, databases: make(map[string]sqlDatabase, len(databases))}
+	for name, dc := range databases {
+		database, err := newSQLDatabase(dc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid sql database %s", name)
+		}
+		sc.databases[name] = database
+		log.Info("sql database configured", "database", name, "driver", dc.Driver, "queries", len(database.queries))
+	}
+
+	return sc, nil
+}
+
+func newSQLDatabase(dc conf.SQLDatabaseConf) (sqlDatabase, error) {
+	queries := make(map[string]sqlQuery, len(dc.Queries))
+	for name, text := range dc.Queries {
+		q, err := parseSQLQuery(text, dc.Placeholder)
+		if err != nil {
+			return sqlDatabase{}, errors.Wrapf(err, "query %s", name)
+		}
+		queries[name] = q
+	}
+
+	dsn := dc.DSN
+	if dc.DSNEnv != "" {
+		dsn = os.Getenv(dc.DSNEnv)
+	}
+
+	db, err := sql.Open(dc.Driver, dsn)
+	if err != nil {
+		return sqlDatabase{}, err
+	}
+	if dc.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(dc.MaxOpenConns)
+	}
+
+	maxRows := dc.MaxRows
+	if maxRows <= 0 {
+		maxRows = defaultSQLMaxRows
+	}
+
+	return sqlDatabase{db: db, maxRows: maxRows, queries: queries}, nil
+}
+
+// parseSQLQuery replaces the :name parameters of the query by the
+// placeholders of the driver, keeping their order to bind them.
+// Only SELECT queries, optionally with common table expressions,
+// are accepted.
+func parseSQLQuery(text string, placeholder string) (sqlQuery, error) {
+	if !sqlReadOnlyRegex.MatchString(text) {
+		return sqlQuery{}, errors.New("only select queries are allowed")
+	}
+
+	switch placeholder {
+	case "", SQLQuestionPlaceholder, SQLDollarPlaceholder:
+	default:
+		return sqlQuery{}, errors.Errorf("unknown placeholder %q, must be one of ? or $", placeholder)
+	}
+
+	var params []string
+	parsed := sqlParamRegex.ReplaceAllStringFunc(text, func(m string) string {
+		sub := sqlParamRegex.FindStringSubmatch(m)
+		params = append(params, sub[2])
+		if placeholder == SQLDollarPlaceholder {
+			return sub[1] + "$" + strconv.Itoa(len(params))
+		}
+		return sub[1] + "?"
+	})
+
+	return sqlQuery{text: parsed, params: params}, nil
+}
+
+// Do runs the query of requests to mappings with the sql scheme,
+// calling the lifecycle request hooks like the HTTP client.
+func (sc *SQLClient) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	if request.Schema != SQLScheme {
+		return sc.client.Do(ctx, request)
+	}
+
+	requestCtx := sc.lifecycle.BeforeRequest(ctx, request)
+
+	start := time.Now()
+	response, err := sc.query(ctx, request)
+	response.Duration = time.Since(start)
+
+	sc.lifecycle.AfterRequest(requestCtx, request, response, err)
+
+	return response, err
+}
+
+func (sc *SQLClient) query(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	url := SQLScheme + "://" + request.Host + request.Path
+
+	if request.Method != http.MethodGet {
+		return sc.errorResponse(url, http.StatusMethodNotAllowed, "sql resources only support the from method"), nil
+	}
+
+	database, found := sc.databases[request.Host]
+	if !found {
+		return sc.errorResponse(url, http.StatusNotFound, fmt.Sprintf("unknown sql database %s", request.Host)), nil
+	}
+
+	q, found := database.queries[strings.Trim(request.Path, "/")]
+	if !found {
+		return sc.errorResponse(url, http.StatusNotFound, fmt.Sprintf("unknown sql query %s", request.Path)), nil
+	}
+
+	args := make([]interface{}, len(q.params))
+	for i, name := range q.params {
+		value, found := request.Query[name]
+		if !found {
+			return sc.errorResponse(url, http.StatusBadRequest, fmt.Sprintf("missing parameter %s", name)), nil
+		}
+		if _, isList := value.([]interface{}); isList {
+			return sc.errorResponse(url, http.StatusBadRequest, fmt.Sprintf("parameter %s must be a single value", name)), nil
+		}
+		args[i] = value
+	}
+
+	if request.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, request.Timeout)
+		defer cancel()
+	}
+
+	rows, err := database.queryRows(ctx, q, args)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		sc.log.Info("sql query timed out", "url", url)
+		return makeErrorResponse(url, 0, http.StatusRequestTimeout), domain.ErrRequestTimeout
+	case errors.Is(err, domain.ErrResponseTooLarge):
+		sc.log.Info("sql query result too large", "url", url, "maxRows", database.maxRows)
+		return makeErrorResponse(url, 0, http.StatusBadGateway), err
+	case err != nil:
+		return makeErrorResponse(url, 0, http.StatusInternalServerError), errors.Wrap(err, "sql query execution failed")
+	}
+
+	body, err := json.Marshal(rows)
+	if err != nil {
+		return makeErrorResponse(url, 0, http.StatusInternalServerError), err
+	}
+	if exceedsSize(body, request.MaxResponseSize) {
+		return makeErrorResponse(url, 0, http.StatusBadGateway), domain.ErrResponseTooLarge
+	}
+
+	return restql.HTTPResponse{
+		URL:        url,
+		StatusCode: http.StatusOK,
+		Body:       restql.NewResponseBodyFromBytes(sc.log, body),
+		Headers:    restql.Headers{"Content-Type": "application/json"},
+	}, nil
+}
+
+// queryRows runs the query in a read-only transaction, failing
+// when it returns more rows than the database allows.
+func (d sqlDatabase) queryRows(ctx context.Context, q sqlQuery, args []interface{}) ([]map[string]interface{}, error) {
+	tx, err := d.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, q.text, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		if len(result) == d.maxRows {
+			return nil, domain.ErrResponseTooLarge
+		}
+
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				row[column] = string(b)
+				continue
+			}
+			row[column] = values[i]
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+func (sc *SQLClient) errorResponse(url string, status int, message string) restql.HTTPResponse {
+	return restql.HTTPResponse{
+		URL:        url,
+		StatusCode: status,
+		Body:       restql.NewResponseBodyFromValue(sc.log, message),
+	}
+}
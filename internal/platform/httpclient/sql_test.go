@@ -0,0 +1,171 @@
+package httpclient
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+// stubDriver answers every query with the rows of its table,
+// recording the statements and the transactions options.
+type stubDriver struct {
+	mu       sync.Mutex
+	columns  []string
+	rows     [][]driver.Value
+	queries  []string
+	args     [][]driver.Value
+	readOnly []bool
+}
+
+func (d *stubDriver) Open(name string) (driver.Conn, error) { return stubConn{d}, nil }
+
+type stubConn struct{ d *stubDriver }
+
+func (c stubConn) Prepare(query string) (driver.Stmt, error) { return stubStmt{c.d, query}, nil }
+func (c stubConn) Close() error                              { return nil }
+func (c stubConn) Begin() (driver.Tx, error)                 { return stubTx{}, nil }
+
+func (c stubConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.readOnly = append(c.d.readOnly, opts.ReadOnly)
+	return stubTx{}, nil
+}
+
+type stubTx struct{}
+
+func (stubTx) Commit() error   { return nil }
+func (stubTx) Rollback() error { return nil }
+
+type stubStmt struct {
+	d     *stubDriver
+	query string
+}
+
+func (s stubStmt) Close() error  { return nil }
+func (s stubStmt) NumInput() int { return -1 }
+
+func (s stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec not supported")
+}
+
+func (s stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.queries = append(s.d.queries, s.query)
+	s.d.args = append(s.d.args, args)
+	return &stubRows{columns: s.d.columns, rows: s.d.rows}, nil
+}
+
+type stubRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *stubRows) Columns() []string { return r.columns }
+func (r *stubRows) Close() error      { return nil }
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if r.next == len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+var stubSQLDriver = &stubDriver{}
+
+func init() {
+	sql.Register("restql-stub", stubSQLDriver)
+}
+
+func TestParseSQLQuery(t *testing.T) {
+	q, err := parseSQLQuery("SELECT name FROM planets WHERE id = :id AND kind = :kind AND created::date > now()", SQLDollarPlaceholder)
+	test.VerifyError(t, err)
+	test.Equal(t, q.text, "SELECT name FROM planets WHERE id = $1 AND kind = $2 AND created::date > now()")
+	test.Equal(t, q.params, []string{"id", "kind"})
+
+	q, err = parseSQLQuery("with p as (select * from planets) select * from p where id = :id", "")
+	test.VerifyError(t, err)
+	test.Equal(t, q.text, "with p as (select * from planets) select * from p where id = ?")
+
+	_, err = parseSQLQuery("DELETE FROM planets WHERE id = :id", "")
+	if err == nil {
+		t.Fatalf("expected error for query that is not a select")
+	}
+
+	_, err = parseSQLQuery("SELECT 1", "@")
+	if err == nil {
+		t.Fatalf("expected error for unknown placeholder")
+	}
+}
+
+func TestSQLClient(t *testing.T) {
+	stubSQLDriver.columns = []string{"id", "name"}
+	stubSQLDriver.rows = [][]driver.Value{{int64(1), []byte("Tatooine")}, {int64(2), []byte("Alderaan")}}
+
+	databases := map[string]conf.SQLDatabaseConf{
+		"catalog": {
+			Driver:  "restql-stub",
+			MaxRows: 2,
+			Queries: map[string]string{"planets": "SELECT id, name FROM planets WHERE kind = :kind"},
+		},
+		"small": {
+			Driver:  "restql-stub",
+			MaxRows: 1,
+			Queries: map[string]string{"planets": "SELECT id, name FROM planets"},
+		},
+	}
+
+	fallback := &sequenceClient{}
+	client, err := WithSQL(test.NoOpLogger, fallback, plugins.NoOpLifecycle, databases)
+	test.VerifyError(t, err)
+
+	request := restql.HTTPRequest{Method: http.MethodGet, Schema: SQLScheme, Host: "catalog", Path: "/planets", Query: map[string]interface{}{"kind": "desert"}}
+	response, err := client.Do(context.Background(), request)
+	test.VerifyError(t, err)
+
+	test.Equal(t, response.StatusCode, http.StatusOK)
+	test.Equal(t, response.URL, "sql://catalog/planets")
+	expected := []interface{}{
+		map[string]interface{}{"id": float64(1), "name": "Tatooine"},
+		map[string]interface{}{"id": float64(2), "name": "Alderaan"},
+	}
+	test.Equal(t, response.Body.Unmarshal(), expected)
+	test.Equal(t, stubSQLDriver.args[len(stubSQLDriver.args)-1], []driver.Value{"desert"})
+	test.Equal(t, stubSQLDriver.readOnly[len(stubSQLDriver.readOnly)-1], true)
+
+	response, err = client.Do(context.Background(), restql.HTTPRequest{Method: http.MethodGet, Schema: SQLScheme, Host: "small", Path: "/planets"})
+	test.Equal(t, errors.Is(err, domain.ErrResponseTooLarge), true)
+	test.Equal(t, response.StatusCode, http.StatusBadGateway)
+
+	response, err = client.Do(context.Background(), restql.HTTPRequest{Method: http.MethodGet, Schema: SQLScheme, Host: "catalog", Path: "/planets"})
+	test.VerifyError(t, err)
+	test.Equal(t, response.StatusCode, http.StatusBadRequest)
+	test.Equal(t, response.Body.Unmarshal(), "missing parameter kind")
+
+	response, err = client.Do(context.Background(), restql.HTTPRequest{Method: http.MethodPost, Schema: SQLScheme, Host: "catalog", Path: "/planets"})
+	test.VerifyError(t, err)
+	test.Equal(t, response.StatusCode, http.StatusMethodNotAllowed)
+
+	response, err = client.Do(context.Background(), restql.HTTPRequest{Method: http.MethodGet, Schema: SQLScheme, Host: "catalog", Path: "/moons"})
+	test.VerifyError(t, err)
+	test.Equal(t, response.StatusCode, http.StatusNotFound)
+
+	_, err = client.Do(context.Background(), restql.HTTPRequest{Method: http.MethodGet, Schema: "http", Host: "hero.io"})
+	test.VerifyError(t, err)
+	test.Equal(t, fallback.calls, 1)
+}
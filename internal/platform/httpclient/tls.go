@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"expvar"
+	"sync"
+	"sync/atomic"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+)
+
+// tlsMetrics holds the TLS handshakes made to each upstream
+// by the HTTP client, exposed by the expvar handler.
+var tlsMetrics = expvar.NewMap("tls")
+
+// HandshakeStats represents the TLS handshakes made to an
+// upstream and how many of them resumed a previous session.
+type HandshakeStats struct {
+	Handshakes int64   `json:"handshakes"`
+	Resumed    int64   `json:"resumed"`
+	ReuseRate  float64 `json:"reuseRate"`
+}
+
+type handshakeCounters struct {
+	handshakes int64
+	resumed    int64
+}
+
+// handshakeStats counts the handshakes by server name.
+type handshakeStats struct {
+	hosts sync.Map
+}
+
+func (hs *handshakeStats) record(host string, resumed bool) {
+	c, ok := hs.hosts.Load(host)
+	if !ok {
+		c, _ = hs.hosts.LoadOrStore(host, &handshakeCounters{})
+	}
+
+	counters := c.(*handshakeCounters)
+	atomic.AddInt64(&counters.handshakes, 1)
+	if resumed {
+		atomic.AddInt64(&counters.resumed, 1)
+	}
+}
+
+// Stats returns a snapshot of the handshakes of each upstream.
+func (hs *handshakeStats) Stats() map[string]HandshakeStats {
+	result := make(map[string]HandshakeStats)
+	hs.hosts.Range(func(key, value interface{}) bool {
+		counters := value.(*handshakeCounters)
+		stats := HandshakeStats{
+			Handshakes: atomic.LoadInt64(&counters.handshakes),
+			Resumed:    atomic.LoadInt64(&counters.resumed),
+		}
+		if stats.Handshakes > 0 {
+			stats.ReuseRate = float64(stats.Resumed) / float64(stats.Handshakes)
+		}
+
+		result[key.(string)] = stats
+		return true
+	})
+	return result
+}
+
+// newTLSConfig returns the configuration of the connections made
+// with the settings, or the default ones when not given, counting
+// their handshakes and checking the revocation of the upstream
+// certificates on the ones not resumed.
+func newTLSConfig(settings *domain.UpstreamTLS, stats *handshakeStats, crls *crlCache) *tls.Config {
+	if settings == nil {
+		settings = &domain.UpstreamTLS{}
+	}
+
+	cfg := &tls.Config{
+		MinVersion:   settings.MinVersion,
+		CipherSuites: settings.CipherSuites,
+	}
+
+	if settings.DisableSessionTickets {
+		cfg.SessionTicketsDisabled = true
+	} else {
+		cfg.ClientSessionCache = tls.NewLRUClientSessionCache(settings.SessionCacheSize)
+	}
+
+	revocationCheck := settings.RevocationCheck
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		stats.record(cs.ServerName, cs.DidResume)
+		if cs.DidResume || revocationCheck == "" {
+			return nil
+		}
+
+		return checkRevocation(revocationCheck, cs, crls)
+	}
+
+	return cfg
+}
@@ -0,0 +1,173 @@
+package httpclient
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestTLSHandshakeReuse(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings *domain.UpstreamTLS
+		expected HandshakeStats
+	}{
+		{"should resume sessions by default", nil, HandshakeStats{Handshakes: 3, Resumed: 2, ReuseRate: 2.0 / 3}},
+		{"should not resume sessions with tickets disabled", &domain.UpstreamTLS{DisableSessionTickets: true}, HandshakeStats{Handshakes: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			defer server.Close()
+
+			stats := &handshakeStats{}
+			cfg := newTLSConfig(tt.settings, stats, nil)
+			cfg.RootCAs = x509.NewCertPool()
+			cfg.RootCAs.AddCert(server.Certificate())
+			cfg.ServerName = "example.com"
+
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg, DisableKeepAlives: true}}
+			for i := 0; i < 3; i++ {
+				res, err := client.Get(server.URL)
+				test.VerifyError(t, err)
+				res.Body.Close()
+			}
+
+			test.Equal(t, stats.Stats(), map[string]HandshakeStats{"example.com": tt.expected})
+		})
+	}
+}
+
+func TestVerifyOCSP(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	ca, caKey := newTestCertificate(t, nil, nil, func(c *x509.Certificate) { c.IsCA = true })
+	leaf, _ := newTestCertificate(t, ca, caKey, nil)
+	_, otherKey := newTestCertificate(t, nil, nil, func(c *x509.Certificate) { c.IsCA = true })
+
+	good := ocspSingleResponse{Good: true, ThisUpdate: now.Add(-time.Hour), NextUpdate: now.Add(time.Hour)}
+	revoked := ocspSingleResponse{Revoked: ocspRevokedInfo{RevocationTime: now.Add(-time.Hour)}, ThisUpdate: now.Add(-time.Hour)}
+	expired := ocspSingleResponse{Good: true, ThisUpdate: now.Add(-2 * time.Hour), NextUpdate: now.Add(-time.Hour)}
+
+	tests := []struct {
+		name     string
+		response []byte
+		expected string
+	}{
+		{"should accept good certificate", newTestOCSPResponse(t, leaf.SerialNumber, good, caKey), ""},
+		{"should reject revoked certificate", newTestOCSPResponse(t, leaf.SerialNumber, revoked, caKey), "upstream certificate revoked"},
+		{"should reject expired response", newTestOCSPResponse(t, leaf.SerialNumber, expired, caKey), "ocsp response out of its validity period"},
+		{"should reject response for another certificate", newTestOCSPResponse(t, big.NewInt(42), good, caKey), "ocsp response does not cover the upstream certificate"},
+		{"should reject response not signed by the issuer", newTestOCSPResponse(t, leaf.SerialNumber, good, otherKey), "invalid ocsp signature: x509: ECDSA verification failure"},
+		{"should reject malformed response", []byte("ocsp"), "malformed ocsp response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyOCSP(tt.response, leaf, ca, now)
+
+			if tt.expected == "" {
+				test.VerifyError(t, err)
+			} else {
+				test.Equal(t, err.Error(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestCRLCacheVerify(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	ca, caKey := newTestCertificate(t, nil, nil, func(c *x509.Certificate) { c.IsCA = true })
+	withCRL := func(c *x509.Certificate) { c.CRLDistributionPoints = []string{"http://ca.io/crl"} }
+	leaf, _ := newTestCertificate(t, ca, caKey, withCRL)
+	revokedLeaf, _ := newTestCertificate(t, ca, caKey, withCRL)
+
+	crl, err := ca.CreateCRL(rand.Reader, caKey, []pkix.RevokedCertificate{{SerialNumber: revokedLeaf.SerialNumber, RevocationTime: now}}, now, now.Add(time.Hour))
+	test.VerifyError(t, err)
+
+	fetches := 0
+	crls := &crlCache{lists: make(map[string]*pkix.CertificateList), fetch: func(url string) ([]byte, error) {
+		fetches++
+		return crl, nil
+	}}
+
+	test.VerifyError(t, crls.verify(leaf, ca, now))
+	test.Equal(t, crls.verify(revokedLeaf, ca, now) == errCertificateRevoked, true)
+	test.Equal(t, fetches, 1)
+}
+
+var testSerialNumber int64
+
+func newTestCertificate(t *testing.T, parent *x509.Certificate, parentKey crypto.Signer, customize func(*x509.Certificate)) (*x509.Certificate, crypto.Signer) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	test.VerifyError(t, err)
+
+	testSerialNumber++
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(testSerialNumber),
+		Subject:               pkix.Name{CommonName: "restql"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+	}
+	if customize != nil {
+		customize(template)
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	test.VerifyError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	test.VerifyError(t, err)
+
+	return cert, key
+}
+
+func newTestOCSPResponse(t *testing.T, serial *big.Int, single ocspSingleResponse, key crypto.Signer) []byte {
+	single.CertID = ocspCertID{
+		HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}},
+		NameHash:      []byte{0},
+		IssuerKeyHash: []byte{0},
+		SerialNumber:  serial,
+	}
+
+	tbs, err := asn1.Marshal(ocspResponseData{
+		RawResponderID: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 2, IsCompound: true, Bytes: []byte{0x04, 0x01, 0x00}},
+		ProducedAt:     time.Now().UTC().Truncate(time.Second),
+		Responses:      []ocspSingleResponse{single},
+	})
+	test.VerifyError(t, err)
+
+	digest := crypto.SHA256.New()
+	digest.Write(tbs)
+	signature, err := key.Sign(rand.Reader, digest.Sum(nil), crypto.SHA256)
+	test.VerifyError(t, err)
+
+	basic, err := asn1.Marshal(basicOCSPResponse{
+		TBSResponseData:    ocspResponseData{Raw: tbs},
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}},
+		Signature:          asn1.BitString{Bytes: signature, BitLength: 8 * len(signature)},
+	})
+	test.VerifyError(t, err)
+
+	response, err := asn1.Marshal(ocspResponse{Response: ocspResponseBytes{ResponseType: oidOCSPBasicResponse, Response: basic}})
+	test.VerifyError(t, err)
+
+	return response
+}
@@ -0,0 +1,167 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/valyala/fasthttp"
+)
+
+// doTraced executes the request over a dedicated connection, closed once
+// the response is read, so the DNS lookup, connection, TLS handshake and
+// first byte timings of the call can be measured. Since pooled connections
+// are shared among requests, their timings could not be told apart.
+func (hc *fastHTTPClient) doTraced(req *fasthttp.Request, res *fasthttp.Response, request restql.HTTPRequest) (*restql.HTTPTimings, error) {
+	isTLS := request.Schema == "https"
+	tracer := &connTracer{resolver: hc.resolver, isTLS: isTLS}
+
+	c := &fasthttp.HostClient{
+		Addr:                          addMissingPort(string(req.URI().Host()), isTLS),
+		IsTLS:                         isTLS,
+		Name:                          hc.client.Name,
+		DisableHeaderNamesNormalizing: hc.client.DisableHeaderNamesNormalizing,
+		MaxResponseBodySize:           hc.client.MaxResponseBodySize,
+		Dial:                          tracer.dial,
+	}
+
+	req.SetConnectionClose()
+	err := c.DoTimeout(req, res, request.Timeout)
+
+	return tracer.timings(), err
+}
+
+// connTracer opens connections recording the duration of each phase.
+// It is synchronized since a timed out request keeps running in the
+// background while its timings are read.
+type connTracer struct {
+	resolver *dnsResolver
+	isTLS    bool
+
+	mu        sync.Mutex
+	result    restql.HTTPTimings
+	ready     bool
+	writeAt   time.Time
+	firstByte bool
+}
+
+func (t *connTracer) dial(addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fasthttp.DefaultDialTimeout)
+	defer cancel()
+
+	start := time.Now()
+	addrs, err := t.resolver.LookupHost(ctx, host)
+	t.record(func(r *restql.HTTPTimings) { r.DNS = time.Since(start) })
+	if err != nil {
+		return nil, err
+	}
+
+	start = time.Now()
+	raw, err := t.resolver.connect(addrs, port)
+	t.record(func(r *restql.HTTPTimings) { r.Connect = time.Since(start) })
+	if err != nil {
+		return nil, err
+	}
+
+	var conn net.Conn = &tracedConn{Conn: raw, tracer: t}
+
+	// The handshake is done here, instead of by the client, which
+	// uses the returned TLS connection as is, so it is measured
+	// apart from the first byte of the response.
+	if t.isTLS {
+		start = time.Now()
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+		_ = tlsConn.SetDeadline(start.Add(fasthttp.DefaultDialTimeout))
+		err = tlsConn.Handshake()
+		t.record(func(r *restql.HTTPTimings) { r.TLS = time.Since(start) })
+		if err != nil {
+			raw.Close()
+			return nil, err
+		}
+		_ = tlsConn.SetDeadline(time.Time{})
+
+		conn = tlsConn
+	}
+
+	t.markReady()
+
+	return conn, nil
+}
+
+func (t *connTracer) record(fn func(r *restql.HTTPTimings)) {
+	t.mu.Lock()
+	fn(&t.result)
+	t.mu.Unlock()
+}
+
+func (t *connTracer) markReady() {
+	t.mu.Lock()
+	t.ready = true
+	t.mu.Unlock()
+}
+
+func (t *connTracer) wrote() {
+	t.mu.Lock()
+	if t.ready && t.writeAt.IsZero() {
+		t.writeAt = time.Now()
+	}
+	t.mu.Unlock()
+}
+
+func (t *connTracer) read() {
+	t.mu.Lock()
+	if t.ready && !t.firstByte && !t.writeAt.IsZero() {
+		t.firstByte = true
+		t.result.FirstByte = time.Since(t.writeAt)
+	}
+	t.mu.Unlock()
+}
+
+func (t *connTracer) timings() *restql.HTTPTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := t.result
+	return &result
+}
+
+// tracedConn notifies the tracer of the bytes written and read,
+// which are only considered once the connection is ready.
+type tracedConn struct {
+	net.Conn
+	tracer *connTracer
+}
+
+func (c *tracedConn) Write(b []byte) (int, error) {
+	c.tracer.wrote()
+	return c.Conn.Write(b)
+}
+
+func (c *tracedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.tracer.read()
+	}
+	return n, err
+}
+
+func addMissingPort(addr string, isTLS bool) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+
+	port := "80"
+	if isTLS {
+		port = "443"
+	}
+
+	return net.JoinHostPort(addr, port)
+}
@@ -0,0 +1,51 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestTracedRequest(t *testing.T) {
+	var connection string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connection = r.Header.Get("Connection")
+		time.Sleep(20 * time.Millisecond)
+		io.WriteString(w, `{"id": "1"}`)
+	}))
+	defer server.Close()
+
+	client := newFastHTTPClient(test.NoOpLogger, plugins.NoOpLifecycle, &conf.Config{})
+
+	request := restql.HTTPRequest{
+		Method:  http.MethodGet,
+		Schema:  "http",
+		Host:    strings.TrimPrefix(server.URL, "http://"),
+		Timeout: time.Second,
+	}
+
+	response, err := client.Do(context.Background(), request)
+	test.VerifyError(t, err)
+	test.Equal(t, response.Timings, (*restql.HTTPTimings)(nil))
+
+	request.Trace = true
+	response, err = client.Do(context.Background(), request)
+	test.VerifyError(t, err)
+
+	test.Equal(t, response.StatusCode, http.StatusOK)
+	test.Equal(t, response.Body.Unmarshal(), map[string]interface{}{"id": "1"})
+	test.Equal(t, connection, "close")
+	test.Equal(t, response.Timings.Connect > 0, true)
+	test.Equal(t, response.Timings.TLS, time.Duration(0))
+	test.Equal(t, response.Timings.FirstByte >= 20*time.Millisecond, true)
+	test.Equal(t, response.Timings.FirstByte <= response.Duration, true)
+}
@@ -0,0 +1,145 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// UnixScheme is the scheme of the mappings targeting upstreams listening
+// on a Unix domain socket, like sidecars, whose path is given URL encoded
+// as the host, as in "http+unix://%2Fvar%2Frun%2Fcatalog.sock/products/:id".
+const UnixScheme = "http+unix"
+
+// unixSocketHost is the host sent on the
+// requests to Unix domain socket upstreams.
+const unixSocketHost = "localhost"
+
+// UnixSocketPath returns the path of the socket
+// of a Unix domain socket upstream host.
+func UnixSocketPath(host string) (string, error) {
+	path, err := url.PathUnescape(host)
+	if err != nil || path == "" {
+		return "", errors.Errorf("invalid unix socket %s", host)
+	}
+	return path, nil
+}
+
+// UnixDial returns a dial function connecting to the
+// socket at the path, whatever the address requested.
+func UnixDial(path string) fasthttp.DialFunc {
+	return func(addr string) (net.Conn, error) {
+		return net.DialTimeout("unix", path, fasthttp.DefaultDialTimeout)
+	}
+}
+
+// UpstreamAddr returns the scheme and host the request is sent to.
+// Unix domain socket upstreams are requested over plain HTTP, and
+// bracketed IPv6 literals get the default port of the scheme, as
+// the fasthttp client only adds it to hosts without colons.
+func UpstreamAddr(schema string, host string) (string, string) {
+	if schema == UnixScheme {
+		return "http", unixSocketHost
+	}
+
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		port := "80"
+		if schema == "https" {
+			port = "443"
+		}
+		return schema, host + ":" + port
+	}
+
+	return schema, host
+}
+
+// upstreamKey identifies the clients of the upstreams
+// reached through a proxy or with their own TLS settings.
+type upstreamKey struct {
+	proxy domain.OutboundProxy
+	tls   string
+}
+
+// upstreamClient returns the client whose dialer reaches the upstream
+// of the request: the socket of Unix domain socket mappings, the proxy
+// of the statement or, by default, TCP with the cached DNS resolution,
+// handshaking with the TLS settings of the statement, when it has them.
+// The default client is the only one whose requests can be traced.
+func (hc *fastHTTPClient) upstreamClient(ctx context.Context, request restql.HTTPRequest) (client *fasthttp.Client, traceable bool, err error) {
+	if request.Schema == UnixScheme {
+		path, err := UnixSocketPath(request.Host)
+		if err != nil {
+			return nil, false, err
+		}
+		return hc.dialClient(path, UnixDial(path), nil), false, nil
+	}
+
+	var key upstreamKey
+	dial := hc.resolver.Dial
+	if proxy, ok := domain.GetOutboundProxy(ctx); ok {
+		key.proxy = *proxy
+		dial = proxyDial(hc.resolver, *proxy)
+	}
+
+	settings, ok := domain.GetUpstreamTLS(ctx)
+	if ok && request.Schema == "https" {
+		key.tls = settings.String()
+	} else {
+		settings = nil
+	}
+
+	if key == (upstreamKey{}) {
+		return hc.client, true, nil
+	}
+	return hc.dialClient(key, dial, settings), false, nil
+}
+
+// dialClient returns the client opening its connections with the dial
+// function, and the TLS settings when given, created on the first
+// request routed through it, so each socket, proxy and TLS settings
+// keeps its own pool of connections and TLS sessions.
+func (hc *fastHTTPClient) dialClient(key interface{}, dial fasthttp.DialFunc, settings *domain.UpstreamTLS) *fasthttp.Client {
+	if c, ok := hc.dialClients.Load(key); ok {
+		return c.(*fasthttp.Client)
+	}
+
+	tlsConfig := hc.client.TLSConfig
+	if settings != nil {
+		tlsConfig = newTLSConfig(settings, hc.handshakes, hc.crls)
+	}
+
+	c := &fasthttp.Client{
+		Name:                          hc.client.Name,
+		NoDefaultUserAgentHeader:      hc.client.NoDefaultUserAgentHeader,
+		DisableHeaderNamesNormalizing: hc.client.DisableHeaderNamesNormalizing,
+		Dial:                          dial,
+		MaxConnsPerHost:               hc.client.MaxConnsPerHost,
+		MaxIdleConnDuration:           hc.client.MaxIdleConnDuration,
+		MaxConnWaitTimeout:            hc.client.MaxConnWaitTimeout,
+		MaxResponseBodySize:           hc.client.MaxResponseBodySize,
+		TLSConfig:                     tlsConfig,
+	}
+
+	actual, _ := hc.dialClients.LoadOrStore(key, c)
+	return actual.(*fasthttp.Client)
+}
+
+// unixHTTPClient returns a standard library client connecting to the
+// socket at the path. Connections are not reused, as the client only
+// serves long lived streams.
+func unixHTTPClient(path string) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		DisableKeepAlives: true,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+}
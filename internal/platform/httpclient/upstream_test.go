@@ -0,0 +1,96 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestUpstreamAddr(t *testing.T) {
+	tests := []struct {
+		name           string
+		schema         string
+		host           string
+		expectedSchema string
+		expectedHost   string
+	}{
+		{"should keep host names", "http", "hero.io", "http", "hero.io"},
+		{"should keep ipv6 addresses with port", "http", "[::1]:8080", "http", "[::1]:8080"},
+		{"should add http port to ipv6 addresses", "http", "[::1]", "http", "[::1]:80"},
+		{"should add https port to ipv6 addresses", "https", "[2001:db8::1]", "https", "[2001:db8::1]:443"},
+		{"should request unix sockets over http", UnixScheme, "%2Fvar%2Frun%2Fhero.sock", "http", "localhost"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, host := UpstreamAddr(tt.schema, tt.host)
+
+			test.Equal(t, schema, tt.expectedSchema)
+			test.Equal(t, host, tt.expectedHost)
+		})
+	}
+}
+
+func TestUpstreamRequest(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"path": "`+r.URL.Path+`"}`)
+	})
+
+	dir, err := ioutil.TempDir("", "restql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "hero.sock")
+	unixListener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unixListener.Close()
+	go http.Serve(unixListener, handler)
+
+	ipv6Listener, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skip("ipv6 is not available")
+	}
+	defer ipv6Listener.Close()
+	go http.Serve(ipv6Listener, handler)
+
+	tests := []struct {
+		name   string
+		schema string
+		host   string
+	}{
+		{"should request upstreams over unix sockets", UnixScheme, url.PathEscape(socket)},
+		{"should request upstreams over ipv6", "http", ipv6Listener.Addr().String()},
+	}
+
+	client := newFastHTTPClient(test.NoOpLogger, plugins.NoOpLifecycle, &conf.Config{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := restql.HTTPRequest{Method: http.MethodGet, Schema: tt.schema, Host: tt.host, Path: "/heroes/1", Timeout: time.Second}
+
+			response, err := client.Do(context.Background(), request)
+			test.VerifyError(t, err)
+
+			test.Equal(t, response.StatusCode, http.StatusOK)
+			test.Equal(t, response.Body.Unmarshal(), map[string]interface{}{"path": "/heroes/1"})
+			test.Equal(t, strings.HasPrefix(response.URL, tt.schema+"://"+tt.host), true)
+		})
+	}
+}
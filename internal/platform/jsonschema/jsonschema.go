@@ -0,0 +1,533 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema supporting the validation
+// keywords of draft-07 for types, enumerations, objects, arrays,
+// strings, numbers, combinations and local references to
+// definitions. Unknown keywords, like format, are ignored.
+type Schema struct {
+	root *Schema
+
+	always *bool
+	ref    string
+
+	types    []string
+	enum     []interface{}
+	constant []interface{}
+
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+	minProperties        *int
+	maxProperties        *int
+
+	items    *Schema
+	minItems *int
+	maxItems *int
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+
+	allOf []*Schema
+	anyOf []*Schema
+	oneOf []*Schema
+	not   *Schema
+
+	definitions map[string]*Schema
+}
+
+// maxSchemaDepth bounds the references followed while
+// validating, failing on schemas referencing themselves
+// without consuming the value.
+const maxSchemaDepth = 64
+
+// Compile compiles the schema from its
+// generic JSON form, failing on invalid keywords.
+func Compile(raw interface{}) (*Schema, error) {
+	s, err := compileSchema(raw, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkRefs(s, 0); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func compileSchema(raw interface{}, root *Schema) (*Schema, error) {
+	s := &Schema{root: root}
+	if root == nil {
+		s.root = s
+	}
+
+	switch raw := raw.(type) {
+	case bool:
+		s.always = &raw
+		return s, nil
+	case map[string]interface{}:
+		return s, s.compileKeywords(raw)
+	default:
+		return nil, fmt.Errorf("schema must be an object or a boolean")
+	}
+}
+
+func (s *Schema) compileKeywords(raw map[string]interface{}) error {
+	var err error
+
+	if ref, ok := raw["$ref"]; ok {
+		str, ok := ref.(string)
+		if !ok || !(strings.HasPrefix(str, "#/definitions/") || strings.HasPrefix(str, "#/$defs/")) {
+			return fmt.Errorf("$ref must be a local reference to a definition")
+		}
+		s.ref = str
+	}
+
+	switch t := raw["type"].(type) {
+	case nil:
+	case string:
+		s.types = []string{t}
+	case []interface{}:
+		for _, v := range t {
+			str, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("type must be a string or a list of strings")
+			}
+			s.types = append(s.types, str)
+		}
+	default:
+		return fmt.Errorf("type must be a string or a list of strings")
+	}
+
+	if enum, ok := raw["enum"]; ok {
+		list, ok := enum.([]interface{})
+		if !ok {
+			return fmt.Errorf("enum must be a list")
+		}
+		s.enum = list
+	}
+
+	if c, ok := raw["const"]; ok {
+		s.constant = []interface{}{c}
+	}
+
+	if props, ok := raw["properties"]; ok {
+		if s.properties, err = s.compileMap(props, "properties"); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range []string{"definitions", "$defs"} {
+		defs, ok := raw[key]
+		if !ok {
+			continue
+		}
+
+		compiled, err := s.compileMap(defs, key)
+		if err != nil {
+			return err
+		}
+		if s.definitions == nil {
+			s.definitions = make(map[string]*Schema)
+		}
+		for name, d := range compiled {
+			s.definitions[key+"/"+name] = d
+		}
+	}
+
+	if req, ok := raw["required"]; ok {
+		list, ok := req.([]interface{})
+		if !ok {
+			return fmt.Errorf("required must be a list of strings")
+		}
+		for _, v := range list {
+			str, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("required must be a list of strings")
+			}
+			s.required = append(s.required, str)
+		}
+	}
+
+	subschemas := map[string]**Schema{
+		"additionalProperties": &s.additionalProperties,
+		"items":                &s.items,
+		"not":                  &s.not,
+	}
+	for key, target := range subschemas {
+		v, ok := raw[key]
+		if !ok {
+			continue
+		}
+		if *target, err = compileSchema(v, s.root); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	}
+
+	combinations := map[string]*[]*Schema{
+		"allOf": &s.allOf,
+		"anyOf": &s.anyOf,
+		"oneOf": &s.oneOf,
+	}
+	for key, target := range combinations {
+		v, ok := raw[key]
+		if !ok {
+			continue
+		}
+		list, ok := v.([]interface{})
+		if !ok || len(list) == 0 {
+			return fmt.Errorf("%s must be a non empty list of schemas", key)
+		}
+		for i, item := range list {
+			compiled, err := compileSchema(item, s.root)
+			if err != nil {
+				return fmt.Errorf("%s/%d: %v", key, i, err)
+			}
+			*target = append(*target, compiled)
+		}
+	}
+
+	counts := map[string]**int{
+		"minProperties": &s.minProperties,
+		"maxProperties": &s.maxProperties,
+		"minItems":      &s.minItems,
+		"maxItems":      &s.maxItems,
+		"minLength":     &s.minLength,
+		"maxLength":     &s.maxLength,
+	}
+	for key, target := range counts {
+		v, ok := raw[key]
+		if !ok {
+			continue
+		}
+		n, ok := v.(float64)
+		if !ok || n < 0 || n != math.Trunc(n) {
+			return fmt.Errorf("%s must be a non negative integer", key)
+		}
+		i := int(n)
+		*target = &i
+	}
+
+	limits := map[string]**float64{
+		"minimum":          &s.minimum,
+		"maximum":          &s.maximum,
+		"exclusiveMinimum": &s.exclusiveMinimum,
+		"exclusiveMaximum": &s.exclusiveMaximum,
+	}
+	for key, target := range limits {
+		v, ok := raw[key]
+		if !ok {
+			continue
+		}
+		n, ok := v.(float64)
+		if !ok {
+			return fmt.Errorf("%s must be a number", key)
+		}
+		*target = &n
+	}
+
+	if p, ok := raw["pattern"]; ok {
+		str, ok := p.(string)
+		if !ok {
+			return fmt.Errorf("pattern must be a string")
+		}
+		if s.pattern, err = regexp.Compile(str); err != nil {
+			return fmt.Errorf("pattern: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) compileMap(raw interface{}, keyword string) (map[string]*Schema, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an object", keyword)
+	}
+
+	result := make(map[string]*Schema, len(m))
+	for name, v := range m {
+		compiled, err := compileSchema(v, s.root)
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %v", keyword, name, err)
+		}
+		result[name] = compiled
+	}
+
+	return result, nil
+}
+
+// checkRefs verifies that every reference
+// points to a definition of the root schema.
+func (s *Schema) checkRefs(root *Schema, depth int) error {
+	if s == nil || depth > maxSchemaDepth {
+		return nil
+	}
+
+	if s.ref != "" {
+		if _, found := root.definitions[strings.TrimPrefix(s.ref, "#/")]; !found {
+			return fmt.Errorf("unknown reference %s", s.ref)
+		}
+	}
+
+	children := []*Schema{s.additionalProperties, s.items, s.not}
+	for _, p := range s.properties {
+		children = append(children, p)
+	}
+	for _, d := range s.definitions {
+		children = append(children, d)
+	}
+	children = append(children, s.allOf...)
+	children = append(children, s.anyOf...)
+	children = append(children, s.oneOf...)
+
+	for _, c := range children {
+		if err := c.checkRefs(root, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate returns the violations of the schema by the value, in
+// its generic JSON form, each prefixed by the JSON pointer to
+// the offending location. It returns nil for valid values.
+func (s *Schema) Validate(value interface{}) []string {
+	var violations []string
+	s.validate(value, "", 0, &violations)
+	sort.Strings(violations)
+	return violations
+}
+
+func (s *Schema) validate(value interface{}, path string, depth int, violations *[]string) {
+	report := func(format string, args ...interface{}) {
+		location := path
+		if location == "" {
+			location = "/"
+		}
+		*violations = append(*violations, location+": "+fmt.Sprintf(format, args...))
+	}
+
+	if depth > maxSchemaDepth {
+		report("schema nested too deeply")
+		return
+	}
+
+	if s.always != nil {
+		if !*s.always {
+			report("no value is allowed")
+		}
+		return
+	}
+
+	if s.ref != "" {
+		s.root.definitions[strings.TrimPrefix(s.ref, "#/")].validate(value, path, depth+1, violations)
+	}
+
+	if len(s.types) > 0 && !matchesAnyType(value, s.types) {
+		report("expected %s, got %s", strings.Join(s.types, " or "), jsonType(value))
+		return
+	}
+
+	if s.enum != nil && !containsJSON(s.enum, value) {
+		report("value is not one of the allowed ones")
+	}
+
+	if s.constant != nil && !equalJSON(s.constant[0], value) {
+		report("value is not the allowed one")
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		s.validateObject(v, path, depth, violations, report)
+	case []interface{}:
+		s.validateArray(v, path, depth, violations, report)
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.minLength != nil && length < *s.minLength {
+			report("expected at least %d characters, got %d", *s.minLength, length)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			report("expected at most %d characters, got %d", *s.maxLength, length)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			report("does not match pattern %s", s.pattern.String())
+		}
+	case float64:
+		s.validateNumber(v, report)
+	case json.Number:
+		if n, err := v.Float64(); err == nil {
+			s.validateNumber(n, report)
+		}
+	}
+
+	for _, sub := range s.allOf {
+		sub.validate(value, path, depth+1, violations)
+	}
+
+	if len(s.anyOf) > 0 {
+		matched := false
+		for _, sub := range s.anyOf {
+			if sub.isValid(value, depth) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			report("does not match any of the anyOf schemas")
+		}
+	}
+
+	if len(s.oneOf) > 0 {
+		matched := 0
+		for _, sub := range s.oneOf {
+			if sub.isValid(value, depth) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			report("expected to match exactly one of the oneOf schemas, matched %d", matched)
+		}
+	}
+
+	if s.not != nil && s.not.isValid(value, depth) {
+		report("matches the not schema")
+	}
+}
+
+func (s *Schema) isValid(value interface{}, depth int) bool {
+	var violations []string
+	s.validate(value, "", depth+1, &violations)
+	return len(violations) == 0
+}
+
+func (s *Schema) validateObject(obj map[string]interface{}, path string, depth int, violations *[]string, report func(string, ...interface{})) {
+	for _, key := range s.required {
+		if _, found := obj[key]; !found {
+			report("missing required property %s", key)
+		}
+	}
+
+	if s.minProperties != nil && len(obj) < *s.minProperties {
+		report("expected at least %d properties, got %d", *s.minProperties, len(obj))
+	}
+	if s.maxProperties != nil && len(obj) > *s.maxProperties {
+		report("expected at most %d properties, got %d", *s.maxProperties, len(obj))
+	}
+
+	for key, v := range obj {
+		childPath := path + "/" + escapePointer(key)
+		if p, found := s.properties[key]; found {
+			p.validate(v, childPath, depth+1, violations)
+			continue
+		}
+		if s.additionalProperties != nil {
+			s.additionalProperties.validate(v, childPath, depth+1, violations)
+		}
+	}
+}
+
+func (s *Schema) validateArray(list []interface{}, path string, depth int, violations *[]string, report func(string, ...interface{})) {
+	if s.minItems != nil && len(list) < *s.minItems {
+		report("expected at least %d items, got %d", *s.minItems, len(list))
+	}
+	if s.maxItems != nil && len(list) > *s.maxItems {
+		report("expected at most %d items, got %d", *s.maxItems, len(list))
+	}
+
+	if s.items != nil {
+		for i, item := range list {
+			s.items.validate(item, path+"/"+strconv.Itoa(i), depth+1, violations)
+		}
+	}
+}
+
+func (s *Schema) validateNumber(n float64, report func(string, ...interface{})) {
+	if s.minimum != nil && n < *s.minimum {
+		report("expected at least %v, got %v", *s.minimum, n)
+	}
+	if s.maximum != nil && n > *s.maximum {
+		report("expected at most %v, got %v", *s.maximum, n)
+	}
+	if s.exclusiveMinimum != nil && n <= *s.exclusiveMinimum {
+		report("expected more than %v, got %v", *s.exclusiveMinimum, n)
+	}
+	if s.exclusiveMaximum != nil && n >= *s.exclusiveMaximum {
+		report("expected less than %v, got %v", *s.exclusiveMaximum, n)
+	}
+}
+
+func matchesAnyType(value interface{}, types []string) bool {
+	actual := jsonType(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func containsJSON(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if equalJSON(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalJSON(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// escapePointer escapes a property name as
+// a reference token of a JSON pointer.
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
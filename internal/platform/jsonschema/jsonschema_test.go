@@ -0,0 +1,86 @@
+package jsonschema_test
+
+import (
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/jsonschema"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+const heroSchema = `{
+	"type": "object",
+	"required": ["id", "name"],
+	"properties": {
+		"id": {"type": "integer", "minimum": 1},
+		"name": {"type": "string", "minLength": 1},
+		"status": {"enum": ["active", "retired"]},
+		"powers": {"type": "array", "maxItems": 2, "items": {"$ref": "#/definitions/power"}}
+	},
+	"additionalProperties": false,
+	"definitions": {
+		"power": {"type": "string", "pattern": "^[a-z]+$"}
+	}
+}`
+
+func compile(t *testing.T, schema string) *jsonschema.Schema {
+	s, err := jsonschema.Compile(test.Unmarshal(schema))
+	if err != nil {
+		t.Fatalf("failed to compile schema: %v", err)
+	}
+	return s
+}
+
+func TestValidate(t *testing.T) {
+	schema := compile(t, heroSchema)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected []string
+	}{
+		{"valid", `{"id": 1, "name": "batman", "status": "active", "powers": ["money"]}`, nil},
+		{"wrong type", `[]`, []string{"/: expected object, got array"}},
+		{"missing required", `{"id": 1}`, []string{"/: missing required property name"}},
+		{"integer", `{"id": 1.5, "name": "batman"}`, []string{"/id: expected integer, got number"}},
+		{"minimum", `{"id": 0, "name": "batman"}`, []string{"/id: expected at least 1, got 0"}},
+		{"enum", `{"id": 1, "name": "batman", "status": "missing"}`, []string{"/status: value is not one of the allowed ones"}},
+		{"additional", `{"id": 1, "name": "batman", "city": "gotham"}`, []string{"/city: no value is allowed"}},
+		{
+			"items",
+			`{"id": 1, "name": "", "powers": ["money", "Tech", "x"]}`,
+			[]string{"/name: expected at least 1 characters, got 0", "/powers/1: does not match pattern ^[a-z]+$", "/powers: expected at most 2 items, got 3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.Equal(t, schema.Validate(test.Unmarshal(tt.value)), tt.expected)
+		})
+	}
+}
+
+func TestValidateCombinations(t *testing.T) {
+	schema := compile(t, `{"oneOf": [{"type": "string"}, {"type": "integer"}], "not": {"const": 0}}`)
+
+	test.Equal(t, len(schema.Validate("batman")), 0)
+	test.Equal(t, len(schema.Validate(float64(1))), 0)
+	test.Equal(t, schema.Validate(float64(0)), []string{"/: matches the not schema"})
+	test.Equal(t, schema.Validate(true), []string{"/: expected to match exactly one of the oneOf schemas, matched 0"})
+}
+
+func TestCompileErrors(t *testing.T) {
+	schemas := []string{
+		`{"type": 1}`,
+		`{"$ref": "#/definitions/missing"}`,
+		`{"$ref": "http://example.com/schema.json"}`,
+		`{"pattern": "["}`,
+		`{"minItems": -1}`,
+		`{"anyOf": []}`,
+	}
+
+	for _, schema := range schemas {
+		if _, err := jsonschema.Compile(test.Unmarshal(schema)); err == nil {
+			t.Errorf("expected error compiling %s", schema)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"context"
+	"io"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/rs/zerolog"
+)
+
+// NewAccessLog returns an event handler that writes a JSON line
+// for every statement execution, with its resource, method, URL,
+// status, duration in milliseconds and response cache outcome,
+// regardless of the application log level.
+func NewAccessLog(w io.Writer) restql.EventHandler {
+	log := zerolog.New(w).With().Timestamp().Logger()
+
+	return func(ctx context.Context, event restql.Event) {
+		finished, ok := event.(restql.StatementFinishedEvent)
+		if !ok {
+			return
+		}
+
+		entry := log.Log().
+			Str("resource", finished.Resource).
+			Str("method", finished.Method).
+			Str("url", finished.URL).
+			Int("status", finished.StatusCode).
+			Bool("success", finished.Success).
+			Float64("duration", float64(finished.Duration.Microseconds())/1000)
+
+		if finished.Cache != "" {
+			entry = entry.Str("cache", finished.Cache)
+		}
+		if requestID, ok := restql.RequestID(ctx); ok {
+			entry = entry.Str("request-id", requestID)
+		}
+		if traceID, ok := restql.TraceID(ctx); ok {
+			entry = entry.Str("trace-id", traceID)
+		}
+
+		entry.Msg("statement executed")
+	}
+}
@@ -0,0 +1,48 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/logger"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	handler := logger.NewAccessLog(&buf)
+
+	ctx := restql.WithRequestID(context.Background(), "abc-123")
+	handler(ctx, restql.StatementStartedEvent{Resource: "hero"})
+	handler(ctx, restql.StatementFinishedEvent{
+		Resource:   "hero",
+		Method:     "from",
+		URL:        "http://hero.api/heroes",
+		StatusCode: 200,
+		Success:    true,
+		Duration:   1500 * time.Microsecond,
+		Cache:      restql.ResponseCacheHit,
+	})
+
+	var entry map[string]interface{}
+	err := json.Unmarshal(buf.Bytes(), &entry)
+	test.VerifyError(t, err)
+	delete(entry, "time")
+
+	expected := map[string]interface{}{
+		"message":    "statement executed",
+		"resource":   "hero",
+		"method":     "from",
+		"url":        "http://hero.api/heroes",
+		"status":     float64(200),
+		"success":    true,
+		"duration":   1.5,
+		"cache":      "hit",
+		"request-id": "abc-123",
+	}
+	test.Equal(t, entry, expected)
+}
@@ -1,11 +1,11 @@
 package logger
 
 import (
-	"fmt"
 	"io"
 	"io/ioutil"
 
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql/logadapter"
 	"github.com/rs/zerolog"
 )
 
@@ -18,13 +18,12 @@ type LogOptions struct {
 	TimestampFieldFormat string
 	Level                string
 	Format               string
+	// Sampling maps a level name to the rate of its entries
+	// that are output, where N means one in every N entries.
+	Sampling map[string]uint64
 }
 
-type zeroLogger struct {
-	zLogger zerolog.Logger
-}
-
-// New constructs a zeroLogger instance.
+// New constructs a restql.Logger backed by zerolog.
 func New(w io.Writer, options LogOptions) restql.Logger {
 	output := w
 	if options.Format == "pretty" {
@@ -48,61 +47,29 @@ func New(w io.Writer, options LogOptions) restql.Logger {
 	}
 
 	if !options.Enable {
-		logger.Level(zerolog.Disabled)
+		logger = logger.Level(zerolog.Disabled)
 	}
 
-	return &zeroLogger{zLogger: logger}
-}
-
-func (zl *zeroLogger) Panic(msg string, fields ...interface{}) {
-	entry := zl.zLogger.Panic()
-	fieldMap := makeFieldMap(fields)
-
-	entry.Fields(fieldMap).Msg(msg)
-}
-
-func (zl *zeroLogger) Fatal(msg string, fields ...interface{}) {
-	fieldMap := makeFieldMap(fields)
-
-	zl.zLogger.Fatal().Fields(fieldMap).Msg(msg)
-}
-
-func (zl *zeroLogger) Error(msg string, err error, fields ...interface{}) {
-	fieldMap := makeFieldMap(fields)
-
-	zl.zLogger.Error().Err(err).Fields(fieldMap).Msg(msg)
-}
-
-func (zl *zeroLogger) Warn(msg string, fields ...interface{}) {
-	fieldMap := makeFieldMap(fields)
-
-	zl.zLogger.Warn().Fields(fieldMap).Msg(msg)
-}
-
-func (zl *zeroLogger) Info(msg string, fields ...interface{}) {
-	fieldMap := makeFieldMap(fields)
+	log := logadapter.Zerolog(logger)
 
-	zl.zLogger.Info().Fields(fieldMap).Msg(msg)
-}
-
-func (zl *zeroLogger) Debug(msg string, fields ...interface{}) {
-	fieldMap := makeFieldMap(fields)
-
-	zl.zLogger.Debug().Fields(fieldMap).Msg(msg)
-}
+	sampling := parseSampling(options.Sampling)
+	if len(sampling) > 0 {
+		log = restql.NewSampledLogger(log, sampling)
+	}
 
-func (zl *zeroLogger) With(key string, value interface{}) restql.Logger {
-	cl := zl.zLogger.With().Str(key, fmt.Sprintf("%v", value)).Logger()
-	return &zeroLogger{zLogger: cl}
+	return log
 }
 
-func makeFieldMap(fields []interface{}) map[string]interface{} {
-	fieldMap := make(map[string]interface{})
-	for i := 0; i <= len(fields)-2; i += 2 {
-		key := fmt.Sprintf("%v", fields[i])
-		value := fields[i+1]
+func parseSampling(sampling map[string]uint64) map[restql.Level]uint64 {
+	result := make(map[restql.Level]uint64)
+	for name, every := range sampling {
+		level, err := restql.ParseLevel(name)
+		if err != nil {
+			continue
+		}
 
-		fieldMap[key] = value
+		result[level] = every
 	}
-	return fieldMap
+
+	return result
 }
@@ -0,0 +1,145 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+const (
+	defaultWebhookTimeout   = 5 * time.Second
+	defaultWebhookQueueSize = 1000
+)
+
+// QuerySummary is the body posted to the webhook
+// after the execution of a query.
+type QuerySummary struct {
+	Event      string         `json:"event"`
+	At         time.Time      `json:"at"`
+	Tenant     string         `json:"tenant"`
+	Namespace  string         `json:"namespace,omitempty"`
+	Query      string         `json:"query,omitempty"`
+	Revision   int            `json:"revision,omitempty"`
+	DurationMs float64        `json:"durationMs"`
+	Statuses   map[string]int `json:"statuses"`
+	Error      string         `json:"error,omitempty"`
+	RequestID  string         `json:"requestId,omitempty"`
+}
+
+// NewQuerySummary builds the summary of the query execution.
+func NewQuerySummary(ctx context.Context, event restql.QueryFinishedEvent) QuerySummary {
+	summary := QuerySummary{
+		Event:      event.EventName(),
+		At:         time.Now(),
+		Tenant:     event.Tenant,
+		Namespace:  event.Namespace,
+		Query:      event.Query,
+		Revision:   event.Revision,
+		DurationMs: float64(event.Duration.Microseconds()) / 1000,
+		Statuses:   event.Statuses,
+	}
+
+	if event.Err != nil {
+		summary.Error = event.Err.Error()
+	}
+	if requestID, ok := restql.RequestID(ctx); ok {
+		summary.RequestID = requestID
+	}
+
+	return summary
+}
+
+// Webhook posts the summary of every query execution to the
+// configured URL. Summaries are queued and sent in the background,
+// hence query executions are never delayed by the webhook, and the
+// summaries that do not fit the queue are dropped.
+type Webhook struct {
+	log     restql.Logger
+	url     string
+	headers map[string]string
+	client  *http.Client
+	queue   chan QuerySummary
+}
+
+// NewWebhook constructs a Webhook and starts sending its summaries.
+func NewWebhook(log restql.Logger, cfg conf.WebhookConf) *Webhook {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultWebhookQueueSize
+	}
+
+	w := &Webhook{
+		log:     log,
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: timeout},
+		queue:   make(chan QuerySummary, queueSize),
+	}
+	go w.run()
+
+	return w
+}
+
+// Handler returns the event handler queueing
+// the summary of every query execution.
+func (w *Webhook) Handler() restql.EventHandler {
+	return func(ctx context.Context, event restql.Event) {
+		finished, ok := event.(restql.QueryFinishedEvent)
+		if !ok {
+			return
+		}
+
+		select {
+		case w.queue <- NewQuerySummary(ctx, finished):
+		default:
+			w.log.Warn("webhook queue is full, dropping query summary", "tenant", finished.Tenant, "namespace", finished.Namespace, "query", finished.Query)
+		}
+	}
+}
+
+func (w *Webhook) run() {
+	for summary := range w.queue {
+		if err := w.send(summary); err != nil {
+			w.log.Warn("failed to send query summary to webhook", "error", err, "url", w.url)
+		}
+	}
+}
+
+func (w *Webhook) send(summary QuerySummary) error {
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
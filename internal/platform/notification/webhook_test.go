@@ -0,0 +1,63 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/notification"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestWebhook(t *testing.T) {
+	received := make(chan notification.QuerySummary, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		test.Equal(t, r.Method, http.MethodPost)
+		test.Equal(t, r.Header.Get("Content-Type"), "application/json")
+		test.Equal(t, r.Header.Get("Authorization"), "Bearer token")
+
+		var summary notification.QuerySummary
+		test.VerifyError(t, json.NewDecoder(r.Body).Decode(&summary))
+		received <- summary
+	}))
+	defer server.Close()
+
+	webhook := notification.NewWebhook(test.NoOpLogger, conf.WebhookConf{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}})
+	handler := webhook.Handler()
+
+	ctx := restql.WithRequestID(context.Background(), "abc-123")
+	handler(ctx, restql.StatementFinishedEvent{Resource: "hero"})
+	handler(ctx, restql.QueryFinishedEvent{
+		Tenant:    "DC",
+		Namespace: "heroes",
+		Query:     "get-hero",
+		Revision:  2,
+		Statuses:  map[string]int{"hero": 200, "sidekick": 404},
+		Duration:  1500 * time.Microsecond,
+		Err:       errors.New("query timed out"),
+	})
+
+	select {
+	case summary := <-received:
+		summary.At = time.Time{}
+		test.Equal(t, summary, notification.QuerySummary{
+			Event:      restql.QueryFinishedEventName,
+			Tenant:     "DC",
+			Namespace:  "heroes",
+			Query:      "get-hero",
+			Revision:   2,
+			DurationMs: 1.5,
+			Statuses:   map[string]int{"hero": 200, "sidekick": 404},
+			Error:      "query timed out",
+			RequestID:  "abc-123",
+		})
+	case <-time.After(time.Second):
+		t.Fatal("query summary not received")
+	}
+}
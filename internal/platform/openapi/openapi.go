@@ -0,0 +1,321 @@
+// Package openapi generates resource mappings from the OpenAPI
+// or Swagger document describing an upstream.
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const loadTimeout = 10 * time.Second
+
+// Errors returned when the mappings can not be generated from a document.
+var (
+	ErrInvalidDocument = errors.New("invalid openapi document")
+	ErrNoBaseURL       = errors.New("the document does not define the upstream address, a base url must be given")
+)
+
+// Mapping represents a resource generated from a path of the document,
+// with the URL template in the mapping syntax and the headers whose
+// values are defined by default on the document.
+type Mapping struct {
+	Resource string            `json:"resource"`
+	URL      string            `json:"url"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// Options customizes the mappings generated from a document.
+// Prefix is prepended to every resource name, BaseURL replaces the
+// upstream address defined on the document and Location is where the
+// document was loaded from, used to resolve relative server URLs.
+type Options struct {
+	Prefix   string
+	BaseURL  string
+	Location string
+}
+
+type document struct {
+	Swagger  string   `yaml:"swagger"`
+	OpenAPI  string   `yaml:"openapi"`
+	Host     string   `yaml:"host"`
+	BasePath string   `yaml:"basePath"`
+	Schemes  []string `yaml:"schemes"`
+	Servers  []server `yaml:"servers"`
+
+	Paths      map[string]pathItem  `yaml:"paths"`
+	Parameters map[string]parameter `yaml:"parameters"`
+	Components struct {
+		Parameters map[string]parameter `yaml:"parameters"`
+	} `yaml:"components"`
+}
+
+type server struct {
+	URL       string `yaml:"url"`
+	Variables map[string]struct {
+		Default string `yaml:"default"`
+	} `yaml:"variables"`
+}
+
+type pathItem struct {
+	Parameters []parameter `yaml:"parameters"`
+	Get        *operation  `yaml:"get"`
+	Put        *operation  `yaml:"put"`
+	Post       *operation  `yaml:"post"`
+	Delete     *operation  `yaml:"delete"`
+	Patch      *operation  `yaml:"patch"`
+	Head       *operation  `yaml:"head"`
+	Options    *operation  `yaml:"options"`
+}
+
+func (p pathItem) operations() []*operation {
+	var ops []*operation
+	for _, op := range []*operation{p.Get, p.Put, p.Post, p.Delete, p.Patch, p.Head, p.Options} {
+		if op != nil {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+type operation struct {
+	Parameters []parameter `yaml:"parameters"`
+}
+
+type parameter struct {
+	Ref     string      `yaml:"$ref"`
+	Name    string      `yaml:"name"`
+	In      string      `yaml:"in"`
+	Default interface{} `yaml:"default"`
+	Schema  struct {
+		Default interface{} `yaml:"default"`
+	} `yaml:"schema"`
+}
+
+func (p parameter) defaultValue() (interface{}, bool) {
+	if p.Default != nil {
+		return p.Default, true
+	}
+	if p.Schema.Default != nil {
+		return p.Schema.Default, true
+	}
+	return nil, false
+}
+
+// Load reads the document from a file or, when the location
+// is an http or https URL, fetches it from the upstream.
+func Load(ctx context.Context, location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return ioutil.ReadFile(location)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json, application/yaml")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch document from %s", location)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to fetch document from %s: status %d", location, res.StatusCode)
+	}
+
+	return ioutil.ReadAll(res.Body)
+}
+
+// Import generates a mapping for every path of the OpenAPI 3 or
+// Swagger 2 document, in JSON or YAML, sorted by resource name.
+//
+// Path templates are converted to the mapping path parameters, the
+// query parameters of every operation of the path are added to the
+// mapping query and the header parameters with a default value are
+// returned as the mapping headers.
+func Import(content []byte, opts Options) ([]Mapping, error) {
+	var doc document
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDocument, err)
+	}
+	if doc.Swagger == "" && doc.OpenAPI == "" {
+		return nil, fmt.Errorf("%w: neither openapi nor swagger version defined", ErrInvalidDocument)
+	}
+
+	baseURL, err := doc.baseURL(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for p := range doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	seen := make(map[string]string, len(paths))
+	mappings := make([]Mapping, 0, len(paths))
+	for _, p := range paths {
+		name := resourceName(opts.Prefix, p)
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("%w: paths %s and %s generate the same resource %s", ErrInvalidDocument, other, p, name)
+		}
+		seen[name] = p
+
+		m, err := doc.mapping(name, baseURL, p, doc.Paths[p])
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, m)
+	}
+
+	return mappings, nil
+}
+
+func (d document) baseURL(opts Options) (string, error) {
+	if opts.BaseURL != "" {
+		return strings.TrimSuffix(opts.BaseURL, "/"), nil
+	}
+
+	var base string
+	switch {
+	case len(d.Servers) > 0:
+		base = d.Servers[0].URL
+		for name, v := range d.Servers[0].Variables {
+			base = strings.ReplaceAll(base, "{"+name+"}", v.Default)
+		}
+	case d.Host != "":
+		scheme := "https"
+		if len(d.Schemes) > 0 {
+			scheme = d.Schemes[0]
+		}
+		base = scheme + "://" + d.Host + d.BasePath
+	default:
+		// Without them, the upstream is the one serving the document.
+		base = "/" + strings.TrimPrefix(d.BasePath, "/")
+	}
+
+	if !strings.Contains(base, "://") {
+		location, err := url.Parse(opts.Location)
+		if err != nil || !location.IsAbs() || !strings.HasPrefix(location.Scheme, "http") {
+			return "", ErrNoBaseURL
+		}
+
+		ref, err := url.Parse(base)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid server url %s", base)
+		}
+		base = location.ResolveReference(ref).String()
+	}
+
+	return strings.TrimSuffix(base, "/"), nil
+}
+
+var pathTemplateRegex = regexp.MustCompile(`{([^}/]+)}`)
+
+func (d document) mapping(name string, baseURL string, path string, item pathItem) (Mapping, error) {
+	params := append([]parameter{}, item.Parameters...)
+	for _, op := range item.operations() {
+		params = append(params, op.Parameters...)
+	}
+
+	var query []string
+	queryNames := make(map[string]bool)
+	headers := make(map[string]string)
+	for _, p := range params {
+		param, err := d.resolve(p)
+		if err != nil {
+			return Mapping{}, fmt.Errorf("%w: invalid parameter of path %s: %s", ErrInvalidDocument, path, err)
+		}
+
+		switch param.In {
+		case "query":
+			if !queryNames[param.Name] {
+				queryNames[param.Name] = true
+				query = append(query, ":"+param.Name)
+			}
+		case "header":
+			if value, ok := param.defaultValue(); ok {
+				headers[param.Name] = fmt.Sprint(value)
+			}
+		}
+	}
+
+	u := baseURL + pathTemplateRegex.ReplaceAllString(path, ":$1")
+	if len(query) > 0 {
+		u += "?" + strings.Join(query, "&")
+	}
+
+	m := Mapping{Resource: name, URL: u}
+	if len(headers) > 0 {
+		m.Headers = headers
+	}
+
+	return m, nil
+}
+
+// resolve returns the parameter referenced by the local $ref,
+// either a Swagger 2 or an OpenAPI 3 reusable parameter.
+func (d document) resolve(p parameter) (parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+
+	var (
+		param parameter
+		ok    bool
+	)
+	switch {
+	case strings.HasPrefix(p.Ref, "#/components/parameters/"):
+		param, ok = d.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+	case strings.HasPrefix(p.Ref, "#/parameters/"):
+		param, ok = d.Parameters[strings.TrimPrefix(p.Ref, "#/parameters/")]
+	}
+
+	if !ok {
+		return parameter{}, errors.Errorf("unresolved reference %s", p.Ref)
+	}
+	return param, nil
+}
+
+var invalidNameCharsRegex = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// resourceName derives the resource from the path, joining its
+// segments with dashes and naming the path parameters by them,
+// like heroes-by-id for /heroes/{id}.
+func resourceName(prefix string, path string) string {
+	var parts []string
+	if prefix != "" {
+		parts = append(parts, prefix)
+	}
+
+	for _, segment := range strings.Split(path, "/") {
+		if m := pathTemplateRegex.FindStringSubmatch(segment); m != nil {
+			segment = "by-" + m[1]
+		}
+
+		segment = strings.Trim(invalidNameCharsRegex.ReplaceAllString(segment, "-"), "-")
+		if segment != "" {
+			parts = append(parts, strings.ToLower(segment))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "root"
+	}
+	return strings.Join(parts, "-")
+}
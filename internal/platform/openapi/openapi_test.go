@@ -0,0 +1,165 @@
+package openapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/openapi"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+const openAPIDocument = `
+openapi: 3.0.0
+servers:
+  - url: https://{env}.marvel.api/v1/
+    variables:
+      env:
+        default: prod
+components:
+  parameters:
+    page:
+      name: page
+      in: query
+paths:
+  /heroes:
+    parameters:
+      - name: X-Api-Version
+        in: header
+        schema:
+          type: string
+          default: "2"
+    get:
+      parameters:
+        - name: name
+          in: query
+        - $ref: '#/components/parameters/page'
+    post:
+      parameters:
+        - name: name
+          in: query
+  /heroes/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+`
+
+const swaggerDocument = `{
+  "swagger": "2.0",
+  "host": "marvel.api",
+  "basePath": "/v1",
+  "schemes": ["http"],
+  "parameters": {"version": {"name": "X-Api-Version", "in": "header", "type": "integer", "default": 2}},
+  "paths": {
+    "/heroes/{heroId}/sidekicks": {
+      "get": {"parameters": [{"name": "heroId", "in": "path"}, {"$ref": "#/parameters/version"}]}
+    }
+  }
+}`
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		options  openapi.Options
+		expected []openapi.Mapping
+	}{
+		{
+			"should import mappings from an openapi document",
+			openAPIDocument,
+			openapi.Options{},
+			[]openapi.Mapping{
+				{Resource: "heroes", URL: "https://prod.marvel.api/v1/heroes?:name&:page", Headers: map[string]string{"X-Api-Version": "2"}},
+				{Resource: "heroes-by-id", URL: "https://prod.marvel.api/v1/heroes/:id"},
+			},
+		},
+		{
+			"should import mappings with prefix and base url",
+			openAPIDocument,
+			openapi.Options{Prefix: "marvel", BaseURL: "http://localhost:9000/"},
+			[]openapi.Mapping{
+				{Resource: "marvel-heroes", URL: "http://localhost:9000/heroes?:name&:page", Headers: map[string]string{"X-Api-Version": "2"}},
+				{Resource: "marvel-heroes-by-id", URL: "http://localhost:9000/heroes/:id"},
+			},
+		},
+		{
+			"should import mappings from a swagger document",
+			swaggerDocument,
+			openapi.Options{},
+			[]openapi.Mapping{
+				{Resource: "heroes-by-heroid-sidekicks", URL: "http://marvel.api/v1/heroes/:heroId/sidekicks", Headers: map[string]string{"X-Api-Version": "2"}},
+			},
+		},
+		{
+			"should resolve the upstream address against the document location",
+			"openapi: 3.0.0\nservers: [{url: /api}]\npaths: {/heroes: {get: {}}}",
+			openapi.Options{Location: "https://marvel.api/docs/openapi.yaml"},
+			[]openapi.Mapping{
+				{Resource: "heroes", URL: "https://marvel.api/api/heroes"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := openapi.Import([]byte(tt.document), tt.options)
+
+			test.VerifyError(t, err)
+			test.Equal(t, got, tt.expected)
+		})
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		expected error
+	}{
+		{"should fail on a document without version", "paths: {}", openapi.ErrInvalidDocument},
+		{"should fail on unresolved references", "openapi: 3.0.0\nservers: [{url: http://a}]\npaths: {/a: {get: {parameters: [{$ref: '#/components/parameters/b'}]}}}", openapi.ErrInvalidDocument},
+		{"should fail on paths with the same resource", "openapi: 3.0.0\nservers: [{url: http://a}]\npaths: {/a-b: {}, /a/b: {}}", openapi.ErrInvalidDocument},
+		{"should fail without upstream address", "openapi: 3.0.0\npaths: {/a: {}}", openapi.ErrNoBaseURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := openapi.Import([]byte(tt.document), openapi.Options{Location: "openapi.yaml"})
+
+			test.Equal(t, errors.Is(err, tt.expected), true)
+		})
+	}
+}
+
+type writtenMapping struct {
+	Tenant   string
+	Resource string
+	URL      string
+}
+
+type stubMappingsWriter struct {
+	written []writtenMapping
+}
+
+func (s *stubMappingsWriter) Write(ctx context.Context, tenant string, resource string, url string) error {
+	s.written = append(s.written, writtenMapping{Tenant: tenant, Resource: resource, URL: url})
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("openapi: 3.0.0\npaths: {'/heroes/{id}': {get: {}}}"))
+	}))
+	defer server.Close()
+
+	mw := &stubMappingsWriter{}
+	got, err := openapi.Sync(context.Background(), mw, "acme", server.URL+"/openapi.yaml", openapi.Options{})
+
+	test.VerifyError(t, err)
+	test.Equal(t, got, []openapi.Mapping{{Resource: "heroes-by-id", URL: server.URL + "/heroes/:id"}})
+	test.Equal(t, mw.written, []writtenMapping{{Tenant: "acme", Resource: "heroes-by-id", URL: server.URL + "/heroes/:id"}})
+}
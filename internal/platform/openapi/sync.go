@@ -0,0 +1,90 @@
+package openapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+// MappingsWriter is implemented by the persistence
+// the imported mappings are written to.
+type MappingsWriter interface {
+	Write(ctx context.Context, tenant string, resource string, url string) error
+}
+
+// Sync loads the document from the location and writes every mapping
+// generated from it to the tenant, returning the ones written.
+func Sync(ctx context.Context, mw MappingsWriter, tenant string, location string, opts Options) ([]Mapping, error) {
+	content, err := Load(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Location = location
+	mappings, err := Import(content, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range mappings {
+		if err := mw.Write(ctx, tenant, m.Resource, m.URL); err != nil {
+			return nil, errors.Wrapf(err, "failed to write mapping %s", m.Resource)
+		}
+	}
+
+	return mappings, nil
+}
+
+// Syncer imports the mappings of the configured documents when
+// started and, for the ones with an interval, again on every tick,
+// so changes of the upstream paths reach the tenant mappings.
+type Syncer struct {
+	log     restql.Logger
+	mw      MappingsWriter
+	imports []conf.MappingImportConf
+}
+
+// NewSyncer constructs a Syncer of the imports.
+func NewSyncer(log restql.Logger, mw MappingsWriter, imports []conf.MappingImportConf) *Syncer {
+	return &Syncer{log: log, mw: mw, imports: imports}
+}
+
+// Start runs every import in the background until the context is done.
+func (s *Syncer) Start(ctx context.Context) {
+	ctx = restql.WithLogger(ctx, s.log)
+	for _, imp := range s.imports {
+		go s.run(ctx, imp)
+	}
+}
+
+func (s *Syncer) run(ctx context.Context, imp conf.MappingImportConf) {
+	s.sync(ctx, imp)
+	if imp.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(imp.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sync(ctx, imp)
+		}
+	}
+}
+
+func (s *Syncer) sync(ctx context.Context, imp conf.MappingImportConf) {
+	mappings, err := Sync(ctx, s.mw, imp.Tenant, imp.Spec, Options{Prefix: imp.Prefix, BaseURL: imp.BaseURL})
+	if err != nil {
+		s.log.Warn("failed to import mappings", "tenant", imp.Tenant, "spec", imp.Spec, "error", err)
+		return
+	}
+
+	s.log.Info("mappings imported", "tenant", imp.Tenant, "spec", imp.Spec, "mappings", len(mappings))
+}
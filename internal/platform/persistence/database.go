@@ -41,6 +41,26 @@ func NewDatabase(log restql.Logger, disabled bool) (Database, error) {
 	return database, nil
 }
 
+// PingDatabase verifies that the database plugin is reachable by
+// listing its namespaces, succeeding when no database is in use or
+// when its snapshot can serve the mappings and queries instead.
+func PingDatabase(ctx context.Context, db Database) error {
+	if _, ok := db.(noOpDatabase); ok {
+		return nil
+	}
+
+	if s, ok := db.(*Snapshot); ok {
+		err := PingDatabase(ctx, s.Database)
+		if err != nil && s.Available() {
+			return nil
+		}
+		return err
+	}
+
+	_, err := db.FindAllNamespaces(ctx)
+	return err
+}
+
 var errNoDatabase = errors.New("no op database")
 
 type noOpDatabase struct{}
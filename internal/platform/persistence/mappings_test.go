@@ -151,6 +151,7 @@ var noOpLogger = logger.New(ioutil.Discard, logger.LogOptions{})
 type stubDatabase struct {
 	findMappingsForTenant []restql.Mapping
 	findQuery             restql.SavedQuery
+	err                   error
 }
 
 func (s stubDatabase) Name() string {
@@ -182,11 +183,11 @@ func (s stubDatabase) SetMapping(ctx context.Context, tenantID string, mappingsN
 }
 
 func (s stubDatabase) FindMappingsForTenant(ctx context.Context, tenantID string) ([]restql.Mapping, error) {
-	return s.findMappingsForTenant, nil
+	return s.findMappingsForTenant, s.err
 }
 
 func (s stubDatabase) FindQuery(ctx context.Context, namespace string, name string, revision int) (restql.SavedQuery, error) {
-	return s.findQuery, nil
+	return s.findQuery, s.err
 }
 
 type stubEnvSource struct {
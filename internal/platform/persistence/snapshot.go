@@ -0,0 +1,311 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+const defaultSnapshotInterval = 30 * time.Second
+
+// Snapshot is a Database keeping on disk the last known-good mappings
+// and saved queries read from the wrapped one, so they are served while
+// it cannot be reached, including on starts during a database outage.
+type Snapshot struct {
+	Database
+
+	log  restql.Logger
+	path string
+
+	mu         sync.RWMutex
+	content    snapshotContent
+	dirty      bool
+	staleSince time.Time
+}
+
+type snapshotContent struct {
+	UpdatedAt time.Time                            `json:"updatedAt"`
+	Mappings  map[string]map[string]string         `json:"mappings"`
+	Queries   map[string]map[string]map[int]string `json:"queries"`
+}
+
+// SnapshotStatus represents the state of the snapshot, which is stale
+// while it serves the values the database failed to provide.
+type SnapshotStatus struct {
+	Path       string     `json:"path"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+	Stale      bool       `json:"stale"`
+	StaleSince *time.Time `json:"staleSince,omitempty"`
+}
+
+// NewSnapshot constructs a Snapshot of the database, loading the
+// content saved on the file at the path, if any. An unreadable
+// file is discarded, since it is rewritten from the database.
+func NewSnapshot(log restql.Logger, db Database, path string) *Snapshot {
+	s := &Snapshot{
+		Database: db,
+		log:      log,
+		path:     path,
+		content: snapshotContent{
+			Mappings: make(map[string]map[string]string),
+			Queries:  make(map[string]map[string]map[int]string),
+		},
+	}
+
+	data, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		log.Info("no snapshot found", "path", path)
+	case err != nil:
+		log.Warn("failed to read snapshot", "path", path, "error", err)
+	default:
+		var content snapshotContent
+		if err := json.Unmarshal(data, &content); err != nil {
+			log.Warn("failed to decode snapshot", "path", path, "error", err)
+			break
+		}
+		if content.Mappings != nil {
+			s.content.Mappings = content.Mappings
+		}
+		if content.Queries != nil {
+			s.content.Queries = content.Queries
+		}
+		s.content.UpdatedAt = content.UpdatedAt
+		log.Info("snapshot loaded", "path", path, "updatedAt", content.UpdatedAt)
+	}
+
+	return s
+}
+
+// Start saves the snapshot on every interval it has changed,
+// until the context is done, when it is saved a last time.
+func (s *Snapshot) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultSnapshotInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				s.save()
+				return
+			case <-ticker.C:
+				s.save()
+			}
+		}
+	}()
+}
+
+func (s *Snapshot) save() {
+	if err := s.Save(); err != nil {
+		s.log.Error("failed to save snapshot", err, "path", s.path)
+
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+	}
+}
+
+// Save writes the snapshot to its file, when it has changed,
+// replacing it at once so a crash never leaves it truncated.
+func (s *Snapshot) Save() error {
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(s.content)
+	s.dirty = false
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Status returns the state of the snapshot.
+func (s *Snapshot) Status() SnapshotStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := SnapshotStatus{Path: s.path, Stale: !s.staleSince.IsZero()}
+	if !s.content.UpdatedAt.IsZero() {
+		updatedAt := s.content.UpdatedAt
+		status.UpdatedAt = &updatedAt
+	}
+	if status.Stale {
+		staleSince := s.staleSince
+		status.StaleSince = &staleSince
+	}
+
+	return status
+}
+
+// Available returns true when the snapshot has content to serve.
+func (s *Snapshot) Available() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.content.Mappings) > 0 || len(s.content.Queries) > 0
+}
+
+// FindMappingsForTenant reads the mappings of the tenant from the
+// database, falling back to the snapshot when it cannot be reached.
+func (s *Snapshot) FindMappingsForTenant(ctx context.Context, tenantID string) ([]restql.Mapping, error) {
+	mappings, err := s.Database.FindMappingsForTenant(ctx, tenantID)
+	if err == nil {
+		urls := make(map[string]string, len(mappings))
+		for _, m := range mappings {
+			urls[m.ResourceName()] = m.URL()
+		}
+		s.update(func(c *snapshotContent) bool {
+			if reflect.DeepEqual(c.Mappings[tenantID], urls) {
+				return false
+			}
+			c.Mappings[tenantID] = urls
+			return true
+		})
+		return mappings, nil
+	}
+	if !isDatabaseFailure(err) {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	urls, found := s.content.Mappings[tenantID]
+	s.mu.RUnlock()
+	if !found {
+		return nil, err
+	}
+
+	result := make([]restql.Mapping, 0, len(urls))
+	for resource, url := range urls {
+		m, mErr := restql.NewMapping(resource, url)
+		if mErr != nil {
+			continue
+		}
+		result = append(result, m)
+	}
+
+	s.markStale(ctx, err)
+	return result, nil
+}
+
+// FindQuery reads the saved query from the database, falling
+// back to the snapshot when it cannot be reached.
+func (s *Snapshot) FindQuery(ctx context.Context, namespace string, name string, revision int) (restql.SavedQuery, error) {
+	query, err := s.Database.FindQuery(ctx, namespace, name, revision)
+	if err == nil {
+		if query.Text != "" {
+			s.update(func(c *snapshotContent) bool {
+				if c.Queries[namespace][name][revision] == query.Text {
+					return false
+				}
+				if c.Queries[namespace] == nil {
+					c.Queries[namespace] = make(map[string]map[int]string)
+				}
+				if c.Queries[namespace][name] == nil {
+					c.Queries[namespace][name] = make(map[int]string)
+				}
+				c.Queries[namespace][name][revision] = query.Text
+				return true
+			})
+		}
+		return query, nil
+	}
+	if !isDatabaseFailure(err) {
+		return restql.SavedQuery{}, err
+	}
+
+	s.mu.RLock()
+	text, found := s.content.Queries[namespace][name][revision]
+	s.mu.RUnlock()
+	if !found {
+		return restql.SavedQuery{}, err
+	}
+
+	s.markStale(ctx, err)
+	return restql.SavedQuery{Name: name, Text: text, Revision: revision}, nil
+}
+
+// FindAllTenants lists the tenants of the database, falling
+// back to the ones of the snapshot when it cannot be reached.
+func (s *Snapshot) FindAllTenants(ctx context.Context) ([]string, error) {
+	tenants, err := s.Database.FindAllTenants(ctx)
+	if err == nil || !isDatabaseFailure(err) {
+		return tenants, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.content.Mappings) == 0 {
+		return nil, err
+	}
+
+	tenants = make([]string, 0, len(s.content.Mappings))
+	for tenant := range s.content.Mappings {
+		tenants = append(tenants, tenant)
+	}
+	return tenants, nil
+}
+
+func (s *Snapshot) update(fn func(c *snapshotContent) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fn(&s.content) {
+		s.content.UpdatedAt = time.Now()
+		s.dirty = true
+	}
+
+	if !s.staleSince.IsZero() {
+		s.log.Info("database recovered, snapshot no longer served", "staleSince", s.staleSince)
+		s.staleSince = time.Time{}
+	}
+}
+
+func (s *Snapshot) markStale(ctx context.Context, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.staleSince.IsZero() {
+		s.staleSince = time.Now()
+		restql.GetLogger(ctx).Warn("database unavailable, serving from snapshot", "error", err, "updatedAt", s.content.UpdatedAt)
+	}
+}
+
+// isDatabaseFailure returns true for the errors that the snapshot can
+// cover: failures to communicate with the database, but not the absence
+// of the value, nor the lack of a database.
+func isDatabaseFailure(err error) bool {
+	return err != errNoDatabase &&
+		!errors.Is(err, restql.ErrMappingsNotFoundInDatabase) &&
+		!errors.Is(err, restql.ErrQueryNotFoundInDatabase)
+}
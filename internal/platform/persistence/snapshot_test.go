@@ -0,0 +1,78 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "restql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snapshot.json")
+
+	heroMapping, err := restql.NewMapping("hero", "http://hero.api/")
+	test.VerifyError(t, err)
+	heroQuery := restql.SavedQuery{Name: "heroes", Text: "from hero", Revision: 1}
+
+	db := stubDatabase{findMappingsForTenant: []restql.Mapping{heroMapping}, findQuery: heroQuery}
+	snapshot := NewSnapshot(noOpLogger, db, path)
+
+	mappings, err := snapshot.FindMappingsForTenant(context.Background(), mytenant)
+	test.VerifyError(t, err)
+	test.Equal(t, mappings, []restql.Mapping{heroMapping})
+
+	query, err := snapshot.FindQuery(context.Background(), "marvel", "heroes", 1)
+	test.VerifyError(t, err)
+	test.Equal(t, query, heroQuery)
+	test.Equal(t, snapshot.Status().Stale, false)
+
+	test.VerifyError(t, snapshot.Save())
+
+	unavailable := stubDatabase{err: fmt.Errorf("%w: connection refused", restql.ErrDatabaseCommunicationFailed)}
+	restarted := NewSnapshot(noOpLogger, unavailable, path)
+
+	mappings, err = restarted.FindMappingsForTenant(context.Background(), mytenant)
+	test.VerifyError(t, err)
+	test.Equal(t, mappings, []restql.Mapping{heroMapping})
+
+	query, err = restarted.FindQuery(context.Background(), "marvel", "heroes", 1)
+	test.VerifyError(t, err)
+	test.Equal(t, query, heroQuery)
+
+	status := restarted.Status()
+	test.Equal(t, status.Stale, true)
+	test.Equal(t, status.StaleSince != nil, true)
+	test.Equal(t, status.UpdatedAt != nil, true)
+
+	_, err = restarted.FindMappingsForTenant(context.Background(), "unknown")
+	test.Equal(t, errors.Is(err, restql.ErrDatabaseCommunicationFailed), true)
+
+	_, err = restarted.FindQuery(context.Background(), "marvel", "heroes", 2)
+	test.Equal(t, errors.Is(err, restql.ErrDatabaseCommunicationFailed), true)
+}
+
+func TestSnapshotNotFound(t *testing.T) {
+	dir, err := ioutil.TempDir("", "restql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	snapshot := NewSnapshot(noOpLogger, stubDatabase{err: restql.ErrMappingsNotFoundInDatabase}, filepath.Join(dir, "snapshot.json"))
+
+	_, err = snapshot.FindMappingsForTenant(context.Background(), mytenant)
+	test.Equal(t, errors.Is(err, restql.ErrMappingsNotFoundInDatabase), true)
+	test.Equal(t, snapshot.Status().Stale, false)
+	test.Equal(t, snapshot.Available(), false)
+}
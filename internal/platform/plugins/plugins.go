@@ -20,6 +20,7 @@ type Lifecycle interface {
 	AfterQuery(ctx context.Context, query string, result domain.Resources) context.Context
 	BeforeRequest(ctx context.Context, request restql.HTTPRequest) context.Context
 	AfterRequest(ctx context.Context, request restql.HTTPRequest, response restql.HTTPResponse, err error) context.Context
+	BeforeResponse(ctx context.Context, result domain.Resources, response restql.QueryResponse) restql.QueryResponse
 }
 
 type pluginExecutor func(ctx context.Context, p restql.LifecyclePlugin) context.Context
@@ -63,12 +64,7 @@ func (m manager) BeforeQuery(ctx context.Context, query string, queryCtx restql.
 
 func (m manager) AfterQuery(ctx context.Context, query string, result domain.Resources) context.Context {
 	return m.executeAllPluginsWithContext(ctx, "AfterQuery", func(currentCtx context.Context, p restql.LifecyclePlugin) context.Context {
-		r := make(map[string]interface{})
-		for id, resource := range result {
-			r[string(id)] = resource
-		}
-
-		return p.AfterQuery(currentCtx, query, r)
+		return p.AfterQuery(currentCtx, query, toPluginResult(result))
 	})
 }
 
@@ -83,6 +79,34 @@ func (m manager) AfterRequest(ctx context.Context, request restql.HTTPRequest, r
 		return p.AfterRequest(currentCtx, request, response, err)
 	})
 }
+
+func (m manager) BeforeResponse(ctx context.Context, result domain.Resources, response restql.QueryResponse) restql.QueryResponse {
+	log := restql.GetLogger(ctx)
+	r := toPluginResult(result)
+
+	for _, p := range m.availablePlugins {
+		rp, ok := p.(restql.ResponsePlugin)
+		if !ok {
+			continue
+		}
+
+		m.safeExecute(log, p.Name(), "BeforeResponse", func() {
+			response = rp.BeforeResponse(ctx, r, response)
+		})
+	}
+
+	return response
+}
+
+func toPluginResult(result domain.Resources) map[string]interface{} {
+	r := make(map[string]interface{})
+	for id, resource := range result {
+		r[string(id)] = resource
+	}
+
+	return r
+}
+
 func (m manager) executeAllPluginsWithContext(ctx context.Context, hook string, fn pluginExecutor) context.Context {
 	log := restql.GetLogger(ctx)
 
@@ -165,3 +189,6 @@ func (n noOpLifecycle) BeforeRequest(ctx context.Context, request restql.HTTPReq
 func (n noOpLifecycle) AfterRequest(ctx context.Context, request restql.HTTPRequest, response restql.HTTPResponse, err error) context.Context {
 	return ctx
 }
+func (n noOpLifecycle) BeforeResponse(ctx context.Context, result domain.Resources, response restql.QueryResponse) restql.QueryResponse {
+	return response
+}
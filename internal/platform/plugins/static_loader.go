@@ -1,6 +1,8 @@
 package plugins
 
 import (
+	"context"
+
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
 	"github.com/pkg/errors"
 )
@@ -14,14 +16,45 @@ func loadLifecyclePlugins(logger restql.Logger) []restql.LifecyclePlugin {
 			continue
 		}
 
-		pluginInstance, ok := p.(restql.LifecyclePlugin)
+		pluginInstance, ok := toLifecyclePlugin(pluginInfo, p)
 		if !ok {
 			logger.Error("failed to load plugin", errors.Errorf("plugin of incorrect type: %T", p))
 			continue
 		}
 
-		logger.Debug("plugin loaded", "name", pluginInstance.Name())
+		logger.Debug("plugin loaded", "name", pluginInstance.Name(), "api-version", pluginInfo.APIVersion)
 		ps = append(ps, pluginInstance)
 	}
 	return ps
 }
+
+func toLifecyclePlugin(pluginInfo restql.PluginInfo, p restql.Plugin) (restql.LifecyclePlugin, bool) {
+	if lp, ok := p.(restql.LifecyclePlugin); ok {
+		return lp, true
+	}
+
+	if !pluginInfo.IsLegacy() {
+		return nil, false
+	}
+
+	legacy, ok := p.(restql.LegacyLifecyclePlugin)
+	if !ok {
+		return nil, false
+	}
+
+	return legacyLifecycleShim{LegacyLifecyclePlugin: legacy}, true
+}
+
+// legacyLifecycleShim adapts a plugin built against the previous
+// plugin API major version to the current LifecyclePlugin interface.
+type legacyLifecycleShim struct {
+	restql.LegacyLifecyclePlugin
+}
+
+func (l legacyLifecycleShim) BeforeTransaction(ctx context.Context, tr restql.TransactionRequest) context.Context {
+	return ctx
+}
+
+func (l legacyLifecycleShim) AfterTransaction(ctx context.Context, tr restql.TransactionResponse) context.Context {
+	return ctx
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const memorySweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+// memoryStore keeps the buckets of a single restQL instance,
+// removing the ones that are full since the last sweep.
+type memoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (s *memoryStore) Take(_ context.Context, key string, rule Rule, cost int) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= memorySweepInterval {
+		s.sweep(now)
+	}
+
+	b, found := s.buckets[key]
+	if !found {
+		b = &bucket{tokens: float64(rule.Burst), updated: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens += now.Sub(b.updated).Seconds() * rule.Rate
+	if b.tokens > float64(rule.Burst) {
+		b.tokens = float64(rule.Burst)
+	}
+	b.updated = now
+
+	if b.tokens < float64(cost) {
+		return false, rule.wait(b.tokens, cost), nil
+	}
+
+	b.tokens -= float64(cost)
+	b.full = now.Add(time.Duration((float64(rule.Burst) - b.tokens) / rule.Rate * float64(time.Second)))
+	return true, 0, nil
+}
+
+func (s *memoryStore) list(_ context.Context) ([]storedBucket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]storedBucket, 0, len(s.buckets))
+	for key, b := range s.buckets {
+		result = append(result, storedBucket{key: key, tokens: b.tokens, updated: b.updated})
+	}
+	return result, nil
+}
+
+func (s *memoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
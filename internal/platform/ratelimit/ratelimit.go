@@ -0,0 +1,273 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+// ErrCostExceedsBurst represents the event of taking at once more
+// tokens than the bucket can hold, which is never allowed.
+var ErrCostExceedsBurst = errors.New("cost exceeds the rate limit burst")
+
+// Rule represents a token bucket refilled with Rate
+// tokens per second, holding up to Burst tokens.
+type Rule struct {
+	Rate  float64
+	Burst int
+}
+
+func newRule(c conf.RateLimitRuleConf) (Rule, bool) {
+	if c.Rate <= 0 {
+		return Rule{}, false
+	}
+
+	burst := c.Burst
+	if burst < 1 {
+		burst = int(math.Ceil(c.Rate))
+	}
+
+	return Rule{Rate: c.Rate, Burst: burst}, true
+}
+
+// wait returns the time until the bucket holds the cost in tokens.
+func (r Rule) wait(tokens float64, cost int) time.Duration {
+	return time.Duration((float64(cost) - tokens) / r.Rate * float64(time.Second))
+}
+
+// Store takes cost tokens at once from the buckets identified
+// by key, returning when they will be available if the bucket
+// does not hold enough of them.
+type Store interface {
+	Take(ctx context.Context, key string, rule Rule, cost int) (bool, time.Duration, error)
+}
+
+// pinger is implemented by the stores that
+// depend on a server, to verify it is reachable.
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// storedBucket is the state of a bucket when a token was last
+// taken from it, without the tokens refilled since.
+type storedBucket struct {
+	key     string
+	tokens  float64
+	updated time.Time
+}
+
+// lister is implemented by the stores
+// able to list the buckets they keep.
+type lister interface {
+	list(ctx context.Context) ([]storedBucket, error)
+}
+
+// BucketLevel represents the occupancy of a token bucket,
+// which holds Tokens out of Burst.
+type BucketLevel struct {
+	Key    string  `json:"key"`
+	Tokens float64 `json:"tokens"`
+	Burst  int     `json:"burst"`
+}
+
+type ruleSet struct {
+	fallback *Rule
+	rules    map[string]Rule
+}
+
+func newRuleSet(fallback *conf.RateLimitRuleConf, rules map[string]conf.RateLimitRuleConf) ruleSet {
+	rs := ruleSet{rules: make(map[string]Rule, len(rules))}
+	if fallback != nil {
+		if r, ok := newRule(*fallback); ok {
+			rs.fallback = &r
+		}
+	}
+
+	for name, c := range rules {
+		if r, ok := newRule(c); ok {
+			rs.rules[name] = r
+		}
+	}
+
+	return rs
+}
+
+func (rs ruleSet) get(name string) (Rule, bool) {
+	if r, ok := rs.rules[name]; ok {
+		return r, true
+	}
+
+	if rs.fallback != nil {
+		return *rs.fallback, true
+	}
+
+	return Rule{}, false
+}
+
+// Limiter applies the configured rules to the queries of
+// tenants and clients, and to the requests made to mapped
+// resources. When the store fails the request is allowed.
+type Limiter struct {
+	log       restql.Logger
+	store     Store
+	tenants   ruleSet
+	clients   ruleSet
+	resources ruleSet
+}
+
+// New constructs a Limiter from the configuration, keeping
+// the buckets in memory or, when defined, in Redis.
+func New(log restql.Logger, cfg conf.RateLimitConf) *Limiter {
+	var store Store = newMemoryStore()
+	if cfg.Redis != nil && cfg.Redis.Addr != "" {
+		store = newRedisStore(*cfg.Redis)
+	}
+
+	return NewWithStore(log, cfg, store)
+}
+
+// NewWithStore constructs a Limiter keeping the buckets in the given store.
+func NewWithStore(log restql.Logger, cfg conf.RateLimitConf, store Store) *Limiter {
+	return &Limiter{
+		log:       log,
+		store:     store,
+		tenants:   newRuleSet(cfg.Tenant, cfg.Tenants),
+		clients:   newRuleSet(cfg.Client, cfg.Clients),
+		resources: newRuleSet(nil, cfg.Resources),
+	}
+}
+
+// Ping verifies that the store keeping the buckets is reachable,
+// for the stores that depend on a server. A nil Limiter is always
+// reachable.
+func (l *Limiter) Ping(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	if p, ok := l.store.(pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+// Buckets returns the level of the buckets in use, refilled
+// up to now and sorted by key. A nil Limiter has no buckets.
+func (l *Limiter) Buckets(ctx context.Context) ([]BucketLevel, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	ls, ok := l.store.(lister)
+	if !ok {
+		return nil, nil
+	}
+
+	stored, err := ls.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	levels := make([]BucketLevel, 0, len(stored))
+	for _, b := range stored {
+		rule, found := l.rule(b.key)
+		if !found {
+			continue
+		}
+
+		tokens := math.Min(float64(rule.Burst), b.tokens+math.Max(0, now.Sub(b.updated).Seconds())*rule.Rate)
+		levels = append(levels, BucketLevel{Key: b.key, Tokens: tokens, Burst: rule.Burst})
+	}
+
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Key < levels[j].Key })
+	return levels, nil
+}
+
+// rule returns the rule of the bucket identified by key.
+func (l *Limiter) rule(key string) (Rule, bool) {
+	parts := strings.SplitN(key, ":", 2)
+	if len(parts) != 2 {
+		return Rule{}, false
+	}
+
+	switch parts[0] {
+	case "tenant":
+		return l.tenants.get(parts[1])
+	case "client":
+		return l.clients.get(parts[1])
+	case "resource":
+		return l.resources.get(parts[1][strings.LastIndex(parts[1], ":")+1:])
+	default:
+		return Rule{}, false
+	}
+}
+
+// AllowTenant takes a token from the bucket of the tenant.
+// A nil Limiter allows every request.
+func (l *Limiter) AllowTenant(ctx context.Context, tenant string) (bool, time.Duration) {
+	allowed, wait, _ := l.AllowTenantN(ctx, tenant, 1)
+	return allowed, wait
+}
+
+// AllowTenantN takes n tokens at once from the bucket of the
+// tenant, for requests running several queries. It fails with
+// ErrCostExceedsBurst when n is greater than the bucket holds.
+func (l *Limiter) AllowTenantN(ctx context.Context, tenant string, n int) (bool, time.Duration, error) {
+	if l == nil {
+		return true, 0, nil
+	}
+	return l.allow(ctx, l.tenants, tenant, "tenant:"+tenant, n)
+}
+
+// AllowClient takes a token from the bucket of the client.
+func (l *Limiter) AllowClient(ctx context.Context, client string) (bool, time.Duration) {
+	allowed, wait, _ := l.AllowClientN(ctx, client, 1)
+	return allowed, wait
+}
+
+// AllowClientN takes n tokens at once from the bucket of the
+// client, for requests running several queries. It fails with
+// ErrCostExceedsBurst when n is greater than the bucket holds.
+func (l *Limiter) AllowClientN(ctx context.Context, client string, n int) (bool, time.Duration, error) {
+	if l == nil {
+		return true, 0, nil
+	}
+	return l.allow(ctx, l.clients, client, "client:"+client, n)
+}
+
+// AllowResource takes a token from the bucket of the resource,
+// which is not shared between tenants.
+func (l *Limiter) AllowResource(ctx context.Context, tenant string, resource string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	allowed, wait, _ := l.allow(ctx, l.resources, resource, "resource:"+tenant+":"+resource, 1)
+	return allowed, wait
+}
+
+func (l *Limiter) allow(ctx context.Context, rs ruleSet, name string, key string, cost int) (bool, time.Duration, error) {
+	rule, found := rs.get(name)
+	if !found {
+		return true, 0, nil
+	}
+
+	if cost > rule.Burst {
+		return false, 0, errors.Wrapf(ErrCostExceedsBurst, "%d tokens requested from %s, which holds %d", cost, key, rule.Burst)
+	}
+
+	allowed, wait, err := l.store.Take(ctx, key, rule, cost)
+	if err != nil {
+		l.log.Warn("failed to take rate limit token, allowing request", "key", key, "error", err)
+		return true, 0, nil
+	}
+
+	return allowed, wait, nil
+}
@@ -0,0 +1,213 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/pkg/errors"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemoryStore()
+	store.now = func() time.Time { return now }
+
+	rule := Rule{Rate: 2, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		allowed, _, _ := store.Take(context.Background(), "key", rule, 1)
+		test.Equal(t, allowed, true)
+	}
+
+	allowed, wait, _ := store.Take(context.Background(), "key", rule, 1)
+	test.Equal(t, allowed, false)
+	test.Equal(t, wait, 500*time.Millisecond)
+
+	allowed, wait, _ = store.Take(context.Background(), "other", rule, 3)
+	test.Equal(t, allowed, false)
+	test.Equal(t, wait, 500*time.Millisecond)
+
+	allowed, _, _ = store.Take(context.Background(), "other", rule, 2)
+	test.Equal(t, allowed, true)
+
+	now = now.Add(500 * time.Millisecond)
+	allowed, _, _ = store.Take(context.Background(), "key", rule, 1)
+	test.Equal(t, allowed, true)
+
+	now = now.Add(time.Hour)
+	store.Take(context.Background(), "key", rule, 1)
+	test.Equal(t, len(store.buckets), 1)
+}
+
+type failingStore struct{}
+
+func (f failingStore) Take(ctx context.Context, key string, rule Rule, cost int) (bool, time.Duration, error) {
+	return false, 0, errors.New("store unavailable")
+}
+
+func TestLimiter(t *testing.T) {
+	cfg := conf.RateLimitConf{
+		Tenant:    &conf.RateLimitRuleConf{Rate: 1},
+		Tenants:   map[string]conf.RateLimitRuleConf{"BIG": {Rate: 1, Burst: 3}},
+		Resources: map[string]conf.RateLimitRuleConf{"hero": {Rate: 1}},
+	}
+	l := NewWithStore(test.NoOpLogger, cfg, newMemoryStore())
+	ctx := context.Background()
+
+	allowed, _ := l.AllowTenant(ctx, "SMALL")
+	test.Equal(t, allowed, true)
+	allowed, _ = l.AllowTenant(ctx, "SMALL")
+	test.Equal(t, allowed, false)
+
+	for i := 0; i < 3; i++ {
+		allowed, _ = l.AllowTenant(ctx, "BIG")
+		test.Equal(t, allowed, true)
+	}
+
+	allowed, _ = l.AllowResource(ctx, "SMALL", "hero")
+	test.Equal(t, allowed, true)
+	allowed, _ = l.AllowResource(ctx, "BIG", "hero")
+	test.Equal(t, allowed, true)
+	allowed, _ = l.AllowResource(ctx, "BIG", "hero")
+	test.Equal(t, allowed, false)
+
+	for i := 0; i < 3; i++ {
+		allowed, _ = l.AllowResource(ctx, "BIG", "sidekick")
+		test.Equal(t, allowed, true)
+		allowed, _ = l.AllowClient(ctx, "app")
+		test.Equal(t, allowed, true)
+	}
+
+	_, _, err := l.AllowTenantN(ctx, "BIG", 4)
+	test.Equal(t, errors.Is(err, ErrCostExceedsBurst), true)
+	allowed, _, err = l.AllowClientN(ctx, "web", 1)
+	test.VerifyError(t, err)
+	test.Equal(t, allowed, true)
+
+	failing := NewWithStore(test.NoOpLogger, cfg, failingStore{})
+	allowed, _ = failing.AllowTenant(ctx, "SMALL")
+	test.Equal(t, allowed, true)
+
+	var disabled *Limiter
+	allowed, _ = disabled.AllowTenant(ctx, "SMALL")
+	test.Equal(t, allowed, true)
+}
+
+func TestLimiterBuckets(t *testing.T) {
+	cfg := conf.RateLimitConf{
+		Tenant:    &conf.RateLimitRuleConf{Rate: 0.001, Burst: 5},
+		Resources: map[string]conf.RateLimitRuleConf{"hero": {Rate: 0.001, Burst: 2}},
+	}
+	l := NewWithStore(test.NoOpLogger, cfg, newMemoryStore())
+	ctx := context.Background()
+
+	l.AllowTenant(ctx, "DEFAULT")
+	l.AllowTenant(ctx, "DEFAULT")
+	l.AllowResource(ctx, "DEFAULT", "hero")
+
+	buckets, err := l.Buckets(ctx)
+	test.VerifyError(t, err)
+	test.Equal(t, len(buckets), 2)
+	test.Equal(t, buckets[0].Key, "resource:DEFAULT:hero")
+	test.Equal(t, buckets[0].Burst, 2)
+	test.Equal(t, math.Floor(buckets[0].Tokens), float64(1))
+	test.Equal(t, buckets[1].Key, "tenant:DEFAULT")
+	test.Equal(t, buckets[1].Burst, 5)
+	test.Equal(t, math.Floor(buckets[1].Tokens), float64(3))
+
+	var disabled *Limiter
+	buckets, err = disabled.Buckets(ctx)
+	test.VerifyError(t, err)
+	test.Equal(t, len(buckets), 0)
+}
+
+func TestRedisStore(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	test.VerifyError(t, err)
+	defer listener.Close()
+
+	commands := make(chan []string, 10)
+	go serveRedis(listener, commands)
+
+	store := newRedisStore(conf.RedisConf{Addr: listener.Addr().String(), Password: "s3cr3t", DB: 2, Timeout: time.Second})
+
+	allowed, wait, err := store.Take(context.Background(), "tenant:DEFAULT", Rule{Rate: 1.5, Burst: 2}, 1)
+	test.VerifyError(t, err)
+	test.Equal(t, allowed, false)
+	test.Equal(t, wait, 250*time.Millisecond)
+
+	test.Equal(t, <-commands, []string{"AUTH", "s3cr3t"})
+	test.Equal(t, <-commands, []string{"SELECT", "2"})
+
+	evalsha := <-commands
+	test.Equal(t, evalsha[:4], []string{"EVALSHA", tokenBucketSHA, "1", "restql:ratelimit:tenant:DEFAULT"})
+	test.Equal(t, evalsha[4:6], []string{"1.5", "2"})
+	test.Equal(t, evalsha[7], "1")
+
+	eval := <-commands
+	test.Equal(t, eval[:2], []string{"EVAL", tokenBucketScript})
+
+	_, _, err = store.Take(context.Background(), "tenant:DEFAULT", Rule{Rate: 1.5, Burst: 2}, 1)
+	test.VerifyError(t, err)
+	test.Equal(t, (<-commands)[0], "EVALSHA")
+
+	err = store.Ping(context.Background())
+	test.VerifyError(t, err)
+	test.Equal(t, <-commands, []string{"PING"})
+
+	buckets, err := store.list(context.Background())
+	test.VerifyError(t, err)
+	test.Equal(t, len(buckets), 1)
+	test.Equal(t, buckets[0].key, "tenant:DEFAULT")
+	test.Equal(t, buckets[0].tokens, 0.5)
+	test.Equal(t, buckets[0].updated.Equal(time.Unix(1601553600, 0)), true)
+	test.Equal(t, <-commands, []string{"SCAN", "0", "MATCH", "restql:ratelimit:*", "COUNT", "100"})
+	test.Equal(t, <-commands, []string{"HMGET", "restql:ratelimit:tenant:DEFAULT", "tokens", "updated"})
+}
+
+// serveRedis answers the first script evaluation by hash with
+// a NOSCRIPT error and every other one with a refused token,
+// listing a single bucket to scans.
+func serveRedis(listener net.Listener, commands chan []string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	loaded := false
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+
+		var args []string
+		for _, a := range reply.([]interface{}) {
+			args = append(args, a.(string))
+		}
+		commands <- args
+
+		switch {
+		case args[0] == "EVALSHA" && !loaded:
+			loaded = true
+			conn.Write([]byte("-NOSCRIPT No matching script\r\n"))
+		case strings.HasPrefix(args[0], "EVAL"):
+			conn.Write([]byte("*2\r\n:0\r\n:250\r\n"))
+		case args[0] == "SCAN":
+			conn.Write([]byte("*2\r\n$1\r\n0\r\n*1\r\n$31\r\nrestql:ratelimit:tenant:DEFAULT\r\n"))
+		case args[0] == "HMGET":
+			conn.Write([]byte("*2\r\n$3\r\n0.5\r\n$13\r\n1601553600000\r\n"))
+		default:
+			conn.Write([]byte("+OK\r\n"))
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package ratelimit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/pkg/errors"
+)
+
+const (
+	redisKeyPrefix       = "restql:ratelimit:"
+	defaultRedisTimeout  = 100 * time.Millisecond
+	defaultRedisPoolSize = 16
+)
+
+// tokenBucketScript refills and takes the cost in tokens from the
+// bucket atomically, returning if they were taken and the
+// milliseconds until they are available otherwise.
+const tokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4]) or 1
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(bucket[1]) or burst
+local updated = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rate / 1000)
+local allowed = 0
+local wait = 0
+if tokens >= cost then
+  tokens = tokens - cost
+  allowed = 1
+else
+  wait = math.ceil((cost - tokens) * 1000 / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, wait}
+`
+
+var tokenBucketSHA = func() string {
+	sum := sha1.Sum([]byte(tokenBucketScript))
+	return hex.EncodeToString(sum[:])
+}()
+
+var errRedisProtocol = errors.New("invalid redis reply")
+
+// redisError is an error reply sent by the server,
+// after which the connection can still be used.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// redisStore keeps the buckets in a Redis server, sharing them
+// between restQL instances. It speaks the RESP protocol over a
+// small pool of connections.
+type redisStore struct {
+	addr     string
+	password string
+	db       int
+	timeout  time.Duration
+	conns    chan *redisConn
+}
+
+func newRedisStore(cfg conf.RedisConf) *redisStore {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultRedisTimeout
+	}
+
+	poolSize := cfg.PoolSize
+	if poolSize <= 0 {
+		poolSize = defaultRedisPoolSize
+	}
+
+	return &redisStore{
+		addr:     cfg.Addr,
+		password: cfg.Password,
+		db:       cfg.DB,
+		timeout:  timeout,
+		conns:    make(chan *redisConn, poolSize),
+	}
+}
+
+func (s *redisStore) Take(ctx context.Context, key string, rule Rule, cost int) (bool, time.Duration, error) {
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	c, err := s.acquire(deadline)
+	if err != nil {
+		return false, 0, err
+	}
+
+	now := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+	rate := strconv.FormatFloat(rule.Rate, 'f', -1, 64)
+	burst := strconv.Itoa(rule.Burst)
+	tokens := strconv.Itoa(cost)
+
+	reply, err := c.do(deadline, "EVALSHA", tokenBucketSHA, "1", redisKeyPrefix+key, rate, burst, now, tokens)
+	if re, ok := err.(redisError); ok && strings.HasPrefix(string(re), "NOSCRIPT") {
+		reply, err = c.do(deadline, "EVAL", tokenBucketScript, "1", redisKeyPrefix+key, rate, burst, now, tokens)
+	}
+	s.release(c, err)
+	if err != nil {
+		return false, 0, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, 0, errRedisProtocol
+	}
+
+	allowed, _ := values[0].(int64)
+	wait, _ := values[1].(int64)
+
+	return allowed == 1, time.Duration(wait) * time.Millisecond, nil
+}
+
+// Ping verifies that the Redis server is reachable.
+func (s *redisStore) Ping(ctx context.Context) error {
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	c, err := s.acquire(deadline)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(deadline, "PING")
+	s.release(c, err)
+
+	return err
+}
+
+// list scans the keys of the buckets, reading the state of each.
+func (s *redisStore) list(ctx context.Context) ([]storedBucket, error) {
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	c, err := s.acquire(deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := scanBuckets(c, deadline)
+	s.release(c, err)
+
+	return result, err
+}
+
+func scanBuckets(c *redisConn, deadline time.Time) ([]storedBucket, error) {
+	var result []storedBucket
+	cursor := "0"
+	for {
+		reply, err := c.do(deadline, "SCAN", cursor, "MATCH", redisKeyPrefix+"*", "COUNT", "100")
+		if err != nil {
+			return nil, err
+		}
+
+		page, ok := reply.([]interface{})
+		if !ok || len(page) != 2 {
+			return nil, errRedisProtocol
+		}
+		cursor, _ = page[0].(string)
+		keys, _ := page[1].([]interface{})
+
+		for _, k := range keys {
+			key, _ := k.(string)
+			reply, err := c.do(deadline, "HMGET", key, "tokens", "updated")
+			if err != nil {
+				return nil, err
+			}
+
+			fields, ok := reply.([]interface{})
+			if !ok || len(fields) != 2 || fields[0] == nil || fields[1] == nil {
+				continue
+			}
+			tokens, err := strconv.ParseFloat(fields[0].(string), 64)
+			if err != nil {
+				return nil, errRedisProtocol
+			}
+			updated, err := strconv.ParseInt(fields[1].(string), 10, 64)
+			if err != nil {
+				return nil, errRedisProtocol
+			}
+
+			result = append(result, storedBucket{
+				key:     strings.TrimPrefix(key, redisKeyPrefix),
+				tokens:  tokens,
+				updated: time.Unix(0, updated*int64(time.Millisecond)),
+			})
+		}
+
+		if cursor == "0" || cursor == "" {
+			return result, nil
+		}
+	}
+}
+
+func (s *redisStore) acquire(deadline time.Time) (*redisConn, error) {
+	select {
+	case c := <-s.conns:
+		return c, nil
+	default:
+	}
+
+	conn, err := net.DialTimeout("tcp", s.addr, time.Until(deadline))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to redis")
+	}
+
+	c := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+	if s.password != "" {
+		if _, err := c.do(deadline, "AUTH", s.password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	if s.db != 0 {
+		if _, err := c.do(deadline, "SELECT", strconv.Itoa(s.db)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// release returns the connection to the pool, unless
+// it failed for a reason other than an error reply.
+func (s *redisStore) release(c *redisConn, err error) {
+	if _, ok := err.(redisError); err != nil && !ok {
+		c.conn.Close()
+		return
+	}
+
+	select {
+	case s.conns <- c:
+	default:
+		c.conn.Close()
+	}
+}
+
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (c *redisConn) do(deadline time.Time, args ...string) (interface{}, error) {
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if _, err := c.conn.Write(encodeCommand(args)); err != nil {
+		return nil, err
+	}
+
+	return readReply(c.r)
+}
+
+func encodeCommand(args []string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&buf, "$%d\r\n%s\r\n", len(a), a)
+	}
+	return buf.Bytes()
+}
+
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errRedisProtocol
+	}
+
+	payload := line[1 : len(line)-2]
+	switch line[0] {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, redisError(payload)
+	case ':':
+		n, err := strconv.ParseInt(payload, 10, 64)
+		if err != nil {
+			return nil, errRedisProtocol
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, errRedisProtocol
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, errRedisProtocol
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		// error replies nested in the array are only returned
+		// once it is fully read, keeping the connection usable
+		var replyErr error
+		items := make([]interface{}, n)
+		for i := range items {
+			items[i], err = readReply(r)
+			if _, ok := err.(redisError); ok {
+				replyErr = err
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		if replyErr != nil {
+			return nil, replyErr
+		}
+		return items, nil
+	default:
+		return nil, errRedisProtocol
+	}
+}
@@ -2,10 +2,20 @@ package web
 
 import (
 	"encoding/json"
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/bulkhead"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/cache"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/httpclient"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/openapi"
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/persistence"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/ratelimit"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web/middleware"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
 	"github.com/valyala/fasthttp"
 	"strconv"
+	"strings"
 )
 
 type queryRevision struct {
@@ -27,15 +37,139 @@ type mapping struct {
 	Source string `json:"source"`
 }
 
+type mappingHealth struct {
+	URL               string `json:"url"`
+	ActiveConnections int64  `json:"activeConnections"`
+	*runner.ResourceHealth
+}
+
+type runtimeState struct {
+	Executions []runner.ExecutionSnapshot `json:"executions"`
+	Caches     map[string]cache.Stats     `json:"caches"`
+	RateLimits []ratelimit.BucketLevel    `json:"rateLimits,omitempty"`
+	Bulkheads  map[string]bulkhead.Stats  `json:"bulkheads,omitempty"`
+}
+
 type administrator struct {
 	mr          persistence.MappingsReader
 	mw          persistence.MappingsWriter
 	qr          persistence.QueryReader
 	queryWriter persistence.QueryWriter
+	runner      runner.Runner
+	evaluator   eval.Evaluator
+	tester      QueryTester
+	responses   *cache.ResponseCache
+	faults      *middleware.FaultInjection
+	limiter     *ratelimit.Limiter
+	bulkhead    *bulkhead.Bulkhead
 }
 
-func newAdmin(mr persistence.MappingsReader, mw persistence.MappingsWriter, qr persistence.QueryReader, qw persistence.QueryWriter) *administrator {
-	return &administrator{mr: mr, mw: mw, qr: qr, queryWriter: qw}
+func newAdmin(mr persistence.MappingsReader, mw persistence.MappingsWriter, qr persistence.QueryReader, qw persistence.QueryWriter, r runner.Runner, e eval.Evaluator, qt QueryTester, rc *cache.ResponseCache, fi *middleware.FaultInjection, rl *ratelimit.Limiter, bh *bulkhead.Bulkhead) *administrator {
+	return &administrator{mr: mr, mw: mw, qr: qr, queryWriter: qw, runner: r, evaluator: e, tester: qt, responses: rc, faults: fi, limiter: rl, bulkhead: bh}
+}
+
+func (adm *administrator) RuntimeState(ctx *fasthttp.RequestCtx) error {
+	state := runtimeState{
+		Executions: adm.runner.ActiveExecutions(),
+		Caches:     cache.AllStats(),
+		Bulkheads:  adm.bulkhead.Stats(),
+	}
+
+	buckets, err := adm.limiter.Buckets(ctx)
+	if err != nil {
+		restql.GetLogger(ctx).Warn("failed to list rate limit buckets", "error", err)
+	}
+	state.RateLimits = buckets
+
+	return Respond(ctx, state, fasthttp.StatusOK, nil)
+}
+
+var errEmptyInvalidation = errors.New("invalid invalidation : at least one of resource, url or tag must be provided")
+
+type invalidationResponse struct {
+	Invalidated int `json:"invalidated"`
+}
+
+// InvalidateResponses purges the upstream responses kept in the
+// response cache, selected by the resource, url and tag query args.
+func (adm *administrator) InvalidateResponses(ctx *fasthttp.RequestCtx) error {
+	args := ctx.QueryArgs()
+	ri := cache.ResponseInvalidation{
+		Resource:   string(args.Peek("resource")),
+		URLPattern: string(args.Peek("url")),
+		Tag:        string(args.Peek("tag")),
+	}
+	if ri == (cache.ResponseInvalidation{}) {
+		return RespondError(ctx, errEmptyInvalidation, errToStatusCode)
+	}
+
+	invalidated := adm.responses.Invalidate(ri)
+	restql.GetLogger(ctx).Info("response cache invalidated", "resource", ri.Resource, "url", ri.URLPattern, "tag", ri.Tag, "invalidated", invalidated)
+
+	return Respond(ctx, invalidationResponse{Invalidated: invalidated}, fasthttp.StatusOK, nil)
+}
+
+type profilingState struct {
+	Labels          bool     `json:"labels"`
+	TraceSampleRate *float64 `json:"traceSampleRate"`
+}
+
+func (adm *administrator) Profiling(ctx *fasthttp.RequestCtx) error {
+	return Respond(ctx, adm.profilingState(), fasthttp.StatusOK, nil)
+}
+
+func (adm *administrator) UpdateProfiling(ctx *fasthttp.RequestCtx) error {
+	var body profilingState
+	err := json.Unmarshal(ctx.PostBody(), &body)
+	if err != nil || body.TraceSampleRate == nil {
+		return RespondError(ctx, errFailedToReadRequestBody, errToStatusCode)
+	}
+
+	err = adm.runner.Profiler().SetTraceSampleRate(*body.TraceSampleRate)
+	if err != nil {
+		return RespondError(ctx, err, errToStatusCode)
+	}
+
+	return Respond(ctx, adm.profilingState(), fasthttp.StatusOK, nil)
+}
+
+func (adm *administrator) profilingState() profilingState {
+	profiler := adm.runner.Profiler()
+	rate := profiler.TraceSampleRate()
+
+	return profilingState{Labels: profiler.LabelsEnabled(), TraceSampleRate: &rate}
+}
+
+type faultInjectionState struct {
+	Allowed bool  `json:"allowed"`
+	Enabled *bool `json:"enabled"`
+}
+
+func (adm *administrator) FaultInjection(ctx *fasthttp.RequestCtx) error {
+	return Respond(ctx, adm.faultInjectionState(), fasthttp.StatusOK, nil)
+}
+
+// UpdateFaultInjection switches the injection of the mapping
+// faults in every query, which must be allowed by configuration.
+func (adm *administrator) UpdateFaultInjection(ctx *fasthttp.RequestCtx) error {
+	var body faultInjectionState
+	err := json.Unmarshal(ctx.PostBody(), &body)
+	if err != nil || body.Enabled == nil {
+		return RespondError(ctx, errFailedToReadRequestBody, errToStatusCode)
+	}
+
+	err = adm.faults.SetEnabled(*body.Enabled)
+	if err != nil {
+		return RespondError(ctx, err, errToStatusCode)
+	}
+	restql.GetLogger(ctx).Warn("fault injection switched", "enabled", *body.Enabled)
+
+	return Respond(ctx, adm.faultInjectionState(), fasthttp.StatusOK, nil)
+}
+
+func (adm *administrator) faultInjectionState() faultInjectionState {
+	enabled := adm.faults.Enabled()
+	return faultInjectionState{Allowed: adm.faults.Allowed(), Enabled: &enabled}
 }
 
 func (adm *administrator) AllTenants(ctx *fasthttp.RequestCtx) error {
@@ -81,6 +215,39 @@ func (adm *administrator) TenantMappings(ctx *fasthttp.RequestCtx) error {
 	return Respond(ctx, data, fasthttp.StatusOK, nil)
 }
 
+// TenantHealth summarizes the live health of each mapping of the tenant,
+// from the most recent responses of its resource and the connections
+// in use to its host, to help triage upstream incidents.
+func (adm *administrator) TenantHealth(ctx *fasthttp.RequestCtx) error {
+	log := restql.GetLogger(ctx)
+
+	tenantName, err := pathParamString(ctx, "tenantName")
+	if err != nil {
+		log.Error("failed to load tenant name path param", err)
+		return err
+	}
+
+	mappings, err := adm.mr.FromTenant(ctx, tenantName)
+	if err != nil {
+		return RespondError(ctx, err, errToStatusCode)
+	}
+
+	ms := make(map[string]mappingHealth)
+	for resourceName, m := range mappings {
+		ms[resourceName] = mappingHealth{
+			URL:               m.URL(),
+			ActiveConnections: httpclient.ActiveConnections(m.Host()),
+			ResourceHealth:    adm.runner.ResourceHealth(tenantName, resourceName),
+		}
+	}
+
+	data := map[string]interface{}{
+		"tenant":   tenantName,
+		"mappings": ms,
+	}
+	return Respond(ctx, data, fasthttp.StatusOK, nil)
+}
+
 func (adm administrator) AllNamespaces(ctx *fasthttp.RequestCtx) error {
 	namespaces, err := adm.qr.ListNamespaces(ctx)
 	if err != nil {
@@ -210,6 +377,167 @@ func (adm *administrator) Query(ctx *fasthttp.RequestCtx) error {
 	return Respond(ctx, data, fasthttp.StatusOK, nil)
 }
 
+type queryTestsResponse struct {
+	Passed  bool              `json:"passed"`
+	Results []QueryTestResult `json:"results"`
+}
+
+func (adm *administrator) TestQuery(ctx *fasthttp.RequestCtx) error {
+	log := restql.GetLogger(ctx)
+
+	namespace, err := pathParamString(ctx, "namespace")
+	if err != nil {
+		log.Error("failed to load namespace path param", err)
+		return err
+	}
+
+	queryName, err := pathParamString(ctx, "queryId")
+	if err != nil {
+		log.Error("failed to load query name path param", err)
+		return err
+	}
+
+	nativeCtx := restql.WithLogger(middleware.GetNativeContext(ctx), log)
+	results := adm.tester.Run(nativeCtx, namespace, queryName)
+
+	passed := true
+	for _, r := range results {
+		passed = passed && r.Passed
+	}
+
+	return Respond(ctx, queryTestsResponse{Passed: passed, Results: results}, fasthttp.StatusOK, nil)
+}
+
+// DiffQueryRevisions compares the results of two revisions of a saved
+// query, executed against the mapped resources or replaying a cassette.
+func (adm *administrator) DiffQueryRevisions(ctx *fasthttp.RequestCtx) error {
+	log := restql.GetLogger(ctx)
+
+	namespace, err := pathParamString(ctx, "namespace")
+	if err != nil {
+		log.Error("failed to load namespace path param", err)
+		return err
+	}
+
+	queryName, err := pathParamString(ctx, "queryId")
+	if err != nil {
+		log.Error("failed to load query name path param", err)
+		return err
+	}
+
+	var body RevisionDiff
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil {
+		return RespondError(ctx, errFailedToReadRequestBody, errToStatusCode)
+	}
+
+	nativeCtx := restql.WithLogger(middleware.GetNativeContext(ctx), log)
+	response, err := adm.tester.DiffRevisions(nativeCtx, adm.evaluator, namespace, queryName, body)
+	if err != nil {
+		log.Error("failed to diff query revisions", err)
+		return RespondError(ctx, err, errToStatusCode)
+	}
+
+	return Respond(ctx, response, fasthttp.StatusOK, nil)
+}
+
+type queryExecutionsResponse struct {
+	Executions []eval.RecordedExecution `json:"executions"`
+}
+
+func (adm *administrator) QueryExecutions(ctx *fasthttp.RequestCtx) error {
+	log := restql.GetLogger(ctx)
+
+	namespace, err := pathParamString(ctx, "namespace")
+	if err != nil {
+		log.Error("failed to load namespace path param", err)
+		return err
+	}
+
+	queryName, err := pathParamString(ctx, "queryId")
+	if err != nil {
+		log.Error("failed to load query name path param", err)
+		return err
+	}
+
+	executions := adm.evaluator.RecordedExecutions(namespace, queryName)
+	if executions == nil {
+		executions = []eval.RecordedExecution{}
+	}
+
+	return Respond(ctx, queryExecutionsResponse{Executions: executions}, fasthttp.StatusOK, nil)
+}
+
+func (adm *administrator) RenderQueryExecution(ctx *fasthttp.RequestCtx) error {
+	log := restql.GetLogger(ctx)
+
+	namespace, err := pathParamString(ctx, "namespace")
+	if err != nil {
+		log.Error("failed to load namespace path param", err)
+		return err
+	}
+
+	queryName, err := pathParamString(ctx, "queryId")
+	if err != nil {
+		log.Error("failed to load query name path param", err)
+		return err
+	}
+
+	executionID, err := pathParamString(ctx, "executionId")
+	if err != nil {
+		log.Error("failed to load execution id path param", err)
+		return err
+	}
+
+	nativeCtx := restql.WithLogger(middleware.GetNativeContext(ctx), log)
+	result, err := adm.evaluator.RenderExecution(nativeCtx, namespace, queryName, executionID, string(ctx.PostBody()))
+	if err != nil {
+		log.Error("failed to render recorded execution", err)
+		return RespondError(ctx, err, errToStatusCode)
+	}
+
+	response, err := MakeQueryResponse(result, DebugOptions{})
+	if err != nil {
+		return RespondError(ctx, err, errToStatusCode)
+	}
+
+	return Respond(ctx, response.Body, response.StatusCode, nil)
+}
+
+// Content types of the generated query clients.
+var clientContentTypes = map[string]string{
+	TypeScriptClient: "application/typescript; charset=utf-8",
+	GoClient:         "text/x-go; charset=utf-8",
+	OpenAPIDocument:  "application/json; charset=utf-8",
+}
+
+func (adm *administrator) GenerateClient(ctx *fasthttp.RequestCtx) error {
+	log := restql.GetLogger(ctx)
+
+	namespace, err := pathParamString(ctx, "namespace")
+	if err != nil {
+		log.Error("failed to load namespace path param", err)
+		return err
+	}
+
+	language := string(ctx.QueryArgs().Peek("lang"))
+	if language == "" {
+		language = TypeScriptClient
+	}
+	pkg := string(ctx.QueryArgs().Peek("package"))
+
+	nativeCtx := restql.WithLogger(middleware.GetNativeContext(ctx), log)
+	code, err := NewClientGenerator(log, adm.qr, adm.tester).Generate(nativeCtx, namespace, language, pkg)
+	if err != nil {
+		log.Error("failed to generate query client", err)
+		return RespondError(ctx, err, errToStatusCode)
+	}
+
+	ctx.Response.Header.SetContentType(clientContentTypes[language])
+	ctx.Response.SetStatusCode(fasthttp.StatusOK)
+	ctx.Response.SetBodyString(code)
+	return nil
+}
+
 type mapResourceBody struct {
 	Url string `json:"url"`
 }
@@ -245,6 +573,82 @@ func (adm *administrator) MapResource(ctx *fasthttp.RequestCtx) error {
 	return Respond(ctx, nil, fasthttp.StatusCreated, nil)
 }
 
+var errInvalidMappingImport = errors.New("invalid mapping import : an http or https document url must be provided")
+
+type importMappingsBody struct {
+	URL     string `json:"url"`
+	Prefix  string `json:"prefix"`
+	BaseURL string `json:"baseUrl"`
+}
+
+type importMappingsResponse struct {
+	Mappings []openapi.Mapping `json:"mappings"`
+}
+
+// ImportMappings generates the mappings of the paths of the OpenAPI
+// document fetched from the given URL and writes them to the tenant.
+// The default headers found on the document are only returned, as
+// they are not persisted and must be added to the configuration.
+func (adm *administrator) ImportMappings(ctx *fasthttp.RequestCtx) error {
+	log := restql.GetLogger(ctx)
+
+	tenantName, err := pathParamString(ctx, "tenantName")
+	if err != nil {
+		log.Error("failed to load tenant name path param", err)
+		return err
+	}
+
+	var body importMappingsBody
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil {
+		return RespondError(ctx, errFailedToReadRequestBody, errToStatusCode)
+	}
+	// Documents are only fetched from upstreams, never read from local files.
+	if !strings.HasPrefix(body.URL, "http://") && !strings.HasPrefix(body.URL, "https://") {
+		return RespondError(ctx, errInvalidMappingImport, errToStatusCode)
+	}
+
+	mappings, err := openapi.Sync(ctx, &adm.mw, tenantName, body.URL, openapi.Options{Prefix: body.Prefix, BaseURL: body.BaseURL})
+	if err != nil {
+		log.Error("failed to import mappings", err, "tenant", tenantName, "url", body.URL)
+		return RespondError(ctx, err, errToStatusCode)
+	}
+
+	return Respond(ctx, importMappingsResponse{Mappings: mappings}, fasthttp.StatusCreated, nil)
+}
+
+type previewHeadersBody struct {
+	Headers map[string]string `json:"headers"`
+}
+
+// PreviewHeaders returns the headers a statement of the resource would
+// send upstream for the given client headers, with the ones forwarded,
+// dropped and injected by the configured rules.
+func (adm *administrator) PreviewHeaders(ctx *fasthttp.RequestCtx) error {
+	log := restql.GetLogger(ctx)
+
+	tenantName, err := pathParamString(ctx, "tenantName")
+	if err != nil {
+		log.Error("failed to load tenant name path param", err)
+		return err
+	}
+
+	resourceName, err := pathParamString(ctx, "resource")
+	if err != nil {
+		log.Error("failed to load resource name path param", err)
+		return err
+	}
+
+	var body previewHeadersBody
+	if len(ctx.PostBody()) > 0 {
+		if err := json.Unmarshal(ctx.PostBody(), &body); err != nil {
+			return RespondError(ctx, errFailedToReadRequestBody, errToStatusCode)
+		}
+	}
+
+	preview := adm.runner.PreviewHeaders(tenantName, resourceName, body.Headers)
+	return Respond(ctx, preview, fasthttp.StatusOK, nil)
+}
+
 type createRevisionBody struct {
 	Text string `json:"text"`
 }
@@ -0,0 +1,243 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web/middleware"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	errInvalidBatch  = errors.New("invalid batch")
+	errBatchTooLarge = errors.New("invalid batch : too many queries")
+)
+
+// deduplicatedRequestsHeader informs how many upstream requests of
+// the batch were not made, sharing the response of an identical one.
+const deduplicatedRequestsHeader = "X-Restql-Deduplicated-Requests"
+
+// BatchRequest represents the queries executed together by the
+// batch endpoint, keyed by the name their results are returned
+// under, where Params are shared by every query.
+type BatchRequest struct {
+	Params  map[string]interface{} `json:"params"`
+	Queries map[string]BatchQuery  `json:"queries"`
+}
+
+// BatchQuery represents a query of a batch, either an ad-hoc one
+// given by Text or a saved one identified by Namespace, ID and
+// Revision, which is a number or a revision alias. Its Params
+// take precedence over the ones shared by the batch.
+type BatchQuery struct {
+	Text      string                 `json:"text"`
+	Namespace string                 `json:"namespace"`
+	ID        string                 `json:"id"`
+	Revision  interface{}            `json:"revision"`
+	Params    map[string]interface{} `json:"params"`
+}
+
+type batchResult struct {
+	StatusCode int         `json:"statusCode"`
+	Error      string      `json:"error,omitempty"`
+	Result     interface{} `json:"result,omitempty"`
+}
+
+// RunQueries executes the independent queries of a batch concurrently,
+// sharing the responses of identical upstream requests between them,
+// and returns their results keyed by the name given to each query.
+// Failed queries do not fail the batch, having their status and
+// error returned in place of the result, but the whole batch is
+// refused when any of them is not allowed to the principal.
+func (r restQl) RunQueries(reqCtx *fasthttp.RequestCtx) error {
+	ctx := middleware.GetNativeContext(reqCtx)
+	log := requestLogger(ctx, r.log)
+	ctx = restql.WithLogger(ctx, log)
+
+	tenant, err := makeTenant(reqCtx, r.config.Tenant)
+	if err != nil {
+		log.Error("failed to build query options", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	batch, err := ParseBatchRequest(reqCtx.PostBody(), r.config.HTTP.Server.Batch.MaxQueries)
+	if err != nil {
+		log.Debug("invalid batch request", "error", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	if err := authorizeBatch(ctx, batch); err != nil {
+		log.Debug("batch request refused by namespace authorization", "error", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	input, err := makeQueryInput(reqCtx, log)
+	if err != nil {
+		log.Error("failed to build query input", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+	input.Body = nil
+
+	ctx, err = withSeed(ctx, input)
+	if err != nil {
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	statusPolicy, err := r.queryStatusPolicy(input)
+	if err != nil {
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	dedup := runner.NewRequestDeduplication(ctx)
+	ctx = runner.WithRequestDeduplication(ctx, dedup)
+
+	results := make(map[string]batchResult, len(batch.Queries))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, query := range batch.Queries {
+		name, query := name, query
+		queryInput := batchQueryInput(input, batch.Params, query.Params)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := r.runBatchQuery(ctx, tenant, query, queryInput, statusPolicy)
+
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	headers := map[string]string{deduplicatedRequestsHeader: strconv.Itoa(dedup.Shared())}
+	return Respond(reqCtx, results, http.StatusOK, headers)
+}
+
+func (r restQl) runBatchQuery(ctx context.Context, tenant string, query BatchQuery, input restql.QueryInput, statusPolicy string) batchResult {
+	log := restql.GetLogger(ctx)
+	ctx = eval.WithWarnings(ctx)
+	if isDebugEnabled(input) {
+		ctx = runner.WithTimeline(ctx)
+	}
+
+	var result domain.Resources
+	var err error
+	toStatusCode := errToStatusCode
+	if query.Text != "" {
+		result, err = r.evaluator.AdHocQuery(ctx, query.Text, restql.QueryOptions{Tenant: tenant}, input)
+
+		toStatusCode = make(map[error]int)
+		for err, status := range errToStatusCode {
+			toStatusCode[err] = status
+		}
+		toStatusCode[eval.ErrParser] = http.StatusBadRequest
+	} else {
+		var options restql.QueryOptions
+		options, err = r.batchQueryOptions(tenant, query)
+		if err == nil {
+			result, err = r.evaluator.SavedQuery(ctx, options, input)
+		}
+	}
+	if err != nil {
+		log.Debug("failed to evaluate batch query", "error", err)
+		return batchResult{StatusCode: findStatusCode(toStatusCode, err), Error: err.Error()}
+	}
+
+	response, err := MakeQueryResponse(result, r.debugOptions(ctx, input))
+	if err != nil {
+		return batchResult{StatusCode: findStatusCode(errToStatusCode, err), Error: err.Error()}
+	}
+
+	return batchResult{
+		StatusCode: ApplyStatusPolicy(statusPolicy, response.StatusCode),
+		Result:     genericBody(response.Body, eval.Warnings(ctx)),
+	}
+}
+
+func (r restQl) batchQueryOptions(tenant string, query BatchQuery) (restql.QueryOptions, error) {
+	revisionStr := fmt.Sprintf("%v", query.Revision)
+	revision, err := ResolveRevision(r.config.RevisionAliases[query.Namespace][query.ID], revisionStr, rollCanary())
+	if err != nil {
+		return restql.QueryOptions{}, err
+	}
+
+	return restql.QueryOptions{Namespace: query.Namespace, Id: query.ID, Revision: revision, Tenant: tenant}, nil
+}
+
+// ParseBatchRequest decodes the batch, checking that it has at most
+// the maximum number of queries, when positive, and that each one
+// is either an ad-hoc query or identifies a saved query.
+func ParseBatchRequest(body []byte, maxQueries int) (BatchRequest, error) {
+	var batch BatchRequest
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return BatchRequest{}, fmt.Errorf("%w: %s", errFailedToReadRequestBody, err)
+	}
+
+	if len(batch.Queries) == 0 {
+		return BatchRequest{}, fmt.Errorf("%w : no queries provided", errInvalidBatch)
+	}
+	if maxQueries > 0 && len(batch.Queries) > maxQueries {
+		return BatchRequest{}, fmt.Errorf("%w, the maximum is %d", errBatchTooLarge, maxQueries)
+	}
+
+	for name, query := range batch.Queries {
+		adHoc := query.Text != ""
+		saved := query.Namespace != "" && query.ID != "" && query.Revision != nil
+		if adHoc == saved {
+			return BatchRequest{}, fmt.Errorf("%w : query %s must have either a text or a namespace, id and revision", errInvalidBatch, name)
+		}
+	}
+
+	return batch, nil
+}
+
+// authorizeBatch checks that the principal can run every query of
+// the batch, where ad-hoc ones require access to every namespace.
+func authorizeBatch(ctx context.Context, batch BatchRequest) error {
+	names := make([]string, 0, len(batch.Queries))
+	for name := range batch.Queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		query := batch.Queries[name]
+
+		var err error
+		if query.Text != "" {
+			err = middleware.AuthorizeAdHocQuery(ctx)
+		} else {
+			err = middleware.AuthorizeNamespace(ctx, query.Namespace)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "query %s", name)
+		}
+	}
+
+	return nil
+}
+
+// batchQueryInput returns the input of a query of the batch, where
+// its own params take precedence over the ones shared by the batch,
+// which take precedence over the request query arguments.
+func batchQueryInput(input restql.QueryInput, shared map[string]interface{}, own map[string]interface{}) restql.QueryInput {
+	params := make(map[string]interface{}, len(input.Params)+len(shared)+len(own))
+	for _, p := range []map[string]interface{}{input.Params, shared, own} {
+		for key, value := range p {
+			params[key] = value
+		}
+	}
+
+	return restql.QueryInput{Params: params, Headers: input.Headers}
+}
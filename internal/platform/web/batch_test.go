@@ -0,0 +1,54 @@
+package web_test
+
+import (
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestParseBatchRequest(t *testing.T) {
+	body := `{
+		"params": {"id": 1},
+		"queries": {
+			"heroes": {"text": "from hero with id = $id"},
+			"villains": {"namespace": "dc", "id": "villains", "revision": 2, "params": {"id": 2}}
+		}
+	}`
+
+	batch, err := web.ParseBatchRequest([]byte(body), 2)
+	test.VerifyError(t, err)
+
+	expected := web.BatchRequest{
+		Params: map[string]interface{}{"id": float64(1)},
+		Queries: map[string]web.BatchQuery{
+			"heroes":   {Text: "from hero with id = $id"},
+			"villains": {Namespace: "dc", ID: "villains", Revision: float64(2), Params: map[string]interface{}{"id": float64(2)}},
+		},
+	}
+	test.Equal(t, batch, expected)
+}
+
+func TestParseBatchRequestError(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"malformed body", `{"queries": [`, "failed to read and unmarshal request body: unexpected end of JSON input"},
+		{"no queries", `{"queries": {}}`, "invalid batch : no queries provided"},
+		{"too many queries", `{"queries": {"a": {"text": "from a"}, "b": {"text": "from b"}, "c": {"text": "from c"}}}`, "invalid batch : too many queries, the maximum is 2"},
+		{"query without text or reference", `{"queries": {"a": {"namespace": "dc"}}}`, "invalid batch : query a must have either a text or a namespace, id and revision"},
+		{"query with text and reference", `{"queries": {"a": {"text": "from a", "namespace": "dc", "id": "a", "revision": 1}}}`, "invalid batch : query a must have either a text or a namespace, id and revision"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := web.ParseBatchRequest([]byte(tt.body), 2)
+			if err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			test.Equal(t, err.Error(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,403 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/persistence"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+// Languages of the generated query clients.
+const (
+	TypeScriptClient = "typescript"
+	GoClient         = "go"
+	OpenAPIDocument  = "openapi"
+)
+
+var errInvalidClientLanguage = errors.New("invalid language : must be typescript, go or openapi")
+
+// ClientGenerator produces typed client functions for the saved queries
+// of a namespace, calling the latest revision of each one. The params
+// types come from the query variables and the response types are
+// inferred from the first test case of the query, when there is one.
+type ClientGenerator struct {
+	log    restql.Logger
+	qr     persistence.QueryReader
+	tester QueryTester
+}
+
+// NewClientGenerator constructs a ClientGenerator using the given
+// queries source and the tester that samples the query responses.
+func NewClientGenerator(log restql.Logger, qr persistence.QueryReader, qt QueryTester) ClientGenerator {
+	return ClientGenerator{log: log, qr: qr, tester: qt}
+}
+
+// GenerateClient produces the client code for the saved queries of the
+// namespace defined in configuration, with the given language and, for
+// Go clients, package name.
+func GenerateClient(log restql.Logger, cfg *conf.Config, namespace, language, pkg string) (string, error) {
+	qt, qr, err := newLocalQueryTester(log, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	ctx := restql.WithLogger(context.Background(), log)
+	return NewClientGenerator(log, qr, qt).Generate(ctx, namespace, language, pkg)
+}
+
+type clientQuery struct {
+	id       string
+	revision int
+	typeName string
+	params   *JSONSchema
+	response *JSONSchema
+
+	// required and usages are the variables without default
+	// and the statement parameters using each variable.
+	required []string
+	usages   map[string][]string
+}
+
+// Generate returns the client code for the saved queries of the namespace.
+// An empty package name defaults to one derived from the namespace.
+func (g ClientGenerator) Generate(ctx context.Context, namespace, language, pkg string) (string, error) {
+	if language != TypeScriptClient && language != GoClient && language != OpenAPIDocument {
+		return "", errInvalidClientLanguage
+	}
+
+	queries, err := g.qr.ListQueriesForNamespace(ctx, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	ids := make([]string, 0, len(queries))
+	for id := range queries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	if language == OpenAPIDocument {
+		return g.openAPI(ctx, namespace, ids, queries)
+	}
+
+	var clientQueries []clientQuery
+	for _, id := range ids {
+		cq, err := g.describe(ctx, namespace, id, queries[id])
+		if err != nil {
+			return "", err
+		}
+		clientQueries = append(clientQueries, cq)
+	}
+
+	if language == TypeScriptClient {
+		return typescriptClient(namespace, clientQueries), nil
+	}
+
+	if pkg == "" {
+		pkg = goPackageName(namespace)
+	}
+	return goClient(namespace, pkg, clientQueries)
+}
+
+func (g ClientGenerator) describe(ctx context.Context, namespace, id string, revisions []restql.SavedQuery) (clientQuery, error) {
+	latest := revisions[0]
+	for _, sq := range revisions {
+		if sq.Revision > latest.Revision {
+			latest = sq
+		}
+	}
+
+	return g.describeRevision(ctx, namespace, id, latest)
+}
+
+func (g ClientGenerator) describeRevision(ctx context.Context, namespace, id string, sq restql.SavedQuery) (clientQuery, error) {
+	query, err := g.tester.parser.Parse(sq.Text)
+	if err != nil {
+		return clientQuery{}, fmt.Errorf("%w : %s/%s/%d : %s", eval.ErrParser, namespace, id, sq.Revision, err)
+	}
+
+	cq := clientQuery{
+		id:       id,
+		revision: sq.Revision,
+		typeName: queryTypeName(id),
+		required: eval.RequiredVariables(query),
+		usages:   eval.VariableUsages(query),
+	}
+
+	tc, hasCase := g.sampleCase(namespace, id, sq.Revision)
+
+	var params map[string]interface{}
+	if hasCase {
+		normalized, err := normalizeBody(toJSONMap(tc.Params))
+		if err == nil {
+			params, _ = normalized.(map[string]interface{})
+		}
+	}
+
+	cq.params = &JSONSchema{Types: []string{SchemaObject}, Properties: make(map[string]*JSONSchema)}
+	for _, name := range eval.QueryVariables(query) {
+		value, found := params[name]
+		if !found {
+			cq.params.Properties[name] = &JSONSchema{}
+			continue
+		}
+		cq.params.Properties[name] = InferSchema(value)
+	}
+
+	if hasCase {
+		body, err := g.tester.Execute(ctx, namespace, id, sq.Revision, tc)
+		if err == nil {
+			cq.response = InferSchema(body)
+		} else {
+			g.log.Warn("failed to sample query response for client generation", "namespace", namespace, "query", id, "case", tc.Name, "error", err)
+		}
+	}
+	if cq.response == nil {
+		cq.response = statementsSchema(query)
+	}
+
+	return cq, nil
+}
+
+// sampleCase returns the first test case of the query
+// that runs the given revision.
+func (g ClientGenerator) sampleCase(namespace, id string, revision int) (conf.QueryTestConf, bool) {
+	for _, tc := range g.tester.cfg.QueryTests[namespace][id] {
+		if tc.Revision == 0 || tc.Revision == revision {
+			return tc, true
+		}
+	}
+
+	return conf.QueryTestConf{}, false
+}
+
+// statementsSchema returns the response schema known without samples,
+// that is, which statements compose it, with results of unknown type
+// unless projected by `only` filters.
+func statementsSchema(query domain.Query) *JSONSchema {
+	s := &JSONSchema{Types: []string{SchemaObject}, Properties: make(map[string]*JSONSchema)}
+	for _, stmt := range query.Statements {
+		if stmt.Hidden {
+			continue
+		}
+
+		key := string(domain.NewResourceID(stmt))
+		s.Properties[key] = &JSONSchema{
+			Types: []string{SchemaObject},
+			Properties: map[string]*JSONSchema{
+				"details": {},
+				"result":  onlySchema(stmt.Only),
+			},
+			Required: []string{"details"},
+		}
+		s.Required = append(s.Required, key)
+	}
+	sort.Strings(s.Required)
+
+	return s
+}
+
+// onlySchema returns the schema of a result projected by the `only`
+// filters, which is an object, or a list of objects, with the selected
+// fields as optional properties. Results selecting every field are of
+// unknown type.
+func onlySchema(only []interface{}) *JSONSchema {
+	root := &JSONSchema{}
+	for _, filter := range only {
+		path, ok := onlyPath(filter)
+		if !ok {
+			continue
+		}
+
+		node := root
+		for _, field := range path {
+			if field == "*" {
+				return &JSONSchema{}
+			}
+
+			if node.Properties == nil {
+				node.Properties = make(map[string]*JSONSchema)
+			}
+			child, found := node.Properties[field]
+			if !found {
+				child = &JSONSchema{}
+				node.Properties[field] = child
+			}
+			node = child
+		}
+	}
+
+	return projectedSchema(root)
+}
+
+func projectedSchema(s *JSONSchema) *JSONSchema {
+	if s.Properties == nil {
+		return s
+	}
+
+	object := &JSONSchema{Types: []string{SchemaObject}, Properties: make(map[string]*JSONSchema, len(s.Properties))}
+	for key, p := range s.Properties {
+		object.Properties[key] = projectedSchema(p)
+	}
+
+	return &JSONSchema{Types: []string{SchemaArray, SchemaObject}, Properties: object.Properties, Items: object}
+}
+
+// onlyPath returns the path of the filter, which
+// may be wrapped by functions like matches.
+func onlyPath(filter interface{}) ([]string, bool) {
+	switch f := filter.(type) {
+	case []string:
+		return f, true
+	case domain.Function:
+		return onlyPath(f.Target())
+	default:
+		return nil, false
+	}
+}
+
+func typescriptClient(namespace string, queries []clientQuery) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "// Code generated by restQL from the saved queries of the %s namespace. DO NOT EDIT.\n\n", namespace)
+	b.WriteString(`export type ClientOptions = {
+  baseUrl: string;
+  tenant?: string;
+  headers?: Record<string, string>;
+};
+
+async function runQuery<T>(options: ClientOptions, path: string, params: object): Promise<T> {
+  const tenant = options.tenant ? "?tenant=" + encodeURIComponent(options.tenant) : "";
+  const response = await fetch(options.baseUrl + path + tenant, {
+    method: "POST",
+    headers: { ...options.headers, "Content-Type": "application/json" },
+    body: JSON.stringify(params),
+  });
+  return (await response.json()) as T;
+}
+`)
+
+	for _, q := range queries {
+		params := q.typeName + "Params"
+		response := q.typeName + "Response"
+		function := strings.ToLower(q.typeName[:1]) + q.typeName[1:]
+
+		b.WriteString("\n")
+		b.WriteString(q.params.TypeScript(params))
+		b.WriteString("\n")
+		b.WriteString(q.response.TypeScript(response))
+		fmt.Fprintf(&b, "\n// %s runs the revision %d of the %s/%s saved query.\n", function, q.revision, namespace, q.id)
+		fmt.Fprintf(&b, "export function %s(options: ClientOptions, params: %s = {}): Promise<%s> {\n", function, params, response)
+		fmt.Fprintf(&b, "  return runQuery<%s>(options, %q, params);\n", response, queryPath(namespace, q))
+		b.WriteString("}\n")
+	}
+
+	return b.String()
+}
+
+func goClient(namespace, pkg string, queries []clientQuery) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "// Code generated by restQL from the saved queries of the %s namespace. DO NOT EDIT.\n\n", namespace)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	fmt.Fprintf(&b, `import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// Client runs the saved queries of the %s namespace in a restQL server.
+type Client struct {
+	BaseURL    string
+	Tenant     string
+	Headers    map[string]string
+	HTTPClient *http.Client
+}
+
+func (c *Client) run(ctx context.Context, path string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	target := c.BaseURL + path
+	if c.Tenant != "" {
+		target += "?tenant=" + url.QueryEscape(c.Tenant)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, value := range c.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+`, namespace)
+
+	for _, q := range queries {
+		params := q.typeName + "Params"
+		response := q.typeName + "Response"
+
+		fmt.Fprintf(&b, "\n// %s are the variables of the %s/%s saved query.\n", params, namespace, q.id)
+		fmt.Fprintf(&b, "type %s %s\n", params, q.params.Go())
+		fmt.Fprintf(&b, "\n// %s is the response of the %s/%s saved query.\n", response, namespace, q.id)
+		fmt.Fprintf(&b, "type %s %s\n", response, q.response.Go())
+		fmt.Fprintf(&b, "\n// %s runs the revision %d of the %s/%s saved query.\n", q.typeName, q.revision, namespace, q.id)
+		fmt.Fprintf(&b, "func (c *Client) %s(ctx context.Context, params %s) (%s, error) {\n", q.typeName, params, response)
+		fmt.Fprintf(&b, "\tvar result %s\n", response)
+		fmt.Fprintf(&b, "\terr := c.run(ctx, %q, params, &result)\n", queryPath(namespace, q))
+		b.WriteString("\treturn result, err\n}\n")
+	}
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to format generated go client")
+	}
+
+	return string(src), nil
+}
+
+func queryPath(namespace string, q clientQuery) string {
+	return fmt.Sprintf("/run-query/%s/%s/%d", namespace, q.id, q.revision)
+}
+
+// goPackageName converts the namespace to a valid Go
+// package name, with only lower case letters and digits.
+func goPackageName(namespace string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(namespace) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9' && b.Len() > 0) {
+			b.WriteRune(r)
+		}
+	}
+
+	if b.Len() == 0 {
+		return "restql"
+	}
+	return b.String()
+}
@@ -0,0 +1,464 @@
+package web
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/jsonschema"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/pkg/errors"
+)
+
+func makeDefaultsCascade(cfg *conf.Config) (runner.DefaultsCascade, error) {
+	global, err := toDefaults(cfg.Defaults.DefaultsConf)
+	if err != nil {
+		return runner.DefaultsCascade{}, errors.Wrap(err, "invalid defaults")
+	}
+	if global.Timeout <= 0 {
+		global.Timeout = cfg.HTTP.QueryResourceTimeout
+	}
+
+	tenants := make(map[string]runner.TenantDefaults, len(cfg.Defaults.Tenants))
+	for tenant, td := range cfg.Defaults.Tenants {
+		mappings, err := toMappingsDefaults(td.Mappings)
+		if err != nil {
+			return runner.DefaultsCascade{}, errors.Wrapf(err, "invalid defaults of tenant %s", tenant)
+		}
+
+		defaults, err := toDefaults(td.DefaultsConf)
+		if err != nil {
+			return runner.DefaultsCascade{}, errors.Wrapf(err, "invalid defaults of tenant %s", tenant)
+		}
+		defaults.Namespaces = td.Namespaces
+
+		tenants[tenant] = runner.TenantDefaults{
+			Defaults: defaults,
+			Mappings: mappings,
+		}
+	}
+
+	mappings, err := toMappingsDefaults(cfg.Defaults.Mappings)
+	if err != nil {
+		return runner.DefaultsCascade{}, errors.Wrap(err, "invalid defaults")
+	}
+
+	namespaces := make(map[string]runner.Defaults, len(cfg.Defaults.Namespaces))
+	for namespace, d := range cfg.Defaults.Namespaces {
+		defaults, err := toDefaults(d)
+		if err != nil {
+			return runner.DefaultsCascade{}, errors.Wrapf(err, "invalid defaults of namespace %s", namespace)
+		}
+		defaults.IgnoreErrors = d.IgnoreErrors
+
+		namespaces[namespace] = defaults
+	}
+
+	return runner.DefaultsCascade{
+		Global:     global,
+		Tenants:    tenants,
+		Mappings:   mappings,
+		Namespaces: namespaces,
+	}, nil
+}
+
+func toMappingsDefaults(mappings map[string]conf.DefaultsConf) (map[string]runner.Defaults, error) {
+	result := make(map[string]runner.Defaults, len(mappings))
+	for resource, d := range mappings {
+		defaults, err := toDefaults(d)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid defaults of mapping %s", resource)
+		}
+		if d.Normalize != nil {
+			defaults.Normalize = &domain.Normalization{
+				Lift:   d.Normalize.Lift,
+				Drop:   d.Normalize.Drop,
+				Rename: d.Normalize.Rename,
+			}
+		}
+		if d.Mock != nil {
+			defaults.Mock = toMock(*d.Mock)
+		}
+		if d.ResponseSchema != nil {
+			rs, err := toResponseSchema(*d.ResponseSchema)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid response schema of mapping %s", resource)
+			}
+			defaults.ResponseSchema = rs
+		}
+		if d.Signing != nil {
+			signing, err := toSigning(*d.Signing)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid signing of mapping %s", resource)
+			}
+			defaults.Signing = signing
+		}
+		if d.Policy != nil {
+			policy, err := toPolicy(*d.Policy)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid policy of mapping %s", resource)
+			}
+			defaults.Policy = policy
+		}
+		if d.Faults != nil {
+			faults, err := toFaults(*d.Faults)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid faults of mapping %s", resource)
+			}
+			defaults.Faults = faults
+		}
+		if d.TLS != nil {
+			t, err := toTLS(*d.TLS)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid tls of mapping %s", resource)
+			}
+			defaults.TLS = t
+		}
+		result[resource] = defaults
+	}
+
+	return result, nil
+}
+
+func toDefaults(d conf.DefaultsConf) (runner.Defaults, error) {
+	var forwardHeaders *domain.HeaderForwarding
+	if d.ForwardHeaders != nil {
+		fh, err := toForwardHeaders(*d.ForwardHeaders)
+		if err != nil {
+			return runner.Defaults{}, errors.Wrap(err, "invalid forward headers")
+		}
+		forwardHeaders = fh
+	}
+
+	var proxy *domain.OutboundProxy
+	if d.Proxy != nil {
+		p, err := toProxy(*d.Proxy)
+		if err != nil {
+			return runner.Defaults{}, errors.Wrap(err, "invalid proxy")
+		}
+		proxy = p
+	}
+
+	return runner.Defaults{
+		Timeout: d.Timeout,
+		Retries: d.Retries,
+		MaxAge:  d.MaxAge,
+		SMaxAge: d.SMaxAge,
+		Headers: withEnvValues(d.Headers, d.HeadersEnv),
+		Params:  withEnvValues(d.Params, d.ParamsEnv),
+
+		ForwardConditionalHeaders: d.ForwardConditionalHeaders,
+		ForwardHeaders:            forwardHeaders,
+		SessionCookies:            d.SessionCookies,
+
+		MaxResponseSize:        d.MaxResponseSize,
+		MaxMultiplexedRequests: d.MaxMultiplexedRequests,
+
+		FailoverURLs:        d.Failover.URLs,
+		FailoverStatusCodes: d.Failover.StatusCodes,
+
+		Strict:         d.Strict,
+		MaxQueryMemory: d.MaxQueryMemory,
+		Proxy:          proxy,
+	}, nil
+}
+
+// toForwardHeaders adds the headers of the profiles to the allowed
+// ones, which are only needed when the policy restricts them, since
+// every header is forwarded otherwise.
+func toForwardHeaders(fh conf.ForwardHeadersConf) (*domain.HeaderForwarding, error) {
+	allow := append([]string(nil), fh.Allow...)
+	for _, name := range fh.Profiles {
+		headers, found := domain.ForwardingProfiles[name]
+		if !found {
+			return nil, errors.Errorf("unknown profile %s", name)
+		}
+
+		if len(fh.Allow) > 0 {
+			allow = append(allow, headers...)
+		}
+	}
+
+	return &domain.HeaderForwarding{
+		Allow:  allow,
+		Deny:   fh.Deny,
+		Rename: fh.Rename,
+	}, nil
+}
+
+// withEnvValues adds to the static values the ones read from the
+// environment variables they are mapped to, so secrets like API keys
+// can be injected without being written in the configuration file.
+// Unset variables are ignored and environment values take precedence.
+func withEnvValues(values map[string]string, envs map[string]string) map[string]string {
+	if len(envs) == 0 {
+		return values
+	}
+
+	result := make(map[string]string, len(values)+len(envs))
+	for k, v := range values {
+		result[k] = v
+	}
+	for k, env := range envs {
+		if v, found := os.LookupEnv(env); found {
+			result[k] = v
+		}
+	}
+
+	return result
+}
+
+// toMock converts the mock configuration, encoding its body once,
+// so every mocked statement decodes its own copy. Bodies decoded
+// from YAML are always valid JSON once their maps are converted.
+func toMock(m conf.MockConf) *domain.Mock {
+	body, _ := json.Marshal(toJSONValue(m.Body))
+
+	return &domain.Mock{
+		Status:  m.Status,
+		Headers: m.Headers,
+		Body:    body,
+		Latency: m.Latency,
+	}
+}
+
+// toResponseSchema compiles the response schema, read from its file
+// or, otherwise, converted from its inline YAML form to the generic
+// JSON one, where every number is a float64 like in the responses.
+func toResponseSchema(rs conf.ResponseSchemaConf) (*domain.ResponseSchema, error) {
+	mode := rs.Mode
+	switch mode {
+	case "":
+		mode = domain.SchemaModeFail
+	case domain.SchemaModeFail, domain.SchemaModeAnnotate:
+	default:
+		return nil, errors.Errorf("unknown mode %s, must be one of fail or annotate", mode)
+	}
+
+	data, err := json.Marshal(toJSONValue(rs.Schema))
+	if rs.File != "" {
+		data, err = ioutil.ReadFile(rs.File)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	schema, err := jsonschema.Compile(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ResponseSchema{Schema: schema, Mode: mode}, nil
+}
+
+// toSigning converts the signing configuration, which must declare
+// exactly one scheme, reading the hmac secret from the environment
+// when it is given by a variable.
+func toSigning(s conf.SigningConf) (*domain.RequestSigning, error) {
+	switch {
+	case s.HMAC != nil && s.SigV4 != nil:
+		return nil, errors.New("only one of hmac and sigv4 can be declared")
+	case s.HMAC != nil:
+		secret := s.HMAC.Secret
+		if s.HMAC.SecretEnv != "" {
+			secret = os.Getenv(s.HMAC.SecretEnv)
+		}
+		if secret == "" {
+			return nil, errors.New("hmac secret is empty")
+		}
+
+		return &domain.RequestSigning{
+			Scheme: domain.HMACSigningScheme,
+			KeyID:  s.HMAC.KeyID,
+			Secret: secret,
+			Header: s.HMAC.Header,
+		}, nil
+	case s.SigV4 != nil:
+		if s.SigV4.Service == "" {
+			return nil, errors.New("sigv4 service is empty")
+		}
+
+		return &domain.RequestSigning{
+			Scheme:  domain.SigV4SigningScheme,
+			Region:  s.SigV4.Region,
+			Service: s.SigV4.Service,
+		}, nil
+	default:
+		return nil, errors.New("no signing scheme declared")
+	}
+}
+
+// toProxy converts the proxy configuration, taking the credentials
+// from the URL when they are not given apart. Proxies without port
+// listen on the default one of their scheme.
+func toProxy(p conf.ProxyConf) (*domain.OutboundProxy, error) {
+	if p.Direct {
+		return &domain.OutboundProxy{}, nil
+	}
+
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var defaultPort string
+	switch u.Scheme {
+	case domain.HTTPProxyScheme:
+		defaultPort = "80"
+	case domain.SOCKS5ProxyScheme:
+		defaultPort = "1080"
+	default:
+		return nil, errors.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.New("proxy host is empty")
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+
+	proxy := &domain.OutboundProxy{
+		Scheme:   u.Scheme,
+		Addr:     net.JoinHostPort(u.Hostname(), port),
+		Username: p.Username,
+		Password: p.Password,
+	}
+	if u.User != nil && proxy.Username == "" {
+		proxy.Username = u.User.Username()
+		proxy.Password, _ = u.User.Password()
+	}
+	if p.PasswordEnv != "" {
+		proxy.Password = os.Getenv(p.PasswordEnv)
+	}
+
+	return proxy, nil
+}
+
+// toPolicy converts the policy configuration,
+// normalizing the methods to upper case.
+func toPolicy(p conf.PolicyConf) (*domain.ResourcePolicy, error) {
+	if p.MaxBodySize < 0 {
+		return nil, errors.New("max body size is negative")
+	}
+
+	var methods []string
+	for _, m := range p.Methods {
+		method := strings.ToUpper(strings.TrimSpace(m))
+		if method == "" {
+			return nil, errors.New("method is empty")
+		}
+		methods = append(methods, method)
+	}
+
+	return &domain.ResourcePolicy{
+		Methods:     methods,
+		PathParams:  p.PathParams,
+		MaxBodySize: p.MaxBodySize,
+	}, nil
+}
+
+// toFaults converts the faults configuration, where the latency is
+// added to every request unless its rate is given and the error
+// responses default to 503 Service Unavailable.
+func toFaults(f conf.FaultsConf) (*domain.Faults, error) {
+	if f.Latency < 0 {
+		return nil, errors.New("latency is negative")
+	}
+
+	latencyRate := 1.0
+	if f.LatencyRate != nil {
+		latencyRate = *f.LatencyRate
+	}
+
+	rates := []struct {
+		name  string
+		value float64
+	}{
+		{"latencyRate", latencyRate},
+		{"errorRate", f.ErrorRate},
+		{"resetRate", f.ResetRate},
+		{"truncateRate", f.TruncateRate},
+	}
+	for _, rate := range rates {
+		if rate.value < 0 || rate.value > 1 {
+			return nil, errors.Errorf("%s must be between 0 and 1", rate.name)
+		}
+	}
+
+	errorStatus := f.ErrorStatus
+	if errorStatus == 0 {
+		errorStatus = http.StatusServiceUnavailable
+	}
+	if errorStatus < 100 || errorStatus > 599 {
+		return nil, errors.Errorf("error status %d is invalid", errorStatus)
+	}
+
+	return &domain.Faults{
+		Latency:      f.Latency,
+		LatencyRate:  latencyRate,
+		ErrorRate:    f.ErrorRate,
+		ErrorStatus:  errorStatus,
+		ResetRate:    f.ResetRate,
+		TruncateRate: f.TruncateRate,
+	}, nil
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// toTLS converts the TLS configuration, where the cipher suites are
+// given by their standard names and only the secure ones are allowed.
+func toTLS(t conf.TLSConf) (*domain.UpstreamTLS, error) {
+	result := &domain.UpstreamTLS{SessionCacheSize: t.SessionCacheSize}
+
+	if t.MinVersion != "" {
+		version, found := tlsVersions[t.MinVersion]
+		if !found {
+			return nil, errors.Errorf("unsupported tls version %q", t.MinVersion)
+		}
+		result.MinVersion = version
+	}
+
+	suites := make(map[string]uint16)
+	for _, cs := range tls.CipherSuites() {
+		suites[cs.Name] = cs.ID
+	}
+	for _, name := range t.CipherSuites {
+		id, found := suites[name]
+		if !found {
+			return nil, errors.Errorf("unsupported cipher suite %q", name)
+		}
+		result.CipherSuites = append(result.CipherSuites, id)
+	}
+
+	if t.SessionTickets != nil {
+		result.DisableSessionTickets = !*t.SessionTickets
+	}
+
+	switch t.RevocationCheck {
+	case "", domain.OCSPRevocationCheck, domain.StrictOCSPRevocationCheck, domain.CRLRevocationCheck:
+		result.RevocationCheck = t.RevocationCheck
+	default:
+		return nil, errors.Errorf("unsupported revocation check %q", t.RevocationCheck)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,339 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/cache"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web/middleware"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// Query arguments selecting the execution compared
+// against the one defined by the path parameters.
+const (
+	againstRevisionArg = "against"
+	againstTenantArg   = "againstTenant"
+)
+
+// Kinds of Difference found between two query results.
+const (
+	DiffChanged = "changed"
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+)
+
+var (
+	errEmptyDiff           = errors.New("invalid diff : the against revision or tenant must differ from the base one")
+	errInvalidRevisionDiff = errors.New("invalid diff : the base revision must be given and differ from the target one")
+)
+
+type diffExecution struct {
+	Revision   int         `json:"revision"`
+	Tenant     string      `json:"tenant"`
+	StatusCode int         `json:"statusCode,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Body       interface{} `json:"-"`
+}
+
+// Difference represents a value that is not the same
+// in the results being compared, identified by its path.
+type Difference struct {
+	Path   string      `json:"path"`
+	Kind   string      `json:"kind"`
+	Base   interface{} `json:"base"`
+	Target interface{} `json:"target"`
+}
+
+type diffResponse struct {
+	Base        diffExecution `json:"base"`
+	Target      diffExecution `json:"target"`
+	Equal       bool          `json:"equal"`
+	Differences []Difference  `json:"differences"`
+}
+
+// DiffSavedQuery executes the same saved query and input against
+// two revisions or tenants, and returns the differences between
+// their results. It is useful for validating query migrations or
+// upstream version bumps before moving clients.
+func (r restQl) DiffSavedQuery(reqCtx *fasthttp.RequestCtx) error {
+	log := r.log.With("restql-endpoint", string(reqCtx.Request.URI().Path()))
+
+	ctx := middleware.GetNativeContext(reqCtx)
+	ctx = restql.WithLogger(ctx, log)
+	ctx = cache.WithStalenessTracking(ctx)
+
+	baseOptions, err := makeQueryOptions(reqCtx, log, r.config)
+	if err != nil {
+		log.Error("failed to build query options", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	targetOptions, err := makeAgainstOptions(reqCtx, baseOptions)
+	if err != nil {
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	input, err := makeQueryInput(reqCtx, log)
+	if err != nil {
+		log.Error("failed to build query input", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+	delete(input.Params, againstRevisionArg)
+	delete(input.Params, againstTenantArg)
+
+	response := diffExecutions(
+		func() diffExecution { return executeForDiff(ctx, r.evaluator, baseOptions, input) },
+		func() diffExecution { return executeForDiff(ctx, r.evaluator, targetOptions, input) },
+	)
+
+	return Respond(reqCtx, response, fasthttp.StatusOK, nil)
+}
+
+// diffExecutions runs the base and target executions concurrently
+// and compares their results, unless one of them fails.
+func diffExecutions(executeBase, executeTarget func() diffExecution) diffResponse {
+	var base, target diffExecution
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		base = executeBase()
+	}()
+	go func() {
+		defer wg.Done()
+		target = executeTarget()
+	}()
+	wg.Wait()
+
+	differences := []Difference{}
+	if base.Error == "" && target.Error == "" {
+		if base.StatusCode != target.StatusCode {
+			differences = append(differences, Difference{Path: "statusCode", Kind: DiffChanged, Base: base.StatusCode, Target: target.StatusCode})
+		}
+		differences = append(differences, DiffResults(base.Body, target.Body)...)
+	}
+
+	return diffResponse{
+		Base:        base,
+		Target:      target,
+		Equal:       len(differences) == 0 && base.Error == "" && target.Error == "",
+		Differences: differences,
+	}
+}
+
+// RevisionDiff represents the executions of two revisions of a saved
+// query compared by DiffRevisions, sharing the same tenant and input.
+// Target defaults to the latest revision, and when Cassette is given the
+// upstream requests are replayed from it instead of reaching the mapped
+// resources.
+type RevisionDiff struct {
+	Base     int                    `json:"base"`
+	Target   int                    `json:"target"`
+	Tenant   string                 `json:"tenant"`
+	Params   map[string]interface{} `json:"params"`
+	Headers  map[string]string      `json:"headers"`
+	Cassette string                 `json:"cassette"`
+}
+
+// DiffRevisions executes two revisions of the saved query with the same
+// input, using the given evaluator or replaying the cassette, and returns
+// the differences between their results.
+func (qt QueryTester) DiffRevisions(ctx context.Context, e eval.Evaluator, namespace, queryID string, rd RevisionDiff) (diffResponse, error) {
+	if rd.Target <= 0 {
+		revision, err := qt.latestRevision(ctx, namespace, queryID)
+		if err != nil {
+			return diffResponse{}, err
+		}
+		rd.Target = revision
+	}
+
+	if rd.Base <= 0 || rd.Base == rd.Target {
+		return diffResponse{}, errInvalidRevisionDiff
+	}
+
+	tenant := rd.Tenant
+	if tenant == "" {
+		tenant = qt.cfg.Tenant
+	}
+	input := restql.QueryInput{Params: toJSONMap(rd.Params), Headers: rd.Headers}
+
+	execute := func(revision int) diffExecution {
+		options := restql.QueryOptions{Namespace: namespace, Id: queryID, Revision: revision, Tenant: tenant}
+		if rd.Cassette == "" {
+			return executeForDiff(ctx, e, options, input)
+		}
+
+		replayed, verify, err := qt.caseEvaluator(conf.QueryTestConf{Cassette: rd.Cassette})
+		if err != nil {
+			return diffExecution{Revision: revision, Tenant: tenant, Error: err.Error()}
+		}
+
+		execution := executeForDiff(ctx, replayed, options, input)
+		if err := verify(); err != nil && execution.Error == "" {
+			execution.Error = err.Error()
+		}
+
+		return execution
+	}
+
+	return diffExecutions(
+		func() diffExecution { return execute(rd.Base) },
+		func() diffExecution { return execute(rd.Target) },
+	), nil
+}
+
+func makeAgainstOptions(reqCtx *fasthttp.RequestCtx, base restql.QueryOptions) (restql.QueryOptions, error) {
+	target := base
+
+	if againstRevision := reqCtx.QueryArgs().Peek(againstRevisionArg); len(againstRevision) > 0 {
+		revision, err := strconv.Atoi(string(againstRevision))
+		if err != nil {
+			return restql.QueryOptions{}, errInvalidRevisionType
+		}
+		target.Revision = revision
+	}
+
+	if againstTenant := reqCtx.QueryArgs().Peek(againstTenantArg); len(againstTenant) > 0 {
+		target.Tenant = string(againstTenant)
+	}
+
+	if target == base {
+		return restql.QueryOptions{}, errEmptyDiff
+	}
+
+	return target, nil
+}
+
+func executeForDiff(ctx context.Context, e eval.Evaluator, options restql.QueryOptions, input restql.QueryInput) diffExecution {
+	execution := diffExecution{Revision: options.Revision, Tenant: options.Tenant}
+
+	result, err := e.SavedQuery(ctx, options, input)
+	if err != nil {
+		execution.Error = err.Error()
+		return execution
+	}
+
+	response, err := MakeQueryResponse(result, DebugOptions{})
+	if err != nil {
+		execution.Error = err.Error()
+		return execution
+	}
+
+	body, err := normalizeBody(response.Body)
+	if err != nil {
+		execution.Error = err.Error()
+		return execution
+	}
+
+	execution.StatusCode = response.StatusCode
+	execution.Body = body
+
+	return execution
+}
+
+// normalizeBody converts the response body to its generic JSON form,
+// so results are compared by their content.
+func normalizeBody(body interface{}) (interface{}, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	err = json.Unmarshal(data, &v)
+	return v, err
+}
+
+// DiffResults returns the differences between two JSON values, sorted
+// by path, where object keys are separated by dots and array items
+// are identified by their index, like `hero.result.weapons[1]`.
+func DiffResults(base, target interface{}) []Difference {
+	return diffValues("", base, target, nil)
+}
+
+// diffValues appends the differences between two JSON values, descending
+// into objects and arrays, and identifying each one by its path.
+func diffValues(path string, base, target interface{}, differences []Difference) []Difference {
+	switch base := base.(type) {
+	case map[string]interface{}:
+		target, ok := target.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := make(map[string]struct{}, len(base)+len(target))
+		for k := range base {
+			keys[k] = struct{}{}
+		}
+		for k := range target {
+			keys[k] = struct{}{}
+		}
+
+		sortedKeys := make([]string, 0, len(keys))
+		for k := range keys {
+			sortedKeys = append(sortedKeys, k)
+		}
+		sort.Strings(sortedKeys)
+
+		for _, k := range sortedKeys {
+			childPath := joinPath(path, k)
+			b, inBase := base[k]
+			t, inTarget := target[k]
+
+			switch {
+			case !inTarget:
+				differences = append(differences, Difference{Path: childPath, Kind: DiffRemoved, Base: b})
+			case !inBase:
+				differences = append(differences, Difference{Path: childPath, Kind: DiffAdded, Target: t})
+			default:
+				differences = diffValues(childPath, b, t, differences)
+			}
+		}
+
+		return differences
+	case []interface{}:
+		target, ok := target.([]interface{})
+		if !ok {
+			break
+		}
+
+		for i := 0; i < len(base) || i < len(target); i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+
+			switch {
+			case i >= len(target):
+				differences = append(differences, Difference{Path: childPath, Kind: DiffRemoved, Base: base[i]})
+			case i >= len(base):
+				differences = append(differences, Difference{Path: childPath, Kind: DiffAdded, Target: target[i]})
+			default:
+				differences = diffValues(childPath, base[i], target[i], differences)
+			}
+		}
+
+		return differences
+	}
+
+	if !reflect.DeepEqual(base, target) {
+		differences = append(differences, Difference{Path: path, Kind: DiffChanged, Base: base, Target: target})
+	}
+
+	return differences
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
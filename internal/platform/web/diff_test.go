@@ -0,0 +1,78 @@
+package web_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestDiffResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		target   string
+		expected []web.Difference
+	}{
+		{
+			"should find no difference on equal results",
+			`{"hero":{"details":{"status":200},"result":{"name":"batman","weapons":["belt"]}}}`,
+			`{"hero":{"details":{"status":200},"result":{"name":"batman","weapons":["belt"]}}}`,
+			nil,
+		},
+		{
+			"should find changed values",
+			`{"hero":{"details":{"status":200},"result":{"name":"batman"}}}`,
+			`{"hero":{"details":{"status":404},"result":{"name":"bruce"}}}`,
+			[]web.Difference{
+				{Path: "hero.details.status", Kind: web.DiffChanged, Base: float64(200), Target: float64(404)},
+				{Path: "hero.result.name", Kind: web.DiffChanged, Base: "batman", Target: "bruce"},
+			},
+		},
+		{
+			"should find added and removed fields",
+			`{"hero":{"result":{"name":"batman","city":"gotham"}}}`,
+			`{"hero":{"result":{"name":"batman","team":"jla"}},"sidekick":{"result":{}}}`,
+			[]web.Difference{
+				{Path: "hero.result.city", Kind: web.DiffRemoved, Base: "gotham"},
+				{Path: "hero.result.team", Kind: web.DiffAdded, Target: "jla"},
+				{Path: "sidekick", Kind: web.DiffAdded, Target: map[string]interface{}{"result": map[string]interface{}{}}},
+			},
+		},
+		{
+			"should compare array items by index",
+			`{"hero":[{"result":{"id":1}},{"result":{"id":2}}]}`,
+			`{"hero":[{"result":{"id":1}},{"result":{"id":3}},{"result":{"id":4}}]}`,
+			[]web.Difference{
+				{Path: "hero[1].result.id", Kind: web.DiffChanged, Base: float64(2), Target: float64(3)},
+				{Path: "hero[2]", Kind: web.DiffAdded, Target: map[string]interface{}{"result": map[string]interface{}{"id": float64(4)}}},
+			},
+		},
+		{
+			"should find changed value types",
+			`{"hero":{"result":["batman"]}}`,
+			`{"hero":{"result":"batman"}}`,
+			[]web.Difference{
+				{Path: "hero.result", Kind: web.DiffChanged, Base: []interface{}{"batman"}, Target: "batman"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := web.DiffResults(unmarshal(t, tt.base), unmarshal(t, tt.target))
+			test.Equal(t, got, tt.expected)
+		})
+	}
+}
+
+func unmarshal(t *testing.T, data string) interface{} {
+	var v interface{}
+	err := json.Unmarshal([]byte(data), &v)
+	if err != nil {
+		t.Fatalf("invalid json in test case : %v", err)
+	}
+
+	return v
+}
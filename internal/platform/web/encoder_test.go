@@ -0,0 +1,103 @@
+package web_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/valyala/fasthttp"
+)
+
+type envelopeEncoder struct{}
+
+func (envelopeEncoder) Name() string { return "envelope" }
+
+func (envelopeEncoder) MediaTypes() []string { return []string{"application/vnd.envelope+json"} }
+
+func (envelopeEncoder) Encode(ctx context.Context, response restql.EncodableResponse) (string, []byte, error) {
+	hero := response.Body["hero"].(map[string]interface{})
+	result := hero["result"].(map[string]interface{})
+	body := fmt.Sprintf(`{"status":%d,"data":{"hero":"%s"}}`, response.Status, result["id"])
+	return "application/vnd.envelope+json", []byte(body), nil
+}
+
+func TestRespondQueryWithResponseEncoder(t *testing.T) {
+	restql.RegisterResponseEncoder(envelopeEncoder{})
+
+	response := web.QueryResponse{
+		StatusCode: http.StatusOK,
+		Body:       map[string]web.StatementResult{"hero": {Details: map[string]interface{}{"status": 200}, Result: map[string]interface{}{"id": "1"}}},
+	}
+
+	tests := []struct {
+		name                string
+		accept              string
+		format              string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			"should encode with the encoder of the accepted media type",
+			"application/vnd.envelope+json",
+			"",
+			http.StatusOK,
+			"application/vnd.envelope+json",
+			`{"status":200,"data":{"hero":"1"}}`,
+		},
+		{
+			"should prefer json when accepted first",
+			"application/json, application/vnd.envelope+json",
+			"",
+			http.StatusOK,
+			"application/json; charset=utf-8",
+			`{"hero":{"details":{"status":200},"result":{"id":"1"}}}` + "\n",
+		},
+		{
+			"should encode with the encoder named by the format parameter",
+			"application/json",
+			"envelope",
+			http.StatusOK,
+			"application/vnd.envelope+json",
+			`{"status":200,"data":{"hero":"1"}}`,
+		},
+		{
+			"should encode with a built-in format named by the format parameter",
+			"application/vnd.envelope+json",
+			"json",
+			http.StatusOK,
+			"application/json; charset=utf-8",
+			`{"hero":{"details":{"status":200},"result":{"id":"1"}}}` + "\n",
+		},
+		{
+			"should refuse an unknown format",
+			"",
+			"hal",
+			http.StatusNotAcceptable,
+			"application/json; charset=utf-8",
+			`{"error":"format hal: unknown response format"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(http.MethodGet)
+			ctx.Request.Header.Set("Accept", tt.accept)
+			if tt.format != "" {
+				ctx.Request.SetRequestURI("/run-query?_format=" + tt.format)
+			}
+
+			err := web.RespondQuery(ctx, response)
+
+			test.VerifyError(t, err)
+			test.Equal(t, ctx.Response.StatusCode(), tt.expectedStatus)
+			test.Equal(t, string(ctx.Response.Header.ContentType()), tt.expectedContentType)
+			test.Equal(t, string(ctx.Response.Body()), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package web
+
+import (
+	"context"
+	"mime"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/codec"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	msgpackContentType = "application/msgpack"
+	cborContentType    = "application/cbor"
+)
+
+// warningsField is the query response field listing the
+// warnings found while evaluating the query.
+const warningsField = "_warnings"
+
+var queryMediaTypes = map[string]string{
+	ndjsonContentType:         ndjsonContentType,
+	msgpackContentType:        msgpackContentType,
+	"application/x-msgpack":   msgpackContentType,
+	"application/vnd.msgpack": msgpackContentType,
+	cborContentType:           cborContentType,
+}
+
+// formatParamName is the query parameter choosing the
+// encoding of the query response by its name.
+const formatParamName = "_format"
+
+var queryFormats = map[string]string{
+	"json":    "",
+	"ndjson":  ndjsonContentType,
+	"msgpack": msgpackContentType,
+	"cbor":    cborContentType,
+}
+
+var errUnknownFormat = errors.New("unknown response format")
+
+// queryEncoding is the encoding negotiated for the query response,
+// either one of the built-in media types or a registered encoder.
+// The zero value means the response should be written as JSON.
+type queryEncoding struct {
+	mediaType string
+	encoder   restql.ResponseEncoder
+}
+
+// negotiateEncoding returns the encoding named by the _format
+// parameter or, without it, the first one matching a media type
+// in the Accept header, built-in media types taking precedence
+// over the ones of registered encoders.
+func negotiateEncoding(ctx *fasthttp.RequestCtx) (queryEncoding, error) {
+	if format := string(ctx.QueryArgs().Peek(formatParamName)); format != "" {
+		return formatEncoding(format)
+	}
+
+	accept := string(ctx.Request.Header.Peek("Accept"))
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+
+		if mediaType == "application/json" {
+			return queryEncoding{}, nil
+		}
+
+		if supported, found := queryMediaTypes[mediaType]; found {
+			return queryEncoding{mediaType: supported}, nil
+		}
+
+		if encoder, found := mediaTypeEncoder(mediaType); found {
+			return queryEncoding{encoder: encoder}, nil
+		}
+	}
+
+	return queryEncoding{}, nil
+}
+
+func formatEncoding(format string) (queryEncoding, error) {
+	if mediaType, found := queryFormats[format]; found {
+		return queryEncoding{mediaType: mediaType}, nil
+	}
+
+	for _, encoder := range restql.GetResponseEncoders() {
+		if encoder.Name() == format {
+			return queryEncoding{encoder: encoder}, nil
+		}
+	}
+
+	return queryEncoding{}, errors.Wrapf(errUnknownFormat, "format %s", format)
+}
+
+func mediaTypeEncoder(mediaType string) (restql.ResponseEncoder, bool) {
+	for _, encoder := range restql.GetResponseEncoders() {
+		for _, mt := range encoder.MediaTypes() {
+			if strings.EqualFold(mt, mediaType) {
+				return encoder, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+type binaryMarshaler func(v interface{}) ([]byte, error)
+
+// marshalBinary encodes the query body going through JSON, since the
+// statement results are kept by restQL as raw upstream JSON.
+func marshalBinary(body map[string]StatementResult, warnings []domain.Warning, encoder codec.JSONEncoder, marshal binaryMarshaler) ([]byte, error) {
+	v, err := decodedBody(body, warnings, encoder)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(v)
+}
+
+// marshalWithEncoder encodes the query response with a registered
+// encoder, which receives the body decoded like marshalBinary.
+func marshalWithEncoder(ctx context.Context, response QueryResponse, encoder codec.JSONEncoder, re restql.ResponseEncoder) (string, []byte, error) {
+	v, err := decodedBody(response.Body, response.Warnings, encoder)
+	if err != nil {
+		return "", nil, err
+	}
+
+	body, _ := v.(map[string]interface{})
+	encodable := restql.EncodableResponse{Status: response.StatusCode, Header: response.Headers, Body: body}
+
+	contentType, data, err := re.Encode(ctx, encodable)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "response encoder %s failed", re.Name())
+	}
+
+	return contentType, data, nil
+}
+
+func decodedBody(body map[string]StatementResult, warnings []domain.Warning, encoder codec.JSONEncoder) (interface{}, error) {
+	data, err := encoder.Marshal(genericBody(body, warnings))
+	if err != nil {
+		return nil, err
+	}
+
+	return codec.FromJSON(data)
+}
+
+// genericBody converts the query body to maps, which are
+// handled by the JSON encoders without reflection, along
+// with the warnings, when there are any.
+func genericBody(body map[string]StatementResult, warnings []domain.Warning) map[string]interface{} {
+	result := make(map[string]interface{}, len(body)+1)
+	for id, statement := range body {
+		s := map[string]interface{}{"details": statement.Details}
+		if statement.Result != nil {
+			s["result"] = statement.Result
+		}
+		result[id] = s
+	}
+
+	if len(warnings) > 0 {
+		result[warningsField] = warnings
+	}
+
+	return result
+}
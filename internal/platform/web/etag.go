@@ -0,0 +1,132 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/codec"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web/middleware"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	eTagHeader        = "ETag"
+	ifNoneMatchHeader = "If-None-Match"
+)
+
+var defaultJSONEncoder, _ = codec.NewJSONEncoder(codec.StdJSONEncoder, codec.JSONOptions{EscapeHTML: true, SortKeys: true})
+
+// RespondQuery write the query response back to the client with
+// an ETag computed over the body. If the request is a GET or HEAD
+// with an If-None-Match header matching the ETag, the body is
+// omitted and a 304 Not Modified is returned.
+func RespondQuery(ctx *fasthttp.RequestCtx, response QueryResponse) error {
+	return RespondQueryWith(ctx, response, defaultJSONEncoder)
+}
+
+// RespondQueryWith write the query response back to the client
+// like RespondQuery, encoding the JSON body with the encoder.
+func RespondQueryWith(ctx *fasthttp.RequestCtx, response QueryResponse, encoder codec.JSONEncoder) error {
+	contentType, body, err := encodeQueryResponse(ctx, ctx, response, encoder)
+	if err != nil {
+		return RespondError(ctx, err, errToStatusCode)
+	}
+
+	return writeQueryBody(ctx, contentType, body, response.StatusCode, response.Headers)
+}
+
+// writeQueryBody writes the encoded query response, answering
+// conditional requests matching its entity tag with 304 Not Modified.
+func writeQueryBody(ctx *fasthttp.RequestCtx, contentType string, body []byte, statusCode int, headers map[string]string) error {
+	ctx.Response.Header.SetContentType(contentType)
+	ctx.Response.Header.Add("Vary", "Accept")
+	for k, v := range headers {
+		ctx.Response.Header.Set(k, v)
+	}
+
+	if statusCode != http.StatusOK {
+		ctx.Response.SetStatusCode(statusCode)
+		ctx.Response.SetBodyRaw(body)
+		return nil
+	}
+
+	eTag := makeETag(body)
+	ctx.Response.Header.Set(eTagHeader, eTag)
+
+	method := string(ctx.Method())
+	isConditional := method == http.MethodGet || method == http.MethodHead
+	if isConditional {
+		if matched, ok := matchETag(string(ctx.Request.Header.Peek(ifNoneMatchHeader)), eTag); ok {
+			ctx.Response.Header.Set(eTagHeader, matched)
+			ctx.Response.SetStatusCode(http.StatusNotModified)
+			return nil
+		}
+	}
+
+	ctx.Response.SetStatusCode(statusCode)
+	ctx.Response.SetBodyRaw(body)
+	return nil
+}
+
+// encodeQueryResponse encodes the query response with the encoding
+// negotiated with the client, which defaults to JSON.
+func encodeQueryResponse(ctx context.Context, reqCtx *fasthttp.RequestCtx, response QueryResponse, encoder codec.JSONEncoder) (string, []byte, error) {
+	encoding, err := negotiateEncoding(reqCtx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if encoding.encoder != nil {
+		return marshalWithEncoder(ctx, response, encoder, encoding.encoder)
+	}
+
+	switch mediaType := encoding.mediaType; mediaType {
+	case ndjsonContentType:
+		body, err := marshalNDJSON(response.Body, response.Warnings)
+		return mediaType, body, err
+	case msgpackContentType:
+		body, err := marshalBinary(response.Body, response.Warnings, encoder, codec.MarshalMsgpack)
+		return mediaType, body, err
+	case cborContentType:
+		body, err := marshalBinary(response.Body, response.Warnings, encoder, codec.MarshalCBOR)
+		return mediaType, body, err
+	}
+
+	body, err := encoder.Marshal(genericBody(response.Body, response.Warnings))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return "application/json; charset=utf-8", append(body, '\n'), nil
+}
+
+func makeETag(body []byte) string {
+	return fmt.Sprintf(`"%x"`, sha256.Sum256(body))
+}
+
+// matchETag applies the weak comparison required by If-None-Match
+// against the list of entity tags, where the tags of compressed
+// responses match regardless of their content coding. It returns
+// the matching tag, which is the one of the encoding the client holds.
+func matchETag(ifNoneMatch string, eTag string) (string, bool) {
+	if ifNoneMatch == "" {
+		return "", false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return eTag, true
+		}
+
+		tag := strings.TrimPrefix(candidate, "W/")
+		if middleware.TrimETagCoding(tag) == eTag {
+			return tag, true
+		}
+	}
+
+	return "", false
+}
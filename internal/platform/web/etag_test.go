@@ -0,0 +1,128 @@
+package web_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRespondQuery(t *testing.T) {
+	response := web.QueryResponse{
+		StatusCode: http.StatusOK,
+		Body:       map[string]web.StatementResult{"hero": {Result: map[string]interface{}{"id": "1"}}},
+		Headers:    map[string]string{"Cache-Control": "max-age=60"},
+	}
+	eTag := responseETag(t, response)
+
+	tests := []struct {
+		name           string
+		method         string
+		ifNoneMatch    string
+		response       web.QueryResponse
+		expectedStatus int
+		expectedETag   string
+		expectBody     bool
+	}{
+		{
+			"should write body and etag when there is no if-none-match",
+			http.MethodGet,
+			"",
+			response,
+			http.StatusOK,
+			eTag,
+			true,
+		},
+		{
+			"should return not modified when if-none-match matches",
+			http.MethodGet,
+			eTag,
+			response,
+			http.StatusNotModified,
+			eTag,
+			false,
+		},
+		{
+			"should return not modified when weak if-none-match is in list",
+			http.MethodGet,
+			`"other", W/` + eTag,
+			response,
+			http.StatusNotModified,
+			eTag,
+			false,
+		},
+		{
+			"should return not modified with the tag of the compressed response matching if-none-match",
+			http.MethodGet,
+			strings.TrimSuffix(eTag, `"`) + `-gzip"`,
+			response,
+			http.StatusNotModified,
+			strings.TrimSuffix(eTag, `"`) + `-gzip"`,
+			false,
+		},
+		{
+			"should return not modified when if-none-match is wildcard",
+			http.MethodGet,
+			"*",
+			response,
+			http.StatusNotModified,
+			eTag,
+			false,
+		},
+		{
+			"should write body when if-none-match does not match",
+			http.MethodGet,
+			`"other"`,
+			response,
+			http.StatusOK,
+			eTag,
+			true,
+		},
+		{
+			"should ignore if-none-match on post",
+			http.MethodPost,
+			eTag,
+			response,
+			http.StatusOK,
+			eTag,
+			true,
+		},
+		{
+			"should not generate etag for unsuccessful responses",
+			http.MethodGet,
+			eTag,
+			web.QueryResponse{StatusCode: http.StatusBadGateway, Body: response.Body},
+			http.StatusBadGateway,
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(tt.method)
+			if tt.ifNoneMatch != "" {
+				ctx.Request.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+
+			err := web.RespondQuery(ctx, tt.response)
+
+			test.VerifyError(t, err)
+			test.Equal(t, ctx.Response.StatusCode(), tt.expectedStatus)
+			test.Equal(t, string(ctx.Response.Header.Peek("ETag")), tt.expectedETag)
+			test.Equal(t, len(ctx.Response.Body()) > 0, tt.expectBody)
+		})
+	}
+}
+
+func responseETag(t *testing.T, response web.QueryResponse) string {
+	ctx := &fasthttp.RequestCtx{}
+	err := web.RespondQuery(ctx, response)
+	test.VerifyError(t, err)
+
+	return string(ctx.Response.Header.Peek("ETag"))
+}
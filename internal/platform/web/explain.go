@@ -0,0 +1,108 @@
+package web
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web/middleware"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// Query argument of the explain endpoint.
+const graphFormatArg = "format"
+
+// Formats of the query execution graph.
+const (
+	GraphJSONFormat = "json"
+	GraphDOTFormat  = "dot"
+)
+
+var errInvalidGraphFormat = errors.New("invalid format : must be json or dot")
+
+// ExplainSavedQuery returns the execution graph of a saved query, as a JSON
+// document or as a Graphviz DOT digraph, so its authors can find which
+// statements run in parallel and which ones wait for chained values.
+func (r restQl) ExplainSavedQuery(reqCtx *fasthttp.RequestCtx) error {
+	log := r.log.With("restql-endpoint", string(reqCtx.Request.URI().Path()))
+
+	ctx := middleware.GetNativeContext(reqCtx)
+	ctx = restql.WithLogger(ctx, log)
+
+	options, err := makeQueryRevisionOptions(reqCtx, log, r.config)
+	if err != nil {
+		log.Error("failed to build query options", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	format := string(reqCtx.QueryArgs().Peek(graphFormatArg))
+	if format == "" {
+		format = GraphJSONFormat
+	}
+	if format != GraphJSONFormat && format != GraphDOTFormat {
+		return RespondError(reqCtx, errInvalidGraphFormat, errToStatusCode)
+	}
+
+	graph, err := r.evaluator.QueryGraph(ctx, options)
+	if err != nil {
+		log.Error("failed to build query graph", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	if format == GraphDOTFormat {
+		name := fmt.Sprintf("%s/%s/%d", options.Namespace, options.Id, options.Revision)
+
+		reqCtx.Response.Header.SetContentType("text/vnd.graphviz; charset=utf-8")
+		reqCtx.Response.SetStatusCode(fasthttp.StatusOK)
+		reqCtx.Response.SetBodyString(GraphDOT(name, graph))
+		return nil
+	}
+
+	return Respond(reqCtx, graph, fasthttp.StatusOK, nil)
+}
+
+// GraphDOT renders the query graph as a Graphviz digraph, where
+// statements of the same stage are ranked side by side, edges are
+// labeled with the chained values and hidden statements are dashed.
+func GraphDOT(name string, graph runner.QueryGraph) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "digraph %s {\n", strconv.Quote(name))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, s := range graph.Statements {
+		label := s.Statement
+		if s.Resource != s.Statement {
+			label += "\n" + s.Method + " " + s.Resource
+		}
+
+		attrs := []string{"label=" + strconv.Quote(label)}
+		if s.Hidden {
+			attrs = append(attrs, "style=dashed")
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", strconv.Quote(s.Statement), strings.Join(attrs, ", "))
+	}
+
+	for _, stage := range graph.Stages {
+		if len(stage) < 2 {
+			continue
+		}
+
+		quoted := make([]string, len(stage))
+		for i, s := range stage {
+			quoted[i] = strconv.Quote(s)
+		}
+		fmt.Fprintf(&b, "  { rank=same; %s; }\n", strings.Join(quoted, "; "))
+	}
+
+	for _, e := range graph.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", strconv.Quote(e.From), strconv.Quote(e.To), strconv.Quote(strings.Join(e.Chains, ", ")))
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
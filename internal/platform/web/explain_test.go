@@ -0,0 +1,37 @@
+package web_test
+
+import (
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestGraphDOT(t *testing.T) {
+	graph := runner.QueryGraph{
+		Statements: []runner.GraphStatement{
+			{Statement: "gear", Resource: "weapons", Method: "from", Stage: 1, DependsOn: []string{"hero"}, Hidden: true},
+			{Statement: "hero", Resource: "hero", Method: "from", Stage: 0},
+			{Statement: "villain", Resource: "villain", Method: "from", Stage: 0},
+		},
+		Edges: []runner.GraphEdge{
+			{From: "hero", To: "gear", Chains: []string{"hero.id", "hero.name"}},
+		},
+		Stages:       [][]string{{"hero", "villain"}, {"gear"}},
+		CriticalPath: []string{"hero", "gear"},
+	}
+
+	expected := `digraph "marvel/heroes/1" {
+  rankdir=LR;
+  node [shape=box];
+  "gear" [label="gear\nfrom weapons", style=dashed];
+  "hero" [label="hero"];
+  "villain" [label="villain"];
+  { rank=same; "hero"; "villain"; }
+  "hero" -> "gear" [label="hero.id, hero.name"];
+}
+`
+
+	test.Equal(t, web.GraphDOT("marvel/heroes/1", graph), expected)
+}
@@ -1,24 +1,297 @@
 package web
 
 import (
+	"context"
+	"expvar"
 	"fmt"
+	"sync"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/httpclient"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/persistence"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
 	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+// Resource and dependency states reported by the health endpoints.
+const (
+	HealthUp      = "up"
+	HealthDown    = "down"
+	HealthUnknown = "unknown"
 )
 
+// ResourceCheck represents the state of a mapped resource, either
+// probed on demand or, otherwise, unknown. LastError holds the probe
+// failure or, when there is none, the last failed statement response.
+type ResourceCheck struct {
+	URL        string `json:"url"`
+	Status     string `json:"status"`
+	ProbeURL   string `json:"probeUrl,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	DurationMs int64  `json:"durationMs,omitempty"`
+	LastError  string `json:"lastError,omitempty"`
+}
+
+// DependencyCheck represents the state of a dependency
+// needed by restQL to serve queries.
+type DependencyCheck struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Checker verifies the mapped resources and the
+// dependencies used to serve queries.
+type Checker struct {
+	log          restql.Logger
+	tenant       string
+	mappings     eval.MappingsReader
+	runner       runner.Runner
+	tenants      map[string]conf.TenantDefaultsConf
+	defaults     map[string]conf.DefaultsConf
+	probeTimeout time.Duration
+	client       *fasthttp.Client
+	dependencies map[string]func(ctx context.Context) error
+	snapshot     *persistence.Snapshot
+}
+
+// NewChecker constructs a Checker for the mappings of the
+// given reader and the named dependencies verifications.
+func NewChecker(log restql.Logger, cfg *conf.Config, mr eval.MappingsReader, r runner.Runner, dependencies map[string]func(ctx context.Context) error) *Checker {
+	return &Checker{
+		log:          log,
+		tenant:       cfg.Tenant,
+		mappings:     mr,
+		runner:       r,
+		tenants:      cfg.Defaults.Tenants,
+		defaults:     cfg.Defaults.Mappings,
+		probeTimeout: cfg.Health.ProbeTimeout,
+		client:       &fasthttp.Client{Name: "restql-health"},
+		dependencies: dependencies,
+	}
+}
+
+// Resources reports the state of each mapping of the tenant. When
+// probe is true each upstream is requested concurrently, on the
+// health check path of the mapping or with a HEAD on its base URL.
+// Mappings answered by SQL queries or S3 objects are not probed.
+func (c *Checker) Resources(ctx context.Context, tenant string, probe bool) (map[string]ResourceCheck, error) {
+	mappings, err := c.mappings.FromTenant(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	result := make(map[string]ResourceCheck, len(mappings))
+	for resource, m := range mappings {
+		rc := ResourceCheck{URL: m.URL(), Status: HealthUnknown}
+		if health := c.runner.ResourceHealth(tenant, resource); health != nil && health.LastFailure != nil {
+			rc.LastError = health.LastFailure.Reason
+		}
+
+		if !probe || m.Schema() == httpclient.SQLScheme || m.Schema() == httpclient.S3Scheme || m.Schema() == httpclient.KafkaScheme || m.Schema() == httpclient.AMQPScheme {
+			result[resource] = rc
+			continue
+		}
+
+		wg.Add(1)
+		go func(resource string, m restql.Mapping, rc ResourceCheck) {
+			defer wg.Done()
+			rc = c.probe(m, c.healthPath(tenant, resource), rc)
+
+			mu.Lock()
+			result[resource] = rc
+			mu.Unlock()
+		}(resource, m, rc)
+	}
+	wg.Wait()
+
+	return result, nil
+}
+
+// probe requests the health check path of the mapping, which
+// must answer with a success status, or, without one, the base
+// URL, where any status other than a server error means up.
+func (c *Checker) probe(m restql.Mapping, healthPath string, rc ResourceCheck) ResourceCheck {
+	method, path := fasthttp.MethodGet, healthPath
+	if path == "" {
+		method, path = fasthttp.MethodHead, "/"
+	}
+	rc.ProbeURL = m.Schema() + "://" + m.Host() + path
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	res := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(res)
+
+	client := c.client
+	if m.Schema() == httpclient.UnixScheme {
+		socket, err := httpclient.UnixSocketPath(m.Host())
+		if err != nil {
+			rc.Status = HealthDown
+			rc.LastError = err.Error()
+			return rc
+		}
+
+		client = &fasthttp.Client{Name: c.client.Name, Dial: httpclient.UnixDial(socket)}
+		req.SetConnectionClose()
+	}
+
+	schema, host := httpclient.UpstreamAddr(m.Schema(), m.Host())
+	req.Header.SetMethod(method)
+	req.SetRequestURI(schema + "://" + host + path)
+
+	start := time.Now()
+	err := client.DoTimeout(req, res, c.probeTimeout)
+	rc.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		rc.Status = HealthDown
+		rc.LastError = err.Error()
+		return rc
+	}
+
+	rc.StatusCode = res.StatusCode()
+	healthy := rc.StatusCode < fasthttp.StatusInternalServerError
+	if healthPath != "" {
+		healthy = rc.StatusCode < fasthttp.StatusBadRequest
+	}
+
+	if !healthy {
+		rc.Status = HealthDown
+		rc.LastError = fmt.Sprintf("unhealthy status %d", rc.StatusCode)
+		return rc
+	}
+
+	rc.Status = HealthUp
+	return rc
+}
+
+// healthPath returns the health check path of the mapping,
+// from the tenant mappings defaults or the global ones.
+func (c *Checker) healthPath(tenant string, resource string) string {
+	if td, ok := c.tenants[tenant]; ok {
+		if d, ok := td.Mappings[resource]; ok && d.HealthCheck != nil {
+			return d.HealthCheck.Path
+		}
+	}
+
+	if d, ok := c.defaults[resource]; ok && d.HealthCheck != nil {
+		return d.HealthCheck.Path
+	}
+
+	return ""
+}
+
+// Ready verifies every dependency concurrently, bounded by the probe
+// timeout, and reports if all of them are up along with their states.
+func (c *Checker) Ready(ctx context.Context) (bool, map[string]DependencyCheck) {
+	ctx, cancel := context.WithTimeout(ctx, c.probeTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	ready := true
+	result := make(map[string]DependencyCheck, len(c.dependencies))
+	for name, check := range c.dependencies {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) error) {
+			defer wg.Done()
+			err := check(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				c.log.Warn("dependency not ready", "dependency", name, "error", err)
+				ready = false
+				result[name] = DependencyCheck{Status: HealthDown, Error: err.Error()}
+				return
+			}
+			result[name] = DependencyCheck{Status: HealthUp}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return ready, result
+}
+
 type check struct {
-	build string
+	build   string
+	vars    fasthttp.RequestHandler
+	checker *Checker
 }
 
-func newCheck(build string) check {
-	return check{build: build}
+func newCheck(build string, checker *Checker) check {
+	return check{
+		build:   build,
+		vars:    fasthttpadaptor.NewFastHTTPHandler(expvar.Handler()),
+		checker: checker,
+	}
 }
 
+// Health tells that restQL is running. With the database snapshot
+// enabled, it responds with its state instead, which is stale while
+// the snapshot serves the values the database failed to provide.
 func (c check) Health(ctx *fasthttp.RequestCtx) error {
-	ctx.Response.SetBodyString("I'm healthy! :)")
-	return nil
+	if c.checker.snapshot == nil {
+		ctx.Response.SetBodyString("I'm healthy! :)")
+		return nil
+	}
+
+	data := map[string]interface{}{
+		"status":   HealthUp,
+		"snapshot": c.checker.snapshot.Status(),
+	}
+	return Respond(ctx, data, fasthttp.StatusOK, nil)
 }
 
 func (c check) ResourceStatus(ctx *fasthttp.RequestCtx) error {
 	ctx.Response.SetBodyString(fmt.Sprintf("RestQL is running with build %s", c.build))
 	return nil
 }
+
+// Resources reports the state of each mapping of the tenant,
+// probing the upstreams when the probe parameter is true.
+func (c check) Resources(ctx *fasthttp.RequestCtx) error {
+	tenant, err := makeTenant(ctx, c.checker.tenant)
+	if err != nil {
+		return RespondError(ctx, err, errToStatusCode)
+	}
+
+	probe := ctx.QueryArgs().GetBool("probe")
+	resources, err := c.checker.Resources(ctx, tenant, probe)
+	if err != nil {
+		return RespondError(ctx, err, errToStatusCode)
+	}
+
+	data := map[string]interface{}{
+		"tenant":    tenant,
+		"resources": resources,
+	}
+	return Respond(ctx, data, fasthttp.StatusOK, nil)
+}
+
+// Ready responds with 503 Service Unavailable
+// unless every dependency is up.
+func (c check) Ready(ctx *fasthttp.RequestCtx) error {
+	ready, dependencies := c.checker.Ready(ctx)
+
+	status := fasthttp.StatusOK
+	if !ready {
+		status = fasthttp.StatusServiceUnavailable
+	}
+
+	data := map[string]interface{}{
+		"ready":        ready,
+		"dependencies": dependencies,
+	}
+	return Respond(ctx, data, status, nil)
+}
+
+func (c check) Vars(ctx *fasthttp.RequestCtx) error {
+	c.vars(ctx)
+	return nil
+}
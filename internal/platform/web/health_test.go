@@ -0,0 +1,100 @@
+package web_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+type healthMappings map[string]string
+
+func (h healthMappings) FromTenant(ctx context.Context, tenant string) (map[string]restql.Mapping, error) {
+	result := make(map[string]restql.Mapping, len(h))
+	for resource, url := range h {
+		m, err := restql.NewMapping(resource, url)
+		if err != nil {
+			return nil, err
+		}
+		result[resource] = m
+	}
+	return result, nil
+}
+
+func TestCheckerResources(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/status":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &conf.Config{}
+	cfg.Health.ProbeTimeout = time.Second
+	cfg.Defaults.Mappings = map[string]conf.DefaultsConf{
+		"planets": {HealthCheck: &conf.HealthCheckConf{Path: "/status"}},
+	}
+
+	mappings := healthMappings{
+		"hero":    server.URL + "/api/heroes/:id",
+		"planets": server.URL + "/api/planets",
+		"villain": "http://127.0.0.1:1/api/villains",
+	}
+	r := runner.NewRunner(test.NoOpLogger, runner.Executor{}, 0, runner.DefaultsCascade{}, nil, 0)
+	checker := web.NewChecker(test.NoOpLogger, cfg, mappings, r, nil)
+
+	got, err := checker.Resources(context.Background(), "DEFAULT", false)
+	test.VerifyError(t, err)
+	test.Equal(t, got["hero"], web.ResourceCheck{URL: server.URL + "/api/heroes/:id", Status: web.HealthUnknown})
+	test.Equal(t, atomic.LoadInt32(&requests), int32(0))
+
+	got, err = checker.Resources(context.Background(), "DEFAULT", true)
+	test.VerifyError(t, err)
+
+	test.Equal(t, got["hero"].Status, web.HealthUp)
+	test.Equal(t, got["hero"].ProbeURL, server.URL+"/")
+	test.Equal(t, got["hero"].StatusCode, http.StatusNotFound)
+
+	test.Equal(t, got["planets"].Status, web.HealthDown)
+	test.Equal(t, got["planets"].ProbeURL, server.URL+"/status")
+	test.Equal(t, got["planets"].LastError, "unhealthy status 503")
+
+	test.Equal(t, got["villain"].Status, web.HealthDown)
+	if got["villain"].LastError == "" {
+		t.Errorf("expected probe error for unreachable resource")
+	}
+}
+
+func TestCheckerReady(t *testing.T) {
+	cfg := &conf.Config{}
+	cfg.Health.ProbeTimeout = time.Second
+	r := runner.NewRunner(test.NoOpLogger, runner.Executor{}, 0, runner.DefaultsCascade{}, nil, 0)
+
+	checker := web.NewChecker(test.NoOpLogger, cfg, healthMappings{}, r, map[string]func(ctx context.Context) error{
+		"database":  func(ctx context.Context) error { return nil },
+		"rateLimit": func(ctx context.Context) error { return errors.New("connection refused") },
+	})
+
+	ready, dependencies := checker.Ready(context.Background())
+	test.Equal(t, ready, false)
+
+	expected := map[string]web.DependencyCheck{
+		"database":  {Status: web.HealthUp},
+		"rateLimit": {Status: web.HealthDown, Error: "connection refused"},
+	}
+	test.Equal(t, dependencies, expected)
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	defaultAPIKeyHeader    = "X-Api-Key"
+	defaultNamespacesClaim = "namespaces"
+	allNamespaces          = "*"
+)
+
+// Endpoints running ad-hoc queries, which can call any mapped
+// resource and are only allowed to principals with access to
+// every namespace.
+var adHocQueryPaths = map[string]struct{}{
+	"/run-query":        {},
+	"/run-query/stream": {},
+	"/validate-query":   {},
+	"/explain-query":    {},
+}
+
+// Endpoints running saved queries, whose path starts with
+// the query namespace after the endpoint prefix.
+var savedQueryPrefixes = []string{"/run-query/", "/diff-query/", "/infer-schema/", "/explain/"}
+
+// batchQueryPath is the endpoint running several queries of any
+// kind at once, which are authorized one by one by the handler.
+const batchQueryPath = "/run-queries"
+
+// ErrNamespaceNotAllowed represents the event of running a query
+// of a namespace the authenticated principal has no access to.
+var ErrNamespaceNotAllowed = errors.New("not allowed to run queries of this namespace")
+
+type principalKey struct{}
+
+// AuthorizeNamespace checks that the principal authenticated for the
+// request can run queries of the namespace, which is always the case
+// when authentication is disabled.
+func AuthorizeNamespace(ctx context.Context, namespace string) error {
+	p, ok := ctx.Value(principalKey{}).(principal)
+	if !ok || p.canAccess(namespace) {
+		return nil
+	}
+	return errors.Wrapf(ErrNamespaceNotAllowed, "principal %s on namespace %s", p.name, namespace)
+}
+
+// AuthorizeAdHocQuery checks that the principal authenticated
+// for the request can run ad-hoc queries, which requires access
+// to every namespace.
+func AuthorizeAdHocQuery(ctx context.Context) error {
+	return AuthorizeNamespace(ctx, allNamespaces)
+}
+
+type principal struct {
+	name       string
+	namespaces []string
+}
+
+func (p principal) canAccess(namespace string) bool {
+	for _, ns := range p.namespaces {
+		if ns == allNamespaces || ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+type apiKey struct {
+	key       []byte
+	principal principal
+}
+
+type authentication struct {
+	log             restql.Logger
+	apiKeyHeader    string
+	apiKeys         []apiKey
+	jwt             *jwtValidator
+	namespacesClaim string
+}
+
+func newAuthentication(log restql.Logger, cfg conf.AuthenticationConf) Middleware {
+	a := authentication{log: log, apiKeyHeader: cfg.APIKeyHeader, namespacesClaim: defaultNamespacesClaim}
+	if a.apiKeyHeader == "" {
+		a.apiKeyHeader = defaultAPIKeyHeader
+	}
+
+	for _, k := range cfg.APIKeys {
+		a.apiKeys = append(a.apiKeys, apiKey{key: []byte(k.Key), principal: principal{name: k.Name, namespaces: k.Namespaces}})
+	}
+
+	if j := cfg.JWT; j != nil {
+		v := newJWTValidator(j.Issuer, j.Audience, j.Secret, j.JWKSURL, j.JWKSRefreshInterval, j.Leeway)
+		a.jwt = &v
+		if j.NamespacesClaim != "" {
+			a.namespacesClaim = j.NamespacesClaim
+		}
+	}
+
+	return a
+}
+
+// Apply authenticates the requests to the query endpoints, by API key or
+// JWT bearer token, and authorizes them against the namespaces allowed
+// to the principal. Ad-hoc queries require access to every namespace,
+// while the queries of a batch are authorized with AuthorizeNamespace.
+func (a authentication) Apply(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if ctx.IsOptions() {
+			h(ctx)
+			return
+		}
+
+		path := string(ctx.Path())
+		namespace, protected := protectedNamespace(path)
+		if !protected {
+			h(ctx)
+			return
+		}
+
+		p, ok := a.authenticate(ctx)
+		if !ok {
+			respondAuthError(ctx, fasthttp.StatusUnauthorized, "authentication required")
+			return
+		}
+
+		if !isBatchQuery(path) && !p.canAccess(namespace) {
+			a.log.Debug("request refused by namespace authorization", "principal", p.name, "namespace", namespace)
+			respondAuthError(ctx, fasthttp.StatusForbidden, ErrNamespaceNotAllowed.Error())
+			return
+		}
+
+		nativeCtx := context.WithValue(GetNativeContext(ctx), principalKey{}, p)
+		WithNativeContext(ctx, restql.WithPrincipal(nativeCtx, p.name))
+		h(ctx)
+	}
+}
+
+func (a authentication) authenticate(ctx *fasthttp.RequestCtx) (principal, bool) {
+	if key := ctx.Request.Header.Peek(a.apiKeyHeader); len(key) > 0 {
+		for _, k := range a.apiKeys {
+			if subtle.ConstantTimeCompare(key, k.key) == 1 {
+				return k.principal, true
+			}
+		}
+		return principal{}, false
+	}
+
+	token := string(getBearerToken(ctx))
+	if a.jwt == nil || len(token) < 7 || !strings.EqualFold(token[:7], "bearer ") {
+		return principal{}, false
+	}
+
+	claims, err := a.jwt.Validate(strings.TrimSpace(token[7:]))
+	if err != nil {
+		a.log.Debug("invalid bearer token", "error", err)
+		return principal{}, false
+	}
+
+	subject, _ := claims["sub"].(string)
+	return principal{name: subject, namespaces: claimValues(claims[a.namespacesClaim])}, true
+}
+
+// protectedNamespace returns the namespace a request to a
+// query endpoint needs access to, which for ad-hoc queries
+// is the wildcard of every namespace and for batches is
+// empty, as it depends on each query.
+func protectedNamespace(path string) (string, bool) {
+	path = strings.TrimSuffix(path, "/")
+	if _, ok := adHocQueryPaths[path]; ok {
+		return allNamespaces, true
+	}
+	if isBatchQuery(path) {
+		return "", true
+	}
+
+	for _, prefix := range savedQueryPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			namespace := strings.SplitN(strings.TrimPrefix(path, prefix), "/", 2)[0]
+			return namespace, true
+		}
+	}
+
+	return "", false
+}
+
+func isBatchQuery(path string) bool {
+	return strings.TrimSuffix(path, "/") == batchQueryPath
+}
+
+func respondAuthError(ctx *fasthttp.RequestCtx, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"error": message})
+
+	ctx.Response.Header.SetContentType("application/json; charset=utf-8")
+	ctx.Response.SetStatusCode(status)
+	ctx.Response.SetBody(body)
+}
@@ -0,0 +1,284 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/valyala/fasthttp"
+)
+
+func TestAuthentication(t *testing.T) {
+	secret := "s3cr3t"
+	exp := float64(time.Now().Add(time.Hour).Unix())
+
+	cfg := conf.AuthenticationConf{
+		APIKeys: []conf.APIKeyConf{
+			{Name: "catalog", Key: "catalog-key", Namespaces: []string{"catalog"}},
+			{Name: "ops", Key: "ops-key", Namespaces: []string{"*"}},
+		},
+		JWT: &conf.JWTConf{Issuer: "https://auth.io", Audience: "restql", Secret: secret},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		headers  map[string]string
+		expected int
+	}{
+		{"unprotected path", "/health", nil, http.StatusOK},
+		{"missing credentials", "/run-query/catalog/heroes/1", nil, http.StatusUnauthorized},
+		{"unknown api key", "/run-query/catalog/heroes/1", map[string]string{"X-Api-Key": "nope"}, http.StatusUnauthorized},
+		{"api key allowed namespace", "/run-query/catalog/heroes/1", map[string]string{"X-Api-Key": "catalog-key"}, http.StatusOK},
+		{"api key other namespace", "/run-query/billing/invoices/1", map[string]string{"X-Api-Key": "catalog-key"}, http.StatusForbidden},
+		{"api key ad-hoc query without wildcard", "/run-query", map[string]string{"X-Api-Key": "catalog-key"}, http.StatusForbidden},
+		{"api key ad-hoc query with wildcard", "/run-query", map[string]string{"X-Api-Key": "ops-key"}, http.StatusOK},
+		{"api key saved query without namespace", "/run-query//heroes/1", map[string]string{"X-Api-Key": "catalog-key"}, http.StatusForbidden},
+		{"batch missing credentials", "/run-queries", nil, http.StatusUnauthorized},
+		{"batch unknown api key", "/run-queries", map[string]string{"X-Api-Key": "nope"}, http.StatusUnauthorized},
+		{"batch authorized by query", "/run-queries", map[string]string{"X-Api-Key": "catalog-key"}, http.StatusOK},
+		{
+			"jwt allowed namespace",
+			"/diff-query/catalog/heroes/1",
+			map[string]string{"Authorization": "Bearer " + signHS256(t, secret, map[string]interface{}{"iss": "https://auth.io", "aud": "restql", "exp": exp, "namespaces": []string{"catalog"}})},
+			http.StatusOK,
+		},
+		{
+			"jwt other namespace",
+			"/run-query/billing/invoices/1",
+			map[string]string{"Authorization": "Bearer " + signHS256(t, secret, map[string]interface{}{"iss": "https://auth.io", "aud": "restql", "exp": exp, "namespaces": []string{"catalog"}})},
+			http.StatusForbidden,
+		},
+		{
+			"jwt wrong audience",
+			"/run-query/catalog/heroes/1",
+			map[string]string{"Authorization": "Bearer " + signHS256(t, secret, map[string]interface{}{"iss": "https://auth.io", "aud": "other", "exp": exp, "namespaces": []string{"catalog"}})},
+			http.StatusUnauthorized,
+		},
+		{
+			"jwt expired",
+			"/run-query/catalog/heroes/1",
+			map[string]string{"Authorization": "Bearer " + signHS256(t, secret, map[string]interface{}{"iss": "https://auth.io", "aud": "restql", "exp": float64(time.Now().Add(-time.Hour).Unix()), "namespaces": []string{"catalog"}})},
+			http.StatusUnauthorized,
+		},
+		{
+			"jwt without expiration",
+			"/run-query/catalog/heroes/1",
+			map[string]string{"Authorization": "Bearer " + signHS256(t, secret, map[string]interface{}{"iss": "https://auth.io", "aud": "restql", "namespaces": []string{"catalog"}})},
+			http.StatusUnauthorized,
+		},
+		{
+			"jwt wrong secret",
+			"/run-query/catalog/heroes/1",
+			map[string]string{"Authorization": "Bearer " + signHS256(t, "other", map[string]interface{}{"iss": "https://auth.io", "aud": "restql", "exp": exp, "namespaces": []string{"catalog"}})},
+			http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newAuthentication(test.NoOpLogger, cfg).Apply(func(ctx *fasthttp.RequestCtx) {
+				ctx.SetStatusCode(http.StatusOK)
+			})
+
+			ctx := &fasthttp.RequestCtx{}
+			WithNativeContext(ctx, context.Background())
+			ctx.Request.SetRequestURI(tt.path)
+			for k, v := range tt.headers {
+				ctx.Request.Header.Set(k, v)
+			}
+
+			h(ctx)
+
+			test.Equal(t, ctx.Response.StatusCode(), tt.expected)
+		})
+	}
+}
+
+func TestAuthenticationPrincipal(t *testing.T) {
+	cfg := conf.AuthenticationConf{APIKeys: []conf.APIKeyConf{{Name: "catalog", Key: "catalog-key", Namespaces: []string{"catalog"}}}}
+
+	var principal string
+	h := newAuthentication(test.NoOpLogger, cfg).Apply(func(ctx *fasthttp.RequestCtx) {
+		principal, _ = restql.Principal(GetNativeContext(ctx))
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	WithNativeContext(ctx, context.Background())
+	ctx.Request.SetRequestURI("/run-query/catalog/heroes/1")
+	ctx.Request.Header.Set("X-Api-Key", "catalog-key")
+
+	h(ctx)
+
+	test.Equal(t, principal, "catalog")
+}
+
+func TestAuthorizeNamespace(t *testing.T) {
+	cfg := conf.AuthenticationConf{
+		APIKeys: []conf.APIKeyConf{
+			{Name: "catalog", Key: "catalog-key", Namespaces: []string{"catalog"}},
+			{Name: "ops", Key: "ops-key", Namespaces: []string{"*"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		apiKey    string
+		namespace string
+		adHoc     bool
+		expected  error
+	}{
+		{"allowed namespace", "catalog-key", "catalog", false, nil},
+		{"other namespace", "catalog-key", "billing", false, ErrNamespaceNotAllowed},
+		{"ad-hoc query without wildcard", "catalog-key", "", true, ErrNamespaceNotAllowed},
+		{"ad-hoc query with wildcard", "ops-key", "", true, nil},
+		{"any namespace with wildcard", "ops-key", "billing", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			h := newAuthentication(test.NoOpLogger, cfg).Apply(func(ctx *fasthttp.RequestCtx) {
+				if tt.adHoc {
+					err = AuthorizeAdHocQuery(GetNativeContext(ctx))
+				} else {
+					err = AuthorizeNamespace(GetNativeContext(ctx), tt.namespace)
+				}
+			})
+
+			ctx := &fasthttp.RequestCtx{}
+			WithNativeContext(ctx, context.Background())
+			ctx.Request.SetRequestURI("/run-queries")
+			ctx.Request.Header.Set("X-Api-Key", tt.apiKey)
+
+			h(ctx)
+
+			test.Equal(t, errors.Is(err, tt.expected), true)
+		})
+	}
+
+	t.Run("authentication disabled", func(t *testing.T) {
+		test.VerifyError(t, AuthorizeNamespace(context.Background(), "billing"))
+		test.VerifyError(t, AuthorizeAdHocQuery(context.Background()))
+	})
+}
+
+func TestJWTValidatorWithJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	test.VerifyError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer server.Close()
+
+	v := newJWTValidator("https://auth.io", "", "", server.URL, 0, 0)
+	exp := float64(time.Now().Add(time.Hour).Unix())
+
+	token := signRS256(t, key, "key-1", map[string]interface{}{"iss": "https://auth.io", "sub": "catalog-app", "exp": exp})
+	claims, err := v.Validate(token)
+	test.VerifyError(t, err)
+	test.Equal(t, claims["sub"], "catalog-app")
+
+	_, err = v.Validate(signRS256(t, key, "key-1", map[string]interface{}{"iss": "https://auth.io", "sub": "catalog-app"}))
+	if err != errMissingExpiry {
+		t.Fatalf("expected missing expiration error, got %v", err)
+	}
+
+	_, err = v.Validate(signRS256(t, key, "key-2", map[string]interface{}{"iss": "https://auth.io", "exp": exp}))
+	if err != errUnknownKey {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+
+	_, err = v.Validate(signHS256(t, "secret", map[string]interface{}{"iss": "https://auth.io", "exp": exp}))
+	if err != errUnsupportedAlg {
+		t.Fatalf("expected unsupported algorithm error, got %v", err)
+	}
+}
+
+func TestJWKSRefreshDoesNotBlockKnownKeys(t *testing.T) {
+	release := make(chan struct{})
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		w.Write([]byte(`{"keys":[]}`))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	j := newJWKS(server.URL, time.Minute)
+	j.keys = map[string]crypto.PublicKey{"key-1": &rsa.PublicKey{}}
+	j.fetchedAt = time.Now().Add(-time.Hour)
+
+	done := make(chan error)
+	go func() {
+		_, err := j.Get("key-1")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		test.VerifyError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("stale known key waited for the jwks endpoint")
+	}
+
+	for atomic.LoadInt32(&fetches) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err := j.Get("key-1")
+	test.VerifyError(t, err)
+	test.Equal(t, atomic.LoadInt32(&fetches), int32(1))
+}
+
+func signHS256(t *testing.T, secret string, claims map[string]interface{}) string {
+	input := encodeTokenSegments(t, map[string]string{"alg": "HS256", "typ": "JWT"}, claims)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	input := encodeTokenSegments(t, map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid}, claims)
+
+	digest := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	test.VerifyError(t, err)
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func encodeTokenSegments(t *testing.T, header map[string]string, claims map[string]interface{}) string {
+	h, err := json.Marshal(header)
+	test.VerifyError(t, err)
+	c, err := json.Marshal(claims)
+	test.VerifyError(t, err)
+
+	return base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+}
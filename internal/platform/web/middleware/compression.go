@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// DefaultCompressionMinSize is the response size, in bytes, from
+// which responses are compressed when no threshold is configured.
+const DefaultCompressionMinSize = 1024
+
+// contentCodings are the encodings responses are compressed with,
+// which are appended to their entity tags, so each encoding of a
+// response has its own strong validator.
+var contentCodings = []string{"br", "gzip"}
+
+type compressionOptions struct {
+	MinSize     int
+	GzipLevel   int
+	BrotliLevel int
+}
+
+type compression struct {
+	minSize     int
+	gzipLevel   int
+	brotliLevel int
+}
+
+func newCompression(options compressionOptions) Middleware {
+	c := compression{
+		minSize:     options.MinSize,
+		gzipLevel:   options.GzipLevel,
+		brotliLevel: options.BrotliLevel,
+	}
+
+	if c.minSize <= 0 {
+		c.minSize = DefaultCompressionMinSize
+	}
+
+	if c.gzipLevel <= 0 {
+		c.gzipLevel = fasthttp.CompressDefaultCompression
+	}
+
+	if c.brotliLevel <= 0 {
+		c.brotliLevel = fasthttp.CompressBrotliDefaultCompression
+	}
+
+	return c
+}
+
+func (c compression) Apply(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		h(ctx)
+
+		res := &ctx.Response
+		if res.IsBodyStream() || len(res.Header.Peek(fasthttp.HeaderContentEncoding)) > 0 {
+			return
+		}
+
+		res.Header.Add(fasthttp.HeaderVary, fasthttp.HeaderAcceptEncoding)
+
+		body := res.Body()
+		if len(body) < c.minSize {
+			return
+		}
+
+		encoding := negotiateEncoding(string(ctx.Request.Header.Peek(fasthttp.HeaderAcceptEncoding)))
+		switch encoding {
+		case "br":
+			res.SetBodyRaw(fasthttp.AppendBrotliBytesLevel(nil, body, c.brotliLevel))
+		case "gzip":
+			res.SetBodyRaw(fasthttp.AppendGzipBytesLevel(nil, body, c.gzipLevel))
+		default:
+			return
+		}
+
+		res.Header.Set(fasthttp.HeaderContentEncoding, encoding)
+		if eTag := res.Header.Peek(fasthttp.HeaderETag); len(eTag) > 0 {
+			res.Header.Set(fasthttp.HeaderETag, appendETagCoding(string(eTag), encoding))
+		}
+	}
+}
+
+// appendETagCoding suffixes the opaque tag with the content coding,
+// as in "<tag>-gzip", keeping it weak if it was.
+func appendETagCoding(eTag string, coding string) string {
+	if !strings.HasSuffix(eTag, `"`) {
+		return eTag
+	}
+	return eTag[:len(eTag)-1] + "-" + coding + `"`
+}
+
+// TrimETagCoding removes the content coding appended
+// to the entity tag of a compressed response.
+func TrimETagCoding(eTag string) string {
+	for _, coding := range contentCodings {
+		if suffix := "-" + coding + `"`; strings.HasSuffix(eTag, suffix) {
+			return strings.TrimSuffix(eTag, suffix) + `"`
+		}
+	}
+	return eTag
+}
+
+// negotiateEncoding returns the encoding to be used in the response
+// based on the Accept-Encoding header, preferring brotli over gzip
+// when the client accepts both with the same quality.
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, q := parseEncoding(part)
+		if name != "" {
+			qualities[name] = q
+		}
+	}
+
+	quality := func(encoding string) float64 {
+		if q, found := qualities[encoding]; found {
+			return q
+		}
+		return qualities["*"]
+	}
+
+	br, gzip := quality("br"), quality("gzip")
+	switch {
+	case br > 0 && br >= gzip:
+		return "br"
+	case gzip > 0:
+		return "gzip"
+	default:
+		return ""
+	}
+}
+
+func parseEncoding(part string) (string, float64) {
+	params := strings.Split(part, ";")
+	name := strings.ToLower(strings.TrimSpace(params[0]))
+
+	quality := 1.0
+	for _, p := range params[1:] {
+		p = strings.TrimSpace(p)
+		if !strings.HasPrefix(p, "q=") {
+			continue
+		}
+
+		q, err := strconv.ParseFloat(strings.TrimPrefix(p, "q="), 64)
+		if err != nil {
+			return name, 0
+		}
+		quality = q
+	}
+
+	return name, quality
+}
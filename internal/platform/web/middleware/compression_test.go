@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/valyala/fasthttp"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		expected       string
+	}{
+		{"should not compress without header", "", ""},
+		{"should not compress unsupported encodings", "deflate, identity", ""},
+		{"should use gzip", "gzip", "gzip"},
+		{"should prefer brotli with same quality", "gzip, deflate, br", "br"},
+		{"should use the highest quality", "br;q=0.5, gzip;q=0.8", "gzip"},
+		{"should not use rejected encodings", "br;q=0, gzip", "gzip"},
+		{"should use wildcard for unlisted encodings", "br;q=0, *", "gzip"},
+		{"should reject every encoding", "*;q=0", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.Equal(t, negotiateEncoding(tt.acceptEncoding), tt.expected)
+		})
+	}
+}
+
+func TestCompression(t *testing.T) {
+	body := strings.Repeat("restql", 100)
+	handler := func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.Header.Set(fasthttp.HeaderETag, `"abc"`)
+		ctx.SetBodyString(body)
+	}
+
+	tests := []struct {
+		name             string
+		options          compressionOptions
+		acceptEncoding   string
+		expectedEncoding string
+		expectedETag     string
+	}{
+		{"should compress with brotli", compressionOptions{MinSize: 10}, "br, gzip", "br", `"abc-br"`},
+		{"should compress with gzip", compressionOptions{MinSize: 10}, "gzip", "gzip", `"abc-gzip"`},
+		{"should not compress body smaller than threshold", compressionOptions{MinSize: 1000}, "gzip", "", `"abc"`},
+		{"should not compress when not accepted", compressionOptions{MinSize: 10}, "", "", `"abc"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.Set(fasthttp.HeaderAcceptEncoding, tt.acceptEncoding)
+
+			newCompression(tt.options).Apply(handler)(ctx)
+
+			test.Equal(t, string(ctx.Response.Header.Peek(fasthttp.HeaderContentEncoding)), tt.expectedEncoding)
+			test.Equal(t, string(ctx.Response.Header.Peek(fasthttp.HeaderVary)), fasthttp.HeaderAcceptEncoding)
+			test.Equal(t, string(ctx.Response.Header.Peek(fasthttp.HeaderETag)), tt.expectedETag)
+
+			var got []byte
+			var err error
+			switch tt.expectedEncoding {
+			case "br":
+				got, err = ctx.Response.BodyUnbrotli()
+			case "gzip":
+				got, err = ctx.Response.BodyGunzip()
+			default:
+				got = ctx.Response.Body()
+			}
+
+			test.VerifyError(t, err)
+			test.Equal(t, string(got), body)
+		})
+	}
+}
+
+func TestTrimETagCoding(t *testing.T) {
+	test.Equal(t, TrimETagCoding(`"abc-gzip"`), `"abc"`)
+	test.Equal(t, TrimETagCoding(`"abc-br"`), `"abc"`)
+	test.Equal(t, TrimETagCoding(`"abc"`), `"abc"`)
+	test.Equal(t, TrimETagCoding(`"abc-deflate"`), `"abc-deflate"`)
+}
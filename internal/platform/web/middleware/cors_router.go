@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/valyala/fasthttp"
+)
+
+// corsRouter applies the CORS policy defined for the request path
+// or tenant, falling back to the global policy. Path policies take
+// precedence over tenant ones, and the longest matching path wins.
+type corsRouter struct {
+	log       restql.Logger
+	envTenant string
+	global    *cors
+	tenants   map[string]*cors
+	paths     []corsPath
+}
+
+type corsPath struct {
+	prefix []byte
+	cors   *cors
+}
+
+// newCorsRouter creates a CORS middleware that selects the policy of
+// each request, where the tenant is taken from the environment or,
+// when it is not defined, from the `tenant` query argument.
+func newCorsRouter(log restql.Logger, envTenant string, global corsOptions, tenants map[string]conf.CorsRuleConf, paths map[string]conf.CorsRuleConf) *corsRouter {
+	cr := &corsRouter{
+		log:       log,
+		envTenant: envTenant,
+		global:    newCors(log, global),
+		tenants:   make(map[string]*cors, len(tenants)),
+	}
+
+	for tenant, rule := range tenants {
+		cr.tenants[tenant] = newCors(log, mergeCorsRule(global, rule))
+	}
+
+	for prefix, rule := range paths {
+		cr.paths = append(cr.paths, corsPath{prefix: []byte(prefix), cors: newCors(log, mergeCorsRule(global, rule))})
+	}
+	sort.Slice(cr.paths, func(i, j int) bool {
+		return len(cr.paths[i].prefix) > len(cr.paths[j].prefix)
+	})
+
+	return cr
+}
+
+// Apply wraps a request handler with the CORS middleware
+// of the policy selected for each request.
+func (cr *corsRouter) Apply(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	handlers := make(map[*cors]fasthttp.RequestHandler, len(cr.tenants)+len(cr.paths)+1)
+	handlers[cr.global] = cr.global.Apply(h)
+	for _, c := range cr.tenants {
+		handlers[c] = c.Apply(h)
+	}
+	for _, p := range cr.paths {
+		handlers[p.cors] = p.cors.Apply(h)
+	}
+
+	return func(ctx *fasthttp.RequestCtx) {
+		handlers[cr.policy(ctx)](ctx)
+	}
+}
+
+func (cr *corsRouter) policy(ctx *fasthttp.RequestCtx) *cors {
+	path := ctx.Path()
+	for _, p := range cr.paths {
+		if matchPathPrefix(path, p.prefix) {
+			return p.cors
+		}
+	}
+
+	tenant := cr.envTenant
+	if tenant == "" {
+		tenant = string(ctx.QueryArgs().Peek("tenant"))
+	}
+
+	if c, found := cr.tenants[tenant]; found {
+		return c
+	}
+
+	return cr.global
+}
+
+// matchPathPrefix reports if the path is the prefix
+// itself or one of its sub paths.
+func matchPathPrefix(path, prefix []byte) bool {
+	prefix = bytes.TrimSuffix(prefix, []byte("/"))
+	if !bytes.HasPrefix(path, prefix) {
+		return false
+	}
+
+	return len(path) == len(prefix) || path[len(prefix)] == '/'
+}
+
+// mergeCorsRule returns the options of the rule, where
+// the undefined ones are taken from the base options.
+func mergeCorsRule(base corsOptions, rule conf.CorsRuleConf) corsOptions {
+	options := base
+
+	if rule.AllowOrigin != "" {
+		options.AllowedOrigins = rule.AllowOrigin
+	}
+	if rule.AllowMethods != "" {
+		options.AllowedMethods = rule.AllowMethods
+	}
+	if rule.AllowHeaders != "" {
+		options.AllowedHeaders = rule.AllowHeaders
+	}
+	if rule.ExposeHeaders != "" {
+		options.ExposedHeaders = rule.ExposeHeaders
+	}
+	if rule.MaxAge > 0 {
+		options.MaxAge = rule.MaxAge
+	}
+	if rule.AllowCredentials != nil {
+		options.AllowCredentials = *rule.AllowCredentials
+	}
+
+	return options
+}
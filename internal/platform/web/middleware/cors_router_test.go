@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/valyala/fasthttp"
+)
+
+func TestCorsRouter(t *testing.T) {
+	allowCredentials := true
+	global := corsOptions{AllowedOrigins: "http://restql.io", AllowedMethods: "GET", MaxAge: 10}
+	tenants := map[string]conf.CorsRuleConf{
+		"DC": {AllowOrigin: "http://dc.com", AllowCredentials: &allowCredentials},
+	}
+	paths := map[string]conf.CorsRuleConf{
+		"/run-query":               {AllowOrigin: "http://queries.io"},
+		"/run-query/hero-catalog/": {AllowOrigin: "http://heroes.io", AllowMethods: "GET, POST", MaxAge: 60},
+	}
+
+	cases := []struct {
+		name       string
+		envTenant  string
+		uri        string
+		origin     string
+		resHeaders map[string]string
+	}{
+		{
+			"global policy",
+			"",
+			"http://example.com/validate-query?tenant=MARVEL",
+			"http://restql.io",
+			map[string]string{
+				"Vary":                         "Origin, Access-Control-Request-Method, Access-Control-Request-Headers",
+				"Access-Control-Allow-Origin":  "http://restql.io",
+				"Access-Control-Allow-Methods": "GET",
+				"Access-Control-Allow-Headers": "Origin, Accept, Content-Type, X-Requested-With",
+				"Access-Control-Max-Age":       "10",
+			},
+		},
+		{
+			"tenant policy",
+			"",
+			"http://example.com/validate-query?tenant=DC",
+			"http://dc.com",
+			map[string]string{
+				"Vary":                             "Origin, Access-Control-Request-Method, Access-Control-Request-Headers",
+				"Access-Control-Allow-Origin":      "http://dc.com",
+				"Access-Control-Allow-Methods":     "GET",
+				"Access-Control-Allow-Headers":     "Origin, Accept, Content-Type, X-Requested-With",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "10",
+			},
+		},
+		{
+			"environment tenant policy",
+			"DC",
+			"http://example.com/validate-query?tenant=MARVEL",
+			"http://dc.com",
+			map[string]string{
+				"Vary":                             "Origin, Access-Control-Request-Method, Access-Control-Request-Headers",
+				"Access-Control-Allow-Origin":      "http://dc.com",
+				"Access-Control-Allow-Methods":     "GET",
+				"Access-Control-Allow-Headers":     "Origin, Accept, Content-Type, X-Requested-With",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "10",
+			},
+		},
+		{
+			"longest path policy over tenant",
+			"",
+			"http://example.com/run-query/hero-catalog/fetch-hero/1?tenant=DC",
+			"http://heroes.io",
+			map[string]string{
+				"Vary":                         "Origin, Access-Control-Request-Method, Access-Control-Request-Headers",
+				"Access-Control-Allow-Origin":  "http://heroes.io",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Origin, Accept, Content-Type, X-Requested-With",
+				"Access-Control-Max-Age":       "60",
+			},
+		},
+		{
+			"path prefix matches whole segments",
+			"",
+			"http://example.com/run-query/hero-catalog-v2/fetch-hero/1",
+			"http://queries.io",
+			map[string]string{
+				"Vary":                         "Origin, Access-Control-Request-Method, Access-Control-Request-Headers",
+				"Access-Control-Allow-Origin":  "http://queries.io",
+				"Access-Control-Allow-Methods": "GET",
+				"Access-Control-Allow-Headers": "Origin, Accept, Content-Type, X-Requested-With",
+				"Access-Control-Max-Age":       "10",
+			},
+		},
+	}
+
+	for i := range cases {
+		tc := cases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			cr := newCorsRouter(test.NoOpLogger, tc.envTenant, global, tenants, paths)
+
+			ctx := fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod("OPTIONS")
+			ctx.Request.SetRequestURI(tc.uri)
+			ctx.Request.Header.Add("Origin", tc.origin)
+			ctx.Request.Header.Add("Access-Control-Request-Method", "GET")
+
+			cr.Apply(testHandler)(&ctx)
+			assertHeaders(t, &ctx.Response.Header, tc.resHeaders)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+const defaultFaultInjectionHeader = "X-RestQL-Faults"
+
+// ErrFaultInjectionNotAllowed represents the event of enabling
+// fault injection on an instance where it is not allowed.
+var ErrFaultInjectionNotAllowed = errors.New("fault injection is not allowed")
+
+// FaultInjection switches the injection of the faults declared for
+// the mappings in the queries, either for every one of them or only
+// for the ones requesting it by header. Nothing is injected unless
+// it is allowed by the configuration.
+type FaultInjection struct {
+	allowed bool
+	header  string
+	enabled int32
+}
+
+// NewFaultInjection returns a FaultInjection in the state
+// defined by the configuration.
+func NewFaultInjection(cfg conf.FaultInjectionConf) *FaultInjection {
+	header := cfg.Header
+	if header == "" {
+		header = defaultFaultInjectionHeader
+	}
+
+	fi := &FaultInjection{allowed: cfg.Allowed, header: header}
+	if cfg.Allowed && cfg.Enabled {
+		fi.enabled = 1
+	}
+
+	return fi
+}
+
+// Allowed reports if faults can be injected at all.
+func (fi *FaultInjection) Allowed() bool {
+	return fi != nil && fi.allowed
+}
+
+// Enabled reports if faults are injected in every query.
+func (fi *FaultInjection) Enabled() bool {
+	return fi != nil && atomic.LoadInt32(&fi.enabled) == 1
+}
+
+// SetEnabled defines if faults are injected in every query.
+func (fi *FaultInjection) SetEnabled(enabled bool) error {
+	if !fi.Allowed() {
+		return ErrFaultInjectionNotAllowed
+	}
+
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&fi.enabled, value)
+
+	return nil
+}
+
+// Apply marks the request context for fault injection when it is
+// enabled, where a boolean header value overrides the switch state
+// for the request.
+func (fi *FaultInjection) Apply(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if !fi.Allowed() {
+		return h
+	}
+
+	return func(ctx *fasthttp.RequestCtx) {
+		enabled := fi.Enabled()
+		if value := ctx.Request.Header.Peek(fi.header); len(value) > 0 {
+			if v, err := strconv.ParseBool(string(value)); err == nil {
+				enabled = v
+			}
+		}
+
+		if enabled {
+			WithNativeContext(ctx, domain.WithFaultInjection(GetNativeContext(ctx)))
+		}
+
+		h(ctx)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/valyala/fasthttp"
+)
+
+func TestFaultInjection(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      conf.FaultInjectionConf
+		header   string
+		expected bool
+	}{
+		{"not allowed", conf.FaultInjectionConf{Enabled: true}, "true", false},
+		{"allowed and disabled", conf.FaultInjectionConf{Allowed: true}, "", false},
+		{"allowed and enabled", conf.FaultInjectionConf{Allowed: true, Enabled: true}, "", true},
+		{"enabled by header", conf.FaultInjectionConf{Allowed: true}, "true", true},
+		{"disabled by header", conf.FaultInjectionConf{Allowed: true, Enabled: true}, "false", false},
+		{"invalid header value", conf.FaultInjectionConf{Allowed: true, Enabled: true}, "maybe", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bool
+			h := NewFaultInjection(tt.cfg).Apply(func(reqCtx *fasthttp.RequestCtx) {
+				got = domain.FaultInjectionEnabled(GetNativeContext(reqCtx))
+			})
+
+			reqCtx := &fasthttp.RequestCtx{}
+			WithNativeContext(reqCtx, context.Background())
+			if tt.header != "" {
+				reqCtx.Request.Header.Set(defaultFaultInjectionHeader, tt.header)
+			}
+
+			h(reqCtx)
+
+			test.Equal(t, got, tt.expected)
+		})
+	}
+}
+
+func TestFaultInjectionSwitch(t *testing.T) {
+	fi := NewFaultInjection(conf.FaultInjectionConf{Allowed: true})
+	test.Equal(t, fi.Enabled(), false)
+
+	test.VerifyError(t, fi.SetEnabled(true))
+	test.Equal(t, fi.Enabled(), true)
+
+	test.VerifyError(t, fi.SetEnabled(false))
+	test.Equal(t, fi.Enabled(), false)
+
+	notAllowed := NewFaultInjection(conf.FaultInjectionConf{})
+	test.Equal(t, errors.Is(notAllowed.SetEnabled(true), ErrFaultInjectionNotAllowed), true)
+	test.Equal(t, notAllowed.Enabled(), false)
+}
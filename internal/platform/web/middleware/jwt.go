@@ -0,0 +1,410 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // registers the SHA-256 hash for crypto.Hash
+	_ "crypto/sha512" // registers the SHA-384 and SHA-512 hashes for crypto.Hash
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultJWKSRefreshInterval = 10 * time.Minute
+	minJWKSRefetchInterval     = 30 * time.Second
+)
+
+var (
+	errMalformedToken   = errors.New("malformed token")
+	errUnsupportedAlg   = errors.New("unsupported signing algorithm")
+	errInvalidSignature = errors.New("invalid token signature")
+	errUnknownKey       = errors.New("unknown signing key")
+	errTokenExpired     = errors.New("token expired")
+	errMissingExpiry    = errors.New("token without expiration")
+	errTokenNotYetValid = errors.New("token not yet valid")
+	errInvalidIssuer    = errors.New("invalid token issuer")
+	errInvalidAudience  = errors.New("invalid token audience")
+)
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims map[string]interface{}
+
+// jwtValidator verifies the signature and the registered
+// claims of JSON Web Tokens, signed with HMAC, RSA or ECDSA.
+type jwtValidator struct {
+	issuer   string
+	audience string
+	secret   []byte
+	keys     *jwks
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+func newJWTValidator(issuer, audience, secret, jwksURL string, refreshInterval, leeway time.Duration) jwtValidator {
+	v := jwtValidator{issuer: issuer, audience: audience, secret: []byte(secret), leeway: leeway, now: time.Now}
+	if jwksURL != "" {
+		v.keys = newJWKS(jwksURL, refreshInterval)
+	}
+
+	return v
+}
+
+func (v jwtValidator) Validate(token string) (jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errMalformedToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errMalformedToken
+	}
+
+	if err := v.verify(header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (v jwtValidator) verify(header jwtHeader, signingInput string, signature []byte) error {
+	hash, ok := jwtHashes[header.Alg]
+	if !ok {
+		return errUnsupportedAlg
+	}
+
+	if strings.HasPrefix(header.Alg, "HS") {
+		if len(v.secret) == 0 {
+			return errUnsupportedAlg
+		}
+
+		mac := hmac.New(hash.New, v.secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errInvalidSignature
+		}
+		return nil
+	}
+
+	if v.keys == nil {
+		return errUnsupportedAlg
+	}
+
+	key, err := v.keys.Get(header.Kid)
+	if err != nil {
+		return err
+	}
+
+	h := hash.New()
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(header.Alg, "RS") {
+			return errUnsupportedAlg
+		}
+		if err := rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+			return errInvalidSignature
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(header.Alg, "ES") {
+			return errUnsupportedAlg
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return errInvalidSignature
+		}
+	default:
+		return errUnsupportedAlg
+	}
+
+	return nil
+}
+
+var jwtHashes = map[string]crypto.Hash{
+	"HS256": crypto.SHA256,
+	"HS384": crypto.SHA384,
+	"HS512": crypto.SHA512,
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+func (v jwtValidator) validateClaims(claims jwtClaims) error {
+	now := v.now()
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errMissingExpiry
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(v.leeway)) {
+		return errTokenExpired
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(v.leeway).Before(time.Unix(int64(nbf), 0)) {
+		return errTokenNotYetValid
+	}
+
+	if v.issuer != "" && claims["iss"] != v.issuer {
+		return errInvalidIssuer
+	}
+
+	if v.audience != "" && !containsString(claimValues(claims["aud"]), v.audience) {
+		return errInvalidAudience
+	}
+
+	return nil
+}
+
+// claimValues returns the values of a claim holding a list of
+// strings or a single string with space separated values.
+func claimValues(claim interface{}) []string {
+	switch claim := claim.(type) {
+	case string:
+		return strings.Fields(claim)
+	case []interface{}:
+		values := make([]string, 0, len(claim))
+		for _, c := range claim {
+			if s, ok := c.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errMalformedToken
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return errMalformedToken
+	}
+
+	return nil
+}
+
+// jwks keeps the public keys of a JSON Web Key Set endpoint,
+// fetching them again once the refresh interval is over or
+// when a token is signed by an unknown key, but no more than
+// once every 30 seconds. Known keys are kept if it fails.
+//
+// Only tokens signed by unknown keys wait for the endpoint,
+// sharing a single fetch, while stale keys are refreshed in
+// the background and keep being used meanwhile.
+type jwks struct {
+	url             string
+	refreshInterval time.Duration
+	client          *http.Client
+	group           singleflight.Group
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+func newJWKS(url string, refreshInterval time.Duration) *jwks {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefreshInterval
+	}
+
+	return &jwks{url: url, refreshInterval: refreshInterval, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (j *jwks) Get(kid string) (crypto.PublicKey, error) {
+	key, found, stale := j.lookup(kid)
+	if found {
+		if stale {
+			j.group.DoChan("", j.refresh)
+		}
+		return key, nil
+	}
+
+	if _, err, _ := j.group.Do("", j.refresh); err != nil {
+		return nil, err
+	}
+
+	if key, found, _ = j.lookup(kid); !found {
+		return nil, errUnknownKey
+	}
+
+	return key, nil
+}
+
+func (j *jwks) lookup(kid string) (crypto.PublicKey, bool, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	key, found := j.keys[kid]
+	return key, found, time.Since(j.fetchedAt) >= j.refreshInterval
+}
+
+// refresh fetches the keys unless it was attempted recently,
+// holding the lock only to replace them.
+func (j *jwks) refresh() (interface{}, error) {
+	j.mu.Lock()
+	due := time.Since(j.attemptedAt) >= minJWKSRefetchInterval
+	if due {
+		j.attemptedAt = time.Now()
+	}
+	j.mu.Unlock()
+
+	if !due {
+		return nil, nil
+	}
+
+	keys, err := j.fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.mu.Unlock()
+
+	return nil, nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (j *jwks) fetch() (map[string]crypto.PublicKey, error) {
+	response, err := j.client.Get(j.url)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch jwks")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch jwks : status %d", response.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&set); err != nil {
+		return nil, errors.Wrap(err, "failed to decode jwks")
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := parseJSONWebKey(k)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+var jwkCurves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+func parseJSONWebKey(k jsonWebKey) (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curve, ok := jwkCurves[k.Crv]
+		if !ok {
+			return nil, errUnsupportedAlg
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errUnsupportedAlg
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
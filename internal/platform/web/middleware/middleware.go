@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/ratelimit"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
 	"github.com/valyala/fasthttp"
 )
@@ -27,10 +28,13 @@ type Decorator struct {
 	cfg *conf.Config
 	pm  plugins.Lifecycle
 	cm  *ConnManager
+	rl  *ratelimit.Limiter
+	fi  *FaultInjection
 }
 
-// NewDecorator creates a middleware Decorator
-func NewDecorator(log restql.Logger, cfg *conf.Config, pm plugins.Lifecycle) *Decorator {
+// NewDecorator creates a middleware Decorator, where the rate
+// limiter and fault injection are only defined when enabled.
+func NewDecorator(log restql.Logger, cfg *conf.Config, pm plugins.Lifecycle, rl *ratelimit.Limiter, fi *FaultInjection) *Decorator {
 	cmEnabled := cfg.HTTP.Server.Middlewares.RequestCancellation.Enabled
 	cmWatchingInterval := cfg.HTTP.Server.Middlewares.RequestCancellation.WatchInterval
 
@@ -39,6 +43,8 @@ func NewDecorator(log restql.Logger, cfg *conf.Config, pm plugins.Lifecycle) *De
 		cfg: cfg,
 		pm:  pm,
 		cm:  NewConnManager(log, cmEnabled, cmWatchingInterval),
+		rl:  rl,
+		fi:  fi,
 	}
 }
 
@@ -58,7 +64,7 @@ func (d *Decorator) Apply(h fasthttp.RequestHandler) fasthttp.RequestHandler {
 }
 
 func (d *Decorator) fetchEnabled() []Middleware {
-	mws := []Middleware{newRecoverer(d.log), newNativeContext(d.cm), newTransaction(d.pm)}
+	mws := []Middleware{newRecoverer(d.log), newTenantSelector(d.cfg.HTTP.TenantHeader), newNativeContext(d.cm), newTraceContext(), newTransaction(d.pm)}
 
 	mwCfg := d.cfg.HTTP.Server.Middlewares
 	if mwCfg.Timeout != nil {
@@ -70,15 +76,41 @@ func (d *Decorator) fetchEnabled() []Middleware {
 	}
 
 	if mwCfg.Cors != nil {
-		cors := newCors(d.log, corsOptions{
+		global := corsOptions{
 			AllowedOrigins:   mwCfg.Cors.AllowOrigin,
 			AllowedMethods:   mwCfg.Cors.AllowMethods,
 			AllowedHeaders:   mwCfg.Cors.AllowHeaders,
 			ExposedHeaders:   mwCfg.Cors.ExposeHeaders,
 			MaxAge:           mwCfg.Cors.MaxAge,
 			AllowCredentials: mwCfg.Cors.AllowCredentials,
+		}
+
+		if len(mwCfg.Cors.Tenants) == 0 && len(mwCfg.Cors.Paths) == 0 {
+			mws = append(mws, newCors(d.log, global))
+		} else {
+			mws = append(mws, newCorsRouter(d.log, d.cfg.Tenant, global, mwCfg.Cors.Tenants, mwCfg.Cors.Paths))
+		}
+	}
+
+	if mwCfg.Compression != nil {
+		compression := newCompression(compressionOptions{
+			MinSize:     mwCfg.Compression.MinSize,
+			GzipLevel:   mwCfg.Compression.GzipLevel,
+			BrotliLevel: mwCfg.Compression.BrotliLevel,
 		})
-		mws = append(mws, cors)
+		mws = append(mws, compression)
+	}
+
+	if mwCfg.Authentication != nil {
+		mws = append(mws, newAuthentication(d.log, *mwCfg.Authentication))
+	}
+
+	if d.rl != nil && d.cfg.RateLimit != nil {
+		mws = append(mws, newRateLimit(d.log, d.rl, *d.cfg.RateLimit, d.cfg.Tenant))
+	}
+
+	if d.fi.Allowed() {
+		mws = append(mws, d.fi)
 	}
 
 	if d.cfg.HTTP.Server.Admin.Enable {
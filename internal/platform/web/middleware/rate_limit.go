@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/ratelimit"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+const defaultClientIDHeader = "X-Client-Id"
+
+type rateLimit struct {
+	log            restql.Logger
+	limiter        *ratelimit.Limiter
+	clientIDHeader string
+	envTenant      string
+}
+
+func newRateLimit(log restql.Logger, limiter *ratelimit.Limiter, cfg conf.RateLimitConf, envTenant string) Middleware {
+	rl := rateLimit{log: log, limiter: limiter, clientIDHeader: cfg.ClientIDHeader, envTenant: envTenant}
+	if rl.clientIDHeader == "" {
+		rl.clientIDHeader = defaultClientIDHeader
+	}
+
+	return rl
+}
+
+// Apply limits the rate of requests to the query endpoints by client,
+// identified by the client ID header or else by the remote address,
+// and by tenant, where a batch takes a token for each of its queries.
+// Rejected requests are answered with 429 Too Many Requests and the
+// Retry-After header.
+func (rl rateLimit) Apply(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if ctx.IsOptions() {
+			h(ctx)
+			return
+		}
+
+		path := string(ctx.Path())
+		if _, isQuery := protectedNamespace(path); !isQuery {
+			h(ctx)
+			return
+		}
+
+		cost := 1
+		if isBatchQuery(path) {
+			cost = batchSize(ctx.PostBody())
+		}
+
+		client := string(ctx.Request.Header.Peek(rl.clientIDHeader))
+		if client == "" {
+			client = ctx.RemoteIP().String()
+		}
+
+		allowed, wait, err := rl.limiter.AllowClientN(ctx, client, cost)
+		if errors.Is(err, ratelimit.ErrCostExceedsBurst) {
+			rl.log.Debug("batch refused by client rate limit burst", "client", client, "queries", cost)
+			respondBatchTooLarge(ctx, "batch has more queries than the client rate limit allows at once")
+			return
+		}
+		if !allowed {
+			rl.log.Debug("request refused by client rate limit", "client", client)
+			respondRateLimited(ctx, wait, "client rate limit exceeded")
+			return
+		}
+
+		tenant := rl.envTenant
+		if tenant == "" {
+			tenant = string(ctx.QueryArgs().Peek("tenant"))
+		}
+
+		if tenant != "" {
+			allowed, wait, err := rl.limiter.AllowTenantN(ctx, tenant, cost)
+			if errors.Is(err, ratelimit.ErrCostExceedsBurst) {
+				rl.log.Debug("batch refused by tenant rate limit burst", "tenant", tenant, "queries", cost)
+				respondBatchTooLarge(ctx, "batch has more queries than the tenant rate limit allows at once")
+				return
+			}
+			if !allowed {
+				rl.log.Debug("request refused by tenant rate limit", "tenant", tenant)
+				respondRateLimited(ctx, wait, "tenant rate limit exceeded")
+				return
+			}
+		}
+
+		h(ctx)
+	}
+}
+
+// batchSize returns the number of queries in the batch request body,
+// being at least one, as invalid batches are still answered.
+func batchSize(body []byte) int {
+	var batch struct {
+		Queries map[string]json.RawMessage `json:"queries"`
+	}
+	if err := json.Unmarshal(body, &batch); err != nil || len(batch.Queries) == 0 {
+		return 1
+	}
+	return len(batch.Queries)
+}
+
+// respondBatchTooLarge refuses batches that could never be
+// admitted, instead of asking the client to retry them.
+func respondBatchTooLarge(ctx *fasthttp.RequestCtx, message string) {
+	body, _ := json.Marshal(map[string]string{"error": message})
+
+	ctx.Response.Header.SetContentType("application/json; charset=utf-8")
+	ctx.Response.SetStatusCode(fasthttp.StatusRequestEntityTooLarge)
+	ctx.Response.SetBody(body)
+}
+
+func respondRateLimited(ctx *fasthttp.RequestCtx, wait time.Duration, message string) {
+	body, _ := json.Marshal(map[string]string{"error": message})
+
+	seconds := int64((wait + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	ctx.Response.Header.Set("Retry-After", strconv.FormatInt(seconds, 10))
+	ctx.Response.Header.SetContentType("application/json; charset=utf-8")
+	ctx.Response.SetStatusCode(fasthttp.StatusTooManyRequests)
+	ctx.Response.SetBody(body)
+}
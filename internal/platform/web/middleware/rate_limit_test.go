@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/ratelimit"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRateLimit(t *testing.T) {
+	cfg := conf.RateLimitConf{
+		Tenant:  &conf.RateLimitRuleConf{Rate: 0.5, Burst: 3},
+		Client:  &conf.RateLimitRuleConf{Rate: 0.5, Burst: 2},
+		Clients: map[string]conf.RateLimitRuleConf{"batch": {Rate: 0.5, Burst: 1}},
+	}
+
+	h := newRateLimit(test.NoOpLogger, ratelimit.New(test.NoOpLogger, cfg), cfg, "").Apply(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(http.StatusOK)
+	})
+
+	do := func(path string, client string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(path)
+		if client != "" {
+			ctx.Request.Header.Set("X-Client-Id", client)
+		}
+		h(ctx)
+		return ctx
+	}
+
+	test.Equal(t, do("/run-query?tenant=DEFAULT", "batch").Response.StatusCode(), http.StatusOK)
+
+	ctx := do("/run-query?tenant=DEFAULT", "batch")
+	test.Equal(t, ctx.Response.StatusCode(), http.StatusTooManyRequests)
+	test.Equal(t, string(ctx.Response.Header.Peek("Retry-After")), "2")
+	test.Equal(t, string(ctx.Response.Body()), `{"error":"client rate limit exceeded"}`)
+
+	test.Equal(t, do("/run-query/catalog/heroes/1?tenant=DEFAULT", "web").Response.StatusCode(), http.StatusOK)
+	test.Equal(t, do("/run-query/catalog/heroes/1?tenant=DEFAULT", "web").Response.StatusCode(), http.StatusOK)
+
+	ctx = do("/run-query/catalog/heroes/1?tenant=DEFAULT", "mobile")
+	test.Equal(t, ctx.Response.StatusCode(), http.StatusTooManyRequests)
+	test.Equal(t, string(ctx.Response.Body()), `{"error":"tenant rate limit exceeded"}`)
+
+	test.Equal(t, do("/run-query/catalog/heroes/1?tenant=OTHER", "mobile").Response.StatusCode(), http.StatusOK)
+	test.Equal(t, do("/health", "batch").Response.StatusCode(), http.StatusOK)
+}
+
+func TestRateLimitBatch(t *testing.T) {
+	cfg := conf.RateLimitConf{
+		Tenant: &conf.RateLimitRuleConf{Rate: 0.5, Burst: 4},
+		Client: &conf.RateLimitRuleConf{Rate: 0.5, Burst: 2},
+	}
+
+	h := newRateLimit(test.NoOpLogger, ratelimit.New(test.NoOpLogger, cfg), cfg, "").Apply(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(http.StatusOK)
+	})
+
+	do := func(client string, queries ...string) *fasthttp.RequestCtx {
+		batch := make(map[string]interface{}, len(queries))
+		for _, q := range queries {
+			batch[q] = map[string]string{"text": "from " + q}
+		}
+		body, _ := json.Marshal(map[string]interface{}{"queries": batch})
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(http.MethodPost)
+		ctx.Request.SetRequestURI("/run-queries?tenant=DEFAULT")
+		ctx.Request.Header.Set("X-Client-Id", client)
+		ctx.Request.SetBody(body)
+		h(ctx)
+		return ctx
+	}
+
+	ctx := do("web", "heroes", "villains", "sidekicks")
+	test.Equal(t, ctx.Response.StatusCode(), http.StatusRequestEntityTooLarge)
+	test.Equal(t, len(ctx.Response.Header.Peek("Retry-After")), 0)
+	test.Equal(t, string(ctx.Response.Body()), `{"error":"batch has more queries than the client rate limit allows at once"}`)
+
+	test.Equal(t, do("web", "heroes", "villains").Response.StatusCode(), http.StatusOK)
+
+	ctx = do("web", "heroes")
+	test.Equal(t, ctx.Response.StatusCode(), http.StatusTooManyRequests)
+	test.Equal(t, string(ctx.Response.Body()), `{"error":"client rate limit exceeded"}`)
+
+	test.Equal(t, do("mobile", "heroes", "villains").Response.StatusCode(), http.StatusOK)
+
+	ctx = do("app", "heroes")
+	test.Equal(t, ctx.Response.StatusCode(), http.StatusTooManyRequests)
+	test.Equal(t, string(ctx.Response.Body()), `{"error":"tenant rate limit exceeded"}`)
+
+	test.Equal(t, do("app").Response.StatusCode(), http.StatusTooManyRequests)
+
+	tenantCfg := conf.RateLimitConf{Tenant: &conf.RateLimitRuleConf{Rate: 0.5, Burst: 2}}
+	h = newRateLimit(test.NoOpLogger, ratelimit.New(test.NoOpLogger, tenantCfg), tenantCfg, "").Apply(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(http.StatusOK)
+	})
+
+	ctx = do("web", "heroes", "villains", "sidekicks")
+	test.Equal(t, ctx.Response.StatusCode(), http.StatusRequestEntityTooLarge)
+	test.Equal(t, string(ctx.Response.Body()), `{"error":"batch has more queries than the tenant rate limit allows at once"}`)
+}
@@ -37,6 +37,9 @@ func newRequestID(header string, strategy string, log restql.Logger) Middleware
 	}
 }
 
+// Apply adopts the request id sent by the caller or generates
+// a new one, making it available to the query execution through
+// the request context and returning it in the response headers.
 func (r requestID) Apply(h fasthttp.RequestHandler) fasthttp.RequestHandler {
 	return func(ctx *fasthttp.RequestCtx) {
 		var requestID string
@@ -50,6 +53,8 @@ func (r requestID) Apply(h fasthttp.RequestHandler) fasthttp.RequestHandler {
 			requestID = string(currentRequestID)
 		}
 
+		WithNativeContext(ctx, restql.WithRequestID(GetNativeContext(ctx), requestID))
+
 		h(ctx)
 
 		ctx.Response.Header.Set(r.header, requestID)
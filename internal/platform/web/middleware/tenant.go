@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"bytes"
+
+	"github.com/valyala/fasthttp"
+)
+
+const tenantArg = "tenant"
+
+var tenantPathPrefix = []byte("/tenants/")
+
+type tenantSelector struct {
+	header string
+}
+
+func newTenantSelector(header string) Middleware {
+	return tenantSelector{header: header}
+}
+
+// Apply sets the `tenant` query parameter, read by the handlers and the
+// other middlewares, from the `/tenants/{tenant}` path prefix, which is
+// removed before routing, or, when absent, from the tenant header.
+func (ts tenantSelector) Apply(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(reqCtx *fasthttp.RequestCtx) {
+		if tenant, path, ok := splitTenantPath(reqCtx.Path()); ok {
+			reqCtx.URI().SetPath(path)
+			reqCtx.QueryArgs().Set(tenantArg, tenant)
+		} else if ts.header != "" && len(reqCtx.QueryArgs().Peek(tenantArg)) == 0 {
+			if tenant := reqCtx.Request.Header.Peek(ts.header); len(tenant) > 0 {
+				reqCtx.QueryArgs().SetBytesV(tenantArg, tenant)
+			}
+		}
+
+		h(reqCtx)
+	}
+}
+
+// splitTenantPath returns the tenant and the remaining
+// path of a path in the `/tenants/{tenant}/...` form.
+func splitTenantPath(path []byte) (string, string, bool) {
+	if !bytes.HasPrefix(path, tenantPathPrefix) {
+		return "", "", false
+	}
+
+	rest := path[len(tenantPathPrefix):]
+	i := bytes.IndexByte(rest, '/')
+	if i <= 0 {
+		return "", "", false
+	}
+
+	return string(rest[:i]), string(rest[i:]), true
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/valyala/fasthttp"
+)
+
+func TestTenantSelector(t *testing.T) {
+	tests := []struct {
+		name           string
+		uri            string
+		header         string
+		expectedPath   string
+		expectedTenant string
+	}{
+		{"tenant from path", "/tenants/acme/run-query/ns/query/1?id=1", "", "/run-query/ns/query/1", "acme"},
+		{"path tenant takes precedence", "/tenants/acme/run-query?tenant=other", "umbrella", "/run-query", "acme"},
+		{"tenant from header", "/run-query", "umbrella", "/run-query", "umbrella"},
+		{"query parameter takes precedence over header", "/run-query?tenant=other", "umbrella", "/run-query", "other"},
+		{"incomplete tenant path", "/tenants/acme", "", "/tenants/acme", ""},
+		{"no tenant", "/run-query", "", "/run-query", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path, tenant string
+			h := newTenantSelector("X-Tenant").Apply(func(reqCtx *fasthttp.RequestCtx) {
+				path = string(reqCtx.Path())
+				tenant = string(reqCtx.QueryArgs().Peek(tenantArg))
+			})
+
+			reqCtx := &fasthttp.RequestCtx{}
+			reqCtx.Request.SetRequestURI(tt.uri)
+			if tt.header != "" {
+				reqCtx.Request.Header.Set("X-Tenant", tt.header)
+			}
+
+			h(reqCtx)
+
+			test.Equal(t, path, tt.expectedPath)
+			test.Equal(t, tenant, tt.expectedTenant)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/valyala/fasthttp"
+)
+
+const traceParentHeader = "traceparent"
+
+type traceContext struct{}
+
+func newTraceContext() Middleware {
+	return traceContext{}
+}
+
+// Apply makes the trace identifier received in the W3C `traceparent`
+// header available to the lifecycle plugins through the request context.
+func (t traceContext) Apply(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(reqCtx *fasthttp.RequestCtx) {
+		traceID, ok := parseTraceParent(string(reqCtx.Request.Header.Peek(traceParentHeader)))
+		if ok {
+			WithNativeContext(reqCtx, restql.WithTraceID(GetNativeContext(reqCtx), traceID))
+		}
+
+		h(reqCtx)
+	}
+}
+
+// parseTraceParent returns the trace identifier of a `traceparent`
+// header value, formatted as `version-traceid-parentid-flags`.
+func parseTraceParent(value string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return "", false
+	}
+
+	traceID, parentID := parts[1], parts[2]
+	if !isLowerHex(traceID, 32) || !isLowerHex(parentID, 16) || !isLowerHex(parts[3], 2) {
+		return "", false
+	}
+
+	if traceID == strings.Repeat("0", 32) || parentID == strings.Repeat("0", 16) {
+		return "", false
+	}
+
+	return traceID, true
+}
+
+func isLowerHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/valyala/fasthttp"
+)
+
+func TestTraceContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceParent string
+		expected    string
+	}{
+		{"valid header", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"future version with more fields", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"no header", "", ""},
+		{"invalid version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ""},
+		{"upper case trace id", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", ""},
+		{"all zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", ""},
+		{"short parent id", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa-01", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := newTraceContext().Apply(func(reqCtx *fasthttp.RequestCtx) {
+				got, _ = restql.TraceID(GetNativeContext(reqCtx))
+			})
+
+			reqCtx := &fasthttp.RequestCtx{}
+			WithNativeContext(reqCtx, context.Background())
+			if tt.traceParent != "" {
+				reqCtx.Request.Header.Set(traceParentHeader, tt.traceParent)
+			}
+
+			h(reqCtx)
+
+			test.Equal(t, got, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+type ndjsonLine struct {
+	ID      string      `json:"id"`
+	Index   *int        `json:"index,omitempty"`
+	Details interface{} `json:"details"`
+	Result  interface{} `json:"result,omitempty"`
+}
+
+// ndjsonWarnings is the last line of a NDJSON
+// query response when there are warnings.
+type ndjsonWarnings struct {
+	ID     string           `json:"id"`
+	Result []domain.Warning `json:"result"`
+}
+
+// marshalNDJSON writes each statement result as a JSON line,
+// ordered by resource identifier. Multiplexed statements are
+// split into one line per item, identified by its index,
+// followed by a line with the warnings, when there are any.
+func marshalNDJSON(body map[string]StatementResult, warnings []domain.Warning) ([]byte, error) {
+	ids := make([]string, 0, len(body))
+	for id := range body {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+
+	for _, id := range ids {
+		statement := body[id]
+
+		details, isMultiplexed := statement.Details.([]interface{})
+		if !isMultiplexed {
+			if err := encoder.Encode(ndjsonLine{ID: id, Details: statement.Details, Result: statement.Result}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		results, _ := statement.Result.([]interface{})
+		for i := range details {
+			index := i
+			line := ndjsonLine{ID: id, Index: &index, Details: details[i]}
+			if i < len(results) {
+				line.Result = results[i]
+			}
+
+			if err := encoder.Encode(line); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(warnings) > 0 {
+		if err := encoder.Encode(ndjsonWarnings{ID: warningsField, Result: warnings}); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,110 @@
+package web_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRespondQueryWithNDJSON(t *testing.T) {
+	tests := []struct {
+		name                string
+		accept              string
+		response            web.QueryResponse
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			"should write json when ndjson is not accepted",
+			"application/json",
+			web.QueryResponse{
+				StatusCode: http.StatusOK,
+				Body:       map[string]web.StatementResult{"hero": {Details: map[string]interface{}{"status": 200}, Result: map[string]interface{}{"id": "1"}}},
+			},
+			"application/json; charset=utf-8",
+			`{"hero":{"details":{"status":200},"result":{"id":"1"}}}` + "\n",
+		},
+		{
+			"should write one line per statement ordered by id",
+			"application/x-ndjson",
+			web.QueryResponse{
+				StatusCode: http.StatusOK,
+				Body: map[string]web.StatementResult{
+					"sidekick": {Details: map[string]interface{}{"status": 200}, Result: map[string]interface{}{"id": "2"}},
+					"hero":     {Details: map[string]interface{}{"status": 200}, Result: map[string]interface{}{"id": "1"}},
+				},
+			},
+			"application/x-ndjson",
+			`{"id":"hero","details":{"status":200},"result":{"id":"1"}}` + "\n" +
+				`{"id":"sidekick","details":{"status":200},"result":{"id":"2"}}` + "\n",
+		},
+		{
+			"should write one line per item of multiplexed statement",
+			"text/plain, application/x-ndjson; q=0.9",
+			web.QueryResponse{
+				StatusCode: http.StatusOK,
+				Body: map[string]web.StatementResult{
+					"hero": {
+						Details: []interface{}{map[string]interface{}{"status": 200}, map[string]interface{}{"status": 404}},
+						Result:  []interface{}{map[string]interface{}{"id": "1"}, nil},
+					},
+				},
+			},
+			"application/x-ndjson",
+			`{"id":"hero","index":0,"details":{"status":200},"result":{"id":"1"}}` + "\n" +
+				`{"id":"hero","index":1,"details":{"status":404}}` + "\n",
+		},
+		{
+			"should write warnings in json",
+			"application/json",
+			web.QueryResponse{
+				StatusCode: http.StatusOK,
+				Body:       map[string]web.StatementResult{"hero": {Details: map[string]interface{}{"status": 200}}},
+				Warnings:   []domain.Warning{{Code: domain.FilterMissWarning, Statement: "hero", Message: "only field nmae was not found in the response"}},
+			},
+			"application/json; charset=utf-8",
+			`{"_warnings":[{"code":"filter-miss","statement":"hero","message":"only field nmae was not found in the response"}],"hero":{"details":{"status":200}}}` + "\n",
+		},
+		{
+			"should write warnings as last ndjson line",
+			"application/x-ndjson",
+			web.QueryResponse{
+				StatusCode: http.StatusOK,
+				Body:       map[string]web.StatementResult{"hero": {Details: map[string]interface{}{"status": 200}}},
+				Warnings:   []domain.Warning{{Code: domain.InvalidModifierWarning, Message: "use timeout expects an integer value and was ignored"}},
+			},
+			"application/x-ndjson",
+			`{"id":"hero","details":{"status":200}}` + "\n" +
+				`{"id":"_warnings","result":[{"code":"invalid-modifier","message":"use timeout expects an integer value and was ignored"}]}` + "\n",
+		},
+		{
+			"should write ndjson for unsuccessful responses",
+			"application/x-ndjson",
+			web.QueryResponse{
+				StatusCode: http.StatusBadGateway,
+				Body:       map[string]web.StatementResult{"hero": {Details: map[string]interface{}{"status": 502}}},
+			},
+			"application/x-ndjson",
+			`{"id":"hero","details":{"status":502}}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(http.MethodGet)
+			ctx.Request.Header.Set("Accept", tt.accept)
+
+			err := web.RespondQuery(ctx, tt.response)
+
+			test.VerifyError(t, err)
+			test.Equal(t, ctx.Response.StatusCode(), tt.response.StatusCode)
+			test.Equal(t, string(ctx.Response.Header.ContentType()), tt.expectedContentType)
+			test.Equal(t, string(ctx.Response.Body()), tt.expectedBody)
+		})
+	}
+}
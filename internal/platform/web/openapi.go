@@ -0,0 +1,136 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+const openAPIVersion = "3.1.0"
+
+// openAPI returns an OpenAPI document describing the endpoints that run
+// each revision of the saved queries of the namespace. The parameters
+// come from the query variables, typed by the sampled test case when
+// there is one, and the responses from the sample or, without it, from
+// the statements and their `only` projections.
+func (g ClientGenerator) openAPI(ctx context.Context, namespace string, ids []string, queries map[string][]restql.SavedQuery) (string, error) {
+	paths := make(map[string]interface{})
+	for _, id := range ids {
+		revisions := queries[id]
+		sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+
+		for _, sq := range revisions {
+			cq, err := g.describeRevision(ctx, namespace, id, sq)
+			if err != nil {
+				return "", err
+			}
+			paths[queryPath(namespace, cq)] = openAPIPathItem(namespace, cq)
+		}
+	}
+
+	doc := map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":   fmt.Sprintf("restQL %s saved queries", namespace),
+			"version": "1",
+		},
+		"paths": paths,
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal openapi document")
+	}
+
+	return string(data) + "\n", nil
+}
+
+func openAPIPathItem(namespace string, cq clientQuery) map[string]interface{} {
+	summary := fmt.Sprintf("Runs the revision %d of the %s/%s saved query", cq.revision, namespace, cq.id)
+	operationID := fmt.Sprintf("%sRevision%d", cq.typeName, cq.revision)
+	tenant := map[string]interface{}{
+		"name":        "tenant",
+		"in":          "query",
+		"description": "Tenant whose mappings are used, when not defined in configuration",
+		"schema":      map[string]interface{}{"type": SchemaString},
+	}
+	responses := map[string]interface{}{
+		"200": map[string]interface{}{
+			"description": "The statement results",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": cq.response},
+			},
+		},
+		"default": map[string]interface{}{
+			"description": "The statement results, with the status of the failed ones",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": cq.response},
+			},
+		},
+	}
+
+	parameters := []interface{}{tenant}
+	for _, name := range variableNames(cq.params) {
+		parameters = append(parameters, map[string]interface{}{
+			"name":        name,
+			"in":          "query",
+			"required":    containsName(cq.required, name),
+			"description": variableDescription(cq.usages[name]),
+			"schema":      cq.params.Properties[name],
+		})
+	}
+
+	body := &JSONSchema{Types: cq.params.Types, Properties: cq.params.Properties, Required: cq.required}
+
+	return map[string]interface{}{
+		"get": map[string]interface{}{
+			"operationId": "get" + operationID,
+			"summary":     summary,
+			"parameters":  parameters,
+			"responses":   responses,
+		},
+		"post": map[string]interface{}{
+			"operationId": "post" + operationID,
+			"summary":     summary,
+			"parameters":  []interface{}{tenant},
+			"requestBody": map[string]interface{}{
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": body},
+				},
+			},
+			"responses": responses,
+		},
+	}
+}
+
+func variableNames(params *JSONSchema) []string {
+	names := make([]string, 0, len(params.Properties))
+	for name := range params.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func variableDescription(usages []string) string {
+	if len(usages) == 0 {
+		return "Query variable"
+	}
+
+	return "Query variable used by " + strings.Join(usages, ", ")
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package web_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/parser"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/persistence"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"gopkg.in/yaml.v2"
+)
+
+const openAPIConfig = `
+mappings:
+  hero: http://hero.io/api/:id
+  sidekick: http://sidekick.io/api
+queries:
+  heroes:
+    get-hero:
+      - |
+        from hero with id = $id, universe = $universe -> default("dc")
+          only name, weapons.name
+    list-heroes:
+      - |
+        from hero
+          only name, weapons.name
+queryTests:
+  heroes:
+    get-hero:
+      - name: returns the hero
+        params:
+          id: 1
+        fixtures:
+          hero:
+            body: {name: batman, weapons: [{name: belt}]}
+        expected:
+          hero: {name: batman, weapons: [{name: belt}]}
+`
+
+func TestGenerateOpenAPI(t *testing.T) {
+	var cfg conf.Config
+	err := yaml.Unmarshal([]byte(openAPIConfig), &cfg)
+	test.VerifyError(t, err)
+	cfg.Tenant = "DC"
+
+	p, err := parser.New()
+	test.VerifyError(t, err)
+
+	db, err := persistence.NewDatabase(test.NoOpLogger, true)
+	test.VerifyError(t, err)
+
+	mr := persistence.NewMappingReader(test.NoOpLogger, conf.EnvSource{}, cfg.Mappings, cfg.TenantMappings, db)
+	qr := persistence.NewQueryReader(test.NoOpLogger, cfg.Queries, db)
+	qt := web.NewQueryTester(test.NoOpLogger, &cfg, mr, qr, p)
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	doc, err := web.NewClientGenerator(test.NoOpLogger, qr, qt).Generate(ctx, "heroes", web.OpenAPIDocument, "")
+	test.VerifyError(t, err)
+
+	var got map[string]interface{}
+	test.VerifyError(t, json.Unmarshal([]byte(doc), &got))
+
+	test.Equal(t, got["openapi"], "3.1.0")
+
+	operation := got["paths"].(map[string]interface{})["/run-query/heroes/get-hero/1"].(map[string]interface{})["get"].(map[string]interface{})
+	test.Equal(t, operation["operationId"], "getGetHeroRevision1")
+
+	expectedParameters := []interface{}{
+		map[string]interface{}{
+			"name":        "tenant",
+			"in":          "query",
+			"description": "Tenant whose mappings are used, when not defined in configuration",
+			"schema":      map[string]interface{}{"type": "string"},
+		},
+		map[string]interface{}{
+			"name":        "id",
+			"in":          "query",
+			"required":    true,
+			"description": "Query variable used by hero.id",
+			"schema":      map[string]interface{}{"type": "integer"},
+		},
+		map[string]interface{}{
+			"name":        "universe",
+			"in":          "query",
+			"required":    false,
+			"description": "Query variable used by hero.universe",
+			"schema":      map[string]interface{}{},
+		},
+	}
+	test.Equal(t, operation["parameters"], expectedParameters)
+
+	response := operation["responses"].(map[string]interface{})["200"].(map[string]interface{})
+	schema := response["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+	test.Equal(t, schema["required"], []interface{}{"hero"})
+
+	operation = got["paths"].(map[string]interface{})["/run-query/heroes/list-heroes/1"].(map[string]interface{})["get"].(map[string]interface{})
+	response = operation["responses"].(map[string]interface{})["200"].(map[string]interface{})
+	schema = response["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+	hero := schema["properties"].(map[string]interface{})["hero"].(map[string]interface{})
+	result := hero["properties"].(map[string]interface{})["result"].(map[string]interface{})
+
+	test.Equal(t, result["type"], []interface{}{"array", "object"})
+	test.Equal(t, result["items"].(map[string]interface{})["properties"], map[string]interface{}{
+		"name": map[string]interface{}{},
+		"weapons": map[string]interface{}{
+			"type":       []interface{}{"array", "object"},
+			"properties": map[string]interface{}{"name": map[string]interface{}{}},
+			"items":      map[string]interface{}{"type": "object", "properties": map[string]interface{}{"name": map[string]interface{}{}}},
+		},
+	})
+}
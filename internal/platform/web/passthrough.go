@@ -0,0 +1,73 @@
+package web
+
+import (
+	"context"
+	"mime"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/valyala/fasthttp"
+)
+
+// passThroughResource returns the result of the query single statement
+// when the client asked for pass-through and the upstream body is still
+// in the form it was received. Debug mode disables it, since the
+// debugging information is part of the response envelope.
+func passThroughResource(ctx context.Context, input restql.QueryInput, result domain.Resources) (restql.DoneResource, bool) {
+	if !isPassThroughEnabled(input) || isDebugEnabled(input) {
+		return restql.DoneResource{}, false
+	}
+
+	resourceID, eligible := eval.PassThroughResource(ctx)
+	if !eligible {
+		return restql.DoneResource{}, false
+	}
+
+	dr, ok := result[resourceID].(restql.DoneResource)
+	if !ok || dr.ResponseBody == nil {
+		return restql.DoneResource{}, false
+	}
+
+	body := dr.ResponseBody
+	if body.Value() != nil || len(body.Bytes()) == 0 {
+		return restql.DoneResource{}, false
+	}
+
+	return dr, true
+}
+
+// respondPassThrough writes the upstream body of the statement as the
+// query response, without decoding and encoding it again, along with
+// the headers and status code the query response would have.
+func (r restQl) respondPassThrough(ctx context.Context, reqCtx *fasthttp.RequestCtx, result domain.Resources, dr restql.DoneResource) error {
+	headers := makeHeaders(result)
+	setStalenessHeader(ctx, headers)
+	setQueryHeaders(ctx, headers)
+	reqCtx.SetUserValue(passThroughResponseKey, true)
+
+	return r.writeResponse(ctx, reqCtx, result, restql.QueryResponse{
+		Status:      CalculateStatusCode(result),
+		ContentType: passThroughContentType(dr.ResponseHeaders),
+		Header:      headers,
+		Body:        dr.ResponseBody.Bytes(),
+	})
+}
+
+// passThroughContentType returns the upstream content type, unless
+// the body was converted to JSON when received, as msgpack bodies are.
+func passThroughContentType(headers map[string]string) string {
+	for key, value := range headers {
+		if !strings.EqualFold(key, fasthttp.HeaderContentType) {
+			continue
+		}
+
+		mediaType, _, err := mime.ParseMediaType(value)
+		if err == nil && queryMediaTypes[mediaType] != msgpackContentType {
+			return value
+		}
+	}
+
+	return "application/json; charset=utf-8"
+}
@@ -10,12 +10,14 @@ import (
 type debug struct {
 	index   fasthttp.RequestHandler
 	profile fasthttp.RequestHandler
+	trace   fasthttp.RequestHandler
 }
 
 func newDebug() debug {
 	return debug{
 		index:   fasthttpadaptor.NewFastHTTPHandlerFunc(pprof.Index),
 		profile: fasthttpadaptor.NewFastHTTPHandlerFunc(pprof.Profile),
+		trace:   fasthttpadaptor.NewFastHTTPHandlerFunc(pprof.Trace),
 	}
 }
 
@@ -30,3 +32,9 @@ func (d debug) Profile(ctx *fasthttp.RequestCtx) error {
 	d.profile(ctx)
 	return nil
 }
+
+func (d debug) Trace(ctx *fasthttp.RequestCtx) error {
+	log.Printf("[DEBUG] trace requested")
+	d.trace(ctx)
+	return nil
+}
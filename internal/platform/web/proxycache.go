@@ -0,0 +1,244 @@
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/cache"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/valyala/fasthttp"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	xCacheHeader = "X-Cache"
+	ageHeader    = "Age"
+
+	cacheHit  = "HIT"
+	cacheMiss = "MISS"
+)
+
+// User values used by the query handlers to expose
+// the response written to the proxy cache.
+const (
+	queryResponseKey       = "restql-query-response"
+	passThroughResponseKey = "restql-pass-through-response"
+)
+
+// proxyCache makes the pass-through queries behave as if restQL was
+// a caching reverse proxy: identical concurrent requests are coalesced
+// into a single query execution, and single statement responses are
+// kept while fresh according to their Cache-Control header.
+type proxyCache struct {
+	entries *cache.ProxyCache
+	group   singleflight.Group
+}
+
+func newProxyCache(entries *cache.ProxyCache) *proxyCache {
+	return &proxyCache{entries: entries}
+}
+
+type proxyResult struct {
+	response restql.QueryResponse
+	shared   bool
+}
+
+// Handle wraps a query handler, serving its requests from the
+// cache or from another in-flight request when possible.
+func (p *proxyCache) Handle(next handler) handler {
+	return func(reqCtx *fasthttp.RequestCtx) error {
+		if !acceptsProxyCache(reqCtx) {
+			return next(reqCtx)
+		}
+
+		key := proxyCacheKey(reqCtx)
+		if !hasCacheDirective(reqCtx.Request.Header.Peek("Cache-Control"), "no-cache") {
+			if entry, ok := p.entries.Get(key); ok {
+				return writeProxyResponse(reqCtx, entry.Response, entry.Age())
+			}
+		}
+
+		executed := false
+		var nextErr error
+		v, _, _ := p.group.Do(key, func() (interface{}, error) {
+			executed = true
+			nextErr = next(reqCtx)
+
+			response, ok := reqCtx.UserValue(queryResponseKey).(restql.QueryResponse)
+			if nextErr != nil || !ok {
+				return proxyResult{}, nil
+			}
+
+			if isPassThrough, _ := reqCtx.UserValue(passThroughResponseKey).(bool); isPassThrough {
+				p.entries.Set(key, response, proxyCacheTTL(response))
+			}
+
+			return proxyResult{response: response, shared: true}, nil
+		})
+
+		if executed {
+			reqCtx.Response.Header.Set(xCacheHeader, cacheMiss)
+			return nextErr
+		}
+
+		result := v.(proxyResult)
+		if !result.shared {
+			reqCtx.Response.Header.Set(xCacheHeader, cacheMiss)
+			return next(reqCtx)
+		}
+
+		return writeProxyResponse(reqCtx, result.response, 0)
+	}
+}
+
+// acceptsProxyCache checks if the request asks for a pass-through
+// response, without debugging it, and does not carry credentials,
+// whose responses must not be shared between clients.
+func acceptsProxyCache(reqCtx *fasthttp.RequestCtx) bool {
+	args := reqCtx.QueryArgs()
+	if !isArgEnabled(args, passThroughParamName) || isArgEnabled(args, debugParamName) || isArgEnabled(args, dryRunParamName) {
+		return false
+	}
+
+	header := &reqCtx.Request.Header
+	if len(header.Peek("Authorization")) > 0 || len(header.Peek("Cookie")) > 0 {
+		return false
+	}
+
+	return !hasCacheDirective(header.Peek("Cache-Control"), "no-store")
+}
+
+func isArgEnabled(args *fasthttp.Args, name string) bool {
+	enabled, err := strconv.ParseBool(string(args.Peek(name)))
+	return err == nil && enabled
+}
+
+// proxyCacheKey identifies the request by its method, path, query
+// arguments in any order, negotiated media type and body.
+func proxyCacheKey(reqCtx *fasthttp.RequestCtx) string {
+	var args fasthttp.Args
+	reqCtx.QueryArgs().CopyTo(&args)
+	args.Sort(bytes.Compare)
+
+	h := sha256.New()
+	h.Write(reqCtx.Method())
+	h.Write([]byte{'\n'})
+	h.Write(reqCtx.Path())
+	h.Write([]byte{'\n'})
+	h.Write(args.QueryString())
+	h.Write([]byte{'\n'})
+	h.Write(reqCtx.Request.Header.Peek("Accept"))
+	h.Write([]byte{'\n'})
+	h.Write(reqCtx.PostBody())
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// proxyCacheTTL returns the freshness lifetime of the response
+// as seen by a shared cache, which is zero for responses that
+// are not successful, not public, that set cookies or that
+// vary on request headers not identifying the cache entry.
+// Upstream headers are prefixed by the statement identifier.
+func proxyCacheTTL(response restql.QueryResponse) time.Duration {
+	if response.Status != http.StatusOK {
+		return 0
+	}
+
+	var cacheControl string
+	for key, value := range response.Header {
+		key = strings.ToLower(key)
+		switch {
+		case key == "set-cookie" || strings.HasSuffix(key, "-set-cookie"):
+			return 0
+		case (key == "vary" || strings.HasSuffix(key, "-vary")) && variesOnRequestHeaders(value):
+			return 0
+		case key == "cache-control":
+			cacheControl = value
+		}
+	}
+
+	maxAge, sMaxAge := -1, -1
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value := splitCacheDirective(directive)
+		switch name {
+		case "no-store", "no-cache", "private":
+			return 0
+		case "max-age":
+			maxAge, _ = strconv.Atoi(value)
+		case "s-maxage":
+			sMaxAge, _ = strconv.Atoi(value)
+		}
+	}
+
+	if sMaxAge >= 0 {
+		return time.Duration(sMaxAge) * time.Second
+	}
+	if maxAge >= 0 {
+		return time.Duration(maxAge) * time.Second
+	}
+
+	return 0
+}
+
+// proxyCacheVaryHeaders are the request headers that do not
+// make responses for the same cache key differ, either because
+// they are part of it or because they are not sent upstream.
+var proxyCacheVaryHeaders = []string{"accept", "accept-encoding", "origin"}
+
+func variesOnRequestHeaders(vary string) bool {
+	for _, header := range strings.Split(vary, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !containsFold(proxyCacheVaryHeaders, header) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasCacheDirective(cacheControl []byte, directive string) bool {
+	for _, d := range strings.Split(string(cacheControl), ",") {
+		if name, _ := splitCacheDirective(d); name == directive {
+			return true
+		}
+	}
+
+	return false
+}
+
+func splitCacheDirective(directive string) (string, string) {
+	name, value := strings.TrimSpace(directive), ""
+	if i := strings.IndexByte(name, '='); i >= 0 {
+		name, value = name[:i], strings.Trim(name[i+1:], `"`)
+	}
+
+	return strings.ToLower(name), value
+}
+
+// writeProxyResponse writes a response produced for another request,
+// informing how long ago, in seconds, it was produced.
+func writeProxyResponse(reqCtx *fasthttp.RequestCtx, response restql.QueryResponse, age time.Duration) error {
+	err := writeQueryBody(reqCtx, response.ContentType, response.Body, response.Status, response.Header)
+	if err != nil {
+		return err
+	}
+
+	reqCtx.Response.Header.Set(xCacheHeader, cacheHit)
+	reqCtx.Response.Header.Set(ageHeader, strconv.Itoa(int(age.Seconds())))
+	return nil
+}
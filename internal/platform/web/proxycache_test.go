@@ -0,0 +1,179 @@
+package web
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/cache"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/valyala/fasthttp"
+)
+
+func TestProxyCache(t *testing.T) {
+	passThroughHandler := func(calls *int64, headers map[string]string) handler {
+		return func(reqCtx *fasthttp.RequestCtx) error {
+			atomic.AddInt64(calls, 1)
+
+			reqCtx.SetUserValue(passThroughResponseKey, true)
+			response := restql.QueryResponse{Status: http.StatusOK, ContentType: "application/json", Header: headers, Body: []byte(`{"id":1}`)}
+			reqCtx.SetUserValue(queryResponseKey, response)
+
+			return writeQueryBody(reqCtx, response.ContentType, response.Body, response.Status, response.Header)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		uri           string
+		headers       map[string]string
+		requestHeader map[string]string
+		expectedCalls int64
+		expectedCache string
+	}{
+		{
+			"fresh response is served from cache",
+			"/run-query/ns/q/1?_passthrough=true&name=batman",
+			map[string]string{"Cache-Control": "max-age=60"},
+			nil,
+			1,
+			cacheHit,
+		},
+		{
+			"query arguments order does not matter",
+			"/run-query/ns/q/1?name=batman&_passthrough=true",
+			map[string]string{"Cache-Control": "max-age=60"},
+			nil,
+			1,
+			cacheHit,
+		},
+		{
+			"response without freshness is not stored",
+			"/run-query/ns/q/1?_passthrough=true",
+			map[string]string{"Cache-Control": "no-cache"},
+			nil,
+			2,
+			cacheMiss,
+		},
+		{
+			"response varying on request headers is not stored",
+			"/run-query/ns/q/1?_passthrough=true",
+			map[string]string{"Cache-Control": "max-age=60", "hero-Vary": "X-Api-Key"},
+			nil,
+			2,
+			cacheMiss,
+		},
+		{
+			"request without pass-through is not cached",
+			"/run-query/ns/q/1",
+			map[string]string{"Cache-Control": "max-age=60"},
+			nil,
+			2,
+			"",
+		},
+		{
+			"request with credentials is not cached",
+			"/run-query/ns/q/1?_passthrough=true",
+			map[string]string{"Cache-Control": "max-age=60"},
+			map[string]string{"Authorization": "Bearer token"},
+			2,
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int64
+			pc := newProxyCache(cache.NewProxyCache(test.NoOpLogger, 10))
+			h := pc.Handle(passThroughHandler(&calls, tt.headers))
+
+			var reqCtx *fasthttp.RequestCtx
+			for i := 0; i < 2; i++ {
+				reqCtx = &fasthttp.RequestCtx{}
+				reqCtx.Request.SetRequestURI(tt.uri)
+				for k, v := range tt.requestHeader {
+					reqCtx.Request.Header.Set(k, v)
+				}
+
+				err := h(reqCtx)
+				test.VerifyError(t, err)
+			}
+
+			test.Equal(t, atomic.LoadInt64(&calls), tt.expectedCalls)
+			test.Equal(t, string(reqCtx.Response.Header.Peek(xCacheHeader)), tt.expectedCache)
+			test.Equal(t, string(reqCtx.Response.Body()), `{"id":1}`)
+		})
+	}
+}
+
+func TestProxyCacheCoalescesConcurrentRequests(t *testing.T) {
+	var calls int64
+	release := make(chan struct{})
+	next := func(reqCtx *fasthttp.RequestCtx) error {
+		atomic.AddInt64(&calls, 1)
+		<-release
+
+		response := restql.QueryResponse{Status: http.StatusOK, ContentType: "application/json", Header: map[string]string{"Cache-Control": "no-store"}, Body: []byte(`{"id":1}`)}
+		reqCtx.SetUserValue(queryResponseKey, response)
+
+		return writeQueryBody(reqCtx, response.ContentType, response.Body, response.Status, response.Header)
+	}
+
+	pc := newProxyCache(cache.NewProxyCache(test.NoOpLogger, 10))
+	h := pc.Handle(next)
+
+	results := make([]string, 3)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			reqCtx := &fasthttp.RequestCtx{}
+			reqCtx.Request.SetRequestURI("/run-query/ns/q/1?_passthrough=true")
+			if err := h(reqCtx); err != nil {
+				t.Error(err)
+			}
+
+			results[i] = string(reqCtx.Response.Header.Peek(xCacheHeader)) + " " + string(reqCtx.Response.Body())
+		}(i)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	test.Equal(t, atomic.LoadInt64(&calls), int64(1))
+
+	misses := 0
+	for _, r := range results {
+		if r == cacheMiss+` {"id":1}` {
+			misses++
+		} else {
+			test.Equal(t, r, cacheHit+` {"id":1}`)
+		}
+	}
+	test.Equal(t, misses, 1)
+}
+
+func TestProxyCacheAge(t *testing.T) {
+	entries := cache.NewProxyCache(test.NoOpLogger, 10)
+	pc := newProxyCache(entries)
+	h := pc.Handle(func(reqCtx *fasthttp.RequestCtx) error {
+		t.Fatal("handler should not be called for cached responses")
+		return nil
+	})
+
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI("/run-query/ns/q/1?_passthrough=true")
+	entries.Set(proxyCacheKey(reqCtx), restql.QueryResponse{Status: http.StatusOK, ContentType: "application/json", Body: []byte(`{}`)}, time.Minute)
+
+	err := h(reqCtx)
+	test.VerifyError(t, err)
+
+	test.Equal(t, string(reqCtx.Response.Header.Peek(xCacheHeader)), cacheHit)
+	test.Equal(t, string(reqCtx.Response.Header.Peek(ageHeader)), "0")
+}
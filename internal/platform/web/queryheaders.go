@@ -0,0 +1,64 @@
+package web
+
+import (
+	"context"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+type queryHeadersKey struct{}
+
+// withQueryHeaders returns a context carrying the response headers
+// declared in configuration for the saved query, resolved for the input.
+func withQueryHeaders(ctx context.Context, declared map[string]string, input restql.QueryInput) context.Context {
+	if len(declared) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, queryHeadersKey{}, ResolveQueryHeaders(declared, input))
+}
+
+// setQueryHeaders adds the saved query response headers, which take
+// precedence over the ones derived from the statements results.
+func setQueryHeaders(ctx context.Context, headers map[string]string) {
+	queryHeaders, _ := ctx.Value(queryHeadersKey{}).(map[string]string)
+	for key, value := range queryHeaders {
+		headers[key] = value
+	}
+}
+
+// ResolveQueryHeaders computes the header values starting with `$`
+// from the query input, like query variables, looking for them in the
+// request parameters and then in the request headers. Headers whose
+// value cannot be resolved are not emitted.
+func ResolveQueryHeaders(declared map[string]string, input restql.QueryInput) map[string]string {
+	result := make(map[string]string, len(declared))
+	for key, value := range declared {
+		if !strings.HasPrefix(value, "$") {
+			result[key] = value
+			continue
+		}
+
+		resolved, found := resolveInputValue(strings.TrimPrefix(value, "$"), input)
+		if found {
+			result[key] = resolved
+		}
+	}
+
+	return result
+}
+
+func resolveInputValue(name string, input restql.QueryInput) (string, bool) {
+	if value, found := input.Params[name].(string); found {
+		return value, true
+	}
+
+	for key, value := range input.Headers {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,32 @@
+package web_test
+
+import (
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestResolveQueryHeaders(t *testing.T) {
+	declared := map[string]string{
+		"X-Screen":      "pdp",
+		"Cache-Control": "max-age=60",
+		"X-Channel":     "$channel",
+		"X-Tid":         "$x-tid",
+		"X-Missing":     "$missing",
+	}
+	input := restql.QueryInput{
+		Params:  map[string]interface{}{"channel": "mobile"},
+		Headers: map[string]string{"X-TID": "abc"},
+	}
+
+	got := web.ResolveQueryHeaders(declared, input)
+
+	test.Equal(t, got, map[string]string{
+		"X-Screen":      "pdp",
+		"Cache-Control": "max-age=60",
+		"X-Channel":     "mobile",
+		"X-Tid":         "abc",
+	})
+}
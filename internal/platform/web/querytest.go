@@ -0,0 +1,410 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/internal/parser"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/httpclient"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/persistence"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+// fixtureHostSuffix identifies the hosts of the mappings rewritten
+// to be answered by the test case fixtures.
+const fixtureHostSuffix = ".fixture"
+
+var errMissingFixture = errors.New("no fixture defined for resource")
+
+// QueryTestResult represents the outcome of a saved query test case.
+type QueryTestResult struct {
+	Namespace   string       `json:"namespace"`
+	Query       string       `json:"query"`
+	Revision    int          `json:"revision"`
+	Name        string       `json:"name"`
+	Passed      bool         `json:"passed"`
+	Differences []Difference `json:"differences,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// QueryTester executes the test cases attached to saved queries,
+// answering every upstream request with the case fixtures, or
+// cassette, instead of calling the mapped resources.
+type QueryTester struct {
+	log    restql.Logger
+	cfg    *conf.Config
+	mr     eval.MappingsReader
+	qr     eval.QueryReader
+	parser parser.Parser
+
+	// recorder is the client calling the mapped resources
+	// when the cases cassettes are being recorded.
+	recorder domain.HTTPClient
+}
+
+// NewQueryTester constructs a QueryTester using the given mappings
+// and queries sources.
+func NewQueryTester(log restql.Logger, cfg *conf.Config, mr eval.MappingsReader, qr eval.QueryReader, p parser.Parser) QueryTester {
+	return QueryTester{log: log, cfg: cfg, mr: mr, qr: qr, parser: p}
+}
+
+// RunQueryTests executes the test cases defined in configuration for
+// the given namespace and query, where an empty value selects all of them.
+// When record is true, the cases with a cassette are executed against the
+// mapped resources, recording their interactions instead of replaying them.
+func RunQueryTests(log restql.Logger, cfg *conf.Config, namespace, queryID string, record bool) ([]QueryTestResult, error) {
+	qt, _, err := newLocalQueryTester(log, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if record {
+		qt.recorder = httpclient.WithSigning(httpclient.New(log, plugins.NoOpLifecycle, cfg))
+	}
+
+	ctx := restql.WithLogger(context.Background(), log)
+	return qt.Run(ctx, namespace, queryID), nil
+}
+
+// newLocalQueryTester constructs a QueryTester reading mappings and
+// queries from configuration and database, without running a server.
+func newLocalQueryTester(log restql.Logger, cfg *conf.Config) (QueryTester, persistence.QueryReader, error) {
+	p, err := parser.New()
+	if err != nil {
+		return QueryTester{}, persistence.QueryReader{}, err
+	}
+
+	db, err := persistence.NewDatabase(log, cfg.Plugins.DisableDatabase)
+	if err != nil {
+		return QueryTester{}, persistence.QueryReader{}, err
+	}
+
+	mr := persistence.NewMappingReader(log, cfg.Env, cfg.Mappings, cfg.TenantMappings, db)
+	qr := persistence.NewQueryReader(log, cfg.Queries, db)
+
+	return NewQueryTester(log, cfg, mr, qr, p), qr, nil
+}
+
+// Run executes the test cases of the given namespace and query,
+// where an empty value selects all of them. Results are sorted
+// by namespace and query, keeping the order of each query cases.
+func (qt QueryTester) Run(ctx context.Context, namespace, queryID string) []QueryTestResult {
+	namespaces := make([]string, 0, len(qt.cfg.QueryTests))
+	for ns := range qt.cfg.QueryTests {
+		if namespace == "" || ns == namespace {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+
+	results := []QueryTestResult{}
+	for _, ns := range namespaces {
+		queries := qt.cfg.QueryTests[ns]
+
+		ids := make([]string, 0, len(queries))
+		for id := range queries {
+			if queryID == "" || id == queryID {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			for _, tc := range queries[id] {
+				results = append(results, qt.runCase(ctx, ns, id, tc))
+			}
+		}
+	}
+
+	return results
+}
+
+func (qt QueryTester) runCase(ctx context.Context, namespace, queryID string, tc conf.QueryTestConf) QueryTestResult {
+	result := QueryTestResult{Namespace: namespace, Query: queryID, Revision: tc.Revision, Name: tc.Name}
+
+	if result.Revision <= 0 {
+		revision, err := qt.latestRevision(ctx, namespace, queryID)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.Revision = revision
+	}
+
+	body, err := qt.Execute(ctx, namespace, queryID, result.Revision, tc)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	expected, err := normalizeBody(toJSONValue(tc.Expected))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Differences = DiffResults(expected, projectResults(body, tc.Expected))
+	result.Passed = len(result.Differences) == 0
+
+	return result
+}
+
+// Case returns the test case with the given name
+// defined for the namespace and query.
+func (qt QueryTester) Case(namespace, queryID, name string) (conf.QueryTestConf, bool) {
+	for _, tc := range qt.cfg.QueryTests[namespace][queryID] {
+		if tc.Name == name {
+			return tc, true
+		}
+	}
+
+	return conf.QueryTestConf{}, false
+}
+
+// Execute runs the query revision with the test case input and fixtures,
+// returning the response body in its generic JSON form.
+func (qt QueryTester) Execute(ctx context.Context, namespace, queryID string, revision int, tc conf.QueryTestConf) (interface{}, error) {
+	tenant := tc.Tenant
+	if tenant == "" {
+		tenant = qt.cfg.Tenant
+	}
+
+	e, verify, err := qt.caseEvaluator(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	options := restql.QueryOptions{Namespace: namespace, Id: queryID, Revision: revision, Tenant: tenant}
+	input := restql.QueryInput{Params: toJSONMap(tc.Params), Headers: tc.Headers}
+
+	resources, err := e.SavedQuery(ctx, options, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verify(); err != nil {
+		return nil, err
+	}
+
+	response, err := MakeQueryResponse(resources, DebugOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return normalizeBody(response.Body)
+}
+
+// caseEvaluator returns an evaluator whose upstream requests are
+// answered by the test case client, and a function reporting the
+// requests it was not able to answer.
+func (qt QueryTester) caseEvaluator(tc conf.QueryTestConf) (eval.Evaluator, func() error, error) {
+	client, mr, verify, err := qt.caseClient(tc)
+	if err != nil {
+		return eval.Evaluator{}, nil, err
+	}
+
+	cascade, err := makeDefaultsCascade(qt.cfg)
+	if err != nil {
+		return eval.Evaluator{}, nil, err
+	}
+
+	executor := runner.NewExecutor(qt.log, client, nil, nil, nil, qt.cfg.HTTP.QueryResourceTimeout, qt.cfg.HTTP.ForwardPrefix, nil)
+	r := runner.NewRunner(qt.log, executor, qt.cfg.HTTP.GlobalQueryTimeout, cascade, nil, qt.cfg.HTTP.MaxChainDepth)
+	e := eval.NewEvaluator(qt.log, mr, qt.qr, r, qt.parser, plugins.NoOpLifecycle, qt.cfg.HTTP.FailOnHiddenErrors, nil)
+
+	return e, verify, nil
+}
+
+// caseClient returns the client answering the upstream requests of the
+// test case, the mappings it expects and a function reporting the
+// requests it was not able to answer.
+func (qt QueryTester) caseClient(tc conf.QueryTestConf) (domain.HTTPClient, eval.MappingsReader, func() error, error) {
+	switch {
+	case tc.Cassette != "" && qt.recorder != nil:
+		recorder := httpclient.NewRecorder(qt.log, qt.recorder, tc.Cassette)
+		return recorder, qt.mr, func() error { return nil }, nil
+	case tc.Cassette != "":
+		c, err := httpclient.LoadCassette(tc.Cassette)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		replayer := httpclient.NewReplayer(qt.log, c)
+		verify := func() error {
+			if misses := replayer.Misses(); len(misses) > 0 {
+				return fmt.Errorf("%w : %s", httpclient.ErrCassetteMiss, strings.Join(misses, ", "))
+			}
+			return nil
+		}
+
+		return replayer, qt.mr, verify, nil
+	default:
+		client := &fixtureClient{log: qt.log, fixtures: tc.Fixtures}
+		verify := func() error {
+			if missing := client.missing(); len(missing) > 0 {
+				return fmt.Errorf("%w : %s", errMissingFixture, strings.Join(missing, ", "))
+			}
+			return nil
+		}
+
+		return client, fixtureMappingsReader{mr: qt.mr}, verify, nil
+	}
+}
+
+func (qt QueryTester) latestRevision(ctx context.Context, namespace, queryID string) (int, error) {
+	revisions, err := qt.qr.ListQueryRevisions(ctx, namespace, queryID)
+	if err != nil {
+		return 0, err
+	}
+
+	latest := 0
+	for _, sq := range revisions {
+		if sq.Revision > latest {
+			latest = sq.Revision
+		}
+	}
+
+	if latest == 0 {
+		return 0, errors.Errorf("no revision found for query %s/%s", namespace, queryID)
+	}
+
+	return latest, nil
+}
+
+// projectResults selects from the response body the result
+// of the statements that have an expected value.
+func projectResults(body interface{}, expected map[string]interface{}) interface{} {
+	statements, _ := body.(map[string]interface{})
+
+	projected := make(map[string]interface{}, len(expected))
+	for resourceID := range expected {
+		stmt, ok := statements[resourceID].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		projected[resourceID] = stmt["result"]
+	}
+
+	return projected
+}
+
+// toJSONValue replaces the maps decoded from YAML, which are
+// keyed by interface{}, by maps keyed by string.
+func toJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprintf("%v", key)] = toJSONValue(item)
+		}
+		return m
+	case map[string]interface{}:
+		return toJSONMap(v)
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = toJSONValue(item)
+		}
+		return list
+	default:
+		return v
+	}
+}
+
+func toJSONMap(m map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		result[key] = toJSONValue(value)
+	}
+	return result
+}
+
+// fixtureMappingsReader rewrites the tenant mappings to
+// fixture hosts, keeping their path and query definition.
+type fixtureMappingsReader struct {
+	mr eval.MappingsReader
+}
+
+func (f fixtureMappingsReader) FromTenant(ctx context.Context, tenant string) (map[string]restql.Mapping, error) {
+	mappings, err := f.mr.FromTenant(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]restql.Mapping, len(mappings))
+	for resource, m := range mappings {
+		tail := strings.TrimPrefix(m.URL(), m.Schema()+"://"+m.Host())
+
+		fm, err := restql.NewMapping(resource, "http://"+resource+fixtureHostSuffix+tail)
+		if err != nil {
+			return nil, err
+		}
+		fm.Source = m.Source
+
+		result[resource] = fm
+	}
+
+	return result, nil
+}
+
+// fixtureClient answers the requests to fixture hosts with the
+// response defined for the resource, recording the resources
+// requested without one.
+type fixtureClient struct {
+	log      restql.Logger
+	fixtures map[string]conf.QueryFixtureConf
+
+	mu     sync.Mutex
+	misses map[string]struct{}
+}
+
+func (c *fixtureClient) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	resource := strings.TrimSuffix(request.Host, fixtureHostSuffix)
+
+	fixture, found := c.fixtures[resource]
+	if !found {
+		c.mu.Lock()
+		if c.misses == nil {
+			c.misses = make(map[string]struct{})
+		}
+		c.misses[resource] = struct{}{}
+		c.mu.Unlock()
+
+		return restql.HTTPResponse{}, fmt.Errorf("%w : %s", errMissingFixture, resource)
+	}
+
+	status := fixture.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	return restql.HTTPResponse{
+		URL:        fmt.Sprintf("%s://%s%s", request.Schema, request.Host, request.Path),
+		StatusCode: status,
+		Headers:    fixture.Headers,
+		Body:       restql.NewResponseBodyFromValue(c.log, toJSONValue(fixture.Body)),
+	}, nil
+}
+
+func (c *fixtureClient) missing() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make([]string, 0, len(c.misses))
+	for resource := range c.misses {
+		result = append(result, resource)
+	}
+	sort.Strings(result)
+
+	return result
+}
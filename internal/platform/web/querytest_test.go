@@ -0,0 +1,167 @@
+package web_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/internal/parser"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/httpclient"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/persistence"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"gopkg.in/yaml.v2"
+)
+
+const queryTestsConfig = `
+mappings:
+  hero: http://hero.io/api/:id
+  sidekick: http://sidekick.io/api
+queries:
+  heroes:
+    get-hero:
+      - from hero with id = $id
+      - |
+        from hero with id = $id
+        from sidekick with hero = hero.id
+queryTests:
+  heroes:
+    get-hero:
+      - name: returns the hero
+        revision: 1
+        tenant: DC
+        params:
+          id: 1
+        fixtures:
+          hero:
+            body: {id: 1, name: batman, weapons: [belt]}
+        expected:
+          hero: {id: 1, name: batman, weapons: [belt]}
+      - name: detects changed result
+        revision: 1
+        tenant: DC
+        params:
+          id: 1
+        fixtures:
+          hero:
+            body: {id: 1, name: bruce}
+        expected:
+          hero: {id: 1, name: batman}
+      - name: uses the latest revision
+        params:
+          id: 1
+        fixtures:
+          hero:
+            body: {id: 1, name: batman}
+          sidekick:
+            status: 200
+            body: [{name: robin}]
+        expected:
+          sidekick: [{name: robin}]
+      - name: requires fixtures for every resource
+        params:
+          id: 1
+        fixtures:
+          hero:
+            body: {id: 1, name: batman}
+        expected:
+          hero: {id: 1, name: batman}
+`
+
+func TestQueryTester(t *testing.T) {
+	var cfg conf.Config
+	err := yaml.Unmarshal([]byte(queryTestsConfig), &cfg)
+	test.VerifyError(t, err)
+	cfg.Tenant = "DC"
+
+	p, err := parser.New()
+	test.VerifyError(t, err)
+
+	db, err := persistence.NewDatabase(test.NoOpLogger, true)
+	test.VerifyError(t, err)
+
+	mr := persistence.NewMappingReader(test.NoOpLogger, conf.EnvSource{}, cfg.Mappings, cfg.TenantMappings, db)
+	qr := persistence.NewQueryReader(test.NoOpLogger, cfg.Queries, db)
+	qt := web.NewQueryTester(test.NoOpLogger, &cfg, mr, qr, p)
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	got := qt.Run(ctx, "heroes", "get-hero")
+
+	expected := []web.QueryTestResult{
+		{Namespace: "heroes", Query: "get-hero", Revision: 1, Name: "returns the hero", Passed: true},
+		{
+			Namespace: "heroes", Query: "get-hero", Revision: 1, Name: "detects changed result",
+			Differences: []web.Difference{{Path: "hero.name", Kind: web.DiffChanged, Base: "batman", Target: "bruce"}},
+		},
+		{Namespace: "heroes", Query: "get-hero", Revision: 2, Name: "uses the latest revision", Passed: true},
+		{
+			Namespace: "heroes", Query: "get-hero", Revision: 2, Name: "requires fixtures for every resource",
+			Error: "no fixture defined for resource : sidekick",
+		},
+	}
+
+	test.Equal(t, got, expected)
+	test.Equal(t, qt.Run(ctx, "villains", ""), []web.QueryTestResult{})
+}
+
+func TestQueryTesterDiffRevisions(t *testing.T) {
+	var cfg conf.Config
+	err := yaml.Unmarshal([]byte(queryTestsConfig), &cfg)
+	test.VerifyError(t, err)
+	cfg.Tenant = "DC"
+
+	p, err := parser.New()
+	test.VerifyError(t, err)
+
+	db, err := persistence.NewDatabase(test.NoOpLogger, true)
+	test.VerifyError(t, err)
+
+	mr := persistence.NewMappingReader(test.NoOpLogger, conf.EnvSource{}, cfg.Mappings, cfg.TenantMappings, db)
+	qr := persistence.NewQueryReader(test.NoOpLogger, cfg.Queries, db)
+	qt := web.NewQueryTester(test.NoOpLogger, &cfg, mr, qr, p)
+
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	c := httpclient.Cassette{Interactions: []httpclient.Interaction{
+		{
+			Request:  httpclient.CassetteRequest{Method: http.MethodGet, URL: "http://hero.io/api/1"},
+			Response: httpclient.CassetteResponse{Status: http.StatusOK, Body: json.RawMessage(`{"id": 1, "name": "batman"}`)},
+		},
+		{
+			Request:  httpclient.CassetteRequest{Method: http.MethodGet, URL: "http://sidekick.io/api?hero=1"},
+			Response: httpclient.CassetteResponse{Status: http.StatusOK, Body: json.RawMessage(`{"name": "robin"}`)},
+		},
+	}}
+	test.VerifyError(t, c.Save(cassette))
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	rd := web.RevisionDiff{Base: 1, Params: map[string]interface{}{"id": 1}, Cassette: cassette}
+
+	got, err := qt.DiffRevisions(ctx, eval.Evaluator{}, "heroes", "get-hero", rd)
+	test.VerifyError(t, err)
+
+	test.Equal(t, got.Base.Revision, 1)
+	test.Equal(t, got.Target.Revision, 2)
+	test.Equal(t, got.Target.Tenant, "DC")
+	test.Equal(t, got.Equal, false)
+	test.Equal(t, len(got.Differences), 1)
+	test.Equal(t, got.Differences[0].Path, "sidekick")
+	test.Equal(t, got.Differences[0].Kind, web.DiffAdded)
+
+	c.Interactions = c.Interactions[:1]
+	test.VerifyError(t, c.Save(cassette))
+
+	got, err = qt.DiffRevisions(ctx, eval.Evaluator{}, "heroes", "get-hero", rd)
+	test.VerifyError(t, err)
+
+	test.Equal(t, got.Base.Error, "")
+	test.Equal(t, got.Target.Error, "no interaction recorded for request : GET http://sidekick.io/api?hero=1")
+	test.Equal(t, got.Equal, false)
+
+	_, err = qt.DiffRevisions(ctx, eval.Evaluator{}, "heroes", "get-hero", web.RevisionDiff{Base: 2, Cassette: cassette})
+	test.Equal(t, err != nil, true)
+}
@@ -0,0 +1,78 @@
+package web
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const redactedHeaderValue = "[REDACTED]"
+
+// DebugOptions defines if the debugging information is
+// included in the query response and how it is sanitized.
+type DebugOptions struct {
+	Enabled   bool
+	Redactor  HeaderRedactor
+	RequestID string
+	Seed      *int64
+}
+
+// HeaderRedactor masks the values of sensitive headers, matched
+// by name, case insensitively, or by regular expression.
+// The zero value does not redact any header.
+type HeaderRedactor struct {
+	names    map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+// NewHeaderRedactor constructs a HeaderRedactor for the
+// given header names and name patterns.
+func NewHeaderRedactor(names []string, patterns []string) (HeaderRedactor, error) {
+	hr := HeaderRedactor{names: make(map[string]struct{}, len(names))}
+	for _, name := range names {
+		hr.names[strings.ToLower(name)] = struct{}{}
+	}
+
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return HeaderRedactor{}, errors.Wrapf(err, "invalid header redaction pattern %s", p)
+		}
+		hr.patterns = append(hr.patterns, re)
+	}
+
+	return hr, nil
+}
+
+// Redact returns a copy of the headers with the values
+// of the sensitive ones replaced.
+func (hr HeaderRedactor) Redact(headers map[string]string) map[string]string {
+	if headers == nil || (len(hr.names) == 0 && len(hr.patterns) == 0) {
+		return headers
+	}
+
+	result := make(map[string]string, len(headers))
+	for key, value := range headers {
+		if hr.sensitive(key) {
+			value = redactedHeaderValue
+		}
+		result[key] = value
+	}
+
+	return result
+}
+
+func (hr HeaderRedactor) sensitive(name string) bool {
+	if _, found := hr.names[strings.ToLower(name)]; found {
+		return true
+	}
+
+	for _, re := range hr.patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,93 @@
+package web_test
+
+import (
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestHeaderRedactor(t *testing.T) {
+	redactor, err := web.NewHeaderRedactor([]string{"Authorization", "Set-Cookie"}, []string{"(?i)token"})
+	test.VerifyError(t, err)
+
+	headers := map[string]string{
+		"authorization": "Bearer abc",
+		"Set-Cookie":    "session=123",
+		"X-Auth-Token":  "abcabc",
+		"Content-Type":  "application/json",
+	}
+
+	got := redactor.Redact(headers)
+
+	test.Equal(t, got, map[string]string{
+		"authorization": "[REDACTED]",
+		"Set-Cookie":    "[REDACTED]",
+		"X-Auth-Token":  "[REDACTED]",
+		"Content-Type":  "application/json",
+	})
+	test.Equal(t, headers["authorization"], "Bearer abc")
+}
+
+func TestHeaderRedactorInvalidPattern(t *testing.T) {
+	_, err := web.NewHeaderRedactor(nil, []string{"(token"})
+
+	test.NotEqual(t, err, nil)
+}
+
+func TestMakeQueryResponseRedactsDebugHeaders(t *testing.T) {
+	redactor, err := web.NewHeaderRedactor([]string{"Authorization"}, nil)
+	test.VerifyError(t, err)
+
+	queryResult := domain.Resources{
+		"hero": restql.DoneResource{
+			Status:          200,
+			Success:         true,
+			RequestHeaders:  map[string]string{"Authorization": "Bearer abc", "X-Tid": "123"},
+			ResponseHeaders: map[string]string{"Authorization": "Bearer efg"},
+			ResponseBody:    restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"id": "1"}`)),
+		},
+	}
+
+	got, err := web.MakeQueryResponse(queryResult, web.DebugOptions{Enabled: true, Redactor: redactor})
+	test.VerifyError(t, err)
+
+	debug := got.Body["hero"].Details.(web.StatementDetails).Debug
+	test.Equal(t, debug.RequestHeaders, map[string]string{"Authorization": "[REDACTED]", "X-Tid": "123"})
+	test.Equal(t, debug.ResponseHeaders, map[string]string{"Authorization": "[REDACTED]"})
+}
+
+func TestMakeQueryResponseDebugRequestID(t *testing.T) {
+	queryResult := domain.Resources{
+		"hero": restql.DoneResource{
+			Status:       200,
+			Success:      true,
+			ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"id": "1"}`)),
+		},
+	}
+
+	got, err := web.MakeQueryResponse(queryResult, web.DebugOptions{Enabled: true, RequestID: "abc123"})
+	test.VerifyError(t, err)
+
+	debug := got.Body["hero"].Details.(web.StatementDetails).Debug
+	test.Equal(t, debug.RequestID, "abc123")
+}
+
+func TestMakeQueryResponseDebugSeed(t *testing.T) {
+	queryResult := domain.Resources{
+		"hero": restql.DoneResource{
+			Status:       200,
+			Success:      true,
+			ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"id": "1"}`)),
+		},
+	}
+
+	seed := int64(42)
+	got, err := web.MakeQueryResponse(queryResult, web.DebugOptions{Enabled: true, Seed: &seed})
+	test.VerifyError(t, err)
+
+	debug := got.Body["hero"].Details.(web.StatementDetails).Debug
+	test.Equal(t, *debug.Seed, int64(42))
+}
@@ -7,10 +7,14 @@ import (
 	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
 	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
 	"github.com/b2wdigital/restQL-golang/v4/internal/parser"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/openapi"
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/persistence"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web/middleware"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/valyala/fasthttp"
 )
@@ -26,13 +30,34 @@ var errToStatusCode = map[error]int{
 	eval.ErrParser:                              fasthttp.StatusInternalServerError,
 	eval.ErrTimeout:                             fasthttp.StatusRequestTimeout,
 	eval.ErrMapping:                             fasthttp.StatusInternalServerError,
+	eval.ErrExecutionNotFound:                   fasthttp.StatusNotFound,
 	parser.ErrInvalidQuery:                      fasthttp.StatusUnprocessableEntity,
 	persistence.ErrSetResourceMappingNotAllowed: fasthttp.StatusUnauthorized,
 	persistence.ErrCreateRevisionNotAllowed:     fasthttp.StatusUnauthorized,
 	errPathParamNotFound:                        fasthttp.StatusUnprocessableEntity,
 	errInvalidTenant:                            fasthttp.StatusBadRequest,
+	errInvalidSeed:                              fasthttp.StatusBadRequest,
+	errInvalidStatusPolicy:                      fasthttp.StatusBadRequest,
 	errInvalidRevisionType:                      fasthttp.StatusBadRequest,
+	errEmptyDiff:                                fasthttp.StatusBadRequest,
+	errInvalidRevisionDiff:                      fasthttp.StatusBadRequest,
+	errInvalidBatch:                             fasthttp.StatusBadRequest,
+	errBatchTooLarge:                            fasthttp.StatusRequestEntityTooLarge,
+	errEmptyInvalidation:                        fasthttp.StatusBadRequest,
+	errInvalidClientLanguage:                    fasthttp.StatusBadRequest,
+	errInvalidSchemaFormat:                      fasthttp.StatusBadRequest,
+	errInvalidGraphFormat:                       fasthttp.StatusBadRequest,
+	errUnknownFormat:                            fasthttp.StatusNotAcceptable,
+	errTestCaseNotFound:                         fasthttp.StatusNotFound,
+	errMissingFixture:                           fasthttp.StatusUnprocessableEntity,
 	errFailedToReadRequestBody:                  http.StatusBadRequest,
+	runner.ErrInvalidSampleRate:                 http.StatusBadRequest,
+	runner.ErrQueryMemoryExceeded:               fasthttp.StatusInsufficientStorage,
+	middleware.ErrFaultInjectionNotAllowed:      http.StatusForbidden,
+	middleware.ErrNamespaceNotAllowed:           http.StatusForbidden,
+	errInvalidMappingImport:                     http.StatusBadRequest,
+	openapi.ErrInvalidDocument:                  fasthttp.StatusUnprocessableEntity,
+	openapi.ErrNoBaseURL:                        fasthttp.StatusUnprocessableEntity,
 }
 
 // ErrorResponse is the form used for API responses from failures in the API.
@@ -80,18 +105,46 @@ func findStatusCode(toStatusCode map[error]int, err error) int {
 
 // StatementDebugging represents the client format of debugging information
 type StatementDebugging struct {
-	Method          string                 `json:"method,omitempty"`
-	URL             string                 `json:"url,omitempty"`
-	RequestHeaders  map[string]string      `json:"request-headers,omitempty"`
-	ResponseHeaders map[string]string      `json:"response-headers,omitempty"`
-	Params          map[string]interface{} `json:"params,omitempty"`
-	RequestBody     interface{}            `json:"request-body,omitempty"`
-	ResponseTime    int64                  `json:"response-time,omitempty"`
+	Method           string                 `json:"method,omitempty"`
+	URL              string                 `json:"url,omitempty"`
+	RequestHeaders   map[string]string      `json:"request-headers,omitempty"`
+	ResponseHeaders  map[string]string      `json:"response-headers,omitempty"`
+	Params           map[string]interface{} `json:"params,omitempty"`
+	RequestBody      interface{}            `json:"request-body,omitempty"`
+	ResponseTime     int64                  `json:"response-time,omitempty"`
+	Target           string                 `json:"target,omitempty"`
+	Timeline         *StatementTimeline     `json:"timeline,omitempty"`
+	Mocked           bool                   `json:"mocked,omitempty"`
+	RequestID        string                 `json:"request-id,omitempty"`
+	Seed             *int64                 `json:"seed,omitempty"`
+	SchemaViolations []string               `json:"schema-violations,omitempty"`
+}
+
+// StatementTimeline represents the client format of the statement
+// execution timeline, with durations in fractional milliseconds
+type StatementTimeline struct {
+	StartOffset float64       `json:"start-offset"`
+	QueueWait   float64       `json:"queue-wait"`
+	Attempts    int           `json:"attempts,omitempty"`
+	Cache       string        `json:"cache,omitempty"`
+	DependsOn   []string      `json:"depends-on,omitempty"`
+	HTTP        *HTTPTimeline `json:"http,omitempty"`
+}
+
+// HTTPTimeline represents the client format of the phases of
+// the statement HTTP call, in fractional milliseconds
+type HTTPTimeline struct {
+	DNS       float64 `json:"dns"`
+	Connect   float64 `json:"connect"`
+	TLS       float64 `json:"tls"`
+	FirstByte float64 `json:"first-byte"`
 }
 
 // StatementMetadata represents the client format of metadata
 type StatementMetadata struct {
 	IgnoreErrors string `json:"ignore-errors,omitempty"`
+	Defaulted    bool   `json:"defaulted,omitempty"`
+	Throttled    bool   `json:"throttled,omitempty"`
 }
 
 // StatementDetails represents the client format of the statement details
@@ -99,6 +152,7 @@ type StatementDetails struct {
 	Status   int                 `json:"status"`
 	Success  bool                `json:"success"`
 	Metadata StatementMetadata   `json:"metadata"`
+	Headers  map[string]string   `json:"headers,omitempty"`
 	Debug    *StatementDebugging `json:"debug,omitempty"`
 }
 
@@ -113,10 +167,11 @@ type QueryResponse struct {
 	StatusCode int
 	Body       map[string]StatementResult
 	Headers    map[string]string
+	Warnings   []domain.Warning
 }
 
 // MakeQueryResponse create a query execution response for the client.
-func MakeQueryResponse(queryResult domain.Resources, debug bool) (QueryResponse, error) {
+func MakeQueryResponse(queryResult domain.Resources, debug DebugOptions) (QueryResponse, error) {
 	m := make(map[string]StatementResult)
 	for key, resource := range queryResult {
 		r, err := parseResource(resource, debug)
@@ -132,7 +187,7 @@ func MakeQueryResponse(queryResult domain.Resources, debug bool) (QueryResponse,
 	return QueryResponse{Body: m, StatusCode: statusCode, Headers: headers}, nil
 }
 
-func parseResource(resource interface{}, debug bool) (StatementResult, error) {
+func parseResource(resource interface{}, debug DebugOptions) (StatementResult, error) {
 	switch resource := resource.(type) {
 	case restql.DoneResource:
 		body, err := resource.ResponseBody.Marshal()
@@ -175,37 +230,77 @@ func parseResource(resource interface{}, debug bool) (StatementResult, error) {
 	}
 }
 
-func parseDetails(resource restql.DoneResource, debug bool) StatementDetails {
+func parseDetails(resource restql.DoneResource, debug DebugOptions) StatementDetails {
 	var metadata StatementMetadata
 	if resource.IgnoreErrors {
 		metadata.IgnoreErrors = "ignore"
 	}
+	metadata.Defaulted = resource.Defaulted
+	metadata.Throttled = resource.Throttled
 
 	sd := StatementDetails{
 		Status:   resource.Status,
 		Success:  resource.Success,
 		Metadata: metadata,
+		Headers:  resource.ReturnedHeaders,
 	}
 
-	if debug {
-		sd.Debug = parseDebug(resource)
+	if debug.Enabled {
+		sd.Debug = parseDebug(resource, debug)
 	}
 
 	return sd
 }
 
-func parseDebug(resource restql.DoneResource) *StatementDebugging {
+func parseDebug(resource restql.DoneResource, debug DebugOptions) *StatementDebugging {
 	return &StatementDebugging{
-		Method:          resource.Method,
-		URL:             resource.URL,
-		RequestHeaders:  resource.RequestHeaders,
-		ResponseHeaders: resource.ResponseHeaders,
-		Params:          resource.RequestParams,
-		RequestBody:     resource.RequestBody,
-		ResponseTime:    resource.ResponseTime,
+		Method:           resource.Method,
+		URL:              resource.URL,
+		RequestHeaders:   debug.Redactor.Redact(resource.RequestHeaders),
+		ResponseHeaders:  debug.Redactor.Redact(resource.ResponseHeaders),
+		Params:           resource.RequestParams,
+		RequestBody:      resource.RequestBody,
+		ResponseTime:     resource.ResponseTime,
+		Target:           resource.Target,
+		Timeline:         parseTimeline(resource.Timeline),
+		Mocked:           resource.Mocked,
+		RequestID:        debug.RequestID,
+		Seed:             debug.Seed,
+		SchemaViolations: resource.SchemaViolations,
 	}
 }
 
+func parseTimeline(timeline *restql.StatementTimeline) *StatementTimeline {
+	if timeline == nil {
+		return nil
+	}
+
+	st := &StatementTimeline{
+		StartOffset: milliseconds(timeline.StartOffset),
+		QueueWait:   milliseconds(timeline.QueueWait),
+		Attempts:    timeline.Attempts,
+		Cache:       timeline.Cache,
+		DependsOn:   timeline.DependsOn,
+	}
+
+	if t := timeline.HTTP; t != nil {
+		st.HTTP = &HTTPTimeline{
+			DNS:       milliseconds(t.DNS),
+			Connect:   milliseconds(t.Connect),
+			TLS:       milliseconds(t.TLS),
+			FirstByte: milliseconds(t.FirstByte),
+		}
+	}
+
+	return st
+}
+
+// milliseconds converts the duration to milliseconds,
+// keeping microseconds precision.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
 // CalculateStatusCode returns the greater status in all
 // statement results to be used as the response status code.
 // It applies the following normalization to statement result status codes:
@@ -267,8 +362,9 @@ func findMaxStatusCode(results []interface{}) int {
 func makeHeaders(queryResult domain.Resources) map[string]string {
 	resourceHeaders := makeResourceHeaders(queryResult)
 	ccHeaders := makeCacheControlHeaders(queryResult)
+	resourceCCHeaders := makeResourceCacheControlHeaders(queryResult)
 
-	return appendMap(resourceHeaders, ccHeaders)
+	return appendMap(appendMap(resourceHeaders, ccHeaders), resourceCCHeaders)
 }
 
 func makeResourceHeaders(queryResult domain.Resources) map[string]string {
@@ -315,6 +411,46 @@ func makeCacheControlHeaders(queryResult domain.Resources) map[string]string {
 	return headers
 }
 
+const resourceCacheControlHeaderPrefix = "x-restql-cache-"
+
+// makeResourceCacheControlHeaders builds one header for each statement
+// result with its own cache directives, allowing clients to know how
+// each resource contributed to the merged Cache-Control header.
+// Hidden statements are skipped, not to reveal their names.
+func makeResourceCacheControlHeaders(queryResult domain.Resources) map[string]string {
+	headers := make(map[string]string)
+	for resourceID, result := range queryResult {
+		if isHiddenResult(result) {
+			continue
+		}
+
+		cc := calculateResultCacheControl(result)
+		ccString := generateCacheControlString(cc)
+		if ccString == "" {
+			continue
+		}
+
+		headers[resourceCacheControlHeaderPrefix+string(resourceID)] = ccString
+	}
+
+	return headers
+}
+
+func isHiddenResult(result interface{}) bool {
+	switch result := result.(type) {
+	case restql.DoneResource:
+		return result.Hidden
+	case restql.DoneResources:
+		for _, r := range result {
+			if isHiddenResult(r) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func calculateCacheControl(queryResult domain.Resources) restql.ResourceCacheControl {
 	results := make([]interface{}, len(queryResult))
 	index := 0
@@ -338,7 +474,13 @@ func findMinCacheControl(results []interface{}) restql.ResourceCacheControl {
 	}
 
 	for _, cc := range resourceCacheControls {
+		minCacheControl.Private = minCacheControl.Private || cc.Private
+
 		switch {
+		case minCacheControl.NoStore:
+			continue
+		case cc.NoStore:
+			minCacheControl = restql.ResourceCacheControl{NoStore: true, Private: minCacheControl.Private}
 		case minCacheControl.NoCache:
 			continue
 		case cc.NoCache:
@@ -373,16 +515,31 @@ func calculateResultCacheControl(result interface{}) restql.ResourceCacheControl
 func generateCacheControlString(cacheControl restql.ResourceCacheControl) string {
 	var buf bytes.Buffer
 
-	if cacheControl.NoCache {
-		return "no-cache"
+	if cacheControl.Private {
+		buf.WriteString("private")
+	}
+
+	if cacheControl.NoStore || cacheControl.NoCache {
+		if buf.Len() > 0 {
+			buf.WriteString(", ")
+		}
+		if cacheControl.NoStore {
+			buf.WriteString("no-store")
+		} else {
+			buf.WriteString("no-cache")
+		}
+		return buf.String()
 	}
 
 	if cacheControl.MaxAge.Exist {
+		if buf.Len() > 0 {
+			buf.WriteString(", ")
+		}
 		buf.WriteString("max-age=")
 		buf.WriteString(strconv.Itoa(cacheControl.MaxAge.Time))
 	}
 
-	if cacheControl.SMaxAge.Exist {
+	if cacheControl.SMaxAge.Exist && !cacheControl.Private {
 		if buf.Len() > 0 {
 			buf.WriteString(", ")
 		}
@@ -5,6 +5,7 @@ import (
 	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
 	"testing"
+	"time"
 
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
 	"github.com/b2wdigital/restQL-golang/v4/test"
@@ -60,6 +61,29 @@ func TestMakeQueryResponse(t *testing.T) {
 				Headers: map[string]string{},
 			},
 		},
+		{
+			"should make response with returned headers",
+			domain.Resources{
+				"hero": restql.DoneResource{
+					Status:          200,
+					Success:         true,
+					ResponseHeaders: map[string]string{"X-Total-Count": "42", "X-New-Token": "efgefgefg"},
+					ReturnedHeaders: map[string]string{"X-Total-Count": "42"},
+					ResponseBody:    restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"id": "12345abcde"}`)),
+				},
+			},
+			false,
+			web.QueryResponse{
+				StatusCode: 200,
+				Body: map[string]web.StatementResult{
+					"hero": {
+						Details: web.StatementDetails{Status: 200, Success: true, Headers: map[string]string{"X-Total-Count": "42"}},
+						Result:  rawResult(`{"id": "12345abcde"}`),
+					},
+				},
+				Headers: map[string]string{"hero-X-Total-Count": "42", "hero-X-New-Token": "efgefgefg"},
+			},
+		},
 		{
 			"should make response with debugging",
 			domain.Resources{
@@ -94,6 +118,50 @@ func TestMakeQueryResponse(t *testing.T) {
 				},
 			},
 		},
+		{
+			"should make response with debugging timeline",
+			domain.Resources{
+				"sidekick": restql.DoneResource{
+					Status:  200,
+					Success: true,
+					URL:     "http://sidekick.io/api",
+					Timeline: &restql.StatementTimeline{
+						StartOffset: 12500 * time.Microsecond,
+						QueueWait:   250 * time.Microsecond,
+						Attempts:    2,
+						Cache:       restql.ResponseCacheMiss,
+						DependsOn:   []string{"hero"},
+						HTTP: &restql.HTTPTimings{
+							DNS:       time.Millisecond,
+							Connect:   1500 * time.Microsecond,
+							FirstByte: 20 * time.Millisecond,
+						},
+					},
+					ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"id": "12345abcde"}`)),
+				},
+			},
+			true,
+			web.QueryResponse{
+				StatusCode: 200,
+				Body: map[string]web.StatementResult{
+					"sidekick": {
+						Details: web.StatementDetails{Status: 200, Success: true, Debug: &web.StatementDebugging{
+							URL: "http://sidekick.io/api",
+							Timeline: &web.StatementTimeline{
+								StartOffset: 12.5,
+								QueueWait:   0.25,
+								Attempts:    2,
+								Cache:       "miss",
+								DependsOn:   []string{"hero"},
+								HTTP:        &web.HTTPTimeline{DNS: 1, Connect: 1.5, FirstByte: 20},
+							},
+						}},
+						Result: rawResult(`{"id": "12345abcde"}`),
+					},
+				},
+				Headers: map[string]string{},
+			},
+		},
 		{
 			"should make response for multiplexed result",
 			domain.Resources{
@@ -181,7 +249,10 @@ func TestMakeQueryResponse(t *testing.T) {
 						Result:  rawResult(`{"id": "12345abcde"}`),
 					},
 				},
-				Headers: map[string]string{"Cache-Control": "max-age=400, s-maxage=300"},
+				Headers: map[string]string{
+					"Cache-Control":       "max-age=400, s-maxage=300",
+					"x-restql-cache-hero": "max-age=400, s-maxage=300",
+				},
 			},
 		},
 		{
@@ -205,7 +276,7 @@ func TestMakeQueryResponse(t *testing.T) {
 						Result:  rawResult(`{"id": "12345abcde"}`),
 					},
 				},
-				Headers: map[string]string{"Cache-Control": "max-age=400"},
+				Headers: map[string]string{"Cache-Control": "max-age=400", "x-restql-cache-hero": "max-age=400"},
 			},
 		},
 		{
@@ -229,7 +300,7 @@ func TestMakeQueryResponse(t *testing.T) {
 						Result:  rawResult(`{"id": "12345abcde"}`),
 					},
 				},
-				Headers: map[string]string{"Cache-Control": "s-maxage=300"},
+				Headers: map[string]string{"Cache-Control": "s-maxage=300", "x-restql-cache-hero": "s-maxage=300"},
 			},
 		},
 		{
@@ -253,7 +324,166 @@ func TestMakeQueryResponse(t *testing.T) {
 						Result:  rawResult(`{"id": "12345abcde"}`),
 					},
 				},
-				Headers: map[string]string{"Cache-Control": "no-cache"},
+				Headers: map[string]string{"Cache-Control": "no-cache", "x-restql-cache-hero": "no-cache"},
+			},
+		},
+		{
+			"should make response with cache control header containing only no-store directive",
+			domain.Resources{
+				"hero": restql.DoneResource{
+					Status:       200,
+					Success:      true,
+					CacheControl: restql.ResourceCacheControl{NoStore: true},
+					ResponseBody: &restql.ResponseBody{},
+				},
+				"sidekick": restql.DoneResource{
+					Status:  200,
+					Success: true,
+					CacheControl: restql.ResourceCacheControl{
+						MaxAge: restql.ResourceCacheControlValue{Exist: true, Time: 400},
+					},
+					ResponseBody: &restql.ResponseBody{},
+				},
+			},
+			false,
+			web.QueryResponse{
+				StatusCode: 200,
+				Body: map[string]web.StatementResult{
+					"hero": {
+						Details: web.StatementDetails{Status: 200, Success: true},
+						Result:  nil,
+					},
+					"sidekick": {
+						Details: web.StatementDetails{Status: 200, Success: true},
+						Result:  nil,
+					},
+				},
+				Headers: map[string]string{
+					"Cache-Control":           "no-store",
+					"x-restql-cache-hero":     "no-store",
+					"x-restql-cache-sidekick": "max-age=400",
+				},
+			},
+		},
+		{
+			"should make response with private cache control header",
+			domain.Resources{
+				"hero": restql.DoneResource{
+					Status:  200,
+					Success: true,
+					CacheControl: restql.ResourceCacheControl{
+						Private: true,
+						MaxAge:  restql.ResourceCacheControlValue{Exist: true, Time: 600},
+					},
+					ResponseBody: &restql.ResponseBody{},
+				},
+				"sidekick": restql.DoneResource{
+					Status:  200,
+					Success: true,
+					CacheControl: restql.ResourceCacheControl{
+						MaxAge:  restql.ResourceCacheControlValue{Exist: true, Time: 400},
+						SMaxAge: restql.ResourceCacheControlValue{Exist: true, Time: 1800},
+					},
+					ResponseBody: &restql.ResponseBody{},
+				},
+			},
+			false,
+			web.QueryResponse{
+				StatusCode: 200,
+				Body: map[string]web.StatementResult{
+					"hero": {
+						Details: web.StatementDetails{Status: 200, Success: true},
+						Result:  nil,
+					},
+					"sidekick": {
+						Details: web.StatementDetails{Status: 200, Success: true},
+						Result:  nil,
+					},
+				},
+				Headers: map[string]string{
+					"Cache-Control":           "private, max-age=400",
+					"x-restql-cache-hero":     "private, max-age=600",
+					"x-restql-cache-sidekick": "max-age=400, s-maxage=1800",
+				},
+			},
+		},
+		{
+			"should make response with private no-cache cache control header",
+			domain.Resources{
+				"hero": restql.DoneResource{
+					Status:  200,
+					Success: true,
+					CacheControl: restql.ResourceCacheControl{
+						Private: true,
+						MaxAge:  restql.ResourceCacheControlValue{Exist: true, Time: 600},
+					},
+					ResponseBody: &restql.ResponseBody{},
+				},
+				"sidekick": restql.DoneResource{
+					Status:       200,
+					Success:      true,
+					CacheControl: restql.ResourceCacheControl{NoCache: true},
+					ResponseBody: &restql.ResponseBody{},
+				},
+			},
+			false,
+			web.QueryResponse{
+				StatusCode: 200,
+				Body: map[string]web.StatementResult{
+					"hero": {
+						Details: web.StatementDetails{Status: 200, Success: true},
+						Result:  nil,
+					},
+					"sidekick": {
+						Details: web.StatementDetails{Status: 200, Success: true},
+						Result:  nil,
+					},
+				},
+				Headers: map[string]string{
+					"Cache-Control":           "private, no-cache",
+					"x-restql-cache-hero":     "private, max-age=600",
+					"x-restql-cache-sidekick": "no-cache",
+				},
+			},
+		},
+		{
+			"should make response without cache control header of hidden resources",
+			domain.Resources{
+				"hero": restql.DoneResource{
+					Status:  200,
+					Success: true,
+					CacheControl: restql.ResourceCacheControl{
+						MaxAge: restql.ResourceCacheControlValue{Exist: true, Time: 600},
+					},
+					ResponseBody: &restql.ResponseBody{},
+				},
+				"villain": restql.DoneResource{
+					Status:  200,
+					Success: true,
+					Hidden:  true,
+					CacheControl: restql.ResourceCacheControl{
+						MaxAge: restql.ResourceCacheControlValue{Exist: true, Time: 100},
+					},
+					ResponseBody: &restql.ResponseBody{},
+				},
+			},
+			false,
+			web.QueryResponse{
+				StatusCode: 200,
+				Body: map[string]web.StatementResult{
+					"hero": {
+						Details: web.StatementDetails{Status: 200, Success: true},
+						Result:  nil,
+					},
+					"villain": {
+						Details: web.StatementDetails{Status: 200, Success: true},
+						Result:  nil,
+					},
+				},
+				Headers: map[string]string{
+					"Cache-Control":       "max-age=100",
+					"x-restql-cache-hero": "max-age=600",
+				},
 			},
 		},
 		{
@@ -291,7 +521,11 @@ func TestMakeQueryResponse(t *testing.T) {
 						Result:  nil,
 					},
 				},
-				Headers: map[string]string{"Cache-Control": "max-age=400, s-maxage=300"},
+				Headers: map[string]string{
+					"Cache-Control":           "max-age=400, s-maxage=300",
+					"x-restql-cache-hero":     "max-age=1000, s-maxage=300",
+					"x-restql-cache-sidekick": "max-age=400, s-maxage=1800",
+				},
 			},
 		},
 		{
@@ -340,7 +574,11 @@ func TestMakeQueryResponse(t *testing.T) {
 						Result:  nil,
 					},
 				},
-				Headers: map[string]string{"Cache-Control": "max-age=100, s-maxage=600"},
+				Headers: map[string]string{
+					"Cache-Control":           "max-age=100, s-maxage=600",
+					"x-restql-cache-hero":     "max-age=400, s-maxage=600",
+					"x-restql-cache-sidekick": "max-age=100, s-maxage=1800",
+				},
 			},
 		},
 		{
@@ -425,7 +663,7 @@ func TestMakeQueryResponse(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, _ := web.MakeQueryResponse(tt.queryResult, tt.debug)
+			got, _ := web.MakeQueryResponse(tt.queryResult, web.DebugOptions{Enabled: tt.debug})
 			test.Equal(t, got, tt.expected)
 		})
 	}
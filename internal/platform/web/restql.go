@@ -1,15 +1,24 @@
 package web
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
 	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
-	"github.com/b2wdigital/restQL-golang/v4/internal/parser"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/cache"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/codec"
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web/middleware"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
 	"github.com/pkg/errors"
 	"github.com/valyala/fasthttp"
@@ -18,8 +27,9 @@ import (
 var jsonContentType = "application/json"
 
 var (
-	errInvalidRevisionType     = errors.New("invalid revision : must be an integer")
+	errInvalidRevisionType     = errors.New("invalid revision : must be an integer or a revision alias")
 	errInvalidTenant           = errors.New("invalid tenant : no value provided")
+	errInvalidSeed             = errors.New("invalid seed : must be an integer")
 	errFailedToReadRequestBody = errors.New("failed to read and unmarshal request body")
 )
 
@@ -27,48 +37,145 @@ type restQl struct {
 	config    *conf.Config
 	log       restql.Logger
 	evaluator eval.Evaluator
-	parser    parser.Parser
+	encoder   codec.JSONEncoder
+	tester    QueryTester
+	redactor  HeaderRedactor
+	lifecycle plugins.Lifecycle
+
+	statusPolicy string
+	eventSource  domain.EventSource
 }
 
-func newRestQl(l restql.Logger, cfg *conf.Config, e eval.Evaluator, p parser.Parser) restQl {
-	return restQl{config: cfg, log: l, evaluator: e, parser: p}
+func newRestQl(l restql.Logger, cfg *conf.Config, e eval.Evaluator, encoder codec.JSONEncoder, qt QueryTester, hr HeaderRedactor, lc plugins.Lifecycle, statusPolicy string, es domain.EventSource) restQl {
+	return restQl{config: cfg, log: l, evaluator: e, encoder: encoder, tester: qt, redactor: hr, lifecycle: lc, statusPolicy: statusPolicy, eventSource: es}
 }
 
-func (r restQl) ValidateQuery(ctx *fasthttp.RequestCtx) error {
-	queryTxt := string(ctx.PostBody())
-	_, err := r.parser.Parse(queryTxt)
+func (r restQl) ValidateQuery(reqCtx *fasthttp.RequestCtx) error {
+	ctx := middleware.GetNativeContext(reqCtx)
+	log := requestLogger(ctx, r.log)
+	ctx = restql.WithLogger(ctx, log)
+
+	// The tenant is optional, as without it only
+	// the resources are not validated.
+	tenant, _ := makeTenant(reqCtx, r.config.Tenant)
+
+	queryTxt := string(reqCtx.PostBody())
+	report, err := r.evaluator.ValidateQuery(ctx, queryTxt, tenant)
 	if err != nil {
-		r.log.Error("an error occurred when parsing query", err)
-		e := fmt.Errorf("%w: %s", parser.ErrInvalidQuery, err)
+		log.Error("failed to validate query", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	status := http.StatusOK
+	if !report.Valid {
+		status = http.StatusUnprocessableEntity
+	}
+
+	return Respond(reqCtx, report, status, nil)
+}
+
+type explainResponse struct {
+	Statements []runner.StatementPlan `json:"statements"`
+}
+
+type dryRunResponse struct {
+	Statements []runner.StatementDryRun `json:"statements"`
+}
+
+func (r restQl) ExplainQuery(reqCtx *fasthttp.RequestCtx) error {
+	ctx := middleware.GetNativeContext(reqCtx)
+	log := requestLogger(ctx, r.log)
+	ctx = restql.WithLogger(ctx, log)
+
+	tenant, err := makeTenant(reqCtx, r.config.Tenant)
+	if err != nil {
+		log.Error("failed to build query options", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+	options := restql.QueryOptions{Tenant: tenant}
+
+	input, err := makeQueryInput(reqCtx, log)
+	if err != nil {
+		log.Error("failed to build query input", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	queryTxt := string(reqCtx.PostBody())
+
+	plans, err := r.evaluator.ExplainQuery(ctx, queryTxt, options, input)
+	if err != nil {
+		log.Error("failed to explain query", err)
+
+		explainErrToStatusCode := make(map[error]int)
+		for err, status := range errToStatusCode {
+			explainErrToStatusCode[err] = status
+		}
+		explainErrToStatusCode[eval.ErrParser] = http.StatusBadRequest
 
-		return RespondError(ctx, e, errToStatusCode)
+		return RespondError(reqCtx, err, explainErrToStatusCode)
 	}
 
-	return Respond(ctx, nil, http.StatusOK, nil)
+	return Respond(reqCtx, explainResponse{Statements: plans}, http.StatusOK, nil)
 }
 
 func (r restQl) RunAdHocQuery(reqCtx *fasthttp.RequestCtx) error {
 	ctx := middleware.GetNativeContext(reqCtx)
-	ctx = restql.WithLogger(reqCtx, r.log)
+	log := requestLogger(ctx, r.log)
+	ctx = restql.WithLogger(ctx, log)
+	ctx = cache.WithStalenessTracking(ctx)
+	ctx = runner.WithSLOTracking(ctx)
+	ctx = eval.WithWarnings(ctx)
+	ctx = eval.WithPassThrough(ctx)
 
 	tenant, err := makeTenant(reqCtx, r.config.Tenant)
 	if err != nil {
-		r.log.Error("failed to build query options", err)
+		log.Error("failed to build query options", err)
 		return RespondError(reqCtx, err, errToStatusCode)
 	}
 	options := restql.QueryOptions{Tenant: tenant}
 
-	input, err := makeQueryInput(reqCtx, r.log)
+	input, err := makeQueryInput(reqCtx, log)
+	if err != nil {
+		log.Error("failed to build query input", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	ctx, err = withSeed(ctx, input)
+	if err != nil {
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	statusPolicy, err := r.queryStatusPolicy(input)
 	if err != nil {
-		r.log.Error("failed to build query input", err)
 		return RespondError(reqCtx, err, errToStatusCode)
 	}
 
 	queryTxt := string(reqCtx.PostBody())
 
+	if isDryRunEnabled(input) {
+		statements, err := r.evaluator.DryRunQuery(ctx, queryTxt, options, input)
+		if err != nil {
+			log.Error("failed to dry run adhoc query", err)
+
+			adhocErrToStatusCode := make(map[error]int)
+			for err, status := range errToStatusCode {
+				adhocErrToStatusCode[err] = status
+			}
+			adhocErrToStatusCode[eval.ErrParser] = http.StatusBadRequest
+
+			return RespondError(reqCtx, err, adhocErrToStatusCode)
+		}
+
+		return Respond(reqCtx, dryRunResponse{Statements: statements}, http.StatusOK, nil)
+	}
+
+	if isDebugEnabled(input) {
+		ctx = runner.WithTimeline(ctx)
+	}
+
 	result, err := r.evaluator.AdHocQuery(ctx, queryTxt, options, input)
 	if err != nil {
-		r.log.Error("failed to evaluated adhoc query", err)
+		log.Error("failed to evaluated adhoc query", err)
 
 		adhocErrToStatusCode := make(map[error]int)
 		for err, status := range errToStatusCode {
@@ -79,23 +186,35 @@ func (r restQl) RunAdHocQuery(reqCtx *fasthttp.RequestCtx) error {
 		return RespondError(reqCtx, err, adhocErrToStatusCode)
 	}
 
-	debugEnabled := isDebugEnabled(input)
-	response, err := MakeQueryResponse(result, debugEnabled)
+	if dr, ok := passThroughResource(ctx, input, result); ok {
+		return r.respondPassThrough(ctx, reqCtx, result, dr)
+	}
+
+	response, err := MakeQueryResponse(result, r.debugOptions(ctx, input))
 	if err != nil {
 		return RespondError(reqCtx, err, errToStatusCode)
 	}
+	response.StatusCode = ApplyStatusPolicy(statusPolicy, response.StatusCode)
+	setStalenessHeader(ctx, response.Headers)
+	setQueryHeaders(ctx, response.Headers)
+	if r.config.HTTP.SLOHeader {
+		setSLOHeader(ctx, response.Headers)
+	}
+	response.Warnings = eval.Warnings(ctx)
 
-	return Respond(reqCtx, response.Body, response.StatusCode, response.Headers)
+	return r.respondQuery(ctx, reqCtx, result, response)
 }
 
 func (r restQl) RunSavedQuery(reqCtx *fasthttp.RequestCtx) error {
-	log := r.log.With("restql-endpoint", string(reqCtx.Request.URI().Path()))
-	log = log.With("request-id", string(reqCtx.Request.Header.Peek("X-TID")))
-
 	ctx := middleware.GetNativeContext(reqCtx)
+	log := requestLogger(ctx, r.log).With("restql-endpoint", string(reqCtx.Request.URI().Path()))
 	ctx = restql.WithLogger(ctx, log)
+	ctx = cache.WithStalenessTracking(ctx)
+	ctx = runner.WithSLOTracking(ctx)
+	ctx = eval.WithWarnings(ctx)
+	ctx = eval.WithPassThrough(ctx)
 
-	options, err := makeQueryOptions(reqCtx, log, r.config.Tenant)
+	options, err := makeQueryOptions(reqCtx, log, r.config)
 	if err != nil {
 		log.Error("failed to build query options", err)
 		return RespondError(reqCtx, err, errToStatusCode)
@@ -107,6 +226,31 @@ func (r restQl) RunSavedQuery(reqCtx *fasthttp.RequestCtx) error {
 		return RespondError(reqCtx, err, errToStatusCode)
 	}
 
+	ctx, err = withSeed(ctx, input)
+	if err != nil {
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	statusPolicy, err := r.queryStatusPolicy(input)
+	if err != nil {
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	if isDryRunEnabled(input) {
+		statements, err := r.evaluator.DryRunSavedQuery(ctx, options, input)
+		if err != nil {
+			log.Error("failed to dry run saved query", err)
+			return RespondError(reqCtx, err, errToStatusCode)
+		}
+
+		return Respond(reqCtx, dryRunResponse{Statements: statements}, http.StatusOK, nil)
+	}
+
+	if isDebugEnabled(input) {
+		ctx = runner.WithTimeline(ctx)
+	}
+	ctx = withQueryHeaders(ctx, r.config.QueryHeaders[options.Namespace][options.Id], input)
+
 	result, err := r.evaluator.SavedQuery(ctx, options, input)
 	if err != nil {
 		log.Error("failed to evaluated saved query", err)
@@ -114,16 +258,70 @@ func (r restQl) RunSavedQuery(reqCtx *fasthttp.RequestCtx) error {
 		return RespondError(reqCtx, err, errToStatusCode)
 	}
 
-	debugEnabled := isDebugEnabled(input)
-	response, err := MakeQueryResponse(result, debugEnabled)
+	if dr, ok := passThroughResource(ctx, input, result); ok {
+		return r.respondPassThrough(ctx, reqCtx, result, dr)
+	}
+
+	response, err := MakeQueryResponse(result, r.debugOptions(ctx, input))
+	if err != nil {
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+	response.StatusCode = ApplyStatusPolicy(statusPolicy, response.StatusCode)
+	setStalenessHeader(ctx, response.Headers)
+	setQueryHeaders(ctx, response.Headers)
+	if r.config.HTTP.SLOHeader {
+		setSLOHeader(ctx, response.Headers)
+	}
+	response.Warnings = eval.Warnings(ctx)
+
+	return r.respondQuery(ctx, reqCtx, result, response)
+}
+
+// respondQuery encodes the query response and writes it
+// once post-processed by the lifecycle plugins.
+func (r restQl) respondQuery(ctx context.Context, reqCtx *fasthttp.RequestCtx, result domain.Resources, response QueryResponse) error {
+	contentType, body, err := encodeQueryResponse(ctx, reqCtx, response, r.encoder)
 	if err != nil {
 		return RespondError(reqCtx, err, errToStatusCode)
 	}
 
-	return Respond(reqCtx, response.Body, response.StatusCode, response.Headers)
+	return r.writeResponse(ctx, reqCtx, result, restql.QueryResponse{
+		Status:      response.StatusCode,
+		ContentType: contentType,
+		Header:      response.Headers,
+		Body:        body,
+	})
+}
+
+// writeResponse lets the lifecycle plugins replace the
+// encoded query response before writing it to the client.
+func (r restQl) writeResponse(ctx context.Context, reqCtx *fasthttp.RequestCtx, result domain.Resources, response restql.QueryResponse) error {
+	response = r.lifecycle.BeforeResponse(ctx, result, response)
+	reqCtx.SetUserValue(queryResponseKey, response)
+
+	return writeQueryBody(reqCtx, response.ContentType, response.Body, response.Status, response.Header)
 }
 
-func makeQueryOptions(ctx *fasthttp.RequestCtx, log restql.Logger, envTenant string) (restql.QueryOptions, error) {
+func makeQueryOptions(ctx *fasthttp.RequestCtx, log restql.Logger, cfg *conf.Config) (restql.QueryOptions, error) {
+	qo, err := makeQueryRevisionOptions(ctx, log, cfg)
+	if err != nil {
+		return restql.QueryOptions{}, err
+	}
+
+	tenant, err := makeTenant(ctx, cfg.Tenant)
+	if err != nil {
+		return restql.QueryOptions{}, err
+	}
+	qo.Tenant = tenant
+
+	return qo, nil
+}
+
+// makeQueryRevisionOptions identifies the saved query from the
+// path parameters, for operations that do not depend on a tenant.
+// Revision aliases are resolved on every call, so executions of a
+// canaried alias are split between its revisions.
+func makeQueryRevisionOptions(ctx *fasthttp.RequestCtx, log restql.Logger, cfg *conf.Config) (restql.QueryOptions, error) {
 	namespace, err := pathParamString(ctx, "namespace")
 	if err != nil {
 		log.Error("failed to load namespace path param", err)
@@ -142,14 +340,9 @@ func makeQueryOptions(ctx *fasthttp.RequestCtx, log restql.Logger, envTenant str
 		return restql.QueryOptions{}, err
 	}
 
-	revision, err := strconv.Atoi(revisionStr)
-	if err != nil {
-		log.Debug("failed to convert revision to integer")
-		return restql.QueryOptions{}, errInvalidRevisionType
-	}
-
-	tenant, err := makeTenant(ctx, envTenant)
+	revision, err := ResolveRevision(cfg.RevisionAliases[namespace][queryID], revisionStr, rollCanary())
 	if err != nil {
+		log.Debug("failed to resolve revision", "revision", revisionStr)
 		return restql.QueryOptions{}, err
 	}
 
@@ -157,7 +350,6 @@ func makeQueryOptions(ctx *fasthttp.RequestCtx, log restql.Logger, envTenant str
 		Namespace: namespace,
 		Id:        queryID,
 		Revision:  revision,
-		Tenant:    tenant,
 	}
 
 	return qo, nil
@@ -229,23 +421,149 @@ func makeQueryInput(ctx *fasthttp.RequestCtx, log restql.Logger) (restql.QueryIn
 	return input, nil
 }
 
-const debugParamName = "_debug"
+const staleMappingsHeader = "X-Restql-Stale-Mappings"
+
+// setStalenessHeader informs the age, in seconds, of the mappings used by the query
+// when they were served from cache after failing to be refreshed from the database.
+func setStalenessHeader(ctx context.Context, headers map[string]string) {
+	age, stale := cache.StaleAge(ctx)
+	if !stale {
+		return
+	}
+
+	headers[staleMappingsHeader] = strconv.Itoa(int(age.Seconds()))
+}
+
+const (
+	sloHeader = "x-restql-slo"
+
+	// querySLOName identifies the query itself among
+	// the statements on the slo header.
+	querySLOName = "$query"
+)
+
+// setSLOHeader informs the statements, and the query itself, whose
+// latency exceeded the target given by the `slo` modifier, each with
+// its latency and target in milliseconds.
+func setSLOHeader(ctx context.Context, headers map[string]string) {
+	breaches := runner.SLOBreaches(ctx)
+	if len(breaches) == 0 {
+		return
+	}
+
+	values := make([]string, len(breaches))
+	for i, b := range breaches {
+		name := b.Statement
+		if name == "" {
+			name = querySLOName
+		}
+		values[i] = fmt.Sprintf("%s;latency=%d;target=%d", name, b.Latency.Milliseconds(), b.Target.Milliseconds())
+	}
+
+	headers[sloHeader] = strings.Join(values, ", ")
+}
+
+const (
+	debugParamName        = "_debug"
+	passThroughParamName  = "_passthrough"
+	dryRunParamName       = "_dryrun"
+	seedParamName         = "_seed"
+	statusPolicyParamName = "_statusPolicy"
+)
+
+func (r restQl) debugOptions(ctx context.Context, queryInput restql.QueryInput) DebugOptions {
+	requestID, _ := restql.RequestID(ctx)
+	options := DebugOptions{Enabled: isDebugEnabled(queryInput), Redactor: r.redactor, RequestID: requestID}
+	if seed, ok := restql.Seed(ctx); ok {
+		options.Seed = &seed
+	}
+	return options
+}
+
+// queryStatusPolicy returns the status policy given by the
+// _statusPolicy parameter or, without it, the configured one.
+func (r restQl) queryStatusPolicy(queryInput restql.QueryInput) (string, error) {
+	param, found := queryInput.Params[statusPolicyParamName]
+	if !found {
+		return r.statusPolicy, nil
+	}
+
+	value, ok := param.(string)
+	if !ok || value == "" {
+		return "", errInvalidStatusPolicy
+	}
+
+	return ParseStatusPolicy(value)
+}
+
+// withSeed stores in the context the seed of the query execution,
+// given by the _seed parameter or picked at random, which is
+// reported in the debug payload for the execution to be replayed.
+func withSeed(ctx context.Context, queryInput restql.QueryInput) (context.Context, error) {
+	param, found := queryInput.Params[seedParamName]
+	if !found {
+		return restql.WithSeed(ctx, newSeed()), nil
+	}
+
+	value, ok := param.(string)
+	if !ok {
+		return nil, errInvalidSeed
+	}
+
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, errInvalidSeed
+	}
+
+	return restql.WithSeed(ctx, seed), nil
+}
+
+func newSeed() int64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return time.Now().UnixNano()
+	}
+
+	return int64(binary.BigEndian.Uint64(b[:]) >> 1)
+}
+
+// requestLogger returns the logger of a query request,
+// tagged with its request id when there is one.
+func requestLogger(ctx context.Context, log restql.Logger) restql.Logger {
+	if requestID, ok := restql.RequestID(ctx); ok {
+		return log.With("request-id", requestID)
+	}
+
+	return log
+}
 
 func isDebugEnabled(queryInput restql.QueryInput) bool {
-	param, found := queryInput.Params[debugParamName]
+	return isFlagEnabled(queryInput, debugParamName)
+}
+
+func isPassThroughEnabled(queryInput restql.QueryInput) bool {
+	return isFlagEnabled(queryInput, passThroughParamName)
+}
+
+func isDryRunEnabled(queryInput restql.QueryInput) bool {
+	return isFlagEnabled(queryInput, dryRunParamName)
+}
+
+func isFlagEnabled(queryInput restql.QueryInput, name string) bool {
+	param, found := queryInput.Params[name]
 	if !found {
 		return false
 	}
 
-	debug, ok := param.(string)
+	value, ok := param.(string)
 	if !ok {
 		return false
 	}
 
-	d, err := strconv.ParseBool(debug)
+	enabled, err := strconv.ParseBool(value)
 	if err != nil {
 		return false
 	}
 
-	return d
+	return enabled
 }
@@ -0,0 +1,35 @@
+package web
+
+import (
+	"math/rand"
+	"strconv"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+)
+
+// ResolveRevision returns the saved query revision identified by the
+// revision path parameter, which is either a number or one of the
+// aliases declared for the query. Aliases with a canary send to its
+// revision the executions whose roll, a number from 0 to 99 drawn for
+// each one, is below the canary percentage.
+func ResolveRevision(aliases map[string]conf.RevisionAliasConf, revision string, roll int) (int, error) {
+	if r, err := strconv.Atoi(revision); err == nil {
+		return r, nil
+	}
+
+	alias, found := aliases[revision]
+	if !found || alias.Revision <= 0 {
+		return 0, errInvalidRevisionType
+	}
+
+	canary := alias.Canary
+	if canary.Revision > 0 && roll < canary.Percentage {
+		return canary.Revision, nil
+	}
+
+	return alias.Revision, nil
+}
+
+func rollCanary() int {
+	return rand.Intn(100)
+}
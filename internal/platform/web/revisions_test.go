@@ -0,0 +1,44 @@
+package web_test
+
+import (
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestResolveRevision(t *testing.T) {
+	stable := conf.RevisionAliasConf{Revision: 3}
+	beta := conf.RevisionAliasConf{Revision: 3}
+	beta.Canary.Revision = 4
+	beta.Canary.Percentage = 10
+	aliases := map[string]conf.RevisionAliasConf{"stable": stable, "beta": beta}
+
+	tests := []struct {
+		name     string
+		revision string
+		roll     int
+		expected int
+	}{
+		{"should use numeric revision", "7", 0, 7},
+		{"should resolve alias", "stable", 0, 3},
+		{"should resolve canary revision when roll is below percentage", "beta", 9, 4},
+		{"should resolve alias revision when roll is above percentage", "beta", 10, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := web.ResolveRevision(aliases, tt.revision, tt.roll)
+
+			test.Equal(t, err, nil)
+			test.Equal(t, got, tt.expected)
+		})
+	}
+}
+
+func TestResolveRevisionWithUnknownAlias(t *testing.T) {
+	_, err := web.ResolveRevision(nil, "stable", 0)
+
+	test.NotEqual(t, err, nil)
+}
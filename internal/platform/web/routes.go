@@ -1,37 +1,60 @@
 package web
 
 import (
+	"context"
+	"os"
+
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web/middleware"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
 	"net/http"
 
 	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
 	"github.com/b2wdigital/restQL-golang/v4/internal/parser"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/audit"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/bulkhead"
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/cache"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/codec"
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/httpclient"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/logger"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/notification"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/openapi"
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/persistence"
 	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/ratelimit"
 	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
 	"github.com/valyala/fasthttp"
 )
 
-// API constructs a handler for the restQL query related endpoints
-func API(log restql.Logger, cfg *conf.Config) (fasthttp.RequestHandler, error) {
+// API constructs a handler for the restQL query related endpoints,
+// along with the Checker of the resources and dependencies it uses.
+func API(log restql.Logger, cfg *conf.Config) (fasthttp.RequestHandler, *Checker, error) {
 	log.Debug("starting api")
 	defaultParser, err := parser.New()
 	if err != nil {
 		log.Error("failed to compile parser", err)
-		return nil, err
+		return nil, nil, err
 	}
-	parserCacheLoader := cache.New(log, cfg.Cache.Parser.MaxSize, cache.ParserCacheLoader(defaultParser))
+	parserCacheLoader := cache.New(log, cfg.Cache.Parser.MaxSize,
+		cache.ParserCacheLoader(defaultParser),
+		cache.WithTTL(cfg.Cache.Parser.TTL),
+		cache.WithName("parser"),
+	)
 	parserCache := cache.NewParserCache(log, parserCacheLoader)
 
 	databaseDisabled := cfg.Plugins.DisableDatabase
 	db, err := persistence.NewDatabase(log, databaseDisabled)
 	if err != nil {
 		log.Error("failed to establish connection to database", err)
-		return nil, err
+		return nil, nil, err
+	}
+
+	var snapshot *persistence.Snapshot
+	if cfg.Snapshot.Path != "" {
+		log.Info("database snapshot enabled", "path", cfg.Snapshot.Path)
+		snapshot = persistence.NewSnapshot(log, db, cfg.Snapshot.Path)
+		snapshot.Start(context.Background(), cfg.Snapshot.Interval)
+		db = snapshot
 	}
 
 	lifecycle, err := plugins.NewLifecycle(log)
@@ -39,9 +62,85 @@ func API(log restql.Logger, cfg *conf.Config) (fasthttp.RequestHandler, error) {
 		log.Error("failed to initialize plugins", err)
 	}
 
-	client := httpclient.New(log, lifecycle, cfg)
-	executor := runner.NewExecutor(log, client, cfg.HTTP.QueryResourceTimeout, cfg.HTTP.ForwardPrefix)
-	r := runner.NewRunner(log, executor, cfg.HTTP.GlobalQueryTimeout)
+	s3Client, err := httpclient.WithS3(log, httpclient.WithSigning(httpclient.New(log, lifecycle, cfg)), cfg.S3.Region, cfg.S3.Endpoint, cfg.S3.PathStyle)
+	if err != nil {
+		log.Error("failed to initialize s3 client", err)
+		return nil, nil, err
+	}
+
+	brokerClient, err := httpclient.WithBrokers(log, s3Client, cfg.Brokers.Kafka, cfg.Brokers.AMQP)
+	if err != nil {
+		log.Error("failed to initialize message brokers", err)
+		return nil, nil, err
+	}
+
+	sqlClient, err := httpclient.WithSQL(log, brokerClient, lifecycle, cfg.SQL.Databases)
+	if err != nil {
+		log.Error("failed to initialize sql databases", err)
+		return nil, nil, err
+	}
+
+	cassetteCfg := cfg.HTTP.Client.Cassette
+	client, err := httpclient.WithCassette(log, sqlClient, cassetteCfg.Mode, cassetteCfg.Path)
+	if err != nil {
+		log.Error("failed to initialize cassette", err)
+		return nil, nil, err
+	}
+	responseCache := cache.NewResponseCache(log, cfg.Cache.Responses.MaxSize)
+
+	var rateLimiter *ratelimit.Limiter
+	if cfg.RateLimit != nil {
+		log.Info("rate limit enabled")
+		rateLimiter = ratelimit.New(log, *cfg.RateLimit)
+	}
+
+	var faultInjection *middleware.FaultInjection
+	if cfg.FaultInjection != nil && cfg.FaultInjection.Allowed {
+		log.Warn("fault injection allowed", "enabled", cfg.FaultInjection.Enabled)
+		faultInjection = middleware.NewFaultInjection(*cfg.FaultInjection)
+	}
+
+	var resourceBulkhead *bulkhead.Bulkhead
+	if cfg.Bulkhead != nil {
+		log.Info("resource bulkhead enabled")
+		resourceBulkhead = bulkhead.New(*cfg.Bulkhead)
+	}
+
+	if cfg.Logging.AccessLog {
+		log.Info("access log enabled")
+		restql.SubscribeEvents(logger.NewAccessLog(os.Stdout))
+	}
+
+	if webhookCfg := cfg.Notifications.Webhook; webhookCfg.URL != "" {
+		log.Info("query notification webhook enabled", "url", webhookCfg.URL)
+		restql.SubscribeEvents(notification.NewWebhook(log, webhookCfg).Handler())
+	}
+
+	if cfg.Audit != nil {
+		auditor, err := audit.New(log, *cfg.Audit, cfg.Brokers.Kafka)
+		if err != nil {
+			log.Error("failed to initialize audit log", err)
+			return nil, nil, err
+		}
+		log.Info("audit log enabled", "sink", cfg.Audit.Sink)
+		restql.SubscribeEvents(auditor.Handler())
+	}
+
+	cascade, err := makeDefaultsCascade(cfg)
+	if err != nil {
+		log.Error("failed to initialize defaults", err)
+		return nil, nil, err
+	}
+
+	executor := runner.NewExecutor(log, client, responseCache, rateLimiter, resourceBulkhead, cfg.HTTP.QueryResourceTimeout, cfg.HTTP.ForwardPrefix, requestIDHeaders(cfg))
+	profiler := runner.NewProfiler(cfg.HTTP.Server.EnablePprofLabels)
+	r := runner.NewRunner(log, executor, cfg.HTTP.GlobalQueryTimeout, cascade, profiler, cfg.HTTP.MaxChainDepth)
+
+	failureMode, err := cache.ParseFailureMode(cfg.Cache.Mappings.FailureMode)
+	if err != nil {
+		log.Error("failed to initialize mappings cache", err)
+		return nil, nil, err
+	}
 
 	mappingReader := persistence.NewMappingReader(log, cfg.Env, cfg.Mappings, cfg.TenantMappings, db)
 	tenantCache := cache.New(log, cfg.Cache.Mappings.MaxSize,
@@ -49,35 +148,152 @@ func API(log restql.Logger, cfg *conf.Config) (fasthttp.RequestHandler, error) {
 		cache.WithExpiration(cfg.Cache.Mappings.Expiration),
 		cache.WithRefreshInterval(cfg.Cache.Mappings.RefreshInterval),
 		cache.WithRefreshQueueLength(cfg.Cache.Mappings.RefreshQueueLength),
+		cache.WithName("mappings"),
+		cache.WithFailureMode(failureMode),
 	)
 	cacheMr := cache.NewMappingsReaderCache(log, tenantCache)
+	if cfg.Cache.Mappings.WarmUp {
+		go warmMappingsCache(log, cfg.Tenant, mappingReader, tenantCache)
+	}
 
 	queryReader := persistence.NewQueryReader(log, cfg.Queries, db)
-	queryCache := cache.New(log, cfg.Cache.Query.MaxSize, cache.QueryCacheLoader(queryReader))
-	cacheQr := cache.NewQueryReaderCache(log, queryCache)
+	queryCache := cache.New(log, cfg.Cache.Query.MaxSize, cache.QueryCacheLoader(queryReader), cache.WithName("query"))
+	cacheQr := cache.NewQueryReaderCache(log, queryCache, queryReader)
 
-	e := eval.NewEvaluator(log, cacheMr, cacheQr, r, parserCache, lifecycle)
+	var recorder *eval.ExecutionRecorder
+	if cassetteCfg.Mode == httpclient.CassetteRecord {
+		recorder = eval.NewExecutionRecorder(cassetteCfg.Executions)
+	}
 
-	restQl := newRestQl(log, cfg, e, defaultParser)
+	e := eval.NewEvaluator(log, cacheMr, cacheQr, r, parserCache, lifecycle, cfg.HTTP.FailOnHiddenErrors, recorder)
 
-	md := middleware.NewDecorator(log, cfg, lifecycle)
+	encoderCfg := cfg.HTTP.Server.JSONEncoder
+	encoder, err := codec.NewJSONEncoder(encoderCfg.Name, codec.JSONOptions{EscapeHTML: encoderCfg.EscapeHTML, SortKeys: encoderCfg.SortKeys})
+	if err != nil {
+		log.Error("failed to initialize json encoder", err)
+		return nil, nil, err
+	}
+
+	redactor, err := NewHeaderRedactor(cfg.Debug.RedactHeaders, cfg.Debug.RedactHeaderPatterns)
+	if err != nil {
+		log.Error("failed to initialize debug header redaction", err)
+		return nil, nil, err
+	}
+
+	statusPolicy, err := ParseStatusPolicy(cfg.HTTP.StatusPolicy)
+	if err != nil {
+		log.Error("failed to initialize status policy", err)
+		return nil, nil, err
+	}
+
+	qt := NewQueryTester(log, cfg, cacheMr, cacheQr, parserCache)
+	restQl := newRestQl(log, cfg, e, encoder, qt, redactor, lifecycle, statusPolicy, httpclient.NewEventSource(log))
+
+	runAdHocQuery, runSavedQuery := handler(restQl.RunAdHocQuery), handler(restQl.RunSavedQuery)
+	if cfg.HTTP.Server.ProxyCache.Enable {
+		log.Info("proxy cache enabled")
+		pc := newProxyCache(cache.NewProxyCache(log, cfg.HTTP.Server.ProxyCache.MaxSize))
+		runAdHocQuery, runSavedQuery = pc.Handle(runAdHocQuery), pc.Handle(runSavedQuery)
+	}
+
+	md := middleware.NewDecorator(log, cfg, lifecycle, rateLimiter, faultInjection)
 	app := newApp(log, appOptions{MiddlewareDecorator: md})
 	app.Handle(http.MethodPost, "/validate-query", restQl.ValidateQuery)
-	app.Handle(http.MethodPost, "/run-query", restQl.RunAdHocQuery)
-	app.Handle(http.MethodGet, "/run-query/{namespace}/{queryId}/{revision}", restQl.RunSavedQuery)
-	app.Handle(http.MethodPost, "/run-query/{namespace}/{queryId}/{revision}", restQl.RunSavedQuery)
+	app.Handle(http.MethodPost, "/explain-query", restQl.ExplainQuery)
+	app.Handle(http.MethodGet, "/explain/{namespace}/{queryId}/{revision}", restQl.ExplainSavedQuery)
+	app.Handle(http.MethodPost, "/run-query", runAdHocQuery)
+	app.Handle(http.MethodPost, "/run-query/stream", restQl.StreamAdHocQuery)
+	app.Handle(http.MethodPost, "/run-queries", restQl.RunQueries)
+	app.Handle(http.MethodGet, "/run-query/{namespace}/{queryId}/{revision}", runSavedQuery)
+	app.Handle(http.MethodPost, "/run-query/{namespace}/{queryId}/{revision}", runSavedQuery)
+	app.Handle(http.MethodGet, "/diff-query/{namespace}/{queryId}/{revision}", restQl.DiffSavedQuery)
+	app.Handle(http.MethodPost, "/diff-query/{namespace}/{queryId}/{revision}", restQl.DiffSavedQuery)
+	app.Handle(http.MethodGet, "/infer-schema/{namespace}/{queryId}/{revision}", restQl.InferQuerySchema)
+	app.Handle(http.MethodPost, "/infer-schema/{namespace}/{queryId}/{revision}", restQl.InferQuerySchema)
+
+	mw := persistence.NewMappingWriter(log, cfg.Env, cfg.Mappings, cfg.TenantMappings, db)
+	if len(cfg.MappingImports) > 0 {
+		log.Info("mapping imports enabled", "imports", len(cfg.MappingImports))
+		openapi.NewSyncer(log, &mw, cfg.MappingImports).Start(context.Background())
+	}
 
 	if cfg.HTTP.Server.Admin.Enable {
 		log.Info("administration api enabled")
-		mw := persistence.NewMappingWriter(log, cfg.Env, cfg.Mappings, cfg.TenantMappings, db)
 		qw := persistence.NewQueryWriter(log, cfg.Queries, db)
 
-		adm := newAdmin(mappingReader, mw, queryReader, qw)
+		adm := newAdmin(mappingReader, mw, queryReader, qw, r, e, qt, responseCache, faultInjection, rateLimiter, resourceBulkhead)
 		app = registerAdminEndpoints(adm, app)
 
 	}
 
-	return app.RequestHandler(), nil
+	checker := NewChecker(log, cfg, cacheMr, r, readinessChecks(cfg, db, cacheMr, rateLimiter))
+	checker.snapshot = snapshot
+
+	return app.RequestHandler(), checker, nil
+}
+
+// readinessChecks returns the verifications of the dependencies
+// needed to serve queries: the database plugin, the mappings of
+// the tenant locked by configuration and the rate limit store.
+func readinessChecks(cfg *conf.Config, db persistence.Database, mr eval.MappingsReader, rateLimiter *ratelimit.Limiter) map[string]func(ctx context.Context) error {
+	checks := map[string]func(ctx context.Context) error{
+		"database": func(ctx context.Context) error {
+			return persistence.PingDatabase(ctx, db)
+		},
+	}
+
+	if cfg.Tenant != "" {
+		checks["mappings"] = func(ctx context.Context) error {
+			_, err := mr.FromTenant(ctx, cfg.Tenant)
+			return err
+		}
+	}
+
+	if rateLimiter != nil {
+		checks["rateLimit"] = rateLimiter.Ping
+	}
+
+	return checks
+}
+
+// warmMappingsCache loads the mappings of every known tenant,
+// or only the one locked by configuration, into the cache.
+func warmMappingsCache(log restql.Logger, envTenant string, mr persistence.MappingsReader, c *cache.Cache) {
+	ctx := restql.WithLogger(context.Background(), log)
+
+	tenants := []string{envTenant}
+	if envTenant == "" {
+		var err error
+		tenants, err = mr.ListTenants(ctx)
+		if err != nil {
+			log.Warn("failed to list tenants to warm mappings cache", "error", err)
+			return
+		}
+	}
+
+	keys := make([]interface{}, len(tenants))
+	for i, t := range tenants {
+		keys[i] = t
+	}
+
+	c.Warm(ctx, keys...)
+	log.Info("mappings cache warmed", "tenants", len(tenants))
+}
+
+// requestIDHeaders returns the headers carrying the request id
+// to the upstream calls, which default to the one it is received
+// and returned with.
+func requestIDHeaders(cfg *conf.Config) []string {
+	requestID := cfg.HTTP.Server.Middlewares.RequestID
+	if requestID == nil || requestID.Header == "" {
+		return nil
+	}
+
+	if len(requestID.Propagate) > 0 {
+		return requestID.Propagate
+	}
+
+	return []string{requestID.Header}
 }
 
 // registerAdminEndpoints adds handlers for administrative operations
@@ -85,23 +301,41 @@ func registerAdminEndpoints(adm *administrator, apiApp app) app {
 	apiApp.Handle(http.MethodGet, "/admin/tenant", adm.AllTenants)
 	apiApp.Handle(http.MethodGet, "/admin/tenant/{tenantName}/mapping", adm.TenantMappings)
 	apiApp.Handle(http.MethodPost, "/admin/tenant/{tenantName}/mapping/{resource}", adm.MapResource)
+	apiApp.Handle(http.MethodPost, "/admin/tenant/{tenantName}/mapping/{resource}/headers", adm.PreviewHeaders)
+	apiApp.Handle(http.MethodPost, "/admin/tenant/{tenantName}/mapping-import", adm.ImportMappings)
+	apiApp.Handle(http.MethodGet, "/admin/tenant/{tenantName}/health", adm.TenantHealth)
 
 	apiApp.Handle(http.MethodGet, "/admin/namespace", adm.AllNamespaces)
 	apiApp.Handle(http.MethodGet, "/admin/namespace/{namespace}/query", adm.NamespaceQueries)
+	apiApp.Handle(http.MethodGet, "/admin/namespace/{namespace}/client", adm.GenerateClient)
 	apiApp.Handle(http.MethodGet, "/admin/namespace/{namespace}/query/{queryId}", adm.QueryRevisions)
 	apiApp.Handle(http.MethodGet, "/admin/namespace/{namespace}/query/{queryId}/revision/{revision}", adm.Query)
 	apiApp.Handle(http.MethodPost, "/admin/namespace/{namespace}/query/{queryId}", adm.CreateQueryRevision)
+	apiApp.Handle(http.MethodPost, "/admin/namespace/{namespace}/query/{queryId}/test", adm.TestQuery)
+	apiApp.Handle(http.MethodPost, "/admin/namespace/{namespace}/query/{queryId}/diff", adm.DiffQueryRevisions)
+	apiApp.Handle(http.MethodGet, "/admin/namespace/{namespace}/query/{queryId}/execution", adm.QueryExecutions)
+	apiApp.Handle(http.MethodPost, "/admin/namespace/{namespace}/query/{queryId}/execution/{executionId}/render", adm.RenderQueryExecution)
+
+	apiApp.Handle(http.MethodGet, "/admin/runtime", adm.RuntimeState)
+	apiApp.Handle(http.MethodDelete, "/admin/cache/responses", adm.InvalidateResponses)
+	apiApp.Handle(http.MethodGet, "/admin/profiling", adm.Profiling)
+	apiApp.Handle(http.MethodPut, "/admin/profiling", adm.UpdateProfiling)
+	apiApp.Handle(http.MethodGet, "/admin/faults", adm.FaultInjection)
+	apiApp.Handle(http.MethodPut, "/admin/faults", adm.UpdateFaultInjection)
 
 	return apiApp
 }
 
 // Health constructs a handler for system checks endpoints
-func Health(log restql.Logger, cfg *conf.Config) fasthttp.RequestHandler {
+func Health(log restql.Logger, cfg *conf.Config, checker *Checker) fasthttp.RequestHandler {
 	app := newApp(log, appOptions{})
-	check := newCheck(cfg.Build)
+	check := newCheck(cfg.Build, checker)
 
 	app.Handle(http.MethodGet, "/health", check.Health)
+	app.Handle(http.MethodGet, "/health/resources", check.Resources)
+	app.Handle(http.MethodGet, "/ready", check.Ready)
 	app.Handle(http.MethodGet, "/resource-status", check.ResourceStatus)
+	app.Handle(http.MethodGet, "/debug/vars", check.Vars)
 
 	return app.RequestHandler()
 }
@@ -118,6 +352,7 @@ func Debug(log restql.Logger) fasthttp.RequestHandler {
 	app.Handle(http.MethodGet, "/debug/pprof/mutex", d.Index)
 
 	app.Handle(http.MethodGet, "/debug/pprof/profile", d.Profile)
+	app.Handle(http.MethodGet, "/debug/pprof/trace", d.Trace)
 
 	return app.RequestHandler()
 }
@@ -0,0 +1,502 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/cache"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web/middleware"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// JSON Schema types inferred from sampled values.
+const (
+	SchemaNull    = "null"
+	SchemaBoolean = "boolean"
+	SchemaInteger = "integer"
+	SchemaNumber  = "number"
+	SchemaString  = "string"
+	SchemaArray   = "array"
+	SchemaObject  = "object"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Query arguments of the schema inference endpoint.
+const (
+	schemaFormatArg   = "format"
+	schemaFixturesArg = "fixtures"
+)
+
+// Formats of the inferred response schema.
+const (
+	JSONSchemaFormat = "json-schema"
+	TypeScriptFormat = "typescript"
+)
+
+var (
+	errInvalidSchemaFormat = errors.New("invalid format : must be json-schema or typescript")
+	errTestCaseNotFound    = errors.New("query test case not found")
+)
+
+var (
+	typescriptIdentifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	typeNameSeparatorRegex    = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// InferQuerySchema executes a saved query and returns the schema of its
+// response body, as a JSON Schema document or a TypeScript type, so clients
+// can be typed from it. The `fixtures` query argument selects a test case
+// of the query, whose input and fixtures are used instead of calling the
+// upstreams. Otherwise, every other argument is used as the query input.
+func (r restQl) InferQuerySchema(reqCtx *fasthttp.RequestCtx) error {
+	log := r.log.With("restql-endpoint", string(reqCtx.Request.URI().Path()))
+
+	ctx := middleware.GetNativeContext(reqCtx)
+	ctx = restql.WithLogger(ctx, log)
+	ctx = cache.WithStalenessTracking(ctx)
+
+	options, err := makeQueryOptions(reqCtx, log, r.config)
+	if err != nil {
+		log.Error("failed to build query options", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	format := string(reqCtx.QueryArgs().Peek(schemaFormatArg))
+	if format == "" {
+		format = JSONSchemaFormat
+	}
+	if format != JSONSchemaFormat && format != TypeScriptFormat {
+		return RespondError(reqCtx, errInvalidSchemaFormat, errToStatusCode)
+	}
+
+	var body interface{}
+	if caseName := string(reqCtx.QueryArgs().Peek(schemaFixturesArg)); caseName != "" {
+		body, err = r.sampleFromFixtures(ctx, options, caseName)
+	} else {
+		body, err = r.sampleFromUpstreams(ctx, reqCtx, options)
+	}
+	if err != nil {
+		log.Error("failed to sample query response", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	schema := InferSchema(body)
+	if format == TypeScriptFormat {
+		reqCtx.Response.Header.SetContentType("application/typescript; charset=utf-8")
+		reqCtx.Response.SetStatusCode(fasthttp.StatusOK)
+		reqCtx.Response.SetBodyString(schema.TypeScript(typescriptTypeName(options.Id)))
+		return nil
+	}
+
+	title := fmt.Sprintf("%s/%s/%d", options.Namespace, options.Id, options.Revision)
+	return Respond(reqCtx, schema.Document(title), fasthttp.StatusOK, nil)
+}
+
+func (r restQl) sampleFromFixtures(ctx context.Context, options restql.QueryOptions, caseName string) (interface{}, error) {
+	tc, found := r.tester.Case(options.Namespace, options.Id, caseName)
+	if !found {
+		return nil, fmt.Errorf("%w : %s", errTestCaseNotFound, caseName)
+	}
+	tc.Tenant = options.Tenant
+
+	return r.tester.Execute(ctx, options.Namespace, options.Id, options.Revision, tc)
+}
+
+func (r restQl) sampleFromUpstreams(ctx context.Context, reqCtx *fasthttp.RequestCtx, options restql.QueryOptions) (interface{}, error) {
+	input, err := makeQueryInput(reqCtx, r.log)
+	if err != nil {
+		return nil, err
+	}
+	delete(input.Params, schemaFormatArg)
+
+	result, err := r.evaluator.SavedQuery(ctx, options, input)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := MakeQueryResponse(result, DebugOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return normalizeBody(response.Body)
+}
+
+// JSONSchema represents the shape of a JSON value, inferred from samples.
+// A value seen with more than one type has all of them in Types, along
+// with the properties or items of its object and array samples.
+type JSONSchema struct {
+	Types      []string
+	Properties map[string]*JSONSchema
+	Required   []string
+	Items      *JSONSchema
+}
+
+// InferSchema returns the schema of a value in its generic JSON form.
+// Array items are merged in a single schema, where object properties
+// are required only if present in every item.
+func InferSchema(value interface{}) *JSONSchema {
+	switch v := value.(type) {
+	case nil:
+		return &JSONSchema{Types: []string{SchemaNull}}
+	case bool:
+		return &JSONSchema{Types: []string{SchemaBoolean}}
+	case float64:
+		if v == math.Trunc(v) {
+			return &JSONSchema{Types: []string{SchemaInteger}}
+		}
+		return &JSONSchema{Types: []string{SchemaNumber}}
+	case string:
+		return &JSONSchema{Types: []string{SchemaString}}
+	case []interface{}:
+		var items *JSONSchema
+		for _, item := range v {
+			items = MergeSchemas(items, InferSchema(item))
+		}
+		return &JSONSchema{Types: []string{SchemaArray}, Items: items}
+	case map[string]interface{}:
+		s := &JSONSchema{Types: []string{SchemaObject}, Properties: make(map[string]*JSONSchema, len(v))}
+		for key, item := range v {
+			s.Properties[key] = InferSchema(item)
+			s.Required = append(s.Required, key)
+		}
+		sort.Strings(s.Required)
+		return s
+	default:
+		return &JSONSchema{}
+	}
+}
+
+// MergeSchemas returns a schema matching the values of both schemas.
+// A nil schema represents the absence of samples.
+func MergeSchemas(a, b *JSONSchema) *JSONSchema {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	merged := &JSONSchema{Types: mergeTypes(a.Types, b.Types)}
+
+	if a.Properties != nil || b.Properties != nil {
+		merged.Properties = make(map[string]*JSONSchema)
+		for key, s := range a.Properties {
+			merged.Properties[key] = MergeSchemas(s, b.Properties[key])
+		}
+		for key, s := range b.Properties {
+			if _, found := a.Properties[key]; !found {
+				merged.Properties[key] = s
+			}
+		}
+		merged.Required = mergeRequired(a, b)
+	}
+
+	merged.Items = MergeSchemas(a.Items, b.Items)
+
+	return merged
+}
+
+// mergeTypes returns the sorted union of the types, where
+// integer is dropped when number is present.
+func mergeTypes(a, b []string) []string {
+	set := make(map[string]struct{}, len(a)+len(b))
+	for _, t := range append(append([]string{}, a...), b...) {
+		set[t] = struct{}{}
+	}
+	if _, found := set[SchemaNumber]; found {
+		delete(set, SchemaInteger)
+	}
+
+	types := make([]string, 0, len(set))
+	for t := range set {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// mergeRequired returns the properties required by both schemas, where
+// a schema that is not an object does not restrict the other one.
+func mergeRequired(a, b *JSONSchema) []string {
+	if a.Properties == nil {
+		return b.Required
+	}
+	if b.Properties == nil {
+		return a.Required
+	}
+
+	inB := make(map[string]struct{}, len(b.Required))
+	for _, key := range b.Required {
+		inB[key] = struct{}{}
+	}
+
+	var required []string
+	for _, key := range a.Required {
+		if _, found := inB[key]; found {
+			required = append(required, key)
+		}
+	}
+
+	return required
+}
+
+// MarshalJSON encodes the schema following the JSON Schema specification.
+func (s *JSONSchema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.document(""))
+}
+
+// Document returns the schema as a JSON Schema document with the given title.
+func (s *JSONSchema) Document(title string) map[string]interface{} {
+	doc := s.document(title)
+	doc["$schema"] = jsonSchemaDraft
+	return doc
+}
+
+func (s *JSONSchema) document(title string) map[string]interface{} {
+	doc := make(map[string]interface{})
+	if title != "" {
+		doc["title"] = title
+	}
+
+	switch len(s.Types) {
+	case 0:
+	case 1:
+		doc["type"] = s.Types[0]
+	default:
+		doc["type"] = s.Types
+	}
+
+	if s.Properties != nil {
+		doc["properties"] = s.Properties
+		if len(s.Required) > 0 {
+			doc["required"] = s.Required
+		}
+	}
+
+	if s.Items != nil {
+		doc["items"] = s.Items
+	}
+
+	return doc
+}
+
+// TypeScript returns an exported TypeScript type declaration
+// with the given name, matching the values of the schema.
+func (s *JSONSchema) TypeScript(name string) string {
+	return fmt.Sprintf("export type %s = %s;\n", name, s.typescript(""))
+}
+
+func (s *JSONSchema) typescript(indent string) string {
+	if s == nil || len(s.Types) == 0 {
+		return "unknown"
+	}
+
+	types := make([]string, len(s.Types))
+	for i, t := range s.Types {
+		switch t {
+		case SchemaInteger, SchemaNumber:
+			types[i] = "number"
+		case SchemaArray:
+			items := s.Items.typescript(indent)
+			if s.Items != nil && len(s.Items.Types) > 1 {
+				items = "(" + items + ")"
+			}
+			types[i] = items + "[]"
+		case SchemaObject:
+			types[i] = s.typescriptObject(indent)
+		default:
+			types[i] = t
+		}
+	}
+
+	return strings.Join(types, " | ")
+}
+
+func (s *JSONSchema) typescriptObject(indent string) string {
+	if len(s.Properties) == 0 {
+		return "{}"
+	}
+
+	required := make(map[string]struct{}, len(s.Required))
+	for _, key := range s.Required {
+		required[key] = struct{}{}
+	}
+
+	keys := make([]string, 0, len(s.Properties))
+	for key := range s.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	inner := indent + "  "
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, key := range keys {
+		name := key
+		if !typescriptIdentifierRegex.MatchString(key) {
+			name = fmt.Sprintf("%q", key)
+		}
+		if _, found := required[key]; !found {
+			name += "?"
+		}
+
+		fmt.Fprintf(&b, "%s%s: %s;\n", inner, name, s.Properties[key].typescript(inner))
+	}
+	b.WriteString(indent + "}")
+
+	return b.String()
+}
+
+// Go returns a Go type expression matching the values of the
+// schema, where objects are anonymous structs, nullable values
+// are pointers and values of mixed types are interface{}.
+func (s *JSONSchema) Go() string {
+	return s.golang("")
+}
+
+func (s *JSONSchema) golang(indent string) string {
+	if s == nil {
+		return "interface{}"
+	}
+
+	types := s.Types
+	nullable := false
+	if len(types) == 2 && (types[0] == SchemaNull || types[1] == SchemaNull) {
+		nullable = true
+		if types[0] == SchemaNull {
+			types = types[1:]
+		} else {
+			types = types[:1]
+		}
+	}
+	if len(types) != 1 {
+		return "interface{}"
+	}
+
+	var t string
+	switch types[0] {
+	case SchemaBoolean:
+		t = "bool"
+	case SchemaInteger:
+		t = "int64"
+	case SchemaNumber:
+		t = "float64"
+	case SchemaString:
+		t = "string"
+	case SchemaArray:
+		return "[]" + s.Items.golang(indent)
+	case SchemaObject:
+		t = s.golangStruct(indent)
+	default:
+		return "interface{}"
+	}
+
+	if nullable {
+		return "*" + t
+	}
+	return t
+}
+
+func (s *JSONSchema) golangStruct(indent string) string {
+	if len(s.Properties) == 0 {
+		return "map[string]interface{}"
+	}
+
+	required := make(map[string]struct{}, len(s.Required))
+	for _, key := range s.Required {
+		required[key] = struct{}{}
+	}
+
+	keys := make([]string, 0, len(s.Properties))
+	for key := range s.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	inner := indent + "\t"
+	names := make(map[string]int, len(keys))
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	for _, key := range keys {
+		name := goFieldName(key)
+		names[name]++
+		if n := names[name]; n > 1 {
+			name = fmt.Sprintf("%s%d", name, n)
+		}
+
+		tag := key
+		if _, found := required[key]; !found {
+			tag += ",omitempty"
+		}
+
+		fmt.Fprintf(&b, "%s%s %s `json:%q`\n", inner, name, s.Properties[key].golang(inner), tag)
+	}
+	b.WriteString(indent + "}")
+
+	return b.String()
+}
+
+// goInitialisms are the words written in upper case
+// in Go identifiers, following the Go code review comments.
+var goInitialisms = map[string]struct{}{
+	"api": {}, "html": {}, "http": {}, "https": {}, "id": {},
+	"ip": {}, "json": {}, "sku": {}, "uri": {}, "url": {}, "uuid": {},
+}
+
+// goFieldName converts a JSON object key to an exported
+// Go identifier, like `firstAppearance` to `FirstAppearance`.
+func goFieldName(key string) string {
+	var b strings.Builder
+	for _, part := range typeNameSeparatorRegex.Split(key, -1) {
+		if part == "" {
+			continue
+		}
+		if _, found := goInitialisms[strings.ToLower(part)]; found {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+
+	name := b.String()
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "F" + name
+	}
+
+	return name
+}
+
+// typescriptTypeName converts the query identifier to
+// a TypeScript type name, like `FetchHeroResponse`.
+func typescriptTypeName(queryID string) string {
+	return queryTypeName(queryID) + "Response"
+}
+
+// queryTypeName converts the query identifier to the
+// base name of its generated types, like `FetchHero`.
+func queryTypeName(queryID string) string {
+	var b strings.Builder
+	for _, part := range typeNameSeparatorRegex.Split(queryID, -1) {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+
+	name := b.String()
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "Query" + name
+	}
+
+	return name
+}
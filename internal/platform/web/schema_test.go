@@ -0,0 +1,116 @@
+package web_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestInferSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{
+			"should infer primitive types",
+			`{"name":"batman","age":35,"height":1.88,"active":true,"sidekick":null}`,
+			`{"type":"object","properties":{
+				"active":{"type":"boolean"},
+				"age":{"type":"integer"},
+				"height":{"type":"number"},
+				"name":{"type":"string"},
+				"sidekick":{"type":"null"}
+			},"required":["active","age","height","name","sidekick"]}`,
+		},
+		{
+			"should merge array items",
+			`[{"name":"batman","age":35},{"name":"robin","age":17.5,"mask":true}]`,
+			`{"type":"array","items":{"type":"object","properties":{
+				"age":{"type":"number"},
+				"mask":{"type":"boolean"},
+				"name":{"type":"string"}
+			},"required":["age","name"]}}`,
+		},
+		{
+			"should list every type of mixed values",
+			`[{"weapon":"belt"},{"weapon":null},null]`,
+			`{"type":"array","items":{"type":["null","object"],"properties":{
+				"weapon":{"type":["null","string"]}
+			},"required":["weapon"]}}`,
+		},
+		{
+			"should not define items of empty array",
+			`{"weapons":[]}`,
+			`{"type":"object","properties":{"weapons":{"type":"array"}},"required":["weapons"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(web.InferSchema(test.Unmarshal(tt.value)))
+			test.VerifyError(t, err)
+
+			test.Equal(t, test.Unmarshal(string(got)), test.Unmarshal(tt.expected))
+		})
+	}
+}
+
+func TestSchemaTypeScript(t *testing.T) {
+	value := test.Unmarshal(`{
+		"hero": {
+			"details": {"status": 200, "success": true},
+			"result": [{"name": "batman", "weapons": ["belt"], "first-appearance": 1939}, {"name": "robin", "weapons": [1]}]
+		}
+	}`)
+
+	expected := `export type FetchHeroResponse = {
+  hero: {
+    details: {
+      status: number;
+      success: boolean;
+    };
+    result: {
+      "first-appearance"?: number;
+      name: string;
+      weapons: (number | string)[];
+    }[];
+  };
+};
+`
+
+	got := web.InferSchema(value).TypeScript("FetchHeroResponse")
+
+	test.Equal(t, got, expected)
+}
+
+func TestSchemaGo(t *testing.T) {
+	value := test.Unmarshal(`{
+		"hero": {
+			"details": {"status": 200, "success": true},
+			"result": [{"name": "batman", "id": "1", "weapons": ["belt"], "first-appearance": 1939, "sidekick": null}, {"name": "robin", "id": "2", "weapons": [1], "sidekick": "batman"}]
+		}
+	}`)
+
+	expected := "struct {\n" +
+		"\tHero struct {\n" +
+		"\t\tDetails struct {\n" +
+		"\t\t\tStatus int64 `json:\"status\"`\n" +
+		"\t\t\tSuccess bool `json:\"success\"`\n" +
+		"\t\t} `json:\"details\"`\n" +
+		"\t\tResult []struct {\n" +
+		"\t\t\tFirstAppearance int64 `json:\"first-appearance,omitempty\"`\n" +
+		"\t\t\tID string `json:\"id\"`\n" +
+		"\t\t\tName string `json:\"name\"`\n" +
+		"\t\t\tSidekick *string `json:\"sidekick\"`\n" +
+		"\t\t\tWeapons []interface{} `json:\"weapons\"`\n" +
+		"\t\t} `json:\"result\"`\n" +
+		"\t} `json:\"hero\"`\n" +
+		"}"
+
+	got := web.InferSchema(value).Go()
+
+	test.Equal(t, got, expected)
+}
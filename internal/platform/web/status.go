@@ -0,0 +1,53 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Status policies define how the statement results
+// are combined into the query response status code.
+const (
+	// StatusPolicyHighest responds with the highest status
+	// of the statements that do not ignore errors.
+	StatusPolicyHighest = "highest"
+	// StatusPolicyAlwaysOK responds with 200 OK, leaving the
+	// clients to check the status of each statement.
+	StatusPolicyAlwaysOK = "alwaysOk"
+	// StatusPolicyMultiStatus responds with 207 Multi-Status
+	// when any statement fails and 200 OK otherwise.
+	StatusPolicyMultiStatus = "multiStatus"
+)
+
+var errInvalidStatusPolicy = errors.New("invalid status policy : must be one of highest, alwaysOk or multiStatus")
+
+// ParseStatusPolicy validates the status policy,
+// defaulting to StatusPolicyHighest when empty.
+func ParseStatusPolicy(policy string) (string, error) {
+	switch policy {
+	case "":
+		return StatusPolicyHighest, nil
+	case StatusPolicyHighest, StatusPolicyAlwaysOK, StatusPolicyMultiStatus:
+		return policy, nil
+	default:
+		return "", errInvalidStatusPolicy
+	}
+}
+
+// ApplyStatusPolicy returns the query response status code
+// under the policy, given the one calculated from the
+// statement results by CalculateStatusCode.
+func ApplyStatusPolicy(policy string, statusCode int) int {
+	switch policy {
+	case StatusPolicyAlwaysOK:
+		return http.StatusOK
+	case StatusPolicyMultiStatus:
+		if statusCode >= http.StatusBadRequest {
+			return http.StatusMultiStatus
+		}
+		return http.StatusOK
+	default:
+		return statusCode
+	}
+}
@@ -0,0 +1,47 @@
+package web_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestParseStatusPolicy(t *testing.T) {
+	policy, err := web.ParseStatusPolicy("")
+	test.VerifyError(t, err)
+	test.Equal(t, policy, web.StatusPolicyHighest)
+
+	policy, err = web.ParseStatusPolicy(web.StatusPolicyMultiStatus)
+	test.VerifyError(t, err)
+	test.Equal(t, policy, web.StatusPolicyMultiStatus)
+
+	_, err = web.ParseStatusPolicy("lowest")
+	if err == nil {
+		t.Fatalf("expected error for unknown status policy")
+	}
+}
+
+func TestApplyStatusPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		statusCode int
+		expected   int
+	}{
+		{"highest keeps success", web.StatusPolicyHighest, http.StatusOK, http.StatusOK},
+		{"highest keeps failure", web.StatusPolicyHighest, http.StatusNotFound, http.StatusNotFound},
+		{"always ok on success", web.StatusPolicyAlwaysOK, http.StatusOK, http.StatusOK},
+		{"always ok on failure", web.StatusPolicyAlwaysOK, http.StatusBadGateway, http.StatusOK},
+		{"multi status on success", web.StatusPolicyMultiStatus, http.StatusOK, http.StatusOK},
+		{"multi status on client failure", web.StatusPolicyMultiStatus, http.StatusNotFound, http.StatusMultiStatus},
+		{"multi status on server failure", web.StatusPolicyMultiStatus, http.StatusInternalServerError, http.StatusMultiStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.Equal(t, web.ApplyStatusPolicy(tt.policy, tt.statusCode), tt.expected)
+		})
+	}
+}
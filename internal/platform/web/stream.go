@@ -0,0 +1,193 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web/middleware"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/valyala/fasthttp"
+)
+
+const eventStreamContentType = "text/event-stream"
+
+type streamedStatement struct {
+	ID string `json:"id"`
+	StatementResult
+}
+
+type streamedItem struct {
+	ID    string `json:"id"`
+	Index int    `json:"index"`
+	StatementResult
+}
+
+type streamedEvent struct {
+	ID      string      `json:"id"`
+	Event   string      `json:"event"`
+	EventID string      `json:"eventId,omitempty"`
+	Data    interface{} `json:"data"`
+}
+
+type streamedQuery struct {
+	Status int                        `json:"status"`
+	Result map[string]StatementResult `json:"result"`
+}
+
+// StreamAdHocQuery executes an ad-hoc query, sending the result of
+// each statement as a server-sent event as soon as it is available,
+// or of each item of the unordered multiplexed statements, followed
+// by a final event with the whole query response. The
+// stream is then kept open while the upstreams of the subscribed
+// statements send events, for no longer than the configured duration.
+func (r restQl) StreamAdHocQuery(reqCtx *fasthttp.RequestCtx) error {
+	nativeCtx := middleware.GetNativeContext(reqCtx)
+	log := requestLogger(nativeCtx, r.log)
+
+	tenant, err := makeTenant(reqCtx, r.config.Tenant)
+	if err != nil {
+		log.Error("failed to build query options", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+	options := restql.QueryOptions{Tenant: tenant}
+
+	input, err := makeQueryInput(reqCtx, log)
+	if err != nil {
+		log.Error("failed to build query input", err)
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	nativeCtx, err = withSeed(nativeCtx, input)
+	if err != nil {
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	statusPolicy, err := r.queryStatusPolicy(input)
+	if err != nil {
+		return RespondError(reqCtx, err, errToStatusCode)
+	}
+
+	queryTxt := string(reqCtx.PostBody())
+	debug := r.debugOptions(nativeCtx, input)
+
+	// The stream writer runs after the handler returns, when the request
+	// context is already canceled by the middlewares, hence the query
+	// execution only keeps its values.
+	ctx := detachedContext{parent: nativeCtx}
+
+	reqCtx.Response.Header.SetContentType(eventStreamContentType)
+	reqCtx.Response.Header.Set("Cache-Control", "no-cache")
+	reqCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithCancel(ctx)
+		ctx = restql.WithLogger(ctx, log)
+		if debug.Enabled {
+			ctx = runner.WithTimeline(ctx)
+		}
+
+		stream := &eventStream{w: w, cancel: cancel, log: log}
+
+		onEvent := func(resourceID domain.ResourceID, event domain.UpstreamEvent) {
+			stream.send("upstream", streamedEvent{ID: string(resourceID), Event: event.Event, EventID: event.ID, Data: eventData(event.Data)})
+		}
+		subscriptions := runner.NewSubscriptions(ctx, r.eventSource, r.config.HTTP.Server.Stream.MaxSubscriptionDuration, onEvent)
+		ctx = runner.WithSubscriptions(ctx, subscriptions)
+		defer func() {
+			cancel()
+			subscriptions.Wait()
+		}()
+
+		observer := func(resourceID domain.ResourceID, resource interface{}) {
+			result, err := parseResource(resource, debug)
+			if err != nil {
+				log.Error("failed to parse streamed statement", err, "resource", resourceID)
+				return
+			}
+
+			stream.send("statement", streamedStatement{ID: string(resourceID), StatementResult: result})
+		}
+
+		itemObserver := func(resourceID domain.ResourceID, index int, resource interface{}) {
+			result, err := parseResource(resource, debug)
+			if err != nil {
+				log.Error("failed to parse streamed item", err, "resource", resourceID, "index", index)
+				return
+			}
+
+			stream.send("item", streamedItem{ID: string(resourceID), Index: index, StatementResult: result})
+		}
+
+		result, err := r.evaluator.StreamAdHocQuery(ctx, queryTxt, options, input, observer, itemObserver)
+		if err != nil {
+			log.Error("failed to evaluated streamed adhoc query", err)
+			stream.send("error", ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		response, err := MakeQueryResponse(result, debug)
+		if err != nil {
+			stream.send("error", ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		stream.send("done", streamedQuery{Status: ApplyStatusPolicy(statusPolicy, response.StatusCode), Result: response.Body})
+		subscriptions.Wait()
+	})
+
+	return nil
+}
+
+// eventData keeps the data of upstream events that
+// is valid JSON as is, sending anything else as a string.
+func eventData(data string) interface{} {
+	if json.Valid([]byte(data)) {
+		return json.RawMessage(data)
+	}
+	return data
+}
+
+type eventStream struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	cancel context.CancelFunc
+	log    restql.Logger
+	closed bool
+}
+
+func (es *eventStream) send(event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		es.log.Error("failed to marshal stream event", err, "event", event)
+		return
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if es.closed {
+		return
+	}
+
+	fmt.Fprintf(es.w, "event: %s\ndata: %s\n\n", event, payload)
+	if err := es.w.Flush(); err != nil {
+		es.log.Debug("client disconnected from stream", "event", event)
+		es.closed = true
+		es.cancel()
+	}
+}
+
+// detachedContext keeps the values of its parent
+// but never gets canceled along with it.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (d detachedContext) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (d detachedContext) Done() <-chan struct{}             { return nil }
+func (d detachedContext) Err() error                        { return nil }
+func (d detachedContext) Value(key interface{}) interface{} { return d.parent.Value(key) }
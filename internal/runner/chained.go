@@ -21,19 +21,109 @@ var ErrInvalidChainedParameter = errors.New("chained parameter targeting unknown
 // ResolveChainedValues takes an unresolved Resource collection and replace
 // chain parameter values by data present in the done Resource collection.
 func ResolveChainedValues(resources domain.Resources, doneResources domain.Resources) domain.Resources {
+	return NewChainArena().Resolve(resources, doneResources)
+}
+
+// ChainedValue returns the value targeted by the chain in the
+// done Resource collection, if it is present in a successful result.
+func ChainedValue(chain domain.Chain, doneResources domain.Resources) (interface{}, bool) {
+	for _, item := range chain {
+		if _, ok := item.(string); !ok {
+			return nil, false
+		}
+	}
+
+	arena := NewChainArena()
+	arena.doneResources = doneResources
+
+	v := arena.lookup(chain)
+	if v == nil || v == EmptyChained {
+		return nil, false
+	}
+
+	return v, true
+}
+
+// ChainArena keeps the chained values already extracted from done
+// resources during a query execution, indexed by their path, so
+// that statements and multiplexed items depending on the same path
+// do not walk the source bodies again.
+//
+// Done resources never change once finished, hence the values
+// are valid for the whole execution. It is not safe for concurrent use.
+type ChainArena struct {
+	doneResources domain.Resources
+	values        map[string]interface{}
+	missing       map[string]struct{}
+}
+
+// NewChainArena constructs an empty ChainArena.
+func NewChainArena() *ChainArena {
+	return &ChainArena{values: make(map[string]interface{}), missing: make(map[string]struct{})}
+}
+
+// Resolve takes an unresolved Resource collection and replace chain
+// parameter values by data present in the done Resource collection,
+// reusing the values extracted by previous calls.
+func (a *ChainArena) Resolve(resources domain.Resources, doneResources domain.Resources) domain.Resources {
+	a.doneResources = doneResources
 	for resourceID, stmt := range resources {
-		resources[resourceID] = resolveStatement(stmt, doneResources)
+		resources[resourceID] = resolveStatement(stmt, a)
 	}
 
 	return resources
 }
 
-func resolveStatement(stmt interface{}, doneResources domain.Resources) interface{} {
+func (a *ChainArena) lookup(chain domain.Chain) interface{} {
+	path := toPath(chain)
+	key := strings.Join(path, ".")
+
+	if v, found := a.values[key]; found {
+		if v == nil {
+			a.missing[key] = struct{}{}
+		}
+		return copyLists(v)
+	}
+
+	var v interface{}
+	switch done := a.doneResources[domain.ResourceID(path[0])].(type) {
+	case restql.DoneResources:
+		v = resolveWithMultiplexedRequests(path[1:], done)
+	case restql.DoneResource:
+		v = resolveWithSingleRequest(path[1:], done)
+	default:
+		return nil
+	}
+
+	if v == nil {
+		a.missing[key] = struct{}{}
+	}
+
+	a.values[key] = v
+	return copyLists(v)
+}
+
+// copyLists duplicates the list structure of a value, since
+// later stages, like encoders and ranges, update lists in place.
+func copyLists(value interface{}) interface{} {
+	list, ok := value.([]interface{})
+	if !ok {
+		return value
+	}
+
+	result := make([]interface{}, len(list))
+	for i, v := range list {
+		result[i] = copyLists(v)
+	}
+	return result
+}
+
+func resolveStatement(stmt interface{}, arena *ChainArena) interface{} {
 	switch stmt := stmt.(type) {
 	case domain.Statement:
 		params := stmt.With.Values
 		for paramName, value := range params {
-			v := resolveValue(value, doneResources)
+			v := resolveValue(value, arena)
 			if v == nil {
 				continue
 			}
@@ -42,7 +132,7 @@ func resolveStatement(stmt interface{}, doneResources domain.Resources) interfac
 
 		headers := stmt.Headers
 		for name, value := range headers {
-			resolved := resolveValue(value, doneResources)
+			resolved := resolveValue(value, arena)
 			if resolved == nil {
 				continue
 			}
@@ -55,10 +145,15 @@ func resolveStatement(stmt interface{}, doneResources domain.Resources) interfac
 			headers[name] = headerValue
 		}
 
+		stmt.Timeout = resolveSetting(stmt.Timeout, arena, domain.DynamicTimeout)
+		stmt.CacheControl.MaxAge = resolveSetting(stmt.CacheControl.MaxAge, arena, domain.DynamicMaxAge)
+		stmt.CacheControl.SMaxAge = resolveSetting(stmt.CacheControl.SMaxAge, arena, domain.DynamicMaxAge)
+
+		return stmt
 	case []interface{}:
 		result := make([]interface{}, len(stmt))
 		for i, s := range stmt {
-			result[i] = resolveStatement(s, doneResources)
+			result[i] = resolveStatement(s, arena)
 		}
 		return result
 	}
@@ -66,6 +161,29 @@ func resolveStatement(stmt interface{}, doneResources domain.Resources) interfac
 	return stmt
 }
 
+// statementSettings returns the `timeout`, `max-age`
+// and `s-max-age` values, which can be chained.
+func statementSettings(stmt domain.Statement) []interface{} {
+	return []interface{}{stmt.Timeout, stmt.CacheControl.MaxAge, stmt.CacheControl.SMaxAge}
+}
+
+// resolveSetting returns the `timeout`, `max-age` or `s-max-age`
+// chained value parsed, or nil if it is invalid or unresolved,
+// leaving the statement with its default setting.
+func resolveSetting(value interface{}, arena *ChainArena, parse func(interface{}) (int, bool)) interface{} {
+	chain, ok := value.(domain.Chain)
+	if !ok {
+		return value
+	}
+
+	setting, ok := parse(arena.lookup(chain))
+	if !ok {
+		return nil
+	}
+
+	return setting
+}
+
 func stringify(value interface{}) (string, error) {
 	switch value := value.(type) {
 	case string:
@@ -81,28 +199,51 @@ func stringify(value interface{}) (string, error) {
 	}
 }
 
-func resolveValue(value interface{}, doneResources domain.Resources) interface{} {
+func resolveValue(value interface{}, arena *ChainArena) interface{} {
 	switch param := value.(type) {
 	case domain.Chain:
-		return resolveChainParam(param, doneResources)
+		return arena.lookup(param)
+	case domain.Range:
+		return domain.Range{
+			Start: resolveValue(param.Start, arena),
+			End:   resolveValue(param.End, arena),
+			Step:  resolveValue(param.Step, arena),
+		}
+	case domain.DefaultValue:
+		return resolveDefaultValue(param, arena)
 	case domain.Function:
 		return param.Map(func(target interface{}) interface{} {
-			return resolveValue(target, doneResources)
+			return resolveValue(target, arena)
 		})
 	case []interface{}:
-		return resolveListParam(param, doneResources)
+		return resolveListParam(param, arena)
 	case map[string]interface{}:
-		return resolveObjectParam(param, doneResources)
+		return resolveObjectParam(param, arena)
 	default:
 		return value
 	}
 }
 
-func resolveObjectParam(objectParam map[string]interface{}, doneResources domain.Resources) interface{} {
+// resolveDefaultValue returns the default when the chained value
+// is missing, which is then not reported as missing by strict queries.
+func resolveDefaultValue(dv domain.DefaultValue, arena *ChainArena) interface{} {
+	v := resolveValue(dv.Value, arena)
+	if v != nil && !isEmptyChained(v) {
+		return v
+	}
+
+	if chain, ok := dv.Value.(domain.Chain); ok {
+		delete(arena.missing, strings.Join(toPath(chain), "."))
+	}
+
+	return dv.Default
+}
+
+func resolveObjectParam(objectParam map[string]interface{}, arena *ChainArena) interface{} {
 	result := make(map[string]interface{})
 
 	for key, value := range objectParam {
-		v := resolveValue(value, doneResources)
+		v := resolveValue(value, arena)
 		if v == nil {
 			continue
 		}
@@ -166,31 +307,17 @@ func minimumListValueLength(m map[string]interface{}) int {
 	return int(result)
 }
 
-func resolveListParam(listParam []interface{}, doneResources domain.Resources) []interface{} {
+func resolveListParam(listParam []interface{}, arena *ChainArena) []interface{} {
 	result := make([]interface{}, len(listParam))
 	copy(result, listParam)
 
 	for i, value := range result {
-		result[i] = resolveValue(value, doneResources)
+		result[i] = resolveValue(value, arena)
 	}
 
 	return result
 }
 
-func resolveChainParam(chain domain.Chain, doneResources domain.Resources) interface{} {
-	path := toPath(chain)
-	resourceID := domain.ResourceID(path[0])
-
-	switch done := doneResources[resourceID].(type) {
-	case restql.DoneResources:
-		return resolveWithMultiplexedRequests(path[1:], done)
-	case restql.DoneResource:
-		return resolveWithSingleRequest(path[1:], done)
-	default:
-		return nil
-	}
-}
-
 func resolveWithMultiplexedRequests(path []string, doneRequests restql.DoneResources) []interface{} {
 	var result []interface{}
 
@@ -242,6 +369,10 @@ func getValueFromBody(pathToValue []string, b restql.Body) (interface{}, bool) {
 		return b, true
 	}
 
+	if domain.IsListSelector(pathToValue[0]) {
+		return getValueFromListSelection(pathToValue, b)
+	}
+
 	switch body := b.(type) {
 	case map[string]interface{}:
 		v, found := body[pathToValue[0]]
@@ -261,6 +392,46 @@ func getValueFromBody(pathToValue []string, b restql.Body) (interface{}, bool) {
 	}
 }
 
+// getValueFromListSelection resolves the path over the elements of
+// the list selected by its first segment, which is either an index,
+// a slice or a predicate, like `[0]`, `[1:3]` or `[?(@.active==true)]`.
+func getValueFromListSelection(pathToValue []string, b restql.Body) (interface{}, bool) {
+	list, ok := b.([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	segment := pathToValue[0]
+	if predicate, ok := domain.ParseElementPredicate(segment); ok {
+		var selected []interface{}
+		for _, v := range list {
+			if predicate.Matches(v) {
+				selected = append(selected, v)
+			}
+		}
+		return getValueFromBody(pathToValue[1:], selected)
+	}
+
+	selector, ok := domain.ParseListSelector(segment)
+	if !ok {
+		return nil, false
+	}
+
+	indexes := selector.Indexes(len(list))
+	if selector.Index {
+		if len(indexes) == 0 {
+			return nil, false
+		}
+		return getValueFromBody(pathToValue[1:], list[indexes[0]])
+	}
+
+	selected := make([]interface{}, len(indexes))
+	for i, index := range indexes {
+		selected[i] = list[index]
+	}
+	return getValueFromBody(pathToValue[1:], selected)
+}
+
 func getValueFromHeader(name string, headers map[string]string) (string, bool) {
 	name = strings.ToLower(name)
 	for k, v := range headers {
@@ -313,6 +484,8 @@ func validateParam(value interface{}, resources domain.Resources) error {
 	switch param := value.(type) {
 	case domain.Chain:
 		return validateChainParam(param, resources)
+	case domain.Range:
+		return validateListParam([]interface{}{param.Start, param.End, param.Step}, resources)
 	case domain.Function:
 		return validateParam(param.Target(), resources)
 	case []interface{}:
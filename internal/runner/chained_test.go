@@ -41,6 +41,25 @@ func TestResolveChainedValues(t *testing.T) {
 			domain.Resources{"resource-name": domain.Statement{Resource: "resource-name", With: domain.Params{Values: map[string]interface{}{"id": domain.Chain{"done-resource", "id"}}}}},
 			domain.Resources{"done-resource": restql.DoneResources{restql.DoneResource{Status: 404, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal("{}"))}, restql.DoneResource{Status: 200, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"id": "abcdef"}`))}}},
 		},
+		{
+			"Returns a statement with default value of missing chained value",
+			domain.Resources{"resource-name": domain.Statement{Resource: "resource-name", With: domain.Params{Values: map[string]interface{}{"id": "abcdef", "page": 1, "team": "none"}}}},
+			domain.Resources{"resource-name": domain.Statement{Resource: "resource-name", With: domain.Params{Values: map[string]interface{}{
+				"id":   domain.DefaultValue{Value: domain.Chain{"done-resource", "id"}, Default: "none"},
+				"page": domain.DefaultValue{Value: domain.Chain{"done-resource", "page"}, Default: 1},
+				"team": domain.DefaultValue{Value: domain.Chain{"failed-resource", "team"}, Default: "none"},
+			}}}},
+			domain.Resources{
+				"done-resource":   restql.DoneResource{Status: 200, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"id": "abcdef"}`))},
+				"failed-resource": restql.DoneResource{Status: 500, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"team": "jla"}`))},
+			},
+		},
+		{
+			"Returns a statement with range chained arguments resolved",
+			domain.Resources{"resource-name": domain.Statement{Resource: "resource-name", With: domain.Params{Values: map[string]interface{}{"page": domain.Range{Start: 1, End: float64(3), Step: 1}}}}},
+			domain.Resources{"resource-name": domain.Statement{Resource: "resource-name", With: domain.Params{Values: map[string]interface{}{"page": domain.Range{Start: 1, End: domain.Chain{"done-resource", "pages"}, Step: 1}}}}},
+			domain.Resources{"done-resource": restql.DoneResource{Status: 200, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"pages": 3}`))}},
+		},
 		{
 			"Returns a statement with single done resource value",
 			domain.Resources{"resource-name": domain.Statement{Resource: "resource-name", With: domain.Params{Values: map[string]interface{}{"id": "abcdef"}}}},
@@ -250,6 +269,26 @@ func TestResolveChainedValues(t *testing.T) {
 			domain.Resources{"resource-name": domain.Statement{Resource: "resource-name", Headers: map[string]interface{}{"x-id": domain.Chain{"done-resource", "tokens"}}}},
 			domain.Resources{"done-resource": restql.DoneResource{Status: 200, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"tokens": ["abcdef","ghijkl"]}`))}},
 		},
+		{
+			"Returns a statement with chained values selected from list",
+			domain.Resources{"resource-name": domain.Statement{Resource: "resource-name", With: domain.Params{Values: map[string]interface{}{"ids": []interface{}{"1", "3"}, "first": "1", "last": []interface{}{"2", "3"}, "cheap": []interface{}{"2"}}}}},
+			domain.Resources{"resource-name": domain.Statement{Resource: "resource-name", With: domain.Params{Values: map[string]interface{}{
+				"ids":   domain.Chain{"done-resource", "items", "[?(@.active==true)]", "id"},
+				"first": domain.Chain{"done-resource", "items", "[0]", "id"},
+				"last":  domain.Chain{"done-resource", "items", "[-2:]", "id"},
+				"cheap": domain.Chain{"done-resource", "items", "[?(@.price.value < 10)]", "id"},
+			}}}},
+			domain.Resources{"done-resource": restql.DoneResource{Status: 200, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"items": [{"id": "1", "active": true, "price": {"value": 15}}, {"id": "2", "active": false, "price": {"value": 5}}, {"id": "3", "active": true}]}`))}},
+		},
+		{
+			"Returns a statement with chained timeout and cache control resolved",
+			domain.Resources{"resource-name": domain.Statement{Resource: "resource-name", Timeout: 500, CacheControl: domain.CacheControl{MaxAge: domain.MaxDynamicMaxAge}}},
+			domain.Resources{"resource-name": domain.Statement{Resource: "resource-name", Timeout: domain.Chain{"done-resource", "timeout"}, CacheControl: domain.CacheControl{
+				MaxAge:  domain.Chain{"done-resource", "maxAge"},
+				SMaxAge: domain.Chain{"done-resource", "sMaxAge"},
+			}}},
+			domain.Resources{"done-resource": restql.DoneResource{Status: 200, ResponseBody: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"timeout": 500, "maxAge": 99999999, "sMaxAge": "invalid"}`))}},
+		},
 		{
 			"Returns a statement with object param with resolved list values exploded",
 			domain.Resources{"resource-name": domain.Statement{Resource: "resource-name", With: domain.Params{Values: map[string]interface{}{"info": domain.NoMultiplex{Value: []interface{}{map[string]interface{}{"weapon": "batarang"}, map[string]interface{}{"weapon": "batbelt"}}}}}}},
@@ -266,6 +305,29 @@ func TestResolveChainedValues(t *testing.T) {
 	}
 }
 
+func TestChainArena(t *testing.T) {
+	body := restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"villains": [{"id": "1"}, {"id": "2"}]}`))
+	doneResources := domain.Resources{"hero": restql.DoneResource{Status: 200, ResponseBody: body}}
+
+	statement := func(resource string) domain.Statement {
+		return domain.Statement{Resource: resource, With: domain.Params{Values: map[string]interface{}{"id": domain.Chain{"hero", "villains", "id"}}}}
+	}
+	expected := func(resource string) domain.Statement {
+		return domain.Statement{Resource: resource, With: domain.Params{Values: map[string]interface{}{"id": []interface{}{"1", "2"}}}}
+	}
+
+	arena := runner.NewChainArena()
+
+	got := arena.Resolve(domain.Resources{"villain": statement("villain")}, doneResources)
+	test.Equal(t, got, domain.Resources{"villain": expected("villain")})
+
+	got["villain"].(domain.Statement).With.Values["id"].([]interface{})[0] = "changed"
+	body.SetValue(test.Unmarshal(`{"villains": []}`))
+
+	got = arena.Resolve(domain.Resources{"weapon": statement("weapon")}, doneResources)
+	test.Equal(t, got, domain.Resources{"weapon": expected("weapon")})
+}
+
 func TestValidateChainedValues(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+var conditionalHeaders = []string{
+	"if-none-match",
+	"if-modified-since",
+}
+
+var validatorHeaders = []string{
+	"etag",
+	"last-modified",
+}
+
+func isConditionalHeader(header string) bool {
+	return containsHeader(conditionalHeaders, header)
+}
+
+func containsHeader(headers []string, header string) bool {
+	for _, h := range headers {
+		if strings.EqualFold(header, h) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasValidator(headers restql.Headers) bool {
+	for key := range headers {
+		if containsHeader(validatorHeaders, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func removeConditionalHeaders(request restql.HTTPRequest) restql.HTTPRequest {
+	headers := make(restql.Headers, len(request.Headers))
+	for key, value := range request.Headers {
+		if !isConditionalHeader(key) {
+			headers[key] = value
+		}
+	}
+
+	request.Headers = headers
+	return request
+}
+
+// mergeNotModified updates the cached response with the
+// headers and timing of the 304 Not Modified response.
+func mergeNotModified(cached restql.HTTPResponse, notModified restql.HTTPResponse) restql.HTTPResponse {
+	headers := make(restql.Headers, len(cached.Headers)+len(notModified.Headers))
+	for key, value := range cached.Headers {
+		headers[key] = value
+	}
+	for key, value := range notModified.Headers {
+		if strings.EqualFold(key, "content-length") {
+			continue
+		}
+		headers[key] = value
+	}
+
+	cached.Headers = headers
+	cached.Duration = notModified.Duration
+	cached.Timings = notModified.Timings
+	return cached
+}
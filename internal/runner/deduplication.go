@@ -0,0 +1,143 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"golang.org/x/sync/singleflight"
+)
+
+// RequestDeduplication shares the upstream responses between the
+// queries executed with it, so identical GET requests made by them
+// while one is in flight reach the upstream only once.
+type RequestDeduplication struct {
+	ctx      context.Context
+	group    singleflight.Group
+	requests int64
+	shared   int64
+}
+
+// NewRequestDeduplication constructs an empty RequestDeduplication,
+// whose shared requests are cancelled along with the given context,
+// instead of the one of the query that made them.
+func NewRequestDeduplication(ctx context.Context) *RequestDeduplication {
+	return &RequestDeduplication{ctx: ctx}
+}
+
+type deduplicationKey struct{}
+
+// WithRequestDeduplication returns a context that makes the Executor
+// share the responses of identical requests through the deduplication.
+func WithRequestDeduplication(ctx context.Context, d *RequestDeduplication) context.Context {
+	return context.WithValue(ctx, deduplicationKey{}, d)
+}
+
+func getRequestDeduplication(ctx context.Context) *RequestDeduplication {
+	d, _ := ctx.Value(deduplicationKey{}).(*RequestDeduplication)
+	return d
+}
+
+// Requests returns how many requests were made through the
+// deduplication, including the ones that shared a response.
+func (d *RequestDeduplication) Requests() int {
+	return int(atomic.LoadInt64(&d.requests))
+}
+
+// Shared returns how many requests were not made,
+// using the response of an identical one instead.
+func (d *RequestDeduplication) Shared() int {
+	return int(atomic.LoadInt64(&d.shared))
+}
+
+type dedupedResponse struct {
+	response restql.HTTPResponse
+	err      error
+}
+
+// do makes the request through fn unless an identical one is in
+// flight, returning a copy of the response for each caller, as the
+// statement results manipulate their body. Other methods than GET
+// are always made, as they are not expected to be idempotent.
+//
+// The shared request runs with the values of the context of the
+// caller that made it, but is only cancelled with the context of
+// the deduplication, so a query timing out does not fail the
+// others waiting on it, which each stop waiting with their own.
+func (d *RequestDeduplication) do(ctx context.Context, request restql.HTTPRequest, fn func(ctx context.Context) (restql.HTTPResponse, error)) (restql.HTTPResponse, error) {
+	atomic.AddInt64(&d.requests, 1)
+	if request.Method != http.MethodGet {
+		return fn(ctx)
+	}
+
+	made := false
+	ch := d.group.DoChan(statementCacheKey(request), func() (interface{}, error) {
+		made = true
+		response, err := fn(sharedContext{Context: ctx, cancellation: d.ctx})
+		return dedupedResponse{response: response, err: err}, nil
+	})
+
+	select {
+	case r := <-ch:
+		if !made {
+			atomic.AddInt64(&d.shared, 1)
+		}
+
+		result := r.Val.(dedupedResponse)
+		return copyResponse(restql.GetLogger(ctx), result.response), result.err
+	case <-ctx.Done():
+		return restql.HTTPResponse{}, ctx.Err()
+	}
+}
+
+// sharedContext holds the values of the embedded context,
+// taking its deadline and cancellation from another one.
+type sharedContext struct {
+	context.Context
+	cancellation context.Context
+}
+
+func (c sharedContext) Deadline() (time.Time, bool) { return c.cancellation.Deadline() }
+func (c sharedContext) Done() <-chan struct{}       { return c.cancellation.Done() }
+func (c sharedContext) Err() error                  { return c.cancellation.Err() }
+
+func copyResponse(log restql.Logger, response restql.HTTPResponse) restql.HTTPResponse {
+	if response.Headers != nil {
+		headers := make(restql.Headers, len(response.Headers))
+		for k, v := range response.Headers {
+			headers[k] = v
+		}
+		response.Headers = headers
+	}
+
+	if body := response.Body; body != nil {
+		if value := body.Value(); value != nil {
+			response.Body = restql.NewResponseBodyFromValue(log, copyBodyValue(value))
+		} else {
+			response.Body = restql.NewResponseBodyFromBytes(log, body.Bytes())
+		}
+	}
+
+	return response
+}
+
+func copyBodyValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			m[k] = copyBodyValue(v)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(value))
+		for i, v := range value {
+			l[i] = copyBodyValue(v)
+		}
+		return l
+	default:
+		return value
+	}
+}
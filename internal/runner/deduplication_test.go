@@ -0,0 +1,131 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+type heldClient struct {
+	release chan struct{}
+
+	mu       sync.Mutex
+	requests int
+}
+
+func (c *heldClient) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	c.mu.Lock()
+	c.requests++
+	c.mu.Unlock()
+
+	select {
+	case <-c.release:
+	case <-ctx.Done():
+		return restql.HTTPResponse{}, ctx.Err()
+	}
+
+	return restql.HTTPResponse{
+		URL:        "http://hero.io/api",
+		StatusCode: http.StatusOK,
+		Headers:    restql.Headers{"Content-Type": "application/json"},
+		Body:       restql.NewResponseBodyFromBytes(test.NoOpLogger, []byte(`{"name":"batman"}`)),
+	}, nil
+}
+
+func TestRequestDeduplication(t *testing.T) {
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+		Options:  restql.QueryOptions{Tenant: "DEFAULT"},
+	}
+
+	tests := []struct {
+		name             string
+		method           string
+		expectedRequests int
+		expectedShared   int
+	}{
+		{"should share the response of identical in-flight requests", domain.FromMethod, 1, 2},
+		{"should not share the response of non idempotent requests", domain.ToMethod, 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &heldClient{release: make(chan struct{})}
+			executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, 0, "", nil)
+			dedup := runner.NewRequestDeduplication(context.Background())
+			ctx := runner.WithRequestDeduplication(restql.WithLogger(context.Background(), test.NoOpLogger), dedup)
+			statement := domain.Statement{Method: tt.method, Resource: "hero", With: domain.Params{Values: map[string]interface{}{"id": 1}}}
+
+			results := make([]restql.DoneResource, 3)
+			var wg sync.WaitGroup
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i] = executor.DoStatement(ctx, statement, queryCtx)
+				}(i)
+			}
+
+			for dedup.Requests() < len(results) {
+				time.Sleep(time.Millisecond)
+			}
+			time.Sleep(10 * time.Millisecond)
+			close(client.release)
+			wg.Wait()
+
+			test.Equal(t, client.requests, tt.expectedRequests)
+			test.Equal(t, dedup.Shared(), tt.expectedShared)
+
+			results[0].ResponseBody.Unmarshal().(map[string]interface{})["name"] = "robin"
+			for _, r := range results[1:] {
+				test.Equal(t, r.Status, http.StatusOK)
+				test.Equal(t, r.ResponseBody.Unmarshal(), map[string]interface{}{"name": "batman"})
+			}
+		})
+	}
+}
+
+func TestRequestDeduplicationCancellation(t *testing.T) {
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+		Options:  restql.QueryOptions{Tenant: "DEFAULT"},
+	}
+
+	client := &heldClient{release: make(chan struct{})}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, 0, "", nil)
+	dedup := runner.NewRequestDeduplication(context.Background())
+	ctx := runner.WithRequestDeduplication(restql.WithLogger(context.Background(), test.NoOpLogger), dedup)
+	statement := domain.Statement{Method: domain.FromMethod, Resource: "hero", With: domain.Params{Values: map[string]interface{}{"id": 1}}}
+
+	firstCtx, cancel := context.WithCancel(ctx)
+	first := make(chan restql.DoneResource)
+	go func() { first <- executor.DoStatement(firstCtx, statement, queryCtx) }()
+
+	for dedup.Requests() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	second := make(chan restql.DoneResource)
+	go func() { second <- executor.DoStatement(ctx, statement, queryCtx) }()
+
+	for dedup.Requests() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	<-first
+	close(client.release)
+	result := <-second
+
+	test.Equal(t, client.requests, 1)
+	test.Equal(t, result.Status, http.StatusOK)
+	test.Equal(t, result.ResponseBody.Unmarshal(), map[string]interface{}{"name": "batman"})
+}
@@ -0,0 +1,516 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+)
+
+// Levels of the defaults cascade, from the least to the most specific.
+const (
+	GlobalLevel    = "global"
+	TenantLevel    = "tenant"
+	MappingLevel   = "mapping"
+	NamespaceLevel = "namespace"
+	QueryLevel     = "query"
+	StatementLevel = "statement"
+)
+
+// Defaults represents the fallback values for the statement
+// execution settings defined at a level of the cascade.
+// Unset values are deferred to the previous level.
+type Defaults struct {
+	Timeout time.Duration
+	Retries *int
+	MaxAge  *int
+	SMaxAge *int
+	Headers map[string]string
+	Params  map[string]string
+
+	ForwardConditionalHeaders *bool
+	ForwardHeaders            *domain.HeaderForwarding
+	SessionCookies            *bool
+
+	MaxResponseSize        int
+	MaxMultiplexedRequests int
+
+	FailoverURLs        []string
+	FailoverStatusCodes []int
+
+	// Strict and MaxQueryMemory are only honored
+	// at the tenant and global levels.
+	Strict         *bool
+	MaxQueryMemory int
+
+	// Namespaces is only honored at the tenant level.
+	Namespaces []string
+
+	// IgnoreErrors is only honored at the namespace level,
+	// since a statement can not opt out of ignoring errors.
+	IgnoreErrors *bool
+
+	// Proxy routes the requests through an egress proxy,
+	// where a direct one disables the inherited proxy.
+	Proxy *domain.OutboundProxy
+
+	// Normalize, Mock, ResponseSchema, Signing, Policy, Faults
+	// and TLS are only honored at the mapping level.
+	Normalize      *domain.Normalization
+	Mock           *domain.Mock
+	ResponseSchema *domain.ResponseSchema
+	Signing        *domain.RequestSigning
+	Policy         *domain.ResourcePolicy
+	Faults         *domain.Faults
+	TLS            *domain.UpstreamTLS
+}
+
+// TenantDefaults represents the defaults defined for a tenant,
+// optionally overridden for each of its mappings.
+type TenantDefaults struct {
+	Defaults
+	Mappings map[string]Defaults
+}
+
+// DefaultsCascade resolves the execution settings of a statement in
+// the order: global → tenant → mapping → namespace → query → statement,
+// where the most specific level defining a value wins. The namespace
+// level only applies to the saved queries of the namespace.
+type DefaultsCascade struct {
+	Global     Defaults
+	Tenants    map[string]TenantDefaults
+	Mappings   map[string]Defaults
+	Namespaces map[string]Defaults
+}
+
+// StatementPlan is the outcome of resolving the defaults
+// cascade for a statement, along with the level that
+// provided each value.
+type StatementPlan struct {
+	Resource     string            `json:"resource"`
+	Method       string            `json:"method"`
+	Timeout      string            `json:"timeout"`
+	Retries      int               `json:"retries"`
+	MaxAge       interface{}       `json:"maxAge,omitempty"`
+	SMaxAge      interface{}       `json:"sMaxAge,omitempty"`
+	Cache        int               `json:"cache,omitempty"`
+	NoCache      bool              `json:"noCache,omitempty"`
+	SLO          int               `json:"slo,omitempty"`
+	IgnoreErrors bool              `json:"ignoreErrors,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Params       map[string]string `json:"params,omitempty"`
+	Sources      map[string]string `json:"sources"`
+
+	ForwardConditionalHeaders bool                     `json:"forwardConditionalHeaders"`
+	ForwardHeaders            *domain.HeaderForwarding `json:"forwardHeaders,omitempty"`
+	SessionCookies            bool                     `json:"sessionCookies,omitempty"`
+
+	MaxResponseSize        int `json:"maxResponseSize,omitempty"`
+	MaxMultiplexedRequests int `json:"maxMultiplexedRequests,omitempty"`
+
+	FailoverURLs        []string `json:"failoverUrls,omitempty"`
+	FailoverStatusCodes []int    `json:"failoverStatusCodes,omitempty"`
+
+	Normalize      *domain.Normalization  `json:"normalize,omitempty"`
+	Mocked         bool                   `json:"mocked,omitempty"`
+	Subscribed     bool                   `json:"subscribed,omitempty"`
+	ResponseSchema string                 `json:"responseSchema,omitempty"`
+	Signing        string                 `json:"signing,omitempty"`
+	Policy         *domain.ResourcePolicy `json:"policy,omitempty"`
+	Proxy          string                 `json:"proxy,omitempty"`
+	TLS            string                 `json:"tls,omitempty"`
+
+	Stats *ResourceStats `json:"stats,omitempty"`
+}
+
+// ApplyDefaults transforms an unresolved Resources collection by
+// resolving the defaults cascade into each statement.
+func ApplyDefaults(resources domain.Resources, modifiers domain.Modifiers, tenant string, namespace string, cascade DefaultsCascade) domain.Resources {
+	for resourceID, stmt := range resources {
+		if stmt, ok := stmt.(domain.Statement); ok {
+			resources[resourceID], _ = cascade.Resolve(tenant, namespace, modifiers, stmt)
+		}
+	}
+
+	return resources
+}
+
+// Resolve applies the defaults cascade to the statement of a query
+// of the namespace, empty for ad-hoc queries, returning it with every
+// execution setting filled and the resulting plan.
+func (dc DefaultsCascade) Resolve(tenant string, namespace string, modifiers domain.Modifiers, statement domain.Statement) (domain.Statement, StatementPlan) {
+	plan := StatementPlan{
+		Resource: statement.Resource,
+		Method:   statement.Method,
+		Sources:  make(map[string]string),
+	}
+
+	if statement.Timeout != nil {
+		plan.Sources["timeout"] = StatementLevel
+	}
+	if statement.CacheControl.MaxAge != nil {
+		plan.Sources["maxAge"] = StatementLevel
+	}
+	if statement.CacheControl.SMaxAge != nil {
+		plan.Sources["sMaxAge"] = StatementLevel
+	}
+	if statement.IgnoreErrors {
+		plan.Sources["ignoreErrors"] = StatementLevel
+	}
+
+	headers := make(map[string]interface{}, len(statement.Headers))
+	for key, value := range statement.Headers {
+		key = http.CanonicalHeaderKey(key)
+		headers[key] = value
+		plan.Sources["headers."+key] = StatementLevel
+	}
+
+	cc := applyCacheModifiers(modifiers, statement)
+	if statement.CacheControl.MaxAge == nil && cc.MaxAge != nil {
+		plan.Sources["maxAge"] = QueryLevel
+	}
+	if statement.CacheControl.SMaxAge == nil && cc.SMaxAge != nil {
+		plan.Sources["sMaxAge"] = QueryLevel
+	}
+	statement.CacheControl = cc
+
+	var retries *int
+	if r, ok := modifiers["retries"].(int); ok {
+		retries = &r
+		plan.Sources["retries"] = QueryLevel
+	}
+
+	statement.Subscribed = isSubscribeSelected(modifiers, statement)
+
+	if statement.Cache > 0 {
+		plan.Sources["cache"] = StatementLevel
+	} else if c, ok := modifiers[cacheModifier].(int); ok && c > 0 && !statement.NoCache {
+		statement.Cache = c
+		plan.Sources["cache"] = QueryLevel
+	}
+
+	params := make(map[string]string)
+
+	var forwardConditional, sessionCookies *bool
+	var proxy *domain.OutboundProxy
+	for _, l := range dc.levels(tenant, namespace, statement.Resource) {
+		d := l.defaults
+
+		if !statement.IgnoreErrors && d.IgnoreErrors != nil && *d.IgnoreErrors && l.name == NamespaceLevel {
+			statement.IgnoreErrors = true
+			plan.Sources["ignoreErrors"] = l.name
+		}
+
+		if forwardConditional == nil && d.ForwardConditionalHeaders != nil {
+			forwardConditional = d.ForwardConditionalHeaders
+			plan.Sources["forwardConditionalHeaders"] = l.name
+		}
+
+		if sessionCookies == nil && d.SessionCookies != nil {
+			sessionCookies = d.SessionCookies
+			plan.Sources["sessionCookies"] = l.name
+		}
+
+		if statement.ForwardHeaders == nil && d.ForwardHeaders != nil {
+			statement.ForwardHeaders = d.ForwardHeaders
+			plan.Sources["forwardHeaders"] = l.name
+		}
+
+		if statement.FailoverURLs == nil && d.FailoverURLs != nil {
+			statement.FailoverURLs = d.FailoverURLs
+			plan.Sources["failoverUrls"] = l.name
+		}
+
+		if statement.FailoverStatusCodes == nil && d.FailoverStatusCodes != nil {
+			statement.FailoverStatusCodes = d.FailoverStatusCodes
+			plan.Sources["failoverStatusCodes"] = l.name
+		}
+
+		if statement.Normalize == nil && d.Normalize != nil && l.name == MappingLevel {
+			statement.Normalize = d.Normalize
+			plan.Sources["normalize"] = l.name
+		}
+
+		if statement.Mock == nil && d.Mock != nil && l.name == MappingLevel {
+			statement.Mock = d.Mock
+			statement.Mocked = isMockSelected(modifiers, statement.Resource)
+			plan.Sources["mock"] = l.name
+		}
+
+		if statement.ResponseSchema == nil && d.ResponseSchema != nil && l.name == MappingLevel {
+			statement.ResponseSchema = d.ResponseSchema
+			plan.Sources["responseSchema"] = l.name
+		}
+
+		if statement.Signing == nil && d.Signing != nil && l.name == MappingLevel {
+			statement.Signing = d.Signing
+			plan.Sources["signing"] = l.name
+		}
+
+		if statement.Policy == nil && d.Policy != nil && l.name == MappingLevel {
+			statement.Policy = d.Policy
+			plan.Sources["policy"] = l.name
+		}
+
+		if statement.Faults == nil && d.Faults != nil && l.name == MappingLevel {
+			statement.Faults = d.Faults
+			plan.Sources["faults"] = l.name
+		}
+
+		if statement.TLS == nil && d.TLS != nil && l.name == MappingLevel {
+			statement.TLS = d.TLS
+			plan.Sources["tls"] = l.name
+		}
+
+		if proxy == nil && d.Proxy != nil {
+			proxy = d.Proxy
+			plan.Sources["proxy"] = l.name
+		}
+
+		if statement.MaxResponseSize == 0 && d.MaxResponseSize > 0 {
+			statement.MaxResponseSize = d.MaxResponseSize
+			plan.Sources["maxResponseSize"] = l.name
+		}
+
+		if statement.MaxMultiplexedRequests == 0 && d.MaxMultiplexedRequests > 0 {
+			statement.MaxMultiplexedRequests = d.MaxMultiplexedRequests
+			plan.Sources["maxMultiplexedRequests"] = l.name
+		}
+
+		if statement.Timeout == nil && d.Timeout > 0 {
+			statement.Timeout = int(d.Timeout / time.Millisecond)
+			plan.Sources["timeout"] = l.name
+		}
+
+		if retries == nil && d.Retries != nil {
+			retries = d.Retries
+			plan.Sources["retries"] = l.name
+		}
+
+		if statement.CacheControl.MaxAge == nil && d.MaxAge != nil {
+			statement.CacheControl.MaxAge = *d.MaxAge
+			plan.Sources["maxAge"] = l.name
+		}
+
+		if statement.CacheControl.SMaxAge == nil && d.SMaxAge != nil {
+			statement.CacheControl.SMaxAge = *d.SMaxAge
+			plan.Sources["sMaxAge"] = l.name
+		}
+
+		for key, value := range d.Headers {
+			key = http.CanonicalHeaderKey(key)
+			if _, found := headers[key]; found {
+				continue
+			}
+
+			headers[key] = value
+			plan.Sources["headers."+key] = l.name
+		}
+
+		for key, value := range d.Params {
+			if _, found := params[key]; found {
+				continue
+			}
+
+			params[key] = value
+			plan.Sources["params."+key] = l.name
+		}
+	}
+
+	if retries != nil && *retries > 0 {
+		statement.Retries = *retries
+	}
+
+	if len(headers) > 0 {
+		statement.Headers = headers
+	}
+
+	if len(params) > 0 {
+		statement.DefaultParams = params
+		plan.Params = params
+	}
+
+	if forwardConditional != nil {
+		statement.ForwardConditionalHeaders = *forwardConditional
+	}
+
+	if sessionCookies != nil {
+		statement.SessionCookies = *sessionCookies
+	}
+
+	plan.Timeout = parseTimeout(0, statement).String()
+	plan.Retries = statement.Retries
+	plan.ForwardConditionalHeaders = statement.ForwardConditionalHeaders
+	plan.ForwardHeaders = statement.ForwardHeaders
+	plan.SessionCookies = statement.SessionCookies
+	plan.MaxResponseSize = statement.MaxResponseSize
+	plan.MaxMultiplexedRequests = statement.MaxMultiplexedRequests
+	plan.FailoverURLs = statement.FailoverURLs
+	plan.FailoverStatusCodes = statement.FailoverStatusCodes
+	plan.Normalize = statement.Normalize
+	plan.Mocked = statement.Mocked
+	plan.Subscribed = statement.Subscribed
+	if statement.ResponseSchema != nil {
+		plan.ResponseSchema = statement.ResponseSchema.Mode
+	}
+	if statement.Signing != nil {
+		plan.Signing = statement.Signing.Scheme
+	}
+	plan.Policy = statement.Policy
+	if statement.TLS != nil {
+		plan.TLS = statement.TLS.String()
+	}
+	if proxy != nil && !proxy.Direct() {
+		statement.Proxy = proxy
+		plan.Proxy = proxy.String()
+	}
+	plan.MaxAge = statement.CacheControl.MaxAge
+	plan.SMaxAge = statement.CacheControl.SMaxAge
+	if _, cached := statementCacheTTL(statement); cached {
+		plan.Cache = statement.Cache
+	}
+	plan.NoCache = statement.NoCache
+	plan.IgnoreErrors = statement.IgnoreErrors
+	if statement.SLO > 0 {
+		plan.SLO = statement.SLO
+		plan.Sources["slo"] = StatementLevel
+	}
+	plan.Headers = make(map[string]string, len(headers))
+	for key, value := range headers {
+		plan.Headers[key] = describeValue(value)
+	}
+
+	return statement, plan
+}
+
+func applyCacheModifiers(modifiers domain.Modifiers, statement domain.Statement) domain.CacheControl {
+	cc := statement.CacheControl
+
+	cacheControl, found := modifiers["cache-control"]
+	if cc.MaxAge == nil && found {
+		cc.MaxAge = cacheControl
+	}
+
+	maxAge, found := modifiers["max-age"]
+	if cc.MaxAge == nil && found {
+		cc.MaxAge = maxAge
+	}
+
+	smaxAge, found := modifiers["s-max-age"]
+	if cc.SMaxAge == nil && found {
+		cc.SMaxAge = smaxAge
+	}
+
+	return cc
+}
+
+// describeValue renders a statement value for the plan, where values
+// only known once the query runs are shown as they were written,
+// like `$name` for variables and `hero.id` for chained values.
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case domain.Variable:
+		return "$" + v.Target
+	case domain.Chain:
+		parts := make([]string, len(v))
+		for i, p := range v {
+			parts[i] = describeValue(p)
+		}
+		return strings.Join(parts, ".")
+	case domain.Function:
+		return describeValue(v.Target())
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// HasMock returns true if a mapping level of the
+// tenant declares a mock for the resource.
+func (dc DefaultsCascade) HasMock(tenant string, resource string) bool {
+	for _, l := range dc.levels(tenant, "", resource) {
+		if l.name == MappingLevel && l.defaults.Mock != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Strict returns true if the query runs in strict mode, which is
+// chosen by the `use strict` modifier or, without it, by the tenant
+// and global defaults.
+func (dc DefaultsCascade) Strict(tenant string, modifiers domain.Modifiers) bool {
+	if strict, ok := modifiers[strictModifier].(bool); ok {
+		return strict
+	}
+
+	if td, found := dc.Tenants[tenant]; found && td.Strict != nil {
+		return *td.Strict
+	}
+
+	return dc.Global.Strict != nil && *dc.Global.Strict
+}
+
+// AllowsNamespace returns true if the tenant can run the saved
+// queries of the namespace, which is only restricted when the
+// tenant defaults declare its namespaces.
+func (dc DefaultsCascade) AllowsNamespace(tenant string, namespace string) bool {
+	td, found := dc.Tenants[tenant]
+	if !found || len(td.Namespaces) == 0 {
+		return true
+	}
+
+	for _, n := range td.Namespaces {
+		if n == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MaxQueryMemory returns the ceiling, in bytes, of the response
+// bodies held by a query of the tenant, where zero means unlimited.
+func (dc DefaultsCascade) MaxQueryMemory(tenant string) int {
+	if td, found := dc.Tenants[tenant]; found && td.MaxQueryMemory > 0 {
+		return td.MaxQueryMemory
+	}
+
+	return dc.Global.MaxQueryMemory
+}
+
+type defaultsLevel struct {
+	name     string
+	defaults Defaults
+}
+
+// levels returns the configured levels for the resource,
+// from the most to the least specific.
+func (dc DefaultsCascade) levels(tenant string, namespace string, resource string) []defaultsLevel {
+	var result []defaultsLevel
+
+	if d, found := dc.Namespaces[namespace]; found && namespace != "" {
+		result = append(result, defaultsLevel{name: NamespaceLevel, defaults: d})
+	}
+
+	td, tenantFound := dc.Tenants[tenant]
+	if tenantFound {
+		if d, found := td.Mappings[resource]; found {
+			result = append(result, defaultsLevel{name: MappingLevel, defaults: d})
+		}
+	}
+
+	if d, found := dc.Mappings[resource]; found {
+		result = append(result, defaultsLevel{name: MappingLevel, defaults: d})
+	}
+
+	if tenantFound {
+		result = append(result, defaultsLevel{name: TenantLevel, defaults: td.Defaults})
+	}
+
+	return append(result, defaultsLevel{name: GlobalLevel, defaults: dc.Global})
+}
@@ -0,0 +1,554 @@
+package runner_test
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestDefaultsCascadeResolve(t *testing.T) {
+	one, two, three := 1, 2, 3
+	maxAge, sMaxAge := 100, 200
+
+	cascade := runner.DefaultsCascade{
+		Global: runner.Defaults{
+			Timeout: 5 * time.Second,
+			Retries: &one,
+			Headers: map[string]string{"x-source": "global", "x-global": "yes"},
+		},
+		Tenants: map[string]runner.TenantDefaults{
+			"acme": {
+				Defaults: runner.Defaults{
+					Timeout: 2 * time.Second,
+					MaxAge:  &maxAge,
+					Headers: map[string]string{"X-Source": "tenant"},
+				},
+				Mappings: map[string]runner.Defaults{
+					"hero": {Retries: &three},
+				},
+			},
+		},
+		Mappings: map[string]runner.Defaults{
+			"hero": {Timeout: time.Second, Retries: &two, SMaxAge: &sMaxAge},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		tenant       string
+		modifiers    domain.Modifiers
+		statement    domain.Statement
+		expected     domain.Statement
+		expectedPlan runner.StatementPlan
+	}{
+		{
+			"should apply global defaults to statement",
+			"unknown",
+			nil,
+			domain.Statement{Method: "from", Resource: "sidekick"},
+			domain.Statement{
+				Method:   "from",
+				Resource: "sidekick",
+				Timeout:  5000,
+				Retries:  1,
+				Headers:  map[string]interface{}{"X-Source": "global", "X-Global": "yes"},
+			},
+			runner.StatementPlan{
+				Resource: "sidekick",
+				Method:   "from",
+				Timeout:  "5s",
+				Retries:  1,
+				Headers:  map[string]string{"X-Source": "global", "X-Global": "yes"},
+				Sources: map[string]string{
+					"timeout":          "global",
+					"retries":          "global",
+					"headers.X-Source": "global",
+					"headers.X-Global": "global",
+				},
+			},
+		},
+		{
+			"should apply tenant defaults over global defaults",
+			"acme",
+			nil,
+			domain.Statement{Method: "from", Resource: "sidekick"},
+			domain.Statement{
+				Method:       "from",
+				Resource:     "sidekick",
+				Timeout:      2000,
+				Retries:      1,
+				CacheControl: domain.CacheControl{MaxAge: 100},
+				Headers:      map[string]interface{}{"X-Source": "tenant", "X-Global": "yes"},
+			},
+			runner.StatementPlan{
+				Resource: "sidekick",
+				Method:   "from",
+				Timeout:  "2s",
+				Retries:  1,
+				MaxAge:   100,
+				Headers:  map[string]string{"X-Source": "tenant", "X-Global": "yes"},
+				Sources: map[string]string{
+					"timeout":          "tenant",
+					"retries":          "global",
+					"maxAge":           "tenant",
+					"headers.X-Source": "tenant",
+					"headers.X-Global": "global",
+				},
+			},
+		},
+		{
+			"should apply mapping defaults over tenant defaults",
+			"acme",
+			nil,
+			domain.Statement{Method: "from", Resource: "hero"},
+			domain.Statement{
+				Method:       "from",
+				Resource:     "hero",
+				Timeout:      1000,
+				Retries:      3,
+				CacheControl: domain.CacheControl{MaxAge: 100, SMaxAge: 200},
+				Headers:      map[string]interface{}{"X-Source": "tenant", "X-Global": "yes"},
+			},
+			runner.StatementPlan{
+				Resource: "hero",
+				Method:   "from",
+				Timeout:  "1s",
+				Retries:  3,
+				MaxAge:   100,
+				SMaxAge:  200,
+				Headers:  map[string]string{"X-Source": "tenant", "X-Global": "yes"},
+				Sources: map[string]string{
+					"timeout":          "mapping",
+					"retries":          "mapping",
+					"maxAge":           "tenant",
+					"sMaxAge":          "mapping",
+					"headers.X-Source": "tenant",
+					"headers.X-Global": "global",
+				},
+			},
+		},
+		{
+			"should apply query modifiers over mapping defaults",
+			"acme",
+			domain.Modifiers{"max-age": 50, "s-max-age": 60, "retries": 0},
+			domain.Statement{Method: "from", Resource: "hero"},
+			domain.Statement{
+				Method:       "from",
+				Resource:     "hero",
+				Timeout:      1000,
+				CacheControl: domain.CacheControl{MaxAge: 50, SMaxAge: 60},
+				Headers:      map[string]interface{}{"X-Source": "tenant", "X-Global": "yes"},
+			},
+			runner.StatementPlan{
+				Resource: "hero",
+				Method:   "from",
+				Timeout:  "1s",
+				MaxAge:   50,
+				SMaxAge:  60,
+				Headers:  map[string]string{"X-Source": "tenant", "X-Global": "yes"},
+				Sources: map[string]string{
+					"timeout":          "mapping",
+					"retries":          "query",
+					"maxAge":           "query",
+					"sMaxAge":          "query",
+					"headers.X-Source": "tenant",
+					"headers.X-Global": "global",
+				},
+			},
+		},
+		{
+			"should keep statement values over every default",
+			"acme",
+			domain.Modifiers{"max-age": 50},
+			domain.Statement{
+				Method:       "from",
+				Resource:     "hero",
+				Timeout:      300,
+				CacheControl: domain.CacheControl{MaxAge: 10},
+				Headers:      map[string]interface{}{"x-source": "statement"},
+			},
+			domain.Statement{
+				Method:       "from",
+				Resource:     "hero",
+				Timeout:      300,
+				Retries:      3,
+				CacheControl: domain.CacheControl{MaxAge: 10, SMaxAge: 200},
+				Headers:      map[string]interface{}{"X-Source": "statement", "X-Global": "yes"},
+			},
+			runner.StatementPlan{
+				Resource: "hero",
+				Method:   "from",
+				Timeout:  "300ms",
+				Retries:  3,
+				MaxAge:   10,
+				SMaxAge:  200,
+				Headers:  map[string]string{"X-Source": "statement", "X-Global": "yes"},
+				Sources: map[string]string{
+					"timeout":          "statement",
+					"retries":          "mapping",
+					"maxAge":           "statement",
+					"sMaxAge":          "mapping",
+					"headers.X-Source": "statement",
+					"headers.X-Global": "global",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotPlan := cascade.Resolve(tt.tenant, "", tt.modifiers, tt.statement)
+			test.Equal(t, got, tt.expected)
+			test.Equal(t, gotPlan, tt.expectedPlan)
+		})
+	}
+}
+
+func TestDefaultsCascadeResolveLimits(t *testing.T) {
+	cascade := runner.DefaultsCascade{
+		Global: runner.Defaults{MaxResponseSize: 1048576, MaxMultiplexedRequests: 100},
+		Mappings: map[string]runner.Defaults{
+			"hero": {MaxResponseSize: 1024},
+		},
+	}
+
+	got, gotPlan := cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "hero"})
+
+	test.Equal(t, got.MaxResponseSize, 1024)
+	test.Equal(t, got.MaxMultiplexedRequests, 100)
+	test.Equal(t, gotPlan.MaxResponseSize, 1024)
+	test.Equal(t, gotPlan.MaxMultiplexedRequests, 100)
+	test.Equal(t, gotPlan.Sources["maxResponseSize"], "mapping")
+	test.Equal(t, gotPlan.Sources["maxMultiplexedRequests"], "global")
+}
+
+func TestDefaultsCascadeResolveCache(t *testing.T) {
+	cascade := runner.DefaultsCascade{}
+	modifiers := domain.Modifiers{"cache": 60}
+
+	got, gotPlan := cascade.Resolve("", "", modifiers, domain.Statement{Method: "from", Resource: "hero"})
+	test.Equal(t, got.Cache, 60)
+	test.Equal(t, gotPlan.Cache, 60)
+	test.Equal(t, gotPlan.Sources["cache"], "query")
+
+	got, gotPlan = cascade.Resolve("", "", modifiers, domain.Statement{Method: "from", Resource: "hero", Cache: 10})
+	test.Equal(t, got.Cache, 10)
+	test.Equal(t, gotPlan.Sources["cache"], "statement")
+
+	got, gotPlan = cascade.Resolve("", "", modifiers, domain.Statement{Method: "from", Resource: "hero", NoCache: true})
+	test.Equal(t, got.Cache, 0)
+	test.Equal(t, gotPlan.NoCache, true)
+	test.Equal(t, gotPlan.Sources["cache"], "")
+
+	_, gotPlan = cascade.Resolve("", "", modifiers, domain.Statement{Method: "to", Resource: "hero"})
+	test.Equal(t, gotPlan.Cache, 0)
+}
+
+func TestDefaultsCascadeResolveParams(t *testing.T) {
+	cascade := runner.DefaultsCascade{
+		Global: runner.Defaults{Params: map[string]string{"channel": "web", "locale": "en"}},
+		Tenants: map[string]runner.TenantDefaults{
+			"acme": {
+				Defaults: runner.Defaults{Params: map[string]string{"channel": "app", "apiKey": "acme-key"}},
+				Mappings: map[string]runner.Defaults{"hero": {Params: map[string]string{"apiKey": "hero-key"}}},
+			},
+		},
+	}
+
+	got, gotPlan := cascade.Resolve("acme", "", nil, domain.Statement{Method: "from", Resource: "hero"})
+
+	expected := map[string]string{"channel": "app", "locale": "en", "apiKey": "hero-key"}
+	test.Equal(t, got.DefaultParams, expected)
+	test.Equal(t, gotPlan.Params, expected)
+	test.Equal(t, gotPlan.Sources["params.apiKey"], "mapping")
+	test.Equal(t, gotPlan.Sources["params.channel"], "tenant")
+	test.Equal(t, gotPlan.Sources["params.locale"], "global")
+
+	got, _ = cascade.Resolve("umbrella", "", nil, domain.Statement{Method: "from", Resource: "hero"})
+
+	test.Equal(t, got.DefaultParams, map[string]string{"channel": "web", "locale": "en"})
+}
+
+func TestDefaultsCascadeResolveNamespace(t *testing.T) {
+	ignoreErrors := true
+	maxAge := 60
+	cascade := runner.DefaultsCascade{
+		Global: runner.Defaults{Timeout: time.Second, Headers: map[string]string{"X-Channel": "web"}},
+		Mappings: map[string]runner.Defaults{
+			"hero": {Timeout: 2 * time.Second},
+		},
+		Namespaces: map[string]runner.Defaults{
+			"marvel": {Timeout: 500 * time.Millisecond, MaxAge: &maxAge, IgnoreErrors: &ignoreErrors, Headers: map[string]string{"X-Channel": "comics"}},
+		},
+	}
+
+	got, gotPlan := cascade.Resolve("", "marvel", nil, domain.Statement{Method: "from", Resource: "hero"})
+
+	test.Equal(t, got.Timeout, 500)
+	test.Equal(t, got.CacheControl.MaxAge, 60)
+	test.Equal(t, got.IgnoreErrors, true)
+	test.Equal(t, got.Headers, map[string]interface{}{"X-Channel": "comics"})
+	test.Equal(t, gotPlan.IgnoreErrors, true)
+	test.Equal(t, gotPlan.Sources["timeout"], "namespace")
+	test.Equal(t, gotPlan.Sources["ignoreErrors"], "namespace")
+	test.Equal(t, gotPlan.Sources["headers.X-Channel"], "namespace")
+
+	got, gotPlan = cascade.Resolve("", "marvel", domain.Modifiers{"max-age": 10}, domain.Statement{Method: "from", Resource: "hero", Timeout: 100})
+
+	test.Equal(t, got.Timeout, 100)
+	test.Equal(t, got.CacheControl.MaxAge, 10)
+	test.Equal(t, gotPlan.Sources["maxAge"], "query")
+
+	got, gotPlan = cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "hero"})
+
+	test.Equal(t, got.Timeout, 2000)
+	test.Equal(t, got.IgnoreErrors, false)
+	test.Equal(t, gotPlan.Sources["headers.X-Channel"], "global")
+}
+
+func TestDefaultsCascadeAllowsNamespace(t *testing.T) {
+	cascade := runner.DefaultsCascade{
+		Tenants: map[string]runner.TenantDefaults{
+			"acme":     {Defaults: runner.Defaults{Namespaces: []string{"acme", "shared"}}},
+			"umbrella": {},
+		},
+	}
+
+	test.Equal(t, cascade.AllowsNamespace("acme", "shared"), true)
+	test.Equal(t, cascade.AllowsNamespace("acme", "umbrella"), false)
+	test.Equal(t, cascade.AllowsNamespace("umbrella", "acme"), true)
+	test.Equal(t, cascade.AllowsNamespace("unknown", "acme"), true)
+}
+
+func TestDefaultsCascadeResolveForwardHeaders(t *testing.T) {
+	global := &domain.HeaderForwarding{Deny: []string{"Cookie"}}
+	hero := &domain.HeaderForwarding{Allow: []string{"Authorization"}}
+	cascade := runner.DefaultsCascade{
+		Global: runner.Defaults{ForwardHeaders: global},
+		Mappings: map[string]runner.Defaults{
+			"hero": {ForwardHeaders: hero},
+		},
+	}
+
+	got, gotPlan := cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "hero"})
+
+	test.Equal(t, got.ForwardHeaders, hero)
+	test.Equal(t, gotPlan.ForwardHeaders, hero)
+	test.Equal(t, gotPlan.Sources["forwardHeaders"], "mapping")
+
+	got, gotPlan = cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "villain"})
+
+	test.Equal(t, got.ForwardHeaders, global)
+	test.Equal(t, gotPlan.Sources["forwardHeaders"], "global")
+}
+
+func TestDefaultsCascadeResolveNormalize(t *testing.T) {
+	normalization := &domain.Normalization{Lift: "data"}
+	cascade := runner.DefaultsCascade{
+		Global: runner.Defaults{Normalize: &domain.Normalization{Lift: "result"}},
+		Mappings: map[string]runner.Defaults{
+			"hero": {Normalize: normalization},
+		},
+	}
+
+	got, gotPlan := cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "hero"})
+
+	test.Equal(t, got.Normalize, normalization)
+	test.Equal(t, gotPlan.Sources["normalize"], "mapping")
+
+	got, _ = cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "villain"})
+
+	test.Equal(t, got.Normalize == nil, true)
+}
+
+func TestDefaultsCascadeResolveSigning(t *testing.T) {
+	signing := &domain.RequestSigning{Scheme: domain.SigV4SigningScheme, Service: "execute-api"}
+	cascade := runner.DefaultsCascade{
+		Global: runner.Defaults{Signing: &domain.RequestSigning{Scheme: domain.HMACSigningScheme}},
+		Mappings: map[string]runner.Defaults{
+			"hero": {Signing: signing},
+		},
+	}
+
+	got, gotPlan := cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "hero"})
+
+	test.Equal(t, got.Signing, signing)
+	test.Equal(t, gotPlan.Signing, "sigv4")
+	test.Equal(t, gotPlan.Sources["signing"], "mapping")
+
+	got, _ = cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "villain"})
+
+	test.Equal(t, got.Signing == nil, true)
+}
+
+func TestDefaultsCascadeResolveTLS(t *testing.T) {
+	settings := &domain.UpstreamTLS{MinVersion: tls.VersionTLS12, RevocationCheck: domain.OCSPRevocationCheck}
+	cascade := runner.DefaultsCascade{
+		Global: runner.Defaults{TLS: &domain.UpstreamTLS{DisableSessionTickets: true}},
+		Mappings: map[string]runner.Defaults{
+			"hero": {TLS: settings},
+		},
+	}
+
+	got, gotPlan := cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "hero"})
+
+	test.Equal(t, got.TLS, settings)
+	test.Equal(t, gotPlan.TLS, settings.String())
+	test.Equal(t, gotPlan.Sources["tls"], "mapping")
+
+	got, _ = cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "villain"})
+
+	test.Equal(t, got.TLS == nil, true)
+}
+
+func TestDefaultsCascadeResolvePolicy(t *testing.T) {
+	policy := &domain.ResourcePolicy{Methods: []string{"GET"}}
+	cascade := runner.DefaultsCascade{
+		Global: runner.Defaults{Policy: &domain.ResourcePolicy{MaxBodySize: 10}},
+		Mappings: map[string]runner.Defaults{
+			"hero": {Policy: policy},
+		},
+	}
+
+	got, gotPlan := cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "hero"})
+
+	test.Equal(t, got.Policy, policy)
+	test.Equal(t, gotPlan.Policy, policy)
+	test.Equal(t, gotPlan.Sources["policy"], "mapping")
+
+	got, _ = cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "villain"})
+
+	test.Equal(t, got.Policy == nil, true)
+}
+
+func TestDefaultsCascadeResolveProxy(t *testing.T) {
+	proxy := &domain.OutboundProxy{Scheme: domain.SOCKS5ProxyScheme, Addr: "egress:1080", Username: "restql", Password: "secret"}
+	cascade := runner.DefaultsCascade{
+		Global: runner.Defaults{Proxy: &domain.OutboundProxy{Scheme: domain.HTTPProxyScheme, Addr: "proxy:3128"}},
+		Tenants: map[string]runner.TenantDefaults{
+			"acme": {Defaults: runner.Defaults{Proxy: proxy}},
+		},
+		Mappings: map[string]runner.Defaults{
+			"hero": {Proxy: &domain.OutboundProxy{}},
+		},
+	}
+
+	got, gotPlan := cascade.Resolve("acme", "", nil, domain.Statement{Method: "from", Resource: "villain"})
+
+	test.Equal(t, got.Proxy, proxy)
+	test.Equal(t, gotPlan.Proxy, "socks5://restql@egress:1080")
+	test.Equal(t, gotPlan.Sources["proxy"], "tenant")
+
+	got, gotPlan = cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "villain"})
+
+	test.Equal(t, gotPlan.Proxy, "http://proxy:3128")
+	test.Equal(t, gotPlan.Sources["proxy"], "global")
+
+	got, gotPlan = cascade.Resolve("acme", "", nil, domain.Statement{Method: "from", Resource: "hero"})
+
+	test.Equal(t, got.Proxy == nil, true)
+	test.Equal(t, gotPlan.Proxy, "")
+	test.Equal(t, gotPlan.Sources["proxy"], "mapping")
+}
+
+func TestDefaultsCascadeResolveSessionCookies(t *testing.T) {
+	enabled, disabled := true, false
+	cascade := runner.DefaultsCascade{
+		Global: runner.Defaults{SessionCookies: &enabled},
+		Mappings: map[string]runner.Defaults{
+			"hero": {SessionCookies: &disabled},
+		},
+	}
+
+	got, gotPlan := cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "sidekick"})
+
+	test.Equal(t, got.SessionCookies, true)
+	test.Equal(t, gotPlan.SessionCookies, true)
+	test.Equal(t, gotPlan.Sources["sessionCookies"], "global")
+
+	got, gotPlan = cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "hero"})
+
+	test.Equal(t, got.SessionCookies, false)
+	test.Equal(t, gotPlan.Sources["sessionCookies"], "mapping")
+}
+
+func TestDefaultsCascadeResolveResponseSchema(t *testing.T) {
+	schema := &domain.ResponseSchema{Mode: domain.SchemaModeAnnotate}
+	cascade := runner.DefaultsCascade{
+		Global: runner.Defaults{ResponseSchema: &domain.ResponseSchema{Mode: domain.SchemaModeFail}},
+		Mappings: map[string]runner.Defaults{
+			"hero": {ResponseSchema: schema},
+		},
+	}
+
+	got, gotPlan := cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "hero"})
+
+	test.Equal(t, got.ResponseSchema == schema, true)
+	test.Equal(t, gotPlan.ResponseSchema, domain.SchemaModeAnnotate)
+	test.Equal(t, gotPlan.Sources["responseSchema"], "mapping")
+
+	got, _ = cascade.Resolve("", "", nil, domain.Statement{Method: "from", Resource: "villain"})
+
+	test.Equal(t, got.ResponseSchema == nil, true)
+}
+
+func TestDefaultsCascadeResolveMock(t *testing.T) {
+	mock := &domain.Mock{Status: 200, Body: []byte(`{}`)}
+	cascade := runner.DefaultsCascade{
+		Mappings: map[string]runner.Defaults{
+			"hero":     {Mock: mock},
+			"sidekick": {Mock: mock},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		modifiers      domain.Modifiers
+		resource       string
+		expectedMocked bool
+	}{
+		{"should not select mock without modifier", nil, "hero", false},
+		{"should select every mock with wildcard", domain.Modifiers{"mock": "*"}, "sidekick", true},
+		{"should select listed mock", domain.Modifiers{"mock": "villain, hero"}, "hero", true},
+		{"should not select unlisted mock", domain.Modifiers{"mock": "villain, hero"}, "sidekick", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotPlan := cascade.Resolve("", "", tt.modifiers, domain.Statement{Method: "from", Resource: tt.resource})
+
+			test.Equal(t, got.Mock, mock)
+			test.Equal(t, got.Mocked, tt.expectedMocked)
+			test.Equal(t, gotPlan.Mocked, tt.expectedMocked)
+			test.Equal(t, gotPlan.Sources["mock"], "mapping")
+		})
+	}
+}
+
+func TestDefaultsCascadeResolvePlanHeaders(t *testing.T) {
+	cascade := runner.DefaultsCascade{}
+	statement := domain.Statement{
+		Method:   "from",
+		Resource: "hero",
+		Headers: map[string]interface{}{
+			"X-Static":   "yes",
+			"X-Variable": domain.Variable{Target: "token"},
+			"X-Chained":  domain.Flatten{Value: domain.Chain{"done-resource", domain.Variable{Target: "field"}, "id"}},
+			"X-Number":   10,
+		},
+	}
+
+	_, gotPlan := cascade.Resolve("", "", nil, statement)
+
+	expected := map[string]string{
+		"X-Static":   "yes",
+		"X-Variable": "$token",
+		"X-Chained":  "done-resource.$field.id",
+		"X-Number":   "10",
+	}
+	test.Equal(t, gotPlan.Headers, expected)
+}
@@ -0,0 +1,190 @@
+package runner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/pkg/errors"
+)
+
+// ErrChainCycle represents an error when chain parameter
+// values make statements depend on each other.
+var ErrChainCycle = errors.New("chained parameters form a cycle")
+
+// ErrChainTooDeep represents an error when a statement depends on
+// a longer sequence of chained statements than allowed.
+var ErrChainTooDeep = errors.New("chained parameters exceed maximum depth")
+
+// ValidateChainDependencies returns an error if the statements chain
+// parameter values form a cycle or, when maxDepth is positive, if
+// any statement depends on more than maxDepth chained statements in
+// sequence. The error names the statements in the offending chain.
+func ValidateChainDependencies(resources domain.Resources, maxDepth int) error {
+	graph := make(map[domain.ResourceID][]domain.ResourceID, len(resources))
+	for resourceID, stmt := range resources {
+		graph[resourceID] = statementDependencies(stmt, resources)
+	}
+
+	v := dependencyValidator{
+		graph:    graph,
+		maxDepth: maxDepth,
+		depths:   make(map[domain.ResourceID]int, len(graph)),
+		deepest:  make(map[domain.ResourceID]domain.ResourceID, len(graph)),
+		visiting: make(map[domain.ResourceID]bool, len(graph)),
+	}
+
+	for _, resourceID := range sortedResourceIDs(graph) {
+		err := v.visit(resourceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type dependencyValidator struct {
+	graph    map[domain.ResourceID][]domain.ResourceID
+	maxDepth int
+	depths   map[domain.ResourceID]int
+	deepest  map[domain.ResourceID]domain.ResourceID
+	visiting map[domain.ResourceID]bool
+	path     []domain.ResourceID
+}
+
+// visit computes the length of the longest dependency
+// chain starting at the statement, failing on cycles.
+func (v *dependencyValidator) visit(resourceID domain.ResourceID) error {
+	if _, done := v.depths[resourceID]; done {
+		return nil
+	}
+
+	v.path = append(v.path, resourceID)
+	defer func() { v.path = v.path[:len(v.path)-1] }()
+
+	if v.visiting[resourceID] {
+		return fmt.Errorf("%w : %s", ErrChainCycle, v.describePath(resourceID))
+	}
+	v.visiting[resourceID] = true
+
+	depth := 0
+	for _, dependency := range v.graph[resourceID] {
+		err := v.visit(dependency)
+		if err != nil {
+			return err
+		}
+
+		if d := v.depths[dependency] + 1; d > depth {
+			depth = d
+			v.deepest[resourceID] = dependency
+		}
+	}
+
+	if v.maxDepth > 0 && depth > v.maxDepth {
+		return fmt.Errorf("%w of %d : %s", ErrChainTooDeep, v.maxDepth, v.describeChain(resourceID))
+	}
+
+	v.visiting[resourceID] = false
+	v.depths[resourceID] = depth
+	return nil
+}
+
+// describePath returns the statements in the cycle
+// closed by the resource, in dependency order.
+func (v *dependencyValidator) describePath(resourceID domain.ResourceID) string {
+	start := 0
+	for i, r := range v.path {
+		if r == resourceID {
+			start = i
+			break
+		}
+	}
+
+	names := make([]string, 0, len(v.path)-start)
+	for _, r := range v.path[start:] {
+		names = append(names, string(r))
+	}
+	return strings.Join(names, " -> ")
+}
+
+// describeChain returns the statements in the longest
+// dependency chain starting at the resource.
+func (v *dependencyValidator) describeChain(resourceID domain.ResourceID) string {
+	names := []string{string(resourceID)}
+	for next, found := v.deepest[resourceID]; found; next, found = v.deepest[next] {
+		names = append(names, string(next))
+	}
+	return strings.Join(names, " -> ")
+}
+
+func statementDependencies(stmt interface{}, resources domain.Resources) []domain.ResourceID {
+	seen := make(map[domain.ResourceID]bool)
+	collectStatementDependencies(stmt, resources, seen)
+
+	result := make([]domain.ResourceID, 0, len(seen))
+	for resourceID := range seen {
+		result = append(result, resourceID)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+
+	return result
+}
+
+func collectStatementDependencies(stmt interface{}, resources domain.Resources, seen map[domain.ResourceID]bool) {
+	switch stmt := stmt.(type) {
+	case domain.Statement:
+		for _, value := range stmt.With.Values {
+			collectValueDependencies(value, resources, seen)
+		}
+		for _, value := range stmt.Headers {
+			collectValueDependencies(value, resources, seen)
+		}
+		for _, value := range statementSettings(stmt) {
+			collectValueDependencies(value, resources, seen)
+		}
+	case []interface{}:
+		for _, s := range stmt {
+			collectStatementDependencies(s, resources, seen)
+		}
+	}
+}
+
+func collectValueDependencies(value interface{}, resources domain.Resources, seen map[domain.ResourceID]bool) {
+	switch value := value.(type) {
+	case domain.Chain:
+		target, ok := value[0].(string)
+		if !ok {
+			return
+		}
+
+		if _, found := resources[domain.ResourceID(target)]; found {
+			seen[domain.ResourceID(target)] = true
+		}
+	case domain.Range:
+		collectValueDependencies(value.Start, resources, seen)
+		collectValueDependencies(value.End, resources, seen)
+		collectValueDependencies(value.Step, resources, seen)
+	case domain.Function:
+		collectValueDependencies(value.Target(), resources, seen)
+	case []interface{}:
+		for _, v := range value {
+			collectValueDependencies(v, resources, seen)
+		}
+	case map[string]interface{}:
+		for _, v := range value {
+			collectValueDependencies(v, resources, seen)
+		}
+	}
+}
+
+func sortedResourceIDs(graph map[domain.ResourceID][]domain.ResourceID) []domain.ResourceID {
+	result := make([]domain.ResourceID, 0, len(graph))
+	for resourceID := range graph {
+		result = append(result, resourceID)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+
+	return result
+}
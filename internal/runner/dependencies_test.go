@@ -0,0 +1,102 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestValidateChainDependencies(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxDepth  int
+		resources domain.Resources
+		expected  error
+	}{
+		{
+			"should accept statements without chained parameters",
+			1,
+			domain.Resources{
+				"hero":     domain.Statement{Resource: "hero"},
+				"sidekick": domain.Statement{Resource: "sidekick"},
+			},
+			nil,
+		},
+		{
+			"should accept chain within maximum depth",
+			2,
+			domain.Resources{
+				"hero":     domain.Statement{Resource: "hero"},
+				"sidekick": domain.Statement{Resource: "sidekick", With: domain.Params{Values: map[string]interface{}{"id": domain.Chain{"hero", "sidekickId"}}}},
+				"weapon":   domain.Statement{Resource: "weapon", Headers: map[string]interface{}{"X-Owner": domain.Chain{"sidekick", "id"}}},
+			},
+			nil,
+		},
+		{
+			"should accept any chain depth when maximum depth is not set",
+			0,
+			domain.Resources{
+				"hero":     domain.Statement{Resource: "hero"},
+				"sidekick": domain.Statement{Resource: "sidekick", With: domain.Params{Values: map[string]interface{}{"id": domain.Chain{"hero", "sidekickId"}}}},
+				"weapon":   domain.Statement{Resource: "weapon", With: domain.Params{Values: map[string]interface{}{"id": domain.Chain{"sidekick", "id"}}}},
+			},
+			nil,
+		},
+		{
+			"should reject chain exceeding maximum depth",
+			1,
+			domain.Resources{
+				"hero":     domain.Statement{Resource: "hero"},
+				"sidekick": domain.Statement{Resource: "sidekick", With: domain.Params{Values: map[string]interface{}{"id": domain.Chain{"hero", "sidekickId"}}}},
+				"weapon":   domain.Statement{Resource: "weapon", With: domain.Params{Values: map[string]interface{}{"id": domain.NoMultiplex{Value: domain.Chain{"sidekick", "id"}}}}},
+			},
+			fmt.Errorf("%w of 1 : weapon -> sidekick -> hero", runner.ErrChainTooDeep),
+		},
+		{
+			"should reject statement depending on itself",
+			0,
+			domain.Resources{
+				"hero": domain.Statement{Resource: "hero", With: domain.Params{Values: map[string]interface{}{"id": domain.Chain{"hero", "id"}}}},
+			},
+			fmt.Errorf("%w : hero -> hero", runner.ErrChainCycle),
+		},
+		{
+			"should reject statements depending on each other",
+			0,
+			domain.Resources{
+				"hero":     domain.Statement{Resource: "hero", With: domain.Params{Values: map[string]interface{}{"id": []interface{}{domain.Chain{"weapon", "ownerId"}}}}},
+				"sidekick": domain.Statement{Resource: "sidekick", With: domain.Params{Values: map[string]interface{}{"id": domain.Chain{"hero", "sidekickId"}}}},
+				"weapon":   domain.Statement{Resource: "weapon", With: domain.Params{Values: map[string]interface{}{"owner": map[string]interface{}{"id": domain.Chain{"sidekick", "id"}}}}},
+			},
+			fmt.Errorf("%w : hero -> weapon -> sidekick -> hero", runner.ErrChainCycle),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runner.ValidateChainDependencies(tt.resources, tt.maxDepth)
+			test.Equal(t, fmt.Sprintf("%s", got), fmt.Sprintf("%s", tt.expected))
+		})
+	}
+}
+
+func TestRunnerRejectsChainCycle(t *testing.T) {
+	executor := runner.NewExecutor(test.NoOpLogger, &stubClient{}, nil, nil, nil, 0, "", nil)
+	r := runner.NewRunner(test.NoOpLogger, executor, 0, runner.DefaultsCascade{}, nil, 0)
+
+	query := domain.Query{Statements: []domain.Statement{
+		{Method: domain.FromMethod, Resource: "hero", With: domain.Params{Values: map[string]interface{}{"id": domain.Chain{"sidekick", "heroId"}}}},
+		{Method: domain.FromMethod, Resource: "sidekick", With: domain.Params{Values: map[string]interface{}{"id": domain.Chain{"hero", "sidekickId"}}}},
+	}}
+
+	_, err := r.ExecuteQuery(context.Background(), query, restql.QueryContext{})
+	if !errors.Is(err, runner.ErrChainCycle) {
+		t.Errorf("ExecuteQuery returned error %v, expected %v", err, runner.ErrChainCycle)
+	}
+}
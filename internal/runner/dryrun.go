@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// StatementDryRun represents the requests a statement would make,
+// and the statements it waits for due to chained parameters.
+type StatementDryRun struct {
+	Statement string           `json:"statement"`
+	Resource  string           `json:"resource"`
+	DependsOn []string         `json:"dependsOn,omitempty"`
+	Subquery  bool             `json:"subquery,omitempty"`
+	Requests  []PlannedRequest `json:"requests,omitempty"`
+}
+
+// PlannedRequest represents the HTTP call built for a statement.
+// Chained values are not known before the statements they target
+// are executed, so they are replaced by a `<resource.path>`
+// placeholder and listed as pending.
+type PlannedRequest struct {
+	Method  string                 `json:"method"`
+	URL     string                 `json:"url"`
+	Query   map[string]interface{} `json:"query,omitempty"`
+	Headers map[string]string      `json:"headers,omitempty"`
+	Body    interface{}            `json:"body,omitempty"`
+	Timeout string                 `json:"timeout"`
+	Pending []string               `json:"pending,omitempty"`
+	Mocked  bool                   `json:"mocked,omitempty"`
+}
+
+// DryRunQuery resolves the requests each statement in the query would
+// make, with all values known before execution applied, without
+// calling any upstream. Statements are sorted by identifier.
+func (r Runner) DryRunQuery(query domain.Query, queryCtx restql.QueryContext) ([]StatementDryRun, error) {
+	resources, err := r.initializeResources(query, queryCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]domain.ResourceID, 0, len(resources))
+	for resourceID := range resources {
+		ids = append(ids, resourceID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	result := make([]StatementDryRun, 0, len(ids))
+	for _, resourceID := range ids {
+		stmt := resources[resourceID]
+
+		dr := StatementDryRun{Statement: string(resourceID)}
+		for _, dependency := range statementDependencies(stmt, resources) {
+			dr.DependsOn = append(dr.DependsOn, string(dependency))
+		}
+
+		for _, s := range flattenStatements(stmt) {
+			dr.Resource = s.Resource
+			if _, isSubquery := domain.ParseSubquery(s.Resource); isSubquery {
+				dr.Subquery = true
+				continue
+			}
+			dr.Requests = append(dr.Requests, r.planRequest(s, queryCtx))
+		}
+
+		result = append(result, dr)
+	}
+
+	return result, nil
+}
+
+func (r Runner) planRequest(statement domain.Statement, queryCtx restql.QueryContext) PlannedRequest {
+	var pending []string
+	statement.With.Values = markPendingValues(statement.With.Values, &pending).(map[string]interface{})
+	if statement.With.Body != nil {
+		statement.With.Body = markPendingValues(statement.With.Body, &pending)
+	}
+	if statement.Headers != nil {
+		statement.Headers = markPendingValues(statement.Headers, &pending).(map[string]interface{})
+	}
+
+	request := MakeRequest(r.executor.resourceTimeout, r.executor.forwardPrefix, statement, queryCtx)
+	_, mapped := queryCtx.Mappings[statement.Resource]
+
+	pr := PlannedRequest{
+		Method:  request.Method,
+		Query:   request.Query,
+		Headers: request.Headers,
+		Body:    request.Body,
+		Timeout: request.Timeout.String(),
+		Pending: dedupeSorted(pending),
+		Mocked:  statement.Mock != nil && (statement.Mocked || !mapped),
+	}
+	if request.Host != "" {
+		pr.URL = fmt.Sprintf("%s://%s%s", request.Schema, request.Host, request.Path)
+	}
+
+	return pr
+}
+
+func flattenStatements(stmt interface{}) []domain.Statement {
+	switch stmt := stmt.(type) {
+	case domain.Statement:
+		return []domain.Statement{stmt}
+	case []interface{}:
+		var result []domain.Statement
+		for _, s := range stmt {
+			result = append(result, flattenStatements(s)...)
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// markPendingValues returns a copy of the value with the chained
+// values replaced by placeholders, appending their paths to pending.
+func markPendingValues(value interface{}, pending *[]string) interface{} {
+	switch value := value.(type) {
+	case domain.Chain:
+		p := chainPath(value)
+		*pending = append(*pending, p)
+		return "<" + p + ">"
+	case domain.AsBody:
+		return value.Map(func(target interface{}) interface{} {
+			return markPendingValues(target, pending)
+		})
+	case domain.Function:
+		// Encoders are only applied once the chained values are
+		// resolved, so the whole value is pending.
+		before := len(*pending)
+		markPendingValues(value.Target(), pending)
+		if len(*pending) > before {
+			return "<" + strings.Join((*pending)[before:], ",") + ">"
+		}
+		return value
+	case domain.Range:
+		return domain.Range{
+			Start: markPendingValues(value.Start, pending),
+			End:   markPendingValues(value.End, pending),
+			Step:  markPendingValues(value.Step, pending),
+		}
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, v := range value {
+			result[i] = markPendingValues(v, pending)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			result[k] = markPendingValues(v, pending)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func dedupeSorted(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	sort.Strings(values)
+	result := values[:1]
+	for _, v := range values[1:] {
+		if v != result[len(result)-1] {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
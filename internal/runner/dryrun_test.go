@@ -0,0 +1,71 @@
+package runner_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestRunnerDryRunQuery(t *testing.T) {
+	client := &stubClient{}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+	r := runner.NewRunner(test.NoOpLogger, executor, time.Second, runner.DefaultsCascade{}, nil, 0)
+
+	query := domain.Query{Statements: []domain.Statement{
+		{
+			Method:   domain.FromMethod,
+			Resource: "hero",
+			With:     domain.Params{Values: map[string]interface{}{"id": []interface{}{"1", "2"}}},
+		},
+		{
+			Method:   domain.FromMethod,
+			Resource: "sidekick",
+			Headers:  map[string]interface{}{"X-Hero": domain.Chain{"hero", "name"}},
+			With:     domain.Params{Values: map[string]interface{}{"hero": domain.Chain{"hero", "id"}, "level": 3}},
+		},
+	}}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{
+			"hero":     mapping(t, "http://hero.io/api/:id"),
+			"sidekick": mapping(t, "http://sidekick.io/api/:hero"),
+		},
+		Options: restql.QueryOptions{Tenant: "acme"},
+	}
+
+	statements, err := r.DryRunQuery(query, queryCtx)
+	test.VerifyError(t, err)
+
+	expected := []runner.StatementDryRun{
+		{
+			Statement: "hero",
+			Resource:  "hero",
+			Requests: []runner.PlannedRequest{
+				{Method: http.MethodGet, URL: "http://hero.io/api/1", Query: map[string]interface{}{}, Headers: map[string]string{"Content-Type": "application/json"}, Timeout: "1s"},
+				{Method: http.MethodGet, URL: "http://hero.io/api/2", Query: map[string]interface{}{}, Headers: map[string]string{"Content-Type": "application/json"}, Timeout: "1s"},
+			},
+		},
+		{
+			Statement: "sidekick",
+			Resource:  "sidekick",
+			DependsOn: []string{"hero"},
+			Requests: []runner.PlannedRequest{
+				{
+					Method:  http.MethodGet,
+					URL:     "http://sidekick.io/api/<hero.id>",
+					Query:   map[string]interface{}{"level": 3},
+					Headers: map[string]string{"Content-Type": "application/json", "X-Hero": "<hero.name>"},
+					Timeout: "1s",
+					Pending: []string{"hero.id", "hero.name"},
+				},
+			},
+		},
+	}
+
+	test.Equal(t, statements, expected)
+	test.Equal(t, len(client.requests), 0)
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
@@ -23,12 +25,23 @@ func ApplyEncoders(resources domain.Resources, log restql.Logger) domain.Resourc
 
 func applyEncoderToStatement(log restql.Logger, statement domain.Statement) domain.Statement {
 	values := statement.With.Values
+	deepObjects := make(map[string]interface{})
 	for key, value := range values {
+		if do, ok := value.(domain.DeepObject); ok && !isUnresolved(do.Target()) {
+			delete(values, key)
+			applyDeepObjectEncoder(key, applyEncoderToValue(log, do.Target()), deepObjects)
+			continue
+		}
+
 		result := applyEncoderToValue(log, value)
 
 		values[key] = result
 	}
 
+	for key, value := range deepObjects {
+		values[key] = value
+	}
+
 	body := applyEncoderToBody(log, statement.With.Body)
 
 	statement.With.Body = body
@@ -45,6 +58,12 @@ func applyEncoderToBody(log restql.Logger, body interface{}) interface{} {
 		return applyEncoderToBody(log, body.Target())
 	case domain.Flatten:
 		return applyFlattenEncoder(log, applyEncoderToBody(log, body.Target()))
+	case domain.Delimited:
+		return applyDelimitedEncoder(log, applyEncoderToBody(log, body.Target()), body.Separator)
+	case domain.DeepObject:
+		return applyEncoderToBody(log, body.Target())
+	case domain.Repeated:
+		return applyEncoderToBody(log, body.Target())
 	case domain.Function:
 		return body.Map(func(target interface{}) interface{} {
 			return applyEncoderToBody(log, target)
@@ -58,25 +77,53 @@ func applyEncoderToValue(log restql.Logger, value interface{}) interface{} {
 	switch value := value.(type) {
 	case domain.Base64:
 		target := value.Target()
-		if _, ok := target.(domain.Chain); ok {
+		if isUnresolved(target) {
 			return value
 		}
 
 		return applyBase64encoder(applyEncoderToValue(log, value.Target()))
 	case domain.JSON:
 		target := value.Target()
-		if _, ok := target.(domain.Chain); ok {
+		if isUnresolved(target) {
 			return value
 		}
 
 		return applyJSONEncoder(log, applyEncoderToValue(log, value.Target()))
 	case domain.Flatten:
 		target := value.Target()
-		if _, ok := target.(domain.Chain); ok {
+		if isUnresolved(target) {
 			return value
 		}
 
 		return applyFlattenEncoder(log, applyEncoderToValue(log, value.Target()))
+	case domain.Delimited:
+		target := value.Target()
+		if isUnresolved(target) {
+			return value
+		}
+
+		return applyDelimitedEncoder(log, applyEncoderToValue(log, target), value.Separator)
+	case domain.Repeated:
+		target := value.Target()
+		if isUnresolved(target) {
+			return value
+		}
+
+		return domain.NoMultiplex{Value: applyEncoderToValue(log, target)}
+	case domain.DeepObject:
+		target := value.Target()
+		if isUnresolved(target) {
+			return value
+		}
+
+		return applyEncoderToValue(log, target)
+	case domain.Expression:
+		target := value.Target()
+		if isUnresolved(target) {
+			return value
+		}
+
+		return applyExpression(log, value, applyEncoderToValue(log, target))
 	case domain.Function:
 		return value.Map(func(target interface{}) interface{} {
 			return applyEncoderToValue(log, target)
@@ -98,6 +145,15 @@ func applyEncoderToValue(log restql.Logger, value interface{}) interface{} {
 	}
 }
 
+func isUnresolved(value interface{}) bool {
+	switch value.(type) {
+	case domain.Chain, domain.Range:
+		return true
+	default:
+		return false
+	}
+}
+
 func applyJSONEncoder(log restql.Logger, value interface{}) interface{} {
 	data, err := json.Marshal(value)
 	if err != nil {
@@ -107,6 +163,35 @@ func applyJSONEncoder(log restql.Logger, value interface{}) interface{} {
 	return string(data)
 }
 
+// applyExpression transforms the value by the expression,
+// or each of its elements when it is a list, since lists are
+// multiplexed into a request for each element. Missing
+// chained values are kept, to be reported as such.
+func applyExpression(log restql.Logger, e domain.Expression, value interface{}) interface{} {
+	var transform func(v interface{}) interface{}
+	transform = func(v interface{}) interface{} {
+		if list, ok := v.([]interface{}); ok {
+			result := make([]interface{}, len(list))
+			for i, item := range list {
+				result[i] = transform(item)
+			}
+			return result
+		}
+		if v == EmptyChained {
+			return v
+		}
+
+		result, err := e.Program.Eval(v, e.Variables)
+		if err != nil {
+			log.Debug("failed to apply expression", "expression", e.Program.String(), "target", v, "error", err)
+			return nil
+		}
+		return result
+	}
+
+	return transform(value)
+}
+
 func applyBase64encoder(value interface{}) interface{} {
 	data := []byte(fmt.Sprintf("%v", value))
 	return base64.StdEncoding.EncodeToString(data)
@@ -121,6 +206,61 @@ func applyFlattenEncoder(log restql.Logger, value interface{}) interface{} {
 	return value
 }
 
+func applyDelimitedEncoder(log restql.Logger, value interface{}, separator string) interface{} {
+	list, ok := value.([]interface{})
+	if !ok {
+		log.Warn("delimited encoder used on non list value", "value", value)
+		return value
+	}
+
+	items := make([]string, 0, len(list))
+	for _, v := range list {
+		if v == nil {
+			continue
+		}
+		items = append(items, formatDelimitedItem(v))
+	}
+
+	return strings.Join(items, separator)
+}
+
+func formatDelimitedItem(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case bool:
+		return strconv.FormatBool(value)
+	case int:
+		return strconv.Itoa(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprintf("%v", value)
+		}
+		return string(data)
+	}
+}
+
+// applyDeepObjectEncoder writes each field of the value as a
+// parameter of its own, with the nested keys and list indexes
+// in brackets after the parameter name, like `filter[size][0]`.
+func applyDeepObjectEncoder(key string, value interface{}, params map[string]interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			applyDeepObjectEncoder(key+"["+k+"]", v, params)
+		}
+	case []interface{}:
+		for i, v := range value {
+			applyDeepObjectEncoder(key+"["+strconv.Itoa(i)+"]", v, params)
+		}
+	default:
+		params[key] = value
+	}
+}
+
 func flatten(ii []interface{}) []interface{} {
 	var res []interface{}
 	for _, i := range ii {
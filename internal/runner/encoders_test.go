@@ -245,6 +245,130 @@ func TestApplyEncoders(t *testing.T) {
 				}},
 			}},
 		},
+		{
+			"should apply delimited encoders to list values",
+			domain.Resources{"hero": domain.Statement{
+				Method:   "from",
+				Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"ids":    domain.Delimited{Value: []interface{}{"1", float64(2), 3, true, nil}, Separator: domain.CSVSeparator},
+					"colors": domain.Delimited{Value: []interface{}{"red", "blue"}, Separator: domain.PipeSeparator},
+					"name":   domain.Delimited{Value: "batman", Separator: domain.CSVSeparator},
+				}},
+			}},
+			domain.Resources{"hero": domain.Statement{
+				Method:   "from",
+				Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"ids":    "1,2,3,true",
+					"colors": "red|blue",
+					"name":   "batman",
+				}},
+			}},
+		},
+		{
+			"should apply repeated encoder disabling multiplexing of list value",
+			domain.Resources{"hero": domain.Statement{
+				Method:   "from",
+				Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"ids": domain.Repeated{Value: []interface{}{"1", "2"}},
+				}},
+			}},
+			domain.Resources{"hero": domain.Statement{
+				Method:   "from",
+				Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"ids": domain.NoMultiplex{Value: []interface{}{"1", "2"}},
+				}},
+			}},
+		},
+		{
+			"should apply deep object encoder expanding object fields into parameters",
+			domain.Resources{"hero": domain.Statement{
+				Method:   "from",
+				Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"filter": domain.DeepObject{Value: map[string]interface{}{
+						"color": "red",
+						"size":  map[string]interface{}{"min": 1, "max": 10},
+						"tags":  []interface{}{"a", domain.Delimited{Value: []interface{}{"b", "c"}, Separator: domain.CSVSeparator}},
+					}},
+					"page":  domain.DeepObject{Value: 1},
+					"chain": domain.DeepObject{Value: domain.Chain{"done-resource", "filter"}},
+				}},
+			}},
+			domain.Resources{"hero": domain.Statement{
+				Method:   "from",
+				Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"filter[color]":     "red",
+					"filter[size][min]": 1,
+					"filter[size][max]": 10,
+					"filter[tags][0]":   "a",
+					"filter[tags][1]":   "b,c",
+					"page":              1,
+					"chain":             domain.DeepObject{Value: domain.Chain{"done-resource", "filter"}},
+				}},
+			}},
+		},
+		{
+			"should apply expression to with value before encoders",
+			domain.Resources{"hero": domain.Statement{
+				Method:   "from",
+				Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"name": domain.Base64{Value: domain.Expression{
+						Value:     "Batman",
+						Program:   test.Program("lower(value) + '-' + $suffix"),
+						Variables: map[string]interface{}{"suffix": "dc"},
+					}},
+				}},
+			}},
+			domain.Resources{"hero": domain.Statement{
+				Method:   "from",
+				Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"name": "YmF0bWFuLWRj",
+				}},
+			}},
+		},
+		{
+			"should apply expression to each element of list value",
+			domain.Resources{"hero": domain.Statement{
+				Method:   "from",
+				Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"id":   domain.Expression{Value: []interface{}{1, 2, runner.EmptyChained}, Program: test.Program("value * 10")},
+					"name": domain.Expression{Value: domain.Chain{"done-resource", "name"}, Program: test.Program("upper(value)")},
+				}},
+			}},
+			domain.Resources{"hero": domain.Statement{
+				Method:   "from",
+				Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"id":   []interface{}{float64(10), float64(20), runner.EmptyChained},
+					"name": domain.Expression{Value: domain.Chain{"done-resource", "name"}, Program: test.Program("upper(value)")},
+				}},
+			}},
+		},
+		{
+			"should set with value to null when expression fails",
+			domain.Resources{"hero": domain.Statement{
+				Method:   "from",
+				Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"name": domain.Expression{Value: 10, Program: test.Program("lower(value)")},
+				}},
+			}},
+			domain.Resources{"hero": domain.Statement{
+				Method:   "from",
+				Resource: "hero",
+				With: domain.Params{Values: map[string]interface{}{
+					"name": nil,
+				}},
+			}},
+		},
 	}
 
 	logger := noOpLogger{}
@@ -265,3 +389,5 @@ func (n noOpLogger) Warn(msg string, fields ...interface{})             {}
 func (n noOpLogger) Info(msg string, fields ...interface{})             {}
 func (n noOpLogger) Debug(msg string, fields ...interface{})            {}
 func (n noOpLogger) With(key string, value interface{}) restql.Logger   { return n }
+func (n noOpLogger) WithFields(fields ...interface{}) restql.Logger     { return n }
+func (n noOpLogger) Enabled(level restql.Level) bool                    { return false }
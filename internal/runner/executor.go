@@ -2,6 +2,8 @@ package runner
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"sync"
 	"time"
 
@@ -13,19 +15,30 @@ import (
 // by executing the relevant HTTP calls to
 // the upstream dependency.
 type Executor struct {
-	client          domain.HTTPClient
-	log             restql.Logger
-	resourceTimeout time.Duration
-	forwardPrefix   string
+	client           domain.HTTPClient
+	responseCache    domain.ResponseCache
+	rateLimiter      domain.RateLimiter
+	bulkhead         domain.Bulkhead
+	log              restql.Logger
+	resourceTimeout  time.Duration
+	forwardPrefix    string
+	requestIDHeaders []string
 }
 
 // NewExecutor constructs an instance of Executor.
-func NewExecutor(log restql.Logger, client domain.HTTPClient, resourceTimeout time.Duration, forwardPrefix string) Executor {
-	return Executor{client: client, log: log, resourceTimeout: resourceTimeout, forwardPrefix: forwardPrefix}
+func NewExecutor(log restql.Logger, client domain.HTTPClient, responseCache domain.ResponseCache, rateLimiter domain.RateLimiter, bulkhead domain.Bulkhead, resourceTimeout time.Duration, forwardPrefix string, requestIDHeaders []string) Executor {
+	return Executor{client: client, responseCache: responseCache, rateLimiter: rateLimiter, bulkhead: bulkhead, log: log, resourceTimeout: resourceTimeout, forwardPrefix: forwardPrefix, requestIDHeaders: requestIDHeaders}
 }
 
 // DoStatement process a single statement into a result by executing the relevant HTTP calls to the upstream dependency.
 func (e Executor) DoStatement(ctx context.Context, statement domain.Statement, queryCtx restql.QueryContext) restql.DoneResource {
+	dr := e.doStatement(ctx, statement, queryCtx)
+	dr = applyDefaultValue(restql.GetLogger(ctx), statement, dr)
+	dr.ReturnedHeaders = selectReturnedHeaders(statement, dr)
+	return dr
+}
+
+func (e Executor) doStatement(ctx context.Context, statement domain.Statement, queryCtx restql.QueryContext) restql.DoneResource {
 	log := restql.GetLogger(ctx)
 
 	drOptions := DoneResourceOptions{
@@ -41,26 +54,264 @@ func (e Executor) DoStatement(ctx context.Context, statement domain.Statement, q
 		return emptyChainedResponse
 	}
 
+	if statement.RejectedRequests > 0 {
+		log.Debug("request execution rejected due to multiplexed requests limit", "resource", statement.Resource, "method", statement.Method, "requests", statement.RejectedRequests)
+		return NewMultiplexLimitResponse(log, statement.RejectedRequests, statement.MaxMultiplexedRequests, drOptions)
+	}
+
+	if subquery, ok := domain.ParseSubquery(statement.Resource); ok {
+		return e.doSubquery(ctx, subquery, statement, queryCtx, drOptions)
+	}
+
+	if _, mapped := queryCtx.Mappings[statement.Resource]; statement.Mock != nil && (statement.Mocked || !mapped) {
+		return e.doMock(ctx, statement, queryCtx, drOptions)
+	}
+
 	request := MakeRequest(e.resourceTimeout, e.forwardPrefix, statement, queryCtx)
 
+	cacheTTL, cacheable := statementCacheTTL(statement)
+	cacheable = cacheable && e.responseCache != nil
+	var cacheKey string
+	if cacheable {
+		cacheKey = statementCacheKey(request)
+	}
+
+	setRequestIDHeaders(ctx, request.Headers, e.requestIDHeaders)
+
+	var timeline *restql.StatementTimeline
+	if timelineEnabled(ctx) {
+		timeline = &restql.StatementTimeline{}
+		ctx = withStatementTimeline(ctx, timeline)
+		request.Trace = true
+	}
+
 	log.Debug("executing request for statement", "resource", statement.Resource, "method", statement.Method, "request", request)
 
-	response, err := e.client.Do(ctx, request)
+	if subscriptions := getSubscriptions(ctx); subscriptions != nil && statement.Subscribed && statement.Method == domain.FromMethod {
+		return subscriptions.subscribe(ctx, statement, request, drOptions)
+	}
+
+	if cacheable {
+		if cached, found := e.responseCache.Get(cacheKey); found {
+			return e.doCached(ctx, statement, request, cached, drOptions)
+		}
+		recordCache(ctx, restql.ResponseCacheMiss)
+	}
+
+	if e.rateLimiter != nil {
+		if allowed, wait := e.rateLimiter.AllowResource(ctx, queryCtx.Options.Tenant, statement.Resource); !allowed {
+			log.Debug("request execution rejected due to resource rate limit", "resource", statement.Resource, "method", statement.Method, "retryAfter", wait)
+			return NewRateLimitResponse(log, statement.Resource, wait, drOptions)
+		}
+	}
+
+	if e.bulkhead != nil {
+		release, acquired := e.bulkhead.AcquireResource(ctx, queryCtx.Options.Tenant, statement.Resource)
+		if !acquired {
+			log.Debug("request execution rejected due to resource bulkhead", "resource", statement.Resource, "method", statement.Method)
+			return NewBulkheadRejectedResponse(log, statement.Resource, drOptions)
+		}
+		defer release()
+	}
+
+	if statement.Signing != nil {
+		ctx = domain.WithRequestSigning(ctx, statement.Signing)
+	}
+
+	if statement.Proxy != nil {
+		ctx = domain.WithOutboundProxy(ctx, statement.Proxy)
+	}
+
+	if statement.TLS != nil {
+		ctx = domain.WithUpstreamTLS(ctx, statement.TLS)
+	}
+
+	if statement.SessionCookies {
+		if jar, ok := getSessionJar(ctx, statement.Resource); ok {
+			ctx = domain.WithCookieJar(ctx, jar)
+		}
+	}
+
+	start := time.Now()
+	restql.PublishEvent(ctx, restql.StatementStartedEvent{Resource: statement.Resource, Method: statement.Method, URL: request.Schema + "://" + request.Host + request.Path, At: start})
+
+	var response restql.HTTPResponse
+	var err error
+	if dedup := getRequestDeduplication(ctx); dedup != nil {
+		response, err = dedup.do(ctx, request, func(ctx context.Context) (restql.HTTPResponse, error) { return e.doRequest(ctx, statement, request) })
+	} else {
+		response, err = e.doRequest(ctx, statement, request)
+	}
+	var cacheOutcome string
+	if cacheable {
+		cacheOutcome = restql.ResponseCacheMiss
+	}
+	if err == nil && statement.ForwardConditionalHeaders {
+		response, cacheOutcome, err = e.revalidate(ctx, statement, request, response)
+	}
+
+	var target string
+	if len(statement.FailoverURLs) > 0 {
+		request, response, target, err = e.failover(ctx, statement, queryCtx, request, response, err)
+	}
+
 	if err != nil {
 		errorResponse := NewErrorResponse(log, err, request, response, drOptions)
+		errorResponse.Target = target
+		errorResponse.Timeline = finishTimeline(timeline, response)
 		log.Debug("request execution failed", "error", err, "resource", statement.Resource, "method", statement.Method, "response", errorResponse)
+		publishStatementFinished(ctx, statement, response, false, cacheOutcome, start)
 		return errorResponse
 	}
 
+	if cacheable && response.StatusCode >= 200 && response.StatusCode < 300 {
+		e.responseCache.SetWithTTL(cacheKey, statement.Resource, response, cacheTTL)
+	}
+
 	dr := NewDoneResource(request, response, drOptions)
+	dr.Target = target
+	dr.Timeline = finishTimeline(timeline, response)
+	dr = validateResponse(log, statement, dr)
+	dr = normalizeResponse(log, statement, dr)
 
 	log.Debug("request execution done", "resource", statement.Resource, "method", statement.Method, "response", dr)
+	publishStatementFinished(ctx, statement, response, dr.Success, cacheOutcome, start)
 
 	return dr
 }
 
+func publishStatementFinished(ctx context.Context, statement domain.Statement, response restql.HTTPResponse, success bool, cacheOutcome string, start time.Time) {
+	restql.PublishEvent(ctx, restql.StatementFinishedEvent{
+		Resource:   statement.Resource,
+		Method:     statement.Method,
+		URL:        response.URL,
+		StatusCode: response.StatusCode,
+		Success:    success,
+		Duration:   time.Since(start),
+		Cache:      cacheOutcome,
+	})
+}
+
+func (e Executor) doRequest(ctx context.Context, statement domain.Statement, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	log := restql.GetLogger(ctx)
+
+	recordAttempt(ctx)
+	response, err := e.doUpstream(ctx, statement, request, 0)
+	retries := allowedRetries(statement)
+	for attempt := 1; ctx.Err() == nil; attempt++ {
+		switch {
+		case err == nil:
+			if !isThrottled(response) {
+				return response, nil
+			}
+
+			wait, retry := throttledRetry(ctx, response, attempt <= retries)
+			restql.PublishEvent(ctx, restql.UpstreamThrottledEvent{Resource: statement.Resource, Method: statement.Method, URL: response.URL, StatusCode: response.StatusCode, RetryAfter: wait, Retried: retry})
+			if !retry || !waitRetryAfter(ctx, wait) {
+				return response, nil
+			}
+
+			log.Debug("retrying request throttled by upstream", "resource", statement.Resource, "method", statement.Method, "attempt", attempt, "retryAfter", wait)
+			restql.PublishEvent(ctx, restql.RequestRetryEvent{Resource: statement.Resource, Method: statement.Method, Attempt: attempt, Err: domain.ErrUpstreamThrottled})
+		case isBodyLimitError(err) || attempt > retries:
+			return response, err
+		default:
+			log.Debug("retrying request for statement", "resource", statement.Resource, "method", statement.Method, "attempt", attempt, "error", err)
+			restql.PublishEvent(ctx, restql.RequestRetryEvent{Resource: statement.Resource, Method: statement.Method, Attempt: attempt, Err: err})
+		}
+
+		recordAttempt(ctx)
+		response, err = e.doUpstream(ctx, statement, request, attempt)
+	}
+
+	return response, err
+}
+
+// isBodyLimitError reports if the upstream response was refused
+// for its body, which would be the same on another attempt.
+func isBodyLimitError(err error) bool {
+	return errors.Is(err, domain.ErrResponseTooLarge) || errors.Is(err, domain.ErrResponseTooComplex)
+}
+
+// revalidate handles the upstream response to a request carrying the
+// client conditional headers. A 304 Not Modified is replaced by the
+// cached response for the URL, which is populated by every successful
+// response with a validator. If there is no cached response the request
+// is done again without the conditional headers. It also returns if
+// the cached response was used, as restql.ResponseCacheHit, or not.
+func (e Executor) revalidate(ctx context.Context, statement domain.Statement, request restql.HTTPRequest, response restql.HTTPResponse) (restql.HTTPResponse, string, error) {
+	if e.responseCache == nil {
+		return response, "", nil
+	}
+
+	log := restql.GetLogger(ctx)
+	key := request.Method + " " + response.URL
+	recordCache(ctx, restql.ResponseCacheMiss)
+
+	if response.StatusCode == http.StatusNotModified {
+		cached, found := e.responseCache.Get(key)
+		if found {
+			log.Debug("upstream response not modified, using cached response", "resource", statement.Resource, "url", response.URL)
+			recordCache(ctx, restql.ResponseCacheHit)
+			restql.PublishEvent(ctx, restql.ResponseCacheHitEvent{Resource: statement.Resource, URL: response.URL})
+			return mergeNotModified(cached, response), restql.ResponseCacheHit, nil
+		}
+
+		log.Debug("upstream response not modified but not cached, requesting again", "resource", statement.Resource, "url", response.URL)
+
+		var err error
+		response, err = e.doRequest(ctx, statement, removeConditionalHeaders(request))
+		if err != nil {
+			return response, restql.ResponseCacheMiss, err
+		}
+	}
+
+	if response.StatusCode == http.StatusOK && hasValidator(response.Headers) {
+		e.responseCache.Set(key, statement.Resource, response)
+	}
+
+	return response, restql.ResponseCacheMiss, nil
+}
+
+// finishTimeline sets on the statement timeline the
+// timings of the HTTP call that produced the response.
+func finishTimeline(timeline *restql.StatementTimeline, response restql.HTTPResponse) *restql.StatementTimeline {
+	if timeline == nil {
+		return nil
+	}
+
+	timeline.HTTP = response.Timings
+	return timeline
+}
+
+// allowedRetries returns how many times a failed request can be
+// retried, which is only allowed for idempotent methods.
+func allowedRetries(statement domain.Statement) int {
+	if statement.HTTPMethod != "" {
+		switch statement.HTTPMethod {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+			return statement.Retries
+		default:
+			return 0
+		}
+	}
+
+	switch statement.Method {
+	case domain.FromMethod, domain.IntoMethod, domain.DeleteMethod:
+		return statement.Retries
+	default:
+		return 0
+	}
+}
+
 // DoMultiplexedStatement process multiplexed statements into a result by executing the relevant HTTP calls to the upstream dependency.
+// The responses keep the order of the statements, regardless of the order in which the calls are completed.
 func (e Executor) DoMultiplexedStatement(ctx context.Context, statements []interface{}, queryCtx restql.QueryContext) restql.DoneResources {
+	onDone := getMultiplexedObserver(ctx)
+	if onDone != nil {
+		ctx = withMultiplexedObserver(ctx, nil)
+	}
+
 	responseChans := make([]chan interface{}, len(statements))
 	for i := range responseChans {
 		responseChans[i] = make(chan interface{}, 1)
@@ -75,6 +326,9 @@ func (e Executor) DoMultiplexedStatement(ctx context.Context, statements []inter
 
 		go func() {
 			response := e.doCurrentStatement(ctx, stmt, queryCtx)
+			if onDone != nil {
+				onDone(i, response)
+			}
 			ch <- response
 			wg.Done()
 		}()
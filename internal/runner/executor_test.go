@@ -0,0 +1,623 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+type stubClient struct {
+	responses []restql.HTTPResponse
+	requests  []restql.HTTPRequest
+}
+
+func (c *stubClient) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	c.requests = append(c.requests, request)
+	response := c.responses[0]
+	c.responses = c.responses[1:]
+	return response, nil
+}
+
+type stubResponseCache map[string]restql.HTTPResponse
+
+func (c stubResponseCache) Get(key string) (restql.HTTPResponse, bool) {
+	r, found := c[key]
+	return r, found
+}
+
+func (c stubResponseCache) Set(key string, resource string, response restql.HTTPResponse) {
+	c[key] = response
+}
+
+func (c stubResponseCache) SetWithTTL(key string, resource string, response restql.HTTPResponse, ttl time.Duration) {
+	c[key] = response
+}
+
+func TestExecutorRevalidation(t *testing.T) {
+	url := "http://hero.io/api"
+	key := http.MethodGet + " " + url
+
+	cachedResponse := restql.HTTPResponse{
+		URL:        url,
+		StatusCode: http.StatusOK,
+		Headers:    restql.Headers{"Etag": `"abc"`},
+		Body:       restql.NewResponseBodyFromValue(test.NoOpLogger, map[string]interface{}{"id": "1"}),
+	}
+	notModified := restql.HTTPResponse{URL: url, StatusCode: http.StatusNotModified, Headers: restql.Headers{"Etag": `"abc"`}}
+
+	tests := []struct {
+		name              string
+		cache             stubResponseCache
+		responses         []restql.HTTPResponse
+		expectedStatus    int
+		expectedRequests  int
+		expectedCachedKey bool
+		expectedCache     string
+	}{
+		{
+			"should use cached response when upstream is not modified",
+			stubResponseCache{key: cachedResponse},
+			[]restql.HTTPResponse{notModified},
+			http.StatusOK,
+			1,
+			true,
+			restql.ResponseCacheHit,
+		},
+		{
+			"should request again without conditional headers when response is not cached",
+			stubResponseCache{},
+			[]restql.HTTPResponse{notModified, cachedResponse},
+			http.StatusOK,
+			2,
+			true,
+			restql.ResponseCacheMiss,
+		},
+		{
+			"should not cache response without validator",
+			stubResponseCache{},
+			[]restql.HTTPResponse{{URL: url, StatusCode: http.StatusOK}},
+			http.StatusOK,
+			1,
+			false,
+			restql.ResponseCacheMiss,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &stubClient{responses: tt.responses}
+			executor := runner.NewExecutor(test.NoOpLogger, client, tt.cache, nil, nil, 0, "", nil)
+
+			statement := domain.Statement{Method: domain.FromMethod, Resource: "hero", ForwardConditionalHeaders: true}
+			queryCtx := restql.QueryContext{
+				Mappings: map[string]restql.Mapping{"hero": mapping(t, url)},
+				Input:    restql.QueryInput{Headers: map[string]string{"If-None-Match": `"abc"`}},
+			}
+
+			var cacheOutcome string
+			unsubscribe := restql.SubscribeEvents(func(ctx context.Context, event restql.Event) {
+				if finished, ok := event.(restql.StatementFinishedEvent); ok {
+					cacheOutcome = finished.Cache
+				}
+			})
+			defer unsubscribe()
+
+			ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+			got := executor.DoStatement(ctx, statement, queryCtx)
+
+			test.Equal(t, got.Status, tt.expectedStatus)
+			test.Equal(t, cacheOutcome, tt.expectedCache)
+			test.Equal(t, len(client.requests), tt.expectedRequests)
+
+			_, cached := tt.cache[key]
+			test.Equal(t, cached, tt.expectedCachedKey)
+
+			last := client.requests[len(client.requests)-1]
+			_, conditional := last.Headers["If-None-Match"]
+			test.Equal(t, conditional, tt.expectedRequests == 1)
+		})
+	}
+}
+
+func TestExecutorFailover(t *testing.T) {
+	unavailable := restql.HTTPResponse{StatusCode: http.StatusServiceUnavailable}
+	ok := restql.HTTPResponse{StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, map[string]interface{}{"id": "1"})}
+
+	tests := []struct {
+		name             string
+		responses        []restql.HTTPResponse
+		statusCodes      []int
+		expectedStatus   int
+		expectedTarget   string
+		expectedRequests []string
+	}{
+		{
+			"should not fail over when primary succeeds",
+			[]restql.HTTPResponse{ok},
+			[]int{http.StatusServiceUnavailable},
+			http.StatusOK,
+			runner.PrimaryTarget,
+			[]string{"primary.hero.io"},
+		},
+		{
+			"should fail over on configured status code",
+			[]restql.HTTPResponse{unavailable, ok},
+			[]int{http.StatusServiceUnavailable},
+			http.StatusOK,
+			"failover-1",
+			[]string{"primary.hero.io", "secondary.hero.io"},
+		},
+		{
+			"should try every failover url in order",
+			[]restql.HTTPResponse{unavailable, unavailable, ok},
+			[]int{http.StatusServiceUnavailable},
+			http.StatusOK,
+			"failover-2",
+			[]string{"primary.hero.io", "secondary.hero.io", "tertiary.hero.io"},
+		},
+		{
+			"should not fail over on status code not configured",
+			[]restql.HTTPResponse{unavailable},
+			nil,
+			http.StatusServiceUnavailable,
+			runner.PrimaryTarget,
+			[]string{"primary.hero.io"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &stubClient{responses: tt.responses}
+			executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, 0, "", nil)
+
+			statement := domain.Statement{
+				Method:              domain.FromMethod,
+				Resource:            "hero",
+				FailoverURLs:        []string{"http://secondary.hero.io/api", "http://tertiary.hero.io/api"},
+				FailoverStatusCodes: tt.statusCodes,
+			}
+			queryCtx := restql.QueryContext{
+				Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://primary.hero.io/api")},
+			}
+
+			ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+			got := executor.DoStatement(ctx, statement, queryCtx)
+
+			test.Equal(t, got.Status, tt.expectedStatus)
+			test.Equal(t, got.Target, tt.expectedTarget)
+
+			hosts := make([]string, len(client.requests))
+			for i, r := range client.requests {
+				hosts[i] = r.Host
+			}
+			test.Equal(t, hosts, tt.expectedRequests)
+			test.Equal(t, queryCtx.Mappings["hero"].Host(), "primary.hero.io")
+		})
+	}
+}
+
+func TestExecutorMultiplexLimit(t *testing.T) {
+	client := &stubClient{}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, 0, "", nil)
+
+	statement := domain.Statement{
+		Method:                 domain.FromMethod,
+		Resource:               "hero",
+		With:                   domain.Params{Values: map[string]interface{}{"id": []interface{}{"1", "2", "3"}}},
+		MaxMultiplexedRequests: 2,
+		RejectedRequests:       3,
+	}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+	}
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	got := executor.DoStatement(ctx, statement, queryCtx)
+
+	test.Equal(t, got.Status, http.StatusRequestEntityTooLarge)
+	test.Equal(t, got.Success, false)
+	test.Equal(t, got.ResponseBody.Unmarshal(), "The request was rejected as its list parameters expand into 3 requests, exceeding the limit of 2")
+	test.Equal(t, len(client.requests), 0)
+}
+
+type stubRateLimiter struct {
+	allowed bool
+	keys    []string
+}
+
+func (s *stubRateLimiter) AllowResource(ctx context.Context, tenant string, resource string) (bool, time.Duration) {
+	s.keys = append(s.keys, tenant+":"+resource)
+	return s.allowed, 1500 * time.Millisecond
+}
+
+func TestExecutorRateLimit(t *testing.T) {
+	client := &stubClient{}
+	limiter := &stubRateLimiter{allowed: false}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, limiter, nil, 0, "", nil)
+
+	statement := domain.Statement{Method: domain.FromMethod, Resource: "hero"}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+		Options:  restql.QueryOptions{Tenant: "DEFAULT"},
+	}
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	got := executor.DoStatement(ctx, statement, queryCtx)
+
+	test.Equal(t, got.Status, http.StatusTooManyRequests)
+	test.Equal(t, got.Success, false)
+	test.Equal(t, got.ResponseHeaders, map[string]string{"Retry-After": "2"})
+	test.Equal(t, got.ResponseBody.Unmarshal(), "The request was rejected as it exceeds the rate limit of resource hero")
+	test.Equal(t, limiter.keys, []string{"DEFAULT:hero"})
+	test.Equal(t, len(client.requests), 0)
+}
+
+func TestExecutorStatementCache(t *testing.T) {
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+		Options:  restql.QueryOptions{Tenant: "DEFAULT"},
+	}
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+
+	newResponse := func(name string) restql.HTTPResponse {
+		return restql.HTTPResponse{URL: "http://hero.io/api", StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, name)}
+	}
+
+	t.Run("should reuse cached result of the same request", func(t *testing.T) {
+		client := &stubClient{responses: []restql.HTTPResponse{newResponse("batman"), newResponse("superman")}}
+		executor := runner.NewExecutor(test.NoOpLogger, client, stubResponseCache{}, nil, nil, 0, "", nil)
+		statement := domain.Statement{Method: domain.FromMethod, Resource: "hero", Cache: 60, With: domain.Params{Values: map[string]interface{}{"id": 1}}}
+
+		first := executor.DoStatement(ctx, statement, queryCtx)
+		second := executor.DoStatement(ctx, statement, queryCtx)
+
+		test.Equal(t, first.ResponseBody.Unmarshal(), "batman")
+		test.Equal(t, second.ResponseBody.Unmarshal(), "batman")
+		test.Equal(t, second.Status, http.StatusOK)
+		test.Equal(t, len(client.requests), 1)
+	})
+
+	t.Run("should cache requests with different parameters apart", func(t *testing.T) {
+		client := &stubClient{responses: []restql.HTTPResponse{newResponse("batman"), newResponse("superman")}}
+		executor := runner.NewExecutor(test.NoOpLogger, client, stubResponseCache{}, nil, nil, 0, "", nil)
+		batman := domain.Statement{Method: domain.FromMethod, Resource: "hero", Cache: 60, With: domain.Params{Values: map[string]interface{}{"id": 1}}}
+		superman := domain.Statement{Method: domain.FromMethod, Resource: "hero", Cache: 60, With: domain.Params{Values: map[string]interface{}{"id": 2}}}
+
+		executor.DoStatement(ctx, batman, queryCtx)
+		got := executor.DoStatement(ctx, superman, queryCtx)
+
+		test.Equal(t, got.ResponseBody.Unmarshal(), "superman")
+		test.Equal(t, len(client.requests), 2)
+	})
+
+	t.Run("should always request statements with no cache", func(t *testing.T) {
+		client := &stubClient{responses: []restql.HTTPResponse{newResponse("batman"), newResponse("superman")}}
+		executor := runner.NewExecutor(test.NoOpLogger, client, stubResponseCache{}, nil, nil, 0, "", nil)
+		statement := domain.Statement{Method: domain.FromMethod, Resource: "hero", Cache: 60, NoCache: true}
+
+		executor.DoStatement(ctx, statement, queryCtx)
+		got := executor.DoStatement(ctx, statement, queryCtx)
+
+		test.Equal(t, got.ResponseBody.Unmarshal(), "superman")
+		test.Equal(t, len(client.requests), 2)
+	})
+
+	t.Run("should not cache failed responses", func(t *testing.T) {
+		failed := restql.HTTPResponse{URL: "http://hero.io/api", StatusCode: http.StatusInternalServerError, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, "error")}
+		client := &stubClient{responses: []restql.HTTPResponse{failed, newResponse("batman")}}
+		executor := runner.NewExecutor(test.NoOpLogger, client, stubResponseCache{}, nil, nil, 0, "", nil)
+		statement := domain.Statement{Method: domain.FromMethod, Resource: "hero", Cache: 60}
+
+		executor.DoStatement(ctx, statement, queryCtx)
+		got := executor.DoStatement(ctx, statement, queryCtx)
+
+		test.Equal(t, got.ResponseBody.Unmarshal(), "batman")
+		test.Equal(t, len(client.requests), 2)
+	})
+}
+
+type stubBulkhead struct {
+	acquired bool
+	released int
+}
+
+func (s *stubBulkhead) AcquireResource(ctx context.Context, tenant string, resource string) (func(), bool) {
+	if !s.acquired {
+		return nil, false
+	}
+	return func() { s.released++ }, true
+}
+
+func TestExecutorBulkhead(t *testing.T) {
+	statement := domain.Statement{Method: domain.FromMethod, Resource: "hero"}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+		Options:  restql.QueryOptions{Tenant: "DEFAULT"},
+	}
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+
+	t.Run("should reject request when bulkhead is saturated", func(t *testing.T) {
+		client := &stubClient{}
+		executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, &stubBulkhead{acquired: false}, 0, "", nil)
+
+		got := executor.DoStatement(ctx, statement, queryCtx)
+
+		test.Equal(t, got.Status, runner.BulkheadRejectedStatus)
+		test.Equal(t, got.Success, false)
+		test.Equal(t, got.ResponseBody.Unmarshal(), "The request was rejected as resource hero has too many requests in flight")
+		test.Equal(t, len(client.requests), 0)
+	})
+
+	t.Run("should release slot once request is done", func(t *testing.T) {
+		client := &stubClient{responses: []restql.HTTPResponse{{StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, "ok")}}}
+		bh := &stubBulkhead{acquired: true}
+		executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, bh, 0, "", nil)
+
+		got := executor.DoStatement(ctx, statement, queryCtx)
+
+		test.Equal(t, got.Status, http.StatusOK)
+		test.Equal(t, bh.released, 1)
+	})
+}
+
+func TestExecutorRequestIDHeaders(t *testing.T) {
+	client := &stubClient{responses: []restql.HTTPResponse{{URL: "http://hero.io/api", StatusCode: http.StatusOK}}}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, 0, "", []string{"x-request-id", "X-Correlation-Id"})
+
+	statement := domain.Statement{Method: domain.FromMethod, Resource: "hero"}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+		Input:    restql.QueryInput{Headers: map[string]string{"X-Request-Id": "from-client"}},
+	}
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	ctx = restql.WithRequestID(ctx, "abc123")
+	executor.DoStatement(ctx, statement, queryCtx)
+
+	test.Equal(t, len(client.requests), 1)
+	test.Equal(t, client.requests[0].Headers, restql.Headers{
+		"Content-Type":     "application/json",
+		"X-Request-Id":     "abc123",
+		"X-Correlation-Id": "abc123",
+	})
+}
+
+func TestExecutorReturnHeaders(t *testing.T) {
+	response := restql.HTTPResponse{
+		URL:        "http://hero.io/api",
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"X-Total-Count": "42", "Set-Cookie": "session=abc"},
+	}
+	client := &stubClient{responses: []restql.HTTPResponse{response}}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, 0, "", nil)
+
+	statement := domain.Statement{Method: domain.FromMethod, Resource: "hero", ReturnHeaders: []string{"x-total-count", "Link"}}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+	}
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	got := executor.DoStatement(ctx, statement, queryCtx)
+
+	test.Equal(t, got.ReturnedHeaders, map[string]string{"x-total-count": "42"})
+}
+
+func TestExecutorNormalization(t *testing.T) {
+	upstreamBody := restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"data": {"result": {"hero_name": "batman", "_links": {}}}}`))
+	client := &stubClient{responses: []restql.HTTPResponse{{URL: "http://hero.io/api", StatusCode: http.StatusOK, Body: upstreamBody}}}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, 0, "", nil)
+
+	statement := domain.Statement{
+		Method:    domain.FromMethod,
+		Resource:  "hero",
+		Normalize: &domain.Normalization{Lift: "data.result", Drop: []string{"_links"}, Rename: map[string]string{"hero_name": "name"}},
+	}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+	}
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	got := executor.DoStatement(ctx, statement, queryCtx)
+
+	test.Equal(t, got.ResponseBody.Unmarshal(), test.Unmarshal(`{"name": "batman"}`))
+	test.Equal(t, upstreamBody.Unmarshal(), test.Unmarshal(`{"data": {"result": {"hero_name": "batman", "_links": {}}}}`))
+}
+
+func TestExecutorMock(t *testing.T) {
+	mock := &domain.Mock{Status: http.StatusCreated, Headers: map[string]string{"X-Mock": "yes"}, Body: []byte(`{"name": "batman"}`)}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+	}
+	upstream := restql.HTTPResponse{StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(`{"name": "robin"}`))}
+
+	tests := []struct {
+		name             string
+		statement        domain.Statement
+		expectedStatus   int
+		expectedBody     interface{}
+		expectedMocked   bool
+		expectedRequests int
+	}{
+		{
+			"should serve mock when selected",
+			domain.Statement{Method: domain.FromMethod, Resource: "hero", Mock: mock, Mocked: true},
+			http.StatusCreated,
+			test.Unmarshal(`{"name": "batman"}`),
+			true,
+			0,
+		},
+		{
+			"should serve mock when resource has no mapping",
+			domain.Statement{Method: domain.FromMethod, Resource: "villain", Mock: mock},
+			http.StatusCreated,
+			test.Unmarshal(`{"name": "batman"}`),
+			true,
+			0,
+		},
+		{
+			"should call upstream when mock is not selected",
+			domain.Statement{Method: domain.FromMethod, Resource: "hero", Mock: mock},
+			http.StatusOK,
+			test.Unmarshal(`{"name": "robin"}`),
+			false,
+			1,
+		},
+		{
+			"should time out when mock latency exceeds timeout",
+			domain.Statement{Method: domain.FromMethod, Resource: "hero", Timeout: 10, Mocked: true, Mock: &domain.Mock{Latency: time.Second}},
+			http.StatusRequestTimeout,
+			domain.ErrRequestTimeout.Error(),
+			true,
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &stubClient{responses: []restql.HTTPResponse{upstream}}
+			executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+
+			ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+			got := executor.DoStatement(ctx, tt.statement, queryCtx)
+
+			test.Equal(t, got.Status, tt.expectedStatus)
+			test.Equal(t, got.ResponseBody.Unmarshal(), tt.expectedBody)
+			test.Equal(t, got.Mocked, tt.expectedMocked)
+			test.Equal(t, len(client.requests), tt.expectedRequests)
+		})
+	}
+}
+
+type flakyClient struct {
+	failures int
+	response restql.HTTPResponse
+}
+
+func (c *flakyClient) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	if c.failures > 0 {
+		c.failures--
+		return restql.HTTPResponse{}, errors.New("connection reset")
+	}
+	return c.response, nil
+}
+
+func TestExecutorEvents(t *testing.T) {
+	var names []string
+	unsubscribe := restql.SubscribeEvents(func(ctx context.Context, event restql.Event) {
+		names = append(names, event.EventName())
+
+		if finished, ok := event.(restql.StatementFinishedEvent); ok {
+			test.Equal(t, finished.Resource, "hero")
+			test.Equal(t, finished.StatusCode, http.StatusOK)
+			test.Equal(t, finished.Success, true)
+		}
+	})
+	defer unsubscribe()
+
+	client := &flakyClient{failures: 1, response: restql.HTTPResponse{StatusCode: http.StatusOK, URL: "http://hero.io/api"}}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, 0, "", nil)
+
+	statement := domain.Statement{Method: domain.FromMethod, Resource: "hero", Retries: 1}
+	queryCtx := restql.QueryContext{Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")}}
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	executor.DoStatement(ctx, statement, queryCtx)
+
+	expected := []string{restql.StatementStartedEventName, restql.RequestRetryEventName, restql.StatementFinishedEventName}
+	test.Equal(t, names, expected)
+}
+
+func TestExecutorUpstreamThrottling(t *testing.T) {
+	url := "http://hero.io/api"
+	ok := restql.HTTPResponse{URL: url, StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, map[string]interface{}{"id": "1"})}
+	tooManyRequests := restql.HTTPResponse{URL: url, StatusCode: http.StatusTooManyRequests, Headers: restql.Headers{"Retry-After": "0"}}
+
+	tests := []struct {
+		name              string
+		retries           int
+		responses         []restql.HTTPResponse
+		expectedStatus    int
+		expectedThrottled bool
+		expectedRequests  int
+		expectedEvents    []bool
+	}{
+		{
+			"should retry after the wait asked by the upstream",
+			1,
+			[]restql.HTTPResponse{tooManyRequests, ok},
+			http.StatusOK,
+			false,
+			2,
+			[]bool{true},
+		},
+		{
+			"should report throttling when the retry budget is exhausted",
+			1,
+			[]restql.HTTPResponse{tooManyRequests, tooManyRequests},
+			http.StatusTooManyRequests,
+			true,
+			2,
+			[]bool{true, false},
+		},
+		{
+			"should not retry a 503 without Retry-After",
+			1,
+			[]restql.HTTPResponse{{URL: url, StatusCode: http.StatusServiceUnavailable}},
+			http.StatusServiceUnavailable,
+			false,
+			1,
+			nil,
+		},
+		{
+			"should honor a 503 with Retry-After",
+			1,
+			[]restql.HTTPResponse{{URL: url, StatusCode: http.StatusServiceUnavailable, Headers: restql.Headers{"Retry-After": "0"}}, ok},
+			http.StatusOK,
+			false,
+			2,
+			[]bool{true},
+		},
+		{
+			"should not retry a 429 without a retry budget",
+			0,
+			[]restql.HTTPResponse{tooManyRequests},
+			http.StatusTooManyRequests,
+			true,
+			1,
+			[]bool{false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []bool
+			unsubscribe := restql.SubscribeEvents(func(ctx context.Context, event restql.Event) {
+				if throttled, ok := event.(restql.UpstreamThrottledEvent); ok {
+					events = append(events, throttled.Retried)
+				}
+			})
+			defer unsubscribe()
+
+			client := &stubClient{responses: tt.responses}
+			executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, 0, "", nil)
+
+			statement := domain.Statement{Method: domain.FromMethod, Resource: "hero", Retries: tt.retries}
+			queryCtx := restql.QueryContext{Mappings: map[string]restql.Mapping{"hero": mapping(t, url)}}
+
+			ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+			got := executor.DoStatement(ctx, statement, queryCtx)
+
+			test.Equal(t, got.Status, tt.expectedStatus)
+			test.Equal(t, got.Throttled, tt.expectedThrottled)
+			test.Equal(t, len(client.requests), tt.expectedRequests)
+			test.Equal(t, events, tt.expectedEvents)
+		})
+	}
+}
@@ -44,7 +44,7 @@ func MultiplexStatements(resources domain.Resources) domain.Resources {
 func multiplex(statement domain.Statement) interface{} {
 	values := statement.With.Values
 	body := statement.With.Body
-	if values == nil && body == nil {
+	if (values == nil && body == nil) || statement.RejectedRequests > 0 {
 		return statement
 	}
 
@@ -57,6 +57,11 @@ func multiplex(statement domain.Statement) interface{} {
 		return statement
 	}
 
+	if count, exceeded := exceedsMultiplexLimit(statement); exceeded {
+		statement.RejectedRequests = count
+		return statement
+	}
+
 	statementsParameters := zipListParams(listParams)
 
 	result := make([]interface{}, len(statementsParameters))
@@ -78,6 +83,52 @@ func multiplex(statement domain.Statement) interface{} {
 	return result
 }
 
+// exceedsMultiplexLimit returns if the statement list parameters expand
+// into more requests than the statement allows, along with their count.
+// Statements exceeding it are not multiplexed, but marked with the count
+// of RejectedRequests, so the executor fails them without any request.
+func exceedsMultiplexLimit(statement domain.Statement) (uint64, bool) {
+	limit := statement.MaxMultiplexedRequests
+	if limit <= 0 {
+		return 0, false
+	}
+
+	listParams := getListParamsFromValues(statement.With.Values)
+	listParams = append(listParams, getListParamsFromBody(statement.With.Body)...)
+	if len(listParams) == 0 {
+		return 1, false
+	}
+
+	count := countMultiplexed(listParams, uint64(limit))
+	return count, count > uint64(limit)
+}
+
+// countMultiplexed returns how many requests multiplexing the list
+// parameters results in, including nested lists, stopping as soon
+// as the count goes over the limit.
+func countMultiplexed(listParams []listParameters, limit uint64) uint64 {
+	if len(listParams) == 0 {
+		return 1
+	}
+
+	var count uint64
+	statementCount := minimumListParamLength(listParams)
+	for i := uint64(0); i < statementCount && count <= limit; i++ {
+		var nested []listParameters
+		for _, lp := range listParams {
+			if lp.paramType == bodyParamType {
+				nested = append(nested, getListParamsFromBody(lp.value[i])...)
+			} else {
+				nested = append(nested, findListParameters(lp.path, lp.value[i])...)
+			}
+		}
+
+		count += countMultiplexed(nested, limit-count)
+	}
+
+	return count
+}
+
 func getListParamsFromBody(body interface{}) []listParameters {
 	var result []listParameters
 	if body, ok := body.([]interface{}); ok {
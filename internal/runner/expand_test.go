@@ -291,6 +291,45 @@ func TestMultiplexStatements(t *testing.T) {
 				},
 			},
 		},
+		{
+			"should multiplex statement within the multiplexed requests limit",
+			domain.Resources{
+				"hero": domain.Statement{Method: "from", Resource: "hero", MaxMultiplexedRequests: 2, With: domain.Params{Values: map[string]interface{}{"id": []interface{}{"12345", "67890"}}}},
+			},
+			domain.Resources{
+				"hero": []interface{}{
+					domain.Statement{Method: "from", Resource: "hero", MaxMultiplexedRequests: 2, With: domain.Params{Values: map[string]interface{}{"id": "12345"}}},
+					domain.Statement{Method: "from", Resource: "hero", MaxMultiplexedRequests: 2, With: domain.Params{Values: map[string]interface{}{"id": "67890"}}},
+				},
+			},
+		},
+		{
+			"should reject statement exceeding the multiplexed requests limit",
+			domain.Resources{
+				"hero": domain.Statement{Method: "from", Resource: "hero", MaxMultiplexedRequests: 2, With: domain.Params{Values: map[string]interface{}{"id": []interface{}{"12345", "67890", "19283"}}}},
+			},
+			domain.Resources{
+				"hero": domain.Statement{Method: "from", Resource: "hero", MaxMultiplexedRequests: 2, RejectedRequests: 3, With: domain.Params{Values: map[string]interface{}{"id": []interface{}{"12345", "67890", "19283"}}}},
+			},
+		},
+		{
+			"should count nested lists against the multiplexed requests limit",
+			domain.Resources{
+				"hero": domain.Statement{Method: "from", Resource: "hero", MaxMultiplexedRequests: 3, With: domain.Params{Values: map[string]interface{}{"id": []interface{}{[]interface{}{"1", "2"}, []interface{}{"3", "4"}}}}},
+			},
+			domain.Resources{
+				"hero": domain.Statement{Method: "from", Resource: "hero", MaxMultiplexedRequests: 3, RejectedRequests: 4, With: domain.Params{Values: map[string]interface{}{"id": []interface{}{[]interface{}{"1", "2"}, []interface{}{"3", "4"}}}}},
+			},
+		},
+		{
+			"should not count no multiplex lists against the multiplexed requests limit",
+			domain.Resources{
+				"hero": domain.Statement{Method: "from", Resource: "hero", MaxMultiplexedRequests: 1, With: domain.Params{Values: map[string]interface{}{"id": domain.NoMultiplex{[]interface{}{"12345", "67890"}}}}},
+			},
+			domain.Resources{
+				"hero": domain.Statement{Method: "from", Resource: "hero", MaxMultiplexedRequests: 1, With: domain.Params{Values: map[string]interface{}{"id": domain.NoMultiplex{[]interface{}{"12345", "67890"}}}}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// PrimaryTarget identifies responses served by the statement mapping,
+// while failover responses are identified by the position of the
+// failover URL, like `failover-1`.
+const PrimaryTarget = "primary"
+
+// failover executes the statement against each failover URL in order,
+// while the previous one failed with a connection error or one of the
+// failover status codes. Timeouts do not trigger a failover, since
+// the statement time budget is already spent, nor responses exceeding
+// the size limit, which are expected to be as large on every target.
+func (e Executor) failover(ctx context.Context, statement domain.Statement, queryCtx restql.QueryContext, request restql.HTTPRequest, response restql.HTTPResponse, err error) (restql.HTTPRequest, restql.HTTPResponse, string, error) {
+	log := restql.GetLogger(ctx)
+	target := PrimaryTarget
+
+	for i, url := range statement.FailoverURLs {
+		if !shouldFailover(ctx, statement, response, err) {
+			break
+		}
+
+		mapping, mappingErr := restql.NewMapping(statement.Resource, url)
+		if mappingErr != nil {
+			log.Error("invalid failover url", mappingErr, "resource", statement.Resource, "url", url)
+			continue
+		}
+
+		log.Debug("failing over statement", "resource", statement.Resource, "url", url, "status", response.StatusCode, "error", err)
+
+		request = MakeRequest(e.resourceTimeout, e.forwardPrefix, statement, withMapping(queryCtx, mapping))
+		response, err = e.doRequest(ctx, statement, request)
+		target = "failover-" + strconv.Itoa(i+1)
+	}
+
+	return request, response, target, err
+}
+
+func shouldFailover(ctx context.Context, statement domain.Statement, response restql.HTTPResponse, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, domain.ErrRequestTimeout) && !isBodyLimitError(err)
+	}
+
+	for _, code := range statement.FailoverStatusCodes {
+		if response.StatusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func withMapping(queryCtx restql.QueryContext, mapping restql.Mapping) restql.QueryContext {
+	mappings := make(map[string]restql.Mapping, len(queryCtx.Mappings))
+	for resource, m := range queryCtx.Mappings {
+		mappings[resource] = m
+	}
+	mappings[mapping.ResourceName()] = mapping
+
+	queryCtx.Mappings = mappings
+	return queryCtx
+}
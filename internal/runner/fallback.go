@@ -0,0 +1,22 @@
+package runner
+
+import (
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// applyDefaultValue replaces the body of a failed result by the value
+// of the statement `default` clause, when its errors are ignored, so
+// chained statements and clients can keep working in degraded mode.
+// The status of the result is kept, reporting the upstream failure.
+func applyDefaultValue(log restql.Logger, statement domain.Statement, dr restql.DoneResource) restql.DoneResource {
+	if statement.Default == nil || !statement.IgnoreErrors || dr.Success {
+		return dr
+	}
+
+	log.Debug("using default value for failed statement", "resource", statement.Resource, "status", dr.Status)
+
+	dr.ResponseBody = restql.NewResponseBodyFromBytes(log, statement.Default)
+	dr.Defaulted = true
+	return dr
+}
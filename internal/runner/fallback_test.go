@@ -0,0 +1,69 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestExecutorDefaultValue(t *testing.T) {
+	failed := restql.HTTPResponse{StatusCode: http.StatusServiceUnavailable, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, "unavailable")}
+	ok := restql.HTTPResponse{StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, map[string]interface{}{"name": "Batman"})}
+	defaultValue := []byte(`{"name":"unknown"}`)
+
+	tests := []struct {
+		name              string
+		response          restql.HTTPResponse
+		ignoreErrors      bool
+		expectedStatus    int
+		expectedBody      interface{}
+		expectedDefaulted bool
+	}{
+		{
+			"should use default value when failed statement ignores errors",
+			failed,
+			true,
+			http.StatusServiceUnavailable,
+			map[string]interface{}{"name": "unknown"},
+			true,
+		},
+		{
+			"should not use default value when failed statement does not ignore errors",
+			failed,
+			false,
+			http.StatusServiceUnavailable,
+			"unavailable",
+			false,
+		},
+		{
+			"should not use default value when statement succeeds",
+			ok,
+			true,
+			http.StatusOK,
+			map[string]interface{}{"name": "Batman"},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &stubClient{responses: []restql.HTTPResponse{tt.response}}
+			executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, 0, "", nil)
+
+			statement := domain.Statement{Method: domain.FromMethod, Resource: "hero", Default: defaultValue, IgnoreErrors: tt.ignoreErrors}
+			queryCtx := restql.QueryContext{Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")}}
+
+			ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+			got := executor.DoStatement(ctx, statement, queryCtx)
+
+			test.Equal(t, got.Status, tt.expectedStatus)
+			test.Equal(t, got.ResponseBody.Unmarshal(), tt.expectedBody)
+			test.Equal(t, got.Defaulted, tt.expectedDefaulted)
+		})
+	}
+}
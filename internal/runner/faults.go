@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+// doUpstream executes the request of the statement, injecting the
+// faults declared for its resource when they are enabled for the query.
+func (e Executor) doUpstream(ctx context.Context, statement domain.Statement, request restql.HTTPRequest, attempt int) (restql.HTTPResponse, error) {
+	if statement.Faults == nil || !domain.FaultInjectionEnabled(ctx) {
+		return e.client.Do(ctx, request)
+	}
+
+	return e.doWithFaults(ctx, statement, request, attempt)
+}
+
+// doWithFaults draws the faults affecting the request, at random but
+// reproducible with the query seed, in the order they would happen:
+// the added latency, which can exceed the request timeout, then the
+// dropped connection or the error response, which spare the upstream
+// call, and finally the truncation of the upstream response body.
+// Each attempt of the request has its own draws, so retries can work
+// around the injected failures.
+func (e Executor) doWithFaults(ctx context.Context, statement domain.Statement, request restql.HTTPRequest, attempt int) (restql.HTTPResponse, error) {
+	log := restql.GetLogger(ctx)
+	faults := statement.Faults
+	url := fmt.Sprintf("%s://%s%s", request.Schema, request.Host, request.Path)
+	key := fmt.Sprintf("%s %s %v %d", request.Method, url, request.Query, attempt)
+	start := time.Now()
+
+	if faults.Latency > 0 && restql.Random(ctx, "fault-latency "+key) < faults.LatencyRate {
+		delay := time.Duration(restql.Random(ctx, "fault-delay "+key) * float64(faults.Latency))
+		log.Debug("injecting latency in request", "resource", statement.Resource, "method", statement.Method, "latency", delay)
+
+		if request.Timeout > 0 && delay >= request.Timeout {
+			if !waitRetryAfter(ctx, request.Timeout) {
+				return restql.HTTPResponse{URL: url, Duration: time.Since(start)}, ctx.Err()
+			}
+			return restql.HTTPResponse{URL: url, StatusCode: http.StatusRequestTimeout, Duration: time.Since(start)}, domain.ErrRequestTimeout
+		}
+
+		if !waitRetryAfter(ctx, delay) {
+			return restql.HTTPResponse{URL: url, Duration: time.Since(start)}, ctx.Err()
+		}
+		if request.Timeout > 0 {
+			request.Timeout -= delay
+		}
+	}
+
+	if restql.Random(ctx, "fault-reset "+key) < faults.ResetRate {
+		log.Debug("injecting connection reset in request", "resource", statement.Resource, "method", statement.Method)
+		return restql.HTTPResponse{URL: url, Duration: time.Since(start)}, errors.Wrap(syscall.ECONNRESET, "request execution failed : injected fault")
+	}
+
+	if restql.Random(ctx, "fault-error "+key) < faults.ErrorRate {
+		log.Debug("injecting error response in request", "resource", statement.Resource, "method", statement.Method, "status", faults.ErrorStatus)
+		return restql.HTTPResponse{
+			URL:        url,
+			StatusCode: faults.ErrorStatus,
+			Body:       restql.NewResponseBodyFromValue(log, map[string]interface{}{"error": "injected fault"}),
+			Duration:   time.Since(start),
+		}, nil
+	}
+
+	response, err := e.client.Do(ctx, request)
+	response.Duration = time.Since(start)
+
+	if err == nil && response.Body != nil && restql.Random(ctx, "fault-truncate "+key) < faults.TruncateRate {
+		if body := response.Body.Bytes(); len(body) > 0 {
+			log.Debug("injecting truncated body in response", "resource", statement.Resource, "method", statement.Method)
+			response.Body = restql.NewResponseBodyFromBytes(log, body[:len(body)/2])
+		}
+	}
+
+	return response, err
+}
@@ -0,0 +1,110 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestExecutorFaults(t *testing.T) {
+	body := `{"id": 1, "name": "batman"}`
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+	}
+
+	tests := []struct {
+		name           string
+		faults         domain.Faults
+		enabled        bool
+		retries        int
+		expectedStatus int
+		expectedBody   interface{}
+		expectedCalls  int
+	}{
+		{
+			"should not inject faults unless enabled",
+			domain.Faults{ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable},
+			false,
+			0,
+			http.StatusOK,
+			test.Unmarshal(body),
+			1,
+		},
+		{
+			"should inject error responses without calling the upstream",
+			domain.Faults{ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable},
+			true,
+			0,
+			http.StatusServiceUnavailable,
+			test.Unmarshal(`{"error": "injected fault"}`),
+			0,
+		},
+		{
+			"should inject connection resets on every attempt",
+			domain.Faults{ResetRate: 1},
+			true,
+			2,
+			0,
+			"request execution failed : injected fault: connection reset by peer",
+			0,
+		},
+		{
+			"should inject truncated bodies",
+			domain.Faults{TruncateRate: 1},
+			true,
+			0,
+			http.StatusOK,
+			`{"id": 1, "na`,
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := restql.HTTPResponse{URL: "http://hero.io/api", StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromBytes(test.NoOpLogger, []byte(body))}
+			client := &stubClient{responses: []restql.HTTPResponse{response}}
+			executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+
+			faults := tt.faults
+			statement := domain.Statement{Method: domain.FromMethod, Resource: "hero", Retries: tt.retries, Faults: &faults}
+
+			ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+			if tt.enabled {
+				ctx = domain.WithFaultInjection(ctx)
+			}
+			got := executor.DoStatement(ctx, statement, queryCtx)
+
+			test.Equal(t, got.Status, tt.expectedStatus)
+			test.Equal(t, got.ResponseBody.Unmarshal(), tt.expectedBody)
+			test.Equal(t, len(client.requests), tt.expectedCalls)
+		})
+	}
+}
+
+func TestExecutorLatencyFault(t *testing.T) {
+	client := &stubClient{}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, 10*time.Millisecond, "", nil)
+
+	statement := domain.Statement{Method: domain.FromMethod, Resource: "hero", Faults: &domain.Faults{Latency: time.Hour, LatencyRate: 1}}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+	}
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	ctx = restql.WithSeed(ctx, 42)
+	ctx = domain.WithFaultInjection(ctx)
+
+	start := time.Now()
+	got := executor.DoStatement(ctx, statement, queryCtx)
+
+	test.Equal(t, got.Status, http.StatusRequestTimeout)
+	test.Equal(t, got.ResponseBody.Unmarshal(), domain.ErrRequestTimeout.Error())
+	test.Equal(t, time.Since(start) < time.Second, true)
+	test.Equal(t, len(client.requests), 0)
+}
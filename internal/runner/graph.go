@@ -0,0 +1,209 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+)
+
+// QueryGraph represents the statements of a query as a directed
+// acyclic graph, with an edge from each statement to the ones
+// using its result in chained parameters.
+//
+// Statements in the same stage have chains of dependencies of the
+// same length, the first stage being the ones starting as soon as
+// the query runs. The critical path is the longest chain, which
+// bounds the query latency to the sum of its statements latencies.
+type QueryGraph struct {
+	Statements   []GraphStatement `json:"statements"`
+	Edges        []GraphEdge      `json:"edges"`
+	Stages       [][]string       `json:"stages"`
+	CriticalPath []string         `json:"criticalPath"`
+}
+
+// GraphStatement represents a statement of the query graph.
+type GraphStatement struct {
+	Statement string   `json:"statement"`
+	Resource  string   `json:"resource"`
+	Method    string   `json:"method"`
+	Stage     int      `json:"stage"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+	In        string   `json:"in,omitempty"`
+	Hidden    bool     `json:"hidden,omitempty"`
+	Subquery  bool     `json:"subquery,omitempty"`
+}
+
+// GraphEdge represents a dependency between statements,
+// along with the chained values creating it.
+type GraphEdge struct {
+	From   string   `json:"from"`
+	To     string   `json:"to"`
+	Chains []string `json:"chains"`
+}
+
+// BuildQueryGraph returns the execution graph of the query statements,
+// failing if chained parameters target unknown statements or form a cycle.
+func BuildQueryGraph(query domain.Query) (QueryGraph, error) {
+	resources := domain.NewResources(query.Statements)
+
+	err := ValidateChainedValues(resources)
+	if err != nil {
+		return QueryGraph{}, err
+	}
+
+	err = ValidateChainDependencies(resources, 0)
+	if err != nil {
+		return QueryGraph{}, err
+	}
+
+	dependencies := make(map[domain.ResourceID][]domain.ResourceID, len(resources))
+	for resourceID, stmt := range resources {
+		dependencies[resourceID] = statementDependencies(stmt, resources)
+	}
+
+	stages := make(map[domain.ResourceID]int, len(resources))
+	var stageOf func(resourceID domain.ResourceID) int
+	stageOf = func(resourceID domain.ResourceID) int {
+		if stage, done := stages[resourceID]; done {
+			return stage
+		}
+
+		stage := 0
+		for _, dependency := range dependencies[resourceID] {
+			if s := stageOf(dependency) + 1; s > stage {
+				stage = s
+			}
+		}
+
+		stages[resourceID] = stage
+		return stage
+	}
+
+	graph := QueryGraph{Statements: []GraphStatement{}, Edges: []GraphEdge{}}
+	for _, resourceID := range sortedResourceIDs(dependencies) {
+		stmt := firstStatement(resources[resourceID])
+		_, isSubquery := domain.ParseSubquery(stmt.Resource)
+
+		gs := GraphStatement{
+			Statement: string(resourceID),
+			Resource:  stmt.Resource,
+			Method:    stmt.Method,
+			Stage:     stageOf(resourceID),
+			In:        strings.Join(stmt.In, "."),
+			Hidden:    stmt.Hidden,
+			Subquery:  isSubquery,
+		}
+		for _, dependency := range dependencies[resourceID] {
+			gs.DependsOn = append(gs.DependsOn, string(dependency))
+		}
+		graph.Statements = append(graph.Statements, gs)
+
+		for len(graph.Stages) <= gs.Stage {
+			graph.Stages = append(graph.Stages, []string{})
+		}
+		graph.Stages[gs.Stage] = append(graph.Stages[gs.Stage], gs.Statement)
+
+		chains := make(map[domain.ResourceID][]string)
+		collectStatementChains(resources[resourceID], resources, chains)
+		for _, dependency := range dependencies[resourceID] {
+			graph.Edges = append(graph.Edges, GraphEdge{
+				From:   string(dependency),
+				To:     string(resourceID),
+				Chains: dedupeSorted(chains[dependency]),
+			})
+		}
+	}
+
+	graph.CriticalPath = criticalPath(graph.Stages, dependencies, stages)
+
+	return graph, nil
+}
+
+// criticalPath walks back from the first statement of the last
+// stage through dependencies in the preceding stages.
+func criticalPath(stages [][]string, dependencies map[domain.ResourceID][]domain.ResourceID, stageOf map[domain.ResourceID]int) []string {
+	if len(stages) == 0 {
+		return []string{}
+	}
+
+	current := domain.ResourceID(stages[len(stages)-1][0])
+	path := []string{string(current)}
+	for stageOf[current] > 0 {
+		for _, dependency := range dependencies[current] {
+			if stageOf[dependency] == stageOf[current]-1 {
+				current = dependency
+				break
+			}
+		}
+		path = append([]string{string(current)}, path...)
+	}
+
+	return path
+}
+
+func firstStatement(stmt interface{}) domain.Statement {
+	statements := flattenStatements(stmt)
+	if len(statements) == 0 {
+		return domain.Statement{}
+	}
+
+	return statements[0]
+}
+
+func collectStatementChains(stmt interface{}, resources domain.Resources, chains map[domain.ResourceID][]string) {
+	for _, s := range flattenStatements(stmt) {
+		for _, value := range s.With.Values {
+			collectValueChains(value, resources, chains)
+		}
+		for _, value := range s.Headers {
+			collectValueChains(value, resources, chains)
+		}
+		for _, value := range statementSettings(s) {
+			collectValueChains(value, resources, chains)
+		}
+	}
+}
+
+func collectValueChains(value interface{}, resources domain.Resources, chains map[domain.ResourceID][]string) {
+	switch value := value.(type) {
+	case domain.Chain:
+		target, ok := value[0].(string)
+		if !ok {
+			return
+		}
+
+		if _, found := resources[domain.ResourceID(target)]; found {
+			chains[domain.ResourceID(target)] = append(chains[domain.ResourceID(target)], chainPath(value))
+		}
+	case domain.Range:
+		collectValueChains(value.Start, resources, chains)
+		collectValueChains(value.End, resources, chains)
+		collectValueChains(value.Step, resources, chains)
+	case domain.Function:
+		collectValueChains(value.Target(), resources, chains)
+	case []interface{}:
+		for _, v := range value {
+			collectValueChains(v, resources, chains)
+		}
+	case map[string]interface{}:
+		for _, v := range value {
+			collectValueChains(v, resources, chains)
+		}
+	}
+}
+
+// chainPath returns the dotted representation of a chained value.
+func chainPath(chain domain.Chain) string {
+	path := make([]string, len(chain))
+	for i, segment := range chain {
+		switch segment := segment.(type) {
+		case domain.Variable:
+			path[i] = "$" + segment.Target
+		default:
+			path[i] = fmt.Sprintf("%v", segment)
+		}
+	}
+
+	return strings.Join(path, ".")
+}
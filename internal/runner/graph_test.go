@@ -0,0 +1,67 @@
+package runner_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestBuildQueryGraph(t *testing.T) {
+	query := domain.Query{Statements: []domain.Statement{
+		{Method: domain.FromMethod, Resource: "hero"},
+		{Method: domain.FromMethod, Resource: "villain"},
+		{
+			Method:   domain.FromMethod,
+			Resource: "sidekick",
+			With:     domain.Params{Values: map[string]interface{}{"hero": domain.Chain{"hero", "id"}}},
+			Headers:  map[string]interface{}{"X-Hero": domain.Chain{"hero", "name"}},
+		},
+		{
+			Method:   domain.FromMethod,
+			Resource: "weapons",
+			Alias:    "gear",
+			In:       []string{"sidekick", "weapons"},
+			With: domain.Params{Values: map[string]interface{}{
+				"sidekick": domain.Chain{"sidekick", "id"},
+				"villain":  domain.Chain{"villain", "id"},
+			}},
+			Hidden: true,
+		},
+	}}
+
+	graph, err := runner.BuildQueryGraph(query)
+	test.VerifyError(t, err)
+
+	expected := runner.QueryGraph{
+		Statements: []runner.GraphStatement{
+			{Statement: "gear", Resource: "weapons", Method: domain.FromMethod, Stage: 2, DependsOn: []string{"sidekick", "villain"}, In: "sidekick.weapons", Hidden: true},
+			{Statement: "hero", Resource: "hero", Method: domain.FromMethod, Stage: 0},
+			{Statement: "sidekick", Resource: "sidekick", Method: domain.FromMethod, Stage: 1, DependsOn: []string{"hero"}},
+			{Statement: "villain", Resource: "villain", Method: domain.FromMethod, Stage: 0},
+		},
+		Edges: []runner.GraphEdge{
+			{From: "sidekick", To: "gear", Chains: []string{"sidekick.id"}},
+			{From: "villain", To: "gear", Chains: []string{"villain.id"}},
+			{From: "hero", To: "sidekick", Chains: []string{"hero.id", "hero.name"}},
+		},
+		Stages:       [][]string{{"hero", "villain"}, {"sidekick"}, {"gear"}},
+		CriticalPath: []string{"hero", "sidekick", "gear"},
+	}
+
+	test.Equal(t, graph, expected)
+}
+
+func TestBuildQueryGraphWithCycle(t *testing.T) {
+	query := domain.Query{Statements: []domain.Statement{
+		{Method: domain.FromMethod, Resource: "hero", With: domain.Params{Values: map[string]interface{}{"id": domain.Chain{"sidekick", "hero"}}}},
+		{Method: domain.FromMethod, Resource: "sidekick", With: domain.Params{Values: map[string]interface{}{"hero": domain.Chain{"hero", "id"}}}},
+	}}
+
+	_, err := runner.BuildQueryGraph(query)
+	if !errors.Is(err, runner.ErrChainCycle) {
+		t.Fatalf("expected a chain cycle error, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// forwardedHeaderName returns the name a client header is sent
+// upstream with, unless it is not forwarded by the policy. Without
+// a policy every header but the disallowed ones is forwarded.
+func forwardedHeaderName(policy *domain.HeaderForwarding, header string) (string, bool) {
+	if isDisallowedHeader(header) {
+		return "", false
+	}
+
+	header = http.CanonicalHeaderKey(header)
+	if policy == nil {
+		return header, true
+	}
+
+	if len(policy.Allow) > 0 && !matchesHeader(policy.Allow, header) {
+		return "", false
+	}
+
+	if matchesHeader(policy.Deny, header) {
+		return "", false
+	}
+
+	for from, to := range policy.Rename {
+		if strings.EqualFold(from, header) {
+			return http.CanonicalHeaderKey(to), true
+		}
+	}
+
+	return header, true
+}
+
+// setRequestIDHeaders sends the request id of the query
+// upstream in each of the given headers.
+func setRequestIDHeaders(ctx context.Context, headers map[string]string, names []string) {
+	requestID, ok := restql.RequestID(ctx)
+	if !ok {
+		return
+	}
+
+	for _, name := range names {
+		headers[http.CanonicalHeaderKey(name)] = requestID
+	}
+}
+
+func matchesHeader(patterns []string, header string) bool {
+	for _, p := range patterns {
+		prefix := strings.TrimSuffix(p, "*")
+		if prefix == p && strings.EqualFold(p, header) {
+			return true
+		}
+
+		if prefix != p && len(header) >= len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HeaderPreview is the outcome of the header forwarding rules of
+// a resource for a set of client headers, splitting the headers
+// sent upstream into the forwarded and the injected ones.
+type HeaderPreview struct {
+	Resource  string                   `json:"resource"`
+	Policy    *domain.HeaderForwarding `json:"policy,omitempty"`
+	Source    string                   `json:"source,omitempty"`
+	Forwarded map[string]string        `json:"forwarded"`
+	Dropped   []string                 `json:"dropped"`
+	Injected  map[string]string        `json:"injected"`
+	Headers   map[string]string        `json:"headers"`
+}
+
+// PreviewHeaders resolves the header forwarding rules and the static
+// headers of the resource for the tenant, returning which of the client
+// headers would be sent upstream by a statement without headers.
+func (r Runner) PreviewHeaders(tenant string, resource string, clientHeaders map[string]string) HeaderPreview {
+	statement, plan := r.defaults.Resolve(tenant, "", nil, domain.Statement{Method: domain.FromMethod, Resource: resource})
+	queryCtx := restql.QueryContext{Input: restql.QueryInput{Headers: clientHeaders}}
+
+	forwarded := getForwardHeaders(queryCtx, statement.ForwardConditionalHeaders, statement.ForwardHeaders)
+
+	dropped := []string{}
+	for k := range clientHeaders {
+		_, ok := forwardedHeaderName(statement.ForwardHeaders, k)
+		if !ok || !statement.ForwardConditionalHeaders && isConditionalHeader(k) {
+			dropped = append(dropped, http.CanonicalHeaderKey(k))
+		}
+	}
+	sort.Strings(dropped)
+
+	return HeaderPreview{
+		Resource:  resource,
+		Policy:    statement.ForwardHeaders,
+		Source:    plan.Sources["forwardHeaders"],
+		Forwarded: forwarded,
+		Dropped:   dropped,
+		Injected:  plan.Headers,
+		Headers:   makeHeaders(statement, queryCtx),
+	}
+}
@@ -0,0 +1,42 @@
+package runner_test
+
+import (
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestPreviewHeaders(t *testing.T) {
+	policy := &domain.HeaderForwarding{Allow: []string{"Authorization", "X-Tid"}, Rename: map[string]string{"Authorization": "X-Upstream-Auth"}}
+	cascade := runner.DefaultsCascade{
+		Global: runner.Defaults{Headers: map[string]string{"X-Api-Version": "2"}},
+		Tenants: map[string]runner.TenantDefaults{
+			"DC": {Mappings: map[string]runner.Defaults{"hero": {ForwardHeaders: policy}}},
+		},
+	}
+	r := runner.NewRunner(test.NoOpLogger, runner.Executor{}, 0, cascade, nil, 0)
+
+	got := r.PreviewHeaders("DC", "hero", map[string]string{
+		"authorization": "Bearer abc",
+		"Cookie":        "session=1",
+		"Host":          "restql.io",
+		"If-None-Match": `"abc"`,
+	})
+
+	expected := runner.HeaderPreview{
+		Resource:  "hero",
+		Policy:    policy,
+		Source:    "mapping",
+		Forwarded: map[string]string{"X-Upstream-Auth": "Bearer abc"},
+		Dropped:   []string{"Cookie", "Host", "If-None-Match"},
+		Injected:  map[string]string{"X-Api-Version": "2"},
+		Headers:   map[string]string{"X-Upstream-Auth": "Bearer abc", "X-Api-Version": "2", "Content-Type": "application/json"},
+	}
+	test.Equal(t, got, expected)
+
+	got = r.PreviewHeaders("MARVEL", "hero", map[string]string{"Cookie": "session=1"})
+	test.Equal(t, got.Forwarded, map[string]string{"Cookie": "session=1"})
+	test.Equal(t, got.Dropped, []string{})
+}
@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"errors"
+	"expvar"
+	"sync"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// ErrQueryMemoryExceeded is returned when the decoded bodies held
+// by a query exceed the ceiling defined by the `maxQueryMemory` default.
+var ErrQueryMemoryExceeded = errors.New("query memory exceeded")
+
+// memoryMetrics holds the peak of the memory held by a single query,
+// along with how many queries were aborted by the ceiling and how
+// they spread over size buckets, exposed by the expvar handler.
+var memoryMetrics = expvar.NewMap("queryMemory")
+
+var memoryBuckets = []struct {
+	name string
+	size int64
+}{
+	{"le_64KB", 64 << 10},
+	{"le_1MB", 1 << 20},
+	{"le_16MB", 16 << 20},
+	{"le_128MB", 128 << 20},
+}
+
+var peakQueryMemory struct {
+	mu    sync.Mutex
+	bytes int64
+}
+
+func init() {
+	memoryMetrics.Set("peakBytes", expvar.Func(func() interface{} {
+		peakQueryMemory.mu.Lock()
+		defer peakQueryMemory.mu.Unlock()
+		return peakQueryMemory.bytes
+	}))
+}
+
+// queryMemory approximates the memory held by a query
+// as the size of the response bodies it received.
+type queryMemory struct {
+	limit int64
+	held  int64
+}
+
+func newQueryMemory(limit int) *queryMemory {
+	return &queryMemory{limit: int64(limit)}
+}
+
+// add accounts the bodies of the response, returning false
+// once the query holds more than the ceiling, when there is one.
+func (qm *queryMemory) add(response interface{}) bool {
+	qm.held += responseBodySize(response)
+	return qm.limit <= 0 || qm.held <= qm.limit
+}
+
+// record publishes the memory held by the finished query.
+func (qm *queryMemory) record(exceeded bool) {
+	if exceeded {
+		memoryMetrics.Add("exceeded", 1)
+	}
+
+	bucket := "gt_128MB"
+	for _, b := range memoryBuckets {
+		if qm.held <= b.size {
+			bucket = b.name
+			break
+		}
+	}
+	memoryMetrics.Add(bucket, 1)
+
+	peakQueryMemory.mu.Lock()
+	if qm.held > peakQueryMemory.bytes {
+		peakQueryMemory.bytes = qm.held
+	}
+	peakQueryMemory.mu.Unlock()
+}
+
+func responseBodySize(response interface{}) int64 {
+	switch response := response.(type) {
+	case restql.DoneResource:
+		if response.ResponseBody == nil {
+			return 0
+		}
+		return int64(len(response.ResponseBody.Bytes()))
+	case restql.DoneResources:
+		var size int64
+		for _, r := range response {
+			size += responseBodySize(r)
+		}
+		return size
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,66 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestRunnerMaxQueryMemory(t *testing.T) {
+	heroBody := []byte(`{"id": 1, "sidekickId": "robin"}`)
+	sidekickBody := []byte(`{"id": "robin", "name": "Robin"}`)
+	held := len(heroBody) + len(sidekickBody)
+
+	tests := []struct {
+		name        string
+		defaults    runner.DefaultsCascade
+		expectedErr error
+	}{
+		{"should execute without ceiling", runner.DefaultsCascade{}, nil},
+		{"should execute when bodies fit the ceiling", runner.DefaultsCascade{Global: runner.Defaults{MaxQueryMemory: held}}, nil},
+		{"should fail when bodies exceed the ceiling", runner.DefaultsCascade{Global: runner.Defaults{MaxQueryMemory: held - 1}}, runner.ErrQueryMemoryExceeded},
+		{
+			"should use tenant ceiling over global",
+			runner.DefaultsCascade{
+				Global:  runner.Defaults{MaxQueryMemory: held},
+				Tenants: map[string]runner.TenantDefaults{"DC": {Defaults: runner.Defaults{MaxQueryMemory: len(heroBody)}}},
+			},
+			runner.ErrQueryMemoryExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &stubClient{responses: []restql.HTTPResponse{
+				{StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromBytes(test.NoOpLogger, heroBody)},
+				{StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromBytes(test.NoOpLogger, sidekickBody)},
+			}}
+			executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+			r := runner.NewRunner(test.NoOpLogger, executor, time.Second, tt.defaults, nil, 0)
+
+			query := domain.Query{Statements: []domain.Statement{
+				{Method: domain.FromMethod, Resource: "hero"},
+				{Method: domain.FromMethod, Resource: "sidekick", With: domain.Params{Values: map[string]interface{}{"id": domain.Chain{"hero", "sidekickId"}}}},
+			}}
+			queryCtx := restql.QueryContext{
+				Mappings: map[string]restql.Mapping{
+					"hero":     mapping(t, "http://hero.io/api"),
+					"sidekick": mapping(t, "http://sidekick.io/api"),
+				},
+				Options: restql.QueryOptions{Tenant: "DC"},
+			}
+
+			ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+			_, err := r.ExecuteQuery(ctx, query, queryCtx)
+
+			test.Equal(t, errors.Is(err, tt.expectedErr), true)
+		})
+	}
+}
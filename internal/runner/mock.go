@@ -0,0 +1,100 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// mockModifier selects the resources whose declared mock is
+// served, as a comma separated list or `*` for all of them.
+const mockModifier = "mock"
+
+func isMockSelected(modifiers domain.Modifiers, resource string) bool {
+	return isSelectedBy(modifiers, mockModifier, resource)
+}
+
+// isSelectedBy returns true if the name is in the comma
+// separated list of the modifier, or the list is `*`.
+func isSelectedBy(modifiers domain.Modifiers, modifier string, name string) bool {
+	value, ok := modifiers[modifier].(string)
+	if !ok {
+		return false
+	}
+
+	for _, selected := range strings.Split(value, ",") {
+		selected = strings.TrimSpace(selected)
+		if selected == "*" || selected == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// doMock answers the statement with the mock declared for its
+// resource after the mock latency, without calling the upstream.
+// A latency greater than the statement timeout results in a
+// timeout, as the upstream request would.
+func (e Executor) doMock(ctx context.Context, statement domain.Statement, queryCtx restql.QueryContext, drOptions DoneResourceOptions) restql.DoneResource {
+	log := restql.GetLogger(ctx)
+	mock := statement.Mock
+
+	request := MakeRequest(e.resourceTimeout, e.forwardPrefix, statement, queryCtx)
+	var url string
+	if request.Host != "" {
+		url = fmt.Sprintf("%s://%s%s", request.Schema, request.Host, request.Path)
+	}
+
+	log.Debug("serving mock response for statement", "resource", statement.Resource, "method", statement.Method, "latency", mock.Latency)
+
+	wait := mock.Latency
+	timedOut := request.Timeout > 0 && wait > request.Timeout
+	if timedOut {
+		wait = request.Timeout
+	}
+
+	start := time.Now()
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			response := restql.HTTPResponse{URL: url, Duration: time.Since(start)}
+			dr := NewErrorResponse(log, ctx.Err(), request, response, drOptions)
+			dr.Mocked = true
+			return dr
+		}
+	}
+
+	if timedOut {
+		response := restql.HTTPResponse{URL: url, StatusCode: http.StatusRequestTimeout, Duration: time.Since(start)}
+		dr := NewErrorResponse(log, domain.ErrRequestTimeout, request, response, drOptions)
+		dr.Mocked = true
+		return dr
+	}
+
+	status := mock.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	response := restql.HTTPResponse{
+		URL:        url,
+		StatusCode: status,
+		Headers:    mock.Headers,
+		Body:       restql.NewResponseBodyFromBytes(log, mock.Body),
+		Duration:   time.Since(start),
+	}
+
+	dr := NewDoneResource(request, response, drOptions)
+	dr.Mocked = true
+
+	return normalizeResponse(log, statement, dr)
+}
@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"strings"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// normalizeResponse applies the mapping normalization rules to the body
+// of a successful response. The new body is set on the returned value,
+// leaving the original untouched, since it may be shared with the
+// upstream response cache.
+func normalizeResponse(log restql.Logger, statement domain.Statement, dr restql.DoneResource) restql.DoneResource {
+	if statement.Normalize == nil || !dr.Success || dr.ResponseBody == nil || !dr.ResponseBody.Valid() {
+		return dr
+	}
+
+	body, ok := Normalize(*statement.Normalize, dr.ResponseBody.Unmarshal())
+	if !ok {
+		log.Debug("response body does not have the normalization envelope", "resource", statement.Resource, "lift", statement.Normalize.Lift)
+		return dr
+	}
+
+	dr.ResponseBody = restql.NewResponseBodyFromValue(log, body)
+	return dr
+}
+
+// Normalize returns the value transformed by the normalization rules,
+// or false if it does not have the envelope defined by the lift rule.
+func Normalize(n domain.Normalization, value interface{}) (interface{}, bool) {
+	if n.Lift != "" {
+		for _, key := range strings.Split(n.Lift, ".") {
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+
+			value, ok = obj[key]
+			if !ok {
+				return nil, false
+			}
+		}
+	}
+
+	if len(n.Drop) == 0 && len(n.Rename) == 0 {
+		return value, true
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return normalizeObject(n, value), true
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, item := range value {
+			if obj, ok := item.(map[string]interface{}); ok {
+				result[i] = normalizeObject(n, obj)
+				continue
+			}
+			result[i] = item
+		}
+		return result, true
+	default:
+		return value, true
+	}
+}
+
+func normalizeObject(n domain.Normalization, obj map[string]interface{}) map[string]interface{} {
+	dropped := make(map[string]struct{}, len(n.Drop))
+	for _, key := range n.Drop {
+		dropped[key] = struct{}{}
+	}
+
+	result := make(map[string]interface{}, len(obj))
+	for key, value := range obj {
+		if _, found := dropped[key]; found {
+			continue
+		}
+		if _, found := n.Rename[key]; found {
+			continue
+		}
+		result[key] = value
+	}
+
+	// Renamed fields take precedence over existing ones with the same name.
+	for key, target := range n.Rename {
+		if _, found := dropped[key]; found {
+			continue
+		}
+		if value, found := obj[key]; found {
+			result[target] = value
+		}
+	}
+
+	return result
+}
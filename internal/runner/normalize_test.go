@@ -0,0 +1,64 @@
+package runner_test
+
+import (
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name          string
+		normalization domain.Normalization
+		value         string
+		expected      string
+		expectedOk    bool
+	}{
+		{
+			"should lift nested envelope",
+			domain.Normalization{Lift: "data.result"},
+			`{"data": {"result": {"name": "batman"}}, "status": "ok"}`,
+			`{"name": "batman"}`,
+			true,
+		},
+		{
+			"should not normalize value without envelope",
+			domain.Normalization{Lift: "data.result", Drop: []string{"meta"}},
+			`{"data": {"name": "batman"}, "meta": {}}`,
+			`null`,
+			false,
+		},
+		{
+			"should drop and rename fields",
+			domain.Normalization{Drop: []string{"meta"}, Rename: map[string]string{"hero_name": "name"}},
+			`{"hero_name": "batman", "meta": {}, "city": "gotham"}`,
+			`{"name": "batman", "city": "gotham"}`,
+			true,
+		},
+		{
+			"should prefer renamed field over existing one",
+			domain.Normalization{Rename: map[string]string{"full_name": "name"}},
+			`{"full_name": "bruce wayne", "name": "batman"}`,
+			`{"name": "bruce wayne"}`,
+			true,
+		},
+		{
+			"should normalize each object of lifted list",
+			domain.Normalization{Lift: "items", Rename: map[string]string{"hero_name": "name"}},
+			`{"items": [{"hero_name": "batman"}, {"hero_name": "robin"}, "joker"]}`,
+			`[{"name": "batman"}, {"name": "robin"}, "joker"]`,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := runner.Normalize(tt.normalization, test.Unmarshal(tt.value))
+
+			test.Equal(t, ok, tt.expectedOk)
+			test.Equal(t, got, test.Unmarshal(tt.expected))
+		})
+	}
+}
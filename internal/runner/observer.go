@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"context"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+)
+
+type doneObserverKey struct{}
+
+// DoneObserver receives the result of each resource as soon as its
+// execution is done, before the query as a whole is finished.
+type DoneObserver func(resourceID domain.ResourceID, response interface{})
+
+// WithDoneObserver returns a context that makes the Runner
+// notify the observer of every resource done.
+func WithDoneObserver(ctx context.Context, observer DoneObserver) context.Context {
+	return context.WithValue(ctx, doneObserverKey{}, observer)
+}
+
+func getDoneObserver(ctx context.Context) DoneObserver {
+	observer, ok := ctx.Value(doneObserverKey{}).(DoneObserver)
+	if !ok {
+		return nil
+	}
+
+	return observer
+}
+
+type itemObserverKey struct{}
+
+// ItemObserver receives each result of the unordered multiplexed
+// statements as soon as its request is done, with its index in the
+// statement result. It may be called concurrently.
+type ItemObserver func(resourceID domain.ResourceID, index int, response interface{})
+
+// WithItemObserver returns a context that makes the Runner notify the
+// observer of every result of the multiplexed statements set as
+// unordered, before the statement as a whole is done.
+func WithItemObserver(ctx context.Context, observer ItemObserver) context.Context {
+	return context.WithValue(ctx, itemObserverKey{}, observer)
+}
+
+func getItemObserver(ctx context.Context) ItemObserver {
+	observer, ok := ctx.Value(itemObserverKey{}).(ItemObserver)
+	if !ok {
+		return nil
+	}
+
+	return observer
+}
+
+type multiplexedObserverKey struct{}
+
+type multiplexedObserver func(index int, response interface{})
+
+func withMultiplexedObserver(ctx context.Context, observer multiplexedObserver) context.Context {
+	return context.WithValue(ctx, multiplexedObserverKey{}, observer)
+}
+
+func getMultiplexedObserver(ctx context.Context) multiplexedObserver {
+	observer, ok := ctx.Value(multiplexedObserverKey{}).(multiplexedObserver)
+	if !ok {
+		return nil
+	}
+
+	return observer
+}
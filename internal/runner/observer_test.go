@@ -0,0 +1,116 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestRunnerDoneObserver(t *testing.T) {
+	client := blockingClient{release: make(chan struct{})}
+	close(client.release)
+
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+	r := runner.NewRunner(test.NoOpLogger, executor, time.Second, runner.DefaultsCascade{}, nil, 0)
+
+	query := domain.Query{Statements: []domain.Statement{
+		{Method: domain.FromMethod, Resource: "hero"},
+		{Method: domain.FromMethod, Resource: "sidekick"},
+	}}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{
+			"hero":     mapping(t, "http://hero.io/api"),
+			"sidekick": mapping(t, "http://sidekick.io/api"),
+		},
+	}
+
+	var mu sync.Mutex
+	observed := make(map[domain.ResourceID]interface{})
+	observer := func(resourceID domain.ResourceID, response interface{}) {
+		mu.Lock()
+		observed[resourceID] = response
+		mu.Unlock()
+	}
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	ctx = runner.WithDoneObserver(ctx, observer)
+
+	resources, err := r.ExecuteQuery(ctx, query, queryCtx)
+	test.VerifyError(t, err)
+
+	test.Equal(t, len(observed), 2)
+	for resourceID, response := range resources {
+		test.Equal(t, observed[resourceID].(restql.DoneResource).Status, response.(restql.DoneResource).Status)
+	}
+}
+
+// delayedClient answers each request with its id parameter,
+// after waiting for the duration set for the id.
+type delayedClient map[string]time.Duration
+
+func (c delayedClient) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	id := request.Query["id"].(string)
+	time.Sleep(c[id])
+	return restql.HTTPResponse{StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, id)}, nil
+}
+
+func TestRunnerItemObserver(t *testing.T) {
+	client := delayedClient{"1": 100 * time.Millisecond, "2": 50 * time.Millisecond, "3": 0}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+	r := runner.NewRunner(test.NoOpLogger, executor, time.Second, runner.DefaultsCascade{}, nil, 0)
+
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+	}
+
+	tests := []struct {
+		name      string
+		unordered bool
+		expected  []int
+	}{
+		{"should not notify items of ordered statements", false, nil},
+		{"should notify items of unordered statements as they are done", true, []int{2, 1, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := domain.Query{Statements: []domain.Statement{{
+				Method:    domain.FromMethod,
+				Resource:  "hero",
+				Unordered: tt.unordered,
+				With:      domain.Params{Values: map[string]interface{}{"id": []interface{}{"1", "2", "3"}}},
+			}}}
+
+			var (
+				mu       sync.Mutex
+				observed []int
+			)
+			observer := func(resourceID domain.ResourceID, index int, response interface{}) {
+				mu.Lock()
+				observed = append(observed, index)
+				mu.Unlock()
+			}
+
+			ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+			ctx = runner.WithItemObserver(ctx, observer)
+
+			resources, err := r.ExecuteQuery(ctx, query, queryCtx)
+			test.VerifyError(t, err)
+
+			test.Equal(t, observed, tt.expected)
+
+			results := resources["hero"].(restql.DoneResources)
+			test.Equal(t, len(results), 3)
+			for i, id := range []string{"1", "2", "3"} {
+				test.Equal(t, results[i].(restql.DoneResource).ResponseBody.Unmarshal(), id)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+// ErrPolicyViolation represents an error when a statement makes
+// a request its resource policy does not allow.
+var ErrPolicyViolation = errors.New("resource policy violation")
+
+// ValidatePolicies returns an error naming the first rule violated
+// by the requests of the statements, checked in identifier order.
+// Chained values are not known before execution, hence their
+// placeholders count for the body size.
+func ValidatePolicies(resources domain.Resources, queryCtx restql.QueryContext) error {
+	ids := make([]domain.ResourceID, 0, len(resources))
+	for resourceID := range resources {
+		ids = append(ids, resourceID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, resourceID := range ids {
+		for _, s := range flattenStatements(resources[resourceID]) {
+			if s.Policy == nil {
+				continue
+			}
+
+			if err := validatePolicy(s, queryCtx); err != nil {
+				return fmt.Errorf("%w: statement %s %s", ErrPolicyViolation, resourceID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validatePolicy(statement domain.Statement, queryCtx restql.QueryContext) error {
+	policy := statement.Policy
+	mapping := queryCtx.Mappings[statement.Resource]
+
+	method := httpMethod(statement)
+	if len(policy.Methods) > 0 && !containsString(policy.Methods, method) {
+		return fmt.Errorf("violates the methods rule: %s is not allowed", method)
+	}
+
+	if policy.PathParams != nil {
+		var params []string
+		for key := range statement.With.Values {
+			if mapping.IsPathParam(key) && !containsString(policy.PathParams, key) {
+				params = append(params, key)
+			}
+		}
+		sort.Strings(params)
+
+		if len(params) > 0 {
+			return fmt.Errorf("violates the pathParams rule: %v can not be set", params)
+		}
+	}
+
+	if policy.MaxBodySize > 0 {
+		var pending []string
+		statement.With.Values = markPendingValues(statement.With.Values, &pending).(map[string]interface{})
+		if statement.With.Body != nil {
+			statement.With.Body = markPendingValues(statement.With.Body, &pending)
+		}
+
+		request := MakeRequest(0, "", statement, queryCtx)
+		if request.Body == nil {
+			return nil
+		}
+
+		var size int
+		if s, ok := request.Body.(string); ok {
+			size = len(s)
+		} else {
+			data, err := json.Marshal(request.Body)
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal request body")
+			}
+			size = len(data)
+		}
+
+		if size > policy.MaxBodySize {
+			return fmt.Errorf("violates the maxBodySize rule: body of %d bytes exceeds %d", size, policy.MaxBodySize)
+		}
+	}
+
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package runner_test
+
+import (
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestValidatePolicies(t *testing.T) {
+	queryCtx := restql.QueryContext{Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api/:id/:tenant")}}
+	readOnly := &domain.ResourcePolicy{Methods: []string{"GET"}, PathParams: []string{"id"}, MaxBodySize: 20}
+
+	tests := []struct {
+		name      string
+		statement domain.Statement
+		expected  string
+	}{
+		{
+			"should allow a conforming statement",
+			domain.Statement{Method: domain.FromMethod, Resource: "hero", Policy: readOnly, With: domain.Params{Values: map[string]interface{}{"id": "1", "name": "batman"}}},
+			"",
+		},
+		{
+			"should allow any statement without policy",
+			domain.Statement{Method: domain.ToMethod, Resource: "hero", With: domain.Params{Values: map[string]interface{}{"tenant": "dc"}}},
+			"",
+		},
+		{
+			"should reject a method not allowed",
+			domain.Statement{Method: domain.ToMethod, Resource: "hero", Policy: readOnly},
+			"resource policy violation: statement hero violates the methods rule: POST is not allowed",
+		},
+		{
+			"should reject a method not allowed given by the method clause",
+			domain.Statement{Method: domain.FromMethod, HTTPMethod: "DELETE", Resource: "hero", Policy: readOnly},
+			"resource policy violation: statement hero violates the methods rule: DELETE is not allowed",
+		},
+		{
+			"should reject a path parameter not allowed",
+			domain.Statement{Method: domain.FromMethod, Resource: "hero", Policy: readOnly, With: domain.Params{Values: map[string]interface{}{"id": "1", "tenant": "dc"}}},
+			"resource policy violation: statement hero violates the pathParams rule: [tenant] can not be set",
+		},
+		{
+			"should reject a body larger than allowed",
+			domain.Statement{Method: domain.ToMethod, Resource: "hero", Policy: &domain.ResourcePolicy{MaxBodySize: 20}, With: domain.Params{Values: map[string]interface{}{"name": "bruce wayne, the batman"}}},
+			"resource policy violation: statement hero violates the maxBodySize rule: body of 34 bytes exceeds 20",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := domain.NewResources([]domain.Statement{tt.statement})
+
+			err := runner.ValidatePolicies(resources, queryCtx)
+
+			if tt.expected == "" {
+				test.VerifyError(t, err)
+				return
+			}
+			test.Equal(t, err.Error(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package runner
+
+import (
+	"context"
+	"math"
+	"runtime/pprof"
+	"runtime/trace"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidSampleRate represents the event of setting
+// a trace sample rate outside the [0, 1] interval.
+var ErrInvalidSampleRate = errors.New("invalid trace sample rate : must be between 0 and 1")
+
+type sampledKey struct{}
+
+// traceSampleKey identifies the trace sampling decision
+// among the randomized ones of the query execution.
+const traceSampleKey = "trace-sample"
+
+// Profiler annotates query executions so profiles and execution
+// traces can attribute their cost to tenants, queries and statements.
+//
+// When labels are enabled, goroutines running a query carry pprof
+// labels with its tenant, namespace, query and revision, and the
+// ones running a statement also carry its resource identifier.
+//
+// A fraction of the queries, defined by the trace sample rate,
+// is also recorded as a task with a region for each statement
+// in the runtime execution trace, when one is being collected.
+type Profiler struct {
+	labels     bool
+	sampleRate uint64
+}
+
+// NewProfiler returns a Profiler instance, with
+// trace sampling disabled.
+func NewProfiler(labels bool) *Profiler {
+	return &Profiler{labels: labels}
+}
+
+// LabelsEnabled reports if pprof labels are applied to executions.
+func (p *Profiler) LabelsEnabled() bool {
+	return p != nil && p.labels
+}
+
+// TraceSampleRate returns the fraction of queries recorded
+// in the runtime execution trace.
+func (p *Profiler) TraceSampleRate() float64 {
+	if p == nil {
+		return 0
+	}
+
+	return math.Float64frombits(atomic.LoadUint64(&p.sampleRate))
+}
+
+// SetTraceSampleRate defines the fraction of queries recorded
+// in the runtime execution trace, where 0 disables it.
+func (p *Profiler) SetTraceSampleRate(rate float64) error {
+	if rate < 0 || rate > 1 || math.IsNaN(rate) {
+		return ErrInvalidSampleRate
+	}
+
+	atomic.StoreUint64(&p.sampleRate, math.Float64bits(rate))
+	return nil
+}
+
+// startQuery annotates the goroutine executing the query,
+// returning a function that restores its previous state.
+func (p *Profiler) startQuery(ctx context.Context, options restql.QueryOptions) (context.Context, func()) {
+	if p == nil {
+		return ctx, func() {}
+	}
+
+	parent := ctx
+	if p.labels {
+		ctx = pprof.WithLabels(ctx, queryLabels(options))
+		pprof.SetGoroutineLabels(ctx)
+	}
+
+	var task *trace.Task
+	if rate := p.TraceSampleRate(); rate > 0 && restql.Random(ctx, traceSampleKey) < rate {
+		ctx, task = trace.NewTask(ctx, "restql.query")
+		ctx = context.WithValue(ctx, sampledKey{}, true)
+		trace.Log(ctx, "tenant", options.Tenant)
+		if options.Id != "" {
+			trace.Log(ctx, "query", options.Namespace+"/"+options.Id+"/"+strconv.Itoa(options.Revision))
+		}
+	}
+
+	return ctx, func() {
+		if task != nil {
+			task.End()
+		}
+		if p.labels {
+			pprof.SetGoroutineLabels(parent)
+		}
+	}
+}
+
+// startStatement annotates the goroutine executing the statement,
+// which is expected to end along with it.
+func (p *Profiler) startStatement(ctx context.Context, resourceID domain.ResourceID) (context.Context, func()) {
+	if p == nil {
+		return ctx, func() {}
+	}
+
+	if p.labels {
+		ctx = pprof.WithLabels(ctx, pprof.Labels("statement", string(resourceID)))
+		pprof.SetGoroutineLabels(ctx)
+	}
+
+	if sampled, _ := ctx.Value(sampledKey{}).(bool); sampled {
+		region := trace.StartRegion(ctx, "restql.statement "+string(resourceID))
+		return ctx, region.End
+	}
+
+	return ctx, func() {}
+}
+
+func queryLabels(options restql.QueryOptions) pprof.LabelSet {
+	labels := []string{"tenant", options.Tenant}
+	if options.Id != "" {
+		labels = append(labels,
+			"namespace", options.Namespace,
+			"query", options.Id,
+			"revision", strconv.Itoa(options.Revision),
+		)
+	}
+
+	return pprof.Labels(labels...)
+}
@@ -0,0 +1,81 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+type labelsClient struct {
+	labels chan map[string]string
+}
+
+func (c labelsClient) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	labels := make(map[string]string)
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		labels[key] = value
+		return true
+	})
+	c.labels <- labels
+
+	return restql.HTTPResponse{StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, "ok")}, nil
+}
+
+func TestProfilerLabels(t *testing.T) {
+	client := labelsClient{labels: make(chan map[string]string, 1)}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+	r := runner.NewRunner(test.NoOpLogger, executor, time.Second, runner.DefaultsCascade{}, runner.NewProfiler(true), 0)
+
+	query := domain.Query{Statements: []domain.Statement{{Method: domain.FromMethod, Resource: "hero"}}}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+		Options:  restql.QueryOptions{Tenant: "acme", Namespace: "heroes", Id: "all", Revision: 2},
+	}
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	_, err := r.ExecuteQuery(ctx, query, queryCtx)
+	test.VerifyError(t, err)
+
+	expected := map[string]string{
+		"tenant":    "acme",
+		"namespace": "heroes",
+		"query":     "all",
+		"revision":  "2",
+		"statement": "hero",
+	}
+	test.Equal(t, <-client.labels, expected)
+}
+
+func TestProfilerTraceSampleRate(t *testing.T) {
+	tests := []struct {
+		name         string
+		rate         float64
+		expectedErr  error
+		expectedRate float64
+	}{
+		{"should set sample rate", 0.25, nil, 0.25},
+		{"should disable sampling", 0, nil, 0},
+		{"should reject negative rate", -0.1, runner.ErrInvalidSampleRate, 0},
+		{"should reject rate greater than one", 1.5, runner.ErrInvalidSampleRate, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiler := runner.NewProfiler(false)
+
+			err := profiler.SetTraceSampleRate(tt.rate)
+
+			if err != tt.expectedErr {
+				t.Fatalf("SetTraceSampleRate = %v, want %v", err, tt.expectedErr)
+			}
+			test.Equal(t, profiler.TraceSampleRate(), tt.expectedRate)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package runner
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+)
+
+// MaxRangeLength is the maximum number of values a `range`
+// parameter can generate, limiting the statement fan out.
+const MaxRangeLength = 1000
+
+// ExpandRanges transforms the range parameter values with all
+// of its arguments resolved into the list of generated integers,
+// which are then multiplexed as any other list parameter.
+//
+// Ranges whose arguments are not integers, or with a null step,
+// or that generate more than MaxRangeLength values are replaced
+// by an empty chained value, so the statement is not executed.
+func ExpandRanges(resources domain.Resources) domain.Resources {
+	for resourceID, stmt := range resources {
+		if stmt, ok := stmt.(domain.Statement); ok {
+			for key, value := range stmt.With.Values {
+				stmt.With.Values[key] = expandRangeValue(value)
+			}
+			resources[resourceID] = stmt
+		}
+	}
+
+	return resources
+}
+
+func expandRangeValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case domain.Range:
+		return expandRange(value)
+	case domain.Function:
+		return value.Map(expandRangeValue)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			m[k] = expandRangeValue(v)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(value))
+		for i, v := range value {
+			l[i] = expandRangeValue(v)
+		}
+		return l
+	default:
+		return value
+	}
+}
+
+func expandRange(r domain.Range) interface{} {
+	for _, arg := range []interface{}{r.Start, r.End, r.Step} {
+		if _, ok := arg.(domain.Chain); ok {
+			return r
+		}
+	}
+
+	start, startOk := rangeInt(r.Start)
+	end, endOk := rangeInt(r.End)
+	step, stepOk := rangeInt(r.Step)
+	if !startOk || !endOk || !stepOk || step == 0 {
+		return EmptyChained
+	}
+
+	if (step > 0 && start > end) || (step < 0 && start < end) {
+		return []interface{}{}
+	}
+
+	// unsigned arithmetic keeps the distance between
+	// the bounds exact even when int64 would overflow
+	distance, stride := uint64(end)-uint64(start), uint64(step)
+	if step < 0 {
+		distance, stride = uint64(start)-uint64(end), -uint64(step)
+	}
+
+	if distance/stride >= MaxRangeLength {
+		return EmptyChained
+	}
+
+	result := make([]interface{}, distance/stride+1)
+	for i := range result {
+		result[i] = int(start + int64(i)*step)
+	}
+
+	return result
+}
+
+func rangeInt(value interface{}) (int64, bool) {
+	switch value := value.(type) {
+	case int:
+		return int64(value), true
+	case float64:
+		if value != math.Trunc(value) || math.Abs(value) > 1<<53 {
+			return 0, false
+		}
+		return int64(value), true
+	case string:
+		i, err := strconv.ParseInt(value, 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,90 @@
+package runner_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestExpandRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected interface{}
+	}{
+		{
+			"should expand range inclusive of end",
+			domain.Range{Start: 1, End: 4, Step: 1},
+			[]interface{}{1, 2, 3, 4},
+		},
+		{
+			"should expand range with step",
+			domain.Range{Start: 0, End: 9, Step: 3},
+			[]interface{}{0, 3, 6, 9},
+		},
+		{
+			"should expand descending range",
+			domain.Range{Start: 3, End: 1, Step: -1},
+			[]interface{}{3, 2, 1},
+		},
+		{
+			"should expand range with resolved chained and variable values",
+			domain.Range{Start: "2", End: float64(4), Step: 1},
+			[]interface{}{2, 3, 4},
+		},
+		{
+			"should expand range to empty list when end is before start",
+			domain.Range{Start: 5, End: 1, Step: 1},
+			[]interface{}{},
+		},
+		{
+			"should expand range inside function",
+			domain.NoMultiplex{Value: domain.Range{Start: 1, End: 2, Step: 1}},
+			domain.NoMultiplex{Value: []interface{}{1, 2}},
+		},
+		{
+			"should keep range with unresolved chain",
+			domain.Range{Start: 1, End: domain.Chain{"hero", "pages"}, Step: 1},
+			domain.Range{Start: 1, End: domain.Chain{"hero", "pages"}, Step: 1},
+		},
+		{
+			"should return empty chained when argument is not an integer",
+			domain.Range{Start: 1, End: float64(2.5), Step: 1},
+			runner.EmptyChained,
+		},
+		{
+			"should return empty chained when chain could not be resolved",
+			domain.Range{Start: 1, End: runner.EmptyChained, Step: 1},
+			runner.EmptyChained,
+		},
+		{
+			"should return empty chained when step is zero",
+			domain.Range{Start: 1, End: 2, Step: 0},
+			runner.EmptyChained,
+		},
+		{
+			"should return empty chained when range is too long",
+			domain.Range{Start: 1, End: runner.MaxRangeLength + 1, Step: 1},
+			runner.EmptyChained,
+		},
+		{
+			"should return empty chained when range length overflows",
+			domain.Range{Start: math.MinInt64, End: math.MaxInt64, Step: 1},
+			runner.EmptyChained,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := domain.Resources{"hero": domain.Statement{Resource: "hero", With: domain.Params{Values: map[string]interface{}{"page": tt.input}}}}
+
+			got := runner.ExpandRanges(resources)
+
+			expected := domain.Resources{"hero": domain.Statement{Resource: "hero", With: domain.Params{Values: map[string]interface{}{"page": tt.expected}}}}
+			test.Equal(t, got, expected)
+		})
+	}
+}
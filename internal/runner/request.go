@@ -30,7 +30,7 @@ var queryMethodToHTTPMethod = map[string]string{
 // MakeRequest builds a HTTPRequest from a statement.
 func MakeRequest(defaultResourceTimeout time.Duration, forwardPrefix string, statement domain.Statement, queryCtx restql.QueryContext) restql.HTTPRequest {
 	mapping := queryCtx.Mappings[statement.Resource]
-	method := queryMethodToHTTPMethod[statement.Method]
+	method := httpMethod(statement)
 	headers := makeHeaders(statement, queryCtx)
 	path := mapping.PathWithParams(statement.With.Values)
 	queryParams := makeQueryParams(forwardPrefix, statement, mapping, queryCtx)
@@ -44,6 +44,8 @@ func MakeRequest(defaultResourceTimeout time.Duration, forwardPrefix string, sta
 		Query:   queryParams,
 		Headers: headers,
 		Timeout: timeout,
+
+		MaxResponseSize: statement.MaxResponseSize,
 	}
 
 	if statement.Method == domain.ToMethod || statement.Method == domain.UpdateMethod || statement.Method == domain.IntoMethod {
@@ -53,6 +55,16 @@ func MakeRequest(defaultResourceTimeout time.Duration, forwardPrefix string, sta
 	return req
 }
 
+// httpMethod returns the method declared by the statement `method`
+// clause or, if there is none, the one mapped from its keyword.
+func httpMethod(statement domain.Statement) string {
+	if statement.HTTPMethod != "" {
+		return statement.HTTPMethod
+	}
+
+	return queryMethodToHTTPMethod[statement.Method]
+}
+
 func makeBody(statement domain.Statement, mapping restql.Mapping) restql.Body {
 	if statement.With.Body != nil {
 		return statement.With.Body
@@ -90,7 +102,7 @@ func parseBodyValue(value interface{}) interface{} {
 }
 
 func makeHeaders(statement domain.Statement, queryCtx restql.QueryContext) map[string]string {
-	headers := getForwardHeaders(queryCtx)
+	headers := getForwardHeaders(queryCtx, statement.ForwardConditionalHeaders, statement.ForwardHeaders)
 	for key, value := range statement.Headers {
 		str, ok := value.(string)
 		if !ok {
@@ -117,12 +129,15 @@ func isDisallowedHeader(header string) bool {
 	return false
 }
 
-func getForwardHeaders(queryCtx restql.QueryContext) map[string]string {
+func getForwardHeaders(queryCtx restql.QueryContext, forwardConditional bool, policy *domain.HeaderForwarding) map[string]string {
 	r := make(map[string]string)
 	for k, v := range queryCtx.Input.Headers {
-		if !isDisallowedHeader(k) {
-			k = http.CanonicalHeaderKey(k)
-			r[k] = v
+		if !forwardConditional && isConditionalHeader(k) {
+			continue
+		}
+
+		if name, ok := forwardedHeaderName(policy, k); ok {
+			r[name] = v
 		}
 	}
 	return r
@@ -131,6 +146,10 @@ func getForwardHeaders(queryCtx restql.QueryContext) map[string]string {
 func makeQueryParams(forwardPrefix string, statement domain.Statement, mapping restql.Mapping, queryCtx restql.QueryContext) map[string]interface{} {
 	queryArgs := getForwardParams(forwardPrefix, queryCtx)
 
+	for key, value := range statement.DefaultParams {
+		queryArgs[key] = value
+	}
+
 	for key, value := range mapping.QueryWithParams(statement.With.Values) {
 		queryArgs[key] = value
 	}
@@ -2,9 +2,12 @@ package runner
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
 )
@@ -34,11 +37,30 @@ func NewDoneResource(request restql.HTTPRequest, response restql.HTTPResponse, o
 		ResponseHeaders: response.Headers,
 		ResponseBody:    response.Body,
 		ResponseTime:    response.Duration.Milliseconds(),
+		Throttled:       isThrottled(response),
 	}
 
 	return dr
 }
 
+// selectReturnedHeaders picks the response headers listed on the
+// statement `return-headers` clause, keyed by the name used on the
+// query, ignoring the ones absent from the upstream response.
+func selectReturnedHeaders(statement domain.Statement, dr restql.DoneResource) map[string]string {
+	if len(statement.ReturnHeaders) == 0 {
+		return nil
+	}
+
+	headers := make(map[string]string)
+	for _, name := range statement.ReturnHeaders {
+		if value, found := getValueFromHeader(name, dr.ResponseHeaders); found {
+			headers[name] = value
+		}
+	}
+
+	return headers
+}
+
 // NewErrorResponse builds a DoneResource value for a failed HTTP call.
 func NewErrorResponse(log restql.Logger, err error, request restql.HTTPRequest, response restql.HTTPResponse, options DoneResourceOptions) restql.DoneResource {
 	rb := restql.NewResponseBodyFromValue(log, err.Error())
@@ -58,6 +80,61 @@ func NewErrorResponse(log restql.Logger, err error, request restql.HTTPRequest,
 	}
 }
 
+// NewMultiplexLimitResponse builds a DoneResource for a statement
+// which list parameters expand into more requests than allowed.
+func NewMultiplexLimitResponse(log restql.Logger, count uint64, limit int, options DoneResourceOptions) restql.DoneResource {
+	msg := fmt.Sprintf("The request was rejected as its list parameters expand into %d requests, exceeding the limit of %d", count, limit)
+
+	return restql.DoneResource{
+		Status:       http.StatusRequestEntityTooLarge,
+		Success:      false,
+		IgnoreErrors: options.IgnoreErrors,
+		ResponseBody: restql.NewResponseBodyFromValue(log, msg),
+	}
+}
+
+// NewRateLimitResponse builds a DoneResource for a statement
+// rejected by the rate limit of its resource.
+func NewRateLimitResponse(log restql.Logger, resource string, retryAfter time.Duration, options DoneResourceOptions) restql.DoneResource {
+	msg := fmt.Sprintf("The request was rejected as it exceeds the rate limit of resource %s", resource)
+
+	return restql.DoneResource{
+		Status:          http.StatusTooManyRequests,
+		Success:         false,
+		IgnoreErrors:    options.IgnoreErrors,
+		ResponseBody:    restql.NewResponseBodyFromValue(log, msg),
+		ResponseHeaders: map[string]string{"Retry-After": retryAfterSeconds(retryAfter)},
+	}
+}
+
+// BulkheadRejectedStatus is the status of the statements whose
+// requests were rejected by the bulkhead of their resource, which
+// is distinct from the statuses returned by the upstreams.
+const BulkheadRejectedStatus = 529
+
+// NewBulkheadRejectedResponse builds a DoneResource for a statement
+// rejected because its resource has too many requests in flight.
+func NewBulkheadRejectedResponse(log restql.Logger, resource string, options DoneResourceOptions) restql.DoneResource {
+	msg := fmt.Sprintf("The request was rejected as resource %s has too many requests in flight", resource)
+
+	return restql.DoneResource{
+		Status:       BulkheadRejectedStatus,
+		Success:      false,
+		IgnoreErrors: options.IgnoreErrors,
+		ResponseBody: restql.NewResponseBodyFromValue(log, msg),
+	}
+}
+
+// retryAfterSeconds formats the duration as the whole
+// seconds of a Retry-After header, rounding up.
+func retryAfterSeconds(d time.Duration) string {
+	seconds := int64((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
+
 // NewEmptyChainedResponse builds a DoneResource for a statement
 // with unresolved chain parameters.
 func NewEmptyChainedResponse(log restql.Logger, params []string, options DoneResourceOptions) restql.DoneResource {
@@ -137,7 +214,12 @@ func makeCacheControl(response restql.HTTPResponse, options DoneResourceOptions)
 }
 
 func bestCacheControl(first restql.ResourceCacheControl, second restql.ResourceCacheControl) restql.ResourceCacheControl {
-	result := restql.ResourceCacheControl{}
+	result := restql.ResourceCacheControl{Private: first.Private || second.Private}
+
+	if first.NoStore || second.NoStore {
+		result.NoStore = true
+		return result
+	}
 
 	if first.NoCache || second.NoCache {
 		result.NoCache = true
@@ -214,8 +296,22 @@ func getCacheControlOptionsFromHeader(response restql.HTTPResponse) (cc restql.R
 	for _, ccField := range cacheControlFields {
 		ccField = strings.TrimSpace(ccField)
 
+		if strings.EqualFold(ccField, "no-store") {
+			found = true
+			cc.NoStore = true
+			continue
+		}
+
 		if strings.EqualFold(ccField, "no-cache") {
-			return restql.ResourceCacheControl{NoCache: true}, true
+			found = true
+			cc.NoCache = true
+			continue
+		}
+
+		if strings.EqualFold(ccField, "private") {
+			found = true
+			cc.Private = true
+			continue
 		}
 
 		keyValue := strings.Split(ccField, "=")
@@ -246,5 +342,12 @@ func getCacheControlOptionsFromHeader(response restql.HTTPResponse) (cc restql.R
 		}
 	}
 
+	switch {
+	case cc.NoStore:
+		return restql.ResourceCacheControl{NoStore: true, Private: cc.Private}, true
+	case cc.NoCache:
+		return restql.ResourceCacheControl{NoCache: true, Private: cc.Private}, true
+	}
+
 	return cc, found
 }
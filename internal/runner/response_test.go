@@ -106,6 +106,56 @@ func TestNewDoneResource(t *testing.T) {
 				ResponseBody:    nil,
 			},
 		},
+		{
+			"should create done resource with no-store cache control information returned by resource",
+			restql.HTTPRequest{},
+			restql.HTTPResponse{StatusCode: 200, Body: nil, Headers: map[string]string{"Cache-Control": "no-cache, no-store"}},
+			runner.DoneResourceOptions{MaxAge: 400},
+			restql.DoneResource{
+				Status:  200,
+				Success: true,
+				CacheControl: restql.ResourceCacheControl{
+					NoStore: true,
+				},
+				ResponseHeaders: map[string]string{"Cache-Control": "no-cache, no-store"},
+				IgnoreErrors:    false,
+				ResponseBody:    nil,
+			},
+		},
+		{
+			"should create done resource keeping private with no-cache cache control information returned by resource",
+			restql.HTTPRequest{},
+			restql.HTTPResponse{StatusCode: 200, Body: nil, Headers: map[string]string{"Cache-Control": "private, no-cache"}},
+			runner.DoneResourceOptions{MaxAge: 400},
+			restql.DoneResource{
+				Status:  200,
+				Success: true,
+				CacheControl: restql.ResourceCacheControl{
+					NoCache: true,
+					Private: true,
+				},
+				ResponseHeaders: map[string]string{"Cache-Control": "private, no-cache"},
+				IgnoreErrors:    false,
+				ResponseBody:    nil,
+			},
+		},
+		{
+			"should create done resource with private cache control information returned by resource",
+			restql.HTTPRequest{},
+			restql.HTTPResponse{StatusCode: 200, Body: nil, Headers: map[string]string{"Cache-Control": "private, max-age=600"}},
+			runner.DoneResourceOptions{MaxAge: 400},
+			restql.DoneResource{
+				Status:  200,
+				Success: true,
+				CacheControl: restql.ResourceCacheControl{
+					Private: true,
+					MaxAge:  restql.ResourceCacheControlValue{Exist: true, Time: 400},
+				},
+				ResponseHeaders: map[string]string{"Cache-Control": "private, max-age=600"},
+				IgnoreErrors:    false,
+				ResponseBody:    nil,
+			},
+		},
 		{
 			"should create done resource with cache control information defined in statement if not returned by resource",
 			restql.HTTPRequest{},
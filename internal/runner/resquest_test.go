@@ -41,6 +41,24 @@ func TestMakeRequest(t *testing.T) {
 			restql.QueryContext{Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")}},
 			restql.HTTPRequest{Method: http.MethodPut, Schema: "http", Host: "hero.io", Path: "/api", Query: map[string]interface{}{}, Body: map[string]interface{}{"id": 1}, Headers: map[string]string{"Content-Type": "application/json"}},
 		},
+		{
+			"should make custom method request with body",
+			domain.Statement{Method: domain.ToMethod, HTTPMethod: "PURGE", Resource: "hero", With: domain.Params{Values: map[string]interface{}{"id": 1}}},
+			restql.QueryContext{Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")}},
+			restql.HTTPRequest{Method: "PURGE", Schema: "http", Host: "hero.io", Path: "/api", Query: map[string]interface{}{}, Body: map[string]interface{}{"id": 1}, Headers: map[string]string{"Content-Type": "application/json"}},
+		},
+		{
+			"should make custom method request with query params",
+			domain.Statement{Method: domain.FromMethod, HTTPMethod: "REPORT", Resource: "hero", With: domain.Params{Values: map[string]interface{}{"id": "123456"}}},
+			restql.QueryContext{Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")}},
+			restql.HTTPRequest{Method: "REPORT", Schema: "http", Host: "hero.io", Path: "/api", Query: map[string]interface{}{"id": "123456"}, Headers: map[string]string{"Content-Type": "application/json"}},
+		},
+		{
+			"should make request with default params in query",
+			domain.Statement{Method: domain.ToMethod, Resource: "hero", DefaultParams: map[string]string{"apiKey": "acme-key"}, With: domain.Params{Values: map[string]interface{}{"id": 1}}},
+			restql.QueryContext{Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")}},
+			restql.HTTPRequest{Method: http.MethodPost, Schema: "http", Host: "hero.io", Path: "/api", Query: map[string]interface{}{"apiKey": "acme-key"}, Body: map[string]interface{}{"id": 1}, Headers: map[string]string{"Content-Type": "application/json"}},
+		},
 		{
 			"should make delete request with url",
 			domain.Statement{Method: domain.DeleteMethod, Resource: "hero"},
@@ -110,6 +128,46 @@ func TestMakeRequest(t *testing.T) {
 			restql.QueryContext{Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")}, Input: restql.QueryInput{Headers: map[string]string{"Accept": "*/*"}}},
 			restql.HTTPRequest{Method: http.MethodGet, Schema: "http", Host: "hero.io", Path: "/api", Query: map[string]interface{}{}, Headers: map[string]string{"X-Tid": "1234567890", "Content-Type": "application/json", "Accept": "application/json"}},
 		},
+		{
+			"should not forward conditional headers by default",
+			domain.Statement{Method: domain.FromMethod, Resource: "hero"},
+			restql.QueryContext{
+				Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+				Input:    restql.QueryInput{Headers: map[string]string{"If-None-Match": `"abc"`, "If-Modified-Since": "Wed, 21 Oct 2015 07:28:00 GMT"}},
+			},
+			restql.HTTPRequest{Method: http.MethodGet, Schema: "http", Host: "hero.io", Path: "/api", Query: map[string]interface{}{}, Headers: map[string]string{"Content-Type": "application/json"}},
+		},
+		{
+			"should forward conditional headers when enabled for the statement",
+			domain.Statement{Method: domain.FromMethod, Resource: "hero", ForwardConditionalHeaders: true},
+			restql.QueryContext{
+				Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+				Input:    restql.QueryInput{Headers: map[string]string{"If-None-Match": `"abc"`}},
+			},
+			restql.HTTPRequest{Method: http.MethodGet, Schema: "http", Host: "hero.io", Path: "/api", Query: map[string]interface{}{}, Headers: map[string]string{"If-None-Match": `"abc"`, "Content-Type": "application/json"}},
+		},
+		{
+			"should forward only allowed headers not denied, with renaming",
+			domain.Statement{
+				Method:   domain.FromMethod,
+				Resource: "hero",
+				ForwardHeaders: &domain.HeaderForwarding{
+					Allow:  []string{"Authorization", "x-trace-*"},
+					Deny:   []string{"X-Trace-Debug"},
+					Rename: map[string]string{"authorization": "X-Upstream-Auth"},
+				},
+			},
+			restql.QueryContext{
+				Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+				Input: restql.QueryInput{Headers: map[string]string{
+					"Authorization": "Bearer abcdefgh",
+					"X-Trace-Id":    "123",
+					"X-Trace-Debug": "true",
+					"Cookie":        "session=1",
+				}},
+			},
+			restql.HTTPRequest{Method: http.MethodGet, Schema: "http", Host: "hero.io", Path: "/api", Query: map[string]interface{}{}, Headers: map[string]string{"X-Upstream-Auth": "Bearer abcdefgh", "X-Trace-Id": "123", "Content-Type": "application/json"}},
+		},
 	}
 
 	forwardPrefix := "c_"
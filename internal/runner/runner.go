@@ -21,20 +21,84 @@ type Runner struct {
 	log                restql.Logger
 	executor           Executor
 	globalQueryTimeout time.Duration
+	defaults           DefaultsCascade
+	tracker            *executionTracker
+	stats              *statsRecorder
+	profiler           *Profiler
+	maxChainDepth      int
 }
 
 // NewRunner returns a Runner instance.
-func NewRunner(log restql.Logger, executor Executor, globalQueryTimeout time.Duration) Runner {
+// A non positive maxChainDepth does not limit the chain depth.
+func NewRunner(log restql.Logger, executor Executor, globalQueryTimeout time.Duration, defaults DefaultsCascade, profiler *Profiler, maxChainDepth int) Runner {
 	return Runner{
 		log:                log,
 		executor:           executor,
 		globalQueryTimeout: globalQueryTimeout,
+		defaults:           defaults,
+		tracker:            newExecutionTracker(),
+		stats:              newStatsRecorder(StatsWindow),
+		profiler:           profiler,
+		maxChainDepth:      maxChainDepth,
 	}
 }
 
+// Profiler returns the Profiler annotating the query executions.
+func (r Runner) Profiler() *Profiler {
+	return r.profiler
+}
+
+// ActiveExecutions returns a snapshot of the queries being executed.
+func (r Runner) ActiveExecutions() []ExecutionSnapshot {
+	return r.tracker.snapshot()
+}
+
+// ResourceHealth returns the statistics and last failure of the most
+// recent responses of the resource for the tenant, if there are any.
+func (r Runner) ResourceHealth(tenant string, resource string) *ResourceHealth {
+	return r.stats.health(tenant, resource)
+}
+
+// HasMock returns true if the resource has a mock declared
+// for the tenant, which is served when it is not mapped.
+func (r Runner) HasMock(tenant string, resource string) bool {
+	return r.defaults.HasMock(tenant, resource)
+}
+
+// Strict returns true if the query executed for the tenant
+// with the modifiers runs in strict mode.
+func (r Runner) Strict(tenant string, modifiers domain.Modifiers) bool {
+	return r.defaults.Strict(tenant, modifiers)
+}
+
+// AllowsNamespace returns true if the tenant
+// can run the saved queries of the namespace.
+func (r Runner) AllowsNamespace(tenant string, namespace string) bool {
+	return r.defaults.AllowsNamespace(tenant, namespace)
+}
+
+// PlanQuery resolves the defaults cascade for each statement
+// in the query without executing it.
+func (r Runner) PlanQuery(query domain.Query, queryCtx restql.QueryContext) []StatementPlan {
+	plans := make([]StatementPlan, len(query.Statements))
+	for i, stmt := range query.Statements {
+		_, plans[i] = r.defaults.Resolve(queryCtx.Options.Tenant, queryCtx.Options.Namespace, query.Use, stmt)
+		plans[i].Stats = r.stats.stats(queryCtx.Options.Tenant, stmt.Resource)
+	}
+
+	return plans
+}
+
 // ExecuteQuery process a query into a Resource collection.
 func (r Runner) ExecuteQuery(ctx context.Context, query domain.Query, queryCtx restql.QueryContext) (domain.Resources, error) {
 	log := restql.GetLogger(ctx)
+	start := time.Now()
+	queryStart, timeline := startTimeline(ctx)
+
+	ctx, endProfiling := r.profiler.startQuery(ctx, queryCtx.Options)
+	defer endProfiling()
+
+	ctx = withSessionJars(ctx)
 
 	var cancel context.CancelFunc
 	queryTimeout, ok := r.parseQueryTimeout(query)
@@ -50,6 +114,14 @@ func (r Runner) ExecuteQuery(ctx context.Context, query domain.Query, queryCtx r
 		return nil, err
 	}
 
+	var dependencies map[domain.ResourceID][]string
+	if timeline {
+		dependencies = timelineDependencies(resources)
+	}
+
+	exec := r.tracker.start(queryCtx.Options, resources)
+	defer r.tracker.finish(exec)
+
 	state := NewState(resources)
 
 	requestCh := make(chan request, 10)
@@ -62,17 +134,28 @@ func (r Runner) ExecuteQuery(ctx context.Context, query domain.Query, queryCtx r
 		requestCh: requestCh,
 		resultCh:  resultCh,
 		outputCh:  outputCh,
+		errorCh:   errorCh,
 		state:     state,
+		execution: exec,
+		chains:    NewChainArena(),
+		strict:    r.defaults.Strict(queryCtx.Options.Tenant, query.Use),
+		memory:    newQueryMemory(r.defaults.MaxQueryMemory(queryCtx.Options.Tenant)),
+		observer:  getDoneObserver(ctx),
 		ctx:       ctx,
 	}
 
 	requestWorker := &requestWorker{
-		requestCh: requestCh,
-		resultCh:  resultCh,
-		errorCh:   errorCh,
-		executor:  r.executor,
-		queryCtx:  queryCtx,
-		ctx:       ctx,
+		requestCh:    requestCh,
+		resultCh:     resultCh,
+		errorCh:      errorCh,
+		executor:     r.executor,
+		profiler:     r.profiler,
+		stats:        r.stats,
+		queryCtx:     queryCtx,
+		ctx:          ctx,
+		timeline:     timeline,
+		queryStart:   queryStart,
+		dependencies: dependencies,
 	}
 
 	go stateWorker.Run()
@@ -80,12 +163,14 @@ func (r Runner) ExecuteQuery(ctx context.Context, query domain.Query, queryCtx r
 
 	select {
 	case output := <-outputCh:
+		evaluateQuerySLO(ctx, query, queryCtx, time.Since(start))
 		return output, nil
 	case err := <-errorCh:
 		log.Debug("an error occurred when running the query", "error", err)
 		return nil, err
 	case <-ctx.Done():
 		log.Debug("query timed out")
+		evaluateQuerySLO(ctx, query, queryCtx, time.Since(start))
 		return nil, ErrQueryTimedOut
 	}
 }
@@ -112,16 +197,28 @@ func (r Runner) initializeResources(query domain.Query, queryCtx restql.QueryCon
 		return nil, err
 	}
 
-	resources = ApplyModifiers(resources, query.Use)
+	err = ValidateChainDependencies(resources, r.maxChainDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	resources = ApplyDefaults(resources, query.Use, queryCtx.Options.Tenant, queryCtx.Options.Namespace, r.defaults)
+	resources = ExpandRanges(resources)
 	resources = ApplyEncoders(resources, r.log)
 	resources = MultiplexStatements(resources)
 
+	err = ValidatePolicies(resources, queryCtx)
+	if err != nil {
+		return nil, err
+	}
+
 	return resources, nil
 }
 
 type request struct {
 	ResourceIdentifier domain.ResourceID
 	Statement          interface{}
+	AvailableAt        time.Time
 }
 
 type result struct {
@@ -134,18 +231,37 @@ type stateWorker struct {
 	requestCh chan request
 	resultCh  chan result
 	outputCh  chan domain.Resources
+	errorCh   chan error
 	state     *State
+	execution *execution
+	chains    *ChainArena
+	strict    bool
+	memory    *queryMemory
+	observer  DoneObserver
 	ctx       context.Context
 }
 
 func (sw *stateWorker) Run() {
 	for !sw.state.HasFinished() {
 		availableResources := sw.state.Available()
+		availableAt := time.Now()
 		for resourceID := range availableResources {
 			sw.state.SetAsRequest(resourceID)
+			sw.execution.setStatus(resourceID, StatementRequested)
 		}
 
-		availableResources = ResolveChainedValues(availableResources, sw.state.Done())
+		availableResources = sw.chains.Resolve(availableResources, sw.state.Done())
+		if sw.strict {
+			if err := missingChainsError(sw.chains); err != nil {
+				select {
+				case sw.errorCh <- err:
+				case <-sw.ctx.Done():
+				}
+				return
+			}
+		}
+
+		availableResources = ExpandRanges(availableResources)
 		availableResources = ApplyEncoders(availableResources, sw.log)
 		availableResources = MultiplexStatements(availableResources)
 		availableResources = UnwrapNoMultiplex(availableResources)
@@ -154,7 +270,7 @@ func (sw *stateWorker) Run() {
 			resourceID, stmt := resourceID, stmt
 			go func() {
 				select {
-				case sw.requestCh <- request{ResourceIdentifier: resourceID, Statement: stmt}:
+				case sw.requestCh <- request{ResourceIdentifier: resourceID, Statement: stmt, AvailableAt: availableAt}:
 				case <-sw.ctx.Done():
 				}
 			}()
@@ -162,12 +278,26 @@ func (sw *stateWorker) Run() {
 
 		select {
 		case result := <-sw.resultCh:
+			if !sw.memory.add(result.Response) {
+				sw.memory.record(true)
+				select {
+				case sw.errorCh <- ErrQueryMemoryExceeded:
+				case <-sw.ctx.Done():
+				}
+				return
+			}
+
 			sw.state.UpdateDone(result.ResourceIdentifier, result.Response)
+			sw.execution.setStatus(result.ResourceIdentifier, StatementDone)
+			if sw.observer != nil {
+				sw.observer(result.ResourceIdentifier, result.Response)
+			}
 		case <-sw.ctx.Done():
 			return
 		}
 	}
 
+	sw.memory.record(false)
 	select {
 	case sw.outputCh <- sw.state.Done():
 	case <-sw.ctx.Done():
@@ -175,12 +305,17 @@ func (sw *stateWorker) Run() {
 }
 
 type requestWorker struct {
-	requestCh chan request
-	resultCh  chan result
-	errorCh   chan error
-	executor  Executor
-	queryCtx  restql.QueryContext
-	ctx       context.Context
+	requestCh    chan request
+	resultCh     chan result
+	errorCh      chan error
+	executor     Executor
+	profiler     *Profiler
+	stats        *statsRecorder
+	queryCtx     restql.QueryContext
+	ctx          context.Context
+	timeline     bool
+	queryStart   time.Time
+	dependencies map[domain.ResourceID][]string
 }
 
 func (rw *requestWorker) Run() {
@@ -193,13 +328,26 @@ func (rw *requestWorker) Run() {
 			switch statement := statement.(type) {
 			case domain.Statement:
 				go func() {
-					response := rw.executor.DoStatement(rw.ctx, statement, rw.queryCtx)
-					writeResult(rw.ctx, rw.resultCh, result{ResourceIdentifier: resourceID, Response: response})
+					ctx, endProfiling := rw.profiler.startStatement(rw.ctx, resourceID)
+					defer endProfiling()
+
+					startedAt := time.Now()
+					response := rw.executor.DoStatement(ctx, statement, rw.queryCtx)
+					rw.stats.record(rw.queryCtx.Options.Tenant, statement, response)
+					evaluateStatementSLO(rw.ctx, rw.queryCtx, resourceID, statement, response)
+					writeResult(rw.ctx, rw.resultCh, result{ResourceIdentifier: resourceID, Response: rw.stampTimeline(req, startedAt, response)})
 				}()
 			case []interface{}:
 				go func() {
-					responses := rw.executor.DoMultiplexedStatement(rw.ctx, statement, rw.queryCtx)
-					writeResult(rw.ctx, rw.resultCh, result{ResourceIdentifier: resourceID, Response: responses})
+					ctx, endProfiling := rw.profiler.startStatement(rw.ctx, resourceID)
+					defer endProfiling()
+
+					startedAt := time.Now()
+					ctx = observeItems(ctx, resourceID, statement)
+					responses := rw.executor.DoMultiplexedStatement(ctx, statement, rw.queryCtx)
+					rw.stats.record(rw.queryCtx.Options.Tenant, statement, responses)
+					evaluateStatementSLO(rw.ctx, rw.queryCtx, resourceID, statement, responses)
+					writeResult(rw.ctx, rw.resultCh, result{ResourceIdentifier: resourceID, Response: rw.stampTimeline(req, startedAt, responses)})
 				}()
 			}
 		case <-rw.ctx.Done():
@@ -208,9 +356,32 @@ func (rw *requestWorker) Run() {
 	}
 }
 
+// stampTimeline sets, when timelines are recorded, when the
+// statement became available and started to be executed.
+func (rw *requestWorker) stampTimeline(req request, startedAt time.Time, response interface{}) interface{} {
+	if !rw.timeline {
+		return response
+	}
+
+	return stampTimeline(response, rw.queryStart, req.AvailableAt, startedAt, rw.dependencies[req.ResourceIdentifier])
+}
+
 func writeResult(ctx context.Context, out chan result, r result) {
 	select {
 	case out <- r:
 	case <-ctx.Done():
 	}
 }
+
+// observeItems makes the executor notify the item observer of each
+// result of an unordered multiplexed statement as soon as it is done.
+func observeItems(ctx context.Context, resourceID domain.ResourceID, statement []interface{}) context.Context {
+	observer := getItemObserver(ctx)
+	if observer == nil || !firstStatement(statement).Unordered {
+		return ctx
+	}
+
+	return withMultiplexedObserver(ctx, func(index int, response interface{}) {
+		observer(resourceID, index, response)
+	})
+}
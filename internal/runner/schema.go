@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// maxSchemaViolations bounds the violations kept
+// in the statement result for each response.
+const maxSchemaViolations = 20
+
+// validateResponse validates the body of a successful response against
+// the mapping response schema, before it is normalized. On violations
+// the statement fails with 502 Bad Gateway, unless the schema only
+// annotates them, in both cases reporting them in the statement debug.
+func validateResponse(log restql.Logger, statement domain.Statement, dr restql.DoneResource) restql.DoneResource {
+	rs := statement.ResponseSchema
+	if rs == nil || !dr.Success || dr.ResponseBody == nil || !dr.ResponseBody.Valid() {
+		return dr
+	}
+
+	violations := rs.Schema.Validate(dr.ResponseBody.Unmarshal())
+	if len(violations) == 0 {
+		return dr
+	}
+
+	log.Warn("response does not conform to the mapping schema", "resource", statement.Resource, "mode", rs.Mode, "violations", len(violations))
+
+	if len(violations) > maxSchemaViolations {
+		more := len(violations) - maxSchemaViolations
+		violations = append(violations[:maxSchemaViolations], fmt.Sprintf("and %d more violations", more))
+	}
+	dr.SchemaViolations = violations
+
+	if rs.Mode == domain.SchemaModeAnnotate {
+		return dr
+	}
+
+	dr.Success = false
+	dr.Status = http.StatusBadGateway
+	return dr
+}
@@ -0,0 +1,66 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+// validatorFunc is a SchemaValidator reporting the violations
+// returned by the function.
+type validatorFunc func(value interface{}) []string
+
+func (f validatorFunc) Validate(value interface{}) []string {
+	return f(value)
+}
+
+func TestExecutorResponseSchema(t *testing.T) {
+	schema := validatorFunc(func(value interface{}) []string {
+		if _, found := value.(map[string]interface{})["name"]; !found {
+			return []string{"/: missing required property name"}
+		}
+		return nil
+	})
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+	}
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+
+	tests := []struct {
+		name            string
+		mode            string
+		body            string
+		expectedStatus  int
+		expectedSuccess bool
+		expectedErrors  []string
+	}{
+		{"valid", domain.SchemaModeFail, `{"id": 1, "name": "batman"}`, http.StatusOK, true, nil},
+		{"fail", domain.SchemaModeFail, `{"id": 1}`, http.StatusBadGateway, false, []string{"/: missing required property name"}},
+		{"annotate", domain.SchemaModeAnnotate, `{"id": 1}`, http.StatusOK, true, []string{"/: missing required property name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := restql.NewResponseBodyFromValue(test.NoOpLogger, test.Unmarshal(tt.body))
+			client := &stubClient{responses: []restql.HTTPResponse{{URL: "http://hero.io/api", StatusCode: http.StatusOK, Body: body}}}
+			executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, 0, "", nil)
+
+			statement := domain.Statement{
+				Method:         domain.FromMethod,
+				Resource:       "hero",
+				ResponseSchema: &domain.ResponseSchema{Schema: schema, Mode: tt.mode},
+			}
+
+			got := executor.DoStatement(ctx, statement, queryCtx)
+
+			test.Equal(t, got.Status, tt.expectedStatus)
+			test.Equal(t, got.Success, tt.expectedSuccess)
+			test.Equal(t, got.SchemaViolations, tt.expectedErrors)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+)
+
+// sessionJars keeps, for the execution of a query, a cookie jar for
+// each resource whose statements forward the session cookies, so the
+// cookies set by a statement are sent by the ones chained to it.
+type sessionJars struct {
+	mu   sync.Mutex
+	jars map[string]http.CookieJar
+}
+
+func (sj *sessionJars) forResource(resource string) http.CookieJar {
+	sj.mu.Lock()
+	defer sj.mu.Unlock()
+
+	jar, found := sj.jars[resource]
+	if !found {
+		// cookiejar.New only fails when given invalid options.
+		jar, _ = cookiejar.New(nil)
+		sj.jars[resource] = jar
+	}
+
+	return jar
+}
+
+type sessionJarsKey struct{}
+
+func withSessionJars(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionJarsKey{}, &sessionJars{jars: make(map[string]http.CookieJar)})
+}
+
+// getSessionJar returns the cookie jar of the resource
+// in the query execution, if there is one.
+func getSessionJar(ctx context.Context, resource string) (http.CookieJar, bool) {
+	sj, ok := ctx.Value(sessionJarsKey{}).(*sessionJars)
+	if !ok {
+		return nil, false
+	}
+
+	return sj.forResource(resource), true
+}
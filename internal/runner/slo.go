@@ -0,0 +1,128 @@
+package runner
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// sloModifier sets the target latency of the
+// query in milliseconds, as in `use slo 300`.
+const sloModifier = "slo"
+
+// SLOBreach represents a statement, or the query itself
+// when Statement is empty, whose latency exceeded its target.
+type SLOBreach struct {
+	Statement string
+	Target    time.Duration
+	Latency   time.Duration
+}
+
+type sloTrackingKey struct{}
+
+type sloTracking struct {
+	mu       sync.Mutex
+	breaches []SLOBreach
+}
+
+// WithSLOTracking returns a context that collects the breaches of
+// the target latencies of the queries executed with it.
+func WithSLOTracking(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sloTrackingKey{}, &sloTracking{})
+}
+
+// SLOBreaches returns the breaches collected with the given
+// context, the query first and then the statements by identifier.
+func SLOBreaches(ctx context.Context) []SLOBreach {
+	t, ok := ctx.Value(sloTrackingKey{}).(*sloTracking)
+	if !ok {
+		return nil
+	}
+
+	t.mu.Lock()
+	breaches := make([]SLOBreach, len(t.breaches))
+	copy(breaches, t.breaches)
+	t.mu.Unlock()
+
+	sort.SliceStable(breaches, func(i, j int) bool {
+		return breaches[i].Statement < breaches[j].Statement
+	})
+	return breaches
+}
+
+// evaluateStatementSLO compares the latency of the statement, the
+// slowest response for multiplexed ones, with its `slo` target.
+func evaluateStatementSLO(ctx context.Context, queryCtx restql.QueryContext, resourceID domain.ResourceID, statement interface{}, response interface{}) {
+	stmt := firstStatement(statement)
+	if stmt.SLO <= 0 || len(GetEmptyChainedParams(stmt)) > 0 {
+		return
+	}
+
+	target := time.Duration(stmt.SLO) * time.Millisecond
+	latency := time.Duration(slowestResponseTime(response)) * time.Millisecond
+	evaluateSLO(ctx, queryCtx, string(resourceID), stmt.Resource, target, latency)
+}
+
+// evaluateQuerySLO compares the latency of the
+// query with the target of the `use slo` modifier.
+func evaluateQuerySLO(ctx context.Context, query domain.Query, queryCtx restql.QueryContext, latency time.Duration) {
+	slo, ok := query.Use[sloModifier].(int)
+	if !ok || slo <= 0 {
+		return
+	}
+
+	evaluateSLO(ctx, queryCtx, "", "", time.Duration(slo)*time.Millisecond, latency)
+}
+
+func evaluateSLO(ctx context.Context, queryCtx restql.QueryContext, statement string, resource string, target time.Duration, latency time.Duration) {
+	met := latency <= target
+	opts := queryCtx.Options
+
+	restql.PublishEvent(ctx, restql.SLOEvaluatedEvent{
+		Tenant:    opts.Tenant,
+		Namespace: opts.Namespace,
+		Query:     opts.Id,
+		Revision:  opts.Revision,
+		Statement: statement,
+		Resource:  resource,
+		Target:    target,
+		Latency:   latency,
+		Met:       met,
+	})
+
+	if met {
+		return
+	}
+
+	restql.GetLogger(ctx).Debug("slo breached", "statement", statement, "target", target.String(), "latency", latency.String())
+
+	t, ok := ctx.Value(sloTrackingKey{}).(*sloTracking)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	t.breaches = append(t.breaches, SLOBreach{Statement: statement, Target: target, Latency: latency})
+	t.mu.Unlock()
+}
+
+func slowestResponseTime(response interface{}) int64 {
+	switch response := response.(type) {
+	case restql.DoneResource:
+		return response.ResponseTime
+	case restql.DoneResources:
+		var slowest int64
+		for _, r := range response {
+			if t := slowestResponseTime(r); t > slowest {
+				slowest = t
+			}
+		}
+		return slowest
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,78 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestRunnerSLO(t *testing.T) {
+	client := &stubClient{responses: []restql.HTTPResponse{
+		{StatusCode: http.StatusOK, Duration: 10 * time.Millisecond},
+		{StatusCode: http.StatusOK, Duration: 20 * time.Millisecond},
+	}}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+	r := runner.NewRunner(test.NoOpLogger, executor, time.Second, runner.DefaultsCascade{}, nil, 0)
+
+	query := domain.Query{
+		Use:        domain.Modifiers{"slo": 1000},
+		Statements: []domain.Statement{{Method: domain.FromMethod, Resource: "hero", SLO: 15}},
+	}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+		Options:  restql.QueryOptions{Tenant: "acme", Namespace: "marvel", Id: "heroes", Revision: 1},
+	}
+
+	var (
+		mu     sync.Mutex
+		events []restql.SLOEvaluatedEvent
+	)
+	unsubscribe := restql.SubscribeEvents(func(ctx context.Context, event restql.Event) {
+		if e, ok := event.(restql.SLOEvaluatedEvent); ok {
+			mu.Lock()
+			events = append(events, e)
+			mu.Unlock()
+		}
+	})
+	defer unsubscribe()
+
+	ctx := runner.WithSLOTracking(restql.WithLogger(context.Background(), test.NoOpLogger))
+	_, err := r.ExecuteQuery(ctx, query, queryCtx)
+	test.VerifyError(t, err)
+	test.Equal(t, len(runner.SLOBreaches(ctx)), 0)
+
+	ctx = runner.WithSLOTracking(restql.WithLogger(context.Background(), test.NoOpLogger))
+	_, err = r.ExecuteQuery(ctx, query, queryCtx)
+	test.VerifyError(t, err)
+	test.Equal(t, runner.SLOBreaches(ctx), []runner.SLOBreach{{Statement: "hero", Target: 15 * time.Millisecond, Latency: 20 * time.Millisecond}})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	test.Equal(t, len(events), 4)
+	for i, met := range []bool{true, false} {
+		statementEvent, queryEvent := events[2*i], events[2*i+1]
+
+		test.Equal(t, statementEvent.Statement, "hero")
+		test.Equal(t, statementEvent.Resource, "hero")
+		test.Equal(t, statementEvent.Tenant, "acme")
+		test.Equal(t, statementEvent.Query, "heroes")
+		test.Equal(t, statementEvent.Target, 15*time.Millisecond)
+		test.Equal(t, statementEvent.Met, met)
+
+		test.Equal(t, queryEvent.Statement, "")
+		test.Equal(t, queryEvent.Target, time.Second)
+		test.Equal(t, queryEvent.Met, true)
+	}
+
+	compliance := 0.5
+	plans := r.PlanQuery(query, queryCtx)
+	test.Equal(t, plans[0].Stats.SLOCompliance, &compliance)
+}
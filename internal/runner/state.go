@@ -64,6 +64,12 @@ func (s *State) canRequest(statement domain.Statement) bool {
 		}
 	}
 
+	for _, v := range statementSettings(statement) {
+		if !s.isValueResolved(v) {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -77,6 +83,8 @@ func (s *State) isValueResolved(value interface{}) bool {
 
 		_, found := s.done[domain.ResourceID(resourceTarget)]
 		return found
+	case domain.Range:
+		return s.isValueResolved(value.Start) && s.isValueResolved(value.End) && s.isValueResolved(value.Step)
 	case domain.Function:
 		return s.isValueResolved(value.Target())
 	case map[string]interface{}:
@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// cacheModifier caches for the given seconds the result of
+// every statement that does not define its own cache.
+const cacheModifier = "cache"
+
+// statementCacheTTL returns for how long the result of the statement
+// is cached, which only applies to from statements with a positive
+// cache and without the no-cache flag.
+func statementCacheTTL(statement domain.Statement) (time.Duration, bool) {
+	if statement.NoCache || statement.Cache <= 0 || statement.Method != domain.FromMethod {
+		return 0, false
+	}
+
+	return time.Duration(statement.Cache) * time.Second, true
+}
+
+// statementCacheKey identifies the resolved request of a statement,
+// so the same URL with different parameters, headers or body is
+// cached apart, regardless of the upstream Cache-Control.
+func statementCacheKey(request restql.HTTPRequest) string {
+	identity := struct {
+		Query   map[string]interface{}
+		Headers restql.Headers
+		Body    restql.Body
+	}{request.Query, request.Headers, request.Body}
+
+	data, err := json.Marshal(identity)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%v", identity))
+	}
+
+	url := request.Schema + "://" + request.Host + request.Path
+	return fmt.Sprintf("statement %s %s %x", request.Method, url, sha256.Sum256(data))
+}
+
+// doCached makes the statement result from the response cached for it.
+func (e Executor) doCached(ctx context.Context, statement domain.Statement, request restql.HTTPRequest, response restql.HTTPResponse, drOptions DoneResourceOptions) restql.DoneResource {
+	log := restql.GetLogger(ctx)
+	start := time.Now()
+
+	log.Debug("using cached statement result", "resource", statement.Resource, "method", statement.Method, "url", response.URL)
+	recordCache(ctx, restql.ResponseCacheHit)
+
+	dr := NewDoneResource(request, response, drOptions)
+	dr = validateResponse(log, statement, dr)
+	dr = normalizeResponse(log, statement, dr)
+
+	publishStatementFinished(ctx, statement, response, dr.Success, restql.ResponseCacheHit, start)
+
+	return dr
+}
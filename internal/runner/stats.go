@@ -0,0 +1,236 @@
+package runner
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// StatsWindow is the number of most recent responses
+// kept for each resource to compute its statistics.
+const StatsWindow = 1000
+
+// ResourceStats represents the latency, error rate and throttle
+// rate observed on the most recent responses of a resource, and the
+// rate of the ones within the target of statements with an `slo`.
+type ResourceStats struct {
+	Samples       int      `json:"samples"`
+	P50Ms         int64    `json:"p50Ms"`
+	P99Ms         int64    `json:"p99Ms"`
+	ErrorRate     float64  `json:"errorRate"`
+	ThrottleRate  float64  `json:"throttleRate,omitempty"`
+	SLOCompliance *float64 `json:"sloCompliance,omitempty"`
+}
+
+// ResourceHealth represents the statistics of a resource
+// along with its 90th percentile latency and last failure,
+// to triage upstream incidents.
+type ResourceHealth struct {
+	ResourceStats
+	P90Ms       int64            `json:"p90Ms"`
+	LastFailure *ResourceFailure `json:"lastFailure,omitempty"`
+}
+
+// ResourceFailure represents the last failed response of a resource.
+type ResourceFailure struct {
+	At     time.Time `json:"at"`
+	Status int       `json:"status"`
+	Reason string    `json:"reason"`
+}
+
+// maxFailureReasonLength bounds the reason kept for failures,
+// which may be taken from large upstream error bodies.
+const maxFailureReasonLength = 200
+
+type statsKey struct {
+	tenant   string
+	resource string
+}
+
+type sample struct {
+	durationMs int64
+	failed     bool
+	throttled  bool
+	sloMs      int64
+}
+
+type resourceSamples struct {
+	samples     []sample
+	next        int
+	lastFailure *ResourceFailure
+}
+
+func (rs *resourceSamples) add(s sample, window int) {
+	if len(rs.samples) < window {
+		rs.samples = append(rs.samples, s)
+		return
+	}
+
+	rs.samples[rs.next] = s
+	rs.next = (rs.next + 1) % window
+}
+
+// statsRecorder keeps a rolling window of the responses
+// received from each resource since restQL started.
+type statsRecorder struct {
+	window    int
+	mu        sync.Mutex
+	resources map[statsKey]*resourceSamples
+}
+
+func newStatsRecorder(window int) *statsRecorder {
+	return &statsRecorder{window: window, resources: make(map[statsKey]*resourceSamples)}
+}
+
+// record adds the responses of a statement, which are a
+// DoneResource or, for multiplexed statements, a DoneResources
+// with the same structure of the statement.
+func (sr *statsRecorder) record(tenant string, statement interface{}, response interface{}) {
+	switch statement := statement.(type) {
+	case domain.Statement:
+		dr, ok := response.(restql.DoneResource)
+		if !ok || len(GetEmptyChainedParams(statement)) > 0 {
+			return
+		}
+
+		s := sample{durationMs: dr.ResponseTime, failed: isFailure(dr), throttled: dr.Throttled, sloMs: int64(statement.SLO)}
+		var failure *ResourceFailure
+		if s.failed {
+			failure = &ResourceFailure{At: time.Now(), Status: dr.Status, Reason: failureReason(dr)}
+		}
+
+		sr.add(statsKey{tenant: tenant, resource: statement.Resource}, s, failure)
+	case []interface{}:
+		responses, ok := response.(restql.DoneResources)
+		if !ok || len(responses) != len(statement) {
+			return
+		}
+
+		for i, s := range statement {
+			sr.record(tenant, s, responses[i])
+		}
+	}
+}
+
+func (sr *statsRecorder) add(key statsKey, s sample, failure *ResourceFailure) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	rs, found := sr.resources[key]
+	if !found {
+		rs = &resourceSamples{}
+		sr.resources[key] = rs
+	}
+
+	rs.add(s, sr.window)
+	if failure != nil {
+		rs.lastFailure = failure
+	}
+}
+
+func (sr *statsRecorder) stats(tenant string, resource string) *ResourceStats {
+	health := sr.health(tenant, resource)
+	if health == nil {
+		return nil
+	}
+
+	return &health.ResourceStats
+}
+
+func (sr *statsRecorder) health(tenant string, resource string) *ResourceHealth {
+	sr.mu.Lock()
+	rs, found := sr.resources[statsKey{tenant: tenant, resource: resource}]
+	if !found {
+		sr.mu.Unlock()
+		return nil
+	}
+
+	samples := make([]sample, len(rs.samples))
+	copy(samples, rs.samples)
+	lastFailure := rs.lastFailure
+	sr.mu.Unlock()
+
+	durations := make([]int64, len(samples))
+	failures, throttles, withSLO, withinSLO := 0, 0, 0, 0
+	for i, s := range samples {
+		durations[i] = s.durationMs
+		if s.failed {
+			failures++
+		}
+		if s.throttled {
+			throttles++
+		}
+		if s.sloMs > 0 {
+			withSLO++
+			if s.durationMs <= s.sloMs {
+				withinSLO++
+			}
+		}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	var sloCompliance *float64
+	if withSLO > 0 {
+		compliance := float64(withinSLO) / float64(withSLO)
+		sloCompliance = &compliance
+	}
+
+	return &ResourceHealth{
+		ResourceStats: ResourceStats{
+			Samples:       len(samples),
+			P50Ms:         percentile(durations, 0.50),
+			P99Ms:         percentile(durations, 0.99),
+			ErrorRate:     float64(failures) / float64(len(samples)),
+			ThrottleRate:  float64(throttles) / float64(len(samples)),
+			SLOCompliance: sloCompliance,
+		},
+		P90Ms:       percentile(durations, 0.90),
+		LastFailure: lastFailure,
+	}
+}
+
+// percentile uses the nearest-rank method over sorted values.
+func percentile(sorted []int64, p float64) int64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	return sorted[rank]
+}
+
+func isFailure(dr restql.DoneResource) bool {
+	return dr.Status == 0 || dr.Status >= http.StatusBadRequest
+}
+
+// failureReason returns the error message of responses that failed
+// without reaching the upstream, or the upstream body otherwise.
+func failureReason(dr restql.DoneResource) string {
+	var reason string
+	if dr.ResponseBody != nil {
+		switch body := dr.ResponseBody.Unmarshal().(type) {
+		case string:
+			reason = body
+		case nil:
+		default:
+			b, _ := json.Marshal(body)
+			reason = string(b)
+		}
+	}
+
+	if reason == "" {
+		reason = http.StatusText(dr.Status)
+	}
+
+	if len(reason) > maxFailureReasonLength {
+		reason = reason[:maxFailureReasonLength] + "..."
+	}
+
+	return reason
+}
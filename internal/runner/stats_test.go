@@ -0,0 +1,84 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestRunnerResourceStats(t *testing.T) {
+	client := &stubClient{responses: []restql.HTTPResponse{
+		{StatusCode: http.StatusOK, Duration: 10 * time.Millisecond},
+		{StatusCode: http.StatusOK, Duration: 20 * time.Millisecond},
+		{StatusCode: http.StatusOK, Duration: 30 * time.Millisecond},
+		{StatusCode: http.StatusTooManyRequests, Duration: 400 * time.Millisecond},
+	}}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+	r := runner.NewRunner(test.NoOpLogger, executor, time.Second, runner.DefaultsCascade{}, nil, 0)
+
+	query := domain.Query{Statements: []domain.Statement{{Method: domain.FromMethod, Resource: "hero"}}}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+		Options:  restql.QueryOptions{Tenant: "acme"},
+	}
+
+	plans := r.PlanQuery(query, queryCtx)
+	test.Equal(t, plans[0].Stats, (*runner.ResourceStats)(nil))
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	for range client.responses {
+		_, err := r.ExecuteQuery(ctx, query, queryCtx)
+		test.VerifyError(t, err)
+	}
+
+	plans = r.PlanQuery(query, queryCtx)
+	test.Equal(t, plans[0].Stats, &runner.ResourceStats{Samples: 4, P50Ms: 20, P99Ms: 400, ErrorRate: 0.25, ThrottleRate: 0.25})
+
+	otherTenant := queryCtx
+	otherTenant.Options.Tenant = "dc"
+	plans = r.PlanQuery(query, otherTenant)
+	test.Equal(t, plans[0].Stats, (*runner.ResourceStats)(nil))
+}
+
+func TestRunnerResourceHealth(t *testing.T) {
+	client := &stubClient{responses: []restql.HTTPResponse{
+		{StatusCode: http.StatusOK, Duration: 10 * time.Millisecond},
+		{StatusCode: http.StatusServiceUnavailable, Duration: 20 * time.Millisecond, Body: restql.NewResponseBodyFromBytes(test.NoOpLogger, []byte(`{"error":"overloaded"}`))},
+		{StatusCode: http.StatusOK, Duration: 30 * time.Millisecond},
+	}}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+	r := runner.NewRunner(test.NoOpLogger, executor, time.Second, runner.DefaultsCascade{}, nil, 0)
+
+	query := domain.Query{Statements: []domain.Statement{{Method: domain.FromMethod, Resource: "hero"}}}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+		Options:  restql.QueryOptions{Tenant: "acme"},
+	}
+
+	test.Equal(t, r.ResourceHealth("acme", "hero"), (*runner.ResourceHealth)(nil))
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	for range client.responses {
+		_, err := r.ExecuteQuery(ctx, query, queryCtx)
+		test.VerifyError(t, err)
+	}
+
+	health := r.ResourceHealth("acme", "hero")
+	if health == nil || health.LastFailure == nil || health.LastFailure.At.IsZero() {
+		t.Fatalf("expected a last failure with its time, got %+v", health)
+	}
+	health.LastFailure.At = time.Time{}
+
+	expected := &runner.ResourceHealth{
+		ResourceStats: runner.ResourceStats{Samples: 3, P50Ms: 20, P99Ms: 30, ErrorRate: 1.0 / 3},
+		P90Ms:         30,
+		LastFailure:   &runner.ResourceFailure{Status: http.StatusServiceUnavailable, Reason: `{"error":"overloaded"}`},
+	}
+	test.Equal(t, health, expected)
+}
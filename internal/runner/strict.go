@@ -0,0 +1,32 @@
+package runner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const strictModifier = "strict"
+
+// ErrMissingChainedValue represents an error when, in strict mode, a chain
+// parameter value references a field absent from the result of a
+// successful dependency.
+var ErrMissingChainedValue = errors.New("chained parameter targeting missing field")
+
+// missingChainsError returns an error listing the chain paths
+// that could not be found in the done resources, if any.
+func missingChainsError(arena *ChainArena) error {
+	if len(arena.missing) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(arena.missing))
+	for path := range arena.missing {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return fmt.Errorf("%w: %s", ErrMissingChainedValue, strings.Join(paths, ", "))
+}
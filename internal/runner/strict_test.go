@@ -0,0 +1,89 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestDefaultsCascadeStrict(t *testing.T) {
+	enabled, disabled := true, false
+	cascade := runner.DefaultsCascade{
+		Global: runner.Defaults{Strict: &enabled},
+		Tenants: map[string]runner.TenantDefaults{
+			"DC":     {Defaults: runner.Defaults{Strict: &disabled}},
+			"MARVEL": {},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		cascade   runner.DefaultsCascade
+		tenant    string
+		modifiers domain.Modifiers
+		expected  bool
+	}{
+		{"should not be strict without defaults", runner.DefaultsCascade{}, "DC", nil, false},
+		{"should use global default", cascade, "MARVEL", nil, true},
+		{"should use tenant default over global", cascade, "DC", nil, false},
+		{"should use modifier over defaults", cascade, "DC", domain.Modifiers{"strict": true}, true},
+		{"should ignore modifier without boolean value", cascade, "DC", domain.Modifiers{"strict": "yes"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.Equal(t, tt.cascade.Strict(tt.tenant, tt.modifiers), tt.expected)
+		})
+	}
+}
+
+func TestRunnerStrictMissingChainedValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		modifiers   domain.Modifiers
+		heroBody    interface{}
+		expectedErr error
+	}{
+		{"should fail when chained field is missing", domain.Modifiers{"strict": true}, map[string]interface{}{"name": "Batman"}, runner.ErrMissingChainedValue},
+		{"should execute when chained field is present", domain.Modifiers{"strict": true}, map[string]interface{}{"sidekickId": "robin"}, nil},
+		{"should execute when not strict", nil, map[string]interface{}{"name": "Batman"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &stubClient{responses: []restql.HTTPResponse{
+				{StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, tt.heroBody)},
+				{StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, map[string]interface{}{})},
+			}}
+			executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+			r := runner.NewRunner(test.NoOpLogger, executor, time.Second, runner.DefaultsCascade{}, nil, 0)
+
+			query := domain.Query{Use: tt.modifiers, Statements: []domain.Statement{
+				{Method: domain.FromMethod, Resource: "hero"},
+				{Method: domain.FromMethod, Resource: "sidekick", With: domain.Params{Values: map[string]interface{}{"id": domain.Chain{"hero", "sidekickId"}}}},
+			}}
+			queryCtx := restql.QueryContext{
+				Mappings: map[string]restql.Mapping{
+					"hero":     mapping(t, "http://hero.io/api"),
+					"sidekick": mapping(t, "http://sidekick.io/api"),
+				},
+			}
+
+			ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+			_, err := r.ExecuteQuery(ctx, query, queryCtx)
+
+			if tt.expectedErr == nil {
+				test.VerifyError(t, err)
+				return
+			}
+			test.Equal(t, errors.Is(err, tt.expectedErr), true)
+		})
+	}
+}
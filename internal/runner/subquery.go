@@ -0,0 +1,46 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/pkg/errors"
+)
+
+// ErrSubqueryNotSupported represents the event of executing a statement
+// targeting a saved query without a SubqueryRunner available.
+var ErrSubqueryNotSupported = errors.New("subquery execution not available")
+
+type subqueryRunnerKey struct{}
+
+// SubqueryRunner executes the saved query targeted by a statement,
+// returning its result as the statement response.
+type SubqueryRunner func(ctx context.Context, subquery domain.Subquery, statement domain.Statement, queryCtx restql.QueryContext) restql.DoneResource
+
+// WithSubqueryRunner returns a context that makes the Executor
+// delegate statements targeting saved queries to the runner.
+func WithSubqueryRunner(ctx context.Context, runner SubqueryRunner) context.Context {
+	return context.WithValue(ctx, subqueryRunnerKey{}, runner)
+}
+
+func (e Executor) doSubquery(ctx context.Context, subquery domain.Subquery, statement domain.Statement, queryCtx restql.QueryContext, options DoneResourceOptions) restql.DoneResource {
+	log := restql.GetLogger(ctx)
+
+	runSubquery, ok := ctx.Value(subqueryRunnerKey{}).(SubqueryRunner)
+	if !ok {
+		return restql.DoneResource{
+			Status:       http.StatusInternalServerError,
+			IgnoreErrors: options.IgnoreErrors,
+			ResponseBody: restql.NewResponseBodyFromValue(log, ErrSubqueryNotSupported.Error()),
+		}
+	}
+
+	log.Debug("executing subquery for statement", "resource", statement.Resource)
+
+	dr := runSubquery(ctx, subquery, statement, queryCtx)
+	dr.IgnoreErrors = options.IgnoreErrors
+
+	return dr
+}
@@ -0,0 +1,46 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestExecutorSubquery(t *testing.T) {
+	statement := domain.Statement{Method: domain.FromMethod, Resource: "query:catalog/product-detail/2", IgnoreErrors: true}
+
+	t.Run("should delegate statement to subquery runner", func(t *testing.T) {
+		var got domain.Subquery
+		subqueryRunner := func(ctx context.Context, subquery domain.Subquery, statement domain.Statement, queryCtx restql.QueryContext) restql.DoneResource {
+			got = subquery
+			return restql.DoneResource{Status: http.StatusOK}
+		}
+
+		client := &stubClient{}
+		executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, 0, "", nil)
+
+		ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+		ctx = runner.WithSubqueryRunner(ctx, subqueryRunner)
+
+		dr := executor.DoStatement(ctx, statement, restql.QueryContext{})
+
+		test.Equal(t, got, domain.Subquery{Namespace: "catalog", ID: "product-detail", Revision: 2})
+		test.Equal(t, dr.Status, http.StatusOK)
+		test.Equal(t, dr.IgnoreErrors, true)
+		test.Equal(t, len(client.requests), 0)
+	})
+
+	t.Run("should fail when subquery runner is not available", func(t *testing.T) {
+		executor := runner.NewExecutor(test.NoOpLogger, &stubClient{}, nil, nil, nil, 0, "", nil)
+
+		ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+		dr := executor.DoStatement(ctx, statement, restql.QueryContext{})
+
+		test.Equal(t, dr.Status, http.StatusInternalServerError)
+	})
+}
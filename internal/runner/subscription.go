@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// subscribeModifier selects the statements, as a comma separated
+// list of their names, whose upstream is subscribed as a
+// server-sent events stream when the query is streamed.
+const subscribeModifier = "subscribe"
+
+func isSubscribeSelected(modifiers domain.Modifiers, statement domain.Statement) bool {
+	return isSelectedBy(modifiers, subscribeModifier, string(domain.NewResourceID(statement)))
+}
+
+// EventHandler receives each event of the subscribed
+// upstreams along with the statement it belongs to.
+type EventHandler func(resourceID domain.ResourceID, event domain.UpstreamEvent)
+
+// Subscriptions keeps the server-sent events streams of the
+// subscribed statements open after they are done, forwarding
+// their events to the handler for at most the maximum duration.
+type Subscriptions struct {
+	ctx         context.Context
+	source      domain.EventSource
+	maxDuration time.Duration
+	handler     EventHandler
+	wg          sync.WaitGroup
+}
+
+// NewSubscriptions constructs a Subscriptions bound to the context,
+// whose cancellation ends every stream, instead of the query one.
+func NewSubscriptions(ctx context.Context, source domain.EventSource, maxDuration time.Duration, handler EventHandler) *Subscriptions {
+	return &Subscriptions{ctx: ctx, source: source, maxDuration: maxDuration, handler: handler}
+}
+
+type subscriptionsKey struct{}
+
+// WithSubscriptions returns a context that makes the Executor
+// subscribe to the upstreams of the selected statements.
+func WithSubscriptions(ctx context.Context, s *Subscriptions) context.Context {
+	return context.WithValue(ctx, subscriptionsKey{}, s)
+}
+
+func getSubscriptions(ctx context.Context) *Subscriptions {
+	s, _ := ctx.Value(subscriptionsKey{}).(*Subscriptions)
+	return s
+}
+
+// Wait blocks until every stream has ended.
+func (s *Subscriptions) Wait() {
+	s.wg.Wait()
+}
+
+// subscribe opens the stream of the statement, which is done as soon
+// as the upstream responds, while its events keep being forwarded.
+func (s *Subscriptions) subscribe(ctx context.Context, statement domain.Statement, request restql.HTTPRequest, drOptions DoneResourceOptions) restql.DoneResource {
+	log := restql.GetLogger(ctx)
+	resourceID := domain.NewResourceID(statement)
+
+	streamCtx, cancel := context.WithTimeout(s.ctx, s.maxDuration)
+	response, events, err := s.source.Subscribe(streamCtx, request)
+	if err != nil {
+		cancel()
+		log.Debug("upstream subscription failed", "resource", statement.Resource, "error", err)
+		return NewErrorResponse(log, err, request, response, drOptions)
+	}
+
+	if events == nil {
+		cancel()
+		return NewDoneResource(request, response, drOptions)
+	}
+
+	log.Debug("upstream subscribed", "resource", statement.Resource, "url", response.URL)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+
+		for event := range events {
+			s.handler(resourceID, event)
+		}
+		log.Debug("upstream subscription ended", "resource", statement.Resource)
+	}()
+
+	return NewDoneResource(request, response, drOptions)
+}
@@ -0,0 +1,111 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+type stubEventSource struct {
+	response restql.HTTPResponse
+	events   []domain.UpstreamEvent
+	requests []restql.HTTPRequest
+}
+
+func (s *stubEventSource) Subscribe(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, <-chan domain.UpstreamEvent, error) {
+	s.requests = append(s.requests, request)
+	if s.events == nil {
+		return s.response, nil, nil
+	}
+
+	events := make(chan domain.UpstreamEvent)
+	go func() {
+		defer close(events)
+		for _, e := range s.events {
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return s.response, events, nil
+}
+
+func TestExecutorSubscription(t *testing.T) {
+	url := "http://hero.io/events"
+	streamed := restql.HTTPResponse{URL: url, StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromBytes(test.NoOpLogger, nil)}
+	plain := restql.HTTPResponse{URL: url, StatusCode: http.StatusNotFound, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, "not found")}
+	fromClient := restql.HTTPResponse{URL: url, StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, map[string]interface{}{"id": "1"})}
+
+	tests := []struct {
+		name               string
+		subscribed         bool
+		source             *stubEventSource
+		expectedStatus     int
+		expectedEvents     []domain.UpstreamEvent
+		expectedSubscribes int
+	}{
+		{
+			"should forward events of subscribed statement",
+			true,
+			&stubEventSource{response: streamed, events: []domain.UpstreamEvent{{ID: "1", Event: "message", Data: `{"id":1}`}, {Event: "update", Data: "hi"}}},
+			http.StatusOK,
+			[]domain.UpstreamEvent{{ID: "1", Event: "message", Data: `{"id":1}`}, {Event: "update", Data: "hi"}},
+			1,
+		},
+		{
+			"should return response of upstream that is not a stream",
+			true,
+			&stubEventSource{response: plain},
+			http.StatusNotFound,
+			nil,
+			1,
+		},
+		{
+			"should execute statement that is not subscribed",
+			false,
+			&stubEventSource{response: streamed},
+			http.StatusOK,
+			nil,
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &stubClient{responses: []restql.HTTPResponse{fromClient}}
+			executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, 0, "", nil)
+
+			var mu sync.Mutex
+			var events []domain.UpstreamEvent
+			handler := func(resourceID domain.ResourceID, event domain.UpstreamEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				test.Equal(t, resourceID, domain.ResourceID("hero"))
+				events = append(events, event)
+			}
+
+			ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+			subscriptions := runner.NewSubscriptions(ctx, tt.source, time.Second, handler)
+			ctx = runner.WithSubscriptions(ctx, subscriptions)
+
+			statement := domain.Statement{Method: domain.FromMethod, Resource: "hero", Subscribed: tt.subscribed}
+			queryCtx := restql.QueryContext{Mappings: map[string]restql.Mapping{"hero": mapping(t, url)}}
+
+			got := executor.DoStatement(ctx, statement, queryCtx)
+			subscriptions.Wait()
+
+			test.Equal(t, got.Status, tt.expectedStatus)
+			test.Equal(t, events, tt.expectedEvents)
+			test.Equal(t, len(tt.source.requests), tt.expectedSubscribes)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// isThrottled reports if the upstream refused the request for
+// exceeding its rate limit, which is a 429 or a 503 that tells
+// when the request can be done again.
+func isThrottled(response restql.HTTPResponse) bool {
+	switch response.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		_, found := retryAfter(response, time.Now())
+		return found
+	default:
+		return false
+	}
+}
+
+// throttledRetry returns how long to wait before doing again a
+// throttled request and if it should be done at all, which requires
+// a Retry-After header, an attempt left in the retry budget and,
+// when the query has a deadline, enough time for the wait.
+func throttledRetry(ctx context.Context, response restql.HTTPResponse, hasAttempt bool) (time.Duration, bool) {
+	now := time.Now()
+	wait, found := retryAfter(response, now)
+	if !found || !hasAttempt {
+		return wait, false
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		return wait, false
+	}
+
+	return wait, true
+}
+
+// retryAfter parses the Retry-After header of the response, given
+// either as seconds or as an HTTP date, into the wait it asks for.
+func retryAfter(response restql.HTTPResponse, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(getRetryAfterHeader(response.Headers))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	wait := date.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
+func getRetryAfterHeader(headers map[string]string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, "Retry-After") {
+			return v
+		}
+	}
+	return ""
+}
+
+// waitRetryAfter waits for the duration, returning
+// false if the query context is done before it.
+func waitRetryAfter(ctx context.Context, wait time.Duration) bool {
+	if wait <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+type timelineKey struct{}
+
+type statementTimelineKey struct{}
+
+type timelineTracking struct {
+	once  sync.Once
+	start time.Time
+}
+
+// WithTimeline returns a context where the statements of the queries
+// executed with it record their execution timeline in the resulting
+// DoneResource, so the execution waterfall can be debugged. Timelines
+// are relative to the start of the first query executed with it.
+func WithTimeline(ctx context.Context) context.Context {
+	return context.WithValue(ctx, timelineKey{}, &timelineTracking{})
+}
+
+// startTimeline returns the start of the query execution that timelines
+// are relative to, if they are recorded for the context.
+func startTimeline(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(timelineKey{}).(*timelineTracking)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	t.once.Do(func() {
+		t.start = time.Now()
+	})
+
+	return t.start, true
+}
+
+func timelineEnabled(ctx context.Context) bool {
+	_, ok := ctx.Value(timelineKey{}).(*timelineTracking)
+	return ok
+}
+
+// withStatementTimeline returns a context where the executor
+// records the steps of the statement request in the timeline.
+func withStatementTimeline(ctx context.Context, timeline *restql.StatementTimeline) context.Context {
+	return context.WithValue(ctx, statementTimelineKey{}, timeline)
+}
+
+func getStatementTimeline(ctx context.Context) *restql.StatementTimeline {
+	timeline, _ := ctx.Value(statementTimelineKey{}).(*restql.StatementTimeline)
+	return timeline
+}
+
+func recordAttempt(ctx context.Context) {
+	if timeline := getStatementTimeline(ctx); timeline != nil {
+		timeline.Attempts++
+	}
+}
+
+func recordCache(ctx context.Context, outcome string) {
+	if timeline := getStatementTimeline(ctx); timeline != nil {
+		timeline.Cache = outcome
+	}
+}
+
+// timelineDependencies returns the identifiers of the statements
+// each statement depends on through chained parameters.
+func timelineDependencies(resources domain.Resources) map[domain.ResourceID][]string {
+	result := make(map[domain.ResourceID][]string, len(resources))
+	for resourceID, stmt := range resources {
+		dependencies := statementDependencies(stmt, resources)
+		if len(dependencies) == 0 {
+			continue
+		}
+
+		ids := make([]string, len(dependencies))
+		for i, d := range dependencies {
+			ids[i] = string(d)
+		}
+		result[resourceID] = ids
+	}
+
+	return result
+}
+
+// stampTimeline sets when the statement execution started on the
+// timeline of every DoneResource in the response.
+func stampTimeline(response interface{}, queryStart, availableAt, startedAt time.Time, dependsOn []string) interface{} {
+	switch response := response.(type) {
+	case restql.DoneResource:
+		timeline := restql.StatementTimeline{}
+		if response.Timeline != nil {
+			timeline = *response.Timeline
+		}
+
+		timeline.StartOffset = startedAt.Sub(queryStart)
+		timeline.QueueWait = startedAt.Sub(availableAt)
+		timeline.DependsOn = dependsOn
+		response.Timeline = &timeline
+
+		return response
+	case restql.DoneResources:
+		stamped := make(restql.DoneResources, len(response))
+		for i, r := range response {
+			stamped[i] = stampTimeline(r, queryStart, availableAt, startedAt, dependsOn)
+		}
+		return stamped
+	default:
+		return response
+	}
+}
@@ -0,0 +1,118 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestRunnerTimeline(t *testing.T) {
+	hero := restql.HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       restql.NewResponseBodyFromValue(test.NoOpLogger, map[string]interface{}{"id": "1"}),
+		Timings:    &restql.HTTPTimings{DNS: time.Millisecond, FirstByte: 10 * time.Millisecond},
+	}
+	sidekick := restql.HTTPResponse{StatusCode: http.StatusOK}
+
+	client := &stubClient{responses: []restql.HTTPResponse{hero, sidekick}}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+	r := runner.NewRunner(test.NoOpLogger, executor, time.Second, runner.DefaultsCascade{}, nil, 0)
+
+	query := domain.Query{Statements: []domain.Statement{
+		{Method: domain.FromMethod, Resource: "hero"},
+		{Method: domain.FromMethod, Resource: "sidekick", With: domain.Params{Values: map[string]interface{}{"hero": domain.Chain{"hero", "id"}}}},
+	}}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{
+			"hero":     mapping(t, "http://hero.io/api"),
+			"sidekick": mapping(t, "http://sidekick.io/api"),
+		},
+	}
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	ctx = runner.WithTimeline(ctx)
+
+	resources, err := r.ExecuteQuery(ctx, query, queryCtx)
+	test.VerifyError(t, err)
+
+	heroTimeline := resources["hero"].(restql.DoneResource).Timeline
+	sidekickTimeline := resources["sidekick"].(restql.DoneResource).Timeline
+
+	test.Equal(t, heroTimeline.Attempts, 1)
+	test.Equal(t, heroTimeline.DependsOn, []string(nil))
+	test.Equal(t, heroTimeline.HTTP, hero.Timings)
+	test.Equal(t, sidekickTimeline.Attempts, 1)
+	test.Equal(t, sidekickTimeline.DependsOn, []string{"hero"})
+	test.Equal(t, sidekickTimeline.StartOffset >= heroTimeline.StartOffset, true)
+	test.Equal(t, sidekickTimeline.QueueWait >= 0, true)
+
+	for _, request := range client.requests {
+		test.Equal(t, request.Trace, true)
+	}
+}
+
+func TestRunnerWithoutTimeline(t *testing.T) {
+	client := &stubClient{responses: []restql.HTTPResponse{{StatusCode: http.StatusOK}}}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+	r := runner.NewRunner(test.NoOpLogger, executor, time.Second, runner.DefaultsCascade{}, nil, 0)
+
+	query := domain.Query{Statements: []domain.Statement{{Method: domain.FromMethod, Resource: "hero"}}}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+	}
+
+	ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+	resources, err := r.ExecuteQuery(ctx, query, queryCtx)
+	test.VerifyError(t, err)
+
+	test.Equal(t, resources["hero"].(restql.DoneResource).Timeline, (*restql.StatementTimeline)(nil))
+	test.Equal(t, client.requests[0].Trace, false)
+}
+
+func TestExecutorTimelineCache(t *testing.T) {
+	url := "http://hero.io/api"
+	cached := restql.HTTPResponse{
+		URL:        url,
+		StatusCode: http.StatusOK,
+		Headers:    restql.Headers{"Etag": `"abc"`},
+		Body:       restql.NewResponseBodyFromValue(test.NoOpLogger, map[string]interface{}{"id": "1"}),
+	}
+	notModified := restql.HTTPResponse{URL: url, StatusCode: http.StatusNotModified, Headers: restql.Headers{"Etag": `"abc"`}}
+
+	tests := []struct {
+		name          string
+		cache         stubResponseCache
+		responses     []restql.HTTPResponse
+		expectedCache string
+		attempts      int
+	}{
+		{"should report cache hit", stubResponseCache{http.MethodGet + " " + url: cached}, []restql.HTTPResponse{notModified}, restql.ResponseCacheHit, 1},
+		{"should report cache miss", stubResponseCache{}, []restql.HTTPResponse{notModified, cached}, restql.ResponseCacheMiss, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &stubClient{responses: tt.responses}
+			executor := runner.NewExecutor(test.NoOpLogger, client, tt.cache, nil, nil, 0, "", nil)
+
+			statement := domain.Statement{Method: domain.FromMethod, Resource: "hero", ForwardConditionalHeaders: true}
+			queryCtx := restql.QueryContext{
+				Mappings: map[string]restql.Mapping{"hero": mapping(t, url)},
+				Input:    restql.QueryInput{Headers: map[string]string{"If-None-Match": `"abc"`}},
+			}
+
+			ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+			ctx = runner.WithTimeline(ctx)
+			got := executor.DoStatement(ctx, statement, queryCtx)
+
+			test.Equal(t, got.Timeline.Cache, tt.expectedCache)
+			test.Equal(t, got.Timeline.Attempts, tt.attempts)
+		})
+	}
+}
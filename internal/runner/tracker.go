@@ -0,0 +1,122 @@
+package runner
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// Statement progress states reported by execution snapshots.
+const (
+	StatementPending   = "pending"
+	StatementRequested = "requested"
+	StatementDone      = "done"
+)
+
+// ExecutionSnapshot represents the progress of a query
+// execution at the moment the snapshot was taken.
+type ExecutionSnapshot struct {
+	ID         uint64            `json:"id"`
+	Tenant     string            `json:"tenant"`
+	Namespace  string            `json:"namespace,omitempty"`
+	Query      string            `json:"query,omitempty"`
+	Revision   int               `json:"revision,omitempty"`
+	StartedAt  time.Time         `json:"startedAt"`
+	ElapsedMs  int64             `json:"elapsedMs"`
+	Statements map[string]string `json:"statements"`
+}
+
+type execution struct {
+	mu         sync.Mutex
+	id         uint64
+	options    restql.QueryOptions
+	startedAt  time.Time
+	statements map[domain.ResourceID]string
+}
+
+func (e *execution) setStatus(resourceID domain.ResourceID, status string) {
+	e.mu.Lock()
+	e.statements[resourceID] = status
+	e.mu.Unlock()
+}
+
+func (e *execution) snapshot(now time.Time) ExecutionSnapshot {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	statements := make(map[string]string, len(e.statements))
+	for resourceID, status := range e.statements {
+		statements[string(resourceID)] = status
+	}
+
+	return ExecutionSnapshot{
+		ID:         e.id,
+		Tenant:     e.options.Tenant,
+		Namespace:  e.options.Namespace,
+		Query:      e.options.Id,
+		Revision:   e.options.Revision,
+		StartedAt:  e.startedAt,
+		ElapsedMs:  now.Sub(e.startedAt).Milliseconds(),
+		Statements: statements,
+	}
+}
+
+// executionTracker keeps the query executions in progress.
+type executionTracker struct {
+	lastID     uint64
+	mu         sync.Mutex
+	executions map[uint64]*execution
+}
+
+func newExecutionTracker() *executionTracker {
+	return &executionTracker{executions: make(map[uint64]*execution)}
+}
+
+func (t *executionTracker) start(options restql.QueryOptions, resources domain.Resources) *execution {
+	statements := make(map[domain.ResourceID]string, len(resources))
+	for resourceID := range resources {
+		statements[resourceID] = StatementPending
+	}
+
+	e := &execution{
+		id:         atomic.AddUint64(&t.lastID, 1),
+		options:    options,
+		startedAt:  time.Now(),
+		statements: statements,
+	}
+
+	t.mu.Lock()
+	t.executions[e.id] = e
+	t.mu.Unlock()
+
+	return e
+}
+
+func (t *executionTracker) finish(e *execution) {
+	t.mu.Lock()
+	delete(t.executions, e.id)
+	t.mu.Unlock()
+}
+
+func (t *executionTracker) snapshot() []ExecutionSnapshot {
+	t.mu.Lock()
+	executions := make([]*execution, 0, len(t.executions))
+	for _, e := range t.executions {
+		executions = append(executions, e)
+	}
+	t.mu.Unlock()
+
+	now := time.Now()
+	result := make([]ExecutionSnapshot, len(executions))
+	for i, e := range executions {
+		result[i] = e.snapshot(now)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	return result
+}
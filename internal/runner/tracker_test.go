@@ -0,0 +1,70 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+type blockingClient struct {
+	release chan struct{}
+}
+
+func (c blockingClient) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	<-c.release
+	return restql.HTTPResponse{StatusCode: http.StatusOK, Body: restql.NewResponseBodyFromValue(test.NoOpLogger, "ok")}, nil
+}
+
+func TestRunnerActiveExecutions(t *testing.T) {
+	client := blockingClient{release: make(chan struct{})}
+	executor := runner.NewExecutor(test.NoOpLogger, client, nil, nil, nil, time.Second, "", nil)
+	r := runner.NewRunner(test.NoOpLogger, executor, time.Second, runner.DefaultsCascade{}, nil, 0)
+
+	query := domain.Query{Statements: []domain.Statement{{Method: domain.FromMethod, Resource: "hero"}}}
+	queryCtx := restql.QueryContext{
+		Mappings: map[string]restql.Mapping{"hero": mapping(t, "http://hero.io/api")},
+		Options:  restql.QueryOptions{Tenant: "acme", Namespace: "heroes", Id: "all", Revision: 1},
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ctx := restql.WithLogger(context.Background(), test.NoOpLogger)
+		_, err := r.ExecuteQuery(ctx, query, queryCtx)
+		test.VerifyError(t, err)
+		close(done)
+	}()
+
+	waitForExecution(t, r)
+
+	executions := r.ActiveExecutions()
+	test.Equal(t, len(executions), 1)
+	test.Equal(t, executions[0].Tenant, "acme")
+	test.Equal(t, executions[0].Query, "all")
+	test.Equal(t, executions[0].Statements, map[string]string{"hero": runner.StatementRequested})
+
+	close(client.release)
+	<-done
+
+	test.Equal(t, len(r.ActiveExecutions()), 0)
+}
+
+func waitForExecution(t *testing.T, r runner.Runner) {
+	deadline := time.Now().Add(time.Second)
+	for {
+		executions := r.ActiveExecutions()
+		if len(executions) > 0 && executions[0].Statements["hero"] == runner.StatementRequested {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("execution not tracked before deadline")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,62 @@
+package restql
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// ResponseEncoder is the interface implemented by custom output
+// encodings of the query responses, like an envelope required by
+// the consumers or a hypermedia format.
+//
+// The encoder is chosen by its name on the _format query parameter
+// or, without it, by one of its media types on the Accept header.
+type ResponseEncoder interface {
+	Name() string
+	MediaTypes() []string
+	Encode(ctx context.Context, response EncodableResponse) (contentType string, body []byte, err error)
+}
+
+// EncodableResponse represents the result of a query execution to
+// be encoded. Body holds the statement results by resource identifier,
+// each with its details and result, in the same structure of the JSON
+// response, with maps, slices and primitive values decoded from JSON.
+type EncodableResponse struct {
+	Status int
+	Header map[string]string
+	Body   map[string]interface{}
+}
+
+var (
+	responseEncoders   []ResponseEncoder
+	responseEncodersMu sync.RWMutex
+)
+
+// RegisterResponseEncoder makes the encoder available to the query
+// endpoints. Encoders are registered once, usually when the plugin
+// package is initialized. In case of an encoder already registered
+// with the same name, a warn message will be printed to the os.Stdout
+// and the new one ignored.
+func RegisterResponseEncoder(encoder ResponseEncoder) {
+	responseEncodersMu.Lock()
+	defer responseEncodersMu.Unlock()
+
+	for _, e := range responseEncoders {
+		if e.Name() == encoder.Name() {
+			log.Printf("[WARN] response encoder already registred: %s", encoder.Name())
+			return
+		}
+	}
+
+	responseEncoders = append(responseEncoders, encoder)
+}
+
+// GetResponseEncoders returns the registered encoders,
+// in the order they were registered.
+func GetResponseEncoders() []ResponseEncoder {
+	responseEncodersMu.RLock()
+	defer responseEncodersMu.RUnlock()
+
+	return responseEncoders
+}
@@ -0,0 +1,274 @@
+/*
+Package engine embeds the restQL interpreter in Go applications,
+running queries against the mapped resources without starting the
+restQL HTTP server.
+
+	e, err := engine.New(engine.Config{
+		Mappings: map[string]string{"hero": "http://hero.api/hero/:id"},
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := e.Execute(ctx, "from hero with id = $id", map[string]interface{}{"id": 1})
+
+The query result has the same form of the body returned by the
+`/run-query` endpoint, and plugins registered through the restql
+package are run as they are in the server.
+*/
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/internal/eval"
+	"github.com/b2wdigital/restQL-golang/v4/internal/parser"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/cache"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/conf"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/httpclient"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/persistence"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/plugins"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/web"
+	"github.com/b2wdigital/restQL-golang/v4/internal/runner"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// Errors returned by Engine when the query cannot be executed.
+var (
+	ErrValidation = eval.ErrValidation
+	ErrParser     = eval.ErrParser
+	ErrTimeout    = eval.ErrTimeout
+	ErrMapping    = eval.ErrMapping
+)
+
+// Default values used for the Config fields left empty.
+const (
+	DefaultTenant             = "default"
+	DefaultGlobalQueryTimeout = 30 * time.Second
+	DefaultResourceTimeout    = 5 * time.Second
+)
+
+const debugParamName = "_debug"
+
+// Config represents the parameters of an embedded engine.
+type Config struct {
+	// Logger receives the engine logs, which are discarded if none is given.
+	Logger restql.Logger
+
+	// Tenant under which the mappings of the database plugin are fetched.
+	Tenant string
+
+	// Mappings associates resource names to their URLs.
+	Mappings map[string]string
+
+	// Queries holds the revisions of saved queries by namespace and name.
+	Queries map[string]map[string][]string
+
+	// HTTPClient replaces the client calling the upstreams, like the
+	// fake client of the restqltest package. The request hooks of
+	// lifecycle plugins are not run for a replaced client.
+	HTTPClient restql.HTTPClient
+
+	GlobalQueryTimeout time.Duration
+	ResourceTimeout    time.Duration
+	MaxChainDepth      int
+	ForwardPrefix      string
+}
+
+// Warning represents a non fatal issue found while executing a query.
+type Warning struct {
+	Code      string `json:"code"`
+	Statement string `json:"statement,omitempty"`
+	Message   string `json:"message"`
+}
+
+// Result represents the outcome of a query execution.
+// Body has one entry by statement, with its details and
+// result in their generic JSON form.
+type Result struct {
+	StatusCode int
+	Headers    map[string]string
+	Body       map[string]interface{}
+	Warnings   []Warning
+}
+
+// Engine executes restQL queries, sharing the upstream
+// connections and caches between executions.
+// It is safe for concurrent use.
+type Engine struct {
+	log       restql.Logger
+	tenant    string
+	evaluator eval.Evaluator
+	redactor  web.HeaderRedactor
+}
+
+// New constructs an Engine from the given configuration.
+func New(config Config) (*Engine, error) {
+	log := config.Logger
+	if log == nil {
+		log = restql.GetLogger(context.Background())
+	}
+
+	cfg := conf.Default()
+	cfg.Tenant = config.Tenant
+	if cfg.Tenant == "" {
+		cfg.Tenant = DefaultTenant
+	}
+	cfg.Mappings = config.Mappings
+	cfg.Queries = config.Queries
+	cfg.HTTP.GlobalQueryTimeout = config.GlobalQueryTimeout
+	if cfg.HTTP.GlobalQueryTimeout == 0 {
+		cfg.HTTP.GlobalQueryTimeout = DefaultGlobalQueryTimeout
+	}
+	cfg.HTTP.QueryResourceTimeout = config.ResourceTimeout
+	if cfg.HTTP.QueryResourceTimeout == 0 {
+		cfg.HTTP.QueryResourceTimeout = DefaultResourceTimeout
+	}
+	cfg.HTTP.MaxChainDepth = config.MaxChainDepth
+	cfg.HTTP.ForwardPrefix = config.ForwardPrefix
+
+	defaultParser, err := parser.New()
+	if err != nil {
+		return nil, err
+	}
+	parserCacheLoader := cache.New(log, cfg.Cache.Parser.MaxSize,
+		cache.ParserCacheLoader(defaultParser),
+		cache.WithTTL(cfg.Cache.Parser.TTL),
+		cache.WithName("parser"),
+	)
+	parserCache := cache.NewParserCache(log, parserCacheLoader)
+
+	db, err := persistence.NewDatabase(log, cfg.Plugins.DisableDatabase)
+	if err != nil {
+		return nil, err
+	}
+
+	lifecycle, err := plugins.NewLifecycle(log)
+	if err != nil {
+		return nil, err
+	}
+
+	var client domain.HTTPClient = config.HTTPClient
+	if client == nil {
+		client = httpclient.New(log, lifecycle, cfg)
+	}
+	responseCache := cache.NewResponseCache(log, cfg.Cache.Responses.MaxSize)
+	executor := runner.NewExecutor(log, client, responseCache, nil, nil, cfg.HTTP.QueryResourceTimeout, cfg.HTTP.ForwardPrefix, nil)
+	r := runner.NewRunner(log, executor, cfg.HTTP.GlobalQueryTimeout, runner.DefaultsCascade{}, nil, cfg.HTTP.MaxChainDepth)
+
+	mappingReader := persistence.NewMappingReader(log, noEnv{}, cfg.Mappings, nil, db)
+	queryReader := persistence.NewQueryReader(log, cfg.Queries, db)
+
+	redactor, err := web.NewHeaderRedactor(cfg.Debug.RedactHeaders, cfg.Debug.RedactHeaderPatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Engine{
+		log:       log,
+		tenant:    cfg.Tenant,
+		evaluator: eval.NewEvaluator(log, mappingReader, queryReader, r, parserCache, lifecycle, cfg.HTTP.FailOnHiddenErrors, nil),
+		redactor:  redactor,
+	}, nil
+}
+
+// Execute runs the ad-hoc query with the given parameters.
+// Like in the HTTP API, the `_debug` parameter adds the
+// upstream requests and execution timeline to the result.
+func (e *Engine) Execute(ctx context.Context, queryText string, params map[string]interface{}) (Result, error) {
+	return e.ExecuteInput(ctx, queryText, restql.QueryInput{Params: params})
+}
+
+// ExecuteInput runs the ad-hoc query with the given parameters,
+// headers and body.
+func (e *Engine) ExecuteInput(ctx context.Context, queryText string, input restql.QueryInput) (Result, error) {
+	ctx = e.context(ctx, input)
+	options := restql.QueryOptions{Tenant: e.tenant}
+
+	resources, err := e.evaluator.AdHocQuery(ctx, queryText, options, input)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return e.makeResult(ctx, resources, input)
+}
+
+// ExecuteSaved runs the saved query revision with the given input.
+func (e *Engine) ExecuteSaved(ctx context.Context, namespace, queryID string, revision int, input restql.QueryInput) (Result, error) {
+	ctx = e.context(ctx, input)
+	options := restql.QueryOptions{Namespace: namespace, Id: queryID, Revision: revision, Tenant: e.tenant}
+
+	resources, err := e.evaluator.SavedQuery(ctx, options, input)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return e.makeResult(ctx, resources, input)
+}
+
+func (e *Engine) context(ctx context.Context, input restql.QueryInput) context.Context {
+	ctx = restql.WithLogger(ctx, e.log)
+	ctx = eval.WithWarnings(ctx)
+	if isDebugEnabled(input) {
+		ctx = runner.WithTimeline(ctx)
+	}
+
+	return ctx
+}
+
+func (e *Engine) makeResult(ctx context.Context, resources domain.Resources, input restql.QueryInput) (Result, error) {
+	response, err := web.MakeQueryResponse(resources, web.DebugOptions{Enabled: isDebugEnabled(input), Redactor: e.redactor})
+	if err != nil {
+		return Result{}, err
+	}
+
+	body := make(map[string]interface{}, len(response.Body))
+	for statement, result := range response.Body {
+		v, err := toJSONValue(result)
+		if err != nil {
+			return Result{}, err
+		}
+		body[statement] = v
+	}
+
+	var warnings []Warning
+	for _, w := range eval.Warnings(ctx) {
+		warnings = append(warnings, Warning{Code: w.Code, Statement: w.Statement, Message: w.Message})
+	}
+
+	return Result{StatusCode: response.StatusCode, Headers: response.Headers, Body: body, Warnings: warnings}, nil
+}
+
+func isDebugEnabled(input restql.QueryInput) bool {
+	switch debug := input.Params[debugParamName].(type) {
+	case bool:
+		return debug
+	case string:
+		enabled, err := strconv.ParseBool(debug)
+		return err == nil && enabled
+	default:
+		return false
+	}
+}
+
+func toJSONValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
+
+// noEnv keeps the embedded engine from reading
+// mappings from environment variables.
+type noEnv struct{}
+
+func (noEnv) GetString(key string) string { return "" }
+func (noEnv) GetAll() map[string]string   { return map[string]string{} }
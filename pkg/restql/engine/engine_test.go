@@ -0,0 +1,50 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql/engine"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestEngineExecute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id": "`+r.URL.Query().Get("id")+`", "name": "batman"}`)
+	}))
+	defer server.Close()
+
+	e, err := engine.New(engine.Config{Mappings: map[string]string{"hero": server.URL + "/hero"}})
+	test.VerifyError(t, err)
+
+	result, err := e.Execute(context.Background(), "from hero with id = $id only name", map[string]interface{}{"id": "1"})
+	test.VerifyError(t, err)
+
+	expectedBody := map[string]interface{}{
+		"hero": map[string]interface{}{
+			"details": map[string]interface{}{
+				"status":   float64(200),
+				"success":  true,
+				"metadata": map[string]interface{}{},
+			},
+			"result": map[string]interface{}{"name": "batman"},
+		},
+	}
+
+	test.Equal(t, result.StatusCode, http.StatusOK)
+	test.Equal(t, result.Body, expectedBody)
+}
+
+func TestEngineExecuteWithUnknownMapping(t *testing.T) {
+	e, err := engine.New(engine.Config{Mappings: map[string]string{"hero": "http://hero.api/hero"}})
+	test.VerifyError(t, err)
+
+	_, err = e.Execute(context.Background(), "from villain", nil)
+	if !errors.Is(err, engine.ErrMapping) {
+		t.Fatalf("expected a mapping error, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package restql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Event is implemented by the typed events published by
+// restQL while it executes the statements of a query.
+type Event interface {
+	EventName() string
+}
+
+// Names of the events published by restQL.
+const (
+	StatementStartedEventName  = "statement_started"
+	StatementFinishedEventName = "statement_finished"
+	ResponseCacheHitEventName  = "response_cache_hit"
+	RequestRetryEventName      = "request_retry"
+	UpstreamThrottledEventName = "upstream_throttled"
+	QueryFinishedEventName     = "query_finished"
+	SLOEvaluatedEventName      = "slo_evaluated"
+)
+
+// StatementStartedEvent is published before the
+// request of a statement to its upstream is made.
+type StatementStartedEvent struct {
+	Resource string
+	Method   string
+	URL      string
+	At       time.Time
+}
+
+// EventName returns the name of the event.
+func (e StatementStartedEvent) EventName() string { return StatementStartedEventName }
+
+// StatementFinishedEvent is published when the statement
+// result is done, after retries and failovers. Cache is
+// ResponseCacheHit or ResponseCacheMiss when the statement
+// revalidates a cached response, and empty otherwise.
+type StatementFinishedEvent struct {
+	Resource   string
+	Method     string
+	URL        string
+	StatusCode int
+	Success    bool
+	Duration   time.Duration
+	Cache      string
+}
+
+// EventName returns the name of the event.
+func (e StatementFinishedEvent) EventName() string { return StatementFinishedEventName }
+
+// ResponseCacheHitEvent is published when an upstream answers
+// a conditional request with 304 Not Modified and the response
+// cached for the URL is used as the statement result.
+type ResponseCacheHitEvent struct {
+	Resource string
+	URL      string
+}
+
+// EventName returns the name of the event.
+func (e ResponseCacheHitEvent) EventName() string { return ResponseCacheHitEventName }
+
+// RequestRetryEvent is published before a failed
+// statement request is done again.
+type RequestRetryEvent struct {
+	Resource string
+	Method   string
+	Attempt  int
+	Err      error
+}
+
+// EventName returns the name of the event.
+func (e RequestRetryEvent) EventName() string { return RequestRetryEventName }
+
+// UpstreamThrottledEvent is published when an upstream answers a
+// statement request with 429 Too Many Requests, or 503 Service
+// Unavailable with a Retry-After header. RetryAfter is the wait it
+// asked for and Retried reports if the request is done again.
+type UpstreamThrottledEvent struct {
+	Resource   string
+	Method     string
+	URL        string
+	StatusCode int
+	RetryAfter time.Duration
+	Retried    bool
+}
+
+// EventName returns the name of the event.
+func (e UpstreamThrottledEvent) EventName() string { return UpstreamThrottledEventName }
+
+// QueryFinishedEvent is published once the statements of a query
+// are executed, before its results are filtered. Namespace, Query and
+// Revision are empty for ad-hoc queries. Params holds the input
+// parameters of the query, Statuses the status of each statement by
+// resource identifier, which is the highest one for multiplexed
+// statements, and Err the failure of the execution, if any.
+type QueryFinishedEvent struct {
+	Tenant    string
+	Namespace string
+	Query     string
+	Revision  int
+	Params    map[string]interface{}
+	Statuses  map[string]int
+	Duration  time.Duration
+	Err       error
+}
+
+// EventName returns the name of the event.
+func (e QueryFinishedEvent) EventName() string { return QueryFinishedEventName }
+
+// SLOEvaluatedEvent is published once a statement or a query with a
+// target latency, given by the `slo` modifier, is executed. Statement
+// is the resource identifier of the statement, empty for the query
+// itself, and Met reports if the Latency was within the Target.
+type SLOEvaluatedEvent struct {
+	Tenant    string
+	Namespace string
+	Query     string
+	Revision  int
+	Statement string
+	Resource  string
+	Target    time.Duration
+	Latency   time.Duration
+	Met       bool
+}
+
+// EventName returns the name of the event.
+func (e SLOEvaluatedEvent) EventName() string { return SLOEvaluatedEventName }
+
+// EventHandler receives the events published during query executions,
+// along with the context of the statement that originated them.
+// Handlers are called synchronously by the goroutine executing the
+// statement, hence they should not block.
+type EventHandler func(ctx context.Context, event Event)
+
+type eventSubscription struct {
+	seat    int
+	handler EventHandler
+}
+
+var (
+	eventsMu      sync.RWMutex
+	subscriptions []eventSubscription
+	nextSeat      int
+)
+
+// SubscribeEvents registers the handler to receive every event
+// published by restQL, returning a function that removes it.
+// Plugins can subscribe when they are initialized and applications
+// embedding restQL before executing queries.
+func SubscribeEvents(handler EventHandler) (unsubscribe func()) {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+
+	seat := nextSeat
+	nextSeat++
+	subscriptions = append(subscriptions, eventSubscription{seat: seat, handler: handler})
+
+	return func() {
+		eventsMu.Lock()
+		defer eventsMu.Unlock()
+
+		for i, s := range subscriptions {
+			if s.seat == seat {
+				subscriptions = append(subscriptions[:i:i], subscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// PublishEvent delivers the event to the subscribed handlers, in the
+// order they subscribed. It is called by restQL runtime and should
+// not be used by plugins.
+func PublishEvent(ctx context.Context, event Event) {
+	eventsMu.RLock()
+	current := subscriptions
+	eventsMu.RUnlock()
+
+	for _, s := range current {
+		s.handler(ctx, event)
+	}
+}
@@ -0,0 +1,26 @@
+package restql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestSubscribeEvents(t *testing.T) {
+	var received []string
+	first := restql.SubscribeEvents(func(ctx context.Context, event restql.Event) {
+		received = append(received, "first:"+event.(restql.ResponseCacheHitEvent).Resource)
+	})
+	second := restql.SubscribeEvents(func(ctx context.Context, event restql.Event) {
+		received = append(received, "second:"+event.(restql.ResponseCacheHitEvent).Resource)
+	})
+	defer second()
+
+	restql.PublishEvent(context.Background(), restql.ResponseCacheHitEvent{Resource: "hero"})
+	first()
+	restql.PublishEvent(context.Background(), restql.ResponseCacheHitEvent{Resource: "villain"})
+
+	test.Equal(t, received, []string{"first:hero", "second:hero", "second:villain"})
+}
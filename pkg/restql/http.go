@@ -1,6 +1,7 @@
 package restql
 
 import (
+	"context"
 	"time"
 )
 
@@ -10,6 +11,12 @@ type Body interface{}
 // Headers represents all HTTP header in a request or response.
 type Headers map[string]string
 
+// HTTPClient executes the HTTP calls to the upstream dependencies,
+// respecting the cancellation signal from the given context.Context.
+type HTTPClient interface {
+	Do(ctx context.Context, request HTTPRequest) (HTTPResponse, error)
+}
+
 // HttpRequest represents a HTTP call to be
 // made to an upstream dependency defined by the mappings.
 type HTTPRequest struct {
@@ -21,6 +28,14 @@ type HTTPRequest struct {
 	Body    Body
 	Headers Headers
 	Timeout time.Duration
+
+	// MaxResponseSize is the maximum size, in bytes, of the
+	// response body, where zero means there is no limit.
+	MaxResponseSize int
+
+	// Trace requests the HTTPTimings of the call, which is then
+	// made over a dedicated connection instead of a pooled one.
+	Trace bool
 }
 
 // HttpResponse represents a HTTP call result
@@ -31,5 +46,16 @@ type HTTPResponse struct {
 	Body       *ResponseBody
 	Headers    Headers
 	Duration   time.Duration
+	Timings    *HTTPTimings
+}
+
+// HTTPTimings represents the duration of each phase
+// of a traced HTTP call, where FirstByte is measured
+// from the moment the request starts being written.
+type HTTPTimings struct {
+	DNS       time.Duration
+	Connect   time.Duration
+	TLS       time.Duration
+	FirstByte time.Duration
 }
 
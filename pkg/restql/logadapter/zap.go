@@ -0,0 +1,75 @@
+package logadapter
+
+import (
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+)
+
+// SugaredLogger is the subset of the zap.SugaredLogger methods
+// used by the zap adapter, which is declared here so that restQL
+// does not depend on zap. A *zap.SugaredLogger satisfies it.
+type SugaredLogger interface {
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+	Panicw(msg string, keysAndValues ...interface{})
+	Fatalw(msg string, keysAndValues ...interface{})
+}
+
+type zapLogger struct {
+	sLogger SugaredLogger
+	level   restql.Level
+	fields  []interface{}
+}
+
+// Zap returns a restql.Logger writing the entries with the given
+// zap.SugaredLogger, reporting as enabled the levels from the given
+// one up, which should be the minimum level of the zap core.
+func Zap(l SugaredLogger, level restql.Level) restql.Logger {
+	return &zapLogger{sLogger: l, level: level}
+}
+
+func (zl *zapLogger) Panic(msg string, fields ...interface{}) {
+	zl.sLogger.Panicw(msg, zl.withContext(fields)...)
+}
+
+func (zl *zapLogger) Fatal(msg string, fields ...interface{}) {
+	zl.sLogger.Fatalw(msg, zl.withContext(fields)...)
+}
+
+func (zl *zapLogger) Error(msg string, err error, fields ...interface{}) {
+	zl.sLogger.Errorw(msg, zl.withContext(append([]interface{}{"error", err}, fields...))...)
+}
+
+func (zl *zapLogger) Warn(msg string, fields ...interface{}) {
+	zl.sLogger.Warnw(msg, zl.withContext(fields)...)
+}
+
+func (zl *zapLogger) Info(msg string, fields ...interface{}) {
+	zl.sLogger.Infow(msg, zl.withContext(fields)...)
+}
+
+func (zl *zapLogger) Debug(msg string, fields ...interface{}) {
+	zl.sLogger.Debugw(msg, zl.withContext(fields)...)
+}
+
+func (zl *zapLogger) With(key string, value interface{}) restql.Logger {
+	return zl.WithFields(key, value)
+}
+
+func (zl *zapLogger) WithFields(fields ...interface{}) restql.Logger {
+	return &zapLogger{sLogger: zl.sLogger, level: zl.level, fields: zl.withContext(fields)}
+}
+
+func (zl *zapLogger) Enabled(level restql.Level) bool {
+	return level >= zl.level
+}
+
+// withContext returns the fields of the derived logger followed
+// by the entry ones, in a new slice so that loggers derived from
+// the same parent do not share the backing array.
+func (zl *zapLogger) withContext(fields []interface{}) []interface{} {
+	all := make([]interface{}, 0, len(zl.fields)+len(fields))
+	all = append(all, zl.fields...)
+	return append(all, fields...)
+}
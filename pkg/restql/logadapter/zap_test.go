@@ -0,0 +1,68 @@
+package logadapter_test
+
+import (
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql/logadapter"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+type zapEntry struct {
+	Level         string
+	Msg           string
+	KeysAndValues []interface{}
+}
+
+// sugaredLoggerSpy records the entries the way a
+// zap.SugaredLogger receives them.
+type sugaredLoggerSpy struct {
+	entries []zapEntry
+}
+
+func (s *sugaredLoggerSpy) record(level, msg string, kv []interface{}) {
+	s.entries = append(s.entries, zapEntry{Level: level, Msg: msg, KeysAndValues: kv})
+}
+
+func (s *sugaredLoggerSpy) Debugw(msg string, kv ...interface{}) { s.record("debug", msg, kv) }
+func (s *sugaredLoggerSpy) Infow(msg string, kv ...interface{})  { s.record("info", msg, kv) }
+func (s *sugaredLoggerSpy) Warnw(msg string, kv ...interface{})  { s.record("warn", msg, kv) }
+func (s *sugaredLoggerSpy) Errorw(msg string, kv ...interface{}) { s.record("error", msg, kv) }
+func (s *sugaredLoggerSpy) Panicw(msg string, kv ...interface{}) { s.record("panic", msg, kv) }
+func (s *sugaredLoggerSpy) Fatalw(msg string, kv ...interface{}) { s.record("fatal", msg, kv) }
+
+type zapTestError string
+
+func (e zapTestError) Error() string { return string(e) }
+
+func TestZap(t *testing.T) {
+	spy := &sugaredLoggerSpy{}
+	log := logadapter.Zap(spy, restql.InfoLevel)
+
+	timeout := zapTestError("timeout")
+	base := log.WithFields("tenant", "acme", "revision", 2)
+	base.With("query", "products").Error("request failed", timeout, "resource", "hero")
+	base.With("query", "orders").Info("request done")
+
+	expected := []zapEntry{
+		{
+			Level:         "error",
+			Msg:           "request failed",
+			KeysAndValues: []interface{}{"tenant", "acme", "revision", 2, "query", "products", "error", timeout, "resource", "hero"},
+		},
+		{
+			Level:         "info",
+			Msg:           "request done",
+			KeysAndValues: []interface{}{"tenant", "acme", "revision", 2, "query", "orders"},
+		},
+	}
+	test.Equal(t, spy.entries, expected)
+}
+
+func TestZapEnabled(t *testing.T) {
+	log := logadapter.Zap(&sugaredLoggerSpy{}, restql.WarnLevel)
+
+	test.Equal(t, log.Enabled(restql.InfoLevel), false)
+	test.Equal(t, log.Enabled(restql.WarnLevel), true)
+	test.Equal(t, log.Enabled(restql.ErrorLevel), true)
+}
@@ -0,0 +1,102 @@
+/*
+Package logadapter provides restql.Logger implementations backed by
+logging libraries, for applications embedding restQL or plugins that
+want restQL entries in the same output as their own logs.
+
+	zl := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	log := logadapter.Zerolog(zl)
+
+The zap adapter takes a *zap.SugaredLogger through the SugaredLogger
+interface, so restQL does not require zap as a dependency.
+
+	zl, _ := zap.NewProduction()
+	log := logadapter.Zap(zl.Sugar(), restql.InfoLevel)
+
+Other libraries can be plugged by implementing restql.Logger.
+*/
+package logadapter
+
+import (
+	"fmt"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/rs/zerolog"
+)
+
+var zerologLevels = map[restql.Level]zerolog.Level{
+	restql.DebugLevel: zerolog.DebugLevel,
+	restql.InfoLevel:  zerolog.InfoLevel,
+	restql.WarnLevel:  zerolog.WarnLevel,
+	restql.ErrorLevel: zerolog.ErrorLevel,
+	restql.FatalLevel: zerolog.FatalLevel,
+	restql.PanicLevel: zerolog.PanicLevel,
+}
+
+type zeroLogger struct {
+	zLogger zerolog.Logger
+}
+
+// Zerolog returns a restql.Logger writing the
+// entries with the given zerolog.Logger.
+func Zerolog(l zerolog.Logger) restql.Logger {
+	return &zeroLogger{zLogger: l}
+}
+
+func (zl *zeroLogger) Panic(msg string, fields ...interface{}) {
+	zl.zLogger.Panic().Fields(makeFieldMap(fields)).Msg(msg)
+}
+
+func (zl *zeroLogger) Fatal(msg string, fields ...interface{}) {
+	zl.zLogger.Fatal().Fields(makeFieldMap(fields)).Msg(msg)
+}
+
+func (zl *zeroLogger) Error(msg string, err error, fields ...interface{}) {
+	zl.zLogger.Error().Err(err).Fields(makeFieldMap(fields)).Msg(msg)
+}
+
+func (zl *zeroLogger) Warn(msg string, fields ...interface{}) {
+	zl.zLogger.Warn().Fields(makeFieldMap(fields)).Msg(msg)
+}
+
+func (zl *zeroLogger) Info(msg string, fields ...interface{}) {
+	zl.zLogger.Info().Fields(makeFieldMap(fields)).Msg(msg)
+}
+
+func (zl *zeroLogger) Debug(msg string, fields ...interface{}) {
+	zl.zLogger.Debug().Fields(makeFieldMap(fields)).Msg(msg)
+}
+
+func (zl *zeroLogger) With(key string, value interface{}) restql.Logger {
+	cl := zl.zLogger.With().Str(key, fmt.Sprintf("%v", value)).Logger()
+	return &zeroLogger{zLogger: cl}
+}
+
+func (zl *zeroLogger) WithFields(fields ...interface{}) restql.Logger {
+	cl := zl.zLogger.With().Fields(makeFieldMap(fields)).Logger()
+	return &zeroLogger{zLogger: cl}
+}
+
+func (zl *zeroLogger) Enabled(level restql.Level) bool {
+	zLevel, ok := zerologLevels[level]
+	if !ok {
+		return false
+	}
+
+	min := zl.zLogger.GetLevel()
+	if global := zerolog.GlobalLevel(); global > min {
+		min = global
+	}
+
+	return min != zerolog.Disabled && zLevel >= min
+}
+
+func makeFieldMap(fields []interface{}) map[string]interface{} {
+	fieldMap := make(map[string]interface{})
+	for i := 0; i <= len(fields)-2; i += 2 {
+		key := fmt.Sprintf("%v", fields[i])
+		value := fields[i+1]
+
+		fieldMap[key] = value
+	}
+	return fieldMap
+}
@@ -0,0 +1,47 @@
+package logadapter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql/logadapter"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+	"github.com/rs/zerolog"
+)
+
+func TestZerolog(t *testing.T) {
+	var buf bytes.Buffer
+	log := logadapter.Zerolog(zerolog.New(&buf).Level(zerolog.InfoLevel))
+
+	log.WithFields("tenant", "acme", "revision", 2).With("query", "products").Error("request failed", errors.New("timeout"), "resource", "hero")
+	log.Debug("discarded")
+
+	var entry map[string]interface{}
+	err := json.Unmarshal(buf.Bytes(), &entry)
+	test.VerifyError(t, err)
+
+	expected := map[string]interface{}{
+		"level":    "error",
+		"message":  "request failed",
+		"error":    "timeout",
+		"tenant":   "acme",
+		"revision": float64(2),
+		"query":    "products",
+		"resource": "hero",
+	}
+	test.Equal(t, entry, expected)
+}
+
+func TestZerologEnabled(t *testing.T) {
+	log := logadapter.Zerolog(zerolog.New(nil).Level(zerolog.WarnLevel))
+
+	test.Equal(t, log.Enabled(restql.InfoLevel), false)
+	test.Equal(t, log.Enabled(restql.WarnLevel), true)
+	test.Equal(t, log.Enabled(restql.ErrorLevel), true)
+
+	disabled := logadapter.Zerolog(zerolog.New(nil).Level(zerolog.Disabled))
+	test.Equal(t, disabled.Enabled(restql.PanicLevel), false)
+}
@@ -2,9 +2,55 @@ package restql
 
 import (
 	"context"
+	"fmt"
+	"strings"
 )
 
-// Logger is the interface that wraps all methods for log handling
+// Level represents the severity of a log entry.
+type Level int8
+
+// Log levels, from the least to the most severe.
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	FatalLevel
+	PanicLevel
+)
+
+var levelNames = map[Level]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+	FatalLevel: "fatal",
+	PanicLevel: "panic",
+}
+
+// String returns the lower case name of the level.
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", l)
+}
+
+// ParseLevel returns the Level with the given
+// name, which is case insensitive.
+func ParseLevel(name string) (Level, error) {
+	for level, n := range levelNames {
+		if strings.EqualFold(n, name) {
+			return level, nil
+		}
+	}
+	return DebugLevel, fmt.Errorf("unknown log level : %s", name)
+}
+
+// Logger is the interface that wraps all methods for log handling.
+// The fields are given as alternating keys and values, and the
+// loggers derived by With and WithFields carry their fields to
+// every entry they log.
 type Logger interface {
 	Panic(msg string, fields ...interface{})
 	Fatal(msg string, fields ...interface{})
@@ -13,6 +59,8 @@ type Logger interface {
 	Info(msg string, fields ...interface{})
 	Debug(msg string, fields ...interface{})
 	With(key string, value interface{}) Logger
+	WithFields(fields ...interface{}) Logger
+	Enabled(level Level) bool
 }
 
 type loggerCtxKey struct{}
@@ -49,3 +97,5 @@ func (n noOpLogger) Warn(msg string, fields ...interface{})             {}
 func (n noOpLogger) Info(msg string, fields ...interface{})             {}
 func (n noOpLogger) Debug(msg string, fields ...interface{})            {}
 func (n noOpLogger) With(key string, value interface{}) Logger          { return n }
+func (n noOpLogger) WithFields(fields ...interface{}) Logger            { return n }
+func (n noOpLogger) Enabled(level Level) bool                           { return false }
@@ -8,7 +8,7 @@ import (
 )
 
 var pathParamRegex = regexp.MustCompile(":([^/]+)/?")
-var urlRegex = regexp.MustCompile("(https?)://([^/]+)([^?]*)\\??(.*)")
+var urlRegex = regexp.MustCompile("(https?|http\\+unix|sql|s3|kafka|amqp)://([^/]+)([^?]*)\\??(.*)")
 
 // Mapping represents the association of a name to a REST resource url.
 // It support special syntax in the URL to provide dynamic value substitution, like:
@@ -18,6 +18,15 @@ var urlRegex = regexp.MustCompile("(https?)://([^/]+)([^?]*)\\??(.*)")
 //• QueryRevisions parameters: can be defined by placing a colon (:) before an identifier in the URL query,
 // for example "http://some.api?:page", will replace ":page" by the value of the "page" parameter
 // in the query definition creating the URL "http://some.api?page=<value>".
+// Besides HTTP, the URL can use the sql scheme, as in "sql://catalog/planets",
+// to be answered by the "planets" query of the "catalog" database, or the s3
+// scheme, as in "s3://configs/features/:tenant", to fetch an object of a bucket.
+// The kafka and amqp schemes, as in "kafka://orders/:id" or "amqp://orders/created",
+// publish the body of to statements to a topic or exchange.
+// Upstreams listening on a Unix domain socket are targeted by the http+unix
+// scheme with the URL encoded socket path as host, as in
+// "http+unix://%2Fvar%2Frun%2Fcatalog.sock/products/:id", while IPv6
+// addresses are given in brackets, as in "http://[::1]:8080/products/:id".
 type Mapping struct {
 	resourceName  string
 	url           string
@@ -94,3 +94,41 @@ func TestMappingsQueryWithParams(t *testing.T) {
 		})
 	}
 }
+
+func TestSQLMapping(t *testing.T) {
+	mapping, err := restql.NewMapping("planets", "sql://catalog/planets")
+	test.VerifyError(t, err)
+
+	test.Equal(t, mapping.Schema(), "sql")
+	test.Equal(t, mapping.Host(), "catalog")
+	test.Equal(t, mapping.PathWithParams(nil), "/planets")
+}
+
+func TestS3Mapping(t *testing.T) {
+	mapping, err := restql.NewMapping("features", "s3://configs/features/:tenant")
+	test.VerifyError(t, err)
+
+	test.Equal(t, mapping.Schema(), "s3")
+	test.Equal(t, mapping.Host(), "configs")
+	test.Equal(t, mapping.IsPathParam("tenant"), true)
+	test.Equal(t, mapping.PathWithParams(map[string]interface{}{"tenant": "acme.json"}), "/features/acme.json")
+}
+
+func TestUnixSocketMapping(t *testing.T) {
+	mapping, err := restql.NewMapping("products", "http+unix://%2Fvar%2Frun%2Fcatalog.sock/products/:id")
+	test.VerifyError(t, err)
+
+	test.Equal(t, mapping.Schema(), "http+unix")
+	test.Equal(t, mapping.Host(), "%2Fvar%2Frun%2Fcatalog.sock")
+	test.Equal(t, mapping.PathWithParams(map[string]interface{}{"id": 1}), "/products/1")
+}
+
+func TestIPv6Mapping(t *testing.T) {
+	mapping, err := restql.NewMapping("products", "http://[::1]:8080/products/:id")
+	test.VerifyError(t, err)
+
+	test.Equal(t, mapping.Schema(), "http")
+	test.Equal(t, mapping.Host(), "[::1]:8080")
+	test.Equal(t, mapping.IsPathParam("id"), true)
+	test.Equal(t, mapping.PathWithParams(map[string]interface{}{"id": 1}), "/products/1")
+}
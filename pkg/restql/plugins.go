@@ -3,6 +3,7 @@ package restql
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"net/url"
@@ -46,12 +47,49 @@ func (pt PluginType) String() string {
 	}
 }
 
+// PluginAPIVersion is the major version of the plugin API
+// exposed by this package. Plugins should declare it on
+// PluginInfo.APIVersion so restQL can verify compatibility
+// during registration.
+const PluginAPIVersion = 4
+
+// legacyPluginAPIVersion is the previous major version of the
+// plugin API, still accepted through a compatibility shim.
+const legacyPluginAPIVersion = PluginAPIVersion - 1
+
+// ErrIncompatiblePluginAPI is the error returned when a plugin
+// declares a plugin API version not supported by restQL.
+var ErrIncompatiblePluginAPI = errors.New("incompatible plugin api version")
+
 // PluginInfo represents a plugin instance associating a
 // name and type to a constructor function.
+//
+// APIVersion is the major version of the plugin API the
+// plugin was built against. If not set, it is assumed to be
+// the current PluginAPIVersion.
 type PluginInfo struct {
-	Name string
-	Type PluginType
-	New  func(Logger) (Plugin, error)
+	Name       string
+	Type       PluginType
+	APIVersion int
+	New        func(Logger) (Plugin, error)
+}
+
+// CheckPluginAPIVersion returns an error if the given plugin API version
+// cannot be handled by restQL. Both the current and the previous major
+// version are supported.
+func CheckPluginAPIVersion(version int) error {
+	if version == 0 || version == PluginAPIVersion || version == legacyPluginAPIVersion {
+		return nil
+	}
+
+	return fmt.Errorf("%w: plugin built against version %d, but restQL supports versions %d and %d",
+		ErrIncompatiblePluginAPI, version, legacyPluginAPIVersion, PluginAPIVersion)
+}
+
+// IsLegacy returns true if the plugin was built against
+// the previous major version of the plugin API.
+func (pi PluginInfo) IsLegacy() bool {
+	return pi.APIVersion == legacyPluginAPIVersion
 }
 
 // RegisterPlugin indexes the provided plugin information
@@ -60,10 +98,21 @@ type PluginInfo struct {
 // but only one Database plugin.
 // In case of failure to register the plugin a warn
 // message will be printed to the os.Stdout.
+// Plugins declaring an incompatible APIVersion are refused
+// with an error message.
 func RegisterPlugin(pluginInfo PluginInfo) {
 	pluginsMu.Lock()
 	defer pluginsMu.Unlock()
 
+	if err := CheckPluginAPIVersion(pluginInfo.APIVersion); err != nil {
+		log.Printf("[ERROR] plugin %s refused: %v", pluginInfo.Name, err)
+		return
+	}
+
+	if pluginInfo.IsLegacy() {
+		log.Printf("[WARN] plugin %s uses the deprecated plugin api version %d", pluginInfo.Name, pluginInfo.APIVersion)
+	}
+
 	switch pluginInfo.Type {
 	case LifecyclePluginType:
 		plugins.lifecycle = append(plugins.lifecycle, pluginInfo)
@@ -112,6 +161,20 @@ type LifecyclePlugin interface {
 	AfterRequest(ctx context.Context, request HTTPRequest, response HTTPResponse, err error) context.Context
 }
 
+// LegacyLifecyclePlugin is the interface that defines the
+// lifecycle hooks of the previous plugin API major version,
+// which did not include the transaction hooks.
+//
+// Plugins registered with the legacy APIVersion can implement
+// it instead of LifecyclePlugin.
+type LegacyLifecyclePlugin interface {
+	Plugin
+	BeforeQuery(ctx context.Context, query string, queryCtx QueryContext) context.Context
+	AfterQuery(ctx context.Context, query string, result map[string]interface{}) context.Context
+	BeforeRequest(ctx context.Context, request HTTPRequest) context.Context
+	AfterRequest(ctx context.Context, request HTTPRequest, response HTTPResponse, err error) context.Context
+}
+
 // TransactionRequest represents a query execution
 // transaction received through the /run-query/* endpoints.
 type TransactionRequest struct {
@@ -128,6 +191,25 @@ type TransactionResponse struct {
 	Body   []byte
 }
 
+// ResponsePlugin is an optional interface implemented by lifecycle
+// plugins to post-process the query response before it is written to
+// the client. BeforeResponse receives the evaluated statements and the
+// encoded response, and returns the one to be written, which can be
+// replaced entirely. Plugins are called in registration order, each
+// receiving the response returned by the previous one.
+type ResponsePlugin interface {
+	BeforeResponse(ctx context.Context, result map[string]interface{}, response QueryResponse) QueryResponse
+}
+
+// QueryResponse represents the encoded result of a query
+// execution, as written to the client.
+type QueryResponse struct {
+	Status      int
+	ContentType string
+	Header      map[string]string
+	Body        []byte
+}
+
 // DatabasePlugin is the interface that defines
 // the obligatory operations needed from a database.
 type DatabasePlugin interface {
@@ -0,0 +1,50 @@
+package restql_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestCheckPluginAPIVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  int
+		expected error
+	}{
+		{
+			"should accept plugin without declared version",
+			0,
+			nil,
+		},
+		{
+			"should accept plugin with current version",
+			restql.PluginAPIVersion,
+			nil,
+		},
+		{
+			"should accept plugin with previous major version",
+			restql.PluginAPIVersion - 1,
+			nil,
+		},
+		{
+			"should refuse plugin with older version",
+			restql.PluginAPIVersion - 2,
+			restql.ErrIncompatiblePluginAPI,
+		},
+		{
+			"should refuse plugin with newer version",
+			restql.PluginAPIVersion + 1,
+			restql.ErrIncompatiblePluginAPI,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := restql.CheckPluginAPIVersion(tt.version)
+			test.Equal(t, errors.Is(err, tt.expected), true)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package restql
+
+import "context"
+
+type principalCtxKey struct{}
+
+// WithPrincipal returns a context carrying the name of the API key,
+// or the subject of the token, that authenticated the query request.
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalCtxKey{}, principal)
+}
+
+// Principal extracts the name of who authenticated the
+// query request from the given context.Context.
+func Principal(ctx context.Context) (string, bool) {
+	principal, ok := ctx.Value(principalCtxKey{}).(string)
+	return principal, ok && principal != ""
+}
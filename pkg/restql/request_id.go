@@ -0,0 +1,19 @@
+package restql
+
+import "context"
+
+type requestIDCtxKey struct{}
+
+// WithRequestID returns a context carrying the identifier of the
+// query request, either generated by restQL or adopted from the
+// caller, which is propagated to every upstream call.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, requestID)
+}
+
+// RequestID extracts the identifier of the query
+// request from the given context.Context.
+func RequestID(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDCtxKey{}).(string)
+	return requestID, ok && requestID != ""
+}
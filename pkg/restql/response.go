@@ -2,6 +2,8 @@ package restql
 
 import (
 	"encoding/json"
+	"sync"
+	"time"
 )
 
 // ResponseBody is a wrapper that allows restQL to defer JSON parsing
@@ -21,6 +23,12 @@ type ResponseBody struct {
 	log Logger
 	jsonBytes []byte
 	jsonValue interface{}
+
+	// validation and validBytes memoize the byte slice validation,
+	// since it requires scanning all its content, which happens
+	// once even for bodies shared between goroutines by the caches.
+	validation sync.Once
+	validBytes bool
 }
 
 // NewResponseBodyFromBytes creates a ResponseBody wrapper from
@@ -80,7 +88,7 @@ func (r *ResponseBody) Marshal() (interface{}, error) {
 		return nil, nil
 	}
 
-	if !json.Valid(r.jsonBytes) {
+	if !r.validJSONBytes() {
 		return string(r.jsonBytes), nil
 	}
 
@@ -126,13 +134,23 @@ func (r *ResponseBody) Valid() bool {
 		return true
 	}
 
-	return len(r.jsonBytes) > 0 && json.Valid(r.jsonBytes)
+	return len(r.jsonBytes) > 0 && r.validJSONBytes()
+}
+
+func (r *ResponseBody) validJSONBytes() bool {
+	r.validation.Do(func() {
+		r.validBytes = json.Valid(r.jsonBytes)
+	})
+
+	return r.validBytes
 }
 
 // Clear removes all internal content.
 func (r *ResponseBody) Clear() {
 	r.jsonBytes = nil
 	r.jsonValue = nil
+	r.validation = sync.Once{}
+	r.validBytes = false
 }
 
 // ResourceCacheControlValue represents the values a cache control
@@ -148,6 +166,8 @@ type ResourceCacheControlValue struct {
 // returned by upstream during statement resolution.
 type ResourceCacheControl struct {
 	NoCache bool
+	NoStore bool
+	Private bool
 	MaxAge  ResourceCacheControlValue
 	SMaxAge ResourceCacheControlValue
 }
@@ -166,7 +186,49 @@ type DoneResource struct {
 	ResponseHeaders map[string]string
 	ResponseBody    *ResponseBody
 	ResponseTime    int64
+	Target          string
+	Timeline        *StatementTimeline
+	Mocked          bool
+
+	// SchemaViolations holds how the response body
+	// does not conform to the mapping response schema.
+	SchemaViolations []string
+
+	// Defaulted reports if the response body is the value of
+	// the statement `default` clause instead of the upstream one.
+	Defaulted bool
+
+	// Throttled reports if the upstream refused the
+	// request for exceeding its rate limit.
+	Throttled bool
+
+	// ReturnedHeaders holds the response headers selected
+	// by the statement `return-headers` clause.
+	ReturnedHeaders map[string]string
+
+	// Hidden reports if the statement has the `hidden` clause,
+	// being returned only for having failed.
+	Hidden bool
+}
+
+// Response cache outcomes of a statement revalidation.
+const (
+	ResponseCacheHit  = "hit"
+	ResponseCacheMiss = "miss"
+)
+
+// StatementTimeline represents how a statement was executed,
+// relative to the start of the query, collected when debugging.
+type StatementTimeline struct {
+	StartOffset time.Duration
+	QueueWait   time.Duration
+	Attempts    int
+	Cache       string
+	DependsOn   []string
+	HTTP        *HTTPTimings
 }
 
-// DoneResources represents a multiplexed statement result.
+// DoneResources represents a multiplexed statement result, holding
+// the result of each request in the order of the multiplexed values,
+// regardless of the order in which they were completed.
 type DoneResources []interface{}
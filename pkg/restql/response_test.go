@@ -0,0 +1,30 @@
+package restql_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestResponseBodyConcurrentValidation(t *testing.T) {
+	body := restql.NewResponseBodyFromBytes(test.NoOpLogger, []byte(`{"name":"batman"}`))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			test.Equal(t, body.Valid(), true)
+		}()
+	}
+	wg.Wait()
+
+	invalid := restql.NewResponseBodyFromBytes(test.NoOpLogger, []byte(`{"name"`))
+	test.Equal(t, invalid.Valid(), false)
+
+	marshaled, err := invalid.Marshal()
+	test.VerifyError(t, err)
+	test.Equal(t, marshaled, `{"name"`)
+}
@@ -0,0 +1,241 @@
+/*
+Package restqltest provides an in-memory restQL runtime for tests of
+plugins and applications embedding restQL, where the upstreams are
+replaced by scripted responses.
+
+	fake := restqltest.New()
+	fake.Resource("hero", "/heroes/:id").
+		Respond(restqltest.JSON(200, map[string]interface{}{"id": 1, "name": "batman"}))
+
+	result, err := fake.Run(ctx, "from hero with id = $id", map[string]interface{}{"id": "1"})
+
+	requests := fake.Requests("hero")
+
+Queries run through the same evaluator and runner of the restQL
+server, without any network access.
+*/
+package restqltest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b2wdigital/restQL-golang/v4/internal/domain"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql/engine"
+)
+
+// ErrUnscripted is returned by the fake client for requests
+// to a resource without scripted responses.
+var ErrUnscripted = errors.New("no scripted response for resource")
+
+// Response represents a scripted upstream response.
+// When Err is defined the request fails with it, and
+// when Delay exceeds the request timeout the request
+// fails as timed out.
+type Response struct {
+	StatusCode int
+	Headers    map[string]string
+	Body       interface{}
+	Err        error
+	Delay      time.Duration
+}
+
+// JSON returns a response with the given status code and body,
+// which is any value that can be marshaled to JSON.
+func JSON(statusCode int, body interface{}) Response {
+	return Response{StatusCode: statusCode, Headers: map[string]string{"Content-Type": "application/json"}, Body: body}
+}
+
+// Status returns a response with the given status code and no body.
+func Status(statusCode int) Response {
+	return Response{StatusCode: statusCode}
+}
+
+// Failure returns a response failing the request with the given error,
+// as when the upstream cannot be reached.
+func Failure(err error) Response {
+	return Response{Err: err}
+}
+
+// Script holds the responses of a resource, which are returned
+// in order, the last one answering every following request.
+type Script struct {
+	mu        sync.Mutex
+	responses []Response
+	requests  []restql.HTTPRequest
+}
+
+// Respond appends responses to the script.
+func (s *Script) Respond(responses ...Response) *Script {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses = append(s.responses, responses...)
+	return s
+}
+
+func (s *Script) next(request restql.HTTPRequest) (Response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, request)
+	if len(s.responses) == 0 {
+		return Response{}, false
+	}
+
+	r := s.responses[0]
+	if len(s.responses) > 1 {
+		s.responses = s.responses[1:]
+	}
+
+	return r, true
+}
+
+// Client is a restql.HTTPClient answering the requests
+// with the responses scripted for the requested resource.
+type Client struct {
+	mu      sync.RWMutex
+	scripts map[string]*Script
+}
+
+// NewClient constructs a Client without scripted responses.
+func NewClient() *Client {
+	return &Client{scripts: make(map[string]*Script)}
+}
+
+// Script returns the script of the resource, identified
+// by the host of the URL it is mapped to.
+func (c *Client) Script(resource string) *Script {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, found := c.scripts[resource]
+	if !found {
+		s = &Script{}
+		c.scripts[resource] = s
+	}
+
+	return s
+}
+
+// Do answers the request with the next response scripted
+// for its host, respecting the request timeout.
+func (c *Client) Do(ctx context.Context, request restql.HTTPRequest) (restql.HTTPResponse, error) {
+	url := fmt.Sprintf("%s://%s%s", request.Schema, request.Host, request.Path)
+
+	r, found := c.Script(request.Host).next(request)
+	if !found {
+		return restql.HTTPResponse{URL: url, StatusCode: http.StatusBadGateway}, fmt.Errorf("%w: %s", ErrUnscripted, request.Host)
+	}
+
+	if r.Delay > 0 {
+		timeout := request.Timeout
+		if timeout <= 0 || r.Delay < timeout {
+			timeout = r.Delay
+		}
+
+		select {
+		case <-ctx.Done():
+			return restql.HTTPResponse{URL: url, StatusCode: http.StatusRequestTimeout}, domain.ErrRequestTimeout
+		case <-time.After(timeout):
+		}
+
+		if timeout < r.Delay {
+			return restql.HTTPResponse{URL: url, StatusCode: http.StatusRequestTimeout, Duration: timeout}, domain.ErrRequestTimeout
+		}
+	}
+
+	if r.Err != nil {
+		return restql.HTTPResponse{URL: url, Duration: r.Delay}, r.Err
+	}
+
+	headers := make(restql.Headers, len(r.Headers))
+	for k, v := range r.Headers {
+		headers[k] = v
+	}
+
+	response := restql.HTTPResponse{
+		URL:        url,
+		StatusCode: r.StatusCode,
+		Headers:    headers,
+		Duration:   r.Delay,
+		Body:       restql.NewResponseBodyFromValue(restql.GetLogger(ctx), r.Body),
+	}
+
+	return response, nil
+}
+
+// Requests returns the requests made to the resource.
+func (c *Client) Requests(resource string) []restql.HTTPRequest {
+	s := c.Script(resource)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]restql.HTTPRequest(nil), s.requests...)
+}
+
+// Fake is an in-memory restQL runtime, whose
+// resources are answered by a fake client.
+type Fake struct {
+	client   *Client
+	mappings map[string]string
+	once     sync.Once
+	engine   *engine.Engine
+	err      error
+}
+
+// New constructs a Fake without resources.
+func New() *Fake {
+	return &Fake{client: NewClient(), mappings: make(map[string]string)}
+}
+
+// Resource maps the resource to the given path, which can have path
+// parameters like in regular mappings, and returns its script.
+// Resources must be defined before the first query runs.
+func (f *Fake) Resource(name string, path string) *Script {
+	f.mappings[name] = "http://" + name + "/" + strings.TrimPrefix(path, "/")
+	return f.client.Script(name)
+}
+
+// Mappings returns the mappings of the defined resources.
+func (f *Fake) Mappings() map[string]string {
+	return f.mappings
+}
+
+// Client returns the client answering the requests.
+func (f *Fake) Client() *Client {
+	return f.client
+}
+
+// Engine constructs an engine with the given configuration,
+// using the fake mappings and client.
+func (f *Fake) Engine(config engine.Config) (*engine.Engine, error) {
+	config.Mappings = f.mappings
+	config.HTTPClient = f.client
+
+	return engine.New(config)
+}
+
+// Run executes the ad-hoc query with the given parameters.
+func (f *Fake) Run(ctx context.Context, queryText string, params map[string]interface{}) (engine.Result, error) {
+	f.once.Do(func() {
+		f.engine, f.err = f.Engine(engine.Config{})
+	})
+	if f.err != nil {
+		return engine.Result{}, f.err
+	}
+
+	return f.engine.Execute(ctx, queryText, params)
+}
+
+// Requests returns the requests made to the resource.
+func (f *Fake) Requests(resource string) []restql.HTTPRequest {
+	return f.client.Requests(resource)
+}
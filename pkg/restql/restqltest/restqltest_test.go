@@ -0,0 +1,49 @@
+package restqltest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql/restqltest"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestFakeRun(t *testing.T) {
+	fake := restqltest.New()
+	fake.Resource("hero", "/heroes/:id").
+		Respond(restqltest.JSON(http.StatusOK, map[string]interface{}{"id": "1", "sidekick": "robin"}))
+	fake.Resource("sidekick", "/sidekicks").
+		Respond(restqltest.Failure(errors.New("connection refused")), restqltest.JSON(http.StatusOK, map[string]interface{}{"name": "robin"}))
+
+	query := `
+use retries 1
+
+from hero with id = $id
+from sidekick with name = hero.sidekick
+`
+	result, err := fake.Run(context.Background(), query, map[string]interface{}{"id": "1"})
+	test.VerifyError(t, err)
+
+	test.Equal(t, result.StatusCode, http.StatusOK)
+	test.Equal(t, result.Body["sidekick"].(map[string]interface{})["result"], map[string]interface{}{"name": "robin"})
+
+	heroRequests := fake.Requests("hero")
+	test.Equal(t, len(heroRequests), 1)
+	test.Equal(t, heroRequests[0].Path, "/heroes/1")
+
+	sidekickRequests := fake.Requests("sidekick")
+	test.Equal(t, len(sidekickRequests), 2)
+	test.Equal(t, sidekickRequests[1].Query, map[string]interface{}{"name": "robin"})
+}
+
+func TestFakeUnscriptedResource(t *testing.T) {
+	fake := restqltest.New()
+	fake.Resource("hero", "/heroes")
+
+	result, err := fake.Run(context.Background(), "from hero", nil)
+	test.VerifyError(t, err)
+
+	test.Equal(t, result.StatusCode, http.StatusBadGateway)
+}
@@ -0,0 +1,67 @@
+package restql
+
+import "sync/atomic"
+
+// NewSampledLogger returns a Logger that only writes one of every N
+// entries of a level, as defined by the given rates, keeping high
+// volume levels, like debug, affordable under heavy traffic. Levels
+// without a rate greater than 1 are not sampled, and neither are
+// the error, fatal and panic entries. Loggers derived from it
+// with With and WithFields share the same sampling.
+func NewSampledLogger(l Logger, every map[Level]uint64) Logger {
+	s := &sampler{every: make(map[Level]uint64), counters: make(map[Level]*uint64)}
+	for level, n := range every {
+		if level >= ErrorLevel || n <= 1 {
+			continue
+		}
+		s.every[level] = n
+		s.counters[level] = new(uint64)
+	}
+
+	return sampledLogger{Logger: l, sampler: s}
+}
+
+type sampler struct {
+	every    map[Level]uint64
+	counters map[Level]*uint64
+}
+
+func (s *sampler) keep(level Level) bool {
+	n, ok := s.every[level]
+	if !ok {
+		return true
+	}
+
+	return (atomic.AddUint64(s.counters[level], 1)-1)%n == 0
+}
+
+type sampledLogger struct {
+	Logger
+	sampler *sampler
+}
+
+func (sl sampledLogger) Warn(msg string, fields ...interface{}) {
+	if sl.sampler.keep(WarnLevel) {
+		sl.Logger.Warn(msg, fields...)
+	}
+}
+
+func (sl sampledLogger) Info(msg string, fields ...interface{}) {
+	if sl.sampler.keep(InfoLevel) {
+		sl.Logger.Info(msg, fields...)
+	}
+}
+
+func (sl sampledLogger) Debug(msg string, fields ...interface{}) {
+	if sl.sampler.keep(DebugLevel) {
+		sl.Logger.Debug(msg, fields...)
+	}
+}
+
+func (sl sampledLogger) With(key string, value interface{}) Logger {
+	return sampledLogger{Logger: sl.Logger.With(key, value), sampler: sl.sampler}
+}
+
+func (sl sampledLogger) WithFields(fields ...interface{}) Logger {
+	return sampledLogger{Logger: sl.Logger.WithFields(fields...), sampler: sl.sampler}
+}
@@ -0,0 +1,66 @@
+package restql_test
+
+import (
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+type recordingLogger struct {
+	entries *[]string
+}
+
+func (r recordingLogger) Panic(msg string, fields ...interface{}) {
+	*r.entries = append(*r.entries, msg)
+}
+func (r recordingLogger) Fatal(msg string, fields ...interface{}) {
+	*r.entries = append(*r.entries, msg)
+}
+func (r recordingLogger) Error(msg string, err error, fields ...interface{}) {
+	*r.entries = append(*r.entries, msg)
+}
+func (r recordingLogger) Warn(msg string, fields ...interface{}) {
+	*r.entries = append(*r.entries, msg)
+}
+func (r recordingLogger) Info(msg string, fields ...interface{}) {
+	*r.entries = append(*r.entries, msg)
+}
+func (r recordingLogger) Debug(msg string, fields ...interface{}) {
+	*r.entries = append(*r.entries, msg)
+}
+func (r recordingLogger) With(key string, value interface{}) restql.Logger {
+	return r
+}
+func (r recordingLogger) WithFields(fields ...interface{}) restql.Logger { return r }
+func (r recordingLogger) Enabled(level restql.Level) bool                { return true }
+
+func TestNewSampledLogger(t *testing.T) {
+	var entries []string
+	log := restql.NewSampledLogger(recordingLogger{entries: &entries}, map[restql.Level]uint64{
+		restql.DebugLevel: 3,
+		restql.InfoLevel:  1,
+		restql.ErrorLevel: 2,
+	})
+
+	for i := 0; i < 4; i++ {
+		log.Debug("debug")
+		log.With("key", "value").Info("info")
+		log.WithFields("key", "value").Error("error", nil)
+	}
+
+	expected := []string{"debug", "info", "error", "info", "error", "info", "error", "debug", "info", "error"}
+	test.Equal(t, entries, expected)
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := restql.ParseLevel("WARN")
+	test.VerifyError(t, err)
+	test.Equal(t, level, restql.WarnLevel)
+	test.Equal(t, level.String(), "warn")
+
+	_, err = restql.ParseLevel("verbose")
+	if err == nil {
+		t.Fatalf("expected error for unknown level")
+	}
+}
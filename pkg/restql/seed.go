@@ -0,0 +1,50 @@
+package restql
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+)
+
+type seedCtxKey struct{}
+
+// WithSeed returns a context carrying the seed of the query
+// execution, from which every randomized decision is derived.
+func WithSeed(ctx context.Context, seed int64) context.Context {
+	return context.WithValue(ctx, seedCtxKey{}, seed)
+}
+
+// Seed extracts the seed of the query execution
+// from the given context.Context.
+func Seed(ctx context.Context) (int64, bool) {
+	seed, ok := ctx.Value(seedCtxKey{}).(int64)
+	return seed, ok
+}
+
+// Random returns a pseudo-random number in [0.0,1.0) for the
+// decision identified by key, like sampling or bucketing. When the
+// context carries a seed the number is derived from it and the key,
+// hence each decision is independent from the others and is repeated
+// when the execution is replayed with the same seed. Plugins should
+// use it, instead of their own sources, for their executions to be
+// reproducible as well.
+func Random(ctx context.Context, key string) float64 {
+	seed, ok := Seed(ctx)
+	if !ok {
+		return rand.Float64()
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(key))
+
+	return float64(splitMix64(uint64(seed)^h.Sum64())>>11) / (1 << 53)
+}
+
+// splitMix64 scrambles the bits of x, mapping
+// close inputs to uncorrelated outputs.
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -0,0 +1,35 @@
+package restql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestRandom(t *testing.T) {
+	ctx := restql.WithSeed(context.Background(), 42)
+
+	seed, ok := restql.Seed(ctx)
+	test.Equal(t, ok, true)
+	test.Equal(t, seed, int64(42))
+
+	first := restql.Random(ctx, "trace-sample")
+	test.Equal(t, restql.Random(ctx, "trace-sample"), first)
+	test.Equal(t, restql.Random(restql.WithSeed(context.Background(), 42), "trace-sample"), first)
+
+	if restql.Random(ctx, "bucket") == first {
+		t.Errorf("expected decisions with different keys to be independent")
+	}
+	if restql.Random(restql.WithSeed(context.Background(), 43), "trace-sample") == first {
+		t.Errorf("expected different seeds to produce different numbers")
+	}
+
+	for i := int64(0); i < 1000; i++ {
+		n := restql.Random(restql.WithSeed(context.Background(), i), "trace-sample")
+		if n < 0 || n >= 1 {
+			t.Fatalf("random number out of range: %f", n)
+		}
+	}
+}
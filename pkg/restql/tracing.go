@@ -0,0 +1,21 @@
+package restql
+
+import "context"
+
+type traceIDCtxKey struct{}
+
+// WithTraceID returns a context carrying the identifier of the
+// distributed trace the query execution is part of. restQL sets it
+// from the W3C `traceparent` request header, and tracing plugins
+// starting their own traces can replace it.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDCtxKey{}, traceID)
+}
+
+// TraceID extracts the identifier of the distributed trace from the
+// given context.Context, which metrics plugins can attach as an
+// exemplar to the observations made during the query execution.
+func TraceID(ctx context.Context) (string, bool) {
+	traceID, ok := ctx.Value(traceIDCtxKey{}).(string)
+	return traceID, ok && traceID != ""
+}
@@ -0,0 +1,80 @@
+package e2e
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/b2wdigital/restQL-golang/v4/test"
+)
+
+func TestPassThroughOnSingleStatement(t *testing.T) {
+	query := `from planets`
+
+	planetResponse := `{ "name": "Yavin IV",  "climate": "temperate, tropical" }`
+
+	mockServer := test.NewMockServer(mockPort)
+	defer mockServer.Teardown()
+
+	mockServer.Mux().HandleFunc("/api/planets/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.WriteHeader(200)
+		io.WriteString(w, planetResponse)
+	})
+	mockServer.Start()
+
+	response, err := httpClient.Post(adHocQueryUrl+"&_passthrough=true", "text/plain", strings.NewReader(query))
+	test.VerifyError(t, err)
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	test.VerifyError(t, err)
+
+	test.Equal(t, response.StatusCode, http.StatusOK)
+	test.Equal(t, response.Header.Get("Content-Type"), "application/vnd.api+json")
+	test.Equal(t, string(body), planetResponse)
+}
+
+func TestPassThroughIgnoredWithOnlyFilter(t *testing.T) {
+	query := `
+from planets
+	only
+		name
+`
+
+	planetResponse := `{ "name": "Yavin IV", "climate": "temperate, tropical" }`
+
+	expectedResponse := `
+	{
+		"planets": {
+			"details": {
+				"success": true,
+				"status": 200,
+				"metadata": {}
+			},
+			"result": { "name": "Yavin IV" }
+		}
+	}`
+
+	mockServer := test.NewMockServer(mockPort)
+	defer mockServer.Teardown()
+
+	mockServer.Mux().HandleFunc("/api/planets/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		io.WriteString(w, planetResponse)
+	})
+	mockServer.Start()
+
+	response, err := httpClient.Post(adHocQueryUrl+"&_passthrough=true", "text/plain", strings.NewReader(query))
+	test.VerifyError(t, err)
+	defer response.Body.Close()
+
+	var body map[string]interface{}
+	err = json.NewDecoder(response.Body).Decode(&body)
+	test.VerifyError(t, err)
+
+	test.Equal(t, body, test.Unmarshal(expectedResponse))
+}
@@ -3,6 +3,7 @@ package test
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/b2wdigital/restQL-golang/v4/internal/platform/expr"
 	"github.com/b2wdigital/restQL-golang/v4/pkg/restql"
 	"github.com/google/go-cmp/cmp"
 	"log"
@@ -28,6 +29,19 @@ var regexComparer = cmp.Comparer(func(x, y *regexp.Regexp) bool {
 	return x.String() == y.String()
 })
 
+var programComparer = cmp.Comparer(func(x, y *expr.Program) bool {
+	return x.String() == y.String()
+})
+
+// Program compiles the expression, panicking when it is invalid.
+func Program(source string) *expr.Program {
+	p, err := expr.Compile(source)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
 var mappingComparer = cmp.Comparer(func(x, y restql.Mapping) bool {
 	return x.ResourceName() == y.ResourceName() &&
 		x.Schema() == y.Schema() &&
@@ -39,8 +53,8 @@ var responseBodyTransformer = cmp.Transformer("ResponseBody", func(x restql.Resp
 })
 
 func Equal(t *testing.T, got, expected interface{}) {
-	if !cmp.Equal(got, expected, regexComparer, mappingComparer, responseBodyTransformer) {
-		t.Errorf("got = %+#v, want = %+#v\nMismatch (-want +got):\n%s", got, expected, cmp.Diff(expected, got, regexComparer, mappingComparer, responseBodyTransformer))
+	if !cmp.Equal(got, expected, regexComparer, programComparer, mappingComparer, responseBodyTransformer) {
+		t.Errorf("got = %+#v, want = %+#v\nMismatch (-want +got):\n%s", got, expected, cmp.Diff(expected, got, regexComparer, programComparer, mappingComparer, responseBodyTransformer))
 	}
 }
 
@@ -107,3 +121,5 @@ func (n noOpLogger) Warn(msg string, fields ...interface{})             {}
 func (n noOpLogger) Info(msg string, fields ...interface{})             {}
 func (n noOpLogger) Debug(msg string, fields ...interface{})            {}
 func (n noOpLogger) With(key string, value interface{}) restql.Logger   { return n }
+func (n noOpLogger) WithFields(fields ...interface{}) restql.Logger     { return n }
+func (n noOpLogger) Enabled(level restql.Level) bool                    { return false }